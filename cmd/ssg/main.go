@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
 
 	"github.com/kvnloughead/ssg/internal/ssg"
 )
@@ -13,18 +17,146 @@ func main() {
 	buildCmd := flag.NewFlagSet("build", flag.ExitOnError)
 	serveCmd := flag.NewFlagSet("serve", flag.ExitOnError)
 	newCmd := flag.NewFlagSet("new", flag.ExitOnError)
+	benchCmd := flag.NewFlagSet("bench", flag.ExitOnError)
+	diffCmd := flag.NewFlagSet("diff", flag.ExitOnError)
+	checkCmd := flag.NewFlagSet("check", flag.ExitOnError)
+	importCmd := flag.NewFlagSet("import", flag.ExitOnError)
+	movedCmd := flag.NewFlagSet("moved", flag.ExitOnError)
+	mergeCmd := flag.NewFlagSet("merge", flag.ExitOnError)
+	deployCmd := flag.NewFlagSet("deploy", flag.ExitOnError)
+	publishCmd := flag.NewFlagSet("publish", flag.ExitOnError)
+	tuiCmd := flag.NewFlagSet("tui", flag.ExitOnError)
+	exportCmd := flag.NewFlagSet("export", flag.ExitOnError)
+	newsletterCmd := flag.NewFlagSet("newsletter", flag.ExitOnError)
+	themeInstallCmd := flag.NewFlagSet("theme install", flag.ExitOnError)
+	themeUpdateCmd := flag.NewFlagSet("theme update", flag.ExitOnError)
 
 	// Build command flags
 	buildOutput := buildCmd.String(
 		"output", "public", "output directory for generated site")
 	buildConfig := buildCmd.String(
 		"config", "config.yaml", "path to config file")
+	buildStrict := buildCmd.Bool("strict", false, "fail the build if broken internal links or frontmatter problems are found")
+	buildDrafts := buildCmd.Bool("drafts", false, "include draft posts")
+	buildFuture := buildCmd.Bool("future", false, "include posts dated in the future")
+	buildExpired := buildCmd.Bool("expired", false, "include posts whose expiryDate has passed")
+	buildBaseURL := buildCmd.String("base-url", "", "override baseUrl from the config")
+	buildLog := addLogFlags(buildCmd)
+	buildProfile := buildCmd.Bool("profile", false, "report how long each stage of the build and the slowest posts took")
+	buildCPUProfile := buildCmd.String("cpu-profile", "", "write a pprof CPU profile of the build to this file")
+	buildEnv := buildCmd.String("env", "", "build environment: production or development (default: $SSG_ENV, or production)")
+	buildNotify := buildCmd.Bool("notify", false, "run the notify hooks from the config when the build finishes")
+	buildWebmentions := buildCmd.Bool("send-webmentions", false, "send webmentions after a production build (for output that's served as it's built)")
+	buildShard := buildCmd.String("shard", "", "build only shard i of n, e.g. 2/4 (combine shards with merge)")
+	buildWatch := buildCmd.Bool("watch", false, "keep running and update the output when sources change")
+	buildDryRun := buildCmd.Bool("dry-run", false, "list the files a build would create, change, or delete without writing them")
 
 	// Serve command flags
 	servePort := serveCmd.String("port", "8080", "port to serve on")
+	serveOutput := serveCmd.String(
+		"output", "public", "output directory to build into and serve")
+	serveConfig := serveCmd.String(
+		"config", "config.yaml", "path to config file")
+	serveNoBuild := serveCmd.Bool("no-build", false, "serve the existing output without building first")
+	serveNoListings := serveCmd.Bool("no-listings", false, "respond 404 to directories without an index.html instead of listing them")
+	serveNoWatch := serveCmd.Bool("no-watch", false, "build once instead of rebuilding when sources change")
+	serveNoRewrite := serveCmd.Bool("no-rewrite", false, "serve pages as built, without pointing links to baseUrl at the local server")
+	serveLive := serveCmd.Bool("live", false, "render pages from source on each request instead of serving the last build")
+	serveInMemory := serveCmd.Bool("in-memory", false, "build into memory and serve from there, without writing the output directory")
+	serveLog := addLogFlags(serveCmd)
 
 	// New command flags
 	newTitle := newCmd.String("title", "", "post title")
+	newSlug := newCmd.String("slug", "", "slug for the file name and URL (default: generated from the title)")
+	newDescription := newCmd.String("description", "", "post description")
+	newTags := newCmd.String("tags", "", "comma-separated tags, e.g. go,web")
+	newSection := newCmd.String("section", "posts", "section under content/ to create the post in, e.g. pages")
+	newDraft := newCmd.Bool("draft", true, "create the post as a draft (--draft=false to publish it)")
+	newEdit := newCmd.Bool("edit", false, "open the new post in $EDITOR")
+
+	// Bench command flags
+	benchPosts := benchCmd.Int("posts", 1000, "number of synthetic posts to build")
+
+	// Diff command flags
+	diffOutput := diffCmd.String(
+		"output", "public", "output directory of the previous build")
+	diffConfig := diffCmd.String(
+		"config", "config.yaml", "path to config file")
+	diffRef := diffCmd.String(
+		"ref", "", "git ref of the output directory to compare against")
+	diffStat := diffCmd.Bool("stat", false, "only list changed files")
+	diffWords := diffCmd.Bool("words", false, "show the changed words of each line instead of a unified diff")
+
+	// Check command flags
+	checkConfig := checkCmd.String(
+		"config", "config.yaml", "path to config file")
+	checkExternal := checkCmd.Bool("external", false, "also fetch links to other sites and report dead ones")
+
+	// Import command flags
+	importFrom := importCmd.String("from", "", "generator the site was built with (hugo or jekyll)")
+
+	// Moved command flags
+	movedOutput := movedCmd.String(
+		"output", "public", "output directory of the previous build")
+	movedConfig := movedCmd.String(
+		"config", "config.yaml", "path to config file")
+	movedRef := movedCmd.String(
+		"ref", "", "git ref of the output directory to compare against")
+	movedWrite := movedCmd.Bool("write", false, "add redirects for moved pages to the config")
+
+	// Merge command flags
+	mergeOutput := mergeCmd.String(
+		"output", "public", "output directory for the merged site")
+	mergeConfig := mergeCmd.String(
+		"config", "config.yaml", "path to config file")
+	mergeStrict := mergeCmd.Bool("strict", false, "fail if broken internal links are found")
+
+	// Deploy command flags
+	deployOutput := deployCmd.String(
+		"output", "public", "output directory to build into and deploy")
+	deployConfig := deployCmd.String(
+		"config", "config.yaml", "path to config file")
+	deployNoBuild := deployCmd.Bool("no-build", false, "deploy the existing output without building first")
+	deployDryRun := deployCmd.Bool("dry-run", false, "show what would be deployed without changing the target")
+	deployLog := addLogFlags(deployCmd)
+
+	// Publish command flags
+	publishOutput := publishCmd.String(
+		"output", "public", "output directory to build into")
+	publishConfig := publishCmd.String(
+		"config", "config.yaml", "path to config file")
+	publishRename := publishCmd.Bool("rename", false, "rename a file named by date to the publish date")
+	publishNoBuild := publishCmd.Bool("no-build", false, "update the post without rebuilding the site")
+
+	// TUI command flags
+	tuiOutput := tuiCmd.String(
+		"output", "public", "output directory to build into")
+	tuiConfig := tuiCmd.String(
+		"config", "config.yaml", "path to config file")
+
+	// Export command flags
+	exportOutput := exportCmd.String(
+		"output", "export", "directory to write the export to")
+	exportConfig := exportCmd.String(
+		"config", "config.yaml", "path to config file")
+	exportFormat := exportCmd.String("format", "json", "export format (json)")
+	exportDrafts := exportCmd.Bool("drafts", false, "include draft posts")
+
+	// Newsletter command flags
+	newsletterOutput := newsletterCmd.String(
+		"output", "newsletter.html", "HTML file to write; the plain text goes next to it as .txt")
+	newsletterConfig := newsletterCmd.String(
+		"config", "config.yaml", "path to config file")
+	newsletterCount := newsletterCmd.Int("count", 5, "number of recent posts to include")
+
+	// Theme command flags
+	themeInstallConfig := themeInstallCmd.String(
+		"config", "config.yaml", "path to config file")
+	themeInstallName := themeInstallCmd.String("name", "", "directory name under themes/ (default: from the URL)")
+	themeInstallVersion := themeInstallCmd.String("version", "", "tag, branch, or commit to install (default: the pinned version)")
+	themeUpdateConfig := themeUpdateCmd.String(
+		"config", "config.yaml", "path to config file")
+	themeUpdateVersion := themeUpdateCmd.String("version", "", "tag, branch, or commit to update to (default: latest)")
 
 	// Parse command
 	if len(os.Args) < 2 {
@@ -38,18 +170,62 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
 			os.Exit(1)
 		}
-		if err := ssg.Build(*buildConfig, *buildOutput); err != nil {
+		buildLog.setup()
+		opts := ssg.BuildOptions{
+			ConfigPath:  *buildConfig,
+			OutputDir:   *buildOutput,
+			Drafts:      *buildDrafts,
+			Future:      *buildFuture,
+			Expired:     *buildExpired,
+			BaseURL:     *buildBaseURL,
+			Environment: *buildEnv,
+			Strict:      *buildStrict,
+			Notify:      *buildNotify,
+			Webmentions: *buildWebmentions,
+			Shard:       *buildShard,
+			DryRun:      *buildDryRun,
+			Profile:     *buildProfile,
+			CPUProfile:  *buildCPUProfile,
+		}
+		if *buildWatch && *buildDryRun {
+			fmt.Fprintln(os.Stderr, "Error: --watch and --dry-run can't be combined")
+			os.Exit(1)
+		}
+		if *buildWatch {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			if err := ssg.WatchBuild(ctx, opts); err != nil {
+				fmt.Fprintf(os.Stderr, "Error building site: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if err := ssg.Build(context.Background(), opts); err != nil {
 			fmt.Fprintf(os.Stderr, "Error building site: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Site built successfully!")
+		if !*buildDryRun && !*buildLog.quiet {
+			fmt.Println("Site built successfully!")
+		}
 
 	case "serve":
 		if err := serveCmd.Parse(os.Args[2:]); err != nil {
 			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
 			os.Exit(1)
 		}
-		if err := ssg.Serve(*servePort); err != nil {
+		serveLog.setup()
+		opts := ssg.ServeOptions{
+			ConfigPath: *serveConfig,
+			OutputDir:  *serveOutput,
+			Port:       *servePort,
+			NoBuild:    *serveNoBuild,
+			NoWatch:    *serveNoWatch,
+			NoListings: *serveNoListings,
+			NoRewrite:  *serveNoRewrite,
+			Live:       *serveLive,
+			InMemory:   *serveInMemory,
+		}
+		if err := ssg.Serve(opts); err != nil {
 			fmt.Fprintf(os.Stderr, "Error serving site: %v\n", err)
 			os.Exit(1)
 		}
@@ -64,17 +240,264 @@ func main() {
 			newCmd.Usage()
 			os.Exit(1)
 		}
-		if err := ssg.NewPost(*newTitle); err != nil {
+		var tags []string
+		for tag := range strings.SplitSeq(*newTags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		opts := ssg.NewPostOptions{
+			Title:       *newTitle,
+			Slug:        *newSlug,
+			Description: *newDescription,
+			Tags:        tags,
+			Section:     *newSection,
+			NoDraft:     !*newDraft,
+			Edit:        *newEdit,
+		}
+		if _, err := ssg.NewPost(opts); err != nil {
 			fmt.Fprintf(os.Stderr, "Error creating post: %v\n", err)
 			os.Exit(1)
 		}
 
+	case "bench":
+		if err := benchCmd.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+			os.Exit(1)
+		}
+		if err := ssg.Bench(*benchPosts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running benchmark: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "diff":
+		if err := diffCmd.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+			os.Exit(1)
+		}
+		opts := ssg.DiffOptions{
+			ConfigPath: *diffConfig,
+			OutputDir:  *diffOutput,
+			Ref:        *diffRef,
+			Stat:       *diffStat,
+			Words:      *diffWords,
+		}
+		if err := ssg.Diff(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error comparing builds: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "check":
+		if err := checkCmd.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+			os.Exit(1)
+		}
+		if err := ssg.Check(ssg.CheckOptions{ConfigPath: *checkConfig, External: *checkExternal}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking site: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "import":
+		if err := importCmd.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+			os.Exit(1)
+		}
+		if *importFrom == "" || importCmd.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "Usage: ssg import --from hugo|jekyll <dir>")
+			os.Exit(1)
+		}
+		if err := ssg.Import(*importFrom, importCmd.Arg(0)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error importing site: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "moved":
+		if err := movedCmd.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+			os.Exit(1)
+		}
+		if err := ssg.Moved(*movedConfig, *movedOutput, *movedRef, *movedWrite); err != nil {
+			fmt.Fprintf(os.Stderr, "Error finding moved pages: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "merge":
+		if err := mergeCmd.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+			os.Exit(1)
+		}
+		if mergeCmd.NArg() == 0 {
+			fmt.Fprintln(os.Stderr, "Usage: ssg merge [--config <file>] [--output <dir>] <shard-dir>...")
+			os.Exit(1)
+		}
+		if err := ssg.Merge(*mergeConfig, *mergeOutput, mergeCmd.Args(), *mergeStrict); err != nil {
+			fmt.Fprintf(os.Stderr, "Error merging shards: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "publish":
+		if err := publishCmd.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+			os.Exit(1)
+		}
+		if publishCmd.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "Usage: ssg publish [flags] <slug>")
+			os.Exit(1)
+		}
+		opts := ssg.PublishOptions{
+			ConfigPath: *publishConfig,
+			OutputDir:  *publishOutput,
+			Slug:       publishCmd.Arg(0),
+			Rename:     *publishRename,
+			NoBuild:    *publishNoBuild,
+		}
+		if err := ssg.Publish(context.Background(), opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error publishing post: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "tui":
+		if err := tuiCmd.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+			os.Exit(1)
+		}
+		opts := ssg.TUIOptions{ConfigPath: *tuiConfig, OutputDir: *tuiOutput}
+		if err := ssg.TUI(context.Background(), opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "deploy":
+		if err := deployCmd.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+			os.Exit(1)
+		}
+		deployLog.setup()
+		if deployCmd.NArg() > 1 {
+			fmt.Fprintln(os.Stderr, "Usage: ssg deploy [flags] [<target>]")
+			os.Exit(1)
+		}
+		opts := ssg.DeployOptions{
+			ConfigPath: *deployConfig,
+			OutputDir:  *deployOutput,
+			Target:     deployCmd.Arg(0),
+			NoBuild:    *deployNoBuild,
+			DryRun:     *deployDryRun,
+		}
+		if err := ssg.Deploy(context.Background(), opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error deploying site: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "export":
+		if err := exportCmd.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+			os.Exit(1)
+		}
+		opts := ssg.ExportOptions{
+			ConfigPath: *exportConfig,
+			OutputDir:  *exportOutput,
+			Format:     *exportFormat,
+			Drafts:     *exportDrafts,
+		}
+		if err := ssg.Export(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting site: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "newsletter":
+		if err := newsletterCmd.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+			os.Exit(1)
+		}
+		opts := ssg.NewsletterOptions{
+			ConfigPath: *newsletterConfig,
+			Output:     *newsletterOutput,
+			Count:      *newsletterCount,
+		}
+		if err := ssg.Newsletter(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing newsletter: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "theme":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(os.Stderr, "Usage: ssg theme install|update|new")
+			os.Exit(1)
+		}
+		var err error
+		switch os.Args[2] {
+		case "install":
+			if err := themeInstallCmd.Parse(os.Args[3:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+				os.Exit(1)
+			}
+			err = ssg.InstallTheme(*themeInstallConfig, themeInstallCmd.Arg(0), *themeInstallName, *themeInstallVersion)
+		case "update":
+			if err := themeUpdateCmd.Parse(os.Args[3:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing command arguments: %v\n", err)
+				os.Exit(1)
+			}
+			err = ssg.UpdateTheme(*themeUpdateConfig, *themeUpdateVersion)
+		case "new":
+			if len(os.Args) != 4 {
+				fmt.Fprintln(os.Stderr, "Usage: ssg theme new <name>")
+				os.Exit(1)
+			}
+			err = ssg.NewTheme(os.Args[3])
+		default:
+			fmt.Fprintln(os.Stderr, "Usage: ssg theme install|update|new")
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error managing theme: %v\n", err)
+			os.Exit(1)
+		}
+
 	default:
 		printUsage()
 		os.Exit(1)
 	}
 }
 
+// logFlags are the logging flags of the commands that build the site.
+type logFlags struct {
+	verbose *bool
+	quiet   *bool
+	format  *string
+}
+
+// addLogFlags defines the logging flags on fs.
+func addLogFlags(fs *flag.FlagSet) logFlags {
+	return logFlags{
+		verbose: fs.Bool("verbose", false, "also log each file written and how long each build stage took"),
+		quiet:   fs.Bool("quiet", false, "only log warnings and errors"),
+		format:  fs.String("log-format", "text", "log format: text or json"),
+	}
+}
+
+// setup makes the logger the flags describe the default, writing to stderr,
+// and exits if they're invalid.
+func (f logFlags) setup() {
+	if *f.verbose && *f.quiet {
+		fmt.Fprintln(os.Stderr, "Error: --verbose and --quiet can't be combined")
+		os.Exit(1)
+	}
+	level := slog.LevelInfo
+	switch {
+	case *f.verbose:
+		level = slog.LevelDebug
+	case *f.quiet:
+		level = slog.LevelWarn
+	}
+	logger, err := ssg.NewLogger(os.Stderr, level, *f.format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+}
+
 // printUsage prints the usage information
 func printUsage() {
 	fmt.Println("SSG - Static Site Generator")
@@ -84,9 +507,64 @@ func printUsage() {
 	fmt.Println("  build    Build the static site")
 	fmt.Println("  serve    Serve the site locally")
 	fmt.Println("  new      Create a new post")
+	fmt.Println("  publish  Publish a draft post and rebuild the site")
+	fmt.Println("  tui      Manage posts and build the site interactively")
+	fmt.Println("  bench    Build a synthetic site and report performance")
+	fmt.Println("  diff     Show how a fresh build differs from the previous one")
+	fmt.Println("  check    Validate content, links, and templates without building")
+	fmt.Println("  import   Convert a Hugo or Jekyll site's content into this layout")
+	fmt.Println("  moved    List pages of the previous build that no longer exist")
+	fmt.Println("  merge    Combine the output of sharded builds")
+	fmt.Println("  deploy   Build the site and publish it to a target from the config")
+	fmt.Println("  export   Write the site's content as JSON for other frontends")
+	fmt.Println("  newsletter  Write the latest posts as an email newsletter")
+	fmt.Println("  theme    Install, update, or create a theme")
 	fmt.Println("\nFlags:")
 	fmt.Println("  build --output <dir>   Output directory (default: public)")
 	fmt.Println("  build --config <file>  Config file (default: config.yaml)")
+	fmt.Println("  build --strict         Fail if broken links or frontmatter problems are found")
+	fmt.Println("  build --drafts         Include draft posts")
+	fmt.Println("  build --future         Include posts dated in the future")
+	fmt.Println("  build --expired        Include posts whose expiryDate has passed")
+	fmt.Println("  build --base-url <url> Override baseUrl from the config")
+	fmt.Println("  build --verbose        Also log each file written and how long each stage took")
+	fmt.Println("  build --quiet          Only log warnings and errors")
+	fmt.Println("  build --log-format <f> Log format: text (default) or json, e.g. for CI; also for serve and deploy")
+	fmt.Println("  build --env <env>      Build environment: production or development (default: $SSG_ENV or production)")
+	fmt.Println("  build --notify         Run the notify hooks from the config when done")
+	fmt.Println("  build --shard <i/n>    Build only shard i of n (e.g. 2/4)")
+	fmt.Println("  build --watch          Keep running and update the output when sources change")
+	fmt.Println("  build --dry-run        List the files a build would create, change, or delete")
+	fmt.Println("  build --profile        Report the time of each build stage and the slowest posts")
+	fmt.Println("  build --cpu-profile <file>  Write a pprof CPU profile of the build")
 	fmt.Println("  serve --port <port>    Port to serve on (default: 8080)")
+	fmt.Println("  serve --no-build       Serve the existing output without building first")
+	fmt.Println("  serve --no-listings    Don't list directories without an index.html")
+	fmt.Println("  serve --no-watch       Build once instead of rebuilding on changes")
+	fmt.Println("  serve --no-rewrite     Don't rewrite links to baseUrl to local ones")
+	fmt.Println("  serve --live           Render pages from source on each request")
+	fmt.Println("  serve --in-memory      Build into memory instead of the output directory")
 	fmt.Println("  new --title <title>    Post title (required)")
+	fmt.Println("  new --slug <slug>      Slug for the file name and URL (default: from the title)")
+	fmt.Println("  new --description <d>  Post description")
+	fmt.Println("  new --tags <a,b>       Comma-separated tags")
+	fmt.Println("  new --section <name>   Section under content/ to create the post in (default: posts)")
+	fmt.Println("  new --draft=false      Create the post published instead of as a draft")
+	fmt.Println("  new --edit             Open the new post in $EDITOR")
+	fmt.Println("  bench --posts <n>      Number of synthetic posts (default: 1000)")
+	fmt.Println("  diff --ref <ref>       Compare against a git ref of the output directory")
+	fmt.Println("  diff --stat            Only list changed files")
+	fmt.Println("  diff --words           Show changed words instead of a unified diff")
+	fmt.Println("  check --config <file>  Config file (default: config.yaml)")
+	fmt.Println("  check --external       Also report dead links to other sites (see linkCheck in the config)")
+	fmt.Println("  import --from <gen> <dir>  Import from hugo or jekyll")
+	fmt.Println("  moved --write          Add redirects for moved pages to the config")
+	fmt.Println("  merge <dir>...         Merge shard output directories (--output, --strict)")
+	fmt.Println("  publish <slug>         Publish a draft (--rename to rename it to the new date, --no-build)")
+	fmt.Println("  deploy [<target>]      Deploy to a target (--no-build, --dry-run)")
+	fmt.Println("  export --format json   Export site.json and a document per post (--output, default: export; --drafts)")
+	fmt.Println("  newsletter --count <n> Newest posts to include (default: 5; --output, default: newsletter.html)")
+	fmt.Println("  theme install [<url>]  Install a theme from git (--name, --version); no URL installs the pinned one")
+	fmt.Println("  theme update           Update the theme to its latest commit (--version)")
+	fmt.Println("  theme new <name>       Create a theme skeleton in themes/<name>")
 }
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// alertMarker matches the first line of a GitHub-style alert blockquote,
+// e.g. "[!NOTE]", capturing its type. Like GitHub, the type is matched
+// case-insensitively.
+var alertMarker = regexp.MustCompile(`(?i)^\[!(note|tip|important|warning|caution)\]$`)
+
+// kindAdmonition is the AST node kind of admonitions.
+var kindAdmonition = ast.NewNodeKind("Admonition")
+
+// admonition is a callout block, converted from a blockquote starting with
+// an alert marker.
+type admonition struct {
+	ast.BaseBlock
+	typ string // Lowercase type, e.g. "note"
+}
+
+func (n *admonition) Kind() ast.NodeKind { return kindAdmonition }
+
+func (n *admonition) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Type": n.typ}, nil)
+}
+
+// admonitions is a goldmark extension rendering GitHub-style alerts:
+//
+//	> [!WARNING]
+//	> Back up your data first.
+//
+// as a <div> with the base class and the alert's type as classes, holding a
+// title paragraph and the rest of the blockquote:
+//
+//	<div class="admonition warning">
+//	<p class="admonition-title">Warning</p>
+//	<p>Back up your data first.</p>
+//	</div>
+type admonitions struct {
+	class      string // Class of the <div>, before the type
+	titleClass string // Class of the title paragraph
+}
+
+func (e admonitions) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(e, 100)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(e, 500)))
+}
+
+// Transform replaces blockquotes starting with an alert marker by
+// admonitions, removing the marker.
+func (e admonitions) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	source := reader.Source()
+	var quotes []*ast.Blockquote
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if quote, ok := n.(*ast.Blockquote); ok && entering {
+			quotes = append(quotes, quote)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, quote := range quotes {
+		para, ok := quote.FirstChild().(*ast.Paragraph)
+		if !ok || para.Lines().Len() == 0 {
+			continue
+		}
+		first := para.Lines().At(0)
+		m := alertMarker.FindStringSubmatch(strings.TrimSpace(string(first.Value(source))))
+		if m == nil {
+			continue
+		}
+
+		// Drop the marker's inline nodes, and its paragraph if that was all
+		for c := para.FirstChild(); c != nil; {
+			t, ok := c.(*ast.Text)
+			if !ok || t.Segment.Start >= first.Stop {
+				break
+			}
+			next := c.NextSibling()
+			para.RemoveChild(para, c)
+			c = next
+		}
+		if para.ChildCount() == 0 {
+			quote.RemoveChild(quote, para)
+		}
+
+		node := &admonition{typ: strings.ToLower(m[1])}
+		quote.Parent().ReplaceChild(quote.Parent(), quote, node)
+		for c := quote.FirstChild(); c != nil; {
+			next := c.NextSibling()
+			node.AppendChild(node, c)
+			c = next
+		}
+	}
+}
+
+func (e admonitions) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindAdmonition, e.render)
+}
+
+func (e admonitions) render(w util.BufWriter, _ []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</div>\n")
+		return ast.WalkContinue, nil
+	}
+	typ := n.(*admonition).typ
+	_, _ = w.WriteString(`<div class="`)
+	_, _ = w.Write(util.EscapeHTML([]byte(strings.TrimSpace(e.class + " " + typ))))
+	_, _ = w.WriteString("\">\n<p class=\"")
+	_, _ = w.Write(util.EscapeHTML([]byte(e.titleClass)))
+	_, _ = w.WriteString(`">`)
+	_, _ = w.WriteString(strings.ToUpper(typ[:1]) + typ[1:])
+	_, _ = w.WriteString("</p>\n")
+	return ast.WalkContinue, nil
+}
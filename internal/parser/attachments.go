@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Attachment is a file a post offers for download, from attachments in its
+// frontmatter:
+//
+//	attachments:
+//	  - file: slides.pdf
+//	    title: Slides from the talk
+//	  - file: ../data/results.csv
+//
+// File is relative to the directory of the post's file. The site generator
+// copies it next to the post's page and sets its URL and size.
+type Attachment struct {
+	File  string `yaml:"file"`  // Path of the file, relative to the post's directory
+	Title string `yaml:"title"` // Link text (default: the file's name)
+	Path  string `yaml:"-"`     // Path of the file in the site, set by the parser
+	URL   string `yaml:"-"`     // Site-relative URL of the copied file, set by the site generator
+	Size  int64  `yaml:"-"`     // Size of the file in bytes, set by the site generator
+}
+
+// HumanSize returns the attachment's size for people to read, in binary
+// units, e.g. "512 B", "12 KB", or "3.4 MB".
+func (a Attachment) HumanSize() string {
+	const units = "KMGT"
+	if a.Size < 1<<10 {
+		return fmt.Sprintf("%d B", a.Size)
+	}
+	size, unit := float64(a.Size)/(1<<10), 0
+	for size >= 1<<10 && unit < len(units)-1 {
+		size /= 1 << 10
+		unit++
+	}
+	if size < 10 {
+		return fmt.Sprintf("%.1f %cB", size, units[unit])
+	}
+	return fmt.Sprintf("%.0f %cB", size, units[unit])
+}
+
+// attachments returns the attachments in the frontmatter of the post at
+// path, with their paths resolved and default titles set.
+func attachments(fm Frontmatter, path string) ([]Attachment, error) {
+	var files []Attachment
+	for i, a := range fm.Attachments {
+		a.File = strings.TrimSpace(a.File)
+		if a.File == "" {
+			return nil, fmt.Errorf("attachments[%d]: missing file", i)
+		}
+		if filepath.IsAbs(a.File) || strings.HasPrefix(a.File, "/") {
+			return nil, fmt.Errorf("attachments[%d]: file %q must be relative to the post", i, a.File)
+		}
+		a.Path = filepath.Join(filepath.Dir(path), filepath.FromSlash(a.File))
+		if a.Title == "" {
+			a.Title = filepath.Base(a.Path)
+		}
+		files = append(files, a)
+	}
+	return files, nil
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// kindFigure is the AST node kind of figures.
+var kindFigure = ast.NewNodeKind("Figure")
+
+// figure is a captioned image, converted from a paragraph holding just an
+// image with a title.
+type figure struct {
+	ast.BaseBlock
+	caption []byte // The image's title
+}
+
+func (n *figure) Kind() ast.NodeKind { return kindFigure }
+
+func (n *figure) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Caption": string(n.caption)}, nil)
+}
+
+// figures is a goldmark extension rendering an image with a title, alone in
+// its paragraph:
+//
+//	![A heron](/images/heron.jpg "A heron at dawn")
+//
+// as a figure, its title as the caption:
+//
+//	<figure>
+//	<img src="/images/heron.jpg" alt="A heron" />
+//	<figcaption>A heron at dawn</figcaption>
+//	</figure>
+//
+// Images among other text keep their title attribute, since a <figure>
+// can't be inside a paragraph.
+type figures struct{}
+
+func (e figures) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(e, 100)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(e, 500)))
+}
+
+// Transform replaces paragraphs holding only a titled image by figures.
+func (e figures) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	var paras []*ast.Paragraph
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if para, ok := n.(*ast.Paragraph); ok && entering {
+			paras = append(paras, para)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, para := range paras {
+		img, ok := para.FirstChild().(*ast.Image)
+		if !ok || para.ChildCount() != 1 || len(img.Title) == 0 {
+			continue
+		}
+		node := &figure{caption: img.Title}
+		img.Title = nil
+		para.Parent().ReplaceChild(para.Parent(), para, node)
+		node.AppendChild(node, img)
+	}
+}
+
+func (e figures) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindFigure, e.render)
+}
+
+func (e figures) render(w util.BufWriter, _ []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString("<figure>\n")
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString("\n<figcaption>")
+	html.DefaultWriter.Write(w, n.(*figure).caption) // Resolves escapes and entities as in the title attribute
+	_, _ = w.WriteString("</figcaption>\n</figure>\n")
+	return ast.WalkContinue, nil
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"html/template"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MarkdownExtensions are the file extensions of markdown content unless
+// Options.MarkdownExtensions says otherwise.
+var MarkdownExtensions = []string{".md", ".markdown", ".mdown"}
+
+// ContentParser converts the body of a content file in a format other than
+// markdown, such as AsciiDoc or Org, to HTML. The frontmatter is YAML
+// between --- lines whatever the format, and is parsed before the body
+// reaches the ContentParser.
+//
+// Parsers are registered by file extension in Options.Formats; files with
+// any other extension are parsed as markdown.
+type ContentParser interface {
+	// Convert returns the HTML of body, the content of the file at path
+	// after its frontmatter.
+	Convert(body []byte, path string) (template.HTML, error)
+}
+
+// ContentParserFunc adapts a function to a ContentParser.
+type ContentParserFunc func(body []byte, path string) (template.HTML, error)
+
+func (f ContentParserFunc) Convert(body []byte, path string) (template.HTML, error) {
+	return f(body, path)
+}
+
+// Handles reports whether the file at path is content the parser can parse:
+// markdown, a Jupyter notebook, or a format with a ContentParser.
+func (p *Parser) Handles(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	_, ok := p.formats[ext]
+	return ok || slices.Contains(p.exts, ext) || (p.notebooks && ext == notebookExt)
+}
+
+// contentParser returns the parser registered for the extension of path, or
+// nil if it's markdown.
+func (p *Parser) contentParser(path string) ContentParser {
+	return p.formats[strings.ToLower(filepath.Ext(path))]
+}
+
+// normalizeExt returns ext lowercase and starting with a dot, so it can be
+// given as "adoc" or ".ADOC".
+func normalizeExt(ext string) string {
+	return "." + strings.TrimPrefix(strings.ToLower(ext), ".")
+}
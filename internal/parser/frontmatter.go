@@ -0,0 +1,222 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Reasons a post's frontmatter can't be read, wrapped in a FrontmatterError.
+var (
+	ErrNoFrontmatter       = errors.New("missing frontmatter: the file must start with a --- line")
+	ErrUnclosedFrontmatter = errors.New("malformed frontmatter: no --- line closes it")
+)
+
+// FrontmatterError is a post whose frontmatter is missing or malformed, with
+// where in the file the problem is.
+type FrontmatterError struct {
+	Path   string // File the post was parsed from
+	Line   int    // 1-based line in the file (0 if unknown)
+	Column int    // 1-based column in the line (0 if unknown)
+	Err    error  // ErrNoFrontmatter, ErrUnclosedFrontmatter, or the YAML error
+}
+
+// Error returns the problem prefixed with its position, e.g.
+// "content/posts/hello.md:3:7: malformed frontmatter: ...".
+func (e *FrontmatterError) Error() string {
+	return e.Position() + ": " + e.Reason()
+}
+
+// Position returns the file, line, and column of the problem as
+// "path:line:column", leaving out what's unknown.
+func (e *FrontmatterError) Position() string {
+	pos := e.Path
+	if e.Line > 0 {
+		pos += ":" + strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			pos += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	return pos
+}
+
+// Reason describes the problem without its position.
+func (e *FrontmatterError) Reason() string {
+	if errors.Is(e.Err, ErrNoFrontmatter) || errors.Is(e.Err, ErrUnclosedFrontmatter) {
+		return e.Err.Error()
+	}
+	return "malformed frontmatter: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *FrontmatterError) Unwrap() error {
+	return e.Err
+}
+
+// splitFrontmatter splits content into its YAML frontmatter, between the
+// first two "---" lines, and the markdown after it. Blank lines and a byte
+// order mark before the frontmatter are skipped. openLine is the line number
+// of the opening "---" (or where it should be, for ErrNoFrontmatter).
+func splitFrontmatter(content []byte) (fm, body []byte, openLine int, err error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	line := 1
+	for {
+		next, rest, ok := bytes.Cut(content, []byte("\n"))
+		if !ok || len(bytes.TrimSpace(next)) > 0 {
+			break
+		}
+		content = rest
+		line++
+	}
+
+	first, rest, _ := bytes.Cut(content, []byte("\n"))
+	if !isDelimiter(first) {
+		return nil, nil, line, ErrNoFrontmatter
+	}
+	openLine = line
+	for offset := 0; offset < len(rest) || offset == 0; {
+		next, after, found := bytes.Cut(rest[offset:], []byte("\n"))
+		if isDelimiter(next) {
+			return rest[:offset], after, openLine, nil
+		}
+		if !found {
+			break
+		}
+		offset += len(next) + 1
+	}
+	return nil, nil, openLine, ErrUnclosedFrontmatter
+}
+
+// isDelimiter reports whether line is a frontmatter delimiter: "---",
+// optionally followed by spaces or a carriage return.
+func isDelimiter(line []byte) bool {
+	return string(bytes.TrimRight(line, " \t\r")) == "---"
+}
+
+// yamlLine matches the line number yaml.v3 gives its errors, and the rest of
+// the message.
+var yamlLine = regexp.MustCompile(`line (\d+): (.*)`)
+
+// frontmatterYAMLError locates the YAML error err in the frontmatter fm of
+// the file at path, whose opening "---" is on openLine. yaml.v3 reports lines
+// relative to fm, and no columns, so the failing value is found by decoding
+// each field on its own, falling back to the last value on the line. This
+// also locates errors yaml.v3 gives no line for (e.g., "date: soon").
+func frontmatterYAMLError(path string, fm []byte, openLine int, err error) *FrontmatterError {
+	msg := err.Error()
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		msg = typeErr.Errors[0]
+	}
+	fe := &FrontmatterError{Path: path, Err: err}
+	var doc yaml.Node
+	parsed := yaml.Unmarshal(fm, &doc) == nil
+	if m := yamlLine.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		fe.Line = openLine + line
+		fe.Err = errors.New(m[2])
+		if !parsed {
+			return fe
+		}
+		if value := failingField(&doc); value != nil && value.Line == line {
+			fe.Column = value.Column
+		} else {
+			fe.Column = lastColumn(&doc, line)
+		}
+		return fe
+	}
+	if parsed {
+		if value := failingField(&doc); value != nil {
+			fe.Line = openLine + value.Line
+			fe.Column = value.Column
+		}
+	}
+	return fe
+}
+
+// failingField returns the value of the first top-level field of doc that
+// doesn't decode into a Frontmatter on its own, or nil if there's none.
+func failingField(doc *yaml.Node) *yaml.Node {
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	fields := doc.Content[0].Content
+	for i := 0; i+1 < len(fields); i += 2 {
+		one := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: fields[i : i+2]}
+		var fm Frontmatter
+		if one.Decode(&fm) != nil {
+			return fields[i+1]
+		}
+	}
+	return nil
+}
+
+// lastColumn returns the column of the last node of n's tree on line, or 0
+// if there's none.
+func lastColumn(n *yaml.Node, line int) int {
+	col := 0
+	if n.Line == line {
+		col = n.Column
+	}
+	for _, child := range n.Content {
+		if c := lastColumn(child, line); c > 0 {
+			col = c
+		}
+	}
+	return col
+}
+
+// Field is a top-level field of a post's frontmatter as written in its file,
+// for checks that need more than the parsed Post (see Fields).
+type Field struct {
+	Name   string
+	Value  string   // Value of a scalar field as written, e.g. "2024-01-15" ("" for lists, maps, and null)
+	Values []string // Scalar items of a list field, e.g. the tags
+	Set    bool     // The field has a value (it isn't null, "", or an empty list or map)
+	Line   int      // 1-based line of the field's value in the file
+	Column int      // 1-based column of the field's value in the line
+}
+
+// Fields returns the top-level fields of content's frontmatter, in the order
+// they're written. path is used in errors, which are as for Parse.
+func Fields(content []byte, path string) ([]Field, error) {
+	rawFM, _, openLine, err := splitFrontmatter(content)
+	if err != nil {
+		return nil, &FrontmatterError{Path: path, Line: openLine, Err: err}
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(rawFM, &doc); err != nil {
+		return nil, frontmatterYAMLError(path, rawFM, openLine, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	pairs := doc.Content[0].Content
+	fields := make([]Field, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		value := pairs[i+1]
+		f := Field{Name: pairs[i].Value, Line: openLine + value.Line, Column: value.Column}
+		switch value.Kind {
+		case yaml.ScalarNode:
+			if value.Tag != "!!null" {
+				f.Value = value.Value
+			}
+			f.Set = f.Value != ""
+		case yaml.SequenceNode:
+			for _, item := range value.Content {
+				if item.Kind == yaml.ScalarNode {
+					f.Values = append(f.Values, item.Value)
+				}
+			}
+			f.Set = len(value.Content) > 0
+		default:
+			f.Set = len(value.Content) > 0
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
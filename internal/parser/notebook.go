@@ -0,0 +1,328 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"gopkg.in/yaml.v3"
+)
+
+// notebookExt is the file extension of Jupyter notebooks.
+const notebookExt = ".ipynb"
+
+// notebook is a Jupyter notebook (nbformat 4), as much of it as posts need.
+type notebook struct {
+	Format   int            `json:"nbformat"`
+	Metadata map[string]any `json:"metadata"`
+	Cells    []notebookCell `json:"cells"`
+}
+
+// notebookCell is a cell of a notebook.
+type notebookCell struct {
+	Type        string                             `json:"cell_type"` // "markdown", "code", or "raw"
+	Source      notebookText                       `json:"source"`
+	Outputs     []notebookOutput                   `json:"outputs"`     // Of code cells
+	Attachments map[string]map[string]notebookText `json:"attachments"` // Images of markdown cells, by name, then MIME type
+}
+
+// notebookOutput is an output of a code cell.
+type notebookOutput struct {
+	Type      string                     `json:"output_type"` // "stream", "execute_result", "display_data", or "error"
+	Name      string                     `json:"name"`        // Stream the text was written to: "stdout" or "stderr"
+	Text      notebookText               `json:"text"`        // Of streams
+	Data      map[string]json.RawMessage `json:"data"`        // Of results and displays, by MIME type
+	EName     string                     `json:"ename"`       // Of errors, e.g. "ZeroDivisionError"
+	EValue    string                     `json:"evalue"`
+	Traceback []string                   `json:"traceback"`
+}
+
+// notebookText is text in a notebook, which is stored as a string or as a
+// list of lines.
+type notebookText string
+
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*t = notebookText(strings.Join(lines, ""))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = notebookText(s)
+	return nil
+}
+
+// notebookToolMetadata are the notebook metadata keys Jupyter and other
+// tools write, which aren't frontmatter.
+var notebookToolMetadata = []string{
+	"kernelspec", "language_info", "widgets", "vscode", "colab", "toc", "jupytext",
+	"papermill", "kaggle", "celltoolbar", "interpreter", "orig_nbformat",
+}
+
+// notebookDateFields are the frontmatter fields holding times, which
+// notebook metadata, being JSON, stores as strings.
+var notebookDateFields = []string{"date", "publishDate", "expiryDate"}
+
+// notebookImageTypes are the MIME types of images in outputs, in order of
+// preference.
+var notebookImageTypes = []string{"image/png", "image/jpeg", "image/gif", "image/svg+xml"}
+
+// ansiEscape matches the terminal color codes in tracebacks and output.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// notebookAttachment matches a reference to an image attached to a
+// markdown cell, like ![chart](attachment:chart.png).
+var notebookAttachment = regexp.MustCompile(`attachment:([^\s)"'>]+)`)
+
+// parseNotebook parses a Jupyter notebook into a post, so data-science posts
+// don't need exporting to markdown first:
+//   - Markdown cells are rendered like a markdown post's content, with their
+//     attached images inlined.
+//   - Code cells are rendered as code blocks highlighted in the notebook's
+//     language, each followed by its outputs in a <div class="notebook-output">:
+//     text as <pre>, images inlined as data URLs, HTML (if raw HTML is
+//     allowed, as for markdown), and errors' tracebacks.
+//   - Raw cells are left out.
+//
+// The frontmatter is the first cell, if it's a raw or markdown cell starting
+// with YAML between --- lines, as in a markdown file. Otherwise, it's the
+// notebook's metadata (Jupyter's own keys, like kernelspec, aside), e.g.
+// {"title": "...", "date": "2024-01-15", "tags": [...]}.
+//
+// RawContent is the notebook as markdown, without outputs.
+func (p *Parser) parseNotebook(content []byte, path string) (*Post, error) {
+	var nb notebook
+	if err := json.Unmarshal(content, &nb); err != nil {
+		return nil, fmt.Errorf("parsing notebook: %w", err)
+	}
+	if nb.Format != 0 && nb.Format < 4 {
+		return nil, fmt.Errorf("notebook is nbformat %d; only nbformat 4 is supported (convert it with jupyter nbconvert --to notebook)", nb.Format)
+	}
+
+	fm, cells, err := notebookFrontmatter(nb.Metadata, nb.Cells)
+	if err != nil {
+		return nil, err
+	}
+	md := p.markdown(fm)
+	lang := notebookLanguage(nb.Metadata)
+
+	// The outputs are rendered apart from the markdown, and put in place of
+	// placeholder paragraphs, so the cells are converted as one document
+	// with unique heading IDs whether or not raw HTML is allowed.
+	var doc, raw strings.Builder
+	var outputs []string
+	for _, cell := range cells {
+		source := strings.TrimSpace(string(cell.Source))
+		switch cell.Type {
+		case "markdown":
+			source = notebookAttachment.ReplaceAllStringFunc(source, func(ref string) string {
+				return notebookAttachmentURL(cell.Attachments, strings.TrimPrefix(ref, "attachment:"), ref)
+			})
+		case "code":
+			if source != "" {
+				fence := "```"
+				for strings.Contains(source, fence) {
+					fence += "`"
+				}
+				source = fence + lang + "\n" + source + "\n" + fence
+			}
+		default:
+			continue
+		}
+		if source != "" {
+			doc.WriteString(source + "\n\n")
+			raw.WriteString(source + "\n\n")
+		}
+		out, err := notebookOutputs(cell.Outputs, md == p.md, md)
+		if err != nil {
+			return nil, err
+		}
+		if out != "" {
+			fmt.Fprintf(&doc, "%s\n\n", notebookPlaceholder(len(outputs)))
+			outputs = append(outputs, out)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(doc.String()), &buf); err != nil {
+		return nil, fmt.Errorf("converting notebook: %w", err)
+	}
+	rendered := buf.String()
+	for i, out := range outputs {
+		rendered = strings.Replace(rendered, "<p>"+notebookPlaceholder(i)+"</p>\n", out, 1)
+	}
+	// #nosec G203 -- HTML output from goldmark md parser and escaped notebook outputs
+	return newPost(fm, path, template.HTML(rendered), strings.TrimSpace(raw.String()))
+}
+
+// notebookPlaceholder returns the paragraph text the ith cell output takes
+// the place of.
+func notebookPlaceholder(i int) string {
+	return fmt.Sprintf("SSGNOTEBOOKOUTPUT%d", i)
+}
+
+// notebookFrontmatter returns the frontmatter of a notebook with metadata
+// and cells (see parseNotebook), and the cells after the one it came from,
+// if any.
+func notebookFrontmatter(metadata map[string]any, cells []notebookCell) (Frontmatter, []notebookCell, error) {
+	var fm Frontmatter
+	if len(cells) > 0 && cells[0].Type != "code" {
+		if rawFM, body, _, err := splitFrontmatter([]byte(cells[0].Source)); err == nil {
+			if err := yaml.Unmarshal(rawFM, &fm); err != nil {
+				return fm, nil, fmt.Errorf("parsing the frontmatter in the notebook's first cell: %w", err)
+			}
+			rest := cells[1:]
+			if len(bytes.TrimSpace(body)) > 0 {
+				first := cells[0]
+				first.Source = notebookText(body)
+				rest = append([]notebookCell{first}, rest...)
+			}
+			return fm, rest, nil
+		}
+	}
+
+	fields := make(map[string]any, len(metadata))
+	for key, value := range metadata {
+		if slices.Contains(notebookToolMetadata, key) {
+			continue
+		}
+		if s, ok := value.(string); ok && slices.Contains(notebookDateFields, key) {
+			if t, ok := parseNotebookDate(s); ok {
+				value = t
+			}
+		}
+		fields[key] = value
+	}
+	if len(fields) == 0 {
+		return fm, cells, nil
+	}
+	data, err := yaml.Marshal(fields)
+	if err != nil {
+		return fm, nil, fmt.Errorf("reading notebook metadata: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &fm); err != nil {
+		return fm, nil, fmt.Errorf("reading notebook metadata: %w", err)
+	}
+	return fm, cells, nil
+}
+
+// parseNotebookDate parses a date in notebook metadata, written like a date
+// in YAML frontmatter.
+func parseNotebookDate(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// notebookLanguage returns the programming language of a notebook's code
+// cells, for highlighting, or "" if its metadata doesn't say.
+func notebookLanguage(metadata map[string]any) string {
+	for _, key := range [][2]string{{"language_info", "name"}, {"kernelspec", "language"}} {
+		if info, ok := metadata[key[0]].(map[string]any); ok {
+			if lang, ok := info[key[1]].(string); ok && lang != "" {
+				return strings.ToLower(lang)
+			}
+		}
+	}
+	return ""
+}
+
+// notebookAttachmentURL returns a data URL of the image attached to a cell
+// as name, or ref if it has none.
+func notebookAttachmentURL(attachments map[string]map[string]notebookText, name, ref string) string {
+	data := attachments[name]
+	for _, mime := range notebookImageTypes {
+		if s, ok := data[mime]; ok {
+			return notebookImageURL(mime, string(s))
+		}
+	}
+	return ref
+}
+
+// notebookImageURL returns a data URL of an image in a notebook: base64, or
+// text for SVG.
+func notebookImageURL(mime, data string) string {
+	if mime == "image/svg+xml" {
+		data = base64.StdEncoding.EncodeToString([]byte(data))
+	}
+	return "data:" + mime + ";base64," + strings.Join(strings.Fields(data), "")
+}
+
+// notebookOutputs renders the outputs of a code cell, or returns "" if it
+// has none to show. HTML outputs are only included if rawHTML is set, and
+// markdown outputs are converted with md.
+func notebookOutputs(outputs []notebookOutput, rawHTML bool, md goldmark.Markdown) (string, error) {
+	var b strings.Builder
+	for _, out := range outputs {
+		switch out.Type {
+		case "stream":
+			class := "notebook-stream"
+			if out.Name == "stderr" {
+				class += " notebook-stderr"
+			}
+			fmt.Fprintf(&b, "<pre class=\"%s\">%s</pre>\n", class, html.EscapeString(ansiEscape.ReplaceAllString(string(out.Text), "")))
+		case "error":
+			text := strings.Join(out.Traceback, "\n")
+			if text == "" {
+				text = out.EName + ": " + out.EValue
+			}
+			fmt.Fprintf(&b, "<pre class=\"notebook-error\">%s</pre>\n", html.EscapeString(ansiEscape.ReplaceAllString(text, "")))
+		case "execute_result", "display_data":
+			data, err := notebookData(out.Data, rawHTML, md)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(data)
+		}
+	}
+	if b.Len() == 0 {
+		return "", nil
+	}
+	return "<div class=\"notebook-output\">\n" + b.String() + "</div>\n", nil
+}
+
+// notebookData renders the richest representation of a result or display
+// it can: an image, HTML (if rawHTML is set), markdown, or plain text.
+func notebookData(data map[string]json.RawMessage, rawHTML bool, md goldmark.Markdown) (string, error) {
+	text := func(mime string) (string, bool) {
+		var t notebookText
+		if raw, ok := data[mime]; !ok || json.Unmarshal(raw, &t) != nil {
+			return "", false
+		}
+		return string(t), true
+	}
+
+	for _, mime := range notebookImageTypes {
+		if s, ok := text(mime); ok {
+			return fmt.Sprintf("<img src=\"%s\" alt=\"Cell output\">\n", notebookImageURL(mime, s)), nil
+		}
+	}
+	if s, ok := text("text/html"); ok && rawHTML {
+		return strings.TrimSpace(s) + "\n", nil
+	}
+	if s, ok := text("text/markdown"); ok {
+		var buf bytes.Buffer
+		if err := md.Convert([]byte(s), &buf); err != nil {
+			return "", fmt.Errorf("converting notebook output: %w", err)
+		}
+		return buf.String(), nil
+	}
+	if s, ok := text("text/plain"); ok {
+		return fmt.Sprintf("<pre class=\"notebook-result\">%s</pre>\n", html.EscapeString(ansiEscape.ReplaceAllString(s, ""))), nil
+	}
+	return "", nil
+}
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,73 +16,345 @@ import (
 	highlighting "github.com/yuin/goldmark-highlighting/v2"
 
 	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
 	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 	"gopkg.in/yaml.v3"
 )
 
 // Post represents a parsed markdown post with frontmatter
 type Post struct {
-	Title       string
-	Date        time.Time
-	Slug        string
-	Description string
-	Tags        []string
-	Keywords    string // Comma-separated string of tags
-	Draft       bool
-	Content     template.HTML // Unescaped HTML content
-	RawContent  string        // Original markdown
+	Title        string
+	Date         time.Time
+	PublishDate  time.Time // When the post goes live, if not its date (zero if unset)
+	ExpiryDate   time.Time // When the post is taken down (zero if never)
+	Lastmod      time.Time // When the post last changed: its Date, or the later date of the last commit changing it, set by the site generator
+	Contributors []string  // Names of the authors of the commits changing the post, first contribution first, set by the site generator
+	Slug         string
+	Description  string
+	Tags         []string
+	Aliases      []string  // Old site paths that redirect to the post
+	CommentsURL  string    // Comment thread of the post, e.g. a Mastodon status
+	CommentCount int       // Replies in the comment thread, fetched by the site generator
+	Mentions     []Mention // Webmentions of the post, oldest first, fetched by the site generator
+	WantsQRCode  *bool     // qrcode in the frontmatter, overriding the site's default (nil if unset)
+	QRCode       string    // Site-relative URL of the post's QR code image, set by the site generator ("" if none)
+	OGImage      string    // Site-relative URL of the post's social preview image, set by the site generator ("" if none)
+	Series       string    // Name of the series the post is part of ("" if none)
+	SeriesWeight int       // Position in the series from series_weight (0 if unset)
+	SeriesPart   int       // 1-based position in the series, set by the site generator
+	SeriesPrev   *Post     // Previous post in the series, set by the site generator (nil if first)
+	SeriesNext   *Post     // Next post in the series, set by the site generator (nil if last)
+	Prev         *Post     // Previous (older) post, set by the site generator (nil if the oldest)
+	Next         *Post     // Next (newer) post, set by the site generator (nil if the newest)
+	Lang         string    // Language of the post (BCP 47, e.g. "es"), from lang or its content directory ("" if unset)
+	Translation  string    // Key matching the post's translations, from translationKey ("" to match by slug)
+	Translations []*Post   // The post in the site's other languages, set by the site generator
+	Outputs      []string  // Formats from outputs in the frontmatter; the site generator replaces them with those written besides HTML
+	Keywords     string    // Comma-separated string of tags
+	Draft        bool
+	Content      template.HTML  // Unescaped HTML content
+	RawContent   string         // Original markdown
+	File         string         // Path of the file the post was parsed from, e.g. "content/posts/hello.md"
+	URL          string         // Site-relative URL, set by the site generator from its permalink config
+	Params       map[string]any // Unrecognized frontmatter keys, e.g. {{ .Post.Params.cover_image }}
+	Bundle       string         // Directory of the post's page bundle, holding its index file and resources, set by the site generator ("" if none)
+	Resources    []string       // Site-relative URLs of the files in the post's page bundle, set by the site generator
+	Attachments  []Attachment   // Files offered for download, from attachments in the frontmatter
+}
+
+// Mention is a webmention of a post from another site: a reply, like,
+// repost, bookmark, or plain link to it.
+type Mention struct {
+	Type      string    // "reply", "like", "repost", "bookmark", or "mention"
+	URL       string    // Page the mention is on
+	Author    string    // Name of its author
+	AuthorURL string    // Author's home page
+	Photo     string    // URL of the author's avatar
+	Content   string    // Text of a reply or mention ("" for likes and reposts)
+	Published time.Time // When it was published, or received if its page doesn't say
 }
 
 // Frontmatter represents the YAML frontmatter
 type Frontmatter struct {
-	Title       string    `yaml:"title"`
-	Date        time.Time `yaml:"date"`
-	Description string    `yaml:"description"`
-	Tags        []string  `yaml:"tags"`
-	Draft       bool      `yaml:"draft"`
+	Title          string    `yaml:"title"`
+	Date           time.Time `yaml:"date"`
+	PublishDate    time.Time `yaml:"publishDate"`    // Publish from this time instead of date
+	ExpiryDate     time.Time `yaml:"expiryDate"`     // Leave the post out of builds from this time
+	Slug           string    `yaml:"slug,omitempty"` // Overrides the slug derived from the filename
+	Description    string    `yaml:"description"`
+	Tags           []string  `yaml:"tags"`
+	Draft          bool      `yaml:"draft"`
+	Aliases        []string  `yaml:"aliases"`        // Old site paths to redirect here, e.g. after migrating
+	Comments       string    `yaml:"comments"`       // URL of the post's comment thread
+	QRCode         *bool     `yaml:"qrcode"`         // Generate a QR code of the post's URL (default: the site's setting)
+	RawHTML        *bool     `yaml:"rawHTML"`        // Pass raw HTML in the markdown through (default: the parser's setting)
+	Series         string    `yaml:"series"`         // Name of a series of posts the post is part of
+	SeriesWeight   int       `yaml:"series_weight"`  // Position in the series (default: after weighted posts, by date)
+	Lang           string    `yaml:"lang"`           // Language of the post, on multilingual sites (default: its content directory's, or the site's)
+	TranslationKey string    `yaml:"translationKey"` // Key shared by translations of a post (default: its slug)
+	Outputs        []string  `yaml:"outputs"`        // Formats to write the post in, e.g. [html, json, markdown] (default: the site's)
+
+	// Attachments are files offered for download (see Attachment).
+	Attachments []Attachment `yaml:"attachments"`
+
+	// Params collects any other keys, so custom fields like cover_image or
+	// gallery reach templates without changes to this struct.
+	Params map[string]any `yaml:",inline"`
 }
 
 // Parser handles markdown parsing with goldmark
 type Parser struct {
-	md goldmark.Markdown
+	md        goldmark.Markdown        // Passes raw HTML through
+	safe      goldmark.Markdown        // Omits raw HTML
+	rawHTML   bool                     // Pass raw HTML through unless a post's frontmatter says otherwise
+	exts      []string                 // Extensions of markdown files
+	formats   map[string]ContentParser // Parsers of other files, by extension
+	notebooks bool                     // Parse .ipynb files as Jupyter notebooks
+}
+
+// Options configures a Parser.
+type Options struct {
+	// NoRawHTML omits raw HTML blocks and inline tags from the rendered
+	// markdown (goldmark leaves an "<!-- raw HTML omitted -->" comment in
+	// their place), unless a post's frontmatter sets rawHTML: true.
+	NoRawHTML bool
+
+	NoHardWraps     bool   // Join lines of a paragraph with spaces instead of <br>
+	NoTypographer   bool   // Keep straight quotes, "--", and "..." as typed
+	NoTaskLists     bool   // Render "- [ ] item" as plain list items instead of checkboxes
+	HeadingIDPrefix string // Prepended to generated heading IDs, e.g. "h-" for "h-introduction"
+
+	FootnoteBacklink      string // HTML of the link back from a footnote (default: "↩︎")
+	FootnoteBacklinkTitle string // Title attribute of footnote backlinks
+	FootnoteLinkTitle     string // Title attribute of links to footnotes
+
+	// ExternalLinks opens links to other sites in a new tab, adding
+	// target="_blank" and rel="noopener noreferrer" (see externalLinker).
+	// Links to SiteHost, relative links, and links in raw HTML are left
+	// alone.
+	ExternalLinks     bool
+	ExternalLinkClass string // Class added to external links, e.g. for an icon
+	SiteHost          string // Host of the site's own URLs, e.g. "example.com"
+
+	// NoAdmonitions leaves GitHub-style alerts ("> [!NOTE]") as plain
+	// blockquotes instead of rendering them as admonitions (see admonitions).
+	NoAdmonitions        bool
+	AdmonitionClass      string // Class of admonitions, before their type (default: "admonition")
+	AdmonitionTitleClass string // Class of admonitions' titles (default: "admonition-title")
+
+	NoFigures bool // Keep images with a title as <img title=...> instead of captioned figures (see figures)
+
+	// NoNotebooks leaves .ipynb files alone instead of parsing them as
+	// Jupyter notebooks (see parseNotebook).
+	NoNotebooks bool
+
+	// MarkdownExtensions are the file extensions of markdown content
+	// (default: MarkdownExtensions).
+	MarkdownExtensions []string
+
+	// Formats are the parsers of content files that aren't markdown, by
+	// file extension, e.g. ".adoc" (see ContentParser).
+	Formats map[string]ContentParser
 }
 
 // New creates a new Parser with goldmark configured.
-//   - Extensions: GitHub Flavored, footnotes, smart punctuation
+//   - Extensions: GitHub Flavored, footnotes, smart punctuation, alerts as admonitions
+//   - Titled images alone in a paragraph as <figure> with a <figcaption>
 //   - Auto-generate heading ID's
 //   - newlines -> <br>
 //   - Syntax highlighting via https://github.com/alecthomas/chroma
 //   - Unsafe HTML rendering from within Markdown (don't use with user provided content)
 func New() *Parser {
-	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.GFM,         // GitHub Flavored Markdown
-			extension.Footnote,    // Footnote support
-			extension.Typographer, // Smart punctuation
-			highlighting.NewHighlighting( // Synax highlighting
-				highlighting.WithStyle("manni"),
-				highlighting.WithFormatOptions(
-					chromahtml.WithLineNumbers(true),
-					chromahtml.WrapLongLines(true),
-				),
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions creates a Parser configured like New, with the features
+// opts turns off or customizes. A post's frontmatter can override the raw
+// HTML default with rawHTML: true or rawHTML: false.
+func NewWithOptions(opts Options) *Parser {
+	exts := MarkdownExtensions
+	if len(opts.MarkdownExtensions) > 0 {
+		exts = make([]string, len(opts.MarkdownExtensions))
+		for i, ext := range opts.MarkdownExtensions {
+			exts[i] = normalizeExt(ext)
+		}
+	}
+	formats := make(map[string]ContentParser, len(opts.Formats))
+	for ext, cp := range opts.Formats {
+		formats[normalizeExt(ext)] = cp
+	}
+	return &Parser{
+		md:        newMarkdown(opts, true),
+		safe:      newMarkdown(opts, false),
+		rawHTML:   !opts.NoRawHTML,
+		exts:      exts,
+		formats:   formats,
+		notebooks: !opts.NoNotebooks,
+	}
+}
+
+// newMarkdown returns the goldmark configuration described on New, adjusted
+// by opts, with unsafe HTML rendering only if rawHTML is set.
+func newMarkdown(opts Options, rawHTML bool) goldmark.Markdown {
+	extensions := []goldmark.Extender{
+		// GitHub Flavored Markdown (extension.GFM), with task lists added below
+		extension.Linkify,
+		extension.Table,
+		extension.Strikethrough,
+		extension.NewFootnote(footnoteOptions(opts)...), // Footnote support
+		highlighting.NewHighlighting( // Synax highlighting
+			highlighting.WithStyle("manni"),
+			highlighting.WithFormatOptions(
+				chromahtml.WithLineNumbers(true),
+				chromahtml.WrapLongLines(true),
 			),
 		),
-		goldmark.WithParserOptions(
-			parser.WithAutoHeadingID(), // Auto-generate heading IDs
-		),
-		goldmark.WithRendererOptions(
-			html.WithHardWraps(), // Convert newlines to <br>
-			html.WithXHTML(),     // Use more strict XML-style tags
-			html.WithUnsafe(),
-		),
+	}
+	if !opts.NoTaskLists {
+		extensions = append(extensions, extension.TaskList)
+	}
+	if !opts.NoTypographer {
+		extensions = append(extensions, extension.Typographer) // Smart punctuation
+	}
+	if !opts.NoAdmonitions {
+		a := admonitions{class: opts.AdmonitionClass, titleClass: opts.AdmonitionTitleClass}
+		if a.class == "" {
+			a.class = "admonition"
+		}
+		if a.titleClass == "" {
+			a.titleClass = "admonition-title"
+		}
+		extensions = append(extensions, a)
+	}
+	if !opts.NoFigures {
+		extensions = append(extensions, figures{})
+	}
+
+	parserOptions := []parser.Option{
+		parser.WithAutoHeadingID(), // Auto-generate heading IDs
+	}
+	if opts.HeadingIDPrefix != "" {
+		parserOptions = append(parserOptions, parser.WithASTTransformers(
+			util.Prioritized(headingIDPrefixer{prefix: opts.HeadingIDPrefix}, 100),
+		))
+	}
+	if opts.ExternalLinks {
+		parserOptions = append(parserOptions, parser.WithASTTransformers(
+			util.Prioritized(externalLinker{siteHost: strings.ToLower(opts.SiteHost), class: opts.ExternalLinkClass}, 100),
+		))
+	}
+
+	rendererOptions := []renderer.Option{
+		html.WithXHTML(), // Use more strict XML-style tags
+	}
+	if !opts.NoHardWraps {
+		rendererOptions = append(rendererOptions, html.WithHardWraps()) // Convert newlines to <br>
+	}
+	if rawHTML {
+		rendererOptions = append(rendererOptions, html.WithUnsafe())
+	}
+
+	return goldmark.New(
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(parserOptions...),
+		goldmark.WithRendererOptions(rendererOptions...),
 	)
+}
 
-	return &Parser{md: md}
+// footnoteOptions returns the footnote extension's options for the labels
+// set in opts.
+func footnoteOptions(opts Options) []extension.FootnoteOption {
+	var options []extension.FootnoteOption
+	if opts.FootnoteBacklink != "" {
+		options = append(options, extension.WithFootnoteBacklinkHTML(opts.FootnoteBacklink))
+	}
+	if opts.FootnoteBacklinkTitle != "" {
+		options = append(options, extension.WithFootnoteBacklinkTitle(opts.FootnoteBacklinkTitle))
+	}
+	if opts.FootnoteLinkTitle != "" {
+		options = append(options, extension.WithFootnoteLinkTitle(opts.FootnoteLinkTitle))
+	}
+	return options
 }
 
-// ParseFile reads and parses a markdown file with YAML frontmatter.
+// headingIDPrefixer prepends a prefix to the IDs generated for headings,
+// so they can't clash with IDs used by the site's templates.
+type headingIDPrefixer struct {
+	prefix string
+}
+
+func (t headingIDPrefixer) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		if id, ok := heading.AttributeString("id"); ok {
+			if b, ok := id.([]byte); ok {
+				heading.SetAttributeString("id", append([]byte(t.prefix), b...))
+			}
+		}
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// externalLinker marks links and autolinks to other sites to open in a new
+// tab, without giving the opened page access to the site (noopener) or
+// telling it where the visitor came from (noreferrer).
+type externalLinker struct {
+	siteHost string // Lowercase host of the site's own URLs
+	class    string // Class added to external links, if set
+}
+
+func (t externalLinker) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	source := reader.Source()
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var dest []byte
+		switch link := n.(type) {
+		case *ast.Link:
+			dest = link.Destination
+		case *ast.AutoLink:
+			if link.AutoLinkType != ast.AutoLinkURL {
+				return ast.WalkContinue, nil
+			}
+			dest = link.URL(source)
+		default:
+			return ast.WalkContinue, nil
+		}
+		if !t.external(string(dest)) {
+			return ast.WalkContinue, nil
+		}
+		n.SetAttributeString("target", []byte("_blank"))
+		n.SetAttributeString("rel", []byte("noopener noreferrer"))
+		if t.class != "" {
+			n.SetAttributeString("class", []byte(t.class))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// external reports whether dest is a web URL on another host than the
+// site's. Relative links, fragments, and mailto: links aren't.
+func (t externalLinker) external(dest string) bool {
+	u, err := url.Parse(dest)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	return strings.ToLower(u.Hostname()) != t.siteHost
+}
+
+// ParseFile reads and parses a content file with YAML frontmatter.
 //
 // This is the main entry point for parsing posts. It reads the file from disk
 // and delegates to Parse() for the actual parsing logic.
@@ -115,61 +388,166 @@ func (p *Parser) ParseFile(path string) (*Post, error) {
 //	Markdown content here...
 //
 // Process:
-//  1. Splits content on "---" delimiters to extract frontmatter
+//  1. Extracts the frontmatter between the first two "---" lines
 //  2. Parses YAML frontmatter into structured data
-//  3. Converts markdown to HTML using goldmark (with GFM, footnotes, etc.)
-//  4. Generates a URL-friendly slug from the filename
-//  5. Returns a Post struct with both HTML (Content) and original markdown (RawContent)
+//  3. Converts markdown to HTML using goldmark (with GFM, footnotes, etc.),
+//     or other formats with the ContentParser for path's extension
+//  4. Generates a URL-friendly slug from the filename, unless the frontmatter
+//     sets slug
+//  5. Uses the filename's date prefix as the date if the frontmatter has none
+//  6. Returns a Post struct with both HTML (Content) and original markdown (RawContent),
+//     and any unrecognized frontmatter keys in Params
+//
+// Jupyter notebooks (.ipynb files) are parsed by parseNotebook instead.
 //
 // Parameters:
 //   - content: Raw file content as bytes
-//   - path: File path (used only for slug generation)
+//   - path: File path (used for the default slug and date, and in errors)
 //
-// Returns a Post struct or an error if parsing fails.
+// Returns a Post struct or an error if parsing fails. Errors in the
+// frontmatter are *FrontmatterError, giving the file, line, and column, and
+// telling missing frontmatter (ErrNoFrontmatter) from malformed frontmatter.
 func (p *Parser) Parse(content []byte, path string) (*Post, error) {
+	if p.notebooks && strings.EqualFold(filepath.Ext(path), notebookExt) {
+		return p.parseNotebook(content, path)
+	}
+
 	// Split frontmatter and content
-	parts := bytes.SplitN(content, []byte("---"), 3)
-	if len(parts) < 3 {
-		return nil, fmt.Errorf("invalid frontmatter format")
+	rawFM, body, openLine, err := splitFrontmatter(content)
+	if err != nil {
+		return nil, &FrontmatterError{Path: path, Line: openLine, Err: err}
 	}
 
 	// Parse frontmatter
 	var fm Frontmatter
-	if err := yaml.Unmarshal(parts[1], &fm); err != nil {
-		return nil, fmt.Errorf("parsing frontmatter: %w", err)
+	if err := yaml.Unmarshal(rawFM, &fm); err != nil {
+		return nil, frontmatterYAMLError(path, rawFM, openLine, err)
 	}
 
-	// Parse markdown content
-	var buf bytes.Buffer
-	markdown := bytes.TrimSpace(parts[2])
-	if err := p.md.Convert(markdown, &buf); err != nil {
-		return nil, fmt.Errorf("converting markdown: %w", err)
+	// Parse markdown content, or content in the format of path's extension
+	var rendered template.HTML
+	markdown := bytes.TrimSpace(body)
+	if cp := p.contentParser(path); cp != nil {
+		rendered, err = cp.Convert(markdown, path)
+		if err != nil {
+			return nil, fmt.Errorf("converting %s: %w", strings.TrimPrefix(filepath.Ext(path), "."), err)
+		}
+	} else {
+		var buf bytes.Buffer
+		if err := p.markdown(fm).Convert(markdown, &buf); err != nil {
+			return nil, fmt.Errorf("converting markdown: %w", err)
+		}
+		// #nosec G203 -- HTML output from goldmark md parser, not from user input
+		rendered = template.HTML(buf.String())
 	}
 
-	// Generate slug from filename
-	slug := generateSlug(path)
+	return newPost(fm, path, rendered, string(markdown))
+}
 
-	post := &Post{
-		Title:       fm.Title,
-		Date:        fm.Date,
-		Slug:        slug,
-		Description: fm.Description,
-		Tags:        fm.Tags,
-		Keywords:    strings.Join(fm.Tags, ", "),
+// markdown returns the goldmark configuration for a post with frontmatter
+// fm: the one passing raw HTML through if the parser does, unless fm says
+// otherwise.
+func (p *Parser) markdown(fm Frontmatter) goldmark.Markdown {
+	rawHTML := p.rawHTML
+	if fm.RawHTML != nil {
+		rawHTML = *fm.RawHTML
+	}
+	if rawHTML {
+		return p.md
+	}
+	return p.safe
+}
 
-		Draft: fm.Draft,
-		// #nosec G203 -- HTML output from goldmark md parser, not from user input
-		Content:    template.HTML(buf.String()),
-		RawContent: string(markdown),
+// newPost returns the post parsed from the file at path, with frontmatter
+// fm, its content rendered as HTML, and raw as its source. Returns an error
+// if fm's attachments are invalid.
+func newPost(fm Frontmatter, path string, rendered template.HTML, raw string) (*Post, error) {
+	files, err := attachments(fm, path)
+	if err != nil {
+		return nil, err
 	}
 
-	return post, nil
+	// Generate slug from filename unless the frontmatter sets one
+	slug := fm.Slug
+	if slug == "" {
+		slug = generateSlug(path)
+	}
+
+	// Fall back to the filename's date prefix if the frontmatter has no date
+	date := fm.Date
+	if date.IsZero() {
+		date = filenameDate(path)
+	}
+
+	return &Post{
+		Title:        fm.Title,
+		Date:         date,
+		Lastmod:      date,
+		PublishDate:  fm.PublishDate,
+		ExpiryDate:   fm.ExpiryDate,
+		Slug:         slug,
+		Description:  fm.Description,
+		Tags:         fm.Tags,
+		Aliases:      fm.Aliases,
+		CommentsURL:  fm.Comments,
+		WantsQRCode:  fm.QRCode,
+		Series:       strings.TrimSpace(fm.Series),
+		SeriesWeight: fm.SeriesWeight,
+		Lang:         strings.TrimSpace(fm.Lang),
+		Translation:  strings.TrimSpace(fm.TranslationKey),
+		Outputs:      fm.Outputs,
+		Keywords:     strings.Join(fm.Tags, ", "),
+
+		Draft:       fm.Draft,
+		Content:     rendered,
+		RawContent:  raw,
+		File:        path,
+		Params:      fm.Params,
+		Attachments: files,
+	}, nil
+}
+
+// Markdownify converts a markdown snippet (without frontmatter) to HTML using
+// the same goldmark configuration as posts, including the parser's raw HTML
+// setting.
+//
+// This backs the markdownify template function, so templates can render
+// markdown stored in config or frontmatter fields.
+//
+// Returns the rendered HTML or an error if conversion fails.
+func (p *Parser) Markdownify(markdown string) (template.HTML, error) {
+	md := p.safe
+	if p.rawHTML {
+		md = p.md
+	}
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(markdown), &buf); err != nil {
+		return "", fmt.Errorf("converting markdown: %w", err)
+	}
+	// #nosec G203 -- HTML output from goldmark md parser, not from user input
+	return template.HTML(buf.String()), nil
+}
+
+// filenameDate returns the date in a "YYYY-MM-DD-" filename prefix, or the
+// zero time if the filename has no valid date prefix. The name of a page
+// bundle's index file is its directory's (see contentName).
+func filenameDate(path string) time.Time {
+	filename := contentName(path)
+	if len(filename) < 11 || filename[10] != '-' {
+		return time.Time{}
+	}
+	date, err := time.Parse("2006-01-02", filename[:10])
+	if err != nil {
+		return time.Time{}
+	}
+	return date
 }
 
 // generateSlug creates a URL-friendly slug from a file path. It extracts the
 // filename, removes the extension, and strips the date prefix if present.
 //
-// For example: "content/posts/2024-01-15-my-first-post.md" → "my-first-post"
+// For example: "content/posts/2024-01-15-my-first-post.md" → "my-first-post",
+// and for a page bundle, "content/posts/2024-01-15-trip/index.md" → "trip"
 //
 // This slug is used in the final URL: /posts/my-first-post.html
 //
@@ -178,12 +556,24 @@ func (p *Parser) Parse(content []byte, path string) (*Post, error) {
 //
 // Returns the slug string.
 func generateSlug(path string) string {
-	filename := filepath.Base(path)
-	// Remove extension
-	slug := strings.TrimSuffix(filename, filepath.Ext(filename))
+	slug := contentName(path)
 	// Remove date prefix if present (YYYY-MM-DD-)
 	if len(slug) > 11 && slug[4] == '-' && slug[7] == '-' && slug[10] == '-' {
 		slug = slug[11:]
 	}
 	return slug
 }
+
+// contentName returns the name a content file's slug and date come from: its
+// filename without the extension, or for the index file of a page bundle
+// (e.g., "content/posts/trip/index.md"), the bundle's directory.
+func contentName(path string) string {
+	filename := filepath.Base(path)
+	name := strings.TrimSuffix(filename, filepath.Ext(filename))
+	if name == "index" {
+		if dir := filepath.Base(filepath.Dir(path)); dir != "." && dir != string(filepath.Separator) {
+			return dir
+		}
+	}
+	return name
+}
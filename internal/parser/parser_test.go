@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"errors"
+	"html/template"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -126,22 +129,45 @@ func TestParse_InvalidFrontmatter(t *testing.T) {
 	tests := []struct {
 		name    string
 		content string
+		want    string // Expected error message
+		wantErr error  // Expected wrapped error, if any
 	}{
 		{
 			name:    "no frontmatter delimiters",
 			content: "This is just markdown content",
+			want:    "test.md:1: missing frontmatter: the file must start with a --- line",
+			wantErr: ErrNoFrontmatter,
+		},
+		{
+			name:    "delimiter after blank lines",
+			content: "\n\nTitle\n---\ntitle: Test\n---\n",
+			want:    "test.md:3: missing frontmatter: the file must start with a --- line",
+			wantErr: ErrNoFrontmatter,
 		},
 		{
 			name:    "single delimiter only",
 			content: "---\ntitle: Test\n",
+			want:    "test.md:1: malformed frontmatter: no --- line closes it",
+			wantErr: ErrUnclosedFrontmatter,
 		},
 		{
 			name: "invalid YAML",
 			content: `---
 title: Test
-invalid yaml here: [unclosed
+invalid: yaml: here
 ---
 Content`,
+			want: "test.md:3: malformed frontmatter: mapping values are not allowed in this context",
+		},
+		{
+			name:    "wrong type",
+			content: "---\ntitle: Test\ndate: soon\n---\nContent",
+			want:    `test.md:3:7: malformed frontmatter: parsing time "soon" as "2006-01-02T15:04:05Z07:00": cannot parse "soon" as "2006"`,
+		},
+		{
+			name:    "wrong kind",
+			content: "---\ntitle: Test\ntags: {a: b}\n---\nContent",
+			want:    "test.md:3:7: malformed frontmatter: cannot unmarshal !!map into []string",
 		},
 	}
 
@@ -150,12 +176,38 @@ Content`,
 		t.Run(tt.name, func(t *testing.T) {
 			_, err := p.Parse([]byte(tt.content), "test.md")
 			if err == nil {
-				t.Error("Parse() succeeded, want error")
+				t.Fatal("Parse() succeeded, want error")
+			}
+			var fmErr *FrontmatterError
+			if !errors.As(err, &fmErr) {
+				t.Fatalf("Parse() error = %T, want *FrontmatterError", err)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Parse() error = %q, want %q", err, tt.want)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse() error doesn't wrap %v", tt.wantErr)
 			}
 		})
 	}
 }
 
+// TestParse_DelimiterInContent tests that "---" in the markdown (a thematic
+// break) doesn't end the frontmatter early or get taken for it
+func TestParse_DelimiterInContent(t *testing.T) {
+	content := "---\ntitle: Dashes --- in title\ndate: 2024-01-15T10:00:00Z\n---\n\nAbove\n\n---\n\nBelow\n"
+	post, err := New().Parse([]byte(content), "test.md")
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if post.Title != "Dashes --- in title" {
+		t.Errorf("Title = %q, want %q", post.Title, "Dashes --- in title")
+	}
+	if !strings.Contains(string(post.Content), "<hr") || !strings.Contains(string(post.Content), "Below") {
+		t.Errorf("Content = %q, want the thematic break and text after it", post.Content)
+	}
+}
+
 // TestParse_EmptyTags tests parsing with no tags
 func TestParse_EmptyTags(t *testing.T) {
 	p := New()
@@ -336,6 +388,97 @@ func TestGenerateSlug(t *testing.T) {
 	}
 }
 
+// TestParse_SlugAndDateFallbacks tests frontmatter slugs and filename dates
+func TestParse_SlugAndDateFallbacks(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		path     string
+		wantSlug string
+		wantDate string
+	}{
+		{
+			name:     "slug from frontmatter",
+			content:  "---\ntitle: Test\ndate: 2024-01-15T10:00:00Z\nslug: custom\n---\nContent",
+			path:     "2024-01-15-original.md",
+			wantSlug: "custom",
+			wantDate: "2024-01-15",
+		},
+		{
+			name:     "date from filename",
+			content:  "---\ntitle: Test\n---\nContent",
+			path:     "content/posts/2023-06-30-dated.md",
+			wantSlug: "dated",
+			wantDate: "2023-06-30",
+		},
+		{
+			name:     "frontmatter date wins",
+			content:  "---\ntitle: Test\ndate: 2024-02-01T00:00:00Z\n---\nContent",
+			path:     "2023-06-30-dated.md",
+			wantSlug: "dated",
+			wantDate: "2024-02-01",
+		},
+		{
+			name:     "no date anywhere",
+			content:  "---\ntitle: Test\n---\nContent",
+			path:     "2023-13-45-invalid.md",
+			wantSlug: "invalid",
+			wantDate: "0001-01-01",
+		},
+	}
+
+	p := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post, err := p.Parse([]byte(tt.content), tt.path)
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			if post.Slug != tt.wantSlug {
+				t.Errorf("Slug = %q, want %q", post.Slug, tt.wantSlug)
+			}
+			if got := post.Date.Format("2006-01-02"); got != tt.wantDate {
+				t.Errorf("Date = %s, want %s", got, tt.wantDate)
+			}
+		})
+	}
+}
+
+// TestParse_Params tests that unrecognized frontmatter keys are kept in Params
+func TestParse_Params(t *testing.T) {
+	content := "---\ntitle: Test\ndate: 2024-01-15T10:00:00Z\ncover_image: /images/cover.jpg\ngallery:\n  name: Go\n  part: 2\naliases: [/old/test/]\nqrcode: false\nseries: Learning Go\nseries_weight: 3\nlang: es\ntranslationKey: hello\n---\nContent"
+	post, err := New().Parse([]byte(content), "test.md")
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	if post.Params["cover_image"] != "/images/cover.jpg" {
+		t.Errorf("Params[cover_image] = %v, want %q", post.Params["cover_image"], "/images/cover.jpg")
+	}
+	gallery, ok := post.Params["gallery"].(map[string]any)
+	if !ok || gallery["name"] != "Go" || gallery["part"] != 2 {
+		t.Errorf("Params[gallery] = %#v, want nested map", post.Params["gallery"])
+	}
+	if _, ok := post.Params["title"]; ok {
+		t.Error("Params contains a known field (title)")
+	}
+	if len(post.Aliases) != 1 || post.Aliases[0] != "/old/test/" {
+		t.Errorf("Aliases = %v, want [/old/test/]", post.Aliases)
+	}
+	if post.WantsQRCode == nil || *post.WantsQRCode {
+		t.Errorf("WantsQRCode = %v, want false", post.WantsQRCode)
+	}
+	if post.Series != "Learning Go" || post.SeriesWeight != 3 {
+		t.Errorf("Series = %q, %d, want \"Learning Go\", 3", post.Series, post.SeriesWeight)
+	}
+	if _, ok := post.Params["series_weight"]; ok {
+		t.Error("Params contains a known field (series_weight)")
+	}
+	if post.Lang != "es" || post.Translation != "hello" {
+		t.Errorf("Lang, Translation = %q, %q, want es, hello", post.Lang, post.Translation)
+	}
+}
+
 // TestParse_MissingRequiredFields tests parsing with missing required fields
 func TestParse_MissingRequiredFields(t *testing.T) {
 	tests := []struct {
@@ -444,3 +587,386 @@ func main() {
 		}
 	}
 }
+
+// TestMarkdownify tests converting markdown snippets without frontmatter
+func TestMarkdownify(t *testing.T) {
+	p := New()
+	html, err := p.Markdownify("Hello **world**")
+	if err != nil {
+		t.Fatalf("Markdownify() failed: %v", err)
+	}
+	if !strings.Contains(string(html), "<strong>world</strong>") {
+		t.Errorf("Markdownify() = %q, want bold text", html)
+	}
+}
+
+// TestParse_RawHTML tests that raw HTML passes through or is omitted as the
+// parser and frontmatter say
+func TestParse_RawHTML(t *testing.T) {
+	body := "\n---\n\n<div class=\"embed\">Video</div>\n\nText with <kbd>Ctrl</kbd>.\n"
+	tests := []struct {
+		name    string
+		opts    Options
+		fm      string
+		wantRaw bool
+	}{
+		{"default", Options{}, "", true},
+		{"disabled", Options{NoRawHTML: true}, "", false},
+		{"post opts in", Options{NoRawHTML: true}, "rawHTML: true\n", true},
+		{"post opts out", Options{}, "rawHTML: false\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "---\ntitle: Test\n" + tt.fm + body
+			post, err := NewWithOptions(tt.opts).Parse([]byte(content), "test.md")
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			html := string(post.Content)
+			for _, raw := range []string{`<div class="embed">`, "<kbd>Ctrl</kbd>"} {
+				if got := strings.Contains(html, raw); got != tt.wantRaw {
+					t.Errorf("Content contains %s = %v, want %v\n%s", raw, got, tt.wantRaw, html)
+				}
+			}
+			if !tt.wantRaw && !strings.Contains(html, "raw HTML omitted") {
+				t.Errorf("Content = %q, want raw HTML omitted", html)
+			}
+			if _, ok := post.Params["rawHTML"]; ok {
+				t.Error("Params contains rawHTML")
+			}
+		})
+	}
+}
+
+// TestNewWithOptions tests turning off and customizing markdown features
+func TestNewWithOptions(t *testing.T) {
+	content := "---\ntitle: Test\n---\n\n## Intro\n\n\"Quoted\" -- line one\nline two[^1]\n\n- [x] done\n\n[^1]: A note.\n"
+	tests := []struct {
+		name   string
+		opts   Options
+		want   []string
+		reject []string
+	}{
+		{
+			name: "defaults",
+			want: []string{`<h2 id="intro">`, "&ldquo;Quoted&rdquo;", "line one<br />", `type="checkbox"`, "&#x21a9;&#xfe0e;"},
+		},
+		{
+			name: "customized",
+			opts: Options{
+				NoHardWraps:           true,
+				NoTypographer:         true,
+				NoTaskLists:           true,
+				HeadingIDPrefix:       "h-",
+				FootnoteBacklink:      "back",
+				FootnoteBacklinkTitle: "Back to text",
+				FootnoteLinkTitle:     "See note",
+			},
+			want:   []string{`<h2 id="h-intro">`, "&quot;Quoted&quot; --", "line one\nline two", "[x] done", ">back</a>", `title="Back to text"`, `title="See note"`},
+			reject: []string{"&ldquo;", "<br />", `type="checkbox"`, "&#x21a9;"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post, err := NewWithOptions(tt.opts).Parse([]byte(content), "test.md")
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			html := string(post.Content)
+			for _, s := range tt.want {
+				if !strings.Contains(html, s) {
+					t.Errorf("Content missing %q:\n%s", s, html)
+				}
+			}
+			for _, s := range tt.reject {
+				if strings.Contains(html, s) {
+					t.Errorf("Content contains %q:\n%s", s, html)
+				}
+			}
+		})
+	}
+}
+
+// TestParse_ExternalLinks tests that only links to other sites open in a new
+// tab
+func TestParse_ExternalLinks(t *testing.T) {
+	content := "---\ntitle: Test\n---\n\n" +
+		"[other](https://other.com/page) [own](https://example.com/about.html) [relative](/posts/a.html) " +
+		"[mail](mailto:me@example.com) [anchor](#top) https://auto.org/x www.bare.net\n"
+	p := NewWithOptions(Options{ExternalLinks: true, ExternalLinkClass: "external", SiteHost: "Example.com"})
+	post, err := p.Parse([]byte(content), "test.md")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	html := string(post.Content)
+
+	decorated := ` target="_blank" rel="noopener noreferrer" class="external">`
+	for _, href := range []string{"https://other.com/page", "https://auto.org/x", "http://www.bare.net"} {
+		if !strings.Contains(html, `<a href="`+href+`"`+decorated) {
+			t.Errorf("link to %s isn't external:\n%s", href, html)
+		}
+	}
+	for _, href := range []string{"https://example.com/about.html", "/posts/a.html", "mailto:me@example.com", "#top"} {
+		if !strings.Contains(html, `<a href="`+href+`">`) {
+			t.Errorf("link to %s was changed:\n%s", href, html)
+		}
+	}
+
+	post, err = New().Parse([]byte(content), "test.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(post.Content), "_blank") {
+		t.Errorf("links changed without ExternalLinks:\n%s", post.Content)
+	}
+}
+
+// TestFields tests that fields are returned as written, with their positions
+func TestFields(t *testing.T) {
+	content := "\n---\ntitle: Hello\ndate: 2024-01-15\ntags: [go, web]\ndescription:\nparams: {a: b}\n---\nBody\n"
+	fields, err := Fields([]byte(content), "test.md")
+	if err != nil {
+		t.Fatalf("Fields() failed: %v", err)
+	}
+	want := []Field{
+		{Name: "title", Value: "Hello", Set: true, Line: 3, Column: 8},
+		{Name: "date", Value: "2024-01-15", Set: true, Line: 4, Column: 7},
+		{Name: "tags", Values: []string{"go", "web"}, Set: true, Line: 5, Column: 7},
+		{Name: "description", Line: 6, Column: 13},
+		{Name: "params", Set: true, Line: 7, Column: 9},
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("Fields() = %+v, want %+v", fields, want)
+	}
+	for i := range want {
+		f, w := fields[i], want[i]
+		if f.Name != w.Name || f.Value != w.Value || strings.Join(f.Values, ",") != strings.Join(w.Values, ",") ||
+			f.Set != w.Set || f.Line != w.Line || f.Column != w.Column {
+			t.Errorf("field %d = %+v, want %+v", i, f, w)
+		}
+	}
+
+	if _, err := Fields([]byte("no frontmatter"), "test.md"); !errors.Is(err, ErrNoFrontmatter) {
+		t.Errorf("Fields() error = %v, want ErrNoFrontmatter", err)
+	}
+}
+
+// TestParse_Admonitions tests that GitHub-style alerts render as admonitions
+func TestParse_Admonitions(t *testing.T) {
+	content := "---\ntitle: Test\n---\n\n> [!NOTE]\n> Read *this*.\n\n> [!warning]\n>\n> First.\n>\n> Second.\n\n> [!NOTE] inline\n\n> Plain quote\n"
+	post, err := New().Parse([]byte(content), "test.md")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	html := string(post.Content)
+	for _, want := range []string{
+		"<div class=\"admonition note\">\n<p class=\"admonition-title\">Note</p>\n<p>Read <em>this</em>.</p>\n</div>",
+		"<div class=\"admonition warning\">\n<p class=\"admonition-title\">Warning</p>\n<p>First.</p>\n<p>Second.</p>\n</div>",
+		"<blockquote>\n<p>[!NOTE] inline</p>\n</blockquote>",
+		"<blockquote>\n<p>Plain quote</p>\n</blockquote>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Content missing %q:\n%s", want, html)
+		}
+	}
+
+	post, err = NewWithOptions(Options{AdmonitionClass: "callout", AdmonitionTitleClass: "callout-title"}).Parse([]byte(content), "test.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(post.Content), `<div class="callout note">`+"\n"+`<p class="callout-title">Note</p>`) {
+		t.Errorf("custom classes not used:\n%s", post.Content)
+	}
+
+	post, err = NewWithOptions(Options{NoAdmonitions: true}).Parse([]byte(content), "test.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(post.Content), "admonition") || !strings.Contains(string(post.Content), "[!NOTE]") {
+		t.Errorf("alerts converted with NoAdmonitions:\n%s", post.Content)
+	}
+}
+
+// TestParse_Figures tests that titled images on their own render as captioned
+// figures
+func TestParse_Figures(t *testing.T) {
+	content := "---\ntitle: Test\n---\n\n![A heron](/heron.jpg \"A heron &amp; friend\")\n\nText ![inline](/a.jpg \"Inline\") more\n\n![untitled](/b.jpg)\n"
+	post, err := New().Parse([]byte(content), "test.md")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	html := string(post.Content)
+	for _, want := range []string{
+		"<figure>\n<img src=\"/heron.jpg\" alt=\"A heron\" />\n<figcaption>A heron &amp; friend</figcaption>\n</figure>",
+		`<p>Text <img src="/a.jpg" alt="inline" title="Inline" /> more</p>`,
+		`<p><img src="/b.jpg" alt="untitled" /></p>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Content missing %q:\n%s", want, html)
+		}
+	}
+
+	post, err = NewWithOptions(Options{NoFigures: true}).Parse([]byte(content), "test.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(post.Content), "<figure>") {
+		t.Errorf("figure rendered with NoFigures:\n%s", post.Content)
+	}
+}
+
+// TestParse_Formats tests parsing content in formats other than markdown
+func TestParse_Formats(t *testing.T) {
+	p := NewWithOptions(Options{Formats: map[string]ContentParser{
+		"adoc": ContentParserFunc(func(body []byte, path string) (template.HTML, error) {
+			return template.HTML("<pre>" + path + ": " + string(body) + "</pre>"), nil
+		}),
+	}})
+	post, err := p.Parse([]byte("---\ntitle: Doc\n---\n\n= Heading\n"), "2024-01-15-doc.ADOC")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if want := "<pre>2024-01-15-doc.ADOC: = Heading</pre>"; string(post.Content) != want {
+		t.Errorf("Content = %q, want %q", post.Content, want)
+	}
+	if post.Title != "Doc" || post.Slug != "doc" || post.RawContent != "= Heading" {
+		t.Errorf("Parse() = %q, %q, %q; want the frontmatter, slug, and body", post.Title, post.Slug, post.RawContent)
+	}
+
+	for path, want := range map[string]bool{"a.md": true, "a.markdown": true, "a.mdown": true, "a.adoc": true, "a.org": false, "a.txt": false} {
+		if got := p.Handles(path); got != want {
+			t.Errorf("Handles(%q) = %v, want %v", path, got, want)
+		}
+	}
+	if NewWithOptions(Options{MarkdownExtensions: []string{"txt"}}).Handles("a.md") {
+		t.Error("Handles(\"a.md\") with MarkdownExtensions: [txt] = true, want false")
+	}
+}
+
+// TestParse_Notebook tests parsing Jupyter notebooks
+func TestParse_Notebook(t *testing.T) {
+	nb := `{
+ "nbformat": 4,
+ "metadata": {
+  "title": "Plotting",
+  "date": "2024-02-01",
+  "tags": ["python", "data"],
+  "cover": "/plot.png",
+  "kernelspec": {"name": "python3", "language": "python"},
+  "language_info": {"name": "python"}
+ },
+ "cells": [
+  {"cell_type": "markdown", "source": ["# Setup\n", "See ![logo](attachment:logo.png)."],
+   "attachments": {"logo.png": {"image/png": "iVBORw0K\nGgo="}}},
+  {"cell_type": "code", "source": "print(1 < 2)\n1/0", "outputs": [
+   {"output_type": "stream", "name": "stdout", "text": ["True\n"]},
+   {"output_type": "error", "ename": "ZeroDivisionError", "evalue": "division by zero",
+    "traceback": ["\u001b[0;31mZeroDivisionError\u001b[0m: division by zero"]}
+  ]},
+  {"cell_type": "code", "source": "df", "outputs": [
+   {"output_type": "execute_result", "data": {"text/plain": ["   a\n", "0  1"], "text/html": "<table><tr><td>1</td></tr></table>"}},
+   {"output_type": "display_data", "data": {"image/png": "iVBORw0KGgo=\n", "text/plain": "<Figure>"}}
+  ]},
+  {"cell_type": "raw", "source": "left out"},
+  {"cell_type": "markdown", "source": "# Setup"}
+ ]
+}`
+	post, err := New().Parse([]byte(nb), "content/posts/plotting.ipynb")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if post.Title != "Plotting" || post.Slug != "plotting" || post.Date.Format("2006-01-02") != "2024-02-01" || !slices.Equal(post.Tags, []string{"python", "data"}) {
+		t.Errorf("Parse() frontmatter = %q, %q, %v, %v", post.Title, post.Slug, post.Date, post.Tags)
+	}
+	if post.Params["cover"] != "/plot.png" || post.Params["kernelspec"] != nil {
+		t.Errorf("Params = %v, want cover and no kernelspec", post.Params)
+	}
+
+	html := string(post.Content)
+	for _, want := range []string{
+		`<h1 id="setup">Setup</h1>`,
+		`<img src="data:image/png;base64,iVBORw0KGgo=" alt="logo" />`,
+		`<span style="color:#366">print</span>`,
+		"<div class=\"notebook-output\">\n<pre class=\"notebook-stream\">True\n</pre>\n<pre class=\"notebook-error\">ZeroDivisionError: division by zero</pre>\n</div>",
+		"<table><tr><td>1</td></tr></table>\n<img src=\"data:image/png;base64,iVBORw0KGgo=\" alt=\"Cell output\">",
+		`<h1 id="setup-1">Setup</h1>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Content missing %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "left out") || strings.Contains(html, "SSGNOTEBOOKOUTPUT") {
+		t.Errorf("Content has a raw cell or placeholder:\n%s", html)
+	}
+	if !strings.Contains(post.RawContent, "```python\nprint(1 < 2)\n1/0\n```") {
+		t.Errorf("RawContent = %q, want the code as a fenced block", post.RawContent)
+	}
+
+	// Without raw HTML, HTML outputs fall back to text
+	post, err = NewWithOptions(Options{NoRawHTML: true}).Parse([]byte(nb), "plotting.ipynb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html := string(post.Content); strings.Contains(html, "<table>") || !strings.Contains(html, "<pre class=\"notebook-result\">   a\n0  1</pre>") {
+		t.Errorf("Content with NoRawHTML:\n%s", html)
+	}
+
+	// Frontmatter in the first cell
+	nb = `{"nbformat": 4, "metadata": {"title": "Ignored"}, "cells": [
+  {"cell_type": "raw", "source": "---\ntitle: From the cell\ndraft: true\n---\n"},
+  {"cell_type": "markdown", "source": "Text"}]}`
+	post, err = New().Parse([]byte(nb), "2024-03-01-cell.ipynb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Title != "From the cell" || !post.Draft || post.Date.Format("2006-01-02") != "2024-03-01" || string(post.Content) != "<p>Text</p>\n" {
+		t.Errorf("Parse() = %q, draft %v, %v, %q", post.Title, post.Draft, post.Date, post.Content)
+	}
+
+	if _, err := New().Parse([]byte(`{"nbformat": 3}`), "old.ipynb"); err == nil {
+		t.Error("Parse() of an nbformat 3 notebook succeeded")
+	}
+	if p := NewWithOptions(Options{NoNotebooks: true}); p.Handles("a.ipynb") {
+		t.Error("Handles(\"a.ipynb\") with NoNotebooks = true")
+	}
+}
+
+// TestParse_Attachments tests attachments in the frontmatter
+func TestParse_Attachments(t *testing.T) {
+	p := New()
+	post, err := p.Parse([]byte("---\ntitle: Talk\nattachments:\n  - file: slides.pdf\n    title: Slides\n  - file: ../data/results.csv\n---\n\nBody\n"), filepath.Join("content", "posts", "talk.md"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Attachment{
+		{File: "slides.pdf", Title: "Slides", Path: filepath.Join("content", "posts", "slides.pdf")},
+		{File: "../data/results.csv", Title: "results.csv", Path: filepath.Join("content", "data", "results.csv")},
+	}
+	if !slices.Equal(post.Attachments, want) {
+		t.Errorf("Attachments = %+v, want %+v", post.Attachments, want)
+	}
+
+	for _, fm := range []string{"attachments:\n  - title: No file", "attachments:\n  - file: /etc/passwd"} {
+		if _, err := p.Parse([]byte("---\ntitle: Bad\n"+fm+"\n---\n\nBody\n"), "bad.md"); err == nil {
+			t.Errorf("Parse() with %q succeeded, want an error", fm)
+		}
+	}
+}
+
+// TestAttachment_HumanSize tests formatting attachment sizes
+func TestAttachment_HumanSize(t *testing.T) {
+	for size, want := range map[int64]string{
+		0:                 "0 B",
+		1023:              "1023 B",
+		1024:              "1.0 KB",
+		1536:              "1.5 KB",
+		200 * 1024:        "200 KB",
+		3_500_000:         "3.3 MB",
+		5 << 30:           "5.0 GB",
+		int64(2048) << 30: "2.0 TB",
+	} {
+		if got := (Attachment{Size: size}).HumanSize(); got != want {
+			t.Errorf("HumanSize() of %d = %q, want %q", size, got, want)
+		}
+	}
+}
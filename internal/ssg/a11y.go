@@ -0,0 +1,222 @@
+package ssg
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// AccessibilityConfig configures the accessibility audit of built pages.
+// After a build, every HTML page in the output is checked against these
+// rules:
+//   - img-alt (error): images without an alt attribute. An empty alt, for
+//     decorative images, is fine.
+//   - empty-link (error): links with no text, label, or image alt text for
+//     screen readers to announce
+//   - heading-order (warning): headings that skip a level, e.g. an <h4>
+//     right after an <h2>
+//   - landmarks (warning): pages without a <main> landmark
+//
+// Problems are printed as a warning per page, and with fail set, errors fail
+// the build. Redirect pages aren't audited, and nor are sharded builds.
+//
+// Example config.yaml:
+//
+//	accessibility:
+//	  enabled: true
+//	  fail: true
+//	  ignore: [heading-order]
+type AccessibilityConfig struct {
+	Enabled bool     `yaml:"enabled"` // Audit pages after each build
+	Fail    bool     `yaml:"fail"`    // Fail the build if any page has errors
+	Ignore  []string `yaml:"ignore"`  // Rules not to check, e.g. [landmarks]
+}
+
+// a11yRules are the rules of the accessibility audit, and whether breaking
+// them is an error rather than a warning.
+var a11yRules = map[string]bool{
+	"img-alt":       true,
+	"empty-link":    true,
+	"heading-order": false,
+	"landmarks":     false,
+}
+
+// a11yProblem is a rule a page breaks.
+type a11yProblem struct {
+	Rule    string
+	Message string
+}
+
+// reportAccessibility audits the pages of the site built to outputDir in
+// out, if the config at configPath in src enables it (see
+// AccessibilityConfig), printing each page's problems as a warning.
+//
+// Returns an error if the config has unknown rules, the output can't be
+// read, or fail is set and pages have errors.
+func reportAccessibility(src, out fs.FS, outputDir, configPath, env string) error {
+	config, err := loadConfigEnv(src, configPath, env)
+	if err != nil || !config.Accessibility.Enabled {
+		return nil
+	}
+	cfg := config.Accessibility
+	for _, rule := range cfg.Ignore {
+		if _, ok := a11yRules[rule]; !ok {
+			return fmt.Errorf("accessibility.ignore: unknown rule %q", rule)
+		}
+	}
+
+	var errorCount, pages int
+	err = fs.WalkDir(out, outputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".html" {
+			return err
+		}
+		f, err := out.Open(path)
+		if err != nil {
+			return err
+		}
+		doc, err := html.Parse(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+
+		var errs, warnings int
+		var messages []string
+		for _, p := range auditPage(doc) {
+			if slices.Contains(cfg.Ignore, p.Rule) {
+				continue
+			}
+			if a11yRules[p.Rule] {
+				errs++
+			} else {
+				warnings++
+			}
+			messages = append(messages, p.Rule+": "+p.Message)
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+		slog.Warn("accessibility problems", "file", filepath.ToSlash(rel), "errors", errs, "warnings", warnings, "problems", strings.Join(messages, "; "))
+		errorCount += errs
+		if errs > 0 {
+			pages++
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("auditing accessibility: %w", err)
+	}
+	if cfg.Fail && errorCount > 0 {
+		return fmt.Errorf("found %d accessibility errors on %d pages", errorCount, pages)
+	}
+	return nil
+}
+
+// auditPage returns the accessibility rules doc breaks, in document order
+// (see AccessibilityConfig). Redirect pages, which have a meta refresh, are
+// never shown and have none.
+func auditPage(doc *html.Node) []a11yProblem {
+	var problems []a11yProblem
+	hasMain, redirect := false, false
+	prevLevel, prevHeading := 0, ""
+	walkHTML(doc, func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+		if role, _ := nodeAttr(n, "role"); n.DataAtom == atom.Main || role == "main" {
+			hasMain = true
+		}
+		switch n.DataAtom {
+		case atom.Meta:
+			if equiv, _ := nodeAttr(n, "http-equiv"); strings.EqualFold(equiv, "refresh") {
+				redirect = true
+			}
+		case atom.Img:
+			if _, ok := nodeAttr(n, "alt"); !ok && !a11yHidden(n) {
+				src, _ := nodeAttr(n, "src")
+				problems = append(problems, a11yProblem{"img-alt", fmt.Sprintf("image %s has no alt text", src)})
+			}
+		case atom.A:
+			href, ok := nodeAttr(n, "href")
+			if ok && !a11yHidden(n) && accessibleName(n) == "" {
+				problems = append(problems, a11yProblem{"empty-link", fmt.Sprintf("link to %s has no text", href)})
+			}
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			level := int(n.Data[1] - '0')
+			text := strings.Join(strings.Fields(textContent(n)), " ")
+			if prevLevel > 0 && level > prevLevel+1 {
+				problems = append(problems, a11yProblem{"heading-order", fmt.Sprintf("heading %q (h%d) skips a level after %q (h%d)", text, level, prevHeading, prevLevel)})
+			}
+			prevLevel, prevHeading = level, text
+		}
+	})
+	if redirect {
+		return nil
+	}
+	if !hasMain {
+		problems = append(problems, a11yProblem{"landmarks", "page has no <main> landmark"})
+	}
+	return problems
+}
+
+// a11yHidden reports whether an element is hidden from assistive
+// technology, with aria-hidden="true" or role="presentation" (or "none").
+func a11yHidden(n *html.Node) bool {
+	hidden, _ := nodeAttr(n, "aria-hidden")
+	role, _ := nodeAttr(n, "role")
+	return hidden == "true" || role == "presentation" || role == "none"
+}
+
+// accessibleName returns roughly what a screen reader announces for an
+// element: its aria-label or title, or else its text and the alt text of
+// its images, leaving out parts hidden with aria-hidden. An element with
+// aria-labelledby is assumed to be named.
+func accessibleName(n *html.Node) string {
+	for _, key := range []string{"aria-label", "aria-labelledby", "title"} {
+		if v, _ := nodeAttr(n, key); strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	var b strings.Builder
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.Type != html.ElementNode:
+		case a11yHidden(n):
+			return
+		case n.DataAtom == atom.Img:
+			alt, _ := nodeAttr(n, "alt")
+			b.WriteString(alt)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		visit(c)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// textContent returns the text of an element and its descendants.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	walkHTML(n, func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+	})
+	return b.String()
+}
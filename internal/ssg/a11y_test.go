@@ -0,0 +1,85 @@
+package ssg
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// TestAuditPage tests each accessibility rule
+func TestAuditPage(t *testing.T) {
+	page := `<html><body><main>
+<h1>Title</h1>
+<img src="/a.png"> <img src="/b.png" alt=""> <img src="/c.png" aria-hidden="true">
+<a href="/empty"></a> <a href="/icon"><img src="/i.png" alt="Home"></a> <a href="/label" aria-label="Close">×</a>
+<a href="/hidden"><span aria-hidden="true">→</span></a> <a name="anchor"></a>
+<h3>Skipped</h3><h4>Fine</h4><h2>Back up</h2>
+</main></body></html>`
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range auditPage(doc) {
+		got = append(got, p.Rule+": "+p.Message)
+	}
+	want := []string{
+		"img-alt: image /a.png has no alt text",
+		"empty-link: link to /empty has no text",
+		"empty-link: link to /hidden has no text",
+		`heading-order: heading "Skipped" (h3) skips a level after "Title" (h1)`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("auditPage() = %q\nwant %q", got, want)
+	}
+
+	for page, want := range map[string]int{
+		"<html><body><p>No main</p></body></html>":                                                       1,
+		`<html><body><div role="main">Main</div></body></html>`:                                          0,
+		`<html><head><meta http-equiv="refresh" content="0; url=/new/"></head><body><img></body></html>`: 0,
+	} {
+		doc, err := html.Parse(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := auditPage(doc); len(got) != want {
+			t.Errorf("auditPage(%s) = %v, want %d problems", page, got, want)
+		}
+	}
+}
+
+// TestBuild_Accessibility tests failing the build on accessibility errors
+func TestBuild_Accessibility(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"disabled", "", ""},
+		{"warn", "accessibility:\n  enabled: true\n", ""},
+		{"fail", "accessibility:\n  enabled: true\n  fail: true\n", "found 1 accessibility errors on 1 pages"},
+		{"ignored", "accessibility:\n  enabled: true\n  fail: true\n  ignore: [img-alt]\n", ""},
+		{"unknown rule", "accessibility:\n  enabled: true\n  ignore: [contrast]\n", `unknown rule "contrast"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			site := testSite()
+			site["config.yaml"] += tt.config
+			site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\n<img src=\"/photo.jpg\">\n"
+			writeFiles(t, tmpDir, site)
+			t.Chdir(tmpDir)
+
+			err := Build(context.Background(), BuildOptions{})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Build() failed: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Build() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package ssg
+
+import (
+	"html/template"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// absoluteURLs rewrites links and image sources in post HTML to absolute URLs
+// based on baseURL, so content published outside the site (feeds, JSON
+// exports) still resolves in feed readers and syndication targets.
+//
+// href, src, and srcset attributes are resolved against the page's own URL,
+// so relative links work the same as on the page itself. URLs that are
+// already absolute (including mailto: and data: URLs) are left unchanged.
+//
+// Parameters:
+//   - content: Rendered post HTML
+//   - baseURL: Site's baseUrl (e.g., "https://example.com"); if empty, content is returned unchanged
+//   - pageURL: Site-relative URL the content is published at (e.g., "/posts/hello.html")
+//
+// Returns the rewritten HTML.
+func absoluteURLs(content template.HTML, baseURL, pageURL string) template.HTML {
+	if baseURL == "" {
+		return content
+	}
+	base, err := url.Parse(absURL(baseURL, pageURL))
+	if err != nil {
+		return content
+	}
+	return rewriteURLs(content, func(ref string) string {
+		u, err := url.Parse(strings.TrimSpace(ref))
+		if err != nil {
+			return ref
+		}
+		return base.ResolveReference(u).String()
+	})
+}
+
+// rewriteURLs returns content with the URLs in its href, src, and srcset
+// attributes replaced by what resolve returns for them.
+func rewriteURLs(content template.HTML, resolve func(ref string) string) template.HTML {
+	var out strings.Builder
+	z := html.NewTokenizer(strings.NewReader(string(content)))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			out.Write(z.Raw())
+			continue
+		}
+
+		raw := string(z.Raw())
+		tok := z.Token()
+		rewritten := false
+		for i, a := range tok.Attr {
+			if a.Val == "" {
+				continue
+			}
+			switch a.Key {
+			case "href", "src":
+				tok.Attr[i].Val = resolve(a.Val)
+			case "srcset":
+				candidates := strings.Split(a.Val, ",")
+				for j, c := range candidates {
+					fields := strings.Fields(c)
+					if len(fields) > 0 {
+						fields[0] = resolve(fields[0])
+						candidates[j] = strings.Join(fields, " ")
+					}
+				}
+				tok.Attr[i].Val = strings.Join(candidates, ", ")
+			default:
+				continue
+			}
+			rewritten = true
+		}
+		if rewritten {
+			out.WriteString(tok.String())
+		} else {
+			out.WriteString(raw)
+		}
+	}
+
+	// #nosec G203 -- rewritten from HTML produced by the markdown parser
+	return template.HTML(out.String())
+}
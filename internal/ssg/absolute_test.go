@@ -0,0 +1,50 @@
+package ssg
+
+import (
+	"html/template"
+	"testing"
+)
+
+// TestAbsoluteURLs tests resolving links and image sources against baseUrl
+func TestAbsoluteURLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		content string
+		want    string
+	}{
+		{
+			name:    "root-relative and relative links",
+			baseURL: "https://example.com/blog/",
+			content: `<a href="/about.html">About</a> <a href="other.html#x">Other</a>`,
+			want:    `<a href="https://example.com/about.html">About</a> <a href="https://example.com/blog/posts/other.html#x">Other</a>`,
+		},
+		{
+			name:    "images and srcset",
+			baseURL: "https://example.com",
+			content: `<img src="/a.jpg" srcset="/a-480w.jpg 480w, /a.jpg 960w"/>`,
+			want:    `<img src="https://example.com/a.jpg" srcset="https://example.com/a-480w.jpg 480w, https://example.com/a.jpg 960w"/>`,
+		},
+		{
+			name:    "absolute URLs untouched",
+			baseURL: "https://example.com",
+			content: `<a href="https://other.org/x">x</a> <a href="mailto:me@example.com">mail</a>`,
+			want:    `<a href="https://other.org/x">x</a> <a href="mailto:me@example.com">mail</a>`,
+		},
+		{
+			name:    "no baseUrl",
+			baseURL: "",
+			content: `<a href="/about.html">About</a>`,
+			want:    `<a href="/about.html">About</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := absoluteURLs(template.HTML(tt.content), tt.baseURL, "/posts/hello.html")
+			if string(got) != tt.want {
+				t.Errorf("absoluteURLs() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package ssg
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// APIConfig configures the static JSON API.
+//
+// Example config.yaml:
+//
+//	api:
+//	  enabled: true
+//	  pageSize: 20
+type APIConfig struct {
+	Enabled  bool `yaml:"enabled"`  // Write /api/posts.json and /api/posts/<slug>.json
+	PageSize int  `yaml:"pageSize"` // Posts per listing page (default: 10)
+}
+
+// apiPostSummary is a post as it appears in a listing page.
+type apiPostSummary struct {
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	URL         string    `json:"url"`
+	API         string    `json:"api"` // URL of the post's own JSON document
+	Date        time.Time `json:"date"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags"`
+}
+
+// apiPost is the JSON document for a single post.
+type apiPost struct {
+	apiPostSummary
+	Content string `json:"content"` // Rendered HTML, with absolute URLs if baseUrl is set
+}
+
+// apiListing is one page of the post listing.
+type apiListing struct {
+	Page       int              `json:"page"`
+	TotalPages int              `json:"totalPages"`
+	TotalPosts int              `json:"totalPosts"`
+	Prev       string           `json:"prev,omitempty"` // URL of the previous page
+	Next       string           `json:"next,omitempty"` // URL of the next page
+	Posts      []apiPostSummary `json:"posts"`
+}
+
+// writeAPI writes the static JSON API for the published posts.
+//
+// The listing is paginated: page 1 is /api/posts.json and page n is
+// /api/posts/page/<n>.json, each linking to its neighbours. Every post also
+// gets /api/posts/<slug>.json with its rendered content, so client-side
+// features (infinite scroll, related posts) and external consumers can query
+// the site without a server. Relative links and image sources in post content
+// are made absolute using baseURL, so the content works wherever it's shown.
+// On a multilingual site, each language gets its own API under its home page
+// (e.g., /es/api/posts.json) listing the posts in that language.
+//
+// Parameters:
+//   - posts: Published posts, in listing order
+//   - cfg: API configuration from config.yaml
+//   - baseURL: Site's baseUrl, used to make content URLs absolute
+//   - root: Site path the API is under: "/api/", or "/<lang>/api/"
+//   - out: Filesystem the site is written to
+//   - outputDir: Output directory (e.g., "public")
+//
+// Returns an error if a file can't be written. Does nothing if the API is
+// disabled.
+func writeAPI(posts []*parser.Post, cfg APIConfig, baseURL, root string, out FS, outputDir string) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	pageSize := cfg.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	summaries := make([]apiPostSummary, len(posts))
+	for i, post := range posts {
+		summaries[i] = apiPostSummary{
+			Title:       post.Title,
+			Slug:        post.Slug,
+			URL:         post.URL,
+			API:         root + "posts/" + post.Slug + ".json",
+			Date:        post.Date,
+			Description: post.Description,
+			Tags:        post.Tags,
+		}
+		if summaries[i].Tags == nil {
+			summaries[i].Tags = []string{}
+		}
+
+		content := absoluteURLs(post.Content, baseURL, post.URL)
+		doc := apiPost{apiPostSummary: summaries[i], Content: string(content)}
+		if err := writeJSON(out, urlPath(outputDir, doc.API), doc); err != nil {
+			return err
+		}
+	}
+
+	totalPages := max(1, (len(summaries)+pageSize-1)/pageSize)
+	for page := 1; page <= totalPages; page++ {
+		start := (page - 1) * pageSize
+		end := min(start+pageSize, len(summaries))
+		listing := apiListing{
+			Page:       page,
+			TotalPages: totalPages,
+			TotalPosts: len(summaries),
+			Posts:      summaries[start:end],
+		}
+		if page > 1 {
+			listing.Prev = apiPageURL(root, page-1)
+		}
+		if page < totalPages {
+			listing.Next = apiPageURL(root, page+1)
+		}
+		if err := writeJSON(out, urlPath(outputDir, apiPageURL(root, page)), listing); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apiPageURL returns the URL of a page of the post listing of the API under
+// root.
+func apiPageURL(root string, page int) string {
+	if page == 1 {
+		return root + "posts.json"
+	}
+	return fmt.Sprintf("%sposts/page/%d.json", root, page)
+}
+
+// writeJSON writes v to path as indented JSON, creating parent directories.
+func writeJSON(fsys FS, path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	return fsys.WriteFile(path, append(data, '\n'), 0600)
+}
@@ -0,0 +1,108 @@
+package ssg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestWriteAPI tests the paginated listing and per-post documents
+func TestWriteAPI(t *testing.T) {
+	tmpDir := t.TempDir()
+	var posts []*parser.Post
+	for i := 1; i <= 5; i++ {
+		slug := fmt.Sprintf("post-%d", i)
+		posts = append(posts, &parser.Post{
+			Title:   fmt.Sprintf("Post %d", i),
+			Slug:    slug,
+			URL:     "/posts/" + slug + ".html",
+			Date:    time.Date(2024, 1, i, 0, 0, 0, 0, time.UTC),
+			Content: "<p>Body</p>",
+		})
+	}
+
+	if err := writeAPI(posts, APIConfig{Enabled: true, PageSize: 2}, "", "/api/", DirFS("."), tmpDir); err != nil {
+		t.Fatalf("writeAPI() failed: %v", err)
+	}
+
+	var first apiListing
+	readJSON(t, filepath.Join(tmpDir, "api", "posts.json"), &first)
+	if first.Page != 1 || first.TotalPages != 3 || first.TotalPosts != 5 || len(first.Posts) != 2 {
+		t.Errorf("page 1 = %+v, want page 1 of 3 with 2 of 5 posts", first)
+	}
+	if first.Prev != "" || first.Next != "/api/posts/page/2.json" {
+		t.Errorf("page 1 links = %q, %q", first.Prev, first.Next)
+	}
+	if first.Posts[0].API != "/api/posts/post-1.json" || first.Posts[0].Tags == nil {
+		t.Errorf("summary = %+v, want api URL and empty tags", first.Posts[0])
+	}
+
+	var last apiListing
+	readJSON(t, filepath.Join(tmpDir, "api", "posts", "page", "3.json"), &last)
+	if len(last.Posts) != 1 || last.Prev != "/api/posts/page/2.json" || last.Next != "" {
+		t.Errorf("page 3 = %+v, want 1 post linking back to page 2", last)
+	}
+
+	var post apiPost
+	readJSON(t, filepath.Join(tmpDir, "api", "posts", "post-3.json"), &post)
+	if post.Title != "Post 3" || post.Content != "<p>Body</p>" || post.URL != "/posts/post-3.html" {
+		t.Errorf("post = %+v", post)
+	}
+}
+
+// TestWriteAPI_AbsoluteURLs tests that post content links are made absolute
+func TestWriteAPI_AbsoluteURLs(t *testing.T) {
+	tmpDir := t.TempDir()
+	post := &parser.Post{Slug: "a", URL: "/posts/a.html", Content: `<a href="b.html">B</a><img src="/images/x.png" />`}
+	if err := writeAPI([]*parser.Post{post}, APIConfig{Enabled: true}, "https://example.com", "/api/", DirFS("."), tmpDir); err != nil {
+		t.Fatalf("writeAPI() failed: %v", err)
+	}
+
+	var doc apiPost
+	readJSON(t, filepath.Join(tmpDir, "api", "posts", "a.json"), &doc)
+	want := `<a href="https://example.com/posts/b.html">B</a><img src="https://example.com/images/x.png"/>`
+	if doc.Content != want {
+		t.Errorf("content = %s, want %s", doc.Content, want)
+	}
+}
+
+// TestWriteAPI_Disabled tests that nothing is written unless the API is enabled
+func TestWriteAPI_Disabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := writeAPI([]*parser.Post{{Slug: "a"}}, APIConfig{}, "", "/api/", DirFS("."), tmpDir); err != nil {
+		t.Fatalf("writeAPI() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "api")); !os.IsNotExist(err) {
+		t.Errorf("api directory written while disabled")
+	}
+}
+
+// TestWriteAPI_NoPosts tests that an empty site still gets a listing
+func TestWriteAPI_NoPosts(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := writeAPI(nil, APIConfig{Enabled: true}, "", "/api/", DirFS("."), tmpDir); err != nil {
+		t.Fatalf("writeAPI() failed: %v", err)
+	}
+	var listing apiListing
+	readJSON(t, filepath.Join(tmpDir, "api", "posts.json"), &listing)
+	if listing.TotalPages != 1 || listing.Posts == nil {
+		t.Errorf("listing = %+v, want one empty page", listing)
+	}
+}
+
+// readJSON decodes the JSON file at path into v.
+func readJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("decoding %s: %v", path, err)
+	}
+}
@@ -0,0 +1,114 @@
+package ssg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// archetypeDir is the directory of a site's archetypes: templates for the
+// files NewPost creates, named after the section they're for (e.g.,
+// archetypes/posts.md or archetypes/notes/go.md), with archetypes/default.md
+// for every other section.
+const archetypeDir = "archetypes"
+
+// defaultArchetype is the archetype of sites without their own.
+const defaultArchetype = `---
+title: {{yaml .Title}}
+date: {{.Date}}
+description: {{yaml .Description}}
+tags: {{yaml .Tags}}
+draft: {{.Draft}}
+---
+
+Write your post here...
+`
+
+// archetypeData is what an archetype is executed with.
+type archetypeData struct {
+	Title       string   // Title from the command line
+	Slug        string   // Slug of the new file
+	Section     string   // Section the file is created in, e.g. "posts"
+	Date        string   // Creation time in RFC 3339 format
+	Description string   // Description, or ""
+	Tags        []string // Tags, or nil
+	Draft       bool     // Whether the file is created as a draft
+}
+
+// archetype is a parsed archetype template.
+type archetype struct {
+	name string
+	tmpl *template.Template
+}
+
+// findArchetype returns the archetype for new files in section: the first of
+// archetypes/<section>.md and archetypes/default.md in fsys, or
+// defaultArchetype if the site has neither. Archetypes are text/templates
+// executed with archetypeData; {{yaml .Title}} writes a value as YAML, so
+// titles with colons and lists of tags stay valid frontmatter.
+//
+// Returns an error if an archetype can't be read or parsed.
+func findArchetype(fsys fs.FS, section string) (*archetype, error) {
+	for _, name := range []string{section + ".md", "default.md"} {
+		file := path.Join(archetypeDir, name)
+		content, err := fs.ReadFile(fsys, file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading archetype: %w", err)
+		}
+		return parseArchetype(file, string(content))
+	}
+	return parseArchetype("default archetype", defaultArchetype)
+}
+
+// parseArchetype parses the archetype text, named name in errors.
+func parseArchetype(name, text string) (*archetype, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{"yaml": yamlValue}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing archetype %s: %w", name, err)
+	}
+	return &archetype{name: name, tmpl: tmpl}, nil
+}
+
+// render executes the archetype with data.
+func (a *archetype) render(data archetypeData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := a.tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("executing archetype %s: %w", a.name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlValue formats v as a YAML value on one line: scalars are quoted only
+// when they need to be, and lists are written in flow style, like [go, web].
+func yamlValue(v any) (string, error) {
+	if list, ok := v.([]string); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			if strings.ContainsAny(item, ",[]{}") {
+				items[i] = strconv.Quote(item) // Plain in a block, but not in a flow list
+				continue
+			}
+			s, err := yamlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	}
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
@@ -0,0 +1,136 @@
+package ssg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestNewPost_Options tests the frontmatter, section, and slug options
+func TestNewPost_Options(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	path, err := NewPost(NewPostOptions{
+		Title:       "Go: A Tour",
+		Slug:        "about",
+		Description: "Where to start",
+		Tags:        []string{"go", "web, http"},
+		Section:     "pages",
+		NoDraft:     true,
+	})
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	if want := filepath.Join("content", "pages", "about.md"); path != want {
+		t.Errorf("NewPost() created %s, want %s", path, want)
+	}
+
+	post, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("parsing the new post: %v", err)
+	}
+	if post.Title != "Go: A Tour" || post.Description != "Where to start" || post.Draft {
+		t.Errorf("frontmatter = title %q, description %q, draft %v", post.Title, post.Description, post.Draft)
+	}
+	if got := strings.Join(post.Tags, "|"); got != "go|web, http" {
+		t.Errorf("tags = %s, want go|web, http", got)
+	}
+
+	if _, err := NewPost(NewPostOptions{Title: "Again", Slug: "about", Section: "pages"}); err == nil {
+		t.Error("NewPost() over an existing file succeeded, want error")
+	}
+	for _, section := range []string{"../outside", "/abs", "_drafts"} {
+		if _, err := NewPost(NewPostOptions{Title: "Bad", Section: section}); err == nil {
+			t.Errorf("NewPost() in section %q succeeded, want error", section)
+		}
+	}
+}
+
+// TestNewPost_Archetypes tests that the section's archetype is used, then default.md
+func TestNewPost_Archetypes(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"archetypes/notes.md":   "---\ntitle: {{yaml .Title}}\ndate: {{.Date}}\n---\n\nA note in {{.Section}} at /{{.Section}}/{{.Slug}}\n",
+		"archetypes/default.md": "---\ntitle: {{yaml .Title}}\ndraft: {{.Draft}}\n---\n\nFrom the default archetype\n",
+	})
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		section string
+		want    string
+	}{
+		{section: "notes", want: "A note in notes at /notes/quick-tip"},
+		{section: "posts", want: "From the default archetype"},
+	}
+	for _, tt := range tests {
+		path, err := NewPost(NewPostOptions{Title: "Quick Tip", Section: tt.section})
+		if err != nil {
+			t.Fatalf("NewPost() in %s failed: %v", tt.section, err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), tt.want) {
+			t.Errorf("%s:\n%s\nwant it to contain %q", path, content, tt.want)
+		}
+	}
+
+	writeFiles(t, tmpDir, map[string]string{"archetypes/default.md": "{{.Missing}}"})
+	if _, err := NewPost(NewPostOptions{Title: "Broken"}); err == nil {
+		t.Error("NewPost() with a broken archetype succeeded, want error")
+	}
+}
+
+// TestNewPost_Edit tests opening the new post in $EDITOR
+func TestNewPost_Edit(t *testing.T) {
+	tmpDir := t.TempDir()
+	editor := filepath.Join(tmpDir, "editor.sh")
+	writeFiles(t, tmpDir, map[string]string{"editor.sh": "#!/bin/sh\necho edited >> \"$1\"\n"})
+	if err := os.Chmod(editor, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(tmpDir)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+
+	path, err := NewPost(NewPostOptions{Title: "Edited", Edit: true})
+	if err != nil {
+		t.Fatalf("NewPost() failed: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(content), "edited\n") {
+		t.Errorf("editor didn't run on %s:\n%s", path, content)
+	}
+
+	t.Setenv("EDITOR", "")
+	if _, err := NewPost(NewPostOptions{Title: "No Editor", Edit: true}); err == nil {
+		t.Error("NewPost() with --edit and no $EDITOR succeeded, want error")
+	}
+}
+
+// TestYAMLValue tests formatting archetype values as YAML
+func TestYAMLValue(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{value: "Plain title", want: "Plain title"},
+		{value: "Go: A Tour", want: `'Go: A Tour'`},
+		{value: "", want: `""`},
+		{value: []string(nil), want: "[]"},
+		{value: []string{"go", "a, b"}, want: `[go, "a, b"]`},
+	}
+	for _, tt := range tests {
+		got, err := yamlValue(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("yamlValue(%#v) = %s, %v, want %s", tt.value, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Consent banner written by ssg when consent.enabled is set in config.yaml.
+// window.ssgConsent, set above, holds the banner text and scripts.
+//
+// Analytics and embeds load only after the visitor accepts:
+//   - scripts listed under consent.scripts
+//   - <script type="text/plain" data-consent> elements, re-created as scripts
+//   - <iframe data-consent-src="..."> elements, whose src is then set
+//
+// The choice is kept in localStorage. Visitors whose browser sends Global
+// Privacy Control are treated as having declined. Clicking an element with
+// data-consent-open shows the banner again, so the choice can be changed.
+(function () {
+  "use strict";
+  var config = window.ssgConsent || {};
+  var key = "ssg-consent";
+  var banner = null;
+  var loaded = false;
+
+  function stored() {
+    try {
+      return localStorage.getItem(key);
+    } catch (e) {
+      return null;
+    }
+  }
+
+  function store(value) {
+    try {
+      localStorage.setItem(key, value);
+    } catch (e) {
+      // Storage disabled; the choice lasts for this page only
+    }
+  }
+
+  function load() {
+    if (loaded) {
+      return;
+    }
+    loaded = true;
+    (config.scripts || []).forEach(function (s) {
+      var el = document.createElement("script");
+      if (s.src) {
+        el.src = s.src;
+        el.async = true;
+      } else {
+        el.text = s.inline || "";
+      }
+      document.head.appendChild(el);
+    });
+    document.querySelectorAll('script[type="text/plain"][data-consent]').forEach(function (blocked) {
+      var el = document.createElement("script");
+      for (var i = 0; i < blocked.attributes.length; i++) {
+        var attr = blocked.attributes[i];
+        if (attr.name !== "type" && attr.name !== "data-consent") {
+          el.setAttribute(attr.name, attr.value);
+        }
+      }
+      el.text = blocked.text;
+      blocked.parentNode.replaceChild(el, blocked);
+    });
+    document.querySelectorAll("iframe[data-consent-src]").forEach(function (frame) {
+      frame.src = frame.getAttribute("data-consent-src");
+      frame.removeAttribute("data-consent-src");
+    });
+  }
+
+  function choose(value) {
+    store(value);
+    if (banner) {
+      banner.remove();
+      banner = null;
+    }
+    if (value === "granted") {
+      load();
+    }
+  }
+
+  function button(label, value) {
+    var el = document.createElement("button");
+    el.type = "button";
+    el.textContent = label;
+    el.addEventListener("click", function () {
+      choose(value);
+    });
+    return el;
+  }
+
+  function show() {
+    if (banner) {
+      return;
+    }
+    banner = document.createElement("div");
+    banner.className = "ssg-consent";
+    banner.setAttribute("role", "dialog");
+    banner.setAttribute("aria-live", "polite");
+    banner.setAttribute("aria-label", config.accept + " / " + config.reject);
+
+    var text = document.createElement("p");
+    text.textContent = config.message + " ";
+    if (config.policyUrl) {
+      var link = document.createElement("a");
+      link.href = config.policyUrl;
+      link.textContent = config.policyText;
+      text.appendChild(link);
+    }
+    banner.appendChild(text);
+    banner.appendChild(button(config.accept, "granted"));
+    banner.appendChild(button(config.reject, "denied"));
+    document.body.appendChild(banner);
+  }
+
+  function start() {
+    // Zero-specificity defaults, so any theme rule overrides them
+    var style = document.createElement("style");
+    style.textContent =
+      ":where(.ssg-consent){position:fixed;left:0;right:0;bottom:0;z-index:1000;" +
+      "padding:1rem;background:#222;color:#fff;display:flex;flex-wrap:wrap;gap:.5rem;align-items:center}" +
+      ":where(.ssg-consent p){margin:0;flex:1 1 20rem}:where(.ssg-consent a){color:inherit}";
+    document.head.insertBefore(style, document.head.firstChild);
+
+    var choice = stored();
+    if (choice === null && navigator.globalPrivacyControl) {
+      choice = "denied";
+    }
+    if (choice === "granted") {
+      load();
+    } else if (choice !== "denied") {
+      show();
+    }
+
+    document.addEventListener("click", function (e) {
+      if (e.target.closest && e.target.closest("[data-consent-open]")) {
+        e.preventDefault();
+        show();
+      }
+    });
+  }
+
+  if (document.readyState === "loading") {
+    document.addEventListener("DOMContentLoaded", start);
+  } else {
+    start();
+  }
+})();
@@ -0,0 +1,61 @@
+package ssg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// assignAttachments sets the URL and size of the attachments of posts (see
+// parser.Attachment), once their URLs are assigned. Attachments are copied
+// into the post's own directory, where page bundle resources go too (see
+// bundleURL), e.g. "/posts/trip/slides.pdf" for "/posts/trip.html".
+//
+// Returns an error naming every attachment that's missing, is a directory,
+// or has the same name as another of the post's.
+func assignAttachments(fsys fs.FS, posts []*parser.Post) error {
+	var errs []error
+	for _, post := range posts {
+		dir := bundleURL(post.URL)
+		names := make(map[string]bool)
+		for i := range post.Attachments {
+			a := &post.Attachments[i]
+			info, err := fs.Stat(fsys, a.Path)
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("post %s: attachment %s: %w", post.Slug, a.File, err))
+				continue
+			case info.IsDir():
+				errs = append(errs, fmt.Errorf("post %s: attachment %s is a directory", post.Slug, a.File))
+				continue
+			}
+			name := filepath.Base(a.Path)
+			if names[name] {
+				errs = append(errs, fmt.Errorf("post %s: more than one attachment is named %s", post.Slug, name))
+				continue
+			}
+			names[name] = true
+			a.URL = dir + name
+			a.Size = info.Size()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeAttachments copies the attachments of post in src to their URLs
+// under outputDir in out.
+func writeAttachments(src fs.FS, out FS, post *parser.Post, outputDir string) error {
+	for _, a := range post.Attachments {
+		data, err := fs.ReadFile(src, a.Path)
+		if err != nil {
+			return err
+		}
+		if err := out.WriteFile(urlPath(outputDir, a.URL), data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
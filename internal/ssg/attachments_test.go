@@ -0,0 +1,85 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBuild_Attachments tests copying attachments next to posts and listing
+// them in the default theme
+func TestBuild_Attachments(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	delete(site, "templates/base.html")
+	delete(site, "templates/posts.html")
+	delete(site, "templates/post.html")
+	site["content/posts/2024-02-01-talk.md"] = "---\ntitle: Talk\nattachments:\n  - file: files/slides.pdf\n    title: Slides\n  - file: ../data.csv\n---\n\n[Slides](/posts/talk/slides.pdf)\n"
+	site["content/posts/files/slides.pdf"] = strings.Repeat("x", 2048)
+	site["content/data.csv"] = "a,b\n"
+	site["content/notes/vim/index.md"] = "---\ntitle: Vim\nattachments:\n  - file: vimrc\n---\n\nText.\n"
+	site["content/notes/vim/vimrc"] = "set number\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	page, err := os.ReadFile("public/posts/talk.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<a href="/posts/talk/slides.pdf" download>Slides</a> <span class="size">(2.0 KB)</span>`,
+		`<a href="/posts/talk/data.csv" download>data.csv</a> <span class="size">(4 B)</span>`,
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("post page missing %q:\n%s", want, page)
+		}
+	}
+	for file, want := range map[string]string{
+		"public/posts/talk/slides.pdf": strings.Repeat("x", 2048),
+		"public/posts/talk/data.csv":   "a,b\n",
+		"public/notes/vim/vimrc":       "set number\n",
+	} {
+		if data, err := os.ReadFile(file); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", file, data, err, want)
+		}
+	}
+
+	// A missing attachment fails the build
+	writeFiles(t, tmpDir, map[string]string{"content/posts/2024-02-02-gone.md": "---\ntitle: Gone\nattachments:\n  - file: gone.zip\n---\n\nBody\n"})
+	if err := Build(context.Background(), BuildOptions{}); err == nil || !strings.Contains(err.Error(), "gone.zip") {
+		t.Errorf("Build() error = %v, want one naming gone.zip", err)
+	}
+}
+
+// TestCheckSite_Attachments tests reporting missing attachments, and links
+// to attachments
+func TestCheckSite_Attachments(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["content/posts/2024-02-01-talk.md"] = "---\ntitle: Talk\nattachments:\n  - file: slides.pdf\n  - file: gone.zip\n---\n\n[Slides](/posts/talk/slides.pdf) [Zip](/posts/talk/gone.zip)\n"
+	site["content/posts/slides.pdf"] = "pdf"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	problems, err := checkSite(CheckOptions{ConfigPath: "config.yaml"}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("checkSite() failed: %v", err)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.File+": "+p.Message)
+	}
+	file := filepath.Join("content", "posts", "2024-02-01-talk.md")
+	want := []string{file + ": attachment gone.zip: file not found", file + ": broken link to /posts/talk/gone.zip"}
+	if !slices.Equal(got, want) {
+		t.Errorf("checkSite() = %q, want %q", got, want)
+	}
+}
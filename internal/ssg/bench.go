@@ -0,0 +1,173 @@
+package ssg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// benchResult holds the measurements from a benchmark build.
+type benchResult struct {
+	Posts        int
+	Duration     time.Duration
+	Files        int
+	BytesWritten int64
+	PeakRSS      uint64 // Peak resident set size in bytes, 0 if unavailable
+}
+
+// PostsPerSecond returns the build throughput.
+func (b *benchResult) PostsPerSecond() float64 {
+	if b.Duration <= 0 {
+		return 0
+	}
+	return float64(b.Posts) / b.Duration.Seconds()
+}
+
+// Bench synthesizes a site with the given number of posts, builds it, and
+// prints build throughput, output size, and peak memory usage.
+//
+// The site is generated in a temporary directory using the templates and
+// static files of the site in the current directory, so results reflect the
+// real theme. The temporary directory is removed afterwards.
+//
+// Parameters:
+//   - posts: Number of posts to generate (e.g., 5000)
+//
+// Returns an error if the site can't be generated or the build fails.
+func Bench(posts int) error {
+	result, err := runBench(posts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Benchmark: %d posts\n", result.Posts)
+	fmt.Printf("  Build time:  %s\n", result.Duration.Round(time.Millisecond))
+	fmt.Printf("  Throughput:  %.1f posts/sec\n", result.PostsPerSecond())
+	fmt.Printf("  Output:      %.1f MB in %d files\n", float64(result.BytesWritten)/(1<<20), result.Files)
+	if result.PeakRSS > 0 {
+		fmt.Printf("  Peak RSS:    %.1f MB\n", float64(result.PeakRSS)/(1<<20))
+	} else {
+		fmt.Println("  Peak RSS:    unavailable on this platform")
+	}
+	return nil
+}
+
+// runBench generates the benchmark site, builds it, and measures the build.
+func runBench(posts int) (*benchResult, error) {
+	if posts <= 0 {
+		return nil, fmt.Errorf("number of posts must be positive, got %d", posts)
+	}
+
+	siteDir, err := os.MkdirTemp("", "ssg-bench-")
+	if err != nil {
+		return nil, fmt.Errorf("creating bench directory: %w", err)
+	}
+	defer os.RemoveAll(siteDir)
+
+	if err := generateBenchSite(siteDir, posts); err != nil {
+		return nil, fmt.Errorf("generating bench site: %w", err)
+	}
+
+	outputDir := filepath.Join(siteDir, "public")
+	start := time.Now()
+	if err := Build(context.Background(), BuildOptions{Source: DirFS(siteDir)}); err != nil {
+		return nil, fmt.Errorf("building bench site: %w", err)
+	}
+	result := &benchResult{Posts: posts, Duration: time.Since(start), PeakRSS: peakRSS()}
+
+	err = filepath.Walk(outputDir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		result.Files++
+		result.BytesWritten += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("measuring output: %w", err)
+	}
+
+	return result, nil
+}
+
+// generateBenchSite writes a config, the current site's templates, theme, and
+// static files, and synthetic posts into dir. Sites without templates or a
+// theme are benchmarked with the default theme.
+func generateBenchSite(dir string, posts int) error {
+	if err := copyStatic(DirFS("."), DirFS("."), "templates", filepath.Join(dir, "templates"), false, sizeLimits{}); err != nil {
+		return fmt.Errorf("copying templates: %w", err)
+	}
+	if err := copyStatic(DirFS("."), DirFS("."), "static", filepath.Join(dir, "static"), false, sizeLimits{}); err != nil {
+		return fmt.Errorf("copying static files: %w", err)
+	}
+
+	config := `title: Benchmark Site
+description: A synthetic site for measuring build performance
+baseUrl: https://bench.example.com
+author: Bench
+keywords: benchmark
+`
+	if site, err := LoadConfig("config.yaml"); err == nil && site.Theme.Name != "" {
+		themeDir := filepath.Join(themesDir, site.Theme.Name)
+		if err := copyStatic(DirFS("."), DirFS("."), themeDir, filepath.Join(dir, themeDir), false, sizeLimits{}); err != nil {
+			return fmt.Errorf("copying theme: %w", err)
+		}
+		config += "theme: " + site.Theme.Name + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0600); err != nil {
+		return err
+	}
+
+	postsDir := filepath.Join(dir, "content", "posts")
+	if err := os.MkdirAll(postsDir, 0750); err != nil {
+		return err
+	}
+
+	start := time.Date(2015, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < posts; i++ {
+		date := start.Add(time.Duration(i) * 7 * time.Hour)
+		name := fmt.Sprintf("%s-bench-post-%05d.md", date.Format("2006-01-02"), i)
+		if err := os.WriteFile(filepath.Join(postsDir, name), benchPost(i, date), 0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// benchTags is the pool that synthetic posts draw their tags from.
+var benchTags = []string{"go", "web", "performance", "testing", "design", "tools", "notes"}
+
+// benchPost returns the markdown for synthetic post i. Content varies
+// deterministically with i so that builds are reproducible while still
+// exercising headings, lists, links, tables, and highlighted code.
+func benchPost(i int, date time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "---\ntitle: Benchmark Post %d\ndate: %s\n", i, date.Format(time.RFC3339))
+	fmt.Fprintf(&b, "description: Synthetic post number %d\n", i)
+	fmt.Fprintf(&b, "tags: [%s, %s]\n", benchTags[i%len(benchTags)], benchTags[(i/3)%len(benchTags)])
+	b.WriteString("draft: false\n---\n\n")
+
+	sections := 2 + i%4
+	for s := 0; s < sections; s++ {
+		fmt.Fprintf(&b, "## Section %d\n\n", s+1)
+		for p := 0; p < 3; p++ {
+			b.WriteString("Lorem ipsum dolor sit amet, *consectetur* adipiscing elit, sed do **eiusmod** tempor ")
+			b.WriteString("incididunt ut labore et dolore magna aliqua. See [the docs](https://example.com/docs) ")
+			b.WriteString("and `inline code` for details.\n\n")
+		}
+		switch (i + s) % 3 {
+		case 0:
+			b.WriteString("```go\nfunc main() {\n\tfor i := 0; i < 10; i++ {\n\t\tfmt.Println(\"hello\", i)\n\t}\n}\n```\n\n")
+		case 1:
+			b.WriteString("- First item\n- Second item\n  - Nested item\n- Third item\n\n")
+		case 2:
+			b.WriteString("| Name | Value |\n|------|-------|\n| a    | 1     |\n| b    | 2     |\n\n")
+		}
+	}
+
+	return []byte(b.String())
+}
@@ -0,0 +1,98 @@
+package ssg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestRunBench tests generating and building a synthetic site
+func TestRunBench(t *testing.T) {
+	tmpDir := t.TempDir()
+	templatesDir := filepath.Join(tmpDir, "templates")
+	if err := os.MkdirAll(templatesDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	templates := map[string]string{
+		"base.html":  `<html><head><title>{{.Title}}</title></head><body>{{template "posts" .}}</body></html>`,
+		"posts.html": `{{define "posts"}}{{range .Posts}}<a href="/posts/{{.Slug}}.html">{{.Title}}</a>{{end}}{{end}}`,
+		"post.html":  `{{define "posts"}}<article>{{.Post.Content}}</article>{{end}}`,
+	}
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(templatesDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(origDir)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := runBench(25)
+	if err != nil {
+		t.Fatalf("runBench() failed: %v", err)
+	}
+
+	if result.Posts != 25 {
+		t.Errorf("Posts = %d, want 25", result.Posts)
+	}
+	// 25 post pages plus the index
+	if result.Files != 26 {
+		t.Errorf("Files = %d, want 26", result.Files)
+	}
+	if result.BytesWritten == 0 {
+		t.Error("BytesWritten = 0, want > 0")
+	}
+	if result.Duration <= 0 || result.PostsPerSecond() <= 0 {
+		t.Errorf("Duration = %v, PostsPerSecond = %v, want > 0", result.Duration, result.PostsPerSecond())
+	}
+
+	// The working directory must be restored
+	if wd, _ := os.Getwd(); wd != tmpDir {
+		t.Errorf("working directory = %q, want %q", wd, tmpDir)
+	}
+}
+
+// TestRunBench_InvalidCount tests rejecting non-positive post counts
+func TestRunBench_InvalidCount(t *testing.T) {
+	if _, err := runBench(0); err == nil {
+		t.Error("runBench(0) succeeded, want error")
+	}
+}
+
+// TestBenchPost tests that synthetic posts are valid and deterministic
+func TestBenchPost(t *testing.T) {
+	date := time.Date(2020, 5, 1, 9, 0, 0, 0, time.UTC)
+	content := benchPost(7, date)
+
+	if string(content) != string(benchPost(7, date)) {
+		t.Error("benchPost() is not deterministic")
+	}
+
+	post, err := parser.New().Parse(content, "2020-05-01-bench-post-00007.md")
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if post.Title != "Benchmark Post 7" {
+		t.Errorf("Title = %q, want %q", post.Title, "Benchmark Post 7")
+	}
+	if !post.Date.Equal(date) {
+		t.Errorf("Date = %v, want %v", post.Date, date)
+	}
+	if len(post.Tags) != 2 {
+		t.Errorf("len(Tags) = %d, want 2", len(post.Tags))
+	}
+	if !strings.Contains(string(post.Content), "<h2") {
+		t.Error("Content doesn't contain section headings")
+	}
+}
@@ -0,0 +1,103 @@
+package ssg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// BundleConfig describes a group of static CSS or JS files concatenated into a
+// single output file.
+//
+// Example config.yaml:
+//
+//	bundles:
+//	  - name: css/site.css
+//	    files: [css/reset.css, css/style.css]
+//	  - name: js/site.js
+//	    files: [js/*.js]
+//	    minify: true
+type BundleConfig struct {
+	Name   string   `yaml:"name"`   // Output path relative to the output directory
+	Files  []string `yaml:"files"`  // Source files or glob patterns relative to static/, in order
+	Minify bool     `yaml:"minify"` // Minify the bundle (always on when the site-wide minify is set)
+}
+
+// buildBundles concatenates each configured bundle and writes it to the output
+// directory.
+//
+// Files are concatenated in the order listed; glob patterns expand in lexical
+// order. Each bundle's URL carries a short content hash as a query string
+// (e.g., "/css/site.css?v=1a2b3c4d") so browsers fetch a fresh copy whenever
+// the bundle changes.
+//
+// Parameters:
+//   - src: Filesystem the site is read from
+//   - out: Filesystem the site is written to
+//   - bundles: Bundle configuration from config.yaml
+//   - staticDir: Directory containing the source files (e.g., "static")
+//   - outputDir: Directory to write bundles to (e.g., "public")
+//   - minify: Site-wide minify setting
+//
+// Returns a map of bundle name → URL for templates, or an error if a source
+// file is missing, a bundle would collide with a static file, or a bundle
+// can't be written.
+func buildBundles(src fs.FS, out FS, bundles []BundleConfig, staticDir, outputDir string, minify bool) (map[string]string, error) {
+	urls := make(map[string]string)
+
+	for _, b := range bundles {
+		if b.Name == "" {
+			return nil, fmt.Errorf("bundle is missing a name")
+		}
+		// Static files are copied after bundles are written, so a bundle
+		// sharing a static file's name would be silently replaced.
+		if _, err := fs.Stat(src, filepath.Join(staticDir, filepath.FromSlash(b.Name))); err == nil {
+			return nil, fmt.Errorf("bundle %s has the same name as a static file", b.Name)
+		}
+
+		var buf bytes.Buffer
+		for _, pattern := range b.Files {
+			matches, err := fs.Glob(src, filepath.Join(staticDir, filepath.FromSlash(pattern)))
+			if err != nil {
+				return nil, fmt.Errorf("bundle %s: %w", b.Name, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("bundle %s: no files match %s", b.Name, pattern)
+			}
+			sort.Strings(matches)
+
+			for _, match := range matches {
+				data, err := fs.ReadFile(src, match)
+				if err != nil {
+					return nil, fmt.Errorf("bundle %s: %w", b.Name, err)
+				}
+				buf.Write(data)
+				if !bytes.HasSuffix(data, []byte("\n")) {
+					buf.WriteByte('\n')
+				}
+			}
+		}
+
+		data := buf.Bytes()
+		if b.Minify || minify {
+			if fn := minifyFor(b.Name); fn != nil {
+				data = fn(data)
+			}
+		}
+
+		dst := filepath.Join(outputDir, filepath.FromSlash(b.Name))
+		if err := out.WriteFile(dst, data, 0600); err != nil {
+			return nil, fmt.Errorf("writing bundle %s: %w", b.Name, err)
+		}
+
+		sum := sha256.Sum256(data)
+		urls[b.Name] = path.Join("/", b.Name) + "?v=" + hex.EncodeToString(sum[:4])
+	}
+
+	return urls, nil
+}
@@ -0,0 +1,100 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuildBundles tests concatenating static files into bundles
+func TestBuildBundles(t *testing.T) {
+	tmpDir := t.TempDir()
+	staticDir := filepath.Join(tmpDir, "static")
+	outputDir := filepath.Join(tmpDir, "public")
+	writeFiles(t, staticDir, map[string]string{
+		"css/reset.css": "* { margin: 0; }",
+		"css/style.css": "body {\n  color: black;\n}\n",
+		"js/b.js":       "// second\nconsole.log('b');\n",
+		"js/a.js":       "console.log('a');\n",
+	})
+
+	bundles := []BundleConfig{
+		{Name: "css/site.css", Files: []string{"css/reset.css", "css/style.css"}},
+		{Name: "js/site.js", Files: []string{"js/*.js"}, Minify: true},
+	}
+
+	urls, err := buildBundles(DirFS("."), DirFS("."), bundles, staticDir, outputDir, false)
+	if err != nil {
+		t.Fatalf("buildBundles() failed: %v", err)
+	}
+
+	css, err := os.ReadFile(filepath.Join(outputDir, "css", "site.css"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(css) != "* { margin: 0; }\nbody {\n  color: black;\n}\n" {
+		t.Errorf("css bundle = %q, want files concatenated in order", css)
+	}
+
+	js, err := os.ReadFile(filepath.Join(outputDir, "js", "site.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(js) != "console.log('a');\nconsole.log('b');" {
+		t.Errorf("js bundle = %q, want minified files in lexical order", js)
+	}
+
+	if !strings.HasPrefix(urls["css/site.css"], "/css/site.css?v=") {
+		t.Errorf("css URL = %q, want /css/site.css?v=<hash>", urls["css/site.css"])
+	}
+	if !strings.HasPrefix(urls["js/site.js"], "/js/site.js?v=") {
+		t.Errorf("js URL = %q, want /js/site.js?v=<hash>", urls["js/site.js"])
+	}
+}
+
+// TestBuildBundles_MissingFile tests that an unmatched source is an error
+func TestBuildBundles_MissingFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	bundles := []BundleConfig{{Name: "css/site.css", Files: []string{"css/missing.css"}}}
+	if _, err := buildBundles(DirFS("."), DirFS("."), bundles, tmpDir, filepath.Join(tmpDir, "public"), false); err == nil {
+		t.Error("buildBundles() succeeded with missing file, want error")
+	}
+}
+
+// TestBuildBundles_NameCollision tests that a bundle can't shadow a static file
+func TestBuildBundles_NameCollision(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{"css/style.css": "body {}"})
+	bundles := []BundleConfig{{Name: "css/style.css", Files: []string{"css/style.css"}}}
+	if _, err := buildBundles(DirFS("."), DirFS("."), bundles, tmpDir, filepath.Join(tmpDir, "public"), false); err == nil {
+		t.Error("buildBundles() succeeded with colliding name, want error")
+	}
+}
+
+// TestBuild_Bundles tests that bundle URLs are exposed to templates
+func TestBuild_Bundles(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "bundles:\n  - name: css/site.css\n    files: [css/*.css]\n"
+	site["static/css/style.css"] = "body { color: black; }\n"
+	site["templates/base.html"] = `<link rel="stylesheet" href="{{ index .Bundles "css/site.css" }}" />{{template "posts" .}}`
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join("public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `href="/css/site.css?v=`) {
+		t.Errorf("index doesn't link the bundle. Got: %s", index)
+	}
+	if _, err := os.Stat(filepath.Join("public", "css", "site.css")); err != nil {
+		t.Errorf("bundle not written: %v", err)
+	}
+}
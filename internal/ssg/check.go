@@ -0,0 +1,569 @@
+package ssg
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+	"golang.org/x/net/html"
+)
+
+// problem is an issue with the site found by Check.
+type problem struct {
+	File    string // File the problem was found in
+	Message string
+}
+
+// Check validates the site without writing any output, so mistakes can be
+// caught in CI before a broken build is deployed.
+//
+// It reports, for posts and the entries of each section (see loadSections):
+//   - posts with invalid frontmatter, missing a title or date, or not
+//     matching the config's frontmatter schema (see FrontmatterConfig)
+//   - posts sharing a slug (and so an output file)
+//   - published posts dated in the future, and posts that expire before
+//     they're published
+//   - links in posts, section entries, and mounted pages to site paths that
+//     won't exist
+//   - with opts.External, links in them to other sites that are dead (see
+//     LinkCheckConfig)
+//   - missing or invalid template files
+//   - if the config enables it, spelling mistakes, passive voice, and wordy
+//     phrases in posts and section entries (see ProseConfig)
+//
+// Parameters:
+//   - opts: Check options; empty fields take their defaults
+//
+// Returns an error if the config can't be loaded or any problems are found.
+func Check(opts CheckOptions) error {
+	problems, err := checkSite(opts.withDefaults(), time.Now())
+	if err != nil {
+		return err
+	}
+	return reportProblems(os.Stdout, problems)
+}
+
+// reportProblems prints problems to w, returning an error if there are any.
+func reportProblems(w io.Writer, problems []problem) error {
+	if len(problems) == 0 {
+		fmt.Fprintln(w, "No problems found.")
+		return nil
+	}
+	for _, p := range problems {
+		fmt.Fprintf(w, "%s: %s\n", p.File, p.Message)
+	}
+	return fmt.Errorf("found %d problems", len(problems))
+}
+
+// checkSite does the work of Check, treating posts dated after now as
+// future-dated. Problems are sorted by file.
+func checkSite(opts CheckOptions, now time.Time) ([]problem, error) {
+	configPath := opts.ConfigPath
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	p := newParser(config)
+	var problems []problem
+	report := func(file, format string, args ...any) {
+		problems = append(problems, problem{File: file, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Templates
+	funcs, err := templateFuncs(*config, p)
+	if err != nil {
+		report(configPath, "%v", err)
+	}
+	usesDefaultTheme := checkTemplates(*config, funcs, report)
+
+	// Prose linting, done as content is checked
+	prose, err := newProseLinter(DirFS("."), config.Prose)
+	if err != nil {
+		report(configPath, "%v", err)
+	}
+
+	// Social preview image layout
+	if _, err := newOGImager(DirFS("."), config.OGImage); err != nil {
+		report(configPath, "%v", err)
+	}
+
+	// Host
+	if err := checkDeployTarget(config.DeployTarget); err != nil {
+		report(configPath, "%v", err)
+	} else if config.DeployTarget == HostCloudflare && (config.URLs == "" || config.URLs == URLStyleHTML) {
+		report(configPath, "deployTarget cloudflare redirects URLs ending in .html to ones without it; set urls to extensionless or slash")
+	}
+
+	// Theme colors
+	if err := config.Theme.Colors.check(); err != nil {
+		report(configPath, "%v", err)
+	}
+
+	// Package docs
+	for _, dir := range config.Godoc.Packages {
+		if _, err := packageSlug(dir); err != nil {
+			report(configPath, "%v", err)
+		}
+	}
+
+	// Favicon logo
+	if config.Favicons.Source != "" {
+		if _, err := loadFaviconSource(DirFS("."), config.Favicons.Source); err != nil {
+			report(configPath, "%v", err)
+		}
+	}
+
+	// Posts
+	published, files, err := checkContentDir(p, filepath.Join("content", "posts"), config.Frontmatter, prose, now, report)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.checkLanguages(); err != nil {
+		report(configPath, "%v", err)
+	}
+	for _, lang := range config.siteLanguages() {
+		langPosts, langFiles, err := checkContentDir(p, filepath.Join("content", lang), config.Frontmatter, prose, now, report)
+		if err != nil {
+			return nil, err
+		}
+		for _, post := range langPosts {
+			if post.Lang == "" {
+				post.Lang = lang
+			}
+			files[post] = langFiles[post]
+		}
+		published = append(published, langPosts...)
+	}
+	if err := assignLanguages(published, config); err != nil {
+		report(configPath, "%v", err)
+	}
+
+	if err := checkURLStyle(config.URLs); err != nil {
+		report(configPath, "%v", err)
+	}
+	if err := assignPostURLs(published, config.Permalink, config.URLs); err != nil {
+		report(configPath, "%v", err)
+	}
+	for _, post := range published {
+		post.URL = config.languageURL(post.Lang, post.URL)
+	}
+
+	// Sections
+	names, err := findSections(DirFS("."), "content", config.isContentFile, config.siteLanguages()...)
+	if err != nil {
+		return nil, err
+	}
+	var sections []*Section
+	for _, name := range names {
+		entries, entryFiles, err := checkContentDir(p, filepath.Join("content", filepath.FromSlash(name)), config.Frontmatter, prose, now, report)
+		if err != nil {
+			return nil, err
+		}
+		for _, post := range entries {
+			post.URL = pageURL(config.URLs, "/"+name+"/"+post.Slug)
+			files[post] = entryFiles[post]
+		}
+		sections = append(sections, &Section{Name: name, URL: "/" + name + "/", Posts: entries})
+	}
+
+	// Mounted pages
+	pages, err := loadMounts(DirFS("."), p, config.Mounts, config.URLs)
+	if err != nil {
+		report(configPath, "%v", err)
+	}
+
+	// Short links
+	shortlinks, err := loadShortlinks(DirFS("."), config.Shortlinks)
+	if err != nil {
+		report(shortlinksPath, "%v", err)
+	}
+
+	// Series and taxonomies
+	allPosts := slices.Clone(published)
+	for _, section := range sections {
+		allPosts = append(allPosts, section.Posts...)
+	}
+	if err := assignResources(DirFS("."), allPosts); err != nil {
+		return nil, err
+	}
+	if err := assignAttachments(DirFS("."), allPosts); err != nil {
+		report("content", "%v", err)
+	}
+	series, err := collectSeries(allPosts, config)
+	if err != nil {
+		report("content", "%v", err)
+	}
+	taxonomies, err := collectTaxonomies(allPosts, config)
+	if err != nil {
+		report(configPath, "%v", err)
+	}
+
+	// Internal links
+	known, err := sitePaths(*config, published, pages, sections, usesDefaultTheme)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range shortlinks {
+		known[link.Path] = true
+		known[link.Path+"index.html"] = true
+	}
+	for _, s := range series {
+		known[s.URL] = true
+	}
+	for _, tax := range taxonomies {
+		known[tax.URL] = true
+		known[tax.URL+"index.html"] = true
+		for _, term := range tax.Terms {
+			known[term.URL] = true
+		}
+	}
+	external := make(map[string][]string) // External URL → files linking to it
+	checkContent := func(file, pageURL, content string) {
+		checkLinks(file, pageURL, content, known, report)
+		if !opts.External {
+			return
+		}
+		for _, link := range externalLinks(content, config.BaseURL) {
+			if !slices.Contains(external[link], file) {
+				external[link] = append(external[link], file)
+			}
+		}
+	}
+	for _, post := range published {
+		checkContent(files[post], post.URL, string(post.Content))
+	}
+	for _, section := range sections {
+		for _, post := range section.Posts {
+			checkContent(files[post], post.URL, string(post.Content))
+		}
+	}
+	for i, page := range pages {
+		checkContent(config.Mounts[i].Source, page.URL, string(page.Content))
+	}
+
+	if prose != nil && prose.spellErr != nil {
+		report(configPath, "prose.spell: %v", prose.spellErr)
+	}
+
+	// External links
+	if opts.External {
+		if err := checkExternalLinks(external, config.LinkCheck, now, report); err != nil {
+			report(configPath, "%v", err)
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].File < problems[j].File })
+	return problems, nil
+}
+
+// checkContentDir checks the markdown files in dir (posts, or a section's
+// entries), reporting files that fail to parse or don't match schema (see
+// FrontmatterConfig), missing titles and dates, missing attachments,
+// duplicate slugs, and published entries dated after now. With prose, their prose is linted too,
+// drafts included.
+//
+// Returns the published entries and the file each was parsed from. A missing
+// dir has no entries.
+func checkContentDir(p *parser.Parser, dir string, schema FrontmatterConfig, prose *proseLinter, now time.Time, report func(file, format string, args ...any)) ([]*parser.Post, map[*parser.Post]string, error) {
+	paths, err := contentPaths(DirFS("."), dir, p.Handles)
+	if err != nil {
+		return nil, nil, err
+	}
+	var published []*parser.Post
+	files := make(map[*parser.Post]string)
+	slugs := make(map[string]string) // slug → file that claimed it
+	for _, file := range paths {
+		post, err := p.ParseFile(file)
+		var fmErr *parser.FrontmatterError
+		switch {
+		case errors.As(err, &fmErr):
+			report(fmErr.Position(), "%s", fmErr.Reason())
+			continue
+		case err != nil:
+			report(file, "%v", err)
+			continue
+		}
+		if filepath.Dir(file) != dir {
+			post.Bundle = filepath.Dir(file)
+		}
+
+		found, err := schema.validateFile(DirFS("."), file)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range found {
+			report(p.File, "%s", p.Message)
+		}
+		if prose != nil {
+			if err := prose.lint(file, report); err != nil {
+				return nil, nil, err
+			}
+		}
+		// The schema reports these itself if it requires them
+		if post.Title == "" && !slices.Contains(schema.Required, "title") {
+			report(file, "missing required field: title")
+		}
+		if post.Date.IsZero() && !slices.Contains(schema.Required, "date") {
+			report(file, "missing required field: date")
+		}
+		attachments := post.Attachments[:0]
+		for _, a := range post.Attachments {
+			if info, err := os.Stat(a.Path); err != nil || info.IsDir() {
+				report(file, "attachment %s: file not found", a.File)
+				continue
+			}
+			attachments = append(attachments, a)
+		}
+		post.Attachments = attachments
+		if other, ok := slugs[post.Slug]; ok {
+			report(file, "duplicate slug %q (also used by %s)", post.Slug, other)
+		} else {
+			slugs[post.Slug] = file
+		}
+		if !post.ExpiryDate.IsZero() && !post.ExpiryDate.After(publishTime(post)) {
+			report(file, "expiryDate (%s) isn't after the post is published (%s)", post.ExpiryDate.Format("2006-01-02"), publishTime(post).Format("2006-01-02"))
+		}
+		if post.Draft || expired(post, now) {
+			continue
+		}
+		if publishTime(post).After(now) {
+			report(file, "published post is dated in the future (%s)", publishTime(post).Format("2006-01-02"))
+		}
+		published = append(published, post)
+		files[post] = file
+	}
+	return published, files, nil
+}
+
+// checkTemplates reports missing content templates and templates that fail to
+// parse. Returns whether the embedded default theme will be used.
+func checkTemplates(config SiteConfig, funcs template.FuncMap, report func(file, format string, args ...any)) bool {
+	themeDir, err := config.Theme.dir(DirFS("."))
+	if err != nil {
+		report(path.Join(themesDir, config.Theme.Name), "%v", err)
+	}
+	if err := checkSandbox(DirFS("."), config, "templates", themeDir); err != nil {
+		report(path.Join(themesDir, config.Theme.Name), "%v", err)
+	}
+	fsys, isDefault := templateFS(DirFS("."), "templates", themeDir)
+
+	required := []string{"base.html", "posts.html", "post.html"}
+	if len(config.Mounts) > 0 {
+		required = append(required, "page.html")
+	}
+	if len(config.Godoc.Packages) > 0 {
+		required = append(required, "package.html")
+	}
+	var missing bool
+	for _, name := range required {
+		if _, err := fs.Stat(fsys, name); err != nil {
+			report(path.Join("templates", name), "missing template")
+			missing = true
+		}
+	}
+	if missing {
+		return isDefault
+	}
+
+	r, err := newRenderer(DirFS("."), "templates", themeDir, funcs)
+	if err != nil {
+		report("templates", "%v", err)
+		return isDefault
+	}
+	for _, name := range required[1:] {
+		if _, err := r.pageTemplate(name); err != nil {
+			report(path.Join("templates", name), "%v", err)
+		}
+	}
+	return isDefault
+}
+
+// sitePaths returns the URL paths a build would generate: pages, section
+// list pages and entries and their page bundle resources and attachments,
+// redirects, static files, and bundles.
+func sitePaths(config SiteConfig, posts, pages []*parser.Post, sections []*Section, useDefaultTheme bool) (map[string]bool, error) {
+	known := map[string]bool{"/": true, "/index.html": true}
+	for _, lang := range config.siteLanguages() {
+		known[config.languageURL(lang, "/")] = true
+		known[config.languageURL(lang, "/index.html")] = true
+	}
+	for _, post := range posts {
+		known[post.URL] = true
+		for _, resource := range post.Resources {
+			known[resource] = true
+		}
+		for _, a := range post.Attachments {
+			known[a.URL] = true
+		}
+	}
+	for _, section := range sections {
+		known[section.URL] = true
+		known[section.URL+"index.html"] = true
+		for _, post := range section.Posts {
+			known[post.URL] = true
+			for _, resource := range post.Resources {
+				known[resource] = true
+			}
+			for _, a := range post.Attachments {
+				known[a.URL] = true
+			}
+		}
+	}
+	for _, page := range pages {
+		known[page.URL] = true
+	}
+	for _, dir := range config.Godoc.Packages {
+		if slug, err := packageSlug(dir); err == nil {
+			known[pageURL(config.URLs, "/pkg/"+slug)] = true
+		}
+	}
+	for _, b := range config.Bundles {
+		known["/"+b.Name] = true
+	}
+	var sectionPosts []*parser.Post
+	for _, section := range sections {
+		sectionPosts = append(sectionPosts, section.Posts...)
+	}
+	redirects, err := siteRedirects(config, posts, sectionPosts, pages)
+	if err != nil {
+		return nil, err
+	}
+	for from := range redirects {
+		known[from] = true
+	}
+
+	addFiles := func(fsys fs.FS) error {
+		return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				known["/"+p] = true
+			}
+			return err
+		})
+	}
+	if useDefaultTheme {
+		sub, err := fs.Sub(defaultTheme, "theme/static")
+		if err != nil {
+			return nil, err
+		}
+		if err := addFiles(sub); err != nil {
+			return nil, err
+		}
+	}
+	if themeDir, err := config.Theme.dir(DirFS(".")); err == nil && themeDir != "" {
+		themeStatic := filepath.Join(themeDir, "static")
+		if _, err := os.Stat(themeStatic); err == nil {
+			if err := addFiles(os.DirFS(themeStatic)); err != nil {
+				return nil, fmt.Errorf("listing theme static files: %w", err)
+			}
+		}
+	}
+	if _, err := os.Stat("static"); err == nil {
+		if err := addFiles(os.DirFS("static")); err != nil {
+			return nil, fmt.Errorf("listing static files: %w", err)
+		}
+	}
+
+	return known, nil
+}
+
+// checkLinks reports href and src attributes in content that point at site
+// paths missing from known. pageURL is the URL the content is published at,
+// used to resolve relative links.
+func checkLinks(file, pageURL, content string, known map[string]bool, report func(file, format string, args ...any)) {
+	for _, link := range internalLinks(pageURL, content) {
+		if !known[link] && !known[strings.TrimSuffix(link, "/")+"/index.html"] {
+			report(file, "broken link to %s", link)
+		}
+	}
+}
+
+// internalLinks returns the site paths linked from HTML content (via href,
+// src, or srcset), resolved against pageURL. External URLs and fragment-only links are
+// skipped.
+func internalLinks(pageURL, content string) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	var links []string
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return links
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tok := z.Token()
+		refs := []string{attr(tok, "href"), attr(tok, "src")}
+		for _, candidate := range strings.Split(attr(tok, "srcset"), ",") {
+			// Each candidate is a URL optionally followed by a descriptor
+			if fields := strings.Fields(candidate); len(fields) > 0 {
+				refs = append(refs, fields[0])
+			}
+		}
+		for _, ref := range refs {
+			if ref == "" {
+				continue
+			}
+			u, err := url.Parse(ref)
+			if err != nil || u.Scheme != "" || u.Host != "" || (u.Path == "" && u.Opaque == "") {
+				continue
+			}
+			links = append(links, base.ResolveReference(u).Path)
+		}
+	}
+}
+
+// externalLinks returns the http(s) URLs linked from HTML content (via href,
+// src, or srcset) on other hosts than the site's at baseURL, without
+// fragments.
+func externalLinks(content, baseURL string) []string {
+	var siteHost string
+	if u, err := url.Parse(baseURL); err == nil {
+		siteHost = u.Host
+	}
+
+	var links []string
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return links
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tok := z.Token()
+		refs := []string{attr(tok, "href"), attr(tok, "src")}
+		for _, candidate := range strings.Split(attr(tok, "srcset"), ",") {
+			if fields := strings.Fields(candidate); len(fields) > 0 {
+				refs = append(refs, fields[0])
+			}
+		}
+		for _, ref := range refs {
+			u, err := url.Parse(ref)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.EqualFold(u.Host, siteHost) {
+				continue
+			}
+			u.Fragment = ""
+			if link := u.String(); !slices.Contains(links, link) {
+				links = append(links, link)
+			}
+		}
+	}
+}
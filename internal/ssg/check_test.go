@@ -0,0 +1,92 @@
+package ssg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCheckSite tests that each kind of problem is reported
+func TestCheckSite(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["content/posts/2024-02-01-links.md"] = "---\ntitle: Links\ndate: 2024-02-01T10:00:00Z\n---\n\n" +
+		"[ok](/posts/first.html) [relative](first.html) [home](/) [css](/css/style.css) " +
+		"[missing](/posts/nope.html) [external](https://example.com/x) [anchor](#top)\n"
+	site["content/posts/2024-03-01-first.md"] = "---\ntitle: Duplicate\ndate: 2024-03-01T10:00:00Z\n---\n\nSame slug.\n"
+	site["content/posts/untitled.md"] = "---\ndate: 2024-01-01T10:00:00Z\n---\n\nNo title.\n"
+	site["content/posts/future.md"] = "---\ntitle: Future\ndate: 2030-01-01T10:00:00Z\n---\n\nLater.\n"
+	site["content/posts/future-draft.md"] = "---\ntitle: Future Draft\ndate: 2030-01-01T10:00:00Z\ndraft: true\n---\n\nLater.\n"
+	site["content/posts/expired.md"] = "---\ntitle: Expired\ndate: 2020-01-01T10:00:00Z\nexpiryDate: 2020-02-01T00:00:00Z\n---\n\nGone.\n"
+	site["content/posts/backwards.md"] = "---\ntitle: Backwards\ndate: 2020-01-01T10:00:00Z\nexpiryDate: 2019-12-01T00:00:00Z\n---\n\nNever shown.\n"
+	site["content/posts/broken.md"] = "no frontmatter here"
+	site["static/css/style.css"] = "body {}"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	problems, err := checkSite(CheckOptions{ConfigPath: "config.yaml"}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("checkSite() failed: %v", err)
+	}
+
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.File+": "+p.Message)
+	}
+	report := strings.Join(got, "\n")
+
+	want := []string{
+		"content/posts/2024-02-01-links.md: broken link to /posts/nope.html",
+		"content/posts/2024-03-01-first.md: duplicate slug \"first\"",
+		"content/posts/backwards.md: expiryDate (2019-12-01) isn't after the post is published (2020-01-01)",
+		"content/posts/broken.md:1: missing frontmatter: the file must start with a --- line",
+		"content/posts/future.md: published post is dated in the future (2030-01-01)",
+		"content/posts/untitled.md: missing required field: title",
+	}
+	for _, w := range want {
+		if !strings.Contains(report, w) {
+			t.Errorf("report missing %q, got:\n%s", w, report)
+		}
+	}
+	if len(problems) != len(want) {
+		t.Errorf("got %d problems, want %d:\n%s", len(problems), len(want), report)
+	}
+}
+
+// TestCheckSite_MissingTemplate tests that missing content templates are reported
+func TestCheckSite_MissingTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	delete(site, "templates/post.html")
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	problems, err := checkSite(CheckOptions{ConfigPath: "config.yaml"}, time.Now())
+	if err != nil {
+		t.Fatalf("checkSite() failed: %v", err)
+	}
+	if len(problems) != 1 || problems[0].File != "templates/post.html" {
+		t.Errorf("problems = %v, want missing templates/post.html", problems)
+	}
+}
+
+// TestReportProblems tests the report output and exit status
+func TestReportProblems(t *testing.T) {
+	var buf bytes.Buffer
+	if err := reportProblems(&buf, nil); err != nil {
+		t.Errorf("reportProblems() with no problems returned %v", err)
+	}
+	if !strings.Contains(buf.String(), "No problems found.") {
+		t.Errorf("output = %q, want success message", buf.String())
+	}
+
+	buf.Reset()
+	err := reportProblems(&buf, []problem{{File: "a.md", Message: "bad"}})
+	if err == nil {
+		t.Error("reportProblems() with problems returned nil, want error")
+	}
+	if buf.String() != "a.md: bad\n" {
+		t.Errorf("output = %q, want %q", buf.String(), "a.md: bad\n")
+	}
+}
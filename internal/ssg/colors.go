@@ -0,0 +1,126 @@
+package ssg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// colorsStylesheet is the site-relative URL of the stylesheet generated
+// from theme.colors.
+const colorsStylesheet = "/css/variables.css"
+
+// ThemeColors is the palette under theme.colors: CSS custom properties by
+// name, for light mode and for dark mode, so a theme's colors can be changed
+// from the config alone. The build writes them to /css/variables.css, which
+// .Head links, and templates can read them too, e.g.
+// {{ .Site.Theme.Colors.Light.accent }}.
+//
+// Names are the custom properties the theme's stylesheet uses, without the
+// leading "--"; the default theme's are bg, text, muted, and accent. The
+// dark colors apply when the visitor's system prefers a dark scheme, unless
+// the page's <html> has data-theme="light", and whenever it has
+// data-theme="dark", for sites with a toggle. The generated rules are more
+// specific than :root, so they override the theme's own values wherever
+// .Head is in the page.
+//
+// Example config.yaml:
+//
+//	theme:
+//	  colors:
+//	    light:
+//	      bg: "#ffffff"
+//	      text: "#1f2937"
+//	      accent: "#2563eb"
+//	    dark:
+//	      bg: "#0f172a"
+//	      text: "#e5e7eb"
+//	      accent: "#60a5fa"
+type ThemeColors struct {
+	Light map[string]string `yaml:"light,omitempty"` // Custom property name → value, e.g. accent: "#2563eb"
+	Dark  map[string]string `yaml:"dark,omitempty"`  // Values in dark mode (default: the light ones)
+}
+
+// cssPropertyName matches a custom property name without its "--".
+var cssPropertyName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// enabled reports whether the palette has any colors.
+func (c ThemeColors) enabled() bool {
+	return len(c.Light) > 0 || len(c.Dark) > 0
+}
+
+// check returns an error naming the first color that isn't a valid custom
+// property: a name that isn't a CSS identifier, or a value that could break
+// out of its declaration.
+func (c ThemeColors) check() error {
+	for _, mode := range []struct {
+		name   string
+		colors map[string]string
+	}{{"light", c.Light}, {"dark", c.Dark}} {
+		for _, name := range sortedKeys(mode.colors) {
+			value := mode.colors[name]
+			switch {
+			case !cssPropertyName.MatchString(name):
+				return fmt.Errorf("theme.colors.%s: invalid name %q, want e.g. accent", mode.name, name)
+			case strings.TrimSpace(value) == "" || strings.ContainsAny(value, ";{}<>\n"):
+				return fmt.Errorf("theme.colors.%s.%s: invalid value %q", mode.name, name, value)
+			}
+		}
+	}
+	return nil
+}
+
+// css returns the stylesheet of the palette (see ThemeColors).
+func (c ThemeColors) css() string {
+	var b strings.Builder
+	declarations := func(colors map[string]string, indent string) {
+		for _, name := range sortedKeys(colors) {
+			fmt.Fprintf(&b, "%s--%s: %s;\n", indent, name, strings.TrimSpace(colors[name]))
+		}
+	}
+	if len(c.Light) > 0 {
+		b.WriteString("html:root {\n")
+		declarations(c.Light, "  ")
+		b.WriteString("}\n")
+	}
+	if len(c.Dark) > 0 {
+		b.WriteString("\n@media (prefers-color-scheme: dark) {\n  html:root:not([data-theme=\"light\"]) {\n")
+		declarations(c.Dark, "    ")
+		b.WriteString("  }\n}\n\nhtml[data-theme=\"dark\"]:root {\n")
+		declarations(c.Dark, "  ")
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// writeThemeColors writes the stylesheet of the palette to outputDir in out,
+// unless the palette is empty or a file copied from static/ is already
+// there.
+func writeThemeColors(out FS, c ThemeColors, outputDir string) error {
+	if !c.enabled() {
+		return nil
+	}
+	if err := c.check(); err != nil {
+		return err
+	}
+	path := urlPath(outputDir, colorsStylesheet)
+	if _, err := fs.Stat(out, path); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return out.WriteFile(path, []byte(c.css()), 0600)
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
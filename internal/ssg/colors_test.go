@@ -0,0 +1,95 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestThemeColors_CSS tests the stylesheet of a palette
+func TestThemeColors_CSS(t *testing.T) {
+	c := ThemeColors{
+		Light: map[string]string{"text": "#111", "accent": "#2563eb"},
+		Dark:  map[string]string{"text": " #eee "},
+	}
+	want := `html:root {
+  --accent: #2563eb;
+  --text: #111;
+}
+
+@media (prefers-color-scheme: dark) {
+  html:root:not([data-theme="light"]) {
+    --text: #eee;
+  }
+}
+
+html[data-theme="dark"]:root {
+  --text: #eee;
+}
+`
+	if got := c.css(); got != want {
+		t.Errorf("css() = %q, want %q", got, want)
+	}
+	if got := (ThemeColors{Light: map[string]string{"bg": "white"}}).css(); got != "html:root {\n  --bg: white;\n}\n" {
+		t.Errorf("css() without dark colors = %q", got)
+	}
+}
+
+// TestThemeColors_Check tests rejecting names and values that aren't valid
+// custom properties
+func TestThemeColors_Check(t *testing.T) {
+	tests := []struct {
+		colors ThemeColors
+		err    string
+	}{
+		{ThemeColors{Light: map[string]string{"accent": "rgb(0 0 0 / 50%)", "text-main": "#333"}}, ""},
+		{ThemeColors{Light: map[string]string{"--accent": "#000"}}, `theme.colors.light: invalid name "--accent"`},
+		{ThemeColors{Dark: map[string]string{"bg": "#000; } body { display: none"}}, "theme.colors.dark.bg: invalid value"},
+		{ThemeColors{Dark: map[string]string{"bg": "</style>"}}, "theme.colors.dark.bg: invalid value"},
+		{ThemeColors{Light: map[string]string{"bg": " "}}, "theme.colors.light.bg: invalid value"},
+	}
+	for _, tt := range tests {
+		err := tt.colors.check()
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("check(%v) = %v, want %q", tt.colors, err, tt.err)
+		}
+	}
+}
+
+// TestBuild_ThemeColors tests writing the palette's stylesheet and exposing
+// it to templates
+func TestBuild_ThemeColors(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "theme:\n  colors:\n    light:\n      accent: \"#2563eb\"\n    dark:\n      accent: \"#60a5fa\"\n"
+	site["templates/base.html"] = "<head>{{ .Head }}</head><p>{{ .Site.Theme.Colors.Light.accent }} {{ .Site.Theme.Colors.Dark.accent }}</p>"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	css, err := os.ReadFile("public/css/variables.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(css), "--accent: #2563eb;") || !strings.Contains(string(css), "--accent: #60a5fa;") {
+		t.Errorf("variables.css = %q, want both accents", css)
+	}
+	page, err := os.ReadFile("public/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<link rel="stylesheet" href="/css/variables.css" />`, "<p>#2563eb #60a5fa</p>"} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("page missing %q:\n%s", want, page)
+		}
+	}
+
+	// Invalid colors fail the build
+	writeFiles(t, tmpDir, map[string]string{"config.yaml": site["config.yaml"] + "      bg: \"red; }\"\n"})
+	if err := Build(context.Background(), BuildOptions{}); err == nil || !strings.Contains(err.Error(), "theme.colors.dark.bg") {
+		t.Errorf("Build() error = %v, want one naming theme.colors.dark.bg", err)
+	}
+}
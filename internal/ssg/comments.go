@@ -0,0 +1,227 @@
+package ssg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// commentsCachePath is where fetched comment counts are cached between
+// builds, relative to the site root.
+var commentsCachePath = filepath.Join(".ssg", "comments.json")
+
+// defaultCommentsMaxAge is how long a cached count is used before it's
+// fetched again, unless comments.maxAge is set.
+const defaultCommentsMaxAge = time.Hour
+
+// maxCommentsResponse limits the size of a comment API response.
+const maxCommentsResponse = 1 << 20
+
+// commentsClient fetches comment counts.
+var commentsClient = &http.Client{Timeout: 10 * time.Second}
+
+// mastodonStatusPattern matches the path of a Mastodon status URL, in the
+// web form (/@user/<id>) or the ActivityPub form (/users/user/statuses/<id>).
+var mastodonStatusPattern = regexp.MustCompile(`^/(?:@[^/]+|users/[^/]+/statuses)/(\d+)/?$`)
+
+// CommentsConfig fetches the number of comments on posts whose frontmatter
+// links a comment thread, for templates to show as .Post.CommentCount.
+//
+// Example config.yaml:
+//
+//	comments:
+//	  enabled: true
+//	  provider: mastodon
+//
+// with "comments: https://mastodon.social/@you/112233445566" in a post's
+// frontmatter. With provider json, the comments URL is fetched as is and
+// the count read from the field of the response named by field.
+type CommentsConfig struct {
+	Enabled  bool   `yaml:"enabled"`  // Fetch comment counts when building
+	Provider string `yaml:"provider"` // "mastodon" (default) or "json"
+	Field    string `yaml:"field"`    // For json: dot-separated path to the count (default: "count")
+	MaxAge   string `yaml:"maxAge"`   // How long cached counts are reused, e.g. "30m" (default: 1h)
+}
+
+// cachedCount is a comment count in the cache.
+type cachedCount struct {
+	Count   int       `json:"count"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// fetchCommentCounts sets CommentCount on each post with a CommentsURL.
+// Counts are cached in .ssg/comments.json and reused until they're older
+// than maxAge, so rebuilds (and serve's rebuilds especially) don't query the
+// comment host every time. A count that can't be fetched is printed as a
+// warning and the cached count, however old, is used instead, so a comment
+// host being down never fails a build.
+//
+// Parameters:
+//   - posts: Posts to set counts on
+//   - cfg: Comments configuration from config.yaml
+//   - now: Time of the build, to check cached counts' age against
+//
+// Returns an error if the configuration is invalid. Does nothing if
+// comments are disabled.
+func fetchCommentCounts(posts []*parser.Post, cfg CommentsConfig, now time.Time) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	maxAge := defaultCommentsMaxAge
+	if cfg.MaxAge != "" {
+		d, err := time.ParseDuration(cfg.MaxAge)
+		if err != nil {
+			return fmt.Errorf("comments.maxAge: %w", err)
+		}
+		maxAge = d
+	}
+	var fetch func(string) (int, error)
+	switch cfg.Provider {
+	case "", "mastodon":
+		fetch = fetchMastodonCount
+	case "json":
+		field := cfg.Field
+		if field == "" {
+			field = "count"
+		}
+		fetch = func(rawURL string) (int, error) { return fetchJSONCount(rawURL, field) }
+	default:
+		return fmt.Errorf("unknown comments.provider %q (want mastodon or json)", cfg.Provider)
+	}
+
+	cache := readCommentsCache()
+	changed := false
+	for _, post := range posts {
+		if post.CommentsURL == "" {
+			continue
+		}
+		cached, ok := cache[post.CommentsURL]
+		if !ok || now.Sub(cached.Fetched) >= maxAge {
+			count, err := fetch(post.CommentsURL)
+			if err != nil {
+				slog.Warn("fetching comment count", "post", post.Slug, "error", err)
+			} else {
+				cached = cachedCount{Count: count, Fetched: now}
+				cache[post.CommentsURL] = cached
+				changed = true
+			}
+		}
+		post.CommentCount = cached.Count
+	}
+
+	if changed {
+		if err := writeCommentsCache(cache); err != nil {
+			slog.Warn("caching comment counts", "error", err)
+		}
+	}
+	return nil
+}
+
+// fetchMastodonCount returns the number of replies to the Mastodon status
+// at statusURL (e.g., "https://mastodon.social/@you/112233445566"), from
+// the replies_count of the instance's status API.
+func fetchMastodonCount(statusURL string) (int, error) {
+	u, err := url.Parse(statusURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return 0, fmt.Errorf("%q isn't an http(s) URL", statusURL)
+	}
+	m := mastodonStatusPattern.FindStringSubmatch(u.Path)
+	if m == nil {
+		return 0, fmt.Errorf("%q isn't a Mastodon status URL", statusURL)
+	}
+	apiURL := u.Scheme + "://" + u.Host + "/api/v1/statuses/" + m[1]
+
+	var status struct {
+		RepliesCount *int `json:"replies_count"`
+	}
+	if err := getJSON(apiURL, &status); err != nil {
+		return 0, err
+	}
+	if status.RepliesCount == nil {
+		return 0, fmt.Errorf("%s has no replies_count", apiURL)
+	}
+	return *status.RepliesCount, nil
+}
+
+// fetchJSONCount fetches rawURL and returns the number at field, a
+// dot-separated path into the JSON response (e.g., "data.count").
+func fetchJSONCount(rawURL, field string) (int, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return 0, fmt.Errorf("%q isn't an http(s) URL", rawURL)
+	}
+	var v any
+	if err := getJSON(rawURL, &v); err != nil {
+		return 0, err
+	}
+	for _, key := range strings.Split(field, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return 0, fmt.Errorf("%s: no field %q", rawURL, field)
+		}
+		v = obj[key]
+	}
+	n, ok := v.(float64)
+	if !ok || n < 0 || n != float64(int(n)) {
+		return 0, fmt.Errorf("%s: field %q isn't a count", rawURL, field)
+	}
+	return int(n), nil
+}
+
+// getJSON fetches rawURL and decodes its JSON body into v.
+func getJSON(rawURL string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := commentsClient.Do(req) // #nosec G107 -- URL comes from the site's own frontmatter
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", rawURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCommentsResponse))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", rawURL, err)
+	}
+	return nil
+}
+
+// readCommentsCache loads the cached comment counts. A missing or corrupt
+// cache is treated as empty.
+func readCommentsCache() map[string]cachedCount {
+	cache := make(map[string]cachedCount)
+	data, err := os.ReadFile(commentsCachePath)
+	if err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// writeCommentsCache saves the cached comment counts.
+func writeCommentsCache(cache map[string]cachedCount) error {
+	if err := os.MkdirAll(filepath.Dir(commentsCachePath), 0750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(commentsCachePath, append(data, '\n'), 0600)
+}
@@ -0,0 +1,94 @@
+package ssg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestFetchCommentCounts tests fetching, caching, and falling back to cached counts
+func TestFetchCommentCounts(t *testing.T) {
+	t.Chdir(t.TempDir())
+	requests := 0
+	down := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if down {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/statuses/1234":
+			_, _ = w.Write([]byte(`{"id": "1234", "replies_count": 7}`))
+		case "/thread.json":
+			_, _ = w.Write([]byte(`{"data": {"comments": 3}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	posts := []*parser.Post{
+		{Slug: "a", CommentsURL: srv.URL + "/@me/1234"},
+		{Slug: "b", CommentsURL: srv.URL + "/users/me/statuses/1234"},
+		{Slug: "c"},
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := fetchCommentCounts(posts, CommentsConfig{Enabled: true}, now); err != nil {
+		t.Fatalf("fetchCommentCounts() failed: %v", err)
+	}
+	if posts[0].CommentCount != 7 || posts[1].CommentCount != 7 || posts[2].CommentCount != 0 {
+		t.Errorf("counts = %d, %d, %d; want 7, 7, 0", posts[0].CommentCount, posts[1].CommentCount, posts[2].CommentCount)
+	}
+	if requests != 2 {
+		t.Errorf("made %d requests, want 2", requests)
+	}
+
+	// Fresh counts come from the cache; stale ones are used when the host is down
+	requests = 0
+	posts[0].CommentCount = 0
+	if err := fetchCommentCounts(posts[:1], CommentsConfig{Enabled: true}, now.Add(30*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 0 || posts[0].CommentCount != 7 {
+		t.Errorf("cached fetch made %d requests, count %d; want 0 requests and 7", requests, posts[0].CommentCount)
+	}
+	down = true
+	posts[0].CommentCount = 0
+	if err := fetchCommentCounts(posts[:1], CommentsConfig{Enabled: true, MaxAge: "10m"}, now.Add(30*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 || posts[0].CommentCount != 7 {
+		t.Errorf("stale fetch made %d requests, count %d; want 1 request and the cached 7", requests, posts[0].CommentCount)
+	}
+	if cache := readCommentsCache(); len(cache) != 2 {
+		t.Errorf("cache = %v, want 2 entries", cache)
+	}
+
+	down = false
+	post := &parser.Post{Slug: "d", CommentsURL: srv.URL + "/thread.json"}
+	if err := fetchCommentCounts([]*parser.Post{post}, CommentsConfig{Enabled: true, Provider: "json", Field: "data.comments"}, now); err != nil {
+		t.Fatal(err)
+	}
+	if post.CommentCount != 3 {
+		t.Errorf("json count = %d, want 3", post.CommentCount)
+	}
+
+	for _, bad := range []CommentsConfig{{Enabled: true, Provider: "disqus"}, {Enabled: true, MaxAge: "soon"}} {
+		if err := fetchCommentCounts(posts, bad, now); err == nil {
+			t.Errorf("fetchCommentCounts(%+v) succeeded, want error", bad)
+		}
+	}
+}
+
+// TestFetchMastodonCount_InvalidURL tests rejecting URLs that aren't Mastodon statuses
+func TestFetchMastodonCount_InvalidURL(t *testing.T) {
+	for _, bad := range []string{"ftp://host/@me/1", "https://host/@me", "https://host/@me/1/replies", "not a url"} {
+		if _, err := fetchMastodonCount(bad); err == nil {
+			t.Errorf("fetchMastodonCount(%q) succeeded, want error", bad)
+		}
+	}
+}
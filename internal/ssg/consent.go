@@ -0,0 +1,142 @@
+package ssg
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// consentScriptURL is where the consent script is written, relative to the
+// site root.
+const consentScriptURL = "/js/consent.js"
+
+// consentJS shows the consent banner and loads analytics and embeds once the
+// visitor accepts. writeConsentScript prefixes it with the site's settings.
+//
+//go:embed assets/consent.js
+var consentJS string
+
+// ConsentConfig adds a cookie consent banner to every page, and holds back
+// analytics and embeds until the visitor accepts, so themes don't have to
+// implement consent themselves.
+//
+// Example config.yaml:
+//
+//	consent:
+//	  enabled: true
+//	  message: We'd like to use cookies to count visits.
+//	  policyUrl: /privacy.html
+//	  scripts:
+//	    - src: https://www.googletagmanager.com/gtag/js?id=G-XXXXXXX
+//	    - inline: "window.dataLayer = window.dataLayer || []; ..."
+//
+// Templates and posts can hold back their own scripts with
+// <script type="text/plain" data-consent> and embeds with
+// <iframe data-consent-src="...">, and add a link to change the choice with
+// a data-consent-open attribute.
+type ConsentConfig struct {
+	Enabled    bool            `yaml:"enabled"`    // Add the banner and consent script
+	Message    string          `yaml:"message"`    // Banner text
+	Accept     string          `yaml:"accept"`     // Accept button label (default: "Accept")
+	Reject     string          `yaml:"reject"`     // Reject button label (default: "Reject")
+	PolicyURL  string          `yaml:"policyUrl"`  // Link to the privacy policy, shown after the message
+	PolicyText string          `yaml:"policyText"` // Text of the policy link (default: "Privacy policy")
+	Scripts    []ConsentScript `yaml:"scripts"`    // Scripts loaded only after consent
+}
+
+// ConsentScript is a script loaded once the visitor accepts: an external
+// script by URL, or inline code.
+type ConsentScript struct {
+	Src    string `yaml:"src" json:"src,omitempty"`
+	Inline string `yaml:"inline" json:"inline,omitempty"`
+}
+
+// consentSettings are the settings the consent script reads from
+// window.ssgConsent.
+type consentSettings struct {
+	Message    string          `json:"message"`
+	Accept     string          `json:"accept"`
+	Reject     string          `json:"reject"`
+	PolicyURL  string          `json:"policyUrl,omitempty"`
+	PolicyText string          `json:"policyText"`
+	Scripts    []ConsentScript `json:"scripts"`
+}
+
+// writeConsentScript writes the consent script to outputDir in out, with the
+// banner text and scripts from cfg. Does nothing if consent is disabled.
+//
+// Returns an error if a script has neither src nor inline code, or the file
+// can't be written.
+func writeConsentScript(out FS, outputDir string, cfg ConsentConfig, minify bool) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	settings := consentSettings{
+		Message:    cfg.Message,
+		Accept:     cfg.Accept,
+		Reject:     cfg.Reject,
+		PolicyURL:  cfg.PolicyURL,
+		PolicyText: cfg.PolicyText,
+		Scripts:    cfg.Scripts,
+	}
+	if settings.Message == "" {
+		settings.Message = "This site uses cookies for analytics."
+	}
+	if settings.Accept == "" {
+		settings.Accept = "Accept"
+	}
+	if settings.Reject == "" {
+		settings.Reject = "Reject"
+	}
+	if settings.PolicyText == "" {
+		settings.PolicyText = "Privacy policy"
+	}
+	if settings.Scripts == nil {
+		settings.Scripts = []ConsentScript{}
+	}
+	for i, s := range settings.Scripts {
+		if (s.Src == "") == (s.Inline == "") {
+			return fmt.Errorf("consent.scripts[%d] needs exactly one of src and inline", i)
+		}
+	}
+
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	data := []byte("window.ssgConsent = " + string(settingsJSON) + ";\n" + consentJS)
+	if minify {
+		data = minifyJS(data)
+	}
+
+	return out.WriteFile(urlPath(outputDir, consentScriptURL), data, 0600)
+}
+
+// consentTransformer returns an HTMLTransformer that loads the consent
+// script in every page's <head>.
+func consentTransformer() HTMLTransformer {
+	return func(doc *html.Node, _ *RenderedPage) error {
+		var head *html.Node
+		walkHTML(doc, func(n *html.Node) {
+			if head == nil && n.Type == html.ElementNode && n.DataAtom == atom.Head {
+				head = n
+			}
+		})
+		if head == nil {
+			return nil
+		}
+		head.AppendChild(&html.Node{
+			Type:     html.ElementNode,
+			Data:     "script",
+			DataAtom: atom.Script,
+			Attr: []html.Attribute{
+				{Key: "src", Val: consentScriptURL},
+				{Key: "defer"},
+			},
+		})
+		return nil
+	}
+}
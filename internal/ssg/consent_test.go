@@ -0,0 +1,75 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWriteConsentScript tests writing the consent script with the site's settings
+func TestWriteConsentScript(t *testing.T) {
+	outputDir := t.TempDir()
+	cfg := ConsentConfig{
+		Enabled:   true,
+		Message:   `Cookies? "Yes" </script>`,
+		PolicyURL: "/privacy.html",
+		Scripts:   []ConsentScript{{Src: "https://analytics.example.com/a.js"}},
+	}
+	if err := writeConsentScript(DirFS("."), outputDir, cfg, false); err != nil {
+		t.Fatalf("writeConsentScript() failed: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(outputDir, "js", "consent.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`window.ssgConsent = {"message":"Cookies? \"Yes\" \u003c/script\u003e","accept":"Accept","reject":"Reject",`,
+		`"policyUrl":"/privacy.html","policyText":"Privacy policy","scripts":[{"src":"https://analytics.example.com/a.js"}]};`,
+		"function load()",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("consent.js missing %s", want)
+		}
+	}
+
+	cfg.Scripts = []ConsentScript{{Src: "a.js", Inline: "b()"}}
+	if err := writeConsentScript(DirFS("."), outputDir, cfg, false); err == nil {
+		t.Error("writeConsentScript() with src and inline succeeded, want error")
+	}
+
+	empty := t.TempDir()
+	if err := writeConsentScript(DirFS("."), empty, ConsentConfig{}, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(empty, "js")); !os.IsNotExist(err) {
+		t.Error("consent script written while disabled")
+	}
+}
+
+// TestBuild_Consent tests that every page loads the consent script
+func TestBuild_Consent(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "minify: true\nconsent:\n  enabled: true\n  scripts:\n    - inline: \"track()\"\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{Strict: true}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	for _, page := range []string{"index.html", "posts/first.html"} {
+		got, err := os.ReadFile(filepath.Join("public", filepath.FromSlash(page)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(got), `<script src="/js/consent.js" defer`) {
+			t.Errorf("%s doesn't load the consent script:\n%s", page, got)
+		}
+	}
+	script, err := os.ReadFile(filepath.Join("public", "js", "consent.js"))
+	if err != nil || !strings.Contains(string(script), `"inline":"track()"`) {
+		t.Errorf("consent.js = %.200s, %v", script, err)
+	}
+}
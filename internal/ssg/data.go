@@ -0,0 +1,83 @@
+package ssg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// dataDir is the directory of data files, relative to the site root.
+const dataDir = "data"
+
+// dataExts are the extensions of the files loadData reads.
+var dataExts = map[string]bool{".yaml": true, ".yml": true, ".json": true, ".toml": true}
+
+// loadData reads the YAML, JSON, and TOML files under dir in fsys into a map
+// exposed to templates as .Site.Data, keyed by file name without its
+// extension. Subdirectories become nested maps, so data/projects.yaml is
+// .Site.Data.projects and data/talks/2024.json is .Site.Data.talks "2024"
+// (reached with index, since the key starts with a digit). Other files and
+// those starting with "." are ignored.
+//
+// Returns an empty map if dir doesn't exist, or an error if a file can't be
+// read or parsed, or two files would have the same key (like projects.yaml
+// and projects.json).
+func loadData(fsys fs.FS, dir string) (map[string]any, error) {
+	data := make(map[string]any)
+	err := fs.WalkDir(fsys, dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != dir {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !dataExts[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		keys := strings.Split(filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))), "/")
+		parent := data
+		for _, key := range keys[:len(keys)-1] {
+			child, ok := parent[key].(map[string]any)
+			if !ok {
+				if _, taken := parent[key]; taken {
+					return fmt.Errorf("%s: %s is both a file and a directory", path, key)
+				}
+				child = make(map[string]any)
+				parent[key] = child
+			}
+			parent = child
+		}
+		key := keys[len(keys)-1]
+		if _, taken := parent[key]; taken {
+			return fmt.Errorf("%s: another file or directory under %s is also named %s", path, dir, key)
+		}
+
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		var value any
+		if err := decodeByExt(path, content, &value); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		parent[key] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
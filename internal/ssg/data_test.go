@@ -0,0 +1,70 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadData tests reading data files into nested maps
+func TestLoadData(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"projects.yaml":   "- name: ssg\n  url: https://github.com/kvnloughead/ssg\n",
+		"social.json":     `{"github": "kvnloughead"}`,
+		"talks/2024.toml": "[[talk]]\ntitle = \"Static sites\"\n",
+		"notes.txt":       "ignored",
+		".hidden.yaml":    "ignored: true",
+	})
+
+	data, err := loadData(DirFS("."), dir)
+	if err != nil {
+		t.Fatalf("loadData() error = %v", err)
+	}
+	projects, ok := data["projects"].([]any)
+	if !ok || len(projects) != 1 || projects[0].(map[string]any)["name"] != "ssg" {
+		t.Errorf("projects = %#v, want the list from projects.yaml", data["projects"])
+	}
+	if social, ok := data["social"].(map[string]any); !ok || social["github"] != "kvnloughead" {
+		t.Errorf("social = %#v, want the object from social.json", data["social"])
+	}
+	talks, ok := data["talks"].(map[string]any)
+	if !ok || talks["2024"] == nil {
+		t.Errorf("talks = %#v, want talks/2024.toml nested under talks", data["talks"])
+	}
+	if len(data) != 3 {
+		t.Errorf("loadData() keys = %v, want other and hidden files ignored", data)
+	}
+
+	if data, err := loadData(DirFS("."), filepath.Join(dir, "missing")); err != nil || len(data) != 0 {
+		t.Errorf("loadData() of a missing directory = %v, %v, want an empty map", data, err)
+	}
+
+	writeFiles(t, dir, map[string]string{"projects.json": "[]"})
+	if _, err := loadData(DirFS("."), dir); err == nil || !strings.Contains(err.Error(), "projects") {
+		t.Errorf("loadData() with projects.yaml and projects.json error = %v, want a clash", err)
+	}
+}
+
+// TestBuild_Data tests that data files reach templates as .Site.Data
+func TestBuild_Data(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["data/projects.yaml"] = "- name: ssg\n- name: qr\n"
+	site["templates/posts.html"] = `{{define "posts"}}{{ range .Site.Data.projects }}[{{ .name }}]{{ end }}{{end}}`
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{OutputDir: "public"}); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	index, err := os.ReadFile(filepath.Join("public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "[ssg][qr]") {
+		t.Errorf("index.html = %s, want the projects from data/projects.yaml", index)
+	}
+}
@@ -0,0 +1,340 @@
+package ssg
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DeployTarget is a place `ssg deploy` publishes the built site to. Targets
+// are named in config.yaml, and Type picks how the site is uploaded; only
+// the fields for that type are used.
+//
+// Example config.yaml:
+//
+//	deploy:
+//	  server:
+//	    type: rsync
+//	    dest: me@example.com:/var/www/blog
+//	    delete: true
+//	  bucket:
+//	    type: s3
+//	    bucket: example-blog
+//	    region: eu-west-1
+//	    cacheControl:
+//	      - match: "*.html"
+//	        value: no-cache
+//	      - match: "*"
+//	        value: public, max-age=31536000
+//	  pages:
+//	    type: github-pages
+//	    cname: blog.example.com
+type DeployTarget struct {
+	Type   string `yaml:"type"`   // "rsync", "s3", or "github-pages"
+	Delete bool   `yaml:"delete"` // rsync and s3: remove files that aren't in the build
+
+	// rsync
+	Dest  string   `yaml:"dest"`  // Destination, e.g. "me@example.com:/var/www/blog"
+	Flags []string `yaml:"flags"` // Extra rsync arguments, e.g. ["-e", "ssh -p 2222"]
+
+	// s3
+	Bucket       string             `yaml:"bucket"`       // Bucket name
+	Region       string             `yaml:"region"`       // Bucket region (default: us-east-1)
+	Endpoint     string             `yaml:"endpoint"`     // URL of an S3-compatible service, e.g. "https://<account>.r2.cloudflarestorage.com" (default: AWS)
+	Prefix       string             `yaml:"prefix"`       // Key prefix to upload under (default: the bucket root)
+	CacheControl []CacheControlRule `yaml:"cacheControl"` // Cache-Control header of uploaded files; the first matching rule is used
+
+	// github-pages
+	Repo   string `yaml:"repo"`   // Repository to push to (default: the site's origin remote)
+	Branch string `yaml:"branch"` // Branch to push to (default: gh-pages)
+	CNAME  string `yaml:"cname"`  // Custom domain, written to a CNAME file
+}
+
+// CacheControlRule sets the Cache-Control header of the files an s3 target
+// uploads that match a pattern. Patterns use path.Match syntax and match a
+// file's path in the output directory (e.g., "posts/*.html") if they
+// contain a slash, or its name (e.g., "*.css") if they don't.
+type CacheControlRule struct {
+	Match string `yaml:"match"`
+	Value string `yaml:"value"`
+}
+
+// cacheControl returns the Cache-Control value of the first rule matching
+// the file at rel (slash-separated, relative to the output directory), or
+// "" if none do.
+func cacheControl(rules []CacheControlRule, rel string) string {
+	for _, r := range rules {
+		name := rel
+		if !strings.Contains(r.Match, "/") {
+			name = path.Base(rel)
+		}
+		if ok, _ := path.Match(r.Match, name); ok {
+			return r.Value
+		}
+	}
+	return ""
+}
+
+// deployer publishes a built site to one type of deploy target.
+type deployer interface {
+	// check reports problems with the target's configuration or the tools
+	// it needs, before the site is built.
+	check(t DeployTarget) error
+	// deploy publishes outputDir to the target, leaving out the paths
+	// matching the skip patterns (see preserved), and leaving them alone on
+	// the target. With dryRun, it reports what would change without changing
+	// anything.
+	deploy(ctx context.Context, t DeployTarget, outputDir string, skip []string, dryRun bool) error
+}
+
+// deployers maps deploy target types to their implementations. Adding a
+// target type only takes adding it here.
+var deployers = map[string]deployer{
+	"rsync":        rsyncDeployer{},
+	"s3":           s3Deployer{},
+	"github-pages": pagesDeployer{},
+}
+
+// Deploy builds the site for production and publishes it to a deploy target
+// from the config.
+//
+// Parameters:
+//   - opts: Deploy options; opts.Target names the target, and may be empty
+//     if the config has only one
+//
+// Once the site is published, webmentions are sent for the links in new and
+// changed posts if the config enables them (see WebmentionsConfig), and the
+// postPublish hooks from the config are run (see HooksConfig).
+//
+// Paths the config preserves in the output directory (see
+// SiteConfig.Preserve), and a .git directory at its root, aren't the
+// build's, so they're neither published nor removed from the target.
+//
+// Returns an error if the target doesn't exist or is misconfigured, the
+// build fails, publishing fails, or a postPublish hook fails.
+func Deploy(ctx context.Context, opts DeployOptions) error {
+	opts = opts.withDefaults()
+	config, err := LoadConfigEnv(opts.ConfigPath, EnvProduction)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	names := slices.Sorted(maps.Keys(config.Deploy))
+	name := opts.Target
+	switch {
+	case len(names) == 0:
+		return fmt.Errorf("no deploy targets in %s, add one under deploy", opts.ConfigPath)
+	case name == "" && len(names) > 1:
+		return fmt.Errorf("choose a deploy target: %s", strings.Join(names, ", "))
+	case name == "":
+		name = names[0]
+	}
+	target, ok := config.Deploy[name]
+	if !ok {
+		return fmt.Errorf("no deploy target %q (have %s)", name, strings.Join(names, ", "))
+	}
+	d, ok := deployers[target.Type]
+	if !ok {
+		return fmt.Errorf("deploy target %s: unknown type %q (want rsync, s3, or github-pages)", name, target.Type)
+	}
+	if err := d.check(target); err != nil {
+		return fmt.Errorf("deploy target %s: %w", name, err)
+	}
+	if err := checkPreserve(config.Preserve); err != nil {
+		return err
+	}
+	skip := append([]string{".git"}, config.Preserve...)
+
+	if opts.NoBuild {
+		if _, err := os.Stat(opts.OutputDir); err != nil {
+			return fmt.Errorf("%s does not exist, run 'ssg build' first or deploy without --no-build", opts.OutputDir)
+		}
+	} else {
+		buildOpts := BuildOptions{ConfigPath: opts.ConfigPath, OutputDir: opts.OutputDir, Environment: EnvProduction}
+		if err := Build(ctx, buildOpts); err != nil {
+			return fmt.Errorf("building site: %w", err)
+		}
+	}
+
+	start := time.Now()
+	if err := d.deploy(ctx, target, opts.OutputDir, skip, opts.DryRun); err != nil {
+		return fmt.Errorf("deploying to %s: %w", name, err)
+	}
+	if opts.DryRun {
+		return nil
+	}
+	fmt.Printf("Deployed to %s in %s\n", name, time.Since(start).Round(time.Millisecond))
+
+	// Receivers fetch the posts to verify them, so mentions wait until now
+	if err := sendWebmentions(ctx, BuildOptions{ConfigPath: opts.ConfigPath, OutputDir: opts.OutputDir, Environment: EnvProduction}); err != nil {
+		return fmt.Errorf("deployed to %s, but %w", name, err)
+	}
+
+	env := append(hookEnv(BuildOptions{ConfigPath: opts.ConfigPath, OutputDir: opts.OutputDir, Environment: EnvProduction}, config),
+		"SSG_DEPLOY_TARGET="+name,
+		"SSG_DEPLOY_TYPE="+target.Type,
+	)
+	if err := runHooks(ctx, "postPublish", config.Hooks.PostPublish, env); err != nil {
+		return fmt.Errorf("deployed to %s, but %w", name, err)
+	}
+	return nil
+}
+
+// rsyncDeployer copies the site with rsync, over SSH for remote
+// destinations.
+type rsyncDeployer struct{}
+
+func (rsyncDeployer) check(t DeployTarget) error {
+	if t.Dest == "" {
+		return fmt.Errorf("dest is required for rsync")
+	}
+	if _, err := exec.LookPath("rsync"); err != nil {
+		return fmt.Errorf("rsync is not installed")
+	}
+	return nil
+}
+
+func (rsyncDeployer) deploy(ctx context.Context, t DeployTarget, outputDir string, skip []string, dryRun bool) error {
+	cmd := exec.CommandContext(ctx, "rsync", rsyncArgs(t, outputDir, skip, dryRun)...) // #nosec G204 -- arguments come from the site's own config
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// rsyncArgs returns the arguments to rsync outputDir to t.Dest. The
+// trailing slash on the source copies the directory's contents rather than
+// the directory itself. The skip patterns are excluded from the root of the
+// transfer, which also keeps --delete from removing them.
+func rsyncArgs(t DeployTarget, outputDir string, skip []string, dryRun bool) []string {
+	args := []string{"--archive", "--compress", "--human-readable"}
+	if t.Delete {
+		args = append(args, "--delete")
+	}
+	for _, pattern := range skip {
+		args = append(args, "--exclude=/"+strings.Trim(pattern, "/"))
+	}
+	if dryRun {
+		args = append(args, "--dry-run", "--itemize-changes")
+	}
+	args = append(args, t.Flags...)
+	return append(args, strings.TrimSuffix(outputDir, "/")+"/", t.Dest)
+}
+
+// pagesDeployer pushes the site to a GitHub Pages branch. The branch is
+// replaced with a single commit of the build on each deploy, so it doesn't
+// accumulate the history of every generated file.
+type pagesDeployer struct{}
+
+func (pagesDeployer) check(t DeployTarget) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git is not installed")
+	}
+	return nil
+}
+
+func (pagesDeployer) deploy(ctx context.Context, t DeployTarget, outputDir string, skip []string, dryRun bool) error {
+	repo := t.Repo
+	if repo == "" {
+		origin, err := runGit("", "remote", "get-url", "origin")
+		if err != nil {
+			return fmt.Errorf("finding the repository to push to (set repo): %w", err)
+		}
+		repo = origin
+	}
+	// Local repositories are pushed to from the scratch checkout below
+	if _, err := os.Stat(repo); err == nil {
+		if repo, err = filepath.Abs(repo); err != nil {
+			return err
+		}
+	}
+	branch := t.Branch
+	if branch == "" {
+		branch = "gh-pages"
+	}
+
+	dir, err := os.MkdirTemp("", "ssg-deploy-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := copyStatic(DirFS("."), DirFS("."), outputDir, dir, false, sizeLimits{}); err != nil {
+		return fmt.Errorf("copying %s: %w", outputDir, err)
+	}
+	// A copied .git would be pushed in place of the build
+	if err := removeSkipped(dir, skip); err != nil {
+		return err
+	}
+	// Without .nojekyll, GitHub Pages runs Jekyll over the site and drops
+	// files and directories starting with an underscore.
+	if err := os.WriteFile(filepath.Join(dir, ".nojekyll"), nil, 0600); err != nil {
+		return err
+	}
+	if t.CNAME != "" {
+		if err := os.WriteFile(filepath.Join(dir, "CNAME"), []byte(t.CNAME+"\n"), 0600); err != nil {
+			return err
+		}
+	}
+
+	if _, err := runGit("", "init", "--quiet", dir); err != nil {
+		return err
+	}
+	// CI runners and fresh machines often have no identity configured, and
+	// git won't commit without one
+	for key, value := range map[string]string{"user.name": "ssg", "user.email": "ssg@localhost"} {
+		if _, err := runGit(dir, "config", key); err == nil {
+			continue
+		}
+		if _, err := runGit(dir, "config", key, value); err != nil {
+			return err
+		}
+	}
+	if _, err := runGit(dir, "add", "--all"); err != nil {
+		return err
+	}
+	message := "Deploy " + time.Now().UTC().Format(time.RFC3339)
+	if _, err := runGit(dir, "commit", "--quiet", "--message", message); err != nil {
+		return err
+	}
+	if dryRun {
+		files, err := runGit(dir, "ls-files")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Would push %d files to %s of %s\n", len(strings.Split(files, "\n")), branch, repo)
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err = runGit(dir, "push", "--quiet", "--force", repo, "HEAD:refs/heads/"+branch)
+	return err
+}
+
+// removeSkipped removes the paths in dir matching the skip patterns (see
+// preserved).
+func removeSkipped(dir string, skip []string) error {
+	return filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." || !preserved(filepath.ToSlash(rel), skip) {
+			return err
+		}
+		if err := os.RemoveAll(p); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
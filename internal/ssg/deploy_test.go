@@ -0,0 +1,158 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestCacheControl tests matching Cache-Control rules by file name and path
+func TestCacheControl(t *testing.T) {
+	rules := []CacheControlRule{
+		{Match: "posts/*.html", Value: "max-age=60"},
+		{Match: "*.html", Value: "no-cache"},
+		{Match: "*", Value: "max-age=31536000"},
+	}
+	tests := map[string]string{
+		"posts/hello.html": "max-age=60",
+		"index.html":       "no-cache",
+		"about/index.html": "no-cache",
+		"css/style.css":    "max-age=31536000",
+	}
+	for rel, want := range tests {
+		if got := cacheControl(rules, rel); got != want {
+			t.Errorf("cacheControl(%q) = %q, want %q", rel, got, want)
+		}
+	}
+	if got := cacheControl(nil, "index.html"); got != "" {
+		t.Errorf("cacheControl() without rules = %q, want none", got)
+	}
+}
+
+// TestRsyncArgs tests the rsync command line for a target
+func TestRsyncArgs(t *testing.T) {
+	target := DeployTarget{Dest: "me@example.com:/var/www", Delete: true, Flags: []string{"-e", "ssh -p 2222"}}
+	got := rsyncArgs(target, "public/", []string{".git", "/uploads/*.pdf"}, true)
+	want := []string{"--archive", "--compress", "--human-readable", "--delete", "--exclude=/.git", "--exclude=/uploads/*.pdf", "--dry-run", "--itemize-changes", "-e", "ssh -p 2222", "public/", "me@example.com:/var/www"}
+	if !slices.Equal(got, want) {
+		t.Errorf("rsyncArgs() = %q, want %q", got, want)
+	}
+}
+
+// TestDeploy_Targets tests choosing and validating the deploy target
+func TestDeploy_Targets(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{"config.yaml": "title: Blog\n"})
+	t.Chdir(tmpDir)
+
+	deploy := func(target string) error {
+		return Deploy(context.Background(), DeployOptions{Target: target, NoBuild: true})
+	}
+	if err := deploy(""); err == nil || !strings.Contains(err.Error(), "no deploy targets") {
+		t.Errorf("Deploy() without targets error = %v", err)
+	}
+
+	writeFiles(t, tmpDir, map[string]string{"config.yaml": "title: Blog\ndeploy:\n  a:\n    type: ftp\n  b:\n    type: rsync\n"})
+	for target, want := range map[string]string{
+		"":  "choose a deploy target: a, b",
+		"c": `no deploy target "c"`,
+		"a": `unknown type "ftp"`,
+		"b": "dest is required",
+	} {
+		if err := deploy(target); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Deploy(%q) error = %v, want %q", target, err, want)
+		}
+	}
+}
+
+// TestDeploy_GitHubPages tests pushing the site to a Pages branch
+func TestDeploy_GitHubPages(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for _, v := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(v, "Test")
+	}
+	for _, v := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(v, "test@example.com")
+	}
+
+	remote := filepath.Join(t.TempDir(), "site.git")
+	if _, err := runGit("", "init", "--quiet", "--bare", remote); err != nil {
+		t.Fatal(err)
+	}
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "deploy:\n  pages:\n    type: github-pages\n    repo: " + remote + "\n    cname: blog.example.com\n"
+	site["config.yaml"] += "preserve: [.git, notes.txt]\n"
+	site["public/notes.txt"] = "not part of the site"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+	if _, err := runGit("", "init", "--quiet", "public"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Deploy(context.Background(), DeployOptions{DryRun: true}); err != nil {
+		t.Fatalf("Deploy() dry run failed: %v", err)
+	}
+	if _, err := runGit(remote, "rev-parse", "--verify", "gh-pages"); err == nil {
+		t.Error("dry run pushed the branch")
+	}
+
+	for range 2 {
+		if err := Deploy(context.Background(), DeployOptions{}); err != nil {
+			t.Fatalf("Deploy() failed: %v", err)
+		}
+	}
+	files, err := runGit(remote, "ls-tree", "-r", "--name-only", "gh-pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{".nojekyll", "CNAME", "index.html", "posts/first.html"} {
+		if !slices.Contains(strings.Split(files, "\n"), want) {
+			t.Errorf("gh-pages files = %q, want %s", files, want)
+		}
+	}
+	if slices.Contains(strings.Split(files, "\n"), "notes.txt") {
+		t.Errorf("gh-pages files = %q, want no preserved notes.txt", files)
+	}
+	if count, _ := runGit(remote, "rev-list", "--count", "gh-pages"); count != "1" {
+		t.Errorf("gh-pages has %s commits, want 1", count)
+	}
+}
+
+// TestDeploy_GitHubPagesNoIdentity tests deploying to GitHub Pages where git
+// has no user name or email configured, as on a fresh CI runner
+func TestDeploy_GitHubPagesNoIdentity(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, v := range []string{"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_NAME", "GIT_COMMITTER_EMAIL", "EMAIL"} {
+		t.Setenv(v, "")
+		os.Unsetenv(v)
+	}
+
+	remote := filepath.Join(t.TempDir(), "site.git")
+	if _, err := runGit("", "init", "--quiet", "--bare", remote); err != nil {
+		t.Fatal(err)
+	}
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "deploy:\n  pages:\n    type: github-pages\n    repo: " + remote + "\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Deploy(context.Background(), DeployOptions{}); err != nil {
+		t.Fatalf("Deploy() failed: %v", err)
+	}
+	if author, _ := runGit(remote, "log", "-1", "--format=%an <%ae>", "gh-pages"); author != "ssg <ssg@localhost>" {
+		t.Errorf("gh-pages author = %q, want ssg <ssg@localhost>", author)
+	}
+}
@@ -0,0 +1,297 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1" // #nosec G505 -- used to match git blob IDs, not for security
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+)
+
+// fileChange is a single difference between two builds.
+type fileChange struct {
+	Path string // Slash-separated path relative to the output directory
+	Kind byte   // 'A' (added), 'M' (modified), or 'D' (deleted)
+}
+
+// snapshot is the set of output files from a previous build.
+type snapshot struct {
+	description string
+	files       map[string]string                             // Path → digest
+	digest      func(fsys fs.FS, path string) (string, error) // Digests a new file using the same scheme as files
+	read        func(path string) ([]byte, error)             // Returns a file's previous content
+}
+
+// Diff builds the site in memory and reports how the result differs from
+// the previous build, so the effect of a template or content change can be
+// reviewed before deploying.
+//
+// The previous build is either the manifest saved by the last `ssg build`
+// (with file contents read from the output directory), or, if opts.Ref is
+// set, the given git ref of the output directory (useful when the output is
+// a git worktree such as a gh-pages checkout).
+//
+// Each modified text file is shown as a unified diff of its lines, or with
+// opts.Words, as the changed lines with the words removed and added marked
+// [-like this-]{+and this+}, which is easier to read for prose edits to long
+// lines of HTML.
+//
+// The build is a preview, like a dry run: the preBuild and postBuild hooks
+// don't run and nothing is logged as built. Paths the config (and its
+// overlay for $SSG_ENV) lists under preserve aren't compared.
+//
+// Parameters:
+//   - opts: Diff options; empty fields take their defaults
+//
+// Returns an error if the build fails or there is no previous build to compare with.
+func Diff(opts DiffOptions) error {
+	changes, err := diffBuild(opts, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Println("No changes.")
+	}
+	return nil
+}
+
+// diffBuild does the work of Diff, writing the report to w and returning the
+// changes found.
+func diffBuild(opts DiffOptions, w io.Writer) ([]fileChange, error) {
+	opts = opts.withDefaults()
+	configPath, outputDir := opts.ConfigPath, opts.OutputDir
+	var prev *snapshot
+	var err error
+	if opts.Ref != "" {
+		prev, err = gitSnapshot(outputDir, opts.Ref)
+	} else {
+		prev, err = manifestSnapshot(outputDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A preview: the hooks could change files, and their output dir would
+	// be the real one
+	build := BuildOptions{ConfigPath: configPath, OutputDir: outputDir, preview: true}.withDefaults()
+	cleanup, err := scratchOutput(&build)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	out, newDir := build.Output, build.OutputDir
+	prev.dropPreserved(preservePatterns(build.Source, configPath, build.Environment))
+	if err := generate(context.Background(), build); err != nil {
+		return nil, fmt.Errorf("building site: %w", err)
+	}
+	current, err := buildManifest(out, newDir, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading new build: %w", err)
+	}
+
+	changes, err := compareBuilds(prev, current, out, newDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	counts := map[byte]int{}
+	fmt.Fprintf(w, "Changes compared to %s:\n", prev.description)
+	for _, c := range changes {
+		counts[c.Kind]++
+		fmt.Fprintf(w, "  %c %s\n", c.Kind, c.Path)
+	}
+	fmt.Fprintf(w, "\n%d files changed: %d added, %d modified, %d deleted\n",
+		len(changes), counts['A'], counts['M'], counts['D'])
+
+	if opts.Stat {
+		return changes, nil
+	}
+
+	for _, c := range changes {
+		if c.Kind != 'M' || !isTextFile(c.Path) {
+			continue
+		}
+		before, err := prev.read(c.Path)
+		if err != nil {
+			fmt.Fprintf(w, "\n%s: previous content unavailable (%v)\n", c.Path, err)
+			continue
+		}
+		after, err := fs.ReadFile(out, filepath.Join(newDir, filepath.FromSlash(c.Path)))
+		if err != nil {
+			return nil, err
+		}
+		if opts.Words {
+			fmt.Fprintf(w, "\n%s", wordDiff(c.Path, string(before), string(after)))
+		} else {
+			fmt.Fprintf(w, "\n%s", unifiedDiff(c.Path, string(before), string(after)))
+		}
+	}
+
+	return changes, nil
+}
+
+// scratchOutput points opts, which has its defaults set, at somewhere to
+// build the site for Diff or a dry run: a MemFS, so nothing is written to
+// disk. Sites that encode images in extra formats, which needs files on disk
+// (see ImagesConfig.Formats), are built into a temporary directory instead,
+// removed by cleanup.
+func scratchOutput(opts *BuildOptions) (cleanup func(), err error) {
+	config, err := loadConfigEnv(opts.Source, opts.ConfigPath, opts.Environment)
+	if err != nil || len(config.Images.Formats) == 0 {
+		opts.Output = &MemFS{} // Config errors are reported by the build
+		return func() {}, nil
+	}
+	tmpDir, err := os.MkdirTemp("", "ssg-scratch-")
+	if err != nil {
+		return nil, err
+	}
+	opts.Output, opts.OutputDir = DirFS("."), filepath.Join(tmpDir, "public")
+	return func() { os.RemoveAll(tmpDir) }, nil
+}
+
+// compareBuilds lists the files added, modified, or deleted in the build at
+// newDir in fsys (described by current) relative to prev, sorted by path.
+func compareBuilds(prev *snapshot, current *Manifest, fsys fs.FS, newDir string) ([]fileChange, error) {
+	var changes []fileChange
+	for _, p := range current.Paths() {
+		oldDigest, ok := prev.files[p]
+		if !ok {
+			changes = append(changes, fileChange{Path: p, Kind: 'A'})
+			continue
+		}
+		newDigest, err := prev.digest(fsys, filepath.Join(newDir, filepath.FromSlash(p)))
+		if err != nil {
+			return nil, err
+		}
+		if newDigest != oldDigest {
+			changes = append(changes, fileChange{Path: p, Kind: 'M'})
+		}
+	}
+	for p := range prev.files {
+		if _, ok := current.Files[p]; !ok {
+			changes = append(changes, fileChange{Path: p, Kind: 'D'})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// dropPreserved removes the paths matching the preserve patterns from the
+// snapshot, since builds leave them alone (see preserved).
+func (s *snapshot) dropPreserved(preserve []string) {
+	for p := range s.files {
+		if preserved(p, preserve) {
+			delete(s.files, p)
+		}
+	}
+}
+
+// manifestSnapshot describes the previous build using the saved manifest.
+// Previous file contents are read from outputDir as long as they still match
+// the manifest. If no manifest exists, the current contents of outputDir are
+// used instead.
+func manifestSnapshot(outputDir string) (*snapshot, error) {
+	m, err := readManifest(manifestPath)
+	description := fmt.Sprintf("previous build (%s)", manifestPath)
+	if err != nil || filepath.Clean(m.OutputDir) != filepath.Clean(outputDir) {
+		if _, statErr := os.Stat(outputDir); statErr != nil {
+			return nil, fmt.Errorf("no previous build found in %s, run 'ssg build' first", outputDir)
+		}
+		if m, err = buildManifest(DirFS("."), outputDir, nil); err != nil {
+			return nil, fmt.Errorf("reading previous build: %w", err)
+		}
+		description = "current contents of " + outputDir
+	}
+
+	return &snapshot{
+		description: description,
+		files:       m.Files,
+		digest:      hashFile,
+		read: func(p string) ([]byte, error) {
+			oldPath := filepath.Join(outputDir, filepath.FromSlash(p))
+			if sum, err := hashFile(DirFS("."), oldPath); err != nil || sum != m.Files[p] {
+				return nil, fmt.Errorf("%s changed since the manifest was written", oldPath)
+			}
+			return os.ReadFile(oldPath)
+		},
+	}, nil
+}
+
+// gitSnapshot describes the previous build as the tree of ref in the git
+// repository containing outputDir. Digests are git blob IDs, so comparing
+// against it doesn't require reading every old file.
+func gitSnapshot(outputDir, ref string) (*snapshot, error) {
+	// #nosec G204 -- ref and outputDir come from the user's own command line
+	cmd := exec.Command("git", "-C", outputDir, "ls-tree", "-r", "--full-tree", ref)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing files at %s in %s: %w", ref, outputDir, err)
+	}
+
+	files := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		// Format: <mode> SP <type> SP <object> TAB <path>
+		meta, p, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		files[p] = fields[2]
+	}
+
+	return &snapshot{
+		description: fmt.Sprintf("git ref %s of %s", ref, outputDir),
+		files:       files,
+		digest:      gitBlobID,
+		read: func(p string) ([]byte, error) {
+			// #nosec G204 -- ref and path come from the user's command line and git itself
+			return exec.Command("git", "-C", outputDir, "show", ref+":"+p).Output()
+		},
+	}, nil
+}
+
+// gitBlobID returns the object ID git would assign to the contents of the
+// file at path in fsys.
+func gitBlobID(fsys fs.FS, path string) (string, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return "", err
+	}
+	h := sha1.New() // #nosec G401 -- matches git's object format, not used for security
+	fmt.Fprintf(h, "blob %d\x00", len(data))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// isTextFile reports whether a diff of the file's contents is worth showing.
+func isTextFile(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".html", ".htm", ".css", ".js", ".json", ".xml", ".txt", ".svg", ".webmanifest":
+		return true
+	}
+	return false
+}
+
+// unifiedDiff returns a unified diff between two versions of a file.
+func unifiedDiff(name, before, after string) string {
+	edits := myers.ComputeEdits(span.URIFromPath(name), before, after)
+	var buf bytes.Buffer
+	fmt.Fprint(&buf, gotextdiff.ToUnified("a/"+name, "b/"+name, before, edits))
+	return buf.String()
+}
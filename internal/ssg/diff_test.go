@@ -0,0 +1,239 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestDiffBuild tests comparing a fresh build against the saved manifest
+func TestDiffBuild(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["content/posts/2024-01-16-second.md"] = "---\ntitle: Second Post\ndate: 2024-01-16T10:00:00Z\n---\n\nSecond.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if _, err := os.Stat(manifestPath); err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+
+	// Modify the first post, delete the second, add a third
+	writeFiles(t, tmpDir, map[string]string{
+		"content/posts/2024-01-15-first.md": "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\nUpdated first post.\n",
+		"content/posts/2024-01-17-third.md": "---\ntitle: Third Post\ndate: 2024-01-17T10:00:00Z\n---\n\nThird.\n",
+	})
+	if err := os.Remove(filepath.Join("content", "posts", "2024-01-16-second.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	changes, err := diffBuild(DiffOptions{}, &out)
+	if err != nil {
+		t.Fatalf("diffBuild() failed: %v", err)
+	}
+
+	want := []fileChange{
+		{Path: "index.html", Kind: 'M'},
+		{Path: "posts/first.html", Kind: 'M'},
+		{Path: "posts/second.html", Kind: 'D'},
+		{Path: "posts/third.html", Kind: 'A'},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("changes[%d] = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+
+	report := out.String()
+	for _, s := range []string{
+		"M posts/first.html",
+		"4 files changed: 1 added, 2 modified, 1 deleted",
+		"--- a/posts/first.html",
+		"-<p>Hello from the first post.</p>",
+		"+<p>Updated first post.</p>",
+	} {
+		if !strings.Contains(report, s) {
+			t.Errorf("report doesn't contain %q. Got:\n%s", s, report)
+		}
+	}
+
+	// The real output and manifest must be untouched
+	if _, err := os.Stat(filepath.Join("public", "posts", "second.html")); err != nil {
+		t.Error("diff modified the output directory")
+	}
+}
+
+// TestDiffBuild_NoChanges tests diffing an unchanged site
+func TestDiffBuild_NoChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	changes, err := diffBuild(DiffOptions{Stat: true}, &out)
+	if err != nil {
+		t.Fatalf("diffBuild() failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("changes = %+v, want none", changes)
+	}
+}
+
+// TestDiffBuild_NoPreviousBuild tests diffing before the site was ever built
+func TestDiffBuild_NoPreviousBuild(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+	t.Chdir(tmpDir)
+
+	if _, err := diffBuild(DiffOptions{}, &bytes.Buffer{}); err == nil {
+		t.Error("diffBuild() succeeded without a previous build, want error")
+	}
+}
+
+// TestDiffBuild_GitRef tests comparing against a git ref of the output directory
+func TestDiffBuild_GitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "deploy"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", "public"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+
+	writeFiles(t, tmpDir, map[string]string{
+		"content/posts/2024-01-15-first.md": "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\nChanged.\n",
+	})
+
+	var out bytes.Buffer
+	changes, err := diffBuild(DiffOptions{Ref: "HEAD"}, &out)
+	if err != nil {
+		t.Fatalf("diffBuild() failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0] != (fileChange{Path: "posts/first.html", Kind: 'M'}) {
+		t.Errorf("changes = %+v, want only posts/first.html modified", changes)
+	}
+	if !strings.Contains(out.String(), "+<p>Changed.</p>") {
+		t.Errorf("report doesn't contain diff. Got:\n%s", out.String())
+	}
+}
+
+// TestDiffBuild_Preview tests that diff doesn't run the build's hooks, and
+// leaves out the paths preserved in the environment's config overlay
+func TestDiffBuild_Preview(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in the test use sh syntax")
+	}
+
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "hooks:\n  preBuild: echo pre >> hooks.log\n  postBuild: echo post >> hooks.log\n"
+	site["config.production.yaml"] = "preserve: [CNAME]\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{Environment: EnvProduction}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	writeFiles(t, tmpDir, map[string]string{"public/CNAME": "blog.example.com\n"})
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "deploy"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", "public"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+
+	t.Setenv("SSG_ENV", EnvProduction)
+	var out bytes.Buffer
+	changes, err := diffBuild(DiffOptions{Ref: "HEAD"}, &out)
+	if err != nil {
+		t.Fatalf("diffBuild() failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("changes = %+v, want none (CNAME is preserved)", changes)
+	}
+	log, err := os.ReadFile("hooks.log")
+	if err != nil || string(log) != "pre\npost\n" {
+		t.Errorf("hooks.log = %q, %v; want only the build's hooks", log, err)
+	}
+}
+
+// TestDiffBuild_Words tests showing the changed words of a page, without writing a build to disk
+func TestDiffBuild_Words(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	writeFiles(t, tmpDir, map[string]string{
+		"content/posts/2024-01-15-first.md": "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\nHello from the revised first post.\n",
+	})
+
+	var out bytes.Buffer
+	if _, err := diffBuild(DiffOptions{Words: true}, &out); err != nil {
+		t.Fatalf("diffBuild() failed: %v", err)
+	}
+	if want := "<p>Hello from the {+revised +}first post.</p>"; !strings.Contains(out.String(), want) {
+		t.Errorf("report doesn't contain %q. Got:\n%s", want, out.String())
+	}
+	entries, err := os.ReadDir(os.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "ssg-diff-") {
+			t.Errorf("diff built into %s instead of in memory", e.Name())
+		}
+	}
+}
+
+// TestWordDiff tests marking removed and added words on the changed lines
+func TestWordDiff(t *testing.T) {
+	before := "<h1>Title</h1>\n<p>The quick brown fox.</p>\n<p>Unchanged.</p>\n<p>Old ending</p>\n"
+	after := "<h1>Title</h1>\n<p>The slow brown fox.</p>\n<p>Unchanged.</p>\n<p>New ending</p>\n<p>Extra</p>\n"
+	got := wordDiff("index.html", before, after)
+	want := "--- a/index.html\n+++ b/index.html\n" +
+		"@@ line 2 @@\n<p>The [-quick-]{+slow+} brown fox.</p>\n" +
+		"@@ line 4 @@\n<p>[-Old-]{+New+} ending</p>\n{+<p>Extra</p>+}\n"
+	if got != want {
+		t.Errorf("wordDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
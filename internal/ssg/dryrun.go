@@ -0,0 +1,92 @@
+package ssg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+)
+
+// dryRun does the work of Build with opts.DryRun: it builds the site in memory
+// (see scratchOutput) and writes to w the files the build would create (A),
+// change (M), or delete (D) in opts.OutputDir, which is left untouched. The
+// comparison is with the output directory's current contents, so files
+// changed since the last build show up too. The build's hooks don't run,
+// since they could change files themselves, and the manifest isn't saved.
+//
+// Returns the changes, or an error if the build fails (or, with
+// opts.Strict, has broken links).
+func dryRun(ctx context.Context, opts BuildOptions, w io.Writer) ([]fileChange, error) {
+	if opts.Shard != "" {
+		return nil, fmt.Errorf("a dry run can't be sharded")
+	}
+	opts = opts.withDefaults()
+	prev, err := outputSnapshot(opts.Output, opts.OutputDir, preservePatterns(opts.Source, opts.ConfigPath, opts.Environment))
+	if err != nil {
+		return nil, err
+	}
+
+	scratch := opts
+	scratch.preview = true
+	cleanup, err := scratchOutput(&scratch)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	if err := generate(ctx, scratch); err != nil {
+		return nil, err
+	}
+	if err := reportBrokenLinks(scratch.Output, scratch.OutputDir, opts.Strict); err != nil {
+		return nil, err
+	}
+	if err := reportAccessibility(opts.Source, scratch.Output, scratch.OutputDir, opts.ConfigPath, opts.Environment); err != nil {
+		return nil, err
+	}
+	current, err := buildManifest(scratch.Output, scratch.OutputDir, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading new build: %w", err)
+	}
+	changes, err := compareBuilds(prev, current, scratch.Output, scratch.OutputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "Dry run: no changes to %s.\n", opts.OutputDir)
+		return nil, nil
+	}
+	counts := map[byte]int{}
+	fmt.Fprintf(w, "Dry run: changes a build would make to %s:\n", opts.OutputDir)
+	for _, c := range changes {
+		counts[c.Kind]++
+		fmt.Fprintf(w, "  %c %s\n", c.Kind, c.Path)
+	}
+	fmt.Fprintf(w, "\n%d files would change: %d created, %d changed, %d deleted\n",
+		len(changes), counts['A'], counts['M'], counts['D'])
+	return changes, nil
+}
+
+// outputSnapshot describes the current contents of outputDir in fsys, which
+// is empty if it doesn't exist yet, other than the preserved paths.
+func outputSnapshot(fsys fs.FS, outputDir string, preserve []string) (*snapshot, error) {
+	files := map[string]string{}
+	if _, err := fs.Stat(fsys, outputDir); err == nil {
+		m, err := buildManifest(fsys, outputDir, preserve)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", outputDir, err)
+		}
+		files = m.Files
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return &snapshot{
+		description: "current contents of " + outputDir,
+		files:       files,
+		digest:      hashFile,
+		read: func(p string) ([]byte, error) {
+			return fs.ReadFile(fsys, filepath.Join(outputDir, filepath.FromSlash(p)))
+		},
+	}, nil
+}
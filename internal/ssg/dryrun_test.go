@@ -0,0 +1,98 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_DryRun tests reporting the changes a build would make without making them
+func TestBuild_DryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "hooks:\n  preBuild: touch hooked\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+	scratch := t.TempDir()
+	t.Setenv("TMPDIR", scratch)
+
+	opts := BuildOptions{DryRun: true}
+	var out bytes.Buffer
+	changes, err := dryRun(context.Background(), opts.withDefaults(), &out)
+	if err != nil {
+		t.Fatalf("dryRun() error = %v", err)
+	}
+	if len(changes) == 0 || !strings.Contains(out.String(), "A posts/first.html") {
+		t.Errorf("output = %q, want the post page created", out.String())
+	}
+	if _, err := os.Stat("public"); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote the output directory (stat error = %v)", err)
+	}
+	if _, err := os.Stat("hooked"); !os.IsNotExist(err) {
+		t.Error("dry run ran the preBuild hook")
+	}
+	if entries, _ := os.ReadDir(scratch); len(entries) != 0 {
+		t.Errorf("dry run wrote %d temporary files, want the build kept in memory", len(entries))
+	}
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, tmpDir, map[string]string{
+		"public/stale.html":                 "old",
+		"content/posts/2024-01-15-first.md": "---\ntitle: First Post\ndate: 2024-01-15\n---\n\nChanged.\n",
+	})
+	before, err := os.ReadFile(filepath.Join("public", "posts", "first.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	changes, err = dryRun(context.Background(), opts.withDefaults(), &out)
+	if err != nil {
+		t.Fatalf("dryRun() error = %v", err)
+	}
+	want := map[string]byte{"posts/first.html": 'M', "stale.html": 'D'}
+	for _, c := range changes {
+		if kind, ok := want[c.Path]; ok && kind != c.Kind {
+			t.Errorf("%s: kind = %c, want %c", c.Path, c.Kind, kind)
+		}
+		delete(want, c.Path)
+	}
+	if len(want) != 0 {
+		t.Errorf("changes = %v, missing %v", changes, want)
+	}
+	after, err := os.ReadFile(filepath.Join("public", "posts", "first.html"))
+	if err != nil || !bytes.Equal(before, after) {
+		t.Errorf("dry run changed public/posts/first.html (err = %v)", err)
+	}
+	if _, err := os.Stat(filepath.Join("public", "stale.html")); err != nil {
+		t.Errorf("dry run deleted public/stale.html: %v", err)
+	}
+}
+
+// TestBuild_DryRunNoChanges tests a dry run right after a build
+func TestBuild_DryRunNoChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	changes, err := dryRun(context.Background(), BuildOptions{DryRun: true}.withDefaults(), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 || !strings.Contains(out.String(), "no changes") {
+		t.Errorf("changes = %v, output = %q, want none", changes, out.String())
+	}
+
+	if _, err := dryRun(context.Background(), BuildOptions{DryRun: true, Shard: "1/2"}.withDefaults(), &out); err == nil {
+		t.Error("sharded dry run succeeded, want error")
+	}
+}
@@ -0,0 +1,238 @@
+package ssg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// exportSite is site.json, the manifest of an export.
+type exportSite struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	BaseURL     string           `json:"baseUrl,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Author      string           `json:"author,omitempty"`
+	Generated   time.Time        `json:"generated"`
+	Posts       []exportSummary  `json:"posts"`    // Blog posts, newest first
+	Sections    []exportSection  `json:"sections"` // Sections, with their entries
+	Taxonomies  []exportTaxonomy `json:"taxonomies"`
+}
+
+// exportSummary is a post or section entry as listed in site.json.
+type exportSummary struct {
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	URL         string    `json:"url"`  // Site-relative URL of the post's page
+	File        string    `json:"file"` // Path of the post's document, relative to site.json
+	Date        time.Time `json:"date"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags"`
+	Section     string    `json:"section,omitempty"` // Section of an entry ("" for blog posts)
+	Lang        string    `json:"lang,omitempty"`
+}
+
+// exportFrontmatter is the frontmatter of an exported post.
+type exportFrontmatter struct {
+	Title       string         `json:"title"`
+	Date        time.Time      `json:"date"`
+	PublishDate *time.Time     `json:"publishDate,omitempty"`
+	ExpiryDate  *time.Time     `json:"expiryDate,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Tags        []string       `json:"tags"`
+	Series      string         `json:"series,omitempty"`
+	Lang        string         `json:"lang,omitempty"`
+	Draft       bool           `json:"draft,omitempty"`
+	Params      map[string]any `json:"params,omitempty"` // Frontmatter keys the generator doesn't use
+}
+
+// exportPost is the document of one exported post.
+type exportPost struct {
+	exportSummary
+	Frontmatter exportFrontmatter `json:"frontmatter"`
+	HTML        string            `json:"html"` // Rendered content, with absolute URLs if baseUrl is set
+	Text        string            `json:"text"` // Content as plain text, e.g. for previews or search
+}
+
+// exportSection is a section as listed in site.json.
+type exportSection struct {
+	Name  string          `json:"name"`
+	Title string          `json:"title"`
+	URL   string          `json:"url"`
+	Posts []exportSummary `json:"posts"`
+}
+
+// exportTaxonomy is a taxonomy as listed in site.json.
+type exportTaxonomy struct {
+	Name  string       `json:"name"`
+	Title string       `json:"title"`
+	URL   string       `json:"url"`
+	Terms []exportTerm `json:"terms"`
+}
+
+// exportTerm is a taxonomy term, with the URLs of its posts.
+type exportTerm struct {
+	Name  string   `json:"name"`
+	Slug  string   `json:"slug"`
+	URL   string   `json:"url"`
+	Count int      `json:"count"`
+	Posts []string `json:"posts"`
+}
+
+// Export writes the site's content in a machine-readable form, for mobile
+// apps or other frontends to consume without scraping the HTML. The JSON
+// format writes:
+//
+//   - site.json: the site's metadata, and its posts, sections, and
+//     taxonomies, each post with the path of its document
+//   - one document per post and section entry, at its URL with a .json
+//     extension (e.g., posts/hello.json): its frontmatter, rendered HTML,
+//     and plain text
+//
+// Posts are filtered as in a production build. Templates aren't used, so a
+// site can be exported without a theme.
+//
+// Returns an error if the format is unsupported, the content can't be
+// loaded, or a file can't be written.
+func Export(opts ExportOptions) error {
+	opts = opts.withDefaults()
+	if opts.Format != "json" {
+		return fmt.Errorf("unknown export format %q (want json)", opts.Format)
+	}
+
+	config, err := LoadConfigEnv(opts.ConfigPath, EnvProduction)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	p := newParser(config)
+	posts, err := loadPosts(DirFS("."), p, config, opts.Drafts, false, false)
+	if err != nil {
+		return err
+	}
+	sections, err := loadSections(DirFS("."), p, config, opts.Drafts, false, false)
+	if err != nil {
+		return err
+	}
+	allPosts := slices.Clone(posts)
+	for _, section := range sections {
+		allPosts = append(allPosts, section.Posts...)
+	}
+	taxonomies, err := collectTaxonomies(allPosts, config)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(opts.OutputDir); err != nil {
+		return fmt.Errorf("cleaning export directory: %w", err)
+	}
+	count, err := writeExport(config, posts, sections, taxonomies, opts.OutputDir, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d posts to %s\n", count, opts.OutputDir)
+	return nil
+}
+
+// writeExport writes site.json and the document of each post to outputDir,
+// with generated as the time of the export. Returns the number of documents
+// written.
+func writeExport(config *SiteConfig, posts []*parser.Post, sections []*Section, taxonomies []*Taxonomy, outputDir string, generated time.Time) (int, error) {
+	site := exportSite{
+		Title:       config.Title,
+		Description: config.Description,
+		BaseURL:     config.BaseURL,
+		Language:    config.Language,
+		Author:      config.Author,
+		Generated:   generated,
+		Posts:       []exportSummary{},
+		Sections:    []exportSection{},
+		Taxonomies:  []exportTaxonomy{},
+	}
+	count := 0
+	export := func(post *parser.Post, section string) (exportSummary, error) {
+		doc := exportPostDoc(post, section, config.BaseURL)
+		count++
+		return doc.exportSummary, writeJSON(DirFS("."), filepath.Join(outputDir, filepath.FromSlash(doc.File)), doc)
+	}
+
+	for _, post := range posts {
+		summary, err := export(post, "")
+		if err != nil {
+			return 0, err
+		}
+		site.Posts = append(site.Posts, summary)
+	}
+	for _, section := range sections {
+		s := exportSection{Name: section.Name, Title: section.Title, URL: section.URL, Posts: []exportSummary{}}
+		for _, post := range section.Posts {
+			summary, err := export(post, section.Name)
+			if err != nil {
+				return 0, err
+			}
+			s.Posts = append(s.Posts, summary)
+		}
+		site.Sections = append(site.Sections, s)
+	}
+	for _, tax := range taxonomies {
+		t := exportTaxonomy{Name: tax.Name, Title: tax.Title, URL: tax.URL, Terms: []exportTerm{}}
+		for _, term := range tax.Terms {
+			et := exportTerm{Name: term.Name, Slug: term.Slug, URL: term.URL, Count: term.Count, Posts: []string{}}
+			for _, post := range term.Posts {
+				et.Posts = append(et.Posts, post.URL)
+			}
+			t.Terms = append(t.Terms, et)
+		}
+		site.Taxonomies = append(site.Taxonomies, t)
+	}
+
+	if err := writeJSON(DirFS("."), filepath.Join(outputDir, "site.json"), site); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// exportPostDoc returns the export document of post, an entry of section
+// ("" for blog posts), with links in its HTML made absolute using baseURL.
+func exportPostDoc(post *parser.Post, section, baseURL string) exportPost {
+	tags := post.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	fm := exportFrontmatter{
+		Title:       post.Title,
+		Date:        post.Date,
+		Description: post.Description,
+		Tags:        tags,
+		Series:      post.Series,
+		Lang:        post.Lang,
+		Draft:       post.Draft,
+		Params:      post.Params,
+	}
+	if !post.PublishDate.IsZero() {
+		fm.PublishDate = &post.PublishDate
+	}
+	if !post.ExpiryDate.IsZero() {
+		fm.ExpiryDate = &post.ExpiryDate
+	}
+	return exportPost{
+		exportSummary: exportSummary{
+			Title:       post.Title,
+			Slug:        post.Slug,
+			URL:         post.URL,
+			File:        strings.TrimPrefix(formatURL(post.URL, ".json"), "/"),
+			Date:        post.Date,
+			Description: post.Description,
+			Tags:        tags,
+			Section:     section,
+			Lang:        post.Lang,
+		},
+		Frontmatter: fm,
+		HTML:        string(absoluteURLs(post.Content, baseURL, post.URL)),
+		Text:        strings.Join(strings.Fields(htmlText(string(post.Content))), " "),
+	}
+}
@@ -0,0 +1,77 @@
+package ssg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestExport tests writing site.json and a document per post and section entry
+func TestExport(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "taxonomies: [tags]\n"
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\ndate: 2024-01-15\ntags: [go]\ncover: a.png\n---\n\nHello *world* ![pic](/images/a.png).\n"
+	site["content/posts/2024-01-20-draft.md"] = "---\ntitle: Draft\ndate: 2024-01-20\ndraft: true\n---\n\nWIP.\n"
+	site["content/notes/2024-02-01-tip.md"] = "---\ntitle: Tip\ndate: 2024-02-01\n---\n\nA tip.\n"
+	delete(site, "templates/base.html") // Export doesn't need templates
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Export(ExportOptions{}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	var manifest exportSite
+	readJSON(t, filepath.Join("export", "site.json"), &manifest)
+	if manifest.Title != "Test Blog" || manifest.BaseURL != "https://test.com" {
+		t.Errorf("site.json metadata = %+v", manifest)
+	}
+	if len(manifest.Posts) != 1 || manifest.Posts[0].File != "posts/first.json" {
+		t.Fatalf("site.json posts = %+v, want the published post", manifest.Posts)
+	}
+	if len(manifest.Sections) != 1 || len(manifest.Sections[0].Posts) != 1 || manifest.Sections[0].Posts[0].Section != "notes" {
+		t.Errorf("site.json sections = %+v, want notes with its entry", manifest.Sections)
+	}
+	if len(manifest.Taxonomies) == 0 || manifest.Taxonomies[0].Terms[0].Posts[0] != "/posts/first.html" {
+		t.Errorf("site.json taxonomies = %+v, want tags listing the post", manifest.Taxonomies)
+	}
+
+	var post exportPost
+	readJSON(t, filepath.Join("export", "posts", "first.json"), &post)
+	if post.Frontmatter.Params["cover"] != "a.png" || post.Frontmatter.Tags[0] != "go" {
+		t.Errorf("frontmatter = %+v, want tags and params", post.Frontmatter)
+	}
+	if !strings.Contains(post.HTML, `src="https://test.com/images/a.png"`) {
+		t.Errorf("html = %q, want absolute image URL", post.HTML)
+	}
+	if post.Text != "Hello world ." {
+		t.Errorf("text = %q, want plain text", post.Text)
+	}
+	if _, err := os.Stat(filepath.Join("export", "notes", "tip.json")); err != nil {
+		t.Errorf("section entry not exported: %v", err)
+	}
+}
+
+// TestExport_Drafts tests including draft posts, and rejecting unknown formats
+func TestExport_Drafts(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["content/posts/2024-01-20-draft.md"] = "---\ntitle: Draft\ndate: 2024-01-20\ndraft: true\n---\n\nWIP.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Export(ExportOptions{OutputDir: "out", Drafts: true}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	var post exportPost
+	readJSON(t, filepath.Join("out", "posts", "draft.json"), &post)
+	if !post.Frontmatter.Draft {
+		t.Errorf("frontmatter.draft = false, want true")
+	}
+
+	if err := Export(ExportOptions{Format: "xml"}); err == nil {
+		t.Error("Export() with format xml succeeded, want error")
+	}
+}
@@ -0,0 +1,244 @@
+package ssg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// linkCheckCachePath is where links found working by `ssg check --external`
+// are recorded, relative to the site root.
+var linkCheckCachePath = filepath.Join(".ssg", "links.json")
+
+// Defaults of LinkCheckConfig.
+const (
+	defaultLinkCheckMaxAge      = 24 * time.Hour
+	defaultLinkCheckConcurrency = 8
+	defaultLinkCheckDelay       = time.Second
+	defaultLinkCheckTimeout     = 10 * time.Second
+)
+
+// linkCheckUserAgent is sent with link checks, since some sites turn away
+// requests without a User-Agent.
+const linkCheckUserAgent = "Mozilla/5.0 (compatible; ssg link checker)"
+
+// LinkCheckConfig configures how `ssg check --external` checks the links
+// to other sites in posts, section entries, and mounted pages.
+//
+// Links are checked a host at a time, with a delay between requests to the
+// same host so no site is flooded, and several hosts at once. Links found
+// working are recorded in .ssg/links.json and not checked again until
+// they're older than maxAge; dead links are checked on every run.
+//
+// Example config.yaml:
+//
+//	linkCheck:
+//	  ignore:
+//	    - https://twitter.com/
+//	    - http://localhost
+//	  maxAge: 72h
+//	  concurrency: 4
+//	  delay: 2s
+//	  timeout: 20s
+type LinkCheckConfig struct {
+	Ignore      []string `yaml:"ignore"`      // Prefixes of URLs not to check, e.g. sites that block checkers
+	MaxAge      string   `yaml:"maxAge"`      // How long a working link isn't checked again (default: 24h)
+	Concurrency int      `yaml:"concurrency"` // Hosts checked at once (default: 8)
+	Delay       string   `yaml:"delay"`       // Wait between requests to the same host (default: 1s)
+	Timeout     string   `yaml:"timeout"`     // How long to wait for each response (default: 10s)
+}
+
+// linkCheckResult is the outcome of checking one link.
+type linkCheckResult struct {
+	url     string
+	dead    string // Why the link is dead, e.g. "404 Not Found" ("" if it isn't)
+	limited bool   // The host answered 429 Too Many Requests, so the link's state is unknown
+}
+
+// checkExternalLinks checks each link in links, a map of URLs to the files
+// linking to them, and reports each dead one for every file linking to it.
+// Links matching cfg.Ignore aren't checked, and nor are links found working
+// within cfg.MaxAge of now (see LinkCheckConfig).
+//
+// Returns an error if the configuration is invalid.
+func checkExternalLinks(links map[string][]string, cfg LinkCheckConfig, now time.Time, report func(file, format string, args ...any)) error {
+	maxAge, err := configDuration("linkCheck.maxAge", cfg.MaxAge, defaultLinkCheckMaxAge)
+	if err != nil {
+		return err
+	}
+	delay, err := configDuration("linkCheck.delay", cfg.Delay, defaultLinkCheckDelay)
+	if err != nil {
+		return err
+	}
+	timeout, err := configDuration("linkCheck.timeout", cfg.Timeout, defaultLinkCheckTimeout)
+	if err != nil {
+		return err
+	}
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultLinkCheckConcurrency
+	}
+
+	// Group the links to check by host, so each host's are checked in turn
+	cache := readLinkCheckCache()
+	byHost := make(map[string][]string)
+	checking := 0
+	for link := range links {
+		if ignoredLink(link, cfg.Ignore) || now.Sub(cache[link]) < maxAge {
+			continue
+		}
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		byHost[u.Host] = append(byHost[u.Host], link)
+		checking++
+	}
+	slog.Info("checking external links", "links", checking, "hosts", len(byHost), "cached", len(links)-checking)
+
+	hosts := make(chan []string)
+	results := make(chan linkCheckResult)
+	client := &http.Client{Timeout: timeout}
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for hostLinks := range hosts {
+				for i, link := range hostLinks {
+					if i > 0 {
+						time.Sleep(delay)
+					}
+					results <- checkLink(client, link)
+				}
+			}
+		}()
+	}
+	go func() {
+		for _, hostLinks := range byHost {
+			sort.Strings(hostLinks)
+			hosts <- hostLinks
+		}
+		close(hosts)
+		wg.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		switch {
+		case result.limited:
+			slog.Warn("host is rate limiting link checks; try a longer linkCheck.delay", "url", result.url)
+		case result.dead != "":
+			for _, file := range links[result.url] {
+				report(file, "dead link to %s (%s)", result.url, result.dead)
+			}
+		default:
+			cache[result.url] = now
+		}
+	}
+
+	for link, checked := range cache {
+		if now.Sub(checked) >= maxAge {
+			delete(cache, link)
+		}
+	}
+	if err := writeLinkCheckCache(cache); err != nil {
+		slog.Warn("caching link checks", "error", err)
+	}
+	return nil
+}
+
+// checkLink requests link, with HEAD and then GET if HEAD fails, since
+// some servers don't answer HEAD requests properly. Redirects are followed.
+func checkLink(client *http.Client, link string) linkCheckResult {
+	status, err := requestLink(client, http.MethodHead, link)
+	if err != nil || status >= 400 {
+		status, err = requestLink(client, http.MethodGet, link)
+	}
+	switch {
+	case err != nil:
+		return linkCheckResult{url: link, dead: err.Error()}
+	case status == http.StatusTooManyRequests:
+		return linkCheckResult{url: link, limited: true}
+	case status >= 400:
+		return linkCheckResult{url: link, dead: fmt.Sprintf("%d %s", status, http.StatusText(status))}
+	}
+	return linkCheckResult{url: link}
+}
+
+// requestLink makes a request to link and returns the status of the
+// response.
+func requestLink(client *http.Client, method, link string) (int, error) {
+	req, err := http.NewRequest(method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", linkCheckUserAgent)
+	resp, err := client.Do(req) // #nosec G107 -- URL comes from the site's own content
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return 0, urlErr.Err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	return resp.StatusCode, nil
+}
+
+// ignoredLink reports whether link starts with one of the ignored prefixes.
+func ignoredLink(link string, ignore []string) bool {
+	for _, prefix := range ignore {
+		if prefix != "" && strings.HasPrefix(link, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// configDuration parses the duration setting named name, returning def if
+// it's unset.
+func configDuration(name, value string, def time.Duration) (time.Duration, error) {
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return d, nil
+}
+
+// readLinkCheckCache loads when each link was last found working. A missing
+// or corrupt cache is treated as empty.
+func readLinkCheckCache() map[string]time.Time {
+	cache := make(map[string]time.Time)
+	data, err := os.ReadFile(linkCheckCachePath)
+	if err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// writeLinkCheckCache saves when each link was last found working.
+func writeLinkCheckCache(cache map[string]time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(linkCheckCachePath), 0750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(linkCheckCachePath, append(data, '\n'), 0600)
+}
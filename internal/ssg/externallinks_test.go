@@ -0,0 +1,102 @@
+package ssg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCheckSite_ExternalLinks tests reporting dead links to other sites, and
+// skipping ignored and recently checked ones
+func TestCheckSite_ExternalLinks(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/ok", "/image.png":
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "linkCheck:\n  delay: 0s\n  ignore: [" + srv.URL + "/ignored]\n"
+	site["content/posts/2024-02-01-links.md"] = "---\ntitle: Links\ndate: 2024-02-01T10:00:00Z\n---\n\n" +
+		"[ok](" + srv.URL + "/ok#part) [gone](" + srv.URL + "/gone) [no head](" + srv.URL + "/no-head) " +
+		"[limited](" + srv.URL + "/limited) [ignored](" + srv.URL + "/ignored/page) [own](https://test.com/missing) " +
+		"![image](" + srv.URL + "/image.png)\n"
+	site["content/posts/2024-03-01-again.md"] = "---\ntitle: Again\ndate: 2024-03-01T10:00:00Z\n---\n\n[gone](" + srv.URL + "/gone)\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	problems, err := checkSite(CheckOptions{ConfigPath: "config.yaml", External: true}, now)
+	if err != nil {
+		t.Fatalf("checkSite() failed: %v", err)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.File+": "+p.Message)
+	}
+	want := []string{
+		"content/posts/2024-02-01-links.md: dead link to " + srv.URL + "/gone (404 Not Found)",
+		"content/posts/2024-03-01-again.md: dead link to " + srv.URL + "/gone (404 Not Found)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("problems = %q, want %q", got, want)
+	}
+	for _, path := range []string{"/ignored/page", "/missing"} {
+		if slices.ContainsFunc(requests, func(r string) bool { return strings.HasSuffix(r, path) }) {
+			t.Errorf("requested %s, which shouldn't be checked", path)
+		}
+	}
+	if !slices.Contains(requests, "GET /no-head") {
+		t.Errorf("requests = %q, want a GET after the failed HEAD", requests)
+	}
+
+	// Working links aren't checked again until they're a day old
+	requests = nil
+	if _, err := checkSite(CheckOptions{ConfigPath: "config.yaml", External: true}, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(requests)
+	if want := []string{"GET /gone", "GET /limited", "HEAD /gone", "HEAD /limited"}; !slices.Equal(requests, want) {
+		t.Errorf("second run requests = %q, want %q", requests, want)
+	}
+
+	// Without External, nothing is fetched
+	requests = nil
+	if _, err := checkSite(CheckOptions{ConfigPath: "config.yaml"}, now); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("requests without External = %q, want none", requests)
+	}
+}
+
+// TestExternalLinks tests finding links to other sites in content
+func TestExternalLinks(t *testing.T) {
+	content := `<a href="https://other.com/a#x">a</a> <a href="https://other.com/a">again</a> <a href="/local">local</a>` +
+		`<a href="https://example.com/own">own</a> <a href="mailto:me@other.com">mail</a>` +
+		`<img src="http://cdn.net/i.png" srcset="https://cdn.net/i-2x.png 2x, /i.png 1x">`
+	got := externalLinks(content, "https://example.com/")
+	want := []string{"https://other.com/a", "http://cdn.net/i.png", "https://cdn.net/i-2x.png"}
+	if !slices.Equal(got, want) {
+		t.Errorf("externalLinks() = %q, want %q", got, want)
+	}
+}
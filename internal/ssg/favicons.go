@@ -0,0 +1,237 @@
+package ssg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"path/filepath"
+
+	"golang.org/x/image/draw"
+)
+
+// webManifestFile is the name of the web app manifest favicons writes.
+const webManifestFile = "site.webmanifest"
+
+// FaviconsConfig generates the site's icons from one source logo: the
+// favicon sizes browsers ask for, an apple-touch-icon, the icons of a web
+// app manifest, and site.webmanifest itself. .Head links them all, and
+// templates that build their own <head> can use .Icons. A file of the same
+// name in static/ is kept instead of the generated one.
+//
+// The logo should be a square PNG or JPEG of at least 512×512; others are
+// centered on a transparent square.
+//
+// Example config.yaml:
+//
+//	favicons:
+//	  source: static/images/logo.png
+//	  shortName: Blog
+//	  themeColor: "#1e293b"
+type FaviconsConfig struct {
+	Source          string `yaml:"source"`          // PNG or JPEG logo the icons are made from
+	Name            string `yaml:"name"`            // App name in the manifest (default: the site's title)
+	ShortName       string `yaml:"shortName"`       // Name under the icon on a home screen (default: name)
+	ThemeColor      string `yaml:"themeColor"`      // Color of the browser's toolbar, as a theme-color meta tag and in the manifest
+	BackgroundColor string `yaml:"backgroundColor"` // Background of the apple-touch-icon and splash screen (default: "#ffffff")
+}
+
+// faviconFiles are the PNG icons favicons generates, with their size in
+// pixels and whether they're linked from the head (the others are listed
+// in the manifest).
+var faviconFiles = []struct {
+	name   string
+	size   int
+	linked bool
+}{
+	{"favicon-16x16.png", 16, true},
+	{"favicon-32x32.png", 32, true},
+	{"apple-touch-icon.png", 180, true},
+	{"android-chrome-192x192.png", 192, false},
+	{"android-chrome-512x512.png", 512, false},
+}
+
+// faviconICOSizes are the sizes of the images in favicon.ico.
+var faviconICOSizes = []int{16, 32, 48}
+
+// webManifest is the JSON of site.webmanifest.
+type webManifest struct {
+	Name            string            `json:"name"`
+	ShortName       string            `json:"short_name"`
+	Icons           []webManifestIcon `json:"icons"`
+	ThemeColor      string            `json:"theme_color,omitempty"`
+	BackgroundColor string            `json:"background_color"`
+	Display         string            `json:"display"`
+	StartURL        string            `json:"start_url"`
+}
+
+// webManifestIcon is an icon in site.webmanifest.
+type webManifestIcon struct {
+	Src   string `json:"src"`
+	Sizes string `json:"sizes"`
+	Type  string `json:"type"`
+}
+
+// icons returns the icons the head links to when cfg generates them.
+func (cfg FaviconsConfig) icons() []headIcon {
+	icons := []headIcon{{Rel: "icon", Href: "/favicon.ico", Sizes: "any"}}
+	for _, f := range faviconFiles {
+		if !f.linked {
+			continue
+		}
+		rel := "icon"
+		if f.name == "apple-touch-icon.png" {
+			rel = "apple-touch-icon"
+		}
+		icons = append(icons, headIcon{Rel: rel, Type: "image/png", Href: "/" + f.name, Sizes: fmt.Sprintf("%dx%d", f.size, f.size)})
+	}
+	return append(icons, headIcon{Rel: "manifest", Href: "/" + webManifestFile})
+}
+
+// writeFavicons generates the icons and web manifest cfg describes (see
+// FaviconsConfig) from its source logo in src, writing them to outputDir in
+// out, except those a file copied from static/ already has the name of.
+// Does nothing if cfg has no source.
+func writeFavicons(src fs.FS, out FS, cfg FaviconsConfig, site SiteConfig, outputDir string) error {
+	if cfg.Source == "" {
+		return nil
+	}
+	logo, err := loadFaviconSource(src, cfg.Source)
+	if err != nil {
+		return err
+	}
+	background := color.Color(color.White)
+	if cfg.BackgroundColor != "" {
+		if background, err = parseHexColor(cfg.BackgroundColor); err != nil {
+			return fmt.Errorf("favicons.backgroundColor: %w", err)
+		}
+	}
+
+	files := make(map[string][]byte)
+	for _, f := range faviconFiles {
+		bg := color.Color(color.Transparent)
+		if f.name == "apple-touch-icon.png" {
+			bg = background // iOS shows transparency as black
+		}
+		if files[f.name], err = encodePNG(squareIcon(logo, f.size, bg)); err != nil {
+			return err
+		}
+	}
+	if files["favicon.ico"], err = faviconICO(logo); err != nil {
+		return err
+	}
+
+	manifest := webManifest{
+		Name:            cfg.Name,
+		ShortName:       cfg.ShortName,
+		ThemeColor:      cfg.ThemeColor,
+		BackgroundColor: cfg.BackgroundColor,
+		Display:         "standalone",
+		StartURL:        "/",
+	}
+	if manifest.Name == "" {
+		manifest.Name = site.Title
+	}
+	if manifest.ShortName == "" {
+		manifest.ShortName = manifest.Name
+	}
+	if manifest.BackgroundColor == "" {
+		manifest.BackgroundColor = "#ffffff"
+	}
+	for _, f := range faviconFiles {
+		if f.name != "apple-touch-icon.png" && f.size >= 192 {
+			manifest.Icons = append(manifest.Icons, webManifestIcon{Src: "/" + f.name, Sizes: fmt.Sprintf("%dx%d", f.size, f.size), Type: "image/png"})
+		}
+	}
+	if files[webManifestFile], err = json.MarshalIndent(manifest, "", "  "); err != nil {
+		return err
+	}
+
+	for name, data := range files {
+		path := filepath.Join(outputDir, name)
+		if _, err := fs.Stat(out, path); err == nil {
+			continue
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if err := out.WriteFile(path, data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadFaviconSource decodes the logo at path in fsys.
+func loadFaviconSource(fsys fs.FS, path string) (image.Image, error) {
+	f, err := fsys.Open(filepath.ToSlash(path))
+	if err != nil {
+		return nil, fmt.Errorf("favicons.source: %w", err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("favicons.source: %s: %w (want a PNG or JPEG)", path, err)
+	}
+	return img, nil
+}
+
+// squareIcon scales img to fit a size×size square on bg, centered.
+func squareIcon(img image.Image, size int, bg color.Color) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	b := img.Bounds()
+	w, h := size, size
+	if b.Dx() > b.Dy() {
+		h = max(1, size*b.Dy()/b.Dx())
+	} else {
+		w = max(1, size*b.Dx()/max(b.Dy(), 1))
+	}
+	x, y := (size-w)/2, (size-h)/2
+	draw.CatmullRom.Scale(dst, image.Rect(x, y, x+w, y+h), img, b, draw.Over, nil)
+	return dst
+}
+
+// faviconICO returns a favicon.ico of img in faviconICOSizes, each stored
+// as PNG, which every browser that reads .ico files supports.
+func faviconICO(img image.Image) ([]byte, error) {
+	var images [][]byte
+	for _, size := range faviconICOSizes {
+		data, err := encodePNG(squareIcon(img, size, color.Transparent))
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, data)
+	}
+
+	var buf bytes.Buffer
+	// ICONDIR: reserved, type (1 = icon), number of images
+	_ = binary.Write(&buf, binary.LittleEndian, [3]uint16{0, 1, uint16(len(images))})
+	offset := 6 + 16*len(images)
+	for i, data := range images {
+		size := uint8(faviconICOSizes[i]) // 0 would mean 256
+		// ICONDIRENTRY: width, height, colors, reserved, planes, bits per
+		// pixel, size of the image, and its offset in the file
+		buf.Write([]byte{size, size, 0, 0})
+		_ = binary.Write(&buf, binary.LittleEndian, [2]uint16{1, 32})
+		_ = binary.Write(&buf, binary.LittleEndian, [2]uint32{uint32(len(data)), uint32(offset)})
+		offset += len(data)
+	}
+	for _, data := range images {
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodePNG encodes img as PNG.
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,111 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_Favicons tests generating icons and a web manifest from a logo
+// and linking them from the head
+func TestBuild_Favicons(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "favicons:\n  source: static/logo.png\n  shortName: Test\n  themeColor: \"#123456\"\n"
+	site["templates/base.html"] = "<head>{{ .Head }}</head><icons>{{ .Icons }}</icons>{{template \"posts\" .}}"
+	site["static/favicon-16x16.png"] = "mine"
+	writeFiles(t, tmpDir, site)
+	logo := image.NewNRGBA(image.Rect(0, 0, 64, 32))
+	for x := range 64 {
+		for y := range 32 {
+			logo.Set(x, y, color.NRGBA{R: 0xff, A: 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, logo); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, tmpDir, map[string]string{"static/logo.png": buf.String()})
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	for name, size := range map[string]int{
+		"favicon-32x32.png":          32,
+		"apple-touch-icon.png":       180,
+		"android-chrome-192x192.png": 192,
+		"android-chrome-512x512.png": 512,
+	} {
+		f, err := os.Open(filepath.Join("public", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("decoding %s: %v", name, err)
+		}
+		if b := img.Bounds(); b.Dx() != size || b.Dy() != size {
+			t.Errorf("%s is %dx%d, want %dx%d", name, b.Dx(), b.Dy(), size, size)
+		}
+		// The wide logo is centered, leaving the top edge as background
+		_, _, _, a := img.At(size/2, 0).RGBA()
+		if want := name == "apple-touch-icon.png"; (a == 0xffff) != want {
+			t.Errorf("%s top edge alpha = %#x, want opaque %v", name, a, want)
+		}
+	}
+	if data, err := os.ReadFile("public/favicon-16x16.png"); err != nil || string(data) != "mine" {
+		t.Errorf("favicon-16x16.png = %q, %v; want the one from static/", data, err)
+	}
+
+	ico, err := os.ReadFile("public/favicon.ico")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dir [3]uint16
+	if err := binary.Read(bytes.NewReader(ico), binary.LittleEndian, &dir); err != nil || dir != [3]uint16{0, 1, 3} {
+		t.Errorf("favicon.ico header = %v, %v; want an icon of 3 images", dir, err)
+	}
+	offset := binary.LittleEndian.Uint32(ico[6+12 : 6+16])
+	if !bytes.HasPrefix(ico[offset:], []byte("\x89PNG")) || ico[6] != 16 {
+		t.Errorf("favicon.ico's first image isn't a 16x16 PNG")
+	}
+
+	var manifest webManifest
+	data, err := os.ReadFile("public/site.webmanifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Name != "Test Blog" || manifest.ShortName != "Test" || manifest.ThemeColor != "#123456" || manifest.BackgroundColor != "#ffffff" ||
+		len(manifest.Icons) != 2 || manifest.Icons[1] != (webManifestIcon{Src: "/android-chrome-512x512.png", Sizes: "512x512", Type: "image/png"}) {
+		t.Errorf("site.webmanifest = %+v", manifest)
+	}
+
+	page, err := os.ReadFile("public/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	links := `<link rel="icon" sizes="any" href="/favicon.ico" />
+<link rel="icon" type="image/png" sizes="16x16" href="/favicon-16x16.png" />
+<link rel="icon" type="image/png" sizes="32x32" href="/favicon-32x32.png" />
+<link rel="apple-touch-icon" type="image/png" sizes="180x180" href="/apple-touch-icon.png" />
+<link rel="manifest" href="/site.webmanifest" />
+<meta name="theme-color" content="#123456" />
+`
+	if got := strings.Count(string(page), links); got != 2 {
+		t.Errorf("page has the icon links %d times, want 2 (in .Head and .Icons):\n%s", got, page)
+	}
+}
@@ -0,0 +1,79 @@
+package ssg
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// FeaturedConfig selects the posts the home page highlights, available to
+// posts.html as .Featured. Candidates are narrowed by Tag, then Recent, and
+// Count of them are featured: the newest, or a random pick if Random is set.
+//
+// Example config.yaml:
+//
+//	featured:
+//	  tag: featured # Only posts tagged "featured"
+//	  recent: 10    # Among the 10 newest of those
+//	  random: true  # Pick at random...
+//	  count: 3      # ...three of them
+//
+// Random picks are seeded, so every build with the same seed and posts
+// features the same ones. The default seed is the build's date (UTC), so the
+// selection rotates daily but shards and rebuilds on the same day agree.
+type FeaturedConfig struct {
+	Tag    string `yaml:"tag"`    // Only feature posts with this tag
+	Recent int    `yaml:"recent"` // Only feature the N newest candidates
+	Random bool   `yaml:"random"` // Pick randomly among candidates instead of the newest
+	Count  int    `yaml:"count"`  // Number of posts to feature (default: 1)
+	Seed   string `yaml:"seed"`   // Seed for random picks (default: the build date)
+}
+
+// enabled reports whether any featured selection is configured.
+func (c FeaturedConfig) enabled() bool {
+	return c.Tag != "" || c.Recent > 0 || c.Random || c.Count > 0
+}
+
+// featuredPosts selects the featured posts from posts (sorted newest first)
+// as configured by c, with now as the build time for the default seed.
+// Returns nil if no selection is configured.
+func featuredPosts(c FeaturedConfig, posts []*parser.Post, now time.Time) []*parser.Post {
+	if !c.enabled() {
+		return nil
+	}
+
+	var candidates []*parser.Post
+	for _, post := range posts {
+		if c.Tag == "" || slices.Contains(post.Tags, c.Tag) {
+			candidates = append(candidates, post)
+		}
+	}
+	if c.Recent > 0 && len(candidates) > c.Recent {
+		candidates = candidates[:c.Recent]
+	}
+
+	count := c.Count
+	if count <= 0 {
+		count = 1
+	}
+	if c.Random {
+		seed := c.Seed
+		if seed == "" {
+			seed = now.UTC().Format(time.DateOnly)
+		}
+		h := fnv.New64a()
+		h.Write([]byte(seed))
+		rng := rand.New(rand.NewPCG(h.Sum64(), 0)) // #nosec G404 -- picks posts to display, not security sensitive
+		candidates = slices.Clone(candidates)
+		rng.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+	}
+	if len(candidates) > count {
+		candidates = candidates[:count]
+	}
+	return candidates
+}
@@ -0,0 +1,98 @@
+package ssg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestFeaturedPosts tests selecting featured posts by tag, recency, and seeded random picks
+func TestFeaturedPosts(t *testing.T) {
+	var posts []*parser.Post
+	for i := range 10 {
+		post := &parser.Post{Slug: fmt.Sprintf("p%d", i)}
+		if i%2 == 1 {
+			post.Tags = []string{"featured"}
+		}
+		posts = append(posts, post)
+	}
+	slugs := func(posts []*parser.Post) []string {
+		var s []string
+		for _, p := range posts {
+			s = append(s, p.Slug)
+		}
+		return s
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	if got := featuredPosts(FeaturedConfig{}, posts, now); got != nil {
+		t.Errorf("featuredPosts() without config = %v, want nil", slugs(got))
+	}
+	if got := slugs(featuredPosts(FeaturedConfig{Tag: "featured", Count: 2}, posts, now)); !slices.Equal(got, []string{"p1", "p3"}) {
+		t.Errorf("featuredPosts(tag) = %v, want [p1 p3]", got)
+	}
+
+	c := FeaturedConfig{Recent: 4, Random: true, Count: 2}
+	got := featuredPosts(c, posts, now)
+	if len(got) != 2 {
+		t.Fatalf("featuredPosts(random) = %v, want 2 posts", slugs(got))
+	}
+	for _, p := range got {
+		if !slices.Contains([]string{"p0", "p1", "p2", "p3"}, p.Slug) {
+			t.Errorf("featuredPosts(random) picked %s, want one of the 4 newest", p.Slug)
+		}
+	}
+	if again := featuredPosts(c, posts, now.Add(time.Hour)); !slices.Equal(slugs(again), slugs(got)) {
+		t.Errorf("featuredPosts() later the same day = %v, want %v", slugs(again), slugs(got))
+	}
+	if posts[0].Slug != "p0" || posts[3].Slug != "p3" {
+		t.Error("featuredPosts() reordered its input")
+	}
+
+	// Different seeds eventually pick differently; a fixed seed never changes
+	c = FeaturedConfig{Random: true, Count: 3, Seed: "home"}
+	fixed := slugs(featuredPosts(c, posts, now))
+	varies := false
+	for day := range 30 {
+		if !slices.Equal(slugs(featuredPosts(c, posts, now.AddDate(0, 0, day))), fixed) {
+			t.Errorf("featuredPosts() with a fixed seed changed on day %d", day)
+		}
+		c2 := FeaturedConfig{Random: true, Count: 3}
+		if !slices.Equal(slugs(featuredPosts(c2, posts, now.AddDate(0, 0, day))), slugs(featuredPosts(c2, posts, now))) {
+			varies = true
+		}
+	}
+	if !varies {
+		t.Error("featuredPosts() with the date seed never rotated in 30 days")
+	}
+}
+
+// TestBuild_Featured tests that posts.html can list the featured posts
+func TestBuild_Featured(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "featured:\n  tag: pinned\n"
+	site["content/posts/2024-02-01-second.md"] = "---\ntitle: Second Post\ndate: 2024-02-01T10:00:00Z\n---\n\nNewer.\n"
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\ntags: [pinned]\n---\n\nOlder.\n"
+	site["templates/posts.html"] = "{{define \"posts\"}}{{range .Featured}}<strong>{{.Title}}</strong>{{end}}{{end}}"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	index, err := os.ReadFile(filepath.Join("public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "<strong>First Post</strong>") || strings.Contains(string(index), "<strong>Second Post</strong>") {
+		t.Errorf("index.html = %s, want only the pinned post featured", index)
+	}
+}
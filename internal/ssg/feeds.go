@@ -0,0 +1,215 @@
+package ssg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// FeedsConfig configures the site's RSS feeds: one of the posts at
+// /feed.xml (per language on a multilingual site), and optionally one per
+// taxonomy term (e.g., /tags/go/feed.xml) and per section (e.g.,
+// /notes/feed.xml), so readers can subscribe to just the topics they follow.
+//
+// Example config.yaml:
+//
+//	feeds:
+//	  enabled: true
+//	  limit: 20
+//	  terms: true
+//	  sections: true
+type FeedsConfig struct {
+	Enabled  bool `yaml:"enabled"`  // Write /feed.xml (needs baseUrl)
+	Limit    int  `yaml:"limit"`    // Newest posts in each feed (default: 20)
+	Terms    bool `yaml:"terms"`    // Write /<taxonomy>/<term>/feed.xml for each taxonomy term
+	Sections bool `yaml:"sections"` // Write <section>/feed.xml for each section
+}
+
+// defaultFeedLimit is the number of posts in a feed if the config doesn't
+// set one.
+const defaultFeedLimit = 20
+
+// rssFeed is the root element of an RSS 2.0 feed.
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	XMLNSAtom string     `xml:"xmlns:atom,attr"`
+	XMLNSDC   string     `xml:"xmlns:dc,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+// rssChannel is the feed itself: its metadata and items.
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"` // Date of the newest item
+	Self          rssLink   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssLink is the feed's link to itself, which feed validators expect.
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// rssItem is one post in a feed.
+type rssItem struct {
+	Title        string   `xml:"title"`
+	Link         string   `xml:"link"`
+	GUID         rssGUID  `xml:"guid"`
+	PubDate      string   `xml:"pubDate"`
+	Description  string   `xml:"description"` // The post's content, with absolute URLs
+	Categories   []string `xml:"category"`
+	Updated      string   `xml:"atom:updated,omitempty"` // When the post last changed, if after its date (see assignGitInfo)
+	Contributors []string `xml:"dc:contributor"`         // Authors of the commits changing the post
+}
+
+// rssGUID identifies an item by its URL.
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// siteFeed is a feed to write: its site-relative URL, the URL of the page it
+// follows, its title, and its posts, newest first.
+type siteFeed struct {
+	URL, PageURL, Title string
+	Posts               []*parser.Post
+}
+
+// feedsPlugin writes the site's RSS feeds (see FeedsConfig).
+type feedsPlugin struct{ BasePlugin }
+
+func (feedsPlugin) Name() string { return "feeds" }
+
+func (feedsPlugin) AfterBuild(b *BuildContext) error {
+	if !b.Config.Feeds.Enabled || !b.First() {
+		return nil
+	}
+	if b.Config.BaseURL == "" {
+		return fmt.Errorf("feeds need baseUrl set, since their links must be absolute")
+	}
+	for _, lang := range indexLanguages(*b.Config) {
+		site := b.Config.forLanguage(lang)
+		feed := siteFeed{
+			URL:     b.Config.languageURL(lang, "/feed.xml"),
+			PageURL: b.Config.languageURL(lang, "/"),
+			Title:   site.Title,
+			Posts:   postsInLanguage(b.Posts, lang),
+		}
+		if err := writeFeed(b, site, feed); err != nil {
+			return err
+		}
+	}
+	if b.Config.Feeds.Terms {
+		for _, tax := range b.Taxonomies {
+			for _, term := range tax.Terms {
+				feed := siteFeed{URL: termFeedURL(tax, term), PageURL: term.URL, Title: b.Config.Title + ": " + term.Name, Posts: term.Posts}
+				if err := writeFeed(b, *b.Config, feed); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if b.Config.Feeds.Sections {
+		for _, section := range b.Sections {
+			feed := siteFeed{URL: sectionFeedURL(section), PageURL: section.URL, Title: b.Config.Title + ": " + section.Title, Posts: section.Posts}
+			if err := writeFeed(b, *b.Config, feed); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// termFeedURL returns the site-relative URL of the feed of a taxonomy term,
+// e.g. "/tags/go/feed.xml", whatever the site's URL style.
+func termFeedURL(tax *Taxonomy, term *Term) string {
+	return path.Join(tax.URL, term.Slug, "feed.xml")
+}
+
+// sectionFeedURL returns the site-relative URL of the feed of a section,
+// e.g. "/notes/feed.xml".
+func sectionFeedURL(section *Section) string {
+	return path.Join(section.URL, "feed.xml")
+}
+
+// writeFeed writes feed as RSS 2.0, with at most the configured number of
+// posts. Post content is included in full, with its links made absolute.
+func writeFeed(b *BuildContext, site SiteConfig, feed siteFeed) error {
+	limit := site.Feeds.Limit
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	posts := feed.Posts
+	if len(posts) > limit {
+		posts = posts[:limit]
+	}
+
+	channel := rssChannel{
+		Title:       feed.Title,
+		Link:        absURL(site.BaseURL, feed.PageURL),
+		Description: site.Description,
+		Language:    site.Language,
+		Self:        rssLink{Href: absURL(site.BaseURL, feed.URL), Rel: "self", Type: "application/rss+xml"},
+	}
+	if channel.Description == "" {
+		channel.Description = feed.Title
+	}
+	if len(posts) > 0 {
+		channel.LastBuildDate = posts[0].Date.Format(time.RFC1123Z)
+	}
+	for _, post := range posts {
+		link := absURL(site.BaseURL, post.URL)
+		item := rssItem{
+			Title:        post.Title,
+			Link:         link,
+			GUID:         rssGUID{Value: link, IsPermaLink: true},
+			PubDate:      post.Date.Format(time.RFC1123Z),
+			Description:  string(absoluteURLs(post.Content, site.BaseURL, post.URL)),
+			Categories:   post.Tags,
+			Contributors: post.Contributors,
+		}
+		if post.Lastmod.After(post.Date) {
+			item.Updated = post.Lastmod.Format(time.RFC3339)
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	data, err := xml.MarshalIndent(rssFeed{Version: "2.0", XMLNSAtom: "http://www.w3.org/2005/Atom", XMLNSDC: "http://purl.org/dc/elements/1.1/", Channel: channel}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding feed %s: %w", feed.URL, err)
+	}
+	data = append([]byte(xml.Header), data...)
+	outputPath := filepath.Join(b.OutputDir, filepath.FromSlash(strings.TrimPrefix(feed.URL, "/")))
+	if err := b.Output.WriteFile(outputPath, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("writing feed %s: %w", feed.URL, err)
+	}
+	return nil
+}
+
+// pageFeeds returns the site-relative URLs of the feeds a page links to in
+// its head: the site's feed, and the feed of the term or section it lists.
+func pageFeeds(data PageData) []string {
+	cfg := data.Site.Feeds
+	if !cfg.Enabled || data.Site.BaseURL == "" {
+		return nil
+	}
+	feeds := []string{data.Site.languageURL(data.Site.Language, "/feed.xml")}
+	switch {
+	case cfg.Terms && data.Kind == KindTerm && data.Taxonomy != nil && data.Term != nil:
+		feeds = append(feeds, termFeedURL(data.Taxonomy, data.Term))
+	case cfg.Sections && data.Kind == KindSection && data.Section != nil:
+		feeds = append(feeds, sectionFeedURL(data.Section))
+	}
+	return feeds
+}
@@ -0,0 +1,127 @@
+package ssg
+
+import (
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_Feeds tests writing the site's feed and those of each term and section
+func TestBuild_Feeds(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "taxonomies: [tags]\nfeeds:\n  enabled: true\n  limit: 2\n  terms: true\n  sections: true\n"
+	site["templates/base.html"] = "<html>\n<head>{{.Head}}</head>\n<body>\n{{template \"posts\" .}}\n</body>\n</html>\n"
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\ntags: [go]\n---\n\n![Gopher](/images/gopher.png)\n"
+	site["content/posts/2024-01-16-second.md"] = "---\ntitle: Second Post\ndate: 2024-01-16T10:00:00Z\ntags: [go, web]\n---\n\nSecond.\n"
+	site["content/posts/2024-01-17-third.md"] = "---\ntitle: Third Post\ndate: 2024-01-17T10:00:00Z\ntags: [go]\n---\n\nThird.\n"
+	site["content/notes/2024-02-01-a-note.md"] = "---\ntitle: A Note\ndate: 2024-02-01T10:00:00Z\n---\n\nNote.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	tests := []struct {
+		file      string
+		wantTitle string
+		wantItems []string
+	}{
+		{file: "feed.xml", wantTitle: "Test Blog", wantItems: []string{"Third Post", "Second Post"}},
+		{file: "tags/go/feed.xml", wantTitle: "Test Blog: go", wantItems: []string{"Third Post", "Second Post"}},
+		{file: "tags/web/feed.xml", wantTitle: "Test Blog: web", wantItems: []string{"Second Post"}},
+		{file: "notes/feed.xml", wantTitle: "Test Blog: Notes", wantItems: []string{"A Note"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("public", filepath.FromSlash(tt.file)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var feed rssFeed
+			if err := xml.Unmarshal(data, &feed); err != nil {
+				t.Fatalf("parsing feed: %v", err)
+			}
+			if feed.Channel.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", feed.Channel.Title, tt.wantTitle)
+			}
+			var titles []string
+			for _, item := range feed.Channel.Items {
+				titles = append(titles, item.Title)
+			}
+			if strings.Join(titles, ", ") != strings.Join(tt.wantItems, ", ") {
+				t.Errorf("items = %v, want %v", titles, tt.wantItems)
+			}
+		})
+	}
+
+	// Content links are absolute, and pages link to their feeds
+	feed, err := os.ReadFile(filepath.Join("public", "tags", "web", "feed.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<link>https://test.com/posts/second.html</link>",
+		`<atom:link href="https://test.com/tags/web/feed.xml" rel="self" type="application/rss+xml"></atom:link>`,
+		"<pubDate>Tue, 16 Jan 2024 10:00:00 +0000</pubDate>",
+		"<category>web</category>",
+	} {
+		if !strings.Contains(string(feed), want) {
+			t.Errorf("feed doesn't contain %q:\n%s", want, feed)
+		}
+	}
+	page, err := os.ReadFile(filepath.Join("public", "tags", "go.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`type="application/rss&#43;xml" href="/feed.xml"`,
+		`type="application/rss&#43;xml" href="/tags/go/feed.xml"`,
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("term page doesn't contain %q:\n%s", want, page)
+		}
+	}
+}
+
+// TestWriteFeed_AbsoluteContent tests that links in post content are made absolute
+func TestWriteFeed_AbsoluteContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "feeds:\n  enabled: true\n"
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\n[Second](second.html)\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join("public", "feed.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var feed rssFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		t.Fatalf("parsing feed: %v", err)
+	}
+	if len(feed.Channel.Items) != 1 || !strings.Contains(feed.Channel.Items[0].Description, `href="https://test.com/posts/second.html"`) {
+		t.Errorf("items = %+v, want one with absolute links", feed.Channel.Items)
+	}
+}
+
+// TestBuild_FeedsNeedBaseURL tests that feeds without baseUrl fail the build
+func TestBuild_FeedsNeedBaseURL(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] = "title: Test Blog\nfeeds:\n  enabled: true\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err == nil || !strings.Contains(err.Error(), "baseUrl") {
+		t.Errorf("Build() error = %v, want error about baseUrl", err)
+	}
+}
@@ -0,0 +1,101 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// FormatsConfig maps file extensions of content in formats other than
+// markdown to the commands converting it to HTML. Files with these
+// extensions are posts and section entries like markdown files, with the
+// same YAML frontmatter; the rest of the file is piped to the command,
+// which prints its HTML. The command runs with the platform's shell, with
+// SSG_FILE set to the path of the file.
+//
+// Example config.yaml:
+//
+//	formats:
+//	  .adoc: asciidoctor --embedded --out-file - -
+//	  .org: pandoc --from org --to html
+type FormatsConfig map[string]string
+
+var (
+	registeredParsersMu sync.Mutex
+	registeredParsers   = make(map[string]parser.ContentParser) // By extension, lowercase with a dot
+)
+
+// RegisterContentParser makes files with the extension ext (e.g., ".adoc")
+// content in builds started afterwards, parsed by cp, like the formats in
+// the config (see FormatsConfig), which take precedence. Registering ext
+// again replaces the earlier parser.
+func RegisterContentParser(ext string, cp parser.ContentParser) {
+	registeredParsersMu.Lock()
+	defer registeredParsersMu.Unlock()
+	registeredParsers[contentExt(ext)] = cp
+}
+
+// commandParser is a parser.ContentParser converting content to HTML with a
+// shell command (see FormatsConfig).
+type commandParser string
+
+func (c commandParser) Convert(body []byte, path string) (template.HTML, error) {
+	cmd := shellCommand(context.Background(), string(c))
+	cmd.Env = append(os.Environ(), "SSG_FILE="+path)
+	cmd.Stdin = bytes.NewReader(body)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%q: %w: %s", string(c), err, strings.TrimSpace(stderr.String()))
+	}
+	// #nosec G203 -- HTML output from a converter in the site's own config
+	return template.HTML(out), nil
+}
+
+// contentParsers returns the parsers of the formats in the config and those
+// registered with RegisterContentParser, by extension.
+func (c FormatsConfig) contentParsers() map[string]parser.ContentParser {
+	registeredParsersMu.Lock()
+	defer registeredParsersMu.Unlock()
+	parsers := make(map[string]parser.ContentParser, len(registeredParsers)+len(c))
+	for ext, cp := range registeredParsers {
+		parsers[ext] = cp
+	}
+	for ext, command := range c {
+		parsers[contentExt(ext)] = commandParser(command)
+	}
+	return parsers
+}
+
+// isContentFile reports whether the file named name is content: markdown
+// (see MarkdownConfig.Extensions), a Jupyter notebook, or in one of the
+// formats of the config or registered with RegisterContentParser.
+func (c SiteConfig) isContentFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	if _, ok := c.Formats.contentParsers()[ext]; ok {
+		return true
+	}
+	if ext == ".ipynb" && !isFalse(c.Notebooks) {
+		return true
+	}
+	exts := c.Markdown.Extensions
+	if len(exts) == 0 {
+		exts = parser.MarkdownExtensions
+	}
+	return slices.ContainsFunc(exts, func(e string) bool { return contentExt(e) == ext })
+}
+
+// contentExt returns a file extension lowercase and starting with a dot, as
+// the config may give it as "adoc" or ".ADOC".
+func contentExt(ext string) string {
+	return "." + strings.TrimPrefix(strings.ToLower(ext), ".")
+}
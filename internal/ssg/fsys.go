@@ -0,0 +1,336 @@
+package ssg
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FS is a filesystem a build reads a site's sources from and writes its
+// output to: a directory on disk (see DirFS) or memory (see MemFS). Names
+// are the paths the build uses, like "content/posts" or "public/index.html",
+// so the same code runs against either.
+type FS interface {
+	fs.ReadDirFS
+	fs.ReadFileFS
+	fs.StatFS
+
+	// WriteFile writes data to the file name, creating it and any missing
+	// parent directories if needed.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	MkdirAll(name string, perm fs.FileMode) error
+	RemoveAll(name string) error
+	Chmod(name string, mode fs.FileMode) error
+}
+
+// DirFS returns an FS for the directory dir on disk ("." for the working
+// directory). Unlike os.DirFS, names may be absolute or climb out of dir
+// with "..", as the config and output paths given on the command line can.
+func DirFS(dir string) FS {
+	return dirFS(dir)
+}
+
+// dirFS is the FS of a directory on disk, returned by DirFS.
+type dirFS string
+
+// path returns the path on disk of name.
+func (d dirFS) path(name string) string {
+	name = filepath.FromSlash(name)
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(string(d), name)
+}
+
+// diskPath returns the path on disk of name in fsys, for tools that can only
+// read files from disk (like esbuild), or false if fsys isn't on disk.
+func diskPath(fsys fs.FS, name string) (string, bool) {
+	d, ok := fsys.(dirFS)
+	if !ok {
+		return "", false
+	}
+	return d.path(name), true
+}
+
+// subFS returns the tree under dir in fsys, like fs.Sub, but allowing the
+// absolute and ".." paths DirFS does.
+func subFS(fsys fs.FS, dir string) (fs.FS, error) {
+	if d, ok := fsys.(dirFS); ok {
+		return dirFS(d.path(dir)), nil
+	}
+	return fs.Sub(fsys, filepath.ToSlash(dir))
+}
+
+func (d dirFS) Open(name string) (fs.File, error) {
+	return os.Open(d.path(name))
+}
+
+func (d dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(d.path(name))
+}
+
+func (d dirFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(d.path(name))
+}
+
+func (d dirFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(d.path(name))
+}
+
+func (d dirFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	p := d.path(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, perm)
+}
+
+func (d dirFS) MkdirAll(name string, perm fs.FileMode) error {
+	return os.MkdirAll(d.path(name), perm)
+}
+
+func (d dirFS) RemoveAll(name string) error {
+	return os.RemoveAll(d.path(name))
+}
+
+func (d dirFS) Chmod(name string, mode fs.FileMode) error {
+	return os.Chmod(d.path(name), mode)
+}
+
+// MemFS is an FS held in memory, so a site can be built without touching
+// the disk: by tests, or to serve the output straight from memory. The zero
+// value is an empty filesystem ready to use, and it's safe for concurrent
+// use. Absolute names are kept relative to its root.
+type MemFS struct {
+	mu    sync.RWMutex
+	files map[string]*memFile // By cleaned, slash-separated name
+}
+
+// memFile is a file or directory in a MemFS.
+type memFile struct {
+	data    []byte
+	mode    fs.FileMode // Includes fs.ModeDir for directories
+	modTime time.Time
+}
+
+// memName returns the key of name in a MemFS's files.
+func memName(name string) string {
+	name = path.Clean("/" + filepath.ToSlash(name))
+	if name == "/" {
+		return "."
+	}
+	return name[1:]
+}
+
+// stat returns the file at name, or nil if there's none. The root always
+// exists. Callers hold m.mu.
+func (m *MemFS) stat(name string) *memFile {
+	if name == "." {
+		return &memFile{mode: fs.ModeDir | 0750}
+	}
+	return m.files[name]
+}
+
+// mkdirAll creates the directory name and its parents. Callers hold m.mu
+// for writing.
+func (m *MemFS) mkdirAll(name string, perm fs.FileMode) error {
+	if name == "." {
+		return nil
+	}
+	if f := m.files[name]; f != nil {
+		if !f.mode.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+		}
+		return nil
+	}
+	if err := m.mkdirAll(path.Dir(name), perm); err != nil {
+		return err
+	}
+	if m.files == nil {
+		m.files = make(map[string]*memFile)
+	}
+	m.files[name] = &memFile{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+// Open opens the file or directory name for reading.
+func (m *MemFS) Open(name string) (fs.File, error) {
+	info, err := m.Stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if info.IsDir() {
+		entries, err := m.ReadDir(name)
+		if err != nil {
+			return nil, err
+		}
+		return &memDir{info: info, entries: entries}, nil
+	}
+	data, err := m.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &memReader{info: info, Reader: bytes.NewReader(data)}, nil
+}
+
+// ReadDir lists the entries of directory name, sorted by name.
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	dir := memName(name)
+	if f := m.stat(dir); f == nil || !f.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	prefix := dir + "/"
+	if dir == "." {
+		prefix = ""
+	}
+	var entries []fs.DirEntry
+	for key, f := range m.files {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if ok && rest != "" && !strings.Contains(rest, "/") {
+			entries = append(entries, fs.FileInfoToDirEntry(memInfo{name: rest, f: f}))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// ReadFile returns a copy of the contents of the file name.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f := m.stat(memName(name))
+	if f == nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	if f.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	return bytes.Clone(f.data), nil
+}
+
+// Stat describes the file or directory name.
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	key := memName(name)
+	f := m.stat(key)
+	if f == nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return memInfo{name: path.Base(key), f: f}, nil
+}
+
+// WriteFile stores a copy of data as the file name, creating any missing
+// parent directories.
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := memName(name)
+	if f := m.stat(key); f != nil && f.mode.IsDir() {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrExist}
+	}
+	if err := m.mkdirAll(path.Dir(key), 0750); err != nil {
+		return err
+	}
+	if m.files == nil {
+		m.files = make(map[string]*memFile)
+	}
+	m.files[key] = &memFile{data: bytes.Clone(data), mode: perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+// MkdirAll creates the directory name and any missing parents.
+func (m *MemFS) MkdirAll(name string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mkdirAll(memName(name), perm)
+}
+
+// RemoveAll removes name and everything under it. A missing name is not an
+// error.
+func (m *MemFS) RemoveAll(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := memName(name)
+	for k := range m.files {
+		if key == "." || k == key || strings.HasPrefix(k, key+"/") {
+			delete(m.files, k)
+		}
+	}
+	return nil
+}
+
+// Chmod sets the permission bits of name.
+func (m *MemFS) Chmod(name string, mode fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := memName(name)
+	f := m.files[key]
+	if f == nil {
+		return &fs.PathError{Op: "chmod", Path: name, Err: fs.ErrNotExist}
+	}
+	copied := *f // Files handed out by Stat keep their mode
+	copied.mode = f.mode.Type() | mode.Perm()
+	m.files[key] = &copied
+	return nil
+}
+
+// memInfo describes a file in a MemFS.
+type memInfo struct {
+	name string
+	f    *memFile
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return int64(len(i.f.data)) }
+func (i memInfo) Mode() fs.FileMode  { return i.f.mode }
+func (i memInfo) ModTime() time.Time { return i.f.modTime }
+func (i memInfo) IsDir() bool        { return i.f.mode.IsDir() }
+func (i memInfo) Sys() any           { return nil }
+
+// memReader is a file in a MemFS opened for reading.
+type memReader struct {
+	info fs.FileInfo
+	*bytes.Reader
+}
+
+func (r *memReader) Stat() (fs.FileInfo, error) { return r.info, nil }
+func (r *memReader) Close() error               { return nil }
+
+// memDir is a directory in a MemFS opened for reading its entries.
+type memDir struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: fs.ErrInvalid}
+}
+
+// ReadDir returns the next n entries, or all remaining ones if n <= 0.
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
@@ -0,0 +1,145 @@
+package ssg
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestMemFS tests that MemFS behaves as an fs.FS and supports the writes a build makes
+func TestMemFS(t *testing.T) {
+	var m MemFS
+	if err := m.WriteFile("public/posts/first.html", []byte("<p>First</p>"), 0600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	if err := m.WriteFile("/public/index.html", []byte("<p>Home</p>"), 0600); err != nil {
+		t.Fatalf("WriteFile() with an absolute name failed: %v", err)
+	}
+	if err := m.MkdirAll("public/empty", 0750); err != nil {
+		t.Fatalf("MkdirAll() failed: %v", err)
+	}
+	var walked []string
+	err := fs.WalkDir(&m, ".", func(path string, d fs.DirEntry, err error) error {
+		walked = append(walked, path)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WalkDir() failed: %v", err)
+	}
+	want := ". public public/empty public/index.html public/posts public/posts/first.html"
+	if got := strings.Join(walked, " "); got != want {
+		t.Errorf("WalkDir() visited %s, want %s", got, want)
+	}
+	if data, err := fs.ReadFile(&m, "public/index.html"); err != nil || string(data) != "<p>Home</p>" {
+		t.Errorf("ReadFile() = %q, %v, want <p>Home</p>", data, err)
+	}
+
+	if err := m.WriteFile("public/posts", nil, 0600); err == nil {
+		t.Error("WriteFile() over a directory succeeded, want error")
+	}
+	if err := m.Chmod("public/index.html", 0644); err != nil {
+		t.Fatalf("Chmod() failed: %v", err)
+	}
+	if info, err := m.Stat("public/index.html"); err != nil || info.Mode() != 0644 {
+		t.Errorf("Stat() = %v, %v, want mode 0644", info, err)
+	}
+
+	if err := m.RemoveAll("public/posts"); err != nil {
+		t.Fatalf("RemoveAll() failed: %v", err)
+	}
+	if _, err := m.ReadFile("public/posts/first.html"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile() of a removed file = %v, want fs.ErrNotExist", err)
+	}
+	entries, err := m.ReadDir("public")
+	if err != nil {
+		t.Fatalf("ReadDir() failed: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if got := strings.Join(names, " "); got != "empty index.html" {
+		t.Errorf("ReadDir() = %s, want empty index.html", got)
+	}
+}
+
+// TestBuild_InMemory tests building a site from and to a MemFS without touching the disk
+func TestBuild_InMemory(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	src := &MemFS{}
+	for name, content := range testSite() {
+		if err := src.WriteFile(name, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range map[string]string{
+		"static/css/style.css":   "body { margin: 0; }",
+		"go.mod":                 "module example.com/site\n",
+		"greet/greet.go":         "// Package greet says hello.\npackage greet\n\n// Hello says hello.\nfunc Hello() {}\n",
+		"greet/gen.go":           "//go:build ignore\n\npackage main\n\nfunc main() {}\n",
+		"templates/package.html": "{{define \"posts\"}}{{.Package.ImportPath}}{{range .Package.Funcs}} {{.Name}}{{end}}{{end}}",
+	} {
+		if err := src.WriteFile(name, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config, _ := src.ReadFile("config.yaml")
+	if err := src.WriteFile("config.yaml", append(config, "godoc:\n  packages: [greet]\n"...), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Build(context.Background(), BuildOptions{Source: src}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	for _, name := range []string{"public/index.html", "public/posts/first.html", "public/css/style.css", ".ssg/manifest.json"} {
+		if _, err := src.Stat(name); err != nil {
+			t.Errorf("%s wasn't written: %v", name, err)
+		}
+	}
+	post, err := src.ReadFile("public/posts/first.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(post), "Hello from the first post.") {
+		t.Errorf("post page missing content:\n%s", post)
+	}
+	pkg, err := src.ReadFile("public/pkg/greet.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(pkg), "example.com/site/greet Hello") {
+		t.Errorf("package page missing import path or Hello:\n%s", pkg)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) > 0 {
+		t.Errorf("build wrote %s to the working directory", entries[0].Name())
+	}
+}
+
+// TestBuild_MemOutput tests building a site on disk into a MemFS
+func TestBuild_MemOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+
+	out := &MemFS{}
+	if err := Build(context.Background(), BuildOptions{Source: DirFS(tmpDir), Output: out}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if _, err := out.Stat("public/posts/first.html"); err != nil {
+		t.Errorf("post page wasn't written to the MemFS: %v", err)
+	}
+	for _, name := range []string{"public", ".ssg"} {
+		if _, err := os.Stat(tmpDir + "/" + name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s was written to disk", name)
+		}
+	}
+}
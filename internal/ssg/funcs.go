@@ -0,0 +1,224 @@
+package ssg
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+	"golang.org/x/net/html"
+)
+
+var (
+	registeredFuncsMu sync.Mutex
+	registeredFuncs   = template.FuncMap{}
+	registeredCaps    = map[string][]string{} // Capabilities declared with RegisterFuncWithCapabilities
+)
+
+// RegisterFunc adds a function to the FuncMap of every renderer created
+// afterwards. It lets Go programs embedding the generator extend templates
+// with their own functions. A registered function replaces a standard
+// function of the same name.
+//
+// fn must satisfy the text/template rules for functions: it returns one
+// value, or a value and an error.
+//
+// Sandboxed themes (see ThemeConfig.Sandbox) can't call fn unless every
+// capability is granted, since what it does isn't known; use
+// RegisterFuncWithCapabilities to declare what it needs.
+func RegisterFunc(name string, fn any) {
+	registeredFuncsMu.Lock()
+	defer registeredFuncsMu.Unlock()
+	registeredFuncs[name] = fn
+	delete(registeredCaps, name)
+}
+
+// RegisterFuncWithCapabilities is RegisterFunc for a function that declares
+// the capabilities it needs (CapReadFiles, CapExec, and so on), so sandboxed
+// themes granted those capabilities can call it. A function that only
+// formats its arguments needs none.
+func RegisterFuncWithCapabilities(name string, fn any, caps ...string) {
+	registeredFuncsMu.Lock()
+	defer registeredFuncsMu.Unlock()
+	registeredFuncs[name] = fn
+	registeredCaps[name] = caps
+}
+
+// templateFuncs builds the FuncMap available to all templates.
+//
+// Standard functions:
+//   - dateFormat: formats a time with a Go layout, e.g. {{ .Post.Date | dateFormat "Jan 2, 2006" }}
+//   - truncate: shortens text to n characters with an ellipsis, e.g. {{ .Post.Description | truncate 80 }}
+//   - markdownify: renders a markdown string to HTML, e.g. {{ .Site.Description | markdownify }}
+//   - slugify: converts text to a URL slug, transliterated for the site's
+//     language, e.g. {{ slugify "Hello World" }} → "hello-world"
+//   - sortByTitle: sorts posts by title in the site language's order, e.g. {{ range sortByTitle .Posts }}
+//   - sortStrings: sorts strings in the site language's order, e.g. {{ range sortStrings .Post.Tags }}
+//   - absURL: prefixes a path with baseUrl, e.g. {{ absURL "/css/style.css" }}
+//   - termURL: returns the URL of a taxonomy term's page (see Taxonomy), e.g.
+//     {{ range .Post.Tags }}<a href="{{ termURL "tags" . }}">{{ . }}</a>{{ end }}
+//   - safeHTML: marks a string as trusted HTML so it isn't escaped
+//   - getJSON: fetches and decodes a remote JSON document, cached between
+//     builds (see RemoteDataConfig), e.g. {{ range getJSON "https://api.example.com/repos" }}
+//   - getCSV: fetches a remote CSV document as rows of fields, cached the
+//     same way, e.g. {{ range getCSV "https://example.com/talks.csv" }}
+//
+// Functions added with RegisterFunc are layered on top, followed by the
+// user-defined funcs from config.yaml (see userFunc).
+//
+// Parameters:
+//   - config: Site configuration (baseUrl, language, remote data, and user-defined funcs)
+//   - p: Parser used by markdownify
+//
+// Returns the FuncMap, or an error if a user-defined func fails to parse or
+// remoteData.maxAge is invalid.
+func templateFuncs(config SiteConfig, p *parser.Parser) (template.FuncMap, error) {
+	remote, err := newRemoteData(config.RemoteData)
+	if err != nil {
+		return nil, err
+	}
+	funcs := template.FuncMap{
+		"dateFormat":  dateFormat,
+		"truncate":    truncate,
+		"markdownify": p.Markdownify,
+		"slugify":     func(s string) string { return slugifyLang(config.Language, s) },
+		"sortByTitle": func(posts []*parser.Post) []*parser.Post { return sortByTitle(config.Language, posts) },
+		"sortStrings": func(items []string) []string { return sortStrings(config.Language, items) },
+		"absURL":      func(path string) string { return absURL(config.BaseURL, path) },
+		"termURL":     func(taxonomy, term string) string { return termURL(config, taxonomy, term) },
+		"safeHTML":    safeHTML,
+		"getJSON":     remote.getJSON,
+		"getCSV":      remote.getCSV,
+	}
+
+	registeredFuncsMu.Lock()
+	maps.Copy(funcs, registeredFuncs)
+	registeredFuncsMu.Unlock()
+
+	// User-defined funcs can call standard and registered funcs, but not
+	// each other, so they don't depend on map iteration order.
+	base := maps.Clone(funcs)
+	for name, body := range config.Funcs {
+		fn, err := userFunc(name, body, base)
+		if err != nil {
+			return nil, err
+		}
+		funcs[name] = fn
+	}
+
+	return funcs, nil
+}
+
+// userFunc compiles a user-defined function from config.yaml. The body is a
+// template snippet; the function's arguments are available as the dot:
+// nothing for zero arguments, the argument itself for one, and a slice for
+// more. For example, with
+//
+//	funcs:
+//	  greet: "Hello, {{ . }}!"
+//
+// {{ greet .Site.Author }} renders "Hello, Your Name!".
+func userFunc(name, body string, funcs template.FuncMap) (func(args ...any) (template.HTML, error), error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing func %q: %w", name, err)
+	}
+
+	return func(args ...any) (template.HTML, error) {
+		var data any
+		switch len(args) {
+		case 0:
+		case 1:
+			data = args[0]
+		default:
+			data = args
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", err
+		}
+		// #nosec G203 -- output of an html/template, escaped on execution
+		return template.HTML(buf.String()), nil
+	}, nil
+}
+
+// dateFormat formats t using a Go time layout. The layout comes first so the
+// function works at the end of a pipeline.
+func dateFormat(layout string, t time.Time) string {
+	return t.Format(layout)
+}
+
+// truncate shortens s to at most n characters, cutting at the last word
+// boundary and appending an ellipsis. HTML input is reduced to its text first
+// so tags are never cut in half.
+func truncate(n int, s any) string {
+	var text string
+	switch v := s.(type) {
+	case template.HTML:
+		text = htmlText(string(v))
+	default:
+		text = fmt.Sprint(v)
+	}
+	text = strings.Join(strings.Fields(text), " ")
+
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	runes := []rune(text)[:n]
+	cut := string(runes)
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+// htmlText returns the text content of an HTML fragment.
+func htmlText(s string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return b.String()
+		case html.TextToken:
+			b.Write(z.Text())
+		case html.StartTagToken, html.EndTagToken:
+			b.WriteByte(' ')
+		}
+	}
+}
+
+// slugify converts text to a URL-friendly slug: lowercase, spaces replaced by
+// hyphens, and everything except a-z, 0-9, and hyphens removed.
+func slugify(s string) string {
+	slug := strings.ToLower(s)
+	slug = strings.ReplaceAll(slug, " ", "-")
+	var clean strings.Builder
+	for _, r := range slug {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			clean.WriteRune(r)
+		}
+	}
+	return clean.String()
+}
+
+// absURL joins baseURL and path with exactly one slash between them. Paths
+// that are already absolute URLs are returned unchanged.
+func absURL(baseURL, path string) string {
+	if strings.Contains(path, "://") || strings.HasPrefix(path, "//") {
+		return path
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// safeHTML marks s as trusted HTML so html/template doesn't escape it.
+func safeHTML(s string) template.HTML {
+	// #nosec G203 -- explicit opt-in by the template author
+	return template.HTML(s)
+}
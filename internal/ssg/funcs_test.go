@@ -0,0 +1,102 @@
+package ssg
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// executeWithFuncs parses and executes a template string with the standard funcs
+func executeWithFuncs(t *testing.T, config SiteConfig, tmpl string, data any) string {
+	t.Helper()
+	funcs, err := templateFuncs(config, parser.New())
+	if err != nil {
+		t.Fatalf("templateFuncs() failed: %v", err)
+	}
+	parsed, err := template.New("test").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		t.Fatalf("parsing template: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := parsed.Execute(&buf, data); err != nil {
+		t.Fatalf("executing template: %v", err)
+	}
+	return buf.String()
+}
+
+// TestTemplateFuncs tests the standard template functions
+func TestTemplateFuncs(t *testing.T) {
+	config := SiteConfig{BaseURL: "https://example.com/"}
+	data := map[string]any{
+		"Date": time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		"Text": "The quick brown fox jumps over the lazy dog",
+		"HTML": template.HTML("<p>Hello <strong>bold</strong> world</p>"),
+	}
+
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"dateFormat", `{{ .Date | dateFormat "January 2, 2006" }}`, "January 15, 2024"},
+		{"truncate", `{{ .Text | truncate 18 }}`, "The quick brown…"},
+		{"truncate short", `{{ "short" | truncate 18 }}`, "short"},
+		{"truncate html", `{{ .HTML | truncate 12 }}`, "Hello bold…"},
+		{"markdownify", `{{ "Some **bold**" | markdownify }}`, "<p>Some <strong>bold</strong></p>"},
+		{"slugify", `{{ slugify "Hello, World 2024" }}`, "hello-world-2024"},
+		{"absURL", `{{ absURL "/css/style.css" }}`, "https://example.com/css/style.css"},
+		{"absURL absolute", `{{ absURL "https://cdn.example.com/x.js" }}`, "https://cdn.example.com/x.js"},
+		{"safeHTML", `{{ safeHTML "<em>hi</em>" }}`, "<em>hi</em>"},
+		{"termURL", `{{ termURL "tags" "Web Dev" }}`, "/tags/web-dev.html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.TrimSpace(executeWithFuncs(t, config, tt.tmpl, data))
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTemplateFuncs_UserFuncs tests funcs defined in config.yaml
+func TestTemplateFuncs_UserFuncs(t *testing.T) {
+	config := SiteConfig{Funcs: map[string]string{
+		"greet":   "Hello, {{ . }}!",
+		"pair":    "{{ index . 0 }} & {{ index . 1 }}",
+		"slugged": "{{ slugify . }}",
+	}}
+
+	got := executeWithFuncs(t, config, `{{ greet "Ada" }} {{ pair "a" "b" }} {{ slugged "A B" }}`, nil)
+	if got != "Hello, Ada! a & b a-b" {
+		t.Errorf("got %q, want %q", got, "Hello, Ada! a & b a-b")
+	}
+}
+
+// TestTemplateFuncs_InvalidUserFunc tests that a malformed user func is an error
+func TestTemplateFuncs_InvalidUserFunc(t *testing.T) {
+	config := SiteConfig{Funcs: map[string]string{"broken": "{{ .Unclosed "}}
+	if _, err := templateFuncs(config, parser.New()); err == nil {
+		t.Error("templateFuncs() succeeded, want error")
+	}
+}
+
+// TestRegisterFunc tests adding funcs from Go code
+func TestRegisterFunc(t *testing.T) {
+	RegisterFunc("shout", strings.ToUpper)
+	defer func() {
+		registeredFuncsMu.Lock()
+		delete(registeredFuncs, "shout")
+		registeredFuncsMu.Unlock()
+	}()
+
+	got := executeWithFuncs(t, SiteConfig{}, `{{ shout "hi" }}`, nil)
+	if got != "HI" {
+		t.Errorf("got %q, want %q", got, "HI")
+	}
+}
@@ -0,0 +1,180 @@
+package ssg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// gitInfoCachePath is where the git history of the content files is cached
+// between builds, relative to the site root.
+var gitInfoCachePath = filepath.Join(".ssg", "gitinfo.json")
+
+// gitFileInfo is what the git history says about a content file.
+type gitFileInfo struct {
+	Lastmod      time.Time `json:"lastmod"`      // Author date of the newest commit changing the file
+	Contributors []string  `json:"contributors"` // Authors of the commits changing the file, first contribution first
+}
+
+// gitInfoCache is the history of the content files at one commit.
+type gitInfoCache struct {
+	Head  string                 `json:"head"`  // Commit the history was read at
+	Files map[string]gitFileInfo `json:"files"` // By slash-separated path from the site root
+}
+
+// assignGitInfo sets Lastmod and Contributors on each post from the git
+// history of its file, when the site in fsys is on disk and in a git
+// repository, unless gitInfo is false in the config. Lastmod is the author
+// date of the newest commit changing the file, if that's after the post's
+// Date; Contributors are the authors of those commits (as .mailmap names
+// them), in the order they first changed it. Uncommitted changes aren't
+// counted.
+//
+// The history of every file under content/ is read with one git log and
+// cached in .ssg/gitinfo.json until HEAD moves, so rebuilds don't read it
+// again. A site that isn't a git repository, or a machine without git, gets
+// no git info; a shallow clone, as CI services often make, gets a warning,
+// since its history is cut short.
+func assignGitInfo(fsys fs.FS, posts []*parser.Post, enabled *bool) {
+	if isFalse(enabled) || len(posts) == 0 {
+		return
+	}
+	dir, ok := diskPath(fsys, ".")
+	if !ok {
+		return
+	}
+	files, err := gitContentHistory(dir)
+	if err != nil {
+		slog.Warn("reading git history", "error", err)
+		return
+	}
+	for _, post := range posts {
+		info, ok := files[filepath.ToSlash(post.File)]
+		if !ok {
+			continue
+		}
+		if info.Lastmod.After(post.Lastmod) {
+			post.Lastmod = info.Lastmod
+		}
+		post.Contributors = info.Contributors
+	}
+}
+
+// gitContentHistory returns the history of the files under content/ in the
+// git repository dir is in, by slash-separated path from dir, from the cache
+// if HEAD hasn't moved. Returns nil if dir isn't in a repository with
+// commits, or git isn't installed.
+func gitContentHistory(dir string) (map[string]gitFileInfo, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--is-shallow-repository", "HEAD").Output()
+	if err != nil {
+		return nil, nil
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("unexpected git rev-parse output %q", out)
+	}
+	shallow, head := fields[0] == "true", fields[1]
+	if shallow {
+		slog.Warn("git history is shallow, so posts' Lastmod and Contributors only cover the fetched commits; fetch the full history to fix them")
+	}
+
+	cache := readGitInfoCache()
+	if cache.Head == head {
+		return cache.Files, nil
+	}
+	out, err = exec.Command("git", "-C", dir, "-c", "core.quotePath=false", "log", "--format=%x1e%aI%x1f%aN", "--name-only", "--relative", "--", "content").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	files, err := parseGitLog(out)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeGitInfoCache(gitInfoCache{Head: head, Files: files}); err != nil {
+		slog.Warn("caching git history", "error", err)
+	}
+	return files, nil
+}
+
+// parseGitLog reads the history of each file from the output of git log
+// with --name-only, newest commit first, and each commit's header formatted
+// as "%x1e%aI%x1f%aN": a record separator, then its author date and name.
+func parseGitLog(out []byte) (map[string]gitFileInfo, error) {
+	files := make(map[string]gitFileInfo)
+	authors := make(map[string][]string) // Path → authors of its commits, newest first
+	for _, commit := range bytes.Split(out, []byte{0x1e}) {
+		lines := strings.Split(strings.TrimSpace(string(commit)), "\n")
+		if lines[0] == "" {
+			continue
+		}
+		date, name, ok := strings.Cut(lines[0], "\x1f")
+		if !ok {
+			return nil, fmt.Errorf("unexpected git log line %q", lines[0])
+		}
+		when, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return nil, fmt.Errorf("git log: %w", err)
+		}
+		for _, file := range lines[1:] {
+			if file = strings.TrimSpace(file); file == "" {
+				continue
+			}
+			if _, ok := files[file]; !ok {
+				files[file] = gitFileInfo{Lastmod: when}
+			}
+			authors[file] = append(authors[file], name)
+		}
+	}
+	for file, names := range authors {
+		info := files[file]
+		for _, name := range slices.Backward(names) {
+			if !slices.Contains(info.Contributors, name) {
+				info.Contributors = append(info.Contributors, name)
+			}
+		}
+		files[file] = info
+	}
+	return files, nil
+}
+
+// readGitInfoCache loads the cached git history. A missing or corrupt cache
+// is treated as empty.
+func readGitInfoCache() gitInfoCache {
+	var cache gitInfoCache
+	data, err := os.ReadFile(gitInfoCachePath)
+	if err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// writeGitInfoCache saves the cached git history.
+func writeGitInfoCache(cache gitInfoCache) error {
+	if err := os.MkdirAll(filepath.Dir(gitInfoCachePath), 0750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(gitInfoCachePath, append(data, '\n'), 0600)
+}
+
+// lastmod returns when post last changed: its Lastmod, or its Date if that's
+// later (as it is for posts that didn't come from a content file).
+func lastmod(post *parser.Post) time.Time {
+	if post.Lastmod.After(post.Date) {
+		return post.Lastmod
+	}
+	return post.Date
+}
@@ -0,0 +1,107 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseGitLog tests reading each file's last change and contributors
+// from git log output
+func TestParseGitLog(t *testing.T) {
+	out := "\x1e2024-03-01T12:00:00Z\x1fBob\n\ncontent/posts/a.md\n" +
+		"\x1e2024-02-01T12:00:00+01:00\x1fAlice\n\ncontent/posts/a.md\ncontent/notes/b.md\n" +
+		"\x1e2024-01-01T12:00:00Z\x1fBob\n\ncontent/posts/a.md\n"
+
+	files, err := parseGitLog([]byte(out))
+	if err != nil {
+		t.Fatalf("parseGitLog() failed: %v", err)
+	}
+	want := map[string]gitFileInfo{
+		"content/posts/a.md": {Lastmod: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), Contributors: []string{"Bob", "Alice"}},
+		"content/notes/b.md": {Lastmod: time.Date(2024, 2, 1, 11, 0, 0, 0, time.UTC), Contributors: []string{"Alice"}},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("parseGitLog() = %v, want %v", files, want)
+	}
+	for file, w := range want {
+		got := files[file]
+		if !got.Lastmod.Equal(w.Lastmod) || !reflect.DeepEqual(got.Contributors, w.Contributors) {
+			t.Errorf("%s = %+v, want %+v", file, got, w)
+		}
+	}
+
+	if _, err := parseGitLog([]byte("\x1enot a header\n")); err == nil {
+		t.Error("parseGitLog() with a bad header succeeded, want error")
+	}
+}
+
+// TestBuild_GitInfo tests setting Lastmod and Contributors from git history,
+// in templates, the sitemap, and feeds
+func TestBuild_GitInfo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "sitemap:\n  enabled: true\nfeeds:\n  enabled: true\n"
+	site["templates/post.html"] = `{{define "posts"}}{{ .Post.Lastmod.Format "2006-01-02" }} by {{ range .Post.Contributors }}{{ . }};{{ end }}{{end}}`
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	git := func(env []string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(), env...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	commit := func(author, date string) {
+		t.Helper()
+		git(nil, "add", "content")
+		git([]string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date},
+			"-c", "user.name=Test", "-c", "user.email=test@example.com",
+			"commit", "-q", "--allow-empty", "-m", "Edit", "--author", author+" <"+strings.ToLower(author)+"@example.com>")
+	}
+	git(nil, "init", "-q")
+	commit("Alice", "2024-01-15T10:00:00Z")
+	writeFiles(t, tmpDir, map[string]string{
+		"content/posts/2024-01-15-first.md": "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\nEdited.\n",
+	})
+	commit("Bob", "2024-03-01T12:00:00Z")
+	commit("Alice", "2024-03-02T12:00:00Z") // Changes nothing under content/
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	for file, want := range map[string]string{
+		filepath.Join("public", "posts", "first.html"): "2024-03-01 by Alice;Bob;",
+		filepath.Join("public", "sitemap.xml"):         "<lastmod>2024-03-01</lastmod>",
+		filepath.Join("public", "feed.xml"):            "<atom:updated>2024-03-01T12:00:00Z</atom:updated>\n      <dc:contributor>Alice</dc:contributor>\n      <dc:contributor>Bob</dc:contributor>",
+		gitInfoCachePath:                               `"contributors": [`,
+	} {
+		got, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(got), want) {
+			t.Errorf("%s doesn't contain %q:\n%s", file, want, got)
+		}
+	}
+
+	writeFiles(t, tmpDir, map[string]string{"config.yaml": site["config.yaml"] + "gitInfo: false\n"})
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	page, err := os.ReadFile(filepath.Join("public", "posts", "first.html"))
+	if err != nil || !strings.Contains(string(page), "2024-01-15 by ") || strings.Contains(string(page), "Alice") {
+		t.Errorf("with gitInfo: false, page = %q, %v; want its Date and no contributors", page, err)
+	}
+}
@@ -0,0 +1,262 @@
+package ssg
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GodocConfig configures generation of Go package reference pages.
+type GodocConfig struct {
+	// Packages lists package directories to document, relative to the site
+	// root (e.g., "./internal/parser").
+	Packages []string `yaml:"packages"`
+}
+
+// PackageDoc holds the documentation of a single Go package for templates.
+type PackageDoc struct {
+	Name       string        // Package name (e.g., "parser")
+	ImportPath string        // Full import path (e.g., "github.com/you/mod/internal/parser")
+	Slug       string        // Output path relative to /pkg/ (e.g., "internal/parser")
+	Synopsis   string        // First sentence of the package comment
+	Doc        template.HTML // Package comment rendered as HTML
+	Consts     []DeclDoc
+	Vars       []DeclDoc
+	Funcs      []DeclDoc
+	Types      []TypeDoc
+}
+
+// DeclDoc is a documented declaration: a const/var group or a function.
+type DeclDoc struct {
+	Name string        // Identifier (empty for const/var groups)
+	Decl string        // Declaration source, formatted with gofmt
+	Doc  template.HTML // Doc comment rendered as HTML
+}
+
+// TypeDoc is a documented type with its associated declarations.
+type TypeDoc struct {
+	DeclDoc
+	Consts  []DeclDoc
+	Vars    []DeclDoc
+	Funcs   []DeclDoc // Constructors returning the type
+	Methods []DeclDoc
+}
+
+// loadPackageDocs loads documentation for each configured package directory.
+//
+// The import path of each package is derived from the module path in the
+// go.mod file at the site root. If there is no go.mod, the directory path is
+// used as the import path.
+//
+// Parameters:
+//   - fsys: Filesystem the site is read from
+//   - dirs: Package directories relative to the site root (from GodocConfig.Packages)
+//
+// Returns the package docs in configuration order, or an error if a directory
+// is outside the site root or any package fails to parse.
+func loadPackageDocs(fsys fs.FS, dirs []string) ([]*PackageDoc, error) {
+	modulePath := readModulePath(fsys, "go.mod")
+
+	var pkgs []*PackageDoc
+	for _, dir := range dirs {
+		slug, err := packageSlug(dir)
+		if err != nil {
+			return nil, err
+		}
+		importPath := slug
+		if modulePath != "" {
+			importPath = path.Join(modulePath, slug)
+		}
+
+		pkg, err := loadPackageDoc(fsys, dir, importPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading package %s: %w", dir, err)
+		}
+		pkg.Slug = slug
+		pkgs = append(pkgs, pkg)
+	}
+
+	return pkgs, nil
+}
+
+// packageSlug returns the path of the package directory dir under /pkg/,
+// or an error if dir is absolute or leaves the site root, since its page
+// would be written outside /pkg/.
+func packageSlug(dir string) (string, error) {
+	if !filepath.IsLocal(dir) {
+		return "", fmt.Errorf("godoc package %q is outside the site root", dir)
+	}
+	return filepath.ToSlash(filepath.Clean(dir)), nil
+}
+
+// loadPackageDoc parses the non-test Go files in dir that the build
+// constraints of the current platform select (as go build would), and
+// extracts their documentation with go/doc.
+//
+// Only exported identifiers are included. Doc comments are rendered to HTML
+// using the standard Go doc comment syntax (headings, lists, links).
+//
+// Parameters:
+//   - fsys: Filesystem the site is read from
+//   - dir: Directory containing the package's Go files
+//   - importPath: Import path shown on the page and used to resolve doc links
+//
+// Returns the package documentation or an error if the directory can't be
+// read, contains no Go files, or fails to parse.
+func loadPackageDoc(fsys fs.FS, dir, importPath string) (*PackageDoc, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	// Build constraints are read through fsys too
+	bctx := build.Default
+	bctx.OpenFile = func(name string) (io.ReadCloser, error) { return fsys.Open(name) }
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		// Skips helpers like //go:build ignore programs in package main
+		if ok, err := bctx.MatchFile(dir, name); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+		src, err := fs.ReadFile(fsys, filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), src, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	p, err := doc.NewFromFiles(fset, files, importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &docRenderer{pkg: p, fset: fset}
+	pd := &PackageDoc{
+		Name:       p.Name,
+		ImportPath: importPath,
+		Synopsis:   p.Synopsis(p.Doc),
+		Doc:        d.html(p.Doc),
+		Consts:     d.values(p.Consts),
+		Vars:       d.values(p.Vars),
+		Funcs:      d.funcs(p.Funcs),
+	}
+	for _, t := range p.Types {
+		pd.Types = append(pd.Types, TypeDoc{
+			DeclDoc: DeclDoc{Name: t.Name, Decl: d.source(t.Decl), Doc: d.html(t.Doc)},
+			Consts:  d.values(t.Consts),
+			Vars:    d.values(t.Vars),
+			Funcs:   d.funcs(t.Funcs),
+			Methods: d.funcs(t.Methods),
+		})
+	}
+
+	return pd, nil
+}
+
+// docRenderer converts go/doc values into template-ready DeclDocs.
+type docRenderer struct {
+	pkg  *doc.Package
+	fset *token.FileSet
+}
+
+// html renders a doc comment as HTML.
+func (d *docRenderer) html(text string) template.HTML {
+	// #nosec G203 -- HTML generated by go/doc from source comments
+	return template.HTML(d.pkg.HTML(text))
+}
+
+// source formats a declaration node with gofmt. Function bodies are never
+// present because go/doc strips them.
+func (d *docRenderer) source(node any) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, d.fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+func (d *docRenderer) values(values []*doc.Value) []DeclDoc {
+	var out []DeclDoc
+	for _, v := range values {
+		out = append(out, DeclDoc{Decl: d.source(v.Decl), Doc: d.html(v.Doc)})
+	}
+	return out
+}
+
+func (d *docRenderer) funcs(funcs []*doc.Func) []DeclDoc {
+	var out []DeclDoc
+	for _, f := range funcs {
+		out = append(out, DeclDoc{Name: f.Name, Decl: d.source(f.Decl), Doc: d.html(f.Doc)})
+	}
+	return out
+}
+
+// readModulePath returns the module path declared in the go.mod file at
+// goModPath in fsys, or an empty string if the file doesn't exist or has no
+// module directive.
+func readModulePath(fsys fs.FS, goModPath string) string {
+	f, err := fsys.Open(goModPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// renderPackage renders a Go package reference page to an HTML file.
+//
+// Called by Build for each package configured under godoc.packages. Uses
+// "package.html", whose {{define "posts"}} block receives the PackageDoc as
+// .Package.
+//
+// Parameters:
+//   - pkg: Package documentation from loadPackageDocs
+//   - config: Site configuration (title, author, etc.) for template rendering
+//   - outputPath: Where to write the HTML file (e.g., "public/pkg/internal/parser.html")
+//
+// Returns an error if rendering or file writing fails.
+func (r *Renderer) renderPackage(pkg *PackageDoc, config SiteConfig, outputPath string) error {
+	data := PageData{
+		Site:    config,
+		Package: pkg,
+		Title:   "package " + pkg.Name,
+		Kind:    KindPackage,
+		URL:     pageURL(config.URLs, "/pkg/"+pkg.Slug),
+	}
+
+	return r.renderToFile("package.html", data, outputPath)
+}
@@ -0,0 +1,144 @@
+package ssg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadPackageDoc tests extracting documentation from a package directory
+func TestLoadPackageDoc(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	src := `// Package greet says hello.
+//
+// It is used to test documentation extraction.
+package greet
+
+// Greeting is the default greeting.
+const Greeting = "hello"
+
+// Greeter greets people.
+type Greeter struct {
+	Name string
+}
+
+// NewGreeter creates a Greeter.
+func NewGreeter(name string) *Greeter {
+	return &Greeter{Name: name}
+}
+
+// Greet returns a greeting for the Greeter's name.
+func (g *Greeter) Greet() string {
+	return Greeting + " " + g.Name
+}
+
+// Shout greets loudly.
+func Shout(s string) string { return s + "!" }
+
+func unexported() {}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "greet.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	testSrc := "package greet\n\nfunc TestOnly() {}\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "greet_test.go"), []byte(testSrc), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := loadPackageDoc(DirFS("."), tmpDir, "example.com/greet")
+	if err != nil {
+		t.Fatalf("loadPackageDoc() failed: %v", err)
+	}
+
+	if pkg.Name != "greet" {
+		t.Errorf("Name = %q, want %q", pkg.Name, "greet")
+	}
+	if pkg.ImportPath != "example.com/greet" {
+		t.Errorf("ImportPath = %q, want %q", pkg.ImportPath, "example.com/greet")
+	}
+	if pkg.Synopsis != "Package greet says hello." {
+		t.Errorf("Synopsis = %q, want %q", pkg.Synopsis, "Package greet says hello.")
+	}
+	if !strings.Contains(string(pkg.Doc), "<p>It is used to test documentation extraction.") {
+		t.Errorf("Doc doesn't contain rendered paragraph. Got: %s", pkg.Doc)
+	}
+
+	if len(pkg.Consts) != 1 || !strings.Contains(pkg.Consts[0].Decl, `Greeting = "hello"`) {
+		t.Errorf("Consts = %+v, want Greeting", pkg.Consts)
+	}
+
+	if len(pkg.Funcs) != 1 || pkg.Funcs[0].Name != "Shout" {
+		t.Fatalf("Funcs = %+v, want only Shout", pkg.Funcs)
+	}
+	if strings.Contains(pkg.Funcs[0].Decl, "return") {
+		t.Errorf("Func decl contains body: %q", pkg.Funcs[0].Decl)
+	}
+
+	if len(pkg.Types) != 1 {
+		t.Fatalf("len(Types) = %d, want 1", len(pkg.Types))
+	}
+	typ := pkg.Types[0]
+	if typ.Name != "Greeter" {
+		t.Errorf("Type name = %q, want %q", typ.Name, "Greeter")
+	}
+	if len(typ.Funcs) != 1 || typ.Funcs[0].Name != "NewGreeter" {
+		t.Errorf("Type funcs = %+v, want NewGreeter", typ.Funcs)
+	}
+	if len(typ.Methods) != 1 || typ.Methods[0].Name != "Greet" {
+		t.Errorf("Type methods = %+v, want Greet", typ.Methods)
+	}
+}
+
+// TestLoadPackageDoc_NoGoFiles tests documenting a directory without Go files
+func TestLoadPackageDoc_NoGoFiles(t *testing.T) {
+	_, err := loadPackageDoc(DirFS("."), t.TempDir(), "example.com/empty")
+	if err == nil {
+		t.Error("loadPackageDoc() succeeded, want error")
+	}
+}
+
+// TestLoadPackageDoc_BuildConstraints tests skipping files the build
+// constraints exclude, like a //go:build ignore program in package main
+func TestLoadPackageDoc_BuildConstraints(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"greet.go": "// Package greet says hello.\npackage greet\n\n// Hello says hello.\nfunc Hello() {}\n",
+		"gen.go":   "//go:build ignore\n\npackage main\n\nfunc main() {}\n",
+	})
+
+	pkg, err := loadPackageDoc(DirFS("."), tmpDir, "example.com/greet")
+	if err != nil {
+		t.Fatalf("loadPackageDoc() failed: %v", err)
+	}
+	if pkg.Name != "greet" || len(pkg.Funcs) != 1 {
+		t.Errorf("package = %s with %d funcs, want greet with Hello", pkg.Name, len(pkg.Funcs))
+	}
+}
+
+// TestLoadPackageDocs_OutsideRoot tests rejecting package directories
+// outside the site root
+func TestLoadPackageDocs_OutsideRoot(t *testing.T) {
+	for _, dir := range []string{"../other", "internal/../../other", "/usr/lib/go"} {
+		if _, err := loadPackageDocs(DirFS("."), []string{dir}); err == nil || !strings.Contains(err.Error(), "outside the site root") {
+			t.Errorf("loadPackageDocs(%q) error = %v, want outside the site root", dir, err)
+		}
+	}
+}
+
+// TestReadModulePath tests reading the module directive from go.mod
+func TestReadModulePath(t *testing.T) {
+	tmpDir := t.TempDir()
+	goMod := filepath.Join(tmpDir, "go.mod")
+	if err := os.WriteFile(goMod, []byte("module example.com/site\n\ngo 1.25\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readModulePath(DirFS("."), goMod); got != "example.com/site" {
+		t.Errorf("readModulePath() = %q, want %q", got, "example.com/site")
+	}
+	if got := readModulePath(DirFS("."), filepath.Join(tmpDir, "missing.mod")); got != "" {
+		t.Errorf("readModulePath() for missing file = %q, want empty", got)
+	}
+}
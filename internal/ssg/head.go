@@ -0,0 +1,235 @@
+package ssg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// Page kinds, set as PageData.Kind.
+const (
+	KindIndex     = "index"
+	KindSection   = "section"
+	KindPost      = "post"
+	KindPage      = "page"
+	KindPackage   = "package"
+	KindShortlink = "shortlink"
+	KindSeries    = "series"
+	KindTaxonomy  = "taxonomy"
+	KindTerm      = "term"
+	KindNotFound  = "404"
+)
+
+// iconFiles are the icon files linked from the head when present in static/,
+// with their rel and MIME type.
+var iconFiles = []struct{ name, rel, mimeType string }{
+	{"favicon.ico", "icon", "image/x-icon"},
+	{"favicon.svg", "icon", "image/svg+xml"},
+	{"favicon.png", "icon", "image/png"},
+	{"apple-touch-icon.png", "apple-touch-icon", "image/png"},
+}
+
+// headTemplate renders the metadata exposed to templates as .Head.
+var headTemplate = template.Must(template.New("head").Parse(`
+{{- with .Description }}<meta name="description" content="{{ . }}" />{{ end }}
+{{ with .Keywords }}<meta name="keywords" content="{{ . }}" />{{ end }}
+{{ with .Author }}<meta name="author" content="{{ . }}" />{{ end }}
+{{ with .Canonical }}<link rel="canonical" href="{{ . }}" />{{ end }}
+{{ range .Alternates }}<link rel="alternate" hreflang="{{ .Lang }}" href="{{ .Href }}" />
+{{ end -}}
+{{ range .Formats }}<link rel="alternate" type="{{ .Type }}" href="{{ .Href }}" />
+{{ end -}}
+{{ if .NoIndex }}<meta name="robots" content="noindex" />{{ end }}
+{{ with .Refresh }}<meta http-equiv="refresh" content="{{ . }}" />{{ end }}
+<meta property="og:title" content="{{ .Title }}" />
+<meta property="og:type" content="{{ .OGType }}" />
+{{ with .Canonical }}<meta property="og:url" content="{{ . }}" />{{ end }}
+{{ with .Description }}<meta property="og:description" content="{{ . }}" />{{ end }}
+{{ with .SiteName }}<meta property="og:site_name" content="{{ . }}" />{{ end }}
+{{ with .Image }}<meta property="og:image" content="{{ . }}" />
+<meta property="og:image:width" content="{{ $.ImageWidth }}" />
+<meta property="og:image:height" content="{{ $.ImageHeight }}" />
+<meta name="twitter:card" content="summary_large_image" />
+<meta name="twitter:image" content="{{ . }}" />{{ end }}
+{{ with .Published }}<meta property="article:published_time" content="{{ . }}" />{{ end }}{{ with .Modified }}
+<meta property="article:modified_time" content="{{ . }}" />{{ end }}
+{{ range .Tags }}<meta property="article:tag" content="{{ . }}" />
+{{ end }}
+{{- with .Stylesheet }}<link rel="stylesheet" href="{{ . }}" />
+{{ end -}}
+{{- template "icons" . -}}
+<script type="application/ld+json">{{ .JSONLD }}</script>
+{{- define "icons" }}{{ range .Icons }}<link rel="{{ .Rel }}"{{ with .Type }} type="{{ . }}"{{ end }}{{ with .Sizes }} sizes="{{ . }}"{{ end }} href="{{ .Href }}" />
+{{ end }}{{ with .ThemeColor }}<meta name="theme-color" content="{{ . }}" />
+{{ end }}{{ end }}`))
+
+// headData is the input to headTemplate.
+type headData struct {
+	Title, Description, Keywords, Author string
+	Canonical, OGType, SiteName          string
+	Published, Modified                  string
+	Image                                string // Absolute URL of the page's Open Graph image ("" if none)
+	ImageWidth, ImageHeight              int
+	Refresh                              string // Content of a refresh meta tag, e.g. "0; url=https://example.com/"
+	NoIndex                              bool
+	Tags                                 []string
+	Icons                                []headIcon
+	ThemeColor                           string          // Content of a theme-color meta tag ("" for none)
+	Stylesheet                           string          // Stylesheet of the site's theme colors ("" if none)
+	Alternates                           []headAlternate // The page in each of the site's languages, including its own
+	Formats                              []headFormat    // The page in its other output formats
+	JSONLD                               template.JS
+}
+
+// headAlternate is an hreflang alternate <link> in the head.
+type headAlternate struct {
+	Lang, Href string
+}
+
+// headFormat is an alternate <link> in the head to the page in another
+// output format.
+type headFormat struct {
+	Type, Href string
+}
+
+// headIcon is an icon <link> in the head, or the link to the web app
+// manifest.
+type headIcon struct {
+	Rel, Type, Href string
+	Sizes           string // e.g. "32x32" ("" if unknown)
+}
+
+// findIcons returns the icons in iconFiles that exist in staticDir in fsys.
+func findIcons(fsys fs.FS, staticDir string) []headIcon {
+	var icons []headIcon
+	for _, f := range iconFiles {
+		if _, err := fs.Stat(fsys, path.Join(staticDir, f.name)); err == nil {
+			icons = append(icons, headIcon{Rel: f.rel, Type: f.mimeType, Href: "/" + f.name})
+		}
+	}
+	return icons
+}
+
+// iconLinks returns the icon links of the head on their own, for templates
+// that build their <head> without .Head: the site's icons and web app
+// manifest (see FaviconsConfig), and its theme color.
+func (r *Renderer) iconLinks(site SiteConfig) template.HTML {
+	var buf bytes.Buffer
+	if err := headTemplate.ExecuteTemplate(&buf, "icons", headData{Icons: r.icons, ThemeColor: site.Favicons.ThemeColor}); err != nil {
+		return ""
+	}
+	// #nosec G203 -- output of an html/template, escaped on execution
+	return template.HTML(buf.String())
+}
+
+// head builds the <head> metadata for a page: description, keywords, and
+// author meta tags, a canonical link, hreflang links to the page's
+// translations on a multilingual site, links to the post in its other
+// output formats and to the site's feeds (see pageFeeds), Open Graph tags
+// (with the post's image, see OGImageConfig), the stylesheet of the theme's
+// colors (see ThemeColors), icon links, and JSON-LD
+// structured data (BlogPosting for posts, WebSite otherwise).
+//
+// Canonical and Open Graph URLs are absolute, built from baseUrl and the
+// page's URL. Templates output it with {{ .Head }} inside <head>.
+func (r *Renderer) head(data PageData) template.HTML {
+	site := data.Site
+	h := headData{
+		Title:       data.Title,
+		Description: site.Description,
+		Keywords:    site.Keywords,
+		Author:      site.Author,
+		OGType:      "website",
+		SiteName:    site.Title,
+		Icons:       r.icons,
+		ThemeColor:  site.Favicons.ThemeColor,
+	}
+	if site.Theme.Colors.enabled() {
+		h.Stylesheet = colorsStylesheet
+	}
+	if site.BaseURL != "" && data.URL != "" {
+		h.Canonical = absURL(site.BaseURL, data.URL)
+		if len(data.Translations) > 0 {
+			h.Alternates = append(h.Alternates, headAlternate{Lang: site.Language, Href: h.Canonical})
+			for _, t := range data.Translations {
+				h.Alternates = append(h.Alternates, headAlternate{Lang: t.Lang, Href: absURL(site.BaseURL, t.URL)})
+			}
+		}
+	}
+	for _, out := range data.Outputs {
+		h.Formats = append(h.Formats, headFormat{Type: out.MediaType, Href: out.URL})
+	}
+	for _, feed := range pageFeeds(data) {
+		h.Formats = append(h.Formats, headFormat{Type: "application/rss+xml", Href: feed})
+	}
+
+	ld := map[string]any{
+		"@context": "https://schema.org",
+		"@type":    "WebSite",
+		"name":     site.Title,
+	}
+	if data.Post != nil {
+		h.Description = data.Post.Description
+		h.Keywords = data.Post.Keywords
+	}
+	if data.Kind == KindPost {
+		post := data.Post
+		h.OGType = "article"
+		h.Published = post.Date.Format(time.RFC3339)
+		h.Tags = post.Tags
+		ld = map[string]any{
+			"@context":      "https://schema.org",
+			"@type":         "BlogPosting",
+			"headline":      post.Title,
+			"datePublished": h.Published,
+		}
+		if post.Lastmod.After(post.Date) {
+			h.Modified = post.Lastmod.Format(time.RFC3339)
+			ld["dateModified"] = h.Modified
+		}
+		if site.Author != "" {
+			ld["author"] = map[string]string{"@type": "Person", "name": site.Author}
+		}
+		if len(post.Tags) > 0 {
+			ld["keywords"] = post.Keywords
+		}
+		if post.OGImage != "" && site.BaseURL != "" {
+			h.Image = absURL(site.BaseURL, post.OGImage)
+			h.ImageWidth, h.ImageHeight = ogImageWidth, ogImageHeight
+			ld["image"] = h.Image
+		}
+	}
+	if link := data.Shortlink; data.Kind == KindShortlink && link != nil {
+		h.Refresh = fmt.Sprintf("%d; url=%s", link.Delay, link.URL)
+		h.NoIndex = true
+	}
+	if data.Kind == KindNotFound {
+		// Served at any missing URL, so it has no URL of its own
+		h.Canonical, h.Alternates = "", nil
+		h.NoIndex = true
+	}
+	if h.Description != "" {
+		ld["description"] = h.Description
+	}
+	if h.Canonical != "" {
+		ld["url"] = h.Canonical
+	}
+
+	// json.Marshal escapes <, >, and &, so the result can't close the script.
+	jsonLD, err := json.Marshal(ld)
+	if err == nil {
+		// #nosec G203 -- JSON produced by encoding/json with HTML escaping
+		h.JSONLD = template.JS(jsonLD)
+	}
+
+	var buf bytes.Buffer
+	if err := headTemplate.Execute(&buf, h); err != nil {
+		return ""
+	}
+	// #nosec G203 -- output of an html/template, escaped on execution
+	return template.HTML(buf.String())
+}
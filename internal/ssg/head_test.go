@@ -0,0 +1,82 @@
+package ssg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestHead_Post tests the metadata generated for a post page
+func TestHead_Post(t *testing.T) {
+	r := &Renderer{icons: []headIcon{{Rel: "icon", Type: "image/svg+xml", Href: "/favicon.svg"}}}
+	data := PageData{
+		Site: SiteConfig{Title: "My Blog", BaseURL: "https://example.com/", Author: "Ann"},
+		Post: &parser.Post{
+			Title:       "Hello <World>",
+			Description: "A first post",
+			Date:        time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+			Tags:        []string{"go"},
+			Keywords:    "go",
+		},
+		Title: "Hello <World>",
+		Kind:  KindPost,
+		URL:   "/posts/hello.html",
+	}
+
+	got := string(r.head(data))
+	for _, want := range []string{
+		`<meta name="description" content="A first post" />`,
+		`<meta name="author" content="Ann" />`,
+		`<link rel="canonical" href="https://example.com/posts/hello.html" />`,
+		`<meta property="og:title" content="Hello &lt;World&gt;" />`,
+		`<meta property="og:type" content="article" />`,
+		`<meta property="article:published_time" content="2024-01-15T10:00:00Z" />`,
+		`<meta property="article:tag" content="go" />`,
+		`href="/favicon.svg" />`,
+		`"@type":"BlogPosting"`,
+		`"headline":"Hello \u003cWorld\u003e"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("head missing %s, got:\n%s", want, got)
+		}
+	}
+}
+
+// TestHead_Index tests the metadata generated for the home page
+func TestHead_Index(t *testing.T) {
+	r := &Renderer{}
+	data := PageData{
+		Site:  SiteConfig{Title: "My Blog", Description: "Notes", Keywords: "go, web"},
+		Title: "My Blog",
+		Kind:  KindIndex,
+		URL:   "/",
+	}
+
+	got := string(r.head(data))
+	for _, want := range []string{
+		`<meta name="description" content="Notes" />`,
+		`<meta name="keywords" content="go, web" />`,
+		`<meta property="og:type" content="website" />`,
+		`"@type":"WebSite"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("head missing %s, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "canonical") {
+		t.Errorf("head has a canonical link without baseUrl:\n%s", got)
+	}
+}
+
+// TestFindIcons tests that only icons present in static/ are linked
+func TestFindIcons(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{"favicon.ico": "ico", "apple-touch-icon.png": "png"})
+
+	icons := findIcons(DirFS("."), tmpDir)
+	if len(icons) != 2 || icons[0].Href != "/favicon.ico" || icons[1].Rel != "apple-touch-icon" {
+		t.Errorf("findIcons() = %v, want favicon.ico and apple-touch-icon.png", icons)
+	}
+}
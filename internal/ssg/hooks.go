@@ -0,0 +1,99 @@
+package ssg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HooksConfig lists shell commands run around builds and deploys, to chain
+// in tools the generator doesn't run itself (Tailwind, esbuild, Pagefind) or
+// scripts that announce a release.
+//
+// Example config.yaml:
+//
+//	hooks:
+//	  preBuild: npx tailwindcss -i assets/site.css -o static/css/site.css --minify
+//	  postBuild:
+//	    - npx pagefind --site "$SSG_OUTPUT_DIR"
+//	  postPublish: ./scripts/announce.sh
+//
+// Commands run in order with the platform's shell, in the site's root
+// directory, with the build described in environment variables:
+//
+//   - SSG_HOOK: the hook running ("preBuild", "postBuild", or "postPublish")
+//   - SSG_ENV: the build environment (e.g., "production")
+//   - SSG_CONFIG: the config file
+//   - SSG_OUTPUT_DIR: the directory the site is written to
+//   - SSG_BASE_URL: the site's baseUrl
+//   - SSG_BUILD_POSTS, SSG_BUILD_DURATION: the number of published posts and
+//     how long the build took (postBuild only)
+//   - SSG_DEPLOY_TARGET, SSG_DEPLOY_TYPE: the target deployed to and its type
+//     (postPublish only)
+//
+// A command that fails stops the build (or, after a deploy, is reported as
+// an error once the site is published).
+type HooksConfig struct {
+	PreBuild    Commands `yaml:"preBuild"`    // Before the content is read, e.g. to generate CSS into static/ or files into data/
+	PostBuild   Commands `yaml:"postBuild"`   // Once the site is written, e.g. to index it for search
+	PostPublish Commands `yaml:"postPublish"` // After `ssg deploy` publishes the site (not with --dry-run)
+}
+
+// Commands is a list of shell commands. In YAML it's a list, or a string for
+// a single command.
+type Commands []string
+
+// UnmarshalYAML accepts a single command as a plain string as well as a
+// list.
+func (c *Commands) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*c = Commands{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*c = list
+	return nil
+}
+
+// hookEnv returns the environment variables describing a build to its hooks,
+// besides SSG_HOOK.
+func hookEnv(opts BuildOptions, config *SiteConfig) []string {
+	return []string{
+		"SSG_ENV=" + opts.Environment,
+		"SSG_CONFIG=" + opts.ConfigPath,
+		"SSG_OUTPUT_DIR=" + opts.OutputDir,
+		"SSG_BASE_URL=" + config.BaseURL,
+	}
+}
+
+// postBuildEnv returns hookEnv's variables plus the results of a build that
+// published posts posts in duration.
+func postBuildEnv(opts BuildOptions, config *SiteConfig, posts int, duration time.Duration) []string {
+	return append(hookEnv(opts, config),
+		"SSG_BUILD_POSTS="+strconv.Itoa(posts),
+		"SSG_BUILD_DURATION="+duration.Round(time.Millisecond).String(),
+	)
+}
+
+// runHooks runs the commands of the hook name in order, with env added to
+// the environment, and stops at the first that fails. Its output goes to
+// the terminal.
+func runHooks(ctx context.Context, name string, commands Commands, env []string) error {
+	for _, command := range commands {
+		cmd := shellCommand(ctx, command)
+		cmd.Env = append(append(os.Environ(), "SSG_HOOK="+name), env...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %q: %w", name, command, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestCommands_UnmarshalYAML tests that hooks accept a string or a list
+func TestCommands_UnmarshalYAML(t *testing.T) {
+	var cfg HooksConfig
+	if err := yaml.Unmarshal([]byte("preBuild: make css\npostBuild: [a, b]\n"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.PreBuild, Commands{"make css"}) || !slices.Equal(cfg.PostBuild, Commands{"a", "b"}) {
+		t.Errorf("hooks = %q, %q", cfg.PreBuild, cfg.PostBuild)
+	}
+}
+
+// TestBuild_Hooks tests that preBuild and postBuild hooks run around the build
+func TestBuild_Hooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in the test use sh syntax")
+	}
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "hooks:\n" +
+		"  preBuild: 'mkdir -p data && echo \"hook: $SSG_HOOK $SSG_ENV\" > data/generated.yaml'\n" +
+		"  postBuild:\n" +
+		"    - echo \"$SSG_BUILD_POSTS posts for $SSG_BASE_URL\" > \"$SSG_OUTPUT_DIR/built.txt\"\n"
+	site["templates/posts.html"] = `{{define "posts"}}{{ .Site.Data.generated.hook }}{{end}}`
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join("public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "preBuild production") {
+		t.Errorf("index.html = %s, want the data file written by the preBuild hook", index)
+	}
+	built, err := os.ReadFile(filepath.Join("public", "built.txt"))
+	if err != nil {
+		t.Fatalf("postBuild hook didn't run: %v", err)
+	}
+	if got := strings.TrimSpace(string(built)); got != "1 posts for https://test.com" {
+		t.Errorf("built.txt = %q", got)
+	}
+}
+
+// TestBuild_HookFails tests that a failing hook fails the build
+func TestBuild_HookFails(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "hooks:\n  preBuild: exit 3\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	err := Build(context.Background(), BuildOptions{})
+	if err == nil || !strings.Contains(err.Error(), `preBuild hook "exit 3"`) {
+		t.Errorf("Build() error = %v, want error naming the hook", err)
+	}
+	if _, err := os.Stat("public"); err == nil {
+		t.Error("site was built after the preBuild hook failed")
+	}
+}
+
+// fakeDeployer publishes nowhere
+type fakeDeployer struct{}
+
+func (fakeDeployer) check(DeployTarget) error { return nil }
+
+func (fakeDeployer) deploy(context.Context, DeployTarget, string, []string, bool) error { return nil }
+
+// TestDeploy_PostPublish tests that postPublish hooks run after a deploy, but not a dry run
+func TestDeploy_PostPublish(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in the test use sh syntax")
+	}
+	deployers["fake"] = fakeDeployer{}
+	defer delete(deployers, "fake")
+
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "deploy:\n  prod:\n    type: fake\nhooks:\n  postPublish: echo \"$SSG_DEPLOY_TARGET $SSG_DEPLOY_TYPE\" >> published.txt\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Deploy(context.Background(), DeployOptions{DryRun: true}); err != nil {
+		t.Fatalf("Deploy() dry run failed: %v", err)
+	}
+	if _, err := os.Stat("published.txt"); err == nil {
+		t.Error("postPublish hook ran on a dry run")
+	}
+
+	if err := Deploy(context.Background(), DeployOptions{}); err != nil {
+		t.Fatalf("Deploy() failed: %v", err)
+	}
+	published, err := os.ReadFile("published.txt")
+	if err != nil {
+		t.Fatalf("postPublish hook didn't run: %v", err)
+	}
+	if got := strings.TrimSpace(string(published)); got != "prod fake" {
+		t.Errorf("published.txt = %q, want %q", got, "prod fake")
+	}
+}
@@ -0,0 +1,167 @@
+package ssg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// Hosts deployTarget tailors the build output for.
+const (
+	HostNetlify    = "netlify"    // Netlify: _redirects
+	HostVercel     = "vercel"     // Vercel: vercel.json with redirects and clean URL settings
+	HostCloudflare = "cloudflare" // Cloudflare Pages: _redirects and a 404.html
+)
+
+// cloudflareRedirectLimit is the number of static redirects Cloudflare Pages
+// reads from _redirects; the rest are ignored.
+const cloudflareRedirectLimit = 2000
+
+// vercelConfigFile is the name of the file Vercel reads a deployment's
+// settings from.
+const vercelConfigFile = "vercel.json"
+
+// checkDeployTarget returns an error if target isn't one of the hosts.
+func checkDeployTarget(target string) error {
+	switch target {
+	case "", HostNetlify, HostVercel, HostCloudflare:
+		return nil
+	}
+	return fmt.Errorf("deployTarget %q must be %q, %q, or %q", target, HostNetlify, HostVercel, HostCloudflare)
+}
+
+// writeHostFiles writes the files the host named by config.DeployTarget
+// needs to serve the site the way it was built, to outputDir in out:
+//   - netlify: redirects as _redirects rules (see redirectFiles). Netlify
+//     serves 404.html for missing paths and resolves every URL style.
+//   - vercel: vercel.json, with redirects as permanent redirects, cleanUrls
+//     for extensionless URLs, and trailingSlash for slash URLs. A vercel.json
+//     copied from static/ keeps its settings and redirects, with the site's
+//     added after them. Vercel serves 404.html for missing paths.
+//   - cloudflare: redirects as _redirects rules, and a plain 404.html if the
+//     site has none, since Cloudflare Pages serves the home page for every
+//     missing path of a site without one.
+//
+// Called by Build after writeServerRedirects, so the rules of a host that is
+// also listed under redirectFiles are written once. Returns an error for an
+// unknown host, or too many redirects for the host to read.
+func writeHostFiles(out FS, outputDir string, config SiteConfig, redirects map[string]string) error {
+	if err := checkDeployTarget(config.DeployTarget); err != nil {
+		return err
+	}
+	switch config.DeployTarget {
+	case HostNetlify, HostCloudflare:
+		if config.DeployTarget == HostCloudflare && len(redirects) > cloudflareRedirectLimit {
+			return fmt.Errorf("%d redirects, but Cloudflare Pages reads at most %d", len(redirects), cloudflareRedirectLimit)
+		}
+		if !slices.Contains(config.RedirectFiles, config.DeployTarget) {
+			if err := writeServerRedirects(out, outputDir, redirects, []string{config.DeployTarget}); err != nil {
+				return err
+			}
+		}
+		if config.DeployTarget == HostCloudflare {
+			return writeFallbackNotFound(out, outputDir, config)
+		}
+	case HostVercel:
+		return writeVercelConfig(out, outputDir, config.URLs, redirects)
+	}
+	return nil
+}
+
+// vercelRedirect is a redirect in vercel.json.
+type vercelRedirect struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Permanent   bool   `json:"permanent"`
+}
+
+// writeVercelConfig writes vercel.json to outputDir in out with redirects,
+// and the clean URL settings of the URL style (see writeHostFiles), merged
+// into one copied from static/.
+func writeVercelConfig(out FS, outputDir, style string, redirects map[string]string) error {
+	file := filepath.Join(outputDir, vercelConfigFile)
+	settings := make(map[string]any)
+	existing, err := out.ReadFile(file)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(existing, &settings); err != nil {
+			return fmt.Errorf("reading %s from static/: %w", vercelConfigFile, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	setDefault := func(key string, value any) {
+		if _, ok := settings[key]; !ok {
+			settings[key] = value
+		}
+	}
+	switch style {
+	case URLStyleExtensionless:
+		setDefault("cleanUrls", true)
+		setDefault("trailingSlash", false)
+	case URLStyleSlash:
+		setDefault("trailingSlash", true)
+	}
+
+	if len(redirects) > 0 {
+		froms := make([]string, 0, len(redirects))
+		for from := range redirects {
+			froms = append(froms, from)
+		}
+		sort.Strings(froms)
+		list, _ := settings["redirects"].([]any)
+		for _, from := range froms {
+			list = append(list, vercelRedirect{Source: from, Destination: redirects[from], Permanent: true})
+		}
+		settings["redirects"] = list
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := out.WriteFile(file, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", vercelConfigFile, err)
+	}
+	return nil
+}
+
+// fallbackNotFoundTemplate is the 404 page written for hosts that need one
+// when the site doesn't render or copy its own (see writeFallbackNotFound).
+var fallbackNotFoundTemplate = template.Must(template.New("404").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Page not found{{ with .Title }} | {{ . }}{{ end }}</title>
+<meta name="robots" content="noindex" />
+</head>
+<body>
+<h1>Page not found</h1>
+<p>Try the <a href="/">home page</a>.</p>
+</body>
+</html>
+`))
+
+// writeFallbackNotFound writes a plain 404 page (see
+// fallbackNotFoundTemplate) to outputDir in out, unless the build already
+// rendered one from a 404.html template or copied one from static/.
+func writeFallbackNotFound(out FS, outputDir string, config SiteConfig) error {
+	path := urlPath(outputDir, notFoundURL)
+	if _, err := fs.Stat(out, path); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := fallbackNotFoundTemplate.Execute(&buf, config); err != nil {
+		return err
+	}
+	return out.WriteFile(path, buf.Bytes(), 0600)
+}
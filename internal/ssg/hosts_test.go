@@ -0,0 +1,128 @@
+package ssg
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBuild_DeployTarget tests tailoring the output for the host named in
+// the environment's config overlay
+func TestBuild_DeployTarget(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "redirects:\n  /old.html: /posts/first.html\n"
+	site["config.production.yaml"] = "deployTarget: cloudflare\nurls: extensionless\n"
+	site["config.development.yaml"] = "deployTarget: vercel\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{Environment: EnvProduction}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	rules, err := os.ReadFile(filepath.Join("public", "_redirects"))
+	if err != nil || string(rules) != "/old.html /posts/first.html 301\n" {
+		t.Errorf("_redirects = %q, %v", rules, err)
+	}
+	page, err := os.ReadFile(filepath.Join("public", "404.html"))
+	if err != nil || !strings.Contains(string(page), "<title>Page not found | Test Blog</title>") {
+		t.Errorf("404.html = %q, %v", page, err)
+	}
+	if _, err := os.Stat(filepath.Join("public", vercelConfigFile)); err == nil {
+		t.Errorf("%s written for cloudflare", vercelConfigFile)
+	}
+
+	if err := Build(context.Background(), BuildOptions{Environment: EnvDevelopment}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join("public", vercelConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("%s isn't JSON: %v", vercelConfigFile, err)
+	}
+	want := map[string]any{"redirects": []any{map[string]any{"source": "/old.html", "destination": "/posts/first.html", "permanent": true}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", vercelConfigFile, got, want)
+	}
+	for _, name := range []string{"_redirects", "404.html"} {
+		if _, err := os.Stat(filepath.Join("public", name)); err == nil {
+			t.Errorf("%s written for vercel", name)
+		}
+	}
+}
+
+// TestWriteVercelConfig tests merging redirects and clean URL settings into
+// a vercel.json copied from static/
+func TestWriteVercelConfig(t *testing.T) {
+	outputDir := t.TempDir()
+	writeFiles(t, outputDir, map[string]string{
+		vercelConfigFile: `{"trailingSlash": true, "redirects": [{"source": "/docs", "destination": "https://docs.example.com"}]}`,
+	})
+
+	if err := writeVercelConfig(DirFS("."), outputDir, URLStyleExtensionless, map[string]string{"/b": "/c", "/a": "/c"}); err != nil {
+		t.Fatalf("writeVercelConfig() failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, vercelConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		CleanURLs     bool `json:"cleanUrls"`
+		TrailingSlash bool `json:"trailingSlash"`
+		Redirects     []struct{ Source, Destination string }
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.CleanURLs || !got.TrailingSlash {
+		t.Errorf("cleanUrls = %v, trailingSlash = %v, want true, true (kept from static/)", got.CleanURLs, got.TrailingSlash)
+	}
+	var sources []string
+	for _, r := range got.Redirects {
+		sources = append(sources, r.Source)
+	}
+	if want := []string{"/docs", "/a", "/b"}; !slices.Equal(sources, want) {
+		t.Errorf("redirect sources = %q, want %q", sources, want)
+	}
+}
+
+// TestCheckSite_DeployTarget tests reporting unknown hosts and URL styles
+// the host would redirect
+func TestCheckSite_DeployTarget(t *testing.T) {
+	for _, tt := range []struct {
+		config string
+		want   []string
+	}{
+		{"deployTarget: netlify\n", nil},
+		{"deployTarget: cloudflare\nurls: slash\n", nil},
+		{"deployTarget: heroku\n", []string{`config.yaml: deployTarget "heroku" must be "netlify", "vercel", or "cloudflare"`}},
+		{"deployTarget: cloudflare\n", []string{"config.yaml: deployTarget cloudflare redirects URLs ending in .html to ones without it; set urls to extensionless or slash"}},
+	} {
+		tmpDir := t.TempDir()
+		site := testSite()
+		site["config.yaml"] += tt.config
+		writeFiles(t, tmpDir, site)
+		t.Chdir(tmpDir)
+
+		problems, err := checkSite(CheckOptions{ConfigPath: "config.yaml"}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("checkSite() failed: %v", err)
+		}
+		var got []string
+		for _, p := range problems {
+			got = append(got, p.File+": "+p.Message)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("checkSite() with %q = %q, want %q", tt.config, got, tt.want)
+		}
+	}
+}
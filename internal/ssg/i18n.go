@@ -0,0 +1,205 @@
+package ssg
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+	"golang.org/x/text/language"
+)
+
+// LanguageConfig is one of the languages of a multilingual site, with the
+// settings that differ from the site's. A site is multilingual when its
+// config lists languages; language is then the default one, whose pages keep
+// their URLs, while the others get theirs under /<lang>/.
+//
+// Posts in a language go in content/<lang>/, or anywhere posts go with a
+// lang field in their frontmatter. Posts without either are in the default
+// language.
+//
+// Example config.yaml:
+//
+//	language: en
+//	languages:
+//	  es:
+//	    title: Mi Blog
+//	    description: Escritos sobre Go
+type LanguageConfig struct {
+	Title       string `yaml:"title"`       // Site title in the language (default: title)
+	Description string `yaml:"description"` // Site description in the language (default: description)
+}
+
+// Translation is the current page in another language of the site, exposed
+// to templates as .Translations for language switchers.
+type Translation struct {
+	Lang  string // Language (BCP 47, e.g. "es")
+	Title string // Title of the page in that language
+	URL   string // Site-relative URL of the page in that language
+}
+
+// siteLanguages returns the languages of a multilingual site: the default
+// language first, then the others sorted. Returns nil if the site isn't
+// multilingual.
+func (c SiteConfig) siteLanguages() []string {
+	if len(c.Languages) == 0 {
+		return nil
+	}
+	def := c.defaultLanguage()
+	langs := []string{def}
+	for _, lang := range slices.Sorted(maps.Keys(c.Languages)) {
+		if lang != def {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// defaultLanguage returns the site's default language: the one its config
+// sets, even on a config returned by forLanguage.
+func (c SiteConfig) defaultLanguage() string {
+	if c.defaultLang != "" {
+		return c.defaultLang
+	}
+	return c.Language
+}
+
+// checkLanguages returns an error if the languages of a multilingual site
+// aren't valid BCP 47 tags, or it has no default language.
+func (c SiteConfig) checkLanguages() error {
+	if len(c.Languages) == 0 {
+		return nil
+	}
+	if c.Language == "" {
+		return fmt.Errorf("languages needs language set to the default language (e.g., language: en)")
+	}
+	for _, lang := range c.siteLanguages() {
+		if _, err := language.Parse(lang); err != nil {
+			return fmt.Errorf("language %q is not a BCP 47 tag like en or pt-BR", lang)
+		}
+	}
+	return nil
+}
+
+// forLanguage returns the config as seen by pages in lang: Language is set
+// to it, and the title and description are the ones configured for it.
+// Returns c unchanged if lang is empty.
+func (c SiteConfig) forLanguage(lang string) SiteConfig {
+	if lang == "" {
+		return c
+	}
+	c.defaultLang = c.defaultLanguage()
+	c.Language = lang
+	if lc, ok := c.Languages[lang]; ok {
+		if lc.Title != "" {
+			c.Title = lc.Title
+		}
+		if lc.Description != "" {
+			c.Description = lc.Description
+		}
+	}
+	return c
+}
+
+// languageURL returns the site-relative URL of the page at url in lang:
+// url itself for the default language (or any language, if the site isn't
+// multilingual), and url under /<lang> for the others.
+func (c SiteConfig) languageURL(lang, url string) string {
+	if len(c.Languages) == 0 || lang == "" || lang == c.defaultLanguage() {
+		return url
+	}
+	return "/" + lang + url
+}
+
+// assignLanguages sets the language of the posts of a multilingual site
+// that don't have one to the default language, and returns an error if a
+// post is in a language the config doesn't list. Does nothing if the site
+// isn't multilingual.
+func assignLanguages(posts []*parser.Post, config *SiteConfig) error {
+	langs := config.siteLanguages()
+	if langs == nil {
+		return nil
+	}
+	for _, post := range posts {
+		if post.Lang == "" {
+			post.Lang = langs[0]
+		}
+		if !slices.Contains(langs, post.Lang) {
+			return fmt.Errorf("post %s is in %s, which isn't listed under languages", post.Slug, post.Lang)
+		}
+	}
+	return nil
+}
+
+// postsInLanguage returns the posts in lang, keeping their order. On a site
+// that isn't multilingual, lang is empty and every post is returned.
+func postsInLanguage(posts []*parser.Post, lang string) []*parser.Post {
+	if lang == "" {
+		return posts
+	}
+	var in []*parser.Post
+	for _, post := range posts {
+		if post.Lang == lang {
+			in = append(in, post)
+		}
+	}
+	return in
+}
+
+// linkTranslations sets Translations on each post of a multilingual site to
+// the posts in its other languages with the same translationKey, or the same
+// slug if they don't set one, in the order of the site's languages.
+func linkTranslations(posts []*parser.Post, config *SiteConfig) {
+	langs := config.siteLanguages()
+	groups := make(map[string][]*parser.Post)
+	for _, post := range posts {
+		post.Translations = nil
+		key := post.Translation
+		if key == "" {
+			key = post.Slug
+		}
+		groups[key] = append(groups[key], post)
+	}
+	for _, group := range groups {
+		slices.SortStableFunc(group, func(a, b *parser.Post) int {
+			return slices.Index(langs, a.Lang) - slices.Index(langs, b.Lang)
+		})
+		for _, post := range group {
+			for _, other := range group {
+				if other.Lang != post.Lang {
+					post.Translations = append(post.Translations, other)
+				}
+			}
+		}
+	}
+}
+
+// postTranslations returns the translations of a post for PageData.
+func postTranslations(post *parser.Post) []Translation {
+	var ts []Translation
+	for _, other := range post.Translations {
+		ts = append(ts, Translation{Lang: other.Lang, Title: other.Title, URL: other.URL})
+	}
+	return ts
+}
+
+// indexTranslations returns the home pages of the site's languages other
+// than lang for PageData.
+func indexTranslations(config SiteConfig, lang string) []Translation {
+	var ts []Translation
+	for _, other := range config.siteLanguages() {
+		if other != lang {
+			ts = append(ts, Translation{Lang: other, Title: config.forLanguage(other).Title, URL: config.languageURL(other, "/")})
+		}
+	}
+	return ts
+}
+
+// indexLanguages returns the languages with a home page: every language of
+// a multilingual site, or just "" for a site that isn't.
+func indexLanguages(config SiteConfig) []string {
+	if langs := config.siteLanguages(); langs != nil {
+		return langs
+	}
+	return []string{""}
+}
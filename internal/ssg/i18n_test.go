@@ -0,0 +1,138 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestForLanguage tests the config seen by pages in each language
+func TestForLanguage(t *testing.T) {
+	config := SiteConfig{
+		Title:     "My Blog",
+		Language:  "en",
+		Languages: map[string]LanguageConfig{"es": {Title: "Mi Blog"}, "de": {}},
+	}
+
+	if got := config.siteLanguages(); strings.Join(got, ",") != "en,de,es" {
+		t.Errorf("siteLanguages() = %v, want the default first, then the others sorted", got)
+	}
+	es := config.forLanguage("es")
+	if es.Title != "Mi Blog" || es.Language != "es" {
+		t.Errorf("forLanguage(es) = %q, %q", es.Title, es.Language)
+	}
+	if de := config.forLanguage("de"); de.Title != "My Blog" {
+		t.Errorf("forLanguage(de).Title = %q, want the site's title", de.Title)
+	}
+	if got := es.languageURL("es", "/"); got != "/es/" {
+		t.Errorf("languageURL(es) = %q, want /es/", got)
+	}
+	if got := es.languageURL("en", "/posts/a.html"); got != "/posts/a.html" {
+		t.Errorf("languageURL(en) = %q, want the URL unchanged", got)
+	}
+	if got := (SiteConfig{Language: "en"}).languageURL("fr", "/"); got != "/" {
+		t.Errorf("languageURL() on a site that isn't multilingual = %q, want /", got)
+	}
+
+	if err := (SiteConfig{Languages: config.Languages}).checkLanguages(); err == nil {
+		t.Error("checkLanguages() without a default language succeeded")
+	}
+	bad := SiteConfig{Language: "en", Languages: map[string]LanguageConfig{"not a tag": {}}}
+	if err := bad.checkLanguages(); err == nil {
+		t.Error("checkLanguages() with an invalid tag succeeded")
+	}
+}
+
+// TestLinkTranslations tests matching posts with their translations
+func TestLinkTranslations(t *testing.T) {
+	config := &SiteConfig{Language: "en", Languages: map[string]LanguageConfig{"es": {}}}
+	hello := &parser.Post{Slug: "hello", Lang: "en"}
+	hola := &parser.Post{Slug: "hola", Lang: "es", Translation: "hello"}
+	about := &parser.Post{Slug: "about", Lang: "es"}
+	alone := &parser.Post{Slug: "alone", Lang: "en"}
+	aboutEn := &parser.Post{Slug: "about", Lang: "en"}
+
+	linkTranslations([]*parser.Post{hola, hello, about, alone, aboutEn}, config)
+
+	if len(hello.Translations) != 1 || hello.Translations[0] != hola {
+		t.Errorf("hello.Translations = %v, want hola (by translationKey)", hello.Translations)
+	}
+	if len(about.Translations) != 1 || about.Translations[0] != aboutEn {
+		t.Errorf("about.Translations = %v, want the English about (by slug)", about.Translations)
+	}
+	if alone.Translations != nil {
+		t.Errorf("alone.Translations = %v, want none", alone.Translations)
+	}
+}
+
+// TestBuild_Multilingual tests building a site in two languages
+func TestBuild_Multilingual(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] = "title: Test Blog\nbaseUrl: https://test.com\nlanguage: en\nlanguages:\n  es:\n    title: Blog de Prueba\napi:\n  enabled: true\n"
+	site["templates/base.html"] = `<html lang="{{ .Site.Language }}"><head>{{ .Head }}<title>{{ .Site.Title }}</title></head><body>` +
+		`{{ range .Translations }}[{{ .Lang }} {{ .URL }}]{{ end }}{{ template "posts" . }}</body></html>`
+	site["templates/posts.html"] = `{{define "posts"}}{{ range .Posts }}<a href="{{ .URL }}">{{ .Title }}</a>{{ end }}{{end}}`
+	site["templates/post.html"] = `{{define "posts"}}{{ .Post.Title }} home={{ .Home }}{{ with .Post.Prev }} prev={{ .URL }}{{ end }}{{end}}`
+	site["content/es/2024-01-15-first.md"] = "---\ntitle: Primera\n---\n\nHola.\n"
+	site["content/es/2024-01-10-older.md"] = "---\ntitle: Anterior\n---\n\nAntes.\n"
+	site["content/posts/2024-01-20-cafe.md"] = "---\ntitle: Café\nlang: es\n---\n\nCafé.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{OutputDir: "public"}); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join("public", filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	index := read("index.html")
+	if !strings.Contains(index, "First Post") || strings.Contains(index, "Primera") || !strings.Contains(index, "[es /es/]") {
+		t.Errorf("index.html = %s, want English posts and a link to the Spanish home page", index)
+	}
+	esIndex := read("es/index.html")
+	for _, want := range []string{`<html lang="es">`, "<title>Blog de Prueba</title>", "Café", "Primera", "Anterior", "[en /]"} {
+		if !strings.Contains(esIndex, want) {
+			t.Errorf("es/index.html doesn't contain %q:\n%s", want, esIndex)
+		}
+	}
+	if strings.Contains(esIndex, "First Post") {
+		t.Errorf("es/index.html lists an English post:\n%s", esIndex)
+	}
+
+	first := read("posts/first.html")
+	for _, want := range []string{
+		"[es /es/posts/first.html]",
+		`<link rel="alternate" hreflang="en" href="https://test.com/posts/first.html" />`,
+		`<link rel="alternate" hreflang="es" href="https://test.com/es/posts/first.html" />`,
+	} {
+		if !strings.Contains(first, want) {
+			t.Errorf("posts/first.html doesn't contain %q:\n%s", want, first)
+		}
+	}
+	esFirst := read("es/posts/first.html")
+	if !strings.Contains(esFirst, "Primera home=/es/ prev=/es/posts/older.html") {
+		t.Errorf("es/posts/first.html = %s, want its Spanish home page and neighbor", esFirst)
+	}
+	if !strings.Contains(read("es/posts/cafe.html"), "Café") {
+		t.Error("post with lang: es in content/posts wasn't published under /es/")
+	}
+
+	if api := read("es/api/posts.json"); !strings.Contains(api, "Primera") || strings.Contains(api, "First Post") {
+		t.Errorf("es/api/posts.json = %s, want only Spanish posts", api)
+	}
+	if names, err := findSections(DirFS("."), "content", SiteConfig{}.isContentFile, "en", "es"); err != nil || len(names) != 0 {
+		t.Errorf("findSections() = %v, %v, want the language directory skipped", names, err)
+	}
+}
@@ -0,0 +1,162 @@
+package ssg
+
+import (
+	"image"
+	_ "image/gif" // Register GIF for reading dimensions
+	"io/fs"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	_ "golang.org/x/image/webp" // Register WebP for reading dimensions
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// imageSize is the size of an image file in pixels.
+type imageSize struct {
+	width, height int
+}
+
+// imageAttrsTransformer returns an HTMLTransformer that adds attributes to
+// every <img> on a page, from templates and content alike: loading="lazy"
+// and decoding="async" if cfg.Lazy is set, and width and height if
+// cfg.Dimensions is set, so browsers reserve the image's space before it
+// loads instead of shifting the page.
+//
+// Dimensions are read from the image's file in the first of staticDirs that
+// has it. Images on other sites, and files other than JPEG, PNG, GIF, and
+// WebP (like SVG), get no dimensions. Attributes the <img> already has are
+// kept. If only one of width and height is set,
+// the other is added to match the image's aspect ratio.
+//
+// Parameters:
+//   - src: Filesystem the site is read from
+//   - staticDirs: Directories copied to the site's root, highest priority
+//     first (e.g., "static", then the theme's)
+//   - baseURL: Site's baseUrl; absolute URLs on its host are the site's own
+//   - cfg: Image configuration from config.yaml
+func imageAttrsTransformer(src fs.FS, staticDirs []string, baseURL string, cfg ImagesConfig) HTMLTransformer {
+	var siteHost string
+	if u, err := url.Parse(baseURL); err == nil {
+		siteHost = u.Hostname()
+	}
+	var mu sync.Mutex
+	sizes := make(map[string]*imageSize) // By site path; nil if the file has none
+
+	size := func(sitePath string) *imageSize {
+		mu.Lock()
+		defer mu.Unlock()
+		if s, ok := sizes[sitePath]; ok {
+			return s
+		}
+		var s *imageSize
+		for _, dir := range staticDirs {
+			f, err := src.Open(path.Join(dir, sitePath))
+			if err != nil {
+				continue
+			}
+			c, _, err := image.DecodeConfig(f)
+			f.Close()
+			if err == nil && c.Width > 0 && c.Height > 0 {
+				s = &imageSize{width: c.Width, height: c.Height}
+			}
+			break
+		}
+		sizes[sitePath] = s
+		return s
+	}
+
+	return func(doc *html.Node, page *RenderedPage) error {
+		pageURL := "/"
+		if page != nil && page.Data != nil && page.Data.URL != "" {
+			pageURL = page.Data.URL
+		}
+		walkHTML(doc, func(n *html.Node) {
+			if n.Type != html.ElementNode || n.DataAtom != atom.Img {
+				return
+			}
+			if cfg.Lazy {
+				setDefaultAttr(n, "loading", "lazy")
+				setDefaultAttr(n, "decoding", "async")
+			}
+			if !cfg.Dimensions {
+				return
+			}
+			width, hasWidth := nodeAttr(n, "width")
+			height, hasHeight := nodeAttr(n, "height")
+			if hasWidth && hasHeight {
+				return
+			}
+			src, _ := nodeAttr(n, "src")
+			sitePath, ok := imageSitePath(src, pageURL, siteHost)
+			if !ok {
+				return
+			}
+			s := size(sitePath)
+			if s == nil {
+				return
+			}
+			switch {
+			case hasWidth:
+				if w, err := strconv.Atoi(width); err == nil && w > 0 {
+					setDefaultAttr(n, "height", strconv.Itoa((w*s.height+s.width/2)/s.width))
+				}
+			case hasHeight:
+				if h, err := strconv.Atoi(height); err == nil && h > 0 {
+					setDefaultAttr(n, "width", strconv.Itoa((h*s.width+s.height/2)/s.height))
+				}
+			default:
+				setDefaultAttr(n, "width", strconv.Itoa(s.width))
+				setDefaultAttr(n, "height", strconv.Itoa(s.height))
+			}
+		})
+		return nil
+	}
+}
+
+// imageSitePath returns the path of an image's file relative to the site's
+// root, resolving src against the URL of the page it's on. Reports false if
+// src is on another site or isn't an http(s) URL.
+func imageSitePath(src, pageURL, siteHost string) (string, bool) {
+	u, err := url.Parse(src)
+	if err != nil || src == "" {
+		return "", false
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+	if u.Host != "" && !strings.EqualFold(u.Hostname(), siteHost) {
+		return "", false
+	}
+	p := u.Path
+	if !strings.HasPrefix(p, "/") {
+		dir := pageURL
+		if !strings.HasSuffix(dir, "/") {
+			dir = path.Dir(dir)
+		}
+		p = path.Join(dir, p)
+	}
+	p = strings.TrimPrefix(path.Clean(p), "/")
+	return p, p != "" && p != "."
+}
+
+// nodeAttr returns the value of an element's attribute, and whether it has
+// it.
+func nodeAttr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// setDefaultAttr sets an attribute of an element unless it already has it.
+func setDefaultAttr(n *html.Node, key, val string) {
+	if _, ok := nodeAttr(n, key); !ok {
+		n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+	}
+}
@@ -0,0 +1,71 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_ImageAttributes tests adding lazy-loading and dimension
+// attributes to images from their files in static/
+func TestBuild_ImageAttributes(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "images:\n  lazy: true\n  dimensions: true\n"
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First\ndate: 2024-01-15\n---\n\n" +
+		"![Photo](/images/photo.png)\n\n" +
+		"<img src=\"/images/photo.png\" width=\"100\" loading=\"eager\" alt=\"Half\">\n\n" +
+		"<img src=\"../images/photo.png\" alt=\"Relative\">\n\n" +
+		"![Remote](https://other.com/photo.png) ![Missing](/images/missing.png)\n"
+	writeFiles(t, tmpDir, site)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "static", "images"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	writeTestPNG(t, filepath.Join(tmpDir, "static", "images", "photo.png"), 200, 150)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join("public", "posts", "first.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+	for _, want := range []string{
+		`<img src="/images/photo.png" alt="Photo" loading="lazy" decoding="async" width="200" height="150"/>`,
+		`<img src="/images/photo.png" width="100" loading="eager" alt="Half" decoding="async" height="75"/>`,
+		`<img src="../images/photo.png" alt="Relative" loading="lazy" decoding="async" width="200" height="150"/>`,
+		`<img src="https://other.com/photo.png" alt="Remote" loading="lazy" decoding="async"/>`,
+		`<img src="/images/missing.png" alt="Missing" loading="lazy" decoding="async"/>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("first.html missing %s:\n%s", want, html)
+		}
+	}
+}
+
+// TestImageSitePath tests resolving image URLs to paths in the site
+func TestImageSitePath(t *testing.T) {
+	tests := []struct {
+		src, pageURL string
+		want         string
+		ok           bool
+	}{
+		{"/images/a.png", "/posts/hello.html", "images/a.png", true},
+		{"a.png", "/posts/hello.html", "posts/a.png", true},
+		{"a.png", "/posts/hello/", "posts/hello/a.png", true},
+		{"https://Example.com/images/a.png?v=2", "/", "images/a.png", true},
+		{"https://other.com/a.png", "/", "", false},
+		{"data:image/png;base64,AAAA", "/", "", false},
+		{"", "/", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := imageSitePath(tt.src, tt.pageURL, "example.com")
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("imageSitePath(%q, %q) = %q, %v; want %q, %v", tt.src, tt.pageURL, got, ok, tt.want, tt.ok)
+		}
+	}
+}
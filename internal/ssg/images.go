@@ -0,0 +1,335 @@
+package ssg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/net/html"
+)
+
+// ImagesConfig configures responsive image generation, and the attributes
+// added to every <img> on the site (see imageAttrsTransformer).
+//
+// Example config.yaml:
+//
+//	images:
+//	  widths: [480, 960, 1440]
+//	  formats: [webp, avif]
+//	  sizes: "(max-width: 800px) 100vw, 800px"
+//	  quality: 80
+//	  lazy: true
+//	  dimensions: true
+type ImagesConfig struct {
+	Widths     []int    `yaml:"widths"`     // Variant widths in pixels; empty disables the pipeline
+	Formats    []string `yaml:"formats"`    // Extra formats to encode: "webp" (cwebp) and/or "avif" (avifenc)
+	Sizes      string   `yaml:"sizes"`      // Value of the sizes attribute (default: "100vw")
+	Quality    int      `yaml:"quality"`    // Encoder quality from 1-100 (default: 80)
+	Lazy       bool     `yaml:"lazy"`       // Add loading="lazy" and decoding="async" to images
+	Dimensions bool     `yaml:"dimensions"` // Add width and height from the image files in static/, against layout shift
+}
+
+// imageEncoders maps extra output formats to their MIME type and the external
+// encoder used to produce them.
+var imageEncoders = map[string]struct {
+	mimeType string
+	binary   string
+	args     func(quality int, in, out string) []string
+}{
+	"webp": {
+		mimeType: "image/webp",
+		binary:   "cwebp",
+		args: func(quality int, in, out string) []string {
+			return []string{"-quiet", "-q", strconv.Itoa(quality), in, "-o", out}
+		},
+	},
+	"avif": {
+		mimeType: "image/avif",
+		binary:   "avifenc",
+		args: func(quality int, in, out string) []string {
+			return []string{"-q", strconv.Itoa(quality), in, out}
+		},
+	},
+}
+
+// imageVariant is one rendition of an image at a specific width.
+type imageVariant struct {
+	URL   string
+	Width int
+}
+
+// imageSource is a set of variants sharing one MIME type.
+type imageSource struct {
+	Type     string
+	Variants []imageVariant
+}
+
+// responsiveImage describes all generated renditions of a source image.
+type responsiveImage struct {
+	Fallback imageSource   // Variants in the original format, used by <img>
+	Sources  []imageSource // Extra formats, emitted as <source> elements
+}
+
+// processImages generates resized variants of every JPEG and PNG under srcDir.
+//
+// For each image and each configured width smaller than the image, a resized
+// copy is written next to where the original will be copied, named with a
+// width suffix (e.g., "photo.jpg" → "photo-480w.jpg"). For each configured
+// extra format, the original and each variant are also encoded with the
+// format's external encoder (e.g., "photo-480w.webp").
+//
+// Extra formats are encoded by external programs, so they need src and out
+// to be on disk (see DirFS).
+//
+// Parameters:
+//   - src: Filesystem the site is read from
+//   - out: Filesystem the site is written to
+//   - srcDir: Directory containing source images (e.g., "static/images")
+//   - dstDir: Output directory for variants (e.g., "public/images")
+//   - urlPrefix: URL path that dstDir is served under (e.g., "/images")
+//   - cfg: Image pipeline configuration
+//
+// Returns the generated renditions keyed by the original image's URL, or an
+// error if decoding, resizing, or encoding fails. Returns an empty map if the
+// pipeline is disabled or srcDir doesn't exist.
+func processImages(src fs.FS, out FS, srcDir, dstDir, urlPrefix string, cfg ImagesConfig) (map[string]*responsiveImage, error) {
+	images := make(map[string]*responsiveImage)
+	if len(cfg.Widths) == 0 {
+		return images, nil
+	}
+	if _, err := fs.Stat(src, srcDir); errors.Is(err, fs.ErrNotExist) {
+		return images, nil
+	}
+
+	quality := cfg.Quality
+	if quality <= 0 || quality > 100 {
+		quality = 80
+	}
+	for _, format := range cfg.Formats {
+		enc, ok := imageEncoders[format]
+		if !ok {
+			return nil, fmt.Errorf("unsupported image format %q", format)
+		}
+		if _, err := exec.LookPath(enc.binary); err != nil {
+			return nil, fmt.Errorf("%s images require %s to be installed", format, enc.binary)
+		}
+	}
+
+	widths := append([]int(nil), cfg.Widths...)
+	sort.Ints(widths)
+
+	err := fs.WalkDir(src, srcDir, func(srcPath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(srcPath))
+		if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(srcDir, srcPath)
+		if err != nil {
+			return err
+		}
+		url := path.Join(urlPrefix, filepath.ToSlash(relPath))
+		img, err := resizeImage(src, out, srcPath, filepath.Join(dstDir, relPath), url, widths, cfg.Formats, quality)
+		if err != nil {
+			return fmt.Errorf("processing %s: %w", srcPath, err)
+		}
+		images[url] = img
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// resizeImage writes the variants of a single image in srcFS to out and
+// returns its renditions. dstPath and url refer to the original (full-size)
+// image.
+func resizeImage(srcFS fs.FS, out FS, srcPath, dstPath, url string, widths []int, formats []string, quality int) (*responsiveImage, error) {
+	f, err := srcFS.Open(srcPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	src, format, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	ext := filepath.Ext(dstPath)
+	base := strings.TrimSuffix(dstPath, ext)
+	urlBase := strings.TrimSuffix(url, ext)
+
+	img := &responsiveImage{Fallback: imageSource{Type: "image/" + format}}
+	// Each rendition is recorded as its path (to feed extra-format encoders)
+	// alongside its width. The original is always the last entry.
+	type rendition struct {
+		fsys         fs.FS
+		path, suffix string
+		width        int
+	}
+	var renditions []rendition
+
+	for _, width := range widths {
+		if width >= bounds.Dx() {
+			continue
+		}
+		height := bounds.Dy() * width / bounds.Dx()
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+		var buf bytes.Buffer
+		if format == "png" {
+			err = png.Encode(&buf, dst)
+		} else {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: quality})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("encoding %dw variant: %w", width, err)
+		}
+
+		suffix := fmt.Sprintf("-%dw", width)
+		if err := out.WriteFile(base+suffix+ext, buf.Bytes(), 0600); err != nil {
+			return nil, err
+		}
+		renditions = append(renditions, rendition{fsys: out, path: base + suffix + ext, suffix: suffix, width: width})
+	}
+	renditions = append(renditions, rendition{fsys: srcFS, path: srcPath, width: bounds.Dx()})
+
+	for _, r := range renditions {
+		img.Fallback.Variants = append(img.Fallback.Variants, imageVariant{URL: urlBase + r.suffix + ext, Width: r.width})
+	}
+
+	for _, format := range formats {
+		enc := imageEncoders[format]
+		source := imageSource{Type: enc.mimeType}
+		for _, r := range renditions {
+			in, inOK := diskPath(r.fsys, r.path)
+			dst, dstOK := diskPath(out, base+r.suffix+"."+format)
+			if !inOK || !dstOK {
+				return nil, fmt.Errorf("%s images can only be encoded on disk", format)
+			}
+			if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+				return nil, err
+			}
+			// #nosec G204 -- arguments are paths of the site's own image files
+			cmd := exec.Command(enc.binary, enc.args(quality, in, dst)...)
+			if output, err := cmd.CombinedOutput(); err != nil {
+				return nil, fmt.Errorf("encoding %s: %w: %s", dst, err, output)
+			}
+			source.Variants = append(source.Variants, imageVariant{URL: urlBase + r.suffix + "." + format, Width: r.width})
+		}
+		img.Sources = append(img.Sources, source)
+	}
+
+	return img, nil
+}
+
+// srcset formats the variants as a srcset attribute value.
+func (s imageSource) srcset() string {
+	parts := make([]string, len(s.Variants))
+	for i, v := range s.Variants {
+		parts[i] = fmt.Sprintf("%s %dw", v.URL, v.Width)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// rewriteImages replaces <img> tags in post HTML whose src refers to a
+// processed image with responsive markup.
+//
+// The <img> gains srcset and sizes attributes. If extra formats were
+// generated, it is wrapped in a <picture> element with one <source> per
+// format. Images that weren't processed are left untouched.
+//
+// Parameters:
+//   - content: Rendered post HTML
+//   - images: Renditions keyed by URL, from processImages
+//   - sizes: Value of the sizes attribute (defaults to "100vw")
+//
+// Returns the rewritten HTML.
+func rewriteImages(content template.HTML, images map[string]*responsiveImage, sizes string) template.HTML {
+	if len(images) == 0 {
+		return content
+	}
+	if sizes == "" {
+		sizes = "100vw"
+	}
+
+	var out strings.Builder
+	z := html.NewTokenizer(strings.NewReader(string(content)))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			out.Write(z.Raw())
+			continue
+		}
+
+		raw := string(z.Raw())
+		tok := z.Token()
+		if tok.Data != "img" {
+			out.WriteString(raw)
+			continue
+		}
+		img, ok := images[attr(tok, "src")]
+		if !ok {
+			out.WriteString(raw)
+			continue
+		}
+
+		tok.Attr = append(tok.Attr,
+			html.Attribute{Key: "srcset", Val: img.Fallback.srcset()},
+			html.Attribute{Key: "sizes", Val: sizes},
+		)
+		if len(img.Sources) == 0 {
+			out.WriteString(tok.String())
+			continue
+		}
+
+		out.WriteString("<picture>")
+		for _, source := range img.Sources {
+			s := html.Token{Type: html.StartTagToken, Data: "source", Attr: []html.Attribute{
+				{Key: "type", Val: source.Type},
+				{Key: "srcset", Val: source.srcset()},
+				{Key: "sizes", Val: sizes},
+			}}
+			out.WriteString(s.String())
+		}
+		out.WriteString(tok.String())
+		out.WriteString("</picture>")
+	}
+
+	// #nosec G203 -- rewritten from HTML produced by the markdown parser
+	return template.HTML(out.String())
+}
+
+// attr returns the value of the named attribute of a token, or "".
+func attr(tok html.Token, key string) string {
+	for _, a := range tok.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package ssg
+
+import (
+	"html/template"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestPNG writes a solid-color PNG of the given size.
+func writeTestPNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestProcessImages tests generating resized variants
+func TestProcessImages(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "static", "images")
+	dstDir := filepath.Join(tmpDir, "public", "images")
+	if err := os.MkdirAll(filepath.Join(srcDir, "nested"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	writeTestPNG(t, filepath.Join(srcDir, "nested", "photo.png"), 100, 50)
+	if err := os.WriteFile(filepath.Join(srcDir, "notes.txt"), []byte("not an image"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := ImagesConfig{Widths: []int{200, 40}}
+	images, err := processImages(DirFS("."), DirFS("."), srcDir, dstDir, "/images", cfg)
+	if err != nil {
+		t.Fatalf("processImages() failed: %v", err)
+	}
+
+	if len(images) != 1 {
+		t.Fatalf("len(images) = %d, want 1", len(images))
+	}
+	img, ok := images["/images/nested/photo.png"]
+	if !ok {
+		t.Fatalf("images missing /images/nested/photo.png, got %v", images)
+	}
+
+	// 200 is wider than the original, so only the 40w variant is generated
+	want := []imageVariant{
+		{URL: "/images/nested/photo-40w.png", Width: 40},
+		{URL: "/images/nested/photo.png", Width: 100},
+	}
+	if len(img.Fallback.Variants) != len(want) {
+		t.Fatalf("Variants = %+v, want %+v", img.Fallback.Variants, want)
+	}
+	for i, v := range want {
+		if img.Fallback.Variants[i] != v {
+			t.Errorf("Variants[%d] = %+v, want %+v", i, img.Fallback.Variants[i], v)
+		}
+	}
+	if img.Fallback.Type != "image/png" {
+		t.Errorf("Type = %q, want %q", img.Fallback.Type, "image/png")
+	}
+
+	f, err := os.Open(filepath.Join(dstDir, "nested", "photo-40w.png"))
+	if err != nil {
+		t.Fatalf("variant not written: %v", err)
+	}
+	defer f.Close()
+	cfgImg, err := png.DecodeConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfgImg.Width != 40 || cfgImg.Height != 20 {
+		t.Errorf("variant size = %dx%d, want 40x20", cfgImg.Width, cfgImg.Height)
+	}
+}
+
+// TestProcessImages_Disabled tests that no widths disables the pipeline
+func TestProcessImages_Disabled(t *testing.T) {
+	images, err := processImages(DirFS("."), DirFS("."), "/nonexistent", t.TempDir(), "/images", ImagesConfig{})
+	if err != nil {
+		t.Fatalf("processImages() failed: %v", err)
+	}
+	if len(images) != 0 {
+		t.Errorf("len(images) = %d, want 0", len(images))
+	}
+}
+
+// TestProcessImages_UnsupportedFormat tests rejecting unknown formats
+func TestProcessImages_UnsupportedFormat(t *testing.T) {
+	srcDir := t.TempDir()
+	cfg := ImagesConfig{Widths: []int{100}, Formats: []string{"bmp"}}
+	if _, err := processImages(DirFS("."), DirFS("."), srcDir, t.TempDir(), "/images", cfg); err == nil {
+		t.Error("processImages() succeeded with unsupported format, want error")
+	}
+}
+
+// TestRewriteImages tests rewriting <img> tags to responsive markup
+func TestRewriteImages(t *testing.T) {
+	images := map[string]*responsiveImage{
+		"/images/a.jpg": {
+			Fallback: imageSource{Type: "image/jpeg", Variants: []imageVariant{
+				{URL: "/images/a-480w.jpg", Width: 480},
+				{URL: "/images/a.jpg", Width: 1200},
+			}},
+		},
+		"/images/b.png": {
+			Fallback: imageSource{Type: "image/png", Variants: []imageVariant{
+				{URL: "/images/b.png", Width: 300},
+			}},
+			Sources: []imageSource{{Type: "image/webp", Variants: []imageVariant{
+				{URL: "/images/b.webp", Width: 300},
+			}}},
+		},
+	}
+
+	content := `<p><img src="/images/a.jpg" alt="A" /></p>` +
+		`<p><img src="/images/b.png" alt="B"></p>` +
+		`<p><img src="/images/other.gif" alt="C" /></p>`
+
+	got := string(rewriteImages(template.HTML(content), images, "50vw"))
+
+	if !strings.Contains(got, `<img src="/images/a.jpg" alt="A" srcset="/images/a-480w.jpg 480w, /images/a.jpg 1200w" sizes="50vw"/>`) {
+		t.Errorf("fallback-only image not rewritten. Got: %s", got)
+	}
+	if strings.Contains(got, `<picture><img src="/images/a.jpg"`) {
+		t.Errorf("fallback-only image should not be wrapped in <picture>. Got: %s", got)
+	}
+	wantPicture := `<picture><source type="image/webp" srcset="/images/b.webp 300w" sizes="50vw">` +
+		`<img src="/images/b.png" alt="B" srcset="/images/b.png 300w" sizes="50vw"></picture>`
+	if !strings.Contains(got, wantPicture) {
+		t.Errorf("image with sources not wrapped in <picture>. Got: %s", got)
+	}
+	if !strings.Contains(got, `<img src="/images/other.gif" alt="C" />`) {
+		t.Errorf("unprocessed image was modified. Got: %s", got)
+	}
+}
@@ -0,0 +1,691 @@
+package ssg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/kvnloughead/ssg/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// siteImport is a site read from another generator, ready to be written in
+// this generator's layout.
+type siteImport struct {
+	Title     string
+	BaseURL   string
+	Permalink string // Post URL pattern matching the old site's URLs
+	Posts     []importedPost
+	Static    []string // Directories whose contents are copied into static/
+	Warnings  []string
+}
+
+// importedPost is a post converted from another generator.
+type importedPost struct {
+	Source      string // Path of the original file
+	Frontmatter parser.Frontmatter
+	Body        []byte
+	Resources   []string // Files published next to the post (Hugo page bundles)
+	SlugSet     bool     // The frontmatter set the slug
+}
+
+// warnf records a warning about something that couldn't be converted.
+func (imp *siteImport) warnf(format string, args ...any) {
+	imp.Warnings = append(imp.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Import converts the content of a Hugo or Jekyll site in srcDir into this
+// generator's layout in the current directory.
+//
+// Posts are written to content/posts/ with their frontmatter mapped to the
+// fields this generator uses (e.g., Hugo's summary and Jekyll's excerpt become
+// description, Jekyll's published: false becomes draft: true); fields without
+// an equivalent are kept and reach templates as .Post.Params. Static files are
+// copied to static/, and the old site's permalink pattern is translated so
+// existing URLs keep working. A config.yaml is written if there isn't one;
+// otherwise the permalink to add to it is printed.
+//
+// Anything that can't be converted (Liquid tags, Hugo shortcodes, other
+// content sections) is reported as a warning for manual review.
+//
+// Parameters:
+//   - from: Generator the site was built with, "hugo" or "jekyll"
+//   - srcDir: Root directory of the site to import
+//
+// Returns an error if the site can't be read, or if a post would overwrite an
+// existing file.
+func Import(from, srcDir string) error {
+	var imp *siteImport
+	var err error
+	switch from {
+	case "hugo":
+		imp, err = importHugo(srcDir)
+	case "jekyll":
+		imp, err = importJekyll(srcDir)
+	default:
+		return fmt.Errorf("unknown generator %q, want hugo or jekyll", from)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := writeImport(imp); err != nil {
+		return err
+	}
+	for _, w := range imp.Warnings {
+		fmt.Printf("Warning: %s\n", w)
+	}
+	fmt.Printf("Imported %d posts from %s\n", len(imp.Posts), srcDir)
+	return nil
+}
+
+// hugoSections are the content sections imported as posts, in order of
+// preference for the permalink.
+var hugoSections = []string{"posts", "post", "blog"}
+
+// importHugo reads a Hugo site: posts from content/posts (or post, blog),
+// including page bundles, and static files from static/.
+func importHugo(srcDir string) (*siteImport, error) {
+	imp := &siteImport{}
+
+	var config struct {
+		Title      string            `toml:"title" yaml:"title" json:"title"`
+		BaseURL    string            `toml:"baseURL" yaml:"baseURL" json:"baseURL"`
+		Permalinks map[string]string `toml:"permalinks" yaml:"permalinks" json:"permalinks"`
+	}
+	for _, name := range []string{"hugo.toml", "hugo.yaml", "hugo.json", "config.toml", "config.yaml", "config.json"} {
+		data, err := os.ReadFile(filepath.Join(srcDir, name)) // #nosec G304 -- reading the site being imported
+		if err != nil {
+			continue
+		}
+		if err := decodeByExt(name, data, &config); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		break
+	}
+	imp.Title = config.Title
+	imp.BaseURL = config.BaseURL
+
+	contentDir := filepath.Join(srcDir, "content")
+	entries, err := os.ReadDir(contentDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", contentDir, err)
+	}
+	var section string
+	for _, e := range entries {
+		isSection := e.IsDir() && slices.Contains(hugoSections, e.Name())
+		if !isSection {
+			imp.warnf("content/%s not imported: only %s are imported as posts (publish other pages with mounts:)", e.Name(), strings.Join(hugoSections, ", "))
+			continue
+		}
+		if section == "" {
+			section = e.Name()
+		}
+		if err := importHugoSection(imp, filepath.Join(contentDir, e.Name())); err != nil {
+			return nil, err
+		}
+	}
+
+	imp.Permalink = "/" + section + "/:slug/"
+	if section == "" {
+		imp.Permalink = "/posts/:slug/"
+	}
+	if pattern, ok := config.Permalinks[section]; ok {
+		// Hugo's :title is always the title, and its :slug falls back to the
+		// title; :filename and :contentbasename are the slugs already set.
+		for i := range imp.Posts {
+			post := &imp.Posts[i]
+			if strings.Contains(pattern, ":title") || (strings.Contains(pattern, ":slug") && !post.SlugSet) {
+				post.Frontmatter.Slug = slugify(post.Frontmatter.Title)
+			}
+		}
+		imp.Permalink = translatePermalink(imp, pattern, map[string]string{
+			":title": ":slug", ":filename": ":slug", ":contentbasename": ":slug",
+			":section": section, ":sections": section,
+		})
+	}
+
+	if dir := filepath.Join(srcDir, "static"); isDir(dir) {
+		imp.Static = append(imp.Static, dir)
+	}
+	if isDir(filepath.Join(srcDir, "assets")) {
+		imp.warnf("assets/ not imported: it's processed by Hugo Pipes; copy the built files you need into static/")
+	}
+	return imp, nil
+}
+
+// importHugoSection imports the posts in a Hugo content section. A directory
+// containing index.md is a page bundle: its other files are published next to
+// the post.
+func importHugoSection(imp *siteImport, dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isMarkdown(p) || d.Name() == "_index.md" {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(p), "index.md")); err == nil && d.Name() != "index.md" {
+			return nil // A page bundle's resource, copied with its index.md
+		}
+
+		slug := strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))
+		var resources []string
+		if slug == "index" {
+			bundle := filepath.Dir(p)
+			slug = filepath.Base(bundle)
+			err := filepath.WalkDir(bundle, func(r string, rd fs.DirEntry, err error) error {
+				if err == nil && !rd.IsDir() && r != p {
+					resources = append(resources, r)
+				}
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		post, err := importPostFile(imp, p, slug, hugoFields)
+		if err != nil {
+			return err
+		}
+		post.Resources = resources
+		imp.Posts = append(imp.Posts, *post)
+		return nil
+	})
+}
+
+// importJekyll reads a Jekyll site: posts from _posts/ (and _drafts/ as
+// drafts), and static files from the site's asset directories.
+func importJekyll(srcDir string) (*siteImport, error) {
+	imp := &siteImport{}
+
+	var config struct {
+		Title     string `yaml:"title"`
+		URL       string `yaml:"url"`
+		BaseURL   string `yaml:"baseurl"`
+		Permalink string `yaml:"permalink"`
+	}
+	if data, err := os.ReadFile(filepath.Join(srcDir, "_config.yml")); err == nil { // #nosec G304 -- reading the site being imported
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("parsing _config.yml: %w", err)
+		}
+	}
+	imp.Title = config.Title
+	imp.BaseURL = strings.TrimRight(config.URL, "/") + config.BaseURL
+
+	postsDir := filepath.Join(srcDir, "_posts")
+	if !isDir(postsDir) {
+		return nil, fmt.Errorf("%s not found", postsDir)
+	}
+	for _, dir := range []string{postsDir, filepath.Join(srcDir, "_drafts")} {
+		if !isDir(dir) {
+			continue
+		}
+		drafts := filepath.Base(dir) == "_drafts"
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !isMarkdown(p) {
+				return err
+			}
+			slug := strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))
+			if !filenameDateRe.MatchString(slug) && !drafts {
+				imp.warnf("%s skipped: Jekyll posts are named YYYY-MM-DD-title", p)
+				return nil
+			}
+			post, err := importPostFile(imp, p, filenameDateRe.ReplaceAllString(slug, ""), jekyllFields)
+			if err != nil {
+				return err
+			}
+			if post.Frontmatter.Date.IsZero() {
+				post.Frontmatter.Date, _ = time.Parse("2006-01-02", slug[:min(10, len(slug))])
+			}
+			post.Frontmatter.Draft = post.Frontmatter.Draft || drafts
+			imp.Posts = append(imp.Posts, *post)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Jekyll's built-in permalink styles, and its default ("date")
+	styles := map[string]string{
+		"":        "/:categories/:year/:month/:day/:title:output_ext",
+		"date":    "/:categories/:year/:month/:day/:title:output_ext",
+		"pretty":  "/:categories/:year/:month/:day/:title/",
+		"ordinal": "/:categories/:year/:y_day/:title:output_ext",
+		"none":    "/:categories/:title:output_ext",
+	}
+	pattern := config.Permalink
+	if style, ok := styles[pattern]; ok {
+		pattern = style
+	}
+	imp.Permalink = translatePermalink(imp, pattern, map[string]string{
+		":title": ":slug", ":output_ext": ".html", ":i_month": ":month", ":i_day": ":day",
+	})
+
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() && !strings.HasPrefix(name, "_") && !strings.HasPrefix(name, ".") &&
+			name != "vendor" && name != "node_modules" {
+			imp.Static = append(imp.Static, filepath.Join(srcDir, name))
+		}
+	}
+	return imp, nil
+}
+
+// frontmatterFields maps this generator's frontmatter fields to the keys
+// another generator may use for them, in order of preference. Keys in drop
+// have no meaning here and are discarded.
+type frontmatterFields struct {
+	Title, Date, Description, Tags, Draft, Slug, Series []string
+	Lang, TranslationKey                                []string
+	Published                                           string // Key meaning the inverse of draft (Jekyll)
+	Drop                                                []string
+}
+
+var (
+	hugoFields = frontmatterFields{
+		Title:          []string{"title"},
+		Date:           []string{"date", "publishDate"},
+		Description:    []string{"description", "summary"},
+		Tags:           []string{"tags"},
+		Draft:          []string{"draft"},
+		Slug:           []string{"slug"},
+		Series:         []string{"series"},
+		Lang:           []string{"lang"},
+		TranslationKey: []string{"translationKey"},
+		Drop:           []string{"layout", "type", "lastmod"},
+	}
+	jekyllFields = frontmatterFields{
+		Title:          []string{"title"},
+		Date:           []string{"date"},
+		Description:    []string{"description", "excerpt"},
+		Tags:           []string{"tags", "tag"},
+		Draft:          []string{"draft"},
+		Slug:           []string{"slug"},
+		Series:         []string{"series"},
+		Lang:           []string{"lang"},
+		TranslationKey: []string{"translationKey", "lang_ref"},
+		Published:      "published",
+		Drop:           []string{"layout"},
+	}
+)
+
+// filenameDateRe matches the date prefix of a post filename.
+var filenameDateRe = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-`)
+
+// permalinkTokenRe matches a permalink placeholder, and repeatedSlashRe the
+// empty path segments left when one is dropped.
+var (
+	permalinkTokenRe = regexp.MustCompile(`:[a-z_]+`)
+	repeatedSlashRe  = regexp.MustCompile(`/{2,}`)
+)
+
+// templateTagRe matches Liquid tags and Hugo shortcodes, which this
+// generator doesn't process.
+var templateTagRe = regexp.MustCompile(`\{%|\{\{[<%]|\{\{\s*(site|page)\.`)
+
+// importPostFile reads a post written for another generator and maps its
+// frontmatter using fields. slug is the post's slug unless the frontmatter
+// sets one.
+func importPostFile(imp *siteImport, p, slug string, fields frontmatterFields) (*importedPost, error) {
+	content, err := os.ReadFile(p) // #nosec G304 -- reading the site being imported
+	if err != nil {
+		return nil, err
+	}
+	raw, body, err := splitFrontmatter(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+
+	fm := parser.Frontmatter{Slug: slug, Params: map[string]any{}}
+	take := func(keys []string) (any, bool) {
+		for _, k := range keys {
+			if v, ok := raw[k]; ok {
+				for _, k := range keys {
+					delete(raw, k)
+				}
+				return v, true
+			}
+		}
+		return nil, false
+	}
+	if v, ok := take(fields.Title); ok {
+		fm.Title = fmt.Sprint(v)
+	}
+	if v, ok := take(fields.Date); ok {
+		if fm.Date, err = importDate(v); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	if v, ok := take(fields.Description); ok {
+		fm.Description = strings.TrimSpace(fmt.Sprint(v))
+	}
+	if v, ok := take(fields.Tags); ok {
+		fm.Tags = importStrings(v)
+	}
+	if v, ok := take(fields.Draft); ok {
+		fm.Draft = v == true
+	}
+	if v, ok := take(fields.Series); ok {
+		// Hugo's series taxonomy is a list, but a post is in one series here
+		series, isList := v.([]any)
+		if !isList {
+			series = []any{v}
+		}
+		if len(series) > 0 {
+			fm.Series = fmt.Sprint(series[0])
+		}
+		if len(series) > 1 {
+			imp.warnf("%s is in %d series; only %s is kept", p, len(series), fm.Series)
+		}
+	}
+	if v, ok := take(fields.Lang); ok {
+		fm.Lang = fmt.Sprint(v)
+	}
+	if v, ok := take(fields.TranslationKey); ok {
+		fm.TranslationKey = fmt.Sprint(v)
+	}
+	slugSet := false
+	if v, ok := take(fields.Slug); ok {
+		fm.Slug, slugSet = fmt.Sprint(v), true
+	}
+	if fields.Published != "" {
+		if v, ok := take([]string{fields.Published}); ok && v == false {
+			fm.Draft = true
+		}
+	}
+	for _, k := range fields.Drop {
+		delete(raw, k)
+	}
+	for k, v := range raw {
+		fm.Params[k] = v
+	}
+	if len(fm.Params) == 0 {
+		fm.Params = nil
+	}
+	if _, ok := fm.Params["permalink"]; ok {
+		imp.warnf("%s sets its own permalink, which isn't preserved", p)
+	}
+	if _, ok := fm.Params["url"]; ok {
+		imp.warnf("%s sets its own url, which isn't preserved", p)
+	}
+	if templateTagRe.Match(body) {
+		imp.warnf("%s uses Liquid tags or shortcodes, which need converting by hand", p)
+	}
+
+	return &importedPost{Source: p, Frontmatter: fm, Body: body, SlugSet: slugSet}, nil
+}
+
+// splitFrontmatter separates a file's frontmatter from its body. YAML (---),
+// TOML (+++), and JSON ({...}) frontmatter are supported.
+func splitFrontmatter(content []byte) (map[string]any, []byte, error) {
+	raw := map[string]any{}
+	trimmed := bytes.TrimPrefix(content, []byte("\uFEFF"))
+
+	for _, delim := range []string{"---", "+++"} {
+		if !bytes.HasPrefix(trimmed, []byte(delim)) {
+			continue
+		}
+		parts := bytes.SplitN(trimmed, []byte(delim), 3)
+		if len(parts) < 3 {
+			return nil, nil, fmt.Errorf("unterminated frontmatter")
+		}
+		var err error
+		if delim == "+++" {
+			err = toml.Unmarshal(parts[1], &raw)
+		} else {
+			err = yaml.Unmarshal(parts[1], &raw)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing frontmatter: %w", err)
+		}
+		return raw, bytes.TrimSpace(parts[2]), nil
+	}
+
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, fmt.Errorf("parsing frontmatter: %w", err)
+		}
+		return raw, bytes.TrimSpace(trimmed[dec.InputOffset():]), nil
+	}
+
+	return raw, bytes.TrimSpace(trimmed), nil
+}
+
+// importDate converts a frontmatter date, which may already be a time or a
+// string in one of the formats Hugo and Jekyll accept.
+func importDate(v any) (time.Time, error) {
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+	s := strings.TrimSpace(fmt.Sprint(v))
+	for _, layout := range []string{
+		time.RFC3339, "2006-01-02 15:04:05 -0700", "2006-01-02 15:04:05 -07:00",
+		"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02",
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
+
+// importStrings converts a frontmatter list, or a space-separated string as
+// Jekyll allows for tags, to a string slice.
+func importStrings(v any) []string {
+	switch v := v.(type) {
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, fmt.Sprint(item))
+		}
+		return out
+	case string:
+		return strings.Fields(v)
+	}
+	return nil
+}
+
+// translatePermalink converts another generator's permalink pattern using
+// tokens (old token → replacement), keeping :year, :month, :day, and :slug.
+// Unsupported tokens are dropped with a warning.
+func translatePermalink(imp *siteImport, pattern string, tokens map[string]string) string {
+	// Replace longer tokens first so :title doesn't match inside :titles.
+	names := make([]string, 0, len(tokens))
+	for name := range tokens {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	for _, name := range names {
+		pattern = strings.ReplaceAll(pattern, name, tokens[name])
+	}
+
+	pattern = permalinkTokenRe.ReplaceAllStringFunc(pattern, func(tok string) string {
+		switch tok {
+		case ":year", ":month", ":day", ":slug":
+			return tok
+		}
+		imp.warnf("permalink token %s isn't supported and was dropped; check the old URLs still resolve", tok)
+		return ""
+	})
+	pattern = "/" + strings.TrimLeft(repeatedSlashRe.ReplaceAllString(pattern, "/"), "/")
+	if !strings.Contains(pattern, ":slug") {
+		imp.warnf("permalink %q has no slug; using the default", pattern)
+		return ""
+	}
+	return pattern
+}
+
+// writeImport writes an imported site into the current directory: posts in
+// content/posts/, static files in static/, and config.yaml if there is none.
+// Nothing is written if a post would overwrite an existing file.
+func writeImport(imp *siteImport) error {
+	postsDir := filepath.Join("content", "posts")
+	files := make([]string, len(imp.Posts))
+	seen := make(map[string]string)
+	for i, post := range imp.Posts {
+		name := post.Frontmatter.Slug + ".md"
+		if !post.Frontmatter.Date.IsZero() {
+			name = post.Frontmatter.Date.Format("2006-01-02") + "-" + name
+		}
+		files[i] = filepath.Join(postsDir, name)
+		if other, ok := seen[files[i]]; ok {
+			return fmt.Errorf("%s and %s would both be written to %s", other, post.Source, files[i])
+		}
+		seen[files[i]] = post.Source
+		if _, err := os.Stat(files[i]); err == nil {
+			return fmt.Errorf("%s already exists", files[i])
+		}
+	}
+
+	if err := os.MkdirAll(postsDir, 0750); err != nil {
+		return err
+	}
+	for i, post := range imp.Posts {
+		fm := post.Frontmatter
+		fm.Slug = "" // The filename carries the slug
+		header, err := yaml.Marshal(fm)
+		if err != nil {
+			return fmt.Errorf("encoding frontmatter of %s: %w", post.Source, err)
+		}
+		content := "---\n" + string(header) + "---\n\n" + string(post.Body) + "\n"
+		if err := os.WriteFile(files[i], []byte(content), 0600); err != nil {
+			return err
+		}
+		if err := copyResources(post, imp.Permalink); err != nil {
+			return err
+		}
+	}
+
+	for _, dir := range imp.Static {
+		if err := copyImportedStatic(imp, dir); err != nil {
+			return fmt.Errorf("copying %s: %w", dir, err)
+		}
+	}
+
+	if _, err := os.Stat("config.yaml"); os.IsNotExist(err) {
+		data, err := yaml.Marshal(struct {
+			Title     string `yaml:"title"`
+			BaseURL   string `yaml:"baseUrl,omitempty"`
+			Permalink string `yaml:"permalink,omitempty"`
+		}{imp.Title, imp.BaseURL, imp.Permalink})
+		if err != nil {
+			return err
+		}
+		return os.WriteFile("config.yaml", data, 0600)
+	}
+	if imp.Permalink != "" {
+		fmt.Printf("Set permalink: %s in config.yaml to keep the old post URLs\n", imp.Permalink)
+	}
+	return nil
+}
+
+// copyResources copies a page bundle's files into static/, in the directory
+// the post is published in, so relative links from the post still resolve.
+func copyResources(post importedPost, permalink string) error {
+	if len(post.Resources) == 0 {
+		return nil
+	}
+	p := &parser.Post{Slug: post.Frontmatter.Slug, Date: post.Frontmatter.Date}
+	if err := assignPostURLs([]*parser.Post{p}, permalink, URLStyleSlash); err != nil {
+		return err
+	}
+	dir := p.URL
+	if !strings.HasSuffix(dir, "/") {
+		dir = path.Dir(dir)
+	}
+
+	bundle := filepath.Dir(post.Source)
+	for _, res := range post.Resources {
+		rel, err := filepath.Rel(bundle, res)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(res) // #nosec G304 -- reading the site being imported
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join("static", filepath.FromSlash(strings.Trim(dir, "/")), rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyImportedStatic copies a directory of the imported site into static/:
+// Hugo's static/ itself, or a Jekyll directory like assets/ as static/assets/.
+// Files starting with frontmatter are templates the old generator processed,
+// so they're skipped with a warning.
+func copyImportedStatic(imp *siteImport, dir string) error {
+	dst := filepath.Join("static", filepath.Base(dir))
+	if filepath.Base(dir) == "static" {
+		dst = "static"
+	}
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p) // #nosec G304 -- reading the site being imported
+		if err != nil {
+			return err
+		}
+		if bytes.HasPrefix(data, []byte("---")) {
+			imp.warnf("%s not copied: it's a template processed by the old generator", p)
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0600)
+	})
+}
+
+// decodeByExt decodes a TOML, YAML, or JSON config file by its extension.
+func decodeByExt(name string, data []byte, v any) error {
+	switch filepath.Ext(name) {
+	case ".toml":
+		return toml.Unmarshal(data, v)
+	case ".json":
+		return json.Unmarshal(data, v)
+	default:
+		return yaml.Unmarshal(data, v)
+	}
+}
+
+// isMarkdown reports whether path has a markdown extension.
+func isMarkdown(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// isDir reports whether path is an existing directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,155 @@
+package ssg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestImport_Hugo tests importing TOML posts, page bundles, static files, and permalinks
+func TestImport_Hugo(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"hugo.toml":                      "title = \"Old Blog\"\nbaseURL = \"https://old.example.com/\"\n[permalinks]\nposts = \"/:year/:month/:title/\"\n",
+		"content/posts/first.md":         "+++\ntitle = \"First Post\"\ndate = 2024-01-15T10:00:00Z\nsummary = \"The first one\"\ntags = [\"go\", \"web\"]\nseries = \"intro\"\nlayout = \"single\"\n+++\n\nHello {{< youtube abc >}}\n",
+		"content/posts/bundle/index.md":  "---\ntitle: Bundled\ndate: 2024-02-01T00:00:00Z\ndraft: true\n---\n\n![cover](cover.jpg)\n",
+		"content/posts/bundle/cover.jpg": "jpeg",
+		"content/about.md":               "---\ntitle: About\n---\n",
+		"static/favicon.ico":             "icon",
+	})
+	dst := t.TempDir()
+	t.Chdir(dst)
+
+	if err := Import("hugo", src); err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+
+	post, err := parser.New().ParseFile(filepath.Join("content", "posts", "2024-01-15-first-post.md"))
+	if err != nil {
+		t.Fatalf("imported post not readable: %v", err)
+	}
+	if post.Title != "First Post" || post.Description != "The first one" || len(post.Tags) != 2 {
+		t.Errorf("imported post = %+v", post)
+	}
+	if !post.Date.Equal(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v", post.Date)
+	}
+	if post.Series != "intro" || post.Params["layout"] != nil {
+		t.Errorf("Series = %q, Params = %v, want series kept and layout dropped", post.Series, post.Params)
+	}
+
+	bundle, err := parser.New().ParseFile(filepath.Join("content", "posts", "2024-02-01-bundled.md"))
+	if err != nil {
+		t.Fatalf("bundle post not readable: %v", err)
+	}
+	if !bundle.Draft {
+		t.Error("bundle post lost draft: true")
+	}
+
+	for _, name := range []string{"static/favicon.ico", "static/2024/02/bundled/cover.jpg"} {
+		if _, err := os.Stat(filepath.FromSlash(name)); err != nil {
+			t.Errorf("%s not copied: %v", name, err)
+		}
+	}
+
+	config, err := LoadConfig("config.yaml")
+	if err != nil {
+		t.Fatalf("config.yaml not written: %v", err)
+	}
+	if config.Title != "Old Blog" || config.BaseURL != "https://old.example.com/" || config.Permalink != "/:year/:month/:slug/" {
+		t.Errorf("config = %+v", config)
+	}
+}
+
+// TestImport_Jekyll tests importing Jekyll posts, drafts, assets, and permalink styles
+func TestImport_Jekyll(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"_config.yml":                            "title: Jekyll Blog\nurl: https://jekyll.example.com\npermalink: pretty\n",
+		"_posts/2023-05-01-hello-world.markdown": "---\nlayout: post\ntitle: Hello\ndate: 2023-05-01 08:30:00 +0200\ntags: ruby jekyll\nexcerpt: Says hello\n---\n\n{% highlight ruby %}\nputs 1\n{% endhighlight %}\n",
+		"_posts/2023-06-01-hidden.md":            "---\ntitle: Hidden\npublished: false\n---\n\nNot yet.\n",
+		"_drafts/idea.md":                        "---\ntitle: Idea\n---\n\nMaybe.\n",
+		"assets/css/main.css":                    "body {}",
+		"assets/css/style.scss":                  "---\n---\n@import 'main';",
+		"_site/index.html":                       "built",
+	})
+	dst := t.TempDir()
+	t.Chdir(dst)
+
+	if err := Import("jekyll", src); err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+
+	post, err := parser.New().ParseFile(filepath.Join("content", "posts", "2023-05-01-hello-world.md"))
+	if err != nil {
+		t.Fatalf("imported post not readable: %v", err)
+	}
+	if post.Title != "Hello" || post.Description != "Says hello" || strings.Join(post.Tags, ",") != "ruby,jekyll" {
+		t.Errorf("imported post = %+v", post)
+	}
+	if post.Date.UTC().Hour() != 6 {
+		t.Errorf("Date = %v, want the offset preserved", post.Date)
+	}
+
+	hidden, err := parser.New().ParseFile(filepath.Join("content", "posts", "2023-06-01-hidden.md"))
+	if err != nil || !hidden.Draft {
+		t.Errorf("published: false post should be a draft (err = %v)", err)
+	}
+	idea, err := parser.New().ParseFile(filepath.Join("content", "posts", "idea.md"))
+	if err != nil || !idea.Draft {
+		t.Errorf("_drafts post should be a draft (err = %v)", err)
+	}
+
+	if _, err := os.Stat(filepath.Join("static", "assets", "css", "main.css")); err != nil {
+		t.Errorf("asset not copied: %v", err)
+	}
+	for _, name := range []string{"static/assets/css/style.scss", "static/_site/index.html"} {
+		if _, err := os.Stat(filepath.FromSlash(name)); !os.IsNotExist(err) {
+			t.Errorf("%s copied, want it skipped", name)
+		}
+	}
+
+	config, err := LoadConfig("config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Permalink != "/:year/:month/:day/:slug/" || config.BaseURL != "https://jekyll.example.com" {
+		t.Errorf("config = %+v", config)
+	}
+}
+
+// TestImport_NoOverwrite tests that importing refuses to overwrite existing posts
+func TestImport_NoOverwrite(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"_posts/2023-05-01-hello.md": "---\ntitle: Hello\n---\n\nNew.\n",
+	})
+	dst := t.TempDir()
+	writeFiles(t, dst, map[string]string{
+		"content/posts/2023-05-01-hello.md": "---\ntitle: Mine\n---\n\nOld.\n",
+	})
+	t.Chdir(dst)
+
+	if err := Import("jekyll", src); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Import() error = %v, want an already exists error", err)
+	}
+	if err := Import("wordpress", src); err == nil {
+		t.Error("Import() from an unknown generator succeeded, want error")
+	}
+}
+
+// TestTranslatePermalink tests converting other generators' permalink tokens
+func TestTranslatePermalink(t *testing.T) {
+	imp := &siteImport{}
+	got := translatePermalink(imp, "/:categories/:year/:month/:day/:title:output_ext", map[string]string{":title": ":slug", ":output_ext": ".html"})
+	if got != "/:year/:month/:day/:slug.html" {
+		t.Errorf("translatePermalink() = %q", got)
+	}
+	if len(imp.Warnings) != 1 || !strings.Contains(imp.Warnings[0], ":categories") {
+		t.Errorf("Warnings = %v, want one about :categories", imp.Warnings)
+	}
+}
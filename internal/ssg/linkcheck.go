@@ -0,0 +1,67 @@
+package ssg
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// checkOutputLinks scans every generated HTML page in outputDir for internal
+// links (href, src, and srcset) to files that don't exist in outputDir.
+//
+// A link to a directory is satisfied by the directory's index.html, matching
+// how static hosts and `ssg serve` resolve it, and an extensionless link is
+// satisfied by the same path with ".html" added.
+//
+// Returns one problem per broken link, sorted by page, or an error if the
+// output can't be read.
+func checkOutputLinks(fsys fs.FS, outputDir string) ([]problem, error) {
+	var problems []problem
+	err := fs.WalkDir(fsys, outputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".html" {
+			return err
+		}
+
+		relPath, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool)
+		for _, link := range internalLinks("/"+filepath.ToSlash(relPath), string(content)) {
+			if seen[link] || outputExists(fsys, outputDir, link) {
+				continue
+			}
+			seen[link] = true
+			problems = append(problems, problem{File: filepath.ToSlash(relPath), Message: "broken link to " + link})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].File < problems[j].File })
+	return problems, nil
+}
+
+// outputExists reports whether a URL path resolves to a file in outputDir in
+// fsys.
+func outputExists(fsys fs.FS, outputDir, urlPath string) bool {
+	path := filepath.Join(outputDir, filepath.FromSlash(strings.TrimPrefix(urlPath, "/")))
+	info, err := fs.Stat(fsys, path)
+	if err != nil {
+		_, err = fs.Stat(fsys, path+".html")
+		return err == nil && filepath.Ext(path) == ""
+	}
+	if info.IsDir() {
+		_, err = fs.Stat(fsys, filepath.Join(path, "index.html"))
+		return err == nil
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package ssg
+
+import (
+	"context"
+	"testing"
+)
+
+// TestCheckOutputLinks tests that only links to missing output files are reported
+func TestCheckOutputLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"index.html": `<a href="/">Home</a><a href="/posts/">Posts</a><a href="posts/a.html#top">A</a>` +
+			`<link rel="stylesheet" href="/css/style.css"><a href="https://example.com/missing">Ext</a>`,
+		"posts/index.html": `<a href="a.html">A</a><a href="b.html">B</a><a href="b.html">B again</a>`,
+		"posts/a.html":     `<img src="/images/photo.jpg" srcset="/images/photo-480w.jpg 480w, /images/photo.jpg 960w">`,
+		"css/style.css":    "body {}",
+		"images/photo.jpg": "jpeg",
+	})
+
+	problems, err := checkOutputLinks(DirFS("."), tmpDir)
+	if err != nil {
+		t.Fatalf("checkOutputLinks() failed: %v", err)
+	}
+
+	want := []problem{
+		{File: "posts/a.html", Message: "broken link to /images/photo-480w.jpg"},
+		{File: "posts/index.html", Message: "broken link to /posts/b.html"},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("got %v, want %v", problems, want)
+	}
+	for i := range want {
+		if problems[i] != want[i] {
+			t.Errorf("problem %d = %v, want %v", i, problems[i], want[i])
+		}
+	}
+}
+
+// TestBuild_Strict tests that strict builds fail on broken links
+func TestBuild_Strict(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["content/posts/2024-02-01-second.md"] = "---\ntitle: Second\ndate: 2024-02-01T10:00:00Z\n---\n\n[Gone](/posts/gone.html)\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Errorf("Build() failed without strict: %v", err)
+	}
+	if err := Build(context.Background(), BuildOptions{Strict: true}); err == nil {
+		t.Error("Build() with strict succeeded despite a broken link")
+	}
+}
@@ -0,0 +1,102 @@
+package ssg
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// liveSite serves the dev server's pages by rendering them from source on
+// each request (see ServeOptions.Live), so edits to templates, markdown, and
+// data files show up on the next reload without waiting for a rebuild.
+//
+// Only the requested page is rendered, into memory: the config, content,
+// and templates are read again, but site-wide files, image variants, and
+// snapshotted assets aren't written (see BuildOptions.pages). Requests for
+// anything that can't be a page (see isPagePath), like stylesheets, images,
+// and feeds, are served from the last build without rendering anything.
+// The 404 page is rendered with each page request too, for paths
+// that turn out to be missing.
+type liveSite struct {
+	opts    BuildOptions // Options pages are rendered with; Output is replaced per request
+	built   *siteHandler // Serves the last build
+	baseURL string       // Rewritten to local links in rendered pages, or "" (see localURLs)
+
+	mu sync.Mutex // Renders one page at a time, since builds share plugins and caches
+}
+
+func (s *liveSite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	urlPath := path.Clean("/" + r.URL.Path)
+	if (r.Method != http.MethodGet && r.Method != http.MethodHead) || !isPagePath(urlPath) {
+		s.built.ServeHTTP(w, r)
+		return
+	}
+
+	urls := pageCandidates(urlPath)
+	urls[notFoundURL] = true
+	out, err := s.render(r, urls)
+	if err != nil {
+		slog.Error("rendering page", "url", urlPath, "error", err)
+		http.Error(w, fmt.Sprintf("rendering %s: %v", urlPath, err), http.StatusInternalServerError)
+		return
+	}
+	rendered, err := subFS(out, s.opts.OutputDir)
+	if err != nil {
+		s.built.ServeHTTP(w, r)
+		return
+	}
+	h := newFSHandler(rendered, false, s.baseURL)
+	if h.serve(w, r) || s.built.serve(w, r) {
+		return
+	}
+	if _, err := fs.Stat(rendered, "404.html"); err == nil {
+		h.notFound(w, r)
+		return
+	}
+	s.built.notFound(w, r)
+}
+
+// render builds the pages at urls into a new MemFS.
+func (s *liveSite) render(r *http.Request, urls map[string]bool) (*MemFS, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := &MemFS{}
+	opts := s.opts
+	opts.Output = out
+	opts.pages = urls
+	if err := generate(r.Context(), opts); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// isPagePath reports whether a request for urlPath (cleaned) may be for a
+// page: one ending in ".html" or without an extension, as every URL style
+// writes them (see SiteConfig.URLs).
+func isPagePath(urlPath string) bool {
+	ext := path.Ext(urlPath)
+	return ext == "" || ext == ".html"
+}
+
+// pageCandidates returns the page URLs a request for urlPath (cleaned, as
+// siteHandler resolves it) may be served from: the path itself, the same
+// path as a directory or with ".html" added, and for ".../index.html" the
+// directory, since a site's URL style decides which of them its pages use.
+func pageCandidates(urlPath string) map[string]bool {
+	urls := map[string]bool{urlPath: true}
+	if urlPath == "/" {
+		urls["/index.html"] = true
+		return urls
+	}
+	urls[urlPath+"/"] = true
+	urls[urlPath+".html"] = true
+	if dir, ok := strings.CutSuffix(urlPath, "/index.html"); ok {
+		urls[dir+"/"] = true
+	}
+	return urls
+}
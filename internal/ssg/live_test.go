@@ -0,0 +1,153 @@
+package ssg
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestLiveSite tests that pages are rendered from the current sources while
+// other files are served from the last build
+func TestLiveSite(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := testSite()
+	files["static/css/style.css"] = "body { margin: 0; }"
+	writeFiles(t, tmpDir, files)
+	t.Chdir(tmpDir)
+
+	opts := ServeOptions{}.withDefaults()
+	if err := Build(context.Background(), opts.buildOptions()); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	site := &liveSite{
+		opts:  opts.buildOptions().withDefaults(),
+		built: newSiteHandler(opts.OutputDir, false, ""),
+	}
+
+	writeFiles(t, tmpDir, map[string]string{
+		"content/posts/2024-01-15-first.md": "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\nEdited after the build.\n",
+		"templates/post.html":               "{{define \"posts\"}}<main>{{.Post.Content}}</main>{{end}}",
+	})
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{path: "/posts/first.html", wantStatus: 200, wantBody: "<main><p>Edited after the build.</p>"},
+		{path: "/posts/first", wantStatus: 200, wantBody: "Edited after the build."},
+		{path: "/", wantStatus: 200, wantBody: "First Post"},
+		{path: "/css/style.css", wantStatus: 200, wantBody: "margin: 0"},
+		{path: "/missing", wantStatus: 404},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		site.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("GET %s status = %d, want %d", tt.path, rec.Code, tt.wantStatus)
+		}
+		if !strings.Contains(rec.Body.String(), tt.wantBody) {
+			t.Errorf("GET %s body = %q, want it to contain %q", tt.path, rec.Body.String(), tt.wantBody)
+		}
+	}
+
+	built, err := os.ReadFile("public/posts/first.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(built), "Edited") {
+		t.Error("rendering a page on request changed the built output")
+	}
+}
+
+// TestLiveSite_NotFound tests that missing paths get the 404 page rendered
+// from the current template
+func TestLiveSite_NotFound(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := testSite()
+	files["templates/404.html"] = "{{define \"posts\"}}Old 404{{end}}"
+	writeFiles(t, tmpDir, files)
+	t.Chdir(tmpDir)
+
+	opts := ServeOptions{}.withDefaults()
+	if err := Build(context.Background(), opts.buildOptions()); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	site := &liveSite{
+		opts:  opts.buildOptions().withDefaults(),
+		built: newSiteHandler(opts.OutputDir, false, ""),
+	}
+	writeFiles(t, tmpDir, map[string]string{"templates/404.html": "{{define \"posts\"}}New 404{{end}}"})
+
+	rec := httptest.NewRecorder()
+	site.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "New 404") {
+		t.Errorf("body = %q, want the 404 page rendered from the current template", rec.Body.String())
+	}
+}
+
+// TestLiveSite_Error tests that a page that fails to render is reported,
+// while other files are still served without rendering
+func TestLiveSite_Error(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := testSite()
+	files["static/css/style.css"] = "body { margin: 0; }"
+	writeFiles(t, tmpDir, files)
+	t.Chdir(tmpDir)
+
+	opts := ServeOptions{}.withDefaults()
+	if err := Build(context.Background(), opts.buildOptions()); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	site := &liveSite{
+		opts:  opts.buildOptions().withDefaults(),
+		built: newSiteHandler(opts.OutputDir, false, ""),
+	}
+	writeFiles(t, tmpDir, map[string]string{"templates/post.html": "{{define \"posts\"}}{{.Post.Missing}}{{end}}"})
+
+	rec := httptest.NewRecorder()
+	site.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/posts/first.html", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	site.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/css/style.css", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "margin: 0") {
+		t.Errorf("GET /css/style.css = %d %q, want it served from the last build", rec.Code, rec.Body.String())
+	}
+}
+
+// TestPageCandidates tests the page URLs a request may be served from
+func TestPageCandidates(t *testing.T) {
+	got := pageCandidates("/notes/index.html")
+	for _, want := range []string{"/notes/index.html", "/notes/"} {
+		if !got[want] {
+			t.Errorf("pageCandidates(/notes/index.html) = %v, missing %s", got, want)
+		}
+	}
+	if got := pageCandidates("/"); !got["/"] || !got["/index.html"] {
+		t.Errorf("pageCandidates(/) = %v", got)
+	}
+}
+
+// TestIsPagePath tests telling page requests from requests for other files
+func TestIsPagePath(t *testing.T) {
+	for urlPath, want := range map[string]bool{
+		"/":                 true,
+		"/posts/first":      true,
+		"/posts/first.html": true,
+		"/css/style.css":    false,
+		"/feed.xml":         false,
+	} {
+		if got := isPagePath(urlPath); got != want {
+			t.Errorf("isPagePath(%q) = %v, want %v", urlPath, got, want)
+		}
+	}
+}
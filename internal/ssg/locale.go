@@ -0,0 +1,96 @@
+package ssg
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations maps a language to the letters it spells out differently
+// in ASCII than the accent-stripping fallback would: German writes ü as ue,
+// Danish and Norwegian write å as aa, and so on.
+var transliterations = map[string]map[rune]string{
+	"de": {'ä': "ae", 'ö': "oe", 'ü': "ue", 'ß': "ss"},
+	"da": {'æ': "ae", 'ø': "oe", 'å': "aa"},
+	"nb": {'æ': "ae", 'ø': "oe", 'å': "aa"},
+	"nn": {'æ': "ae", 'ø': "oe", 'å': "aa"},
+	"no": {'æ': "ae", 'ø': "oe", 'å': "aa"},
+	"sv": {'å': "a", 'ä': "a", 'ö': "o"},
+	"fi": {'å': "a", 'ä': "a", 'ö': "o"},
+}
+
+// baseTransliterations spells out letters that don't decompose into an ASCII
+// letter and an accent, for every language.
+var baseTransliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'ø': "o", 'œ': "oe", 'ð': "d", 'þ': "th",
+	'đ': "d", 'ł': "l", 'ı': "i",
+}
+
+// stripMarks removes accents by decomposing letters and dropping the
+// combining marks (é → e).
+var stripMarks = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// transliterate returns s lowercased and spelled in ASCII letters where
+// possible, following the conventions of lang (a BCP 47 tag like "de").
+// Letters with no ASCII spelling are kept.
+func transliterate(lang, s string) string {
+	base, _ := language.Make(lang).Base()
+	table := transliterations[base.String()]
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if t, ok := table[r]; ok {
+			b.WriteString(t)
+		} else if t, ok := baseTransliterations[r]; ok {
+			b.WriteString(t)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	out, _, err := transform.String(stripMarks, b.String())
+	if err != nil {
+		return b.String()
+	}
+	return out
+}
+
+// slugifyLang converts text to a URL slug like slugify, transliterating
+// letters following the conventions of lang first, so "Über Straßen" becomes
+// "ueber-strassen" in German rather than "ber-straen".
+func slugifyLang(lang, s string) string {
+	return slugify(transliterate(lang, s))
+}
+
+// newCollator returns a collator ordering strings by the rules of lang, so
+// "Äpfel" sorts with the A's in German but after Z in Swedish. An empty or
+// unknown lang uses the language-neutral root order.
+func newCollator(lang string) *collate.Collator {
+	return collate.New(language.Make(lang), collate.IgnoreCase)
+}
+
+// sortStrings returns a copy of items sorted by the collation rules of lang.
+func sortStrings(lang string, items []string) []string {
+	sorted := slices.Clone(items)
+	c := newCollator(lang)
+	slices.SortStableFunc(sorted, c.CompareString)
+	return sorted
+}
+
+// sortByTitle returns a copy of posts sorted by title using the collation
+// rules of lang.
+func sortByTitle(lang string, posts []*parser.Post) []*parser.Post {
+	sorted := slices.Clone(posts)
+	c := newCollator(lang)
+	slices.SortStableFunc(sorted, func(a, b *parser.Post) int {
+		return c.CompareString(a.Title, b.Title)
+	})
+	return sorted
+}
@@ -0,0 +1,56 @@
+package ssg
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestSlugifyLang tests language-aware transliteration in slugs
+func TestSlugifyLang(t *testing.T) {
+	tests := []struct {
+		lang, in, want string
+	}{
+		{"de", "Über Straßen", "ueber-strassen"},
+		{"", "Über Straßen", "uber-strassen"},
+		{"da", "Blåbær på Øen", "blaabaer-paa-oeen"},
+		{"sv", "Smörgåsbord", "smorgasbord"},
+		{"fr", "Café crème", "cafe-creme"},
+		{"de-AT", "Grüße", "gruesse"},
+		{"", "Hello, World 2024", "hello-world-2024"},
+	}
+	for _, tt := range tests {
+		if got := slugifyLang(tt.lang, tt.in); got != tt.want {
+			t.Errorf("slugifyLang(%q, %q) = %q, want %q", tt.lang, tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestSortStrings tests collation following the language's rules
+func TestSortStrings(t *testing.T) {
+	words := []string{"Zebra", "Äpfel", "apple", "Banana"}
+
+	if got, want := sortStrings("de", words), []string{"Äpfel", "apple", "Banana", "Zebra"}; !slices.Equal(got, want) {
+		t.Errorf("sortStrings(de) = %v, want %v", got, want)
+	}
+	if got, want := sortStrings("sv", words), []string{"apple", "Banana", "Zebra", "Äpfel"}; !slices.Equal(got, want) {
+		t.Errorf("sortStrings(sv) = %v, want %v", got, want)
+	}
+	if words[0] != "Zebra" {
+		t.Error("sortStrings() modified its input")
+	}
+}
+
+// TestSortByTitle tests sorting posts by title
+func TestSortByTitle(t *testing.T) {
+	posts := []*parser.Post{{Title: "Øl"}, {Title: "Ost"}, {Title: "Zebra"}}
+
+	var got []string
+	for _, p := range sortByTitle("da", posts) {
+		got = append(got, p.Title)
+	}
+	if want := []string{"Ost", "Zebra", "Øl"}; !slices.Equal(got, want) {
+		t.Errorf("sortByTitle(da) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+package ssg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log formats accepted by NewLogger.
+const (
+	LogFormatText = "text" // One line per message, for terminals
+	LogFormatJSON = "json" // One JSON object per message, for CI systems and log collectors
+)
+
+// NewLogger returns a logger writing messages of level and above to w in
+// format. Builds log through slog's default logger, so programs set it with
+// slog.SetDefault(logger). Build progress is logged at info level, each
+// file written and how long each build stage took at debug level, and
+// problems that don't stop the build at warn level:
+//
+//	slog.SetDefault(ssg.NewLogger(os.Stderr, slog.LevelDebug, ssg.LogFormatText)) // --verbose
+//	slog.SetDefault(ssg.NewLogger(os.Stderr, slog.LevelWarn, ssg.LogFormatText))  // --quiet
+//
+// Returns an error if format is neither LogFormatText nor LogFormatJSON.
+func NewLogger(w io.Writer, level slog.Level, format string) (*slog.Logger, error) {
+	switch format {
+	case LogFormatText, "":
+		return slog.New(&textHandler{w: w, level: level, mu: &sync.Mutex{}}), nil
+	case LogFormatJSON:
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (want text or json)", format)
+	}
+}
+
+// textHandler writes messages the way the generator always printed them:
+// the message, prefixed with "Warning:" or "Error:" if it's one, followed by
+// its attributes as key=value pairs. There are no timestamps or levels, so
+// the output reads like a command's, not a server's.
+type textHandler struct {
+	w      io.Writer
+	level  slog.Level
+	attrs  string // Attributes added with WithAttrs, formatted
+	prefix string // Group prefix for keys, e.g. "remote."
+	mu     *sync.Mutex
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *textHandler) Handle(_ context.Context, rec slog.Record) error {
+	var b strings.Builder
+	switch {
+	case rec.Level >= slog.LevelError:
+		b.WriteString("Error: ")
+	case rec.Level >= slog.LevelWarn:
+		b.WriteString("Warning: ")
+	}
+	b.WriteString(rec.Message)
+	b.WriteString(h.attrs)
+	rec.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.prefix, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	for _, a := range attrs {
+		writeAttr(&b, h.prefix, a)
+	}
+	h2 := *h
+	h2.attrs += b.String()
+	return &h2
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.prefix += name + "."
+	return &h2
+}
+
+// writeAttr writes a as " key=value" to b, quoting values with spaces or
+// quotes in them, and flattening groups into dotted keys.
+func writeAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			writeAttr(b, prefix+a.Key+".", ga)
+		}
+		return
+	}
+	value := a.Value.String()
+	if a.Value.Kind() == slog.KindDuration {
+		value = a.Value.Duration().Round(time.Millisecond).String()
+	}
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	fmt.Fprintf(b, " %s%s=%s", prefix, a.Key, value)
+}
+
+// stageTimer logs how long each stage of a build took, at debug level, and
+// records it in the build's profile, if it has one.
+type stageTimer struct {
+	last    time.Time
+	profile *buildProfile
+}
+
+// newStageTimer returns a timer for stages starting now, recording them in
+// profile (which may be nil).
+func newStageTimer(profile *buildProfile) *stageTimer {
+	return &stageTimer{last: time.Now(), profile: profile}
+}
+
+// done logs the time since the previous stage finished (or the timer was
+// created) as the duration of stage.
+func (t *stageTimer) done(stage string) {
+	now := time.Now()
+	slog.Debug("Finished stage", "stage", stage, "duration", now.Sub(t.last))
+	t.profile.addStage(stage, now.Sub(t.last))
+	t.last = now
+}
@@ -0,0 +1,106 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setLogger makes a logger writing to a buffer the default for the rest of
+// the test.
+func setLogger(t *testing.T, level slog.Level, format string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, level, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+// TestNewLogger_Text tests the text format: prefixes for problems, quoted attributes, and levels
+func TestNewLogger_Text(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, slog.LevelInfo, LogFormatText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("Built site", "posts", 3, "duration", 1234567*time.Nanosecond)
+	logger.With("url", "https://x.test").Warn("fetching remote data", "error", errors.New("connection refused"))
+	logger.WithGroup("remote").Error("failed", "code", 500)
+	logger.Debug("Wrote file", "file", "public/index.html")
+
+	want := "Built site posts=3 duration=1ms\n" +
+		"Warning: fetching remote data url=https://x.test error=\"connection refused\"\n" +
+		"Error: failed remote.code=500\n"
+	if buf.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	if _, err := NewLogger(&buf, slog.LevelInfo, "xml"); err == nil {
+		t.Error("NewLogger() accepted format xml")
+	}
+}
+
+// TestBuild_Logging tests the build's info, debug, and warning messages in JSON
+func TestBuild_Logging(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["static/big.bin"] = strings.Repeat("x", 2048)
+	site["config.yaml"] += "static:\n  warnSize: 1KB\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+	buf := setLogger(t, slog.LevelDebug, LogFormatJSON)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var stages []string
+	var built, wrote, warned bool
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("line %q isn't JSON: %v", line, err)
+		}
+		switch rec["msg"] {
+		case "Finished stage":
+			stages = append(stages, rec["stage"].(string))
+		case "Built site":
+			built = rec["posts"] == float64(1)
+		case "Wrote file":
+			wrote = true
+		case "large static file":
+			warned = rec["level"] == "WARN"
+		}
+	}
+	if len(stages) == 0 || stages[0] != "config" || stages[len(stages)-1] != "permissions" {
+		t.Errorf("stages = %v, want config through permissions", stages)
+	}
+	if !built || !wrote || !warned {
+		t.Errorf("built = %v, wrote = %v, warned = %v, want all logged:\n%s", built, wrote, warned, buf)
+	}
+}
+
+// TestBuild_Quiet tests that a build at warn level logs only problems
+func TestBuild_Quiet(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+	t.Chdir(tmpDir)
+	buf := setLogger(t, slog.LevelWarn, LogFormatText)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("quiet build logged %q, want nothing", buf.String())
+	}
+}
@@ -0,0 +1,119 @@
+package ssg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// manifestPath is where Build records the manifest of the last build,
+// relative to the site root.
+var manifestPath = filepath.Join(".ssg", "manifest.json")
+
+// Manifest records the files produced by a build and their content hashes.
+type Manifest struct {
+	Generated time.Time         `json:"generated"`
+	OutputDir string            `json:"outputDir"`
+	Files     map[string]string `json:"files"` // Slash-separated path relative to OutputDir → SHA-256 hex digest
+}
+
+// buildManifest hashes every file under dir in fsys.
+//
+// Parameters:
+//   - fsys: Filesystem the build was written to
+//   - dir: Output directory of a build (e.g., "public")
+//   - preserve: Preserve patterns from the config, whose paths are left out
+//     (see preserved)
+//
+// Returns the manifest, or an error if the directory can't be walked or a
+// file can't be read.
+func buildManifest(fsys fs.FS, dir string, preserve []string) (*Manifest, error) {
+	m := &Manifest{Generated: time.Now().UTC(), OutputDir: dir, Files: make(map[string]string)}
+
+	err := fs.WalkDir(fsys, dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if relPath != "." && preserved(filepath.ToSlash(relPath), preserve) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		sum, err := hashFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		m.Files[filepath.ToSlash(relPath)] = sum
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// hashFile returns the hex-encoded SHA-256 digest of the contents of the
+// file at path in fsys.
+func hashFile(fsys fs.FS, path string) (string, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readManifest loads a manifest saved by write.
+func readManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// write saves the manifest to path in fsys as indented JSON, creating parent
+// directories as needed.
+func (m *Manifest) write(fsys FS, path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsys.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// Paths returns the manifest's file paths in sorted order.
+func (m *Manifest) Paths() []string {
+	paths := make([]string, 0, len(m.Files))
+	for p := range m.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
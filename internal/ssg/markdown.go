@@ -0,0 +1,108 @@
+package ssg
+
+import (
+	"net/url"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// MarkdownConfig configures how markdown is converted to HTML. Every
+// feature is on by default; the config turns them off or customizes them.
+//
+// Raw HTML in markdown (embeds, custom markup) passes through by default.
+// Sites with content from less trusted authors can turn it off, so raw HTML
+// is omitted from pages, and opt individual posts back in with rawHTML: true
+// in their frontmatter. A post can likewise opt out with rawHTML: false.
+//
+// Example config.yaml:
+//
+//	markdown:
+//	  extensions: [.md, .markdown]
+//	  rawHTML: false
+//	  hardWraps: false
+//	  figures: false
+//	  headingIdPrefix: h-
+//	  footnotes:
+//	    backlink: "↑"
+//	    backlinkTitle: Back to the text
+//	  externalLinks:
+//	    enabled: true
+//	    class: external
+//	  admonitions:
+//	    class: callout
+//	    titleClass: callout-title
+type MarkdownConfig struct {
+	Extensions      []string            `yaml:"extensions"`      // File extensions of markdown content (default: [.md, .markdown, .mdown])
+	RawHTML         *bool               `yaml:"rawHTML"`         // Pass raw HTML in markdown through (default: true)
+	HardWraps       *bool               `yaml:"hardWraps"`       // Turn line breaks within paragraphs into <br> (default: true)
+	Typographer     *bool               `yaml:"typographer"`     // Turn quotes, "--", and "..." into typographic ones (default: true)
+	TaskLists       *bool               `yaml:"taskLists"`       // Render "- [ ] item" as checkboxes (default: true)
+	Figures         *bool               `yaml:"figures"`         // Render an image with a title, alone in a paragraph, as a <figure> captioned with the title (default: true)
+	HeadingIDPrefix string              `yaml:"headingIdPrefix"` // Prepended to generated heading IDs
+	Footnotes       FootnotesConfig     `yaml:"footnotes"`       // Labels of footnote links
+	ExternalLinks   ExternalLinksConfig `yaml:"externalLinks"`   // Open links to other sites in a new tab
+	Admonitions     AdmonitionsConfig   `yaml:"admonitions"`     // Render "> [!NOTE]" alerts as callouts
+}
+
+// AdmonitionsConfig configures how GitHub-style alerts, blockquotes starting
+// with [!NOTE], [!TIP], [!IMPORTANT], [!WARNING], or [!CAUTION], are
+// rendered: as a <div> with the configured class and the alert's type as
+// classes (e.g. class="admonition warning"), starting with a title
+// paragraph.
+type AdmonitionsConfig struct {
+	Enabled    *bool  `yaml:"enabled"`    // Render alerts as admonitions (default: true)
+	Class      string `yaml:"class"`      // Class of admonitions, before their type (default: admonition)
+	TitleClass string `yaml:"titleClass"` // Class of their titles (default: admonition-title)
+}
+
+// ExternalLinksConfig makes markdown links to other sites open in a new tab, with
+// target="_blank" and rel="noopener noreferrer". Links to the site's
+// baseUrl and relative links are left alone, as are links written in raw
+// HTML.
+type ExternalLinksConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Class   string `yaml:"class"` // Class added to external links, e.g. for an icon (default: none)
+}
+
+// FootnotesConfig sets the labels of the links between footnote references
+// and footnotes.
+type FootnotesConfig struct {
+	Backlink      string `yaml:"backlink"`      // HTML of the link back to the text (default: "↩︎")
+	BacklinkTitle string `yaml:"backlinkTitle"` // Title of the link back to the text
+	LinkTitle     string `yaml:"linkTitle"`     // Title of the link to a footnote
+}
+
+// newParser returns a content parser configured as the site's config says,
+// for markdown and the config's other formats (see FormatsConfig).
+func newParser(config *SiteConfig) *parser.Parser {
+	c := config.Markdown
+	var siteHost string
+	if u, err := url.Parse(config.BaseURL); err == nil {
+		siteHost = u.Hostname()
+	}
+	return parser.NewWithOptions(parser.Options{
+		NoRawHTML:             isFalse(c.RawHTML),
+		NoHardWraps:           isFalse(c.HardWraps),
+		NoTypographer:         isFalse(c.Typographer),
+		NoTaskLists:           isFalse(c.TaskLists),
+		NoFigures:             isFalse(c.Figures),
+		HeadingIDPrefix:       c.HeadingIDPrefix,
+		FootnoteBacklink:      c.Footnotes.Backlink,
+		FootnoteBacklinkTitle: c.Footnotes.BacklinkTitle,
+		FootnoteLinkTitle:     c.Footnotes.LinkTitle,
+		ExternalLinks:         c.ExternalLinks.Enabled,
+		ExternalLinkClass:     c.ExternalLinks.Class,
+		SiteHost:              siteHost,
+		NoAdmonitions:         isFalse(c.Admonitions.Enabled),
+		AdmonitionClass:       c.Admonitions.Class,
+		AdmonitionTitleClass:  c.Admonitions.TitleClass,
+		NoNotebooks:           isFalse(config.Notebooks),
+		MarkdownExtensions:    c.Extensions,
+		Formats:               config.Formats.contentParsers(),
+	})
+}
+
+// isFalse reports whether an optional setting is explicitly false.
+func isFalse(b *bool) bool {
+	return b != nil && !*b
+}
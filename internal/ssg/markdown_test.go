@@ -0,0 +1,65 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_RawHTML tests that markdown.rawHTML: false omits raw HTML except
+// from posts that opt back in
+func TestBuild_RawHTML(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "markdown:\n  rawHTML: false\n"
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\n---\n\n<div class=\"embed\">First</div>\n"
+	site["content/posts/2024-01-16-second.md"] = "---\ntitle: Second Post\nrawHTML: true\n---\n\n<div class=\"embed\">Second</div>\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{OutputDir: "public"}); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	first, err := os.ReadFile(filepath.Join("public", "posts", "first.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(first), `<div class="embed">`) {
+		t.Errorf("first.html kept raw HTML with markdown.rawHTML: false:\n%s", first)
+	}
+	second, err := os.ReadFile(filepath.Join("public", "posts", "second.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(second), `<div class="embed">Second</div>`) {
+		t.Errorf("second.html dropped raw HTML despite rawHTML: true:\n%s", second)
+	}
+}
+
+// TestNewParser tests that markdown settings reach the parser
+func TestNewParser(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"config.yaml": "title: Test\nbaseUrl: https://example.com\nmarkdown:\n  externalLinks:\n    enabled: true\n  hardWraps: false\n  typographer: false\n  taskLists: false\n  figures: false\n  headingIdPrefix: h-\n  footnotes:\n    backlink: back\n  admonitions:\n    class: callout\n",
+	})
+	config, err := LoadConfig(filepath.Join(tmpDir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "---\ntitle: Test\n---\n\n## Intro\n\n\"One\"\ntwo[^1]\n\n- [ ] todo\n\n[^1]: Note.\n\n[out](https://other.com/) [in](https://example.com/a.html)\n\n> [!TIP]\n> Tip.\n\n![alt](/a.jpg \"Caption\")\n"
+	post, err := newParser(config).Parse([]byte(content), "test.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(post.Content)
+	for _, want := range []string{`id="h-intro"`, "&quot;One&quot;\ntwo", "[ ] todo", ">back</a>",
+		`<a href="https://other.com/" target="_blank" rel="noopener noreferrer">out</a>`, `<a href="https://example.com/a.html">in</a>`,
+		`<div class="callout tip">`, `<img src="/a.jpg" alt="alt" title="Caption" />`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Content missing %q:\n%s", want, html)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package ssg
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// minifyFor returns the minification function for a file based on its
+// extension, or nil if files of that type are copied unchanged.
+//
+// Supported extensions: .css, .js, .mjs, .html, .htm
+func minifyFor(path string) func([]byte) []byte {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".css":
+		return minifyCSS
+	case ".js", ".mjs":
+		return minifyJS
+	case ".html", ".htm":
+		return minifyHTML
+	}
+	return nil
+}
+
+// minifyCSS removes comments and redundant whitespace from a stylesheet.
+//
+// Strings are preserved verbatim. Whitespace is dropped around the structural
+// characters `{`, `}`, `;`, `,` and `>` and after `:`, and the last semicolon
+// of each block is removed. Whitespace before `:` is kept because it is
+// significant in selectors (`a :hover` is not `a:hover`).
+func minifyCSS(src []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(src))
+
+	// pendingSpace records that whitespace was skipped and a single space
+	// may need to be written before the next token.
+	pendingSpace := false
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				i = len(src)
+			} else {
+				i += end + 3 // the loop increment skips the final '/'
+			}
+			pendingSpace = true
+
+		case c == '"' || c == '\'':
+			if pendingSpace && !cssNoSpaceAfter(lastByte(&out)) {
+				out.WriteByte(' ')
+			}
+			pendingSpace = false
+			end := skipQuoted(src, i)
+			out.Write(src[i:end])
+			i = end - 1
+
+		case isSpace(c):
+			pendingSpace = true
+
+		case c == '}' && lastByte(&out) == ';':
+			out.Truncate(out.Len() - 1)
+			out.WriteByte(c)
+			pendingSpace = false
+
+		default:
+			if pendingSpace && !cssNoSpaceAfter(lastByte(&out)) && !cssNoSpaceBefore(c) {
+				out.WriteByte(' ')
+			}
+			pendingSpace = false
+			out.WriteByte(c)
+		}
+	}
+
+	return bytes.TrimSpace(out.Bytes())
+}
+
+// cssNoSpaceAfter reports whether whitespace following c can be dropped.
+func cssNoSpaceAfter(c byte) bool {
+	return c == 0 || strings.IndexByte("{};,>:", c) >= 0
+}
+
+// cssNoSpaceBefore reports whether whitespace preceding c can be dropped.
+func cssNoSpaceBefore(c byte) bool {
+	return strings.IndexByte("{};,>", c) >= 0
+}
+
+// minifyJS removes comments and collapses whitespace in a script.
+//
+// This is deliberately conservative: no identifiers are renamed and no
+// statements are rewritten. Runs of whitespace are collapsed to a single
+// space, or a single newline if the run contained one, so automatic semicolon
+// insertion behaves exactly as in the original source. Strings, template
+// literals, and regular expression literals are preserved verbatim.
+func minifyJS(src []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(src))
+
+	// pending holds the collapsed whitespace to write before the next token:
+	// 0 for none, otherwise ' ' or '\n'.
+	var pending byte
+	flush := func() {
+		if pending != 0 && out.Len() > 0 {
+			out.WriteByte(pending)
+		}
+		pending = 0
+	}
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			i-- // let the newline be handled as whitespace
+			if pending == 0 {
+				pending = ' '
+			}
+
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			comment := src[i:]
+			if end >= 0 {
+				comment = src[i : i+end+4]
+			}
+			i += len(comment) - 1
+			if bytes.IndexByte(comment, '\n') >= 0 {
+				pending = '\n'
+			} else if pending == 0 {
+				pending = ' '
+			}
+
+		case c == '"' || c == '\'' || c == '`':
+			flush()
+			end := skipQuoted(src, i)
+			out.Write(src[i:end])
+			i = end - 1
+
+		case c == '/' && jsRegexAllowed(out.Bytes()):
+			flush()
+			end := skipRegex(src, i)
+			out.Write(src[i:end])
+			i = end - 1
+
+		case isSpace(c):
+			if c == '\n' {
+				pending = '\n'
+			} else if pending == 0 {
+				pending = ' '
+			}
+
+		default:
+			flush()
+			out.WriteByte(c)
+		}
+	}
+
+	return out.Bytes()
+}
+
+// jsRegexAllowed reports whether a `/` following the already written output
+// starts a regular expression literal rather than a division operator. It uses
+// the common heuristic of inspecting the previous significant character.
+func jsRegexAllowed(prev []byte) bool {
+	prev = bytes.TrimRight(prev, " \n")
+	if len(prev) == 0 {
+		return true
+	}
+	if strings.IndexByte("(,=:[!&|?{};+-*%<>~^", prev[len(prev)-1]) >= 0 {
+		return true
+	}
+	for _, kw := range []string{"return", "typeof", "case", "do", "else", "in", "of", "void", "yield", "await"} {
+		if bytes.HasSuffix(prev, []byte(kw)) {
+			before := len(prev) - len(kw) - 1
+			if before < 0 || !isIdentByte(prev[before]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// skipRegex returns the index just past the regular expression literal
+// (including flags) starting at src[start].
+func skipRegex(src []byte, start int) int {
+	inClass := false
+	i := start + 1
+	for ; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return i
+		case '/':
+			if !inClass {
+				i++
+				for i < len(src) && isIdentByte(src[i]) {
+					i++
+				}
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// minifyHTML collapses insignificant whitespace and removes comments from an
+// HTML document.
+//
+// Whitespace inside <pre> and <textarea> is preserved. Inline <style> and
+// <script> contents are passed through minifyCSS and minifyJS. Tags keep their
+// original attribute quoting; only the whitespace between attributes is
+// collapsed.
+func minifyHTML(src []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(src))
+
+	z := html.NewTokenizer(bytes.NewReader(src))
+	preserve := 0 // depth of elements whose whitespace is significant
+	rawTag := ""  // name of the enclosing <script> or <style>, if any
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return out.Bytes()
+
+		case html.CommentToken:
+			// Drop comments, keeping conditional comments intact.
+			if raw := z.Raw(); bytes.HasPrefix(raw, []byte("<!--[if")) {
+				out.Write(raw)
+			}
+
+		case html.TextToken:
+			raw := z.Raw()
+			switch {
+			case rawTag == "style":
+				out.Write(minifyCSS(raw))
+			case rawTag == "script":
+				out.Write(minifyJS(raw))
+			case preserve > 0:
+				out.Write(raw)
+			default:
+				out.Write(collapseSpace(raw))
+			}
+
+		case html.StartTagToken:
+			raw := collapseTagSpace(z.Raw())
+			name, _ := z.TagName()
+			switch string(name) {
+			case "pre", "textarea":
+				preserve++
+			case "script", "style":
+				rawTag = string(name)
+			}
+			out.Write(raw)
+
+		case html.SelfClosingTagToken:
+			out.Write(collapseTagSpace(z.Raw()))
+
+		case html.EndTagToken:
+			raw := append([]byte(nil), z.Raw()...)
+			name, _ := z.TagName()
+			switch string(name) {
+			case "pre", "textarea":
+				if preserve > 0 {
+					preserve--
+				}
+			case "script", "style":
+				rawTag = ""
+			}
+			out.Write(raw)
+
+		default:
+			out.Write(z.Raw())
+		}
+	}
+}
+
+// collapseSpace replaces each run of whitespace in s with a single space, or a
+// single newline if the run contained one.
+func collapseSpace(s []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(s))
+	var pending byte
+	for _, c := range s {
+		if isSpace(c) {
+			if c == '\n' {
+				pending = '\n'
+			} else if pending == 0 {
+				pending = ' '
+			}
+			continue
+		}
+		if pending != 0 {
+			out.WriteByte(pending)
+			pending = 0
+		}
+		out.WriteByte(c)
+	}
+	if pending != 0 {
+		out.WriteByte(pending)
+	}
+	return out.Bytes()
+}
+
+// collapseTagSpace replaces each run of whitespace in a raw tag with a single
+// space, leaving quoted attribute values untouched. The result is a copy, so
+// it remains valid after the tokenizer advances.
+func collapseTagSpace(tag []byte) []byte {
+	out := make([]byte, 0, len(tag))
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		switch {
+		case c == '"' || c == '\'':
+			end := bytes.IndexByte(tag[i+1:], c)
+			if end < 0 {
+				return append(out, tag[i:]...)
+			}
+			out = append(out, tag[i:i+end+2]...)
+			i += end + 1
+		case isSpace(c):
+			for i+1 < len(tag) && isSpace(tag[i+1]) {
+				i++
+			}
+			out = append(out, ' ')
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// skipQuoted returns the index just past the quoted string starting at
+// src[start], honoring backslash escapes.
+func skipQuoted(src []byte, start int) int {
+	quote := src[start]
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(src)
+}
+
+// lastByte returns the last byte written to buf, or 0 if it is empty.
+func lastByte(buf *bytes.Buffer) byte {
+	if buf.Len() == 0 {
+		return 0
+	}
+	return buf.Bytes()[buf.Len()-1]
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,217 @@
+package ssg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMinifyCSS tests stylesheet minification
+func TestMinifyCSS(t *testing.T) {
+	tests := []struct {
+		name string
+		css  string
+		want string
+	}{
+		{
+			name: "whitespace and trailing semicolon",
+			css:  "body {\n  color: black;\n  margin: 0 auto;\n}\n",
+			want: "body{color:black;margin:0 auto}",
+		},
+		{
+			name: "comments removed",
+			css:  "/* header */\nh1 { font-size: 2rem; } /* done */",
+			want: "h1{font-size:2rem}",
+		},
+		{
+			name: "selector lists and combinators",
+			css:  "ul > li,\nol > li {\n  padding: 0;\n}",
+			want: "ul>li,ol>li{padding:0}",
+		},
+		{
+			name: "descendant pseudo-class keeps space",
+			css:  "a :hover { color: red; }",
+			want: "a :hover{color:red}",
+		},
+		{
+			name: "strings preserved",
+			css:  `a::after { content: "  /* not a comment */  "; }`,
+			want: `a::after{content:"  /* not a comment */  "}`,
+		},
+		{
+			name: "media query",
+			css:  "@media (max-width: 600px) {\n  body { font-size: 14px; }\n}",
+			want: "@media (max-width:600px){body{font-size:14px}}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(minifyCSS([]byte(tt.css)))
+			if got != tt.want {
+				t.Errorf("minifyCSS() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMinifyJS tests conservative script minification
+func TestMinifyJS(t *testing.T) {
+	tests := []struct {
+		name string
+		js   string
+		want string
+	}{
+		{
+			name: "line comments removed",
+			js:   "// setup\nconst a = 1; // one\nconst b = 2;",
+			want: "const a = 1;\nconst b = 2;",
+		},
+		{
+			name: "block comments removed",
+			js:   "/**\n * Docs\n */\nfunction f() {\n    return 1;\n}",
+			want: "function f() {\nreturn 1;\n}",
+		},
+		{
+			name: "strings preserved",
+			js:   `const s = "a  // b";  const t = 'c /* d */';`,
+			want: `const s = "a  // b"; const t = 'c /* d */';`,
+		},
+		{
+			name: "template literal preserved",
+			js:   "const s = `line 1\n    line 2`;",
+			want: "const s = `line 1\n    line 2`;",
+		},
+		{
+			name: "regex literal preserved",
+			js:   "const re = /\\/\\/  [/]/g;  x = a / b / c;",
+			want: "const re = /\\/\\/  [/]/g; x = a / b / c;",
+		},
+		{
+			name: "newlines kept for semicolon insertion",
+			js:   "let a = 1\n\n\nlet b = 2",
+			want: "let a = 1\nlet b = 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(minifyJS([]byte(tt.js)))
+			if got != tt.want {
+				t.Errorf("minifyJS() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMinifyHTML tests document minification
+func TestMinifyHTML(t *testing.T) {
+	src := `<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <!-- page metadata -->
+    <title>  My   Page </title>
+    <meta
+      name="description"
+      content="A  spaced   description"
+    />
+    <style>
+      body { color: black; }
+    </style>
+  </head>
+  <body>
+    <p class="intro">
+      Hello,    world!
+    </p>
+    <pre><code>func main() {
+    fmt.Println("hi")
+}</code></pre>
+    <script>
+      // greet
+      console.log("hi");
+    </script>
+  </body>
+</html>`
+
+	got := string(minifyHTML([]byte(src)))
+
+	if strings.Contains(got, "page metadata") {
+		t.Error("comment was not removed")
+	}
+	if !strings.Contains(got, `<p class="intro">`) {
+		t.Error("tag attributes were not preserved")
+	}
+	if !strings.Contains(got, `<meta name="description" content="A  spaced   description" />`) {
+		t.Errorf("tag whitespace was not collapsed. Got: %s", got)
+	}
+	if !strings.Contains(got, "\nHello, world!\n") {
+		t.Errorf("text whitespace was not collapsed. Got: %s", got)
+	}
+	if !strings.Contains(got, "func main() {\n    fmt.Println(\"hi\")\n}") {
+		t.Errorf("<pre> whitespace was not preserved. Got: %s", got)
+	}
+	if !strings.Contains(got, "<style>body{color:black}</style>") {
+		t.Errorf("inline CSS was not minified. Got: %s", got)
+	}
+	if strings.Contains(got, "// greet") || !strings.Contains(got, `console.log("hi");`) {
+		t.Errorf("inline JS was not minified. Got: %s", got)
+	}
+	if len(got) >= len(src) {
+		t.Errorf("minified length %d >= original length %d", len(got), len(src))
+	}
+}
+
+// TestMinifyFor tests choosing a minifier by file extension
+func TestMinifyFor(t *testing.T) {
+	for _, path := range []string{"a.css", "b.JS", "c.mjs", "d.html", "e.htm"} {
+		if minifyFor(path) == nil {
+			t.Errorf("minifyFor(%q) = nil, want minifier", path)
+		}
+	}
+	for _, path := range []string{"logo.png", "robots.txt", "data.json"} {
+		if minifyFor(path) != nil {
+			t.Errorf("minifyFor(%q) returned a minifier, want nil", path)
+		}
+	}
+}
+
+// TestCopyStatic_Minify tests that static CSS and JS are minified when enabled
+func TestCopyStatic_Minify(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "static")
+	dstDir := filepath.Join(tmpDir, "public")
+	if err := os.MkdirAll(srcDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"style.css":  "body {\n  color: black;\n}\n",
+		"app.js":     "// comment\nconsole.log(1);\n",
+		"robots.txt": "User-agent: *\n\nDisallow:\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := copyStatic(DirFS("."), DirFS("."), srcDir, dstDir, true, sizeLimits{}); err != nil {
+		t.Fatalf("copyStatic() failed: %v", err)
+	}
+
+	want := map[string]string{
+		"style.css":  "body{color:black}",
+		"app.js":     "console.log(1);",
+		"robots.txt": files["robots.txt"],
+	}
+	for name, wantContent := range want {
+		got, err := os.ReadFile(filepath.Join(dstDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != wantContent {
+			t.Errorf("%s = %q, want %q", name, got, wantContent)
+		}
+	}
+}
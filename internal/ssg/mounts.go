@@ -0,0 +1,178 @@
+package ssg
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+// MountConfig maps a file outside content/ (e.g., README.md in the repo root)
+// to a standalone page on the site.
+//
+// Example config.yaml:
+//
+//	mounts:
+//	  - source: README.md
+//	    title: About
+//	    slug: about
+//	  - source: CONTRIBUTING.md
+type MountConfig struct {
+	Source string `yaml:"source"` // Path to the markdown file, relative to the site root
+	Title  string `yaml:"title"`  // Page title (default: first "# " heading, or the file name)
+	Slug   string `yaml:"slug"`   // Output name (default: lowercased file name without extension)
+}
+
+// h1Pattern matches a top-level ATX heading at the start of a line.
+var h1Pattern = regexp.MustCompile(`(?m)^#[ \t]+(.+?)[ \t#]*$`)
+
+// loadMounts parses each mounted file into a page.
+//
+// Mounted files usually have no frontmatter, so it is generated: the title
+// comes from the mount config, the file's first "# " heading, or its name, and
+// the date is the file's modification time. Files that already start with a
+// frontmatter block are parsed as-is, with the mount's title overriding theirs.
+//
+// Relative links between mounted files (e.g., README.md linking to
+// CONTRIBUTING.md) are rewritten to point at the generated pages.
+//
+// Parameters:
+//   - fsys: Filesystem the site is read from
+//   - p: Parser instance to use for markdown conversion
+//   - mounts: Mount configuration from config.yaml
+//   - style: URL style for the pages' URLs (see pageURL)
+//
+// Returns the pages in configuration order, or an error if a file can't be
+// read or parsed.
+func loadMounts(fsys fs.FS, p *parser.Parser, mounts []MountConfig, style string) ([]*parser.Post, error) {
+	var pages []*parser.Post
+	links := make(map[string]string) // cleaned source path → page URL
+
+	for _, m := range mounts {
+		content, err := fs.ReadFile(fsys, m.Source)
+		if err != nil {
+			return nil, fmt.Errorf("reading mount %s: %w", m.Source, err)
+		}
+		info, err := fs.Stat(fsys, m.Source)
+		if err != nil {
+			return nil, fmt.Errorf("reading mount %s: %w", m.Source, err)
+		}
+
+		if !bytes.HasPrefix(content, []byte("---")) {
+			fm := parser.Frontmatter{Title: m.Title, Date: info.ModTime().UTC()}
+			if fm.Title == "" {
+				fm.Title = mountTitle(m.Source, content)
+			}
+			header, err := yaml.Marshal(fm)
+			if err != nil {
+				return nil, fmt.Errorf("generating frontmatter for %s: %w", m.Source, err)
+			}
+			content = append([]byte("---\n"+string(header)+"---\n\n"), content...)
+		}
+
+		page, err := p.Parse(content, m.Source)
+		if err != nil {
+			return nil, fmt.Errorf("parsing mount %s: %w", m.Source, err)
+		}
+		if m.Title != "" {
+			page.Title = m.Title
+		}
+		page.Slug = strings.ToLower(page.Slug)
+		if m.Slug != "" {
+			page.Slug = m.Slug
+		}
+
+		page.URL = pageURL(style, "/"+page.Slug)
+		links[filepath.Clean(m.Source)] = page.URL
+		pages = append(pages, page)
+	}
+
+	for i, page := range pages {
+		page.Content = rewriteMountLinks(page.Content, filepath.Dir(mounts[i].Source), links)
+	}
+
+	return pages, nil
+}
+
+// mountTitle derives a page title from the first "# " heading in content, or
+// from the file name if there is none.
+func mountTitle(path string, content []byte) string {
+	if m := h1Pattern.FindSubmatch(content); m != nil {
+		return string(m[1])
+	}
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// rewriteMountLinks rewrites relative hrefs in page HTML that point at other
+// mounted files to the URLs of their pages, preserving any #fragment.
+func rewriteMountLinks(content template.HTML, dir string, links map[string]string) template.HTML {
+	var out strings.Builder
+	z := html.NewTokenizer(strings.NewReader(string(content)))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := string(z.Raw())
+		if tt != html.StartTagToken {
+			out.WriteString(raw)
+			continue
+		}
+
+		tok := z.Token()
+		rewritten := false
+		for i, a := range tok.Attr {
+			if tok.Data != "a" || a.Key != "href" || strings.Contains(a.Val, "://") || strings.HasPrefix(a.Val, "/") {
+				continue
+			}
+			target, fragment, _ := strings.Cut(a.Val, "#")
+			if url, ok := links[filepath.Join(dir, target)]; ok {
+				if fragment != "" {
+					url += "#" + fragment
+				}
+				tok.Attr[i].Val = url
+				rewritten = true
+			}
+		}
+		if rewritten {
+			out.WriteString(tok.String())
+		} else {
+			out.WriteString(raw)
+		}
+	}
+
+	// #nosec G203 -- rewritten from HTML produced by the markdown parser
+	return template.HTML(out.String())
+}
+
+// renderPage renders a standalone page (such as a mounted README) to an HTML
+// file.
+//
+// Called by Build for each mounted page. Uses "page.html", whose
+// {{define "posts"}} block receives the page as .Post.
+//
+// Parameters:
+//   - page: Parsed page from loadMounts
+//   - config: Site configuration (title, author, etc.) for template rendering
+//   - outputPath: Where to write the HTML file (e.g., "public/about.html")
+//
+// Returns an error if rendering or file writing fails.
+func (r *Renderer) renderPage(page *parser.Post, config SiteConfig, outputPath string) error {
+	data := PageData{
+		Site:  config,
+		Post:  page,
+		Title: page.Title,
+		Kind:  KindPage,
+		URL:   page.URL,
+	}
+
+	return r.renderToFile("page.html", data, outputPath)
+}
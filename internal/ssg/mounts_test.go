@@ -0,0 +1,111 @@
+package ssg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestLoadMounts tests importing repo files as pages
+func TestLoadMounts(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	readme := `# My Project
+
+A project. See [contributing](CONTRIBUTING.md#setup) and [the site](https://example.com).
+`
+	contributing := `Setup instructions.
+`
+	withFrontmatter := `---
+title: Existing Title
+date: 2024-01-15T10:00:00Z
+---
+
+Already has frontmatter.
+`
+	files := map[string]string{
+		"README.md":       readme,
+		"CONTRIBUTING.md": contributing,
+		"docs/faq.md":     withFrontmatter,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mounts := []MountConfig{
+		{Source: filepath.Join(tmpDir, "README.md")},
+		{Source: filepath.Join(tmpDir, "CONTRIBUTING.md"), Title: "Contributing", Slug: "contribute"},
+		{Source: filepath.Join(tmpDir, "docs", "faq.md")},
+	}
+
+	pages, err := loadMounts(DirFS("."), parser.New(), mounts, "")
+	if err != nil {
+		t.Fatalf("loadMounts() failed: %v", err)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("len(pages) = %d, want 3", len(pages))
+	}
+
+	readmePage := pages[0]
+	if readmePage.Title != "My Project" {
+		t.Errorf("Title = %q, want title from heading %q", readmePage.Title, "My Project")
+	}
+	if readmePage.Slug != "readme" {
+		t.Errorf("Slug = %q, want %q", readmePage.Slug, "readme")
+	}
+	if readmePage.Date.IsZero() {
+		t.Error("Date is zero, want file modification time")
+	}
+	content := string(readmePage.Content)
+	if !strings.Contains(content, `href="/contribute.html#setup"`) {
+		t.Errorf("link to mounted file not rewritten. Got: %s", content)
+	}
+	if !strings.Contains(content, `href="https://example.com"`) {
+		t.Errorf("external link was modified. Got: %s", content)
+	}
+
+	if pages[1].Title != "Contributing" || pages[1].Slug != "contribute" {
+		t.Errorf("configured title/slug not used: %q, %q", pages[1].Title, pages[1].Slug)
+	}
+
+	if pages[2].Title != "Existing Title" {
+		t.Errorf("Title = %q, want frontmatter title %q", pages[2].Title, "Existing Title")
+	}
+}
+
+// TestLoadMounts_MissingFile tests mounting a file that doesn't exist
+func TestLoadMounts_MissingFile(t *testing.T) {
+	_, err := loadMounts(DirFS("."), parser.New(), []MountConfig{{Source: "/nonexistent/README.md"}}, "")
+	if err == nil {
+		t.Error("loadMounts() succeeded, want error")
+	}
+}
+
+// TestMountTitle tests deriving page titles
+func TestMountTitle(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		want    string
+	}{
+		{"README.md", "Intro\n\n# Project Name #\n\n## Sub", "Project Name"},
+		{"CHANGELOG.md", "## Only subheadings", "CHANGELOG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := mountTitle(tt.path, []byte(tt.content)); got != tt.want {
+				t.Errorf("mountTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
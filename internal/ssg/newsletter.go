@@ -0,0 +1,280 @@
+package ssg
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+	"golang.org/x/net/html"
+)
+
+// newsletterTemplate is the built-in email template, used if the site has
+// no newsletter.html. Its styles are inline, since most mail clients ignore
+// <style> elements.
+//
+//go:embed assets/newsletter.html
+var newsletterTemplate string
+
+// emailStyles are inline styles added to tags in post content for email,
+// where stylesheets don't apply.
+var emailStyles = map[string]string{
+	"a":          "color: #1d4ed8;",
+	"img":        "max-width: 100%; height: auto;",
+	"pre":        "padding: 12px; background-color: #f4f4f5; overflow-x: auto; font-size: 14px; line-height: 1.4;",
+	"code":       "font-family: Menlo, Consolas, monospace;",
+	"blockquote": "margin: 0 0 16px; padding-left: 16px; border-left: 3px solid #e4e4e7; color: #52525b;",
+}
+
+// NewsletterData is the data newsletter templates are executed with.
+type NewsletterData struct {
+	Site  SiteConfig     // Site config (title, author, etc.)
+	Posts []*parser.Post // Posts in the newsletter, newest first, with absolute URLs and inline styles in their content
+	Title string         // Subject line: the site's title and its newest post's
+	Date  time.Time      // When the newsletter was generated
+}
+
+// Newsletter writes the site's most recent posts as an email newsletter: an
+// HTML file, ready to paste into a mailing service, and a plain text
+// alternative next to it with a .txt extension.
+//
+// The HTML is rendered with templates/newsletter.html (or the theme's), a
+// complete document executed with NewsletterData and the site's template
+// functions; without one, a built-in template with inline styles is used.
+// Links in post content are made absolute, and common tags in it get inline
+// styles, since mail clients don't load the site's stylesheets.
+//
+// Posts are filtered as in a production build.
+//
+// Parameters:
+//   - opts: Newsletter options; empty fields take their defaults
+//
+// Returns an error if the site has no baseUrl, the content or template
+// can't be loaded, or a file can't be written.
+func Newsletter(opts NewsletterOptions) error {
+	opts = opts.withDefaults()
+	config, err := LoadConfigEnv(opts.ConfigPath, EnvProduction)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if config.BaseURL == "" {
+		return fmt.Errorf("newsletter needs baseUrl set, since its links must be absolute")
+	}
+	src := DirFS(".")
+	p := newParser(config)
+	posts, err := loadPosts(src, p, config, false, false, false)
+	if err != nil {
+		return err
+	}
+	tmpl, err := loadNewsletterTemplate(src, config, p)
+	if err != nil {
+		return err
+	}
+
+	data := newsletterData(config, posts, opts.Count, time.Now())
+	if len(data.Posts) == 0 {
+		return fmt.Errorf("no published posts to send")
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("executing newsletter template: %w", err)
+	}
+	if err := os.WriteFile(opts.Output, []byte(buf.String()), 0600); err != nil {
+		return fmt.Errorf("writing newsletter: %w", err)
+	}
+	textPath := strings.TrimSuffix(opts.Output, filepath.Ext(opts.Output)) + ".txt"
+	if err := os.WriteFile(textPath, []byte(newsletterText(data)), 0600); err != nil {
+		return fmt.Errorf("writing newsletter: %w", err)
+	}
+	fmt.Printf("Wrote %d posts to %s and %s\n", len(data.Posts), opts.Output, textPath)
+	return nil
+}
+
+// loadNewsletterTemplate returns newsletter.html from the site's templates
+// or its theme's, or the built-in template if neither has one.
+func loadNewsletterTemplate(src fs.FS, config *SiteConfig, p *parser.Parser) (*template.Template, error) {
+	funcs, err := templateFuncs(*config, p)
+	if err != nil {
+		return nil, fmt.Errorf("creating template funcs: %w", err)
+	}
+	themeDir, err := config.Theme.dir(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSandbox(src, *config, "templates", themeDir); err != nil {
+		return nil, err
+	}
+	text := newsletterTemplate
+	fsys, _ := templateFS(src, "templates", themeDir)
+	if content, err := fs.ReadFile(fsys, "newsletter.html"); err == nil {
+		text = string(content)
+	}
+	tmpl, err := template.New("newsletter.html").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing newsletter template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// newsletterData returns the data of a newsletter of the count newest posts,
+// generated at now. The posts are copies, with their content prepared for
+// email (see emailContent).
+func newsletterData(config *SiteConfig, posts []*parser.Post, count int, now time.Time) NewsletterData {
+	if len(posts) > count {
+		posts = posts[:count]
+	}
+	data := NewsletterData{Site: *config, Title: config.Title, Date: now}
+	for _, post := range posts {
+		email := *post
+		email.Content = emailContent(post.Content, config.BaseURL, post.URL)
+		data.Posts = append(data.Posts, &email)
+	}
+	if len(data.Posts) > 0 {
+		data.Title = config.Title + ": " + data.Posts[0].Title
+	}
+	return data
+}
+
+// emailContent returns post content prepared for email: its links made
+// absolute (see absoluteURLs) and the tags in emailStyles given their inline
+// styles, ahead of any they already have.
+func emailContent(content template.HTML, baseURL, pageURL string) template.HTML {
+	content = absoluteURLs(content, baseURL, pageURL)
+
+	var out strings.Builder
+	z := html.NewTokenizer(strings.NewReader(string(content)))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := z.Raw()
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			out.Write(raw)
+			continue
+		}
+		raw = append([]byte(nil), raw...)
+		tok := z.Token()
+		style, ok := emailStyles[tok.Data]
+		if !ok {
+			out.Write(raw)
+			continue
+		}
+		styled := false
+		for i, a := range tok.Attr {
+			if a.Key == "style" {
+				tok.Attr[i].Val = style + " " + a.Val
+				styled = true
+			}
+		}
+		if !styled {
+			tok.Attr = append(tok.Attr, html.Attribute{Key: "style", Val: style})
+		}
+		out.WriteString(tok.String())
+	}
+
+	// #nosec G203 -- rewritten from HTML produced by the markdown parser
+	return template.HTML(out.String())
+}
+
+// blankLines matches runs of blank lines, which are collapsed to one.
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// newsletterText returns the plain text alternative of a newsletter: each
+// post's title, date, and URL, then its content as text (see emailText).
+func newsletterText(data NewsletterData) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s\n", data.Site.Title, strings.Repeat("=", len([]rune(data.Site.Title))))
+	for _, post := range data.Posts {
+		link := absURL(data.Site.BaseURL, post.URL)
+		fmt.Fprintf(&b, "\n\n%s\n%s\n%s\n%s\n\n", post.Title, strings.Repeat("-", len([]rune(post.Title))), post.Date.Format("January 2, 2006"), link)
+		b.WriteString(emailText(string(post.Content)))
+		fmt.Fprintf(&b, "\n\nRead on the site: %s\n", link)
+	}
+	return b.String()
+}
+
+// emailText returns HTML content as plain text for email: paragraphs and
+// other blocks separated by blank lines, list items prefixed with "- ",
+// links followed by their URL in parentheses, and preformatted text kept as
+// is.
+func emailText(content string) string {
+	var b strings.Builder
+	var href string  // URL of the link being written, if any
+	var linkText int // Length of b when the link started
+	pre := 0         // Depth of <pre> elements
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		tok := z.Token()
+		switch tt {
+		case html.ErrorToken:
+			lines := strings.Split(b.String(), "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight(line, " ")
+			}
+			return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+		case html.TextToken:
+			if pre > 0 {
+				b.WriteString(tok.Data)
+				continue
+			}
+			text := strings.Join(strings.Fields(tok.Data), " ")
+			if text == "" {
+				continue
+			}
+			last := b.String()
+			if strings.TrimLeft(tok.Data, " \t\n") != tok.Data && last != "" && !strings.HasSuffix(last, "\n") && !strings.HasSuffix(last, " ") {
+				b.WriteByte(' ')
+			}
+			b.WriteString(text)
+			if strings.TrimRight(tok.Data, " \t\n") != tok.Data {
+				b.WriteByte(' ')
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch tok.Data {
+			case "pre":
+				pre++
+				b.WriteString("\n\n")
+			case "li":
+				b.WriteString("\n- ")
+			case "br":
+				b.WriteByte('\n')
+			case "img":
+				for _, a := range tok.Attr {
+					if a.Key == "alt" && a.Val != "" {
+						fmt.Fprintf(&b, "[%s]", a.Val)
+					}
+				}
+			case "a":
+				href, linkText = "", b.Len()
+				for _, a := range tok.Attr {
+					if a.Key == "href" {
+						href = a.Val
+					}
+				}
+			}
+		case html.EndTagToken:
+			switch tok.Data {
+			case "pre":
+				pre--
+				b.WriteString("\n\n")
+			case "p", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "blockquote", "div", "figure", "table", "hr":
+				b.WriteString("\n\n")
+			case "tr":
+				b.WriteByte('\n')
+			case "a":
+				if href != "" && strings.TrimSpace(b.String()[linkText:]) != href {
+					fmt.Fprintf(&b, " (%s)", href)
+				}
+				href = ""
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package ssg
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestNewsletter tests writing the newest posts as HTML and plain text
+func TestNewsletter(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["content/posts/2024-01-16-second.md"] = "---\ntitle: Second Post\ndate: 2024-01-16T10:00:00Z\n---\n\nSee [the first](/posts/first.html).\n\n![A gopher](/images/gopher.png)\n"
+	site["content/posts/2024-01-17-third.md"] = "---\ntitle: Third Post\ndate: 2024-01-17T10:00:00Z\n---\n\nThird.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Newsletter(NewsletterOptions{Count: 2}); err != nil {
+		t.Fatalf("Newsletter() failed: %v", err)
+	}
+
+	page, err := os.ReadFile("newsletter.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<title>Test Blog: Third Post</title>",
+		`<a href="https://test.com/posts/second.html" style="color: #1d4ed8; text-decoration: none;">Second Post</a>`,
+		`<a href="https://test.com/posts/first.html" style="color: #1d4ed8;">the first</a>`,
+		`<img src="https://test.com/images/gopher.png" alt="A gopher" style="max-width: 100%; height: auto;"/>`,
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("newsletter.html doesn't contain %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(string(page), "First Post") {
+		t.Error("newsletter.html includes more posts than --count")
+	}
+
+	text, err := os.ReadFile("newsletter.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Test Blog\n=========\n\n\n" +
+		"Third Post\n----------\nJanuary 17, 2024\nhttps://test.com/posts/third.html\n\nThird.\n\n" +
+		"Read on the site: https://test.com/posts/third.html\n\n\n" +
+		"Second Post\n-----------\nJanuary 16, 2024\nhttps://test.com/posts/second.html\n\n" +
+		"See the first (https://test.com/posts/first.html).\n\n[A gopher]\n\n" +
+		"Read on the site: https://test.com/posts/second.html\n"
+	if string(text) != want {
+		t.Errorf("newsletter.txt =\n%s\nwant\n%s", text, want)
+	}
+}
+
+// TestNewsletter_Template tests rendering with the site's newsletter.html
+func TestNewsletter_Template(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["templates/newsletter.html"] = "<h1>{{ .Site.Title }}</h1>{{ range .Posts }}<h2>{{ .Title }}</h2>{{ end }}"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Newsletter(NewsletterOptions{Output: "out/issue.html"}); err == nil {
+		t.Error("Newsletter() succeeded writing into a missing directory, want error")
+	}
+	if err := Newsletter(NewsletterOptions{Output: "issue.html"}); err != nil {
+		t.Fatalf("Newsletter() failed: %v", err)
+	}
+	page, err := os.ReadFile("issue.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<h1>Test Blog</h1><h2>First Post</h2>"; string(page) != want {
+		t.Errorf("issue.html = %q, want %q", page, want)
+	}
+	if _, err := os.Stat("issue.txt"); err != nil {
+		t.Errorf("plain text not written next to the HTML: %v", err)
+	}
+}
+
+// TestEmailText tests converting post HTML to plain text
+func TestEmailText(t *testing.T) {
+	content := "<h2>Steps</h2>\n<ol>\n<li>Install <code>ssg</code></li>\n<li>Run <a href=\"https://example.com/\">https://example.com/</a></li>\n</ol>\n" +
+		"<pre><code>go run ./cmd/ssg\n  build\n</code></pre>\n<blockquote>\n<p>Quoted\ntext.</p>\n</blockquote>\n"
+	want := "Steps\n\n- Install ssg\n- Run https://example.com/\n\ngo run ./cmd/ssg\n  build\n\nQuoted text."
+	if got := emailText(content); got != want {
+		t.Errorf("emailText() =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,37 @@
+package ssg
+
+import (
+	"io/fs"
+)
+
+// notFoundURL is the URL of the site's 404 page, which static hosts like
+// Netlify, Cloudflare Pages, and GitHub Pages serve for missing paths.
+const notFoundURL = "/404.html"
+
+// renderNotFound renders the site's 404 page with the optional 404.html
+// content template, which defines {{define "posts"}} like the others and gets
+// the site config as .Site. Does nothing if the site (and its theme) has no
+// 404.html template. The page is marked noindex and has no canonical URL,
+// since it's served at whatever URL was missing; its links should be
+// root-relative or absolute for the same reason.
+//
+// A 404.html in static/ is copied over the rendered one, so a hand-written
+// page still wins.
+//
+// Parameters:
+//   - config: Site configuration for template rendering
+//   - outputPath: Where to write the HTML file (e.g., "public/404.html")
+//
+// Returns an error if rendering or file writing fails.
+func (r *Renderer) renderNotFound(config SiteConfig, outputPath string) error {
+	if _, err := fs.Stat(r.fs, "404.html"); err != nil {
+		return nil
+	}
+	data := PageData{
+		Site:  config,
+		Title: "Page not found",
+		Kind:  KindNotFound,
+		URL:   notFoundURL,
+	}
+	return r.renderToFile("404.html", data, outputPath)
+}
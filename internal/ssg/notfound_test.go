@@ -0,0 +1,87 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_NotFound tests rendering 404.html from its template
+func TestBuild_NotFound(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["templates/base.html"] = "<html>\n<head>{{.Head}}</head>\n<body>\n{{template \"posts\" .}}\n</body>\n</html>\n"
+	site["templates/404.html"] = "{{define \"posts\"}}<h1>{{.Title}}</h1><a href=\"/\">Back to {{.Site.Title}}</a>{{end}}"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join("public", "404.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(data)
+	for _, want := range []string{
+		"<h1>Page not found</h1><a href=\"/\">Back to Test Blog</a>",
+		`<meta name="robots" content="noindex" />`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("404.html doesn't contain %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "canonical") {
+		t.Errorf("404.html has a canonical URL:\n%s", page)
+	}
+}
+
+// TestBuild_NotFoundOptional tests that sites without a 404.html template get no 404 page,
+// and that one in static/ replaces the rendered one
+func TestBuild_NotFoundOptional(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string // Contents of 404.html, or "" for none
+	}{
+		{name: "no template"},
+		{
+			name: "static",
+			files: map[string]string{
+				"templates/404.html": "{{define \"posts\"}}rendered{{end}}",
+				"static/404.html":    "hand-written",
+			},
+			want: "hand-written",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			site := testSite()
+			for name, content := range tt.files {
+				site[name] = content
+			}
+			writeFiles(t, tmpDir, site)
+			t.Chdir(tmpDir)
+
+			if err := Build(context.Background(), BuildOptions{}); err != nil {
+				t.Fatalf("Build() failed: %v", err)
+			}
+			data, err := os.ReadFile(filepath.Join("public", "404.html"))
+			if tt.want == "" {
+				if !os.IsNotExist(err) {
+					t.Errorf("404.html written without a template: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("404.html = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// NotifyConfig configures the hooks run when a build finishes, so problems
+// in long-running modes (serve, or rebuilding on changes with air) surface
+// immediately instead of scrolling past in a terminal.
+//
+// Example config.yaml:
+//
+//	notify:
+//	  command: ./scripts/on-build.sh
+//	  webhook: https://hooks.example.com/builds
+//	  desktop: true
+type NotifyConfig struct {
+	Command   string `yaml:"command"`   // Shell command to run, with SSG_BUILD_* variables set
+	Webhook   string `yaml:"webhook"`   // URL to POST a JSON build report to
+	Desktop   bool   `yaml:"desktop"`   // Show a desktop notification (notify-send or osascript)
+	OnSuccess bool   `yaml:"onSuccess"` // Also notify after successful builds, not only failures
+}
+
+// buildReport describes a finished build. It's the JSON body of webhook
+// notifications.
+type buildReport struct {
+	Site     string    `json:"site"`
+	Status   string    `json:"status"` // "success" or "failure"
+	Error    string    `json:"error,omitempty"`
+	Duration string    `json:"duration"`
+	Time     time.Time `json:"time"`
+}
+
+// notifyTimeout limits how long a single notification hook may take.
+const notifyTimeout = 10 * time.Second
+
+// notifyClient sends webhook notifications.
+var notifyClient = &http.Client{Timeout: notifyTimeout}
+
+// notifyBuild runs the notification hooks configured in configPath (with the
+// overlay for env merged in) for a build that finished with buildErr after
+// duration.
+//
+// Hooks run after failures, and after successes too if onSuccess is set. A
+// hook that fails is printed as a warning; it never changes the outcome of
+// the build. Nothing is sent if the config can't be loaded.
+func notifyBuild(configPath, env string, buildErr error, duration time.Duration) {
+	config, err := LoadConfigEnv(configPath, env)
+	if err != nil {
+		return
+	}
+	cfg := config.Notify
+	if buildErr == nil && !cfg.OnSuccess {
+		return
+	}
+
+	report := buildReport{
+		Site:     config.Title,
+		Status:   "success",
+		Duration: duration.Round(time.Millisecond).String(),
+		Time:     time.Now(),
+	}
+	if buildErr != nil {
+		report.Status = "failure"
+		report.Error = buildErr.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	if cfg.Command != "" {
+		if err := notifyCommand(ctx, cfg.Command, report); err != nil {
+			slog.Warn("notify command", "error", err)
+		}
+	}
+	if cfg.Webhook != "" {
+		if err := notifyWebhook(ctx, cfg.Webhook, report); err != nil {
+			slog.Warn("notify webhook", "error", err)
+		}
+	}
+	if cfg.Desktop {
+		if err := notifyDesktop(ctx, report); err != nil {
+			slog.Warn("desktop notification", "error", err)
+		}
+	}
+}
+
+// notifyCommand runs command with the shell, describing the build in the
+// SSG_BUILD_STATUS, SSG_BUILD_ERROR, and SSG_BUILD_DURATION environment
+// variables.
+func notifyCommand(ctx context.Context, command string, report buildReport) error {
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(),
+		"SSG_BUILD_STATUS="+report.Status,
+		"SSG_BUILD_ERROR="+report.Error,
+		"SSG_BUILD_DURATION="+report.Duration,
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// shellCommand returns a command running command with the platform's shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command) // #nosec G204 -- command comes from the site's own config
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command) // #nosec G204 -- command comes from the site's own config
+}
+
+// notifyWebhook POSTs the report as JSON to url.
+func notifyWebhook(ctx context.Context, url string, report buildReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := notifyClient.Do(req) // #nosec G107 -- URL comes from the site's own config
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s responded %s", url, resp.Status)
+	}
+	return nil
+}
+
+// notifyDesktop shows the report as a desktop notification, using
+// notify-send on Linux and osascript on macOS.
+func notifyDesktop(ctx context.Context, report buildReport) error {
+	title := "Build succeeded"
+	message := report.Site + " built in " + report.Duration
+	if report.Status != "success" {
+		title = "Build failed"
+		message = report.Error
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.CommandContext(ctx, "notify-send", "ssg: "+title, message) // #nosec G204 -- arguments aren't interpreted by a shell
+	case "darwin":
+		// The title and message are passed as arguments, so nothing in them
+		// is read as AppleScript
+		cmd = exec.CommandContext(ctx, "osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			"--", "ssg: "+title, message) // #nosec G204 -- arguments aren't interpreted by a shell
+	default:
+		return fmt.Errorf("not supported on %s", runtime.GOOS)
+	}
+	return cmd.Run()
+}
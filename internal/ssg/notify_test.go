@@ -0,0 +1,100 @@
+package ssg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestBuild_NotifyFailure tests that a failed build runs the command hook and webhook
+func TestBuild_NotifyFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("command hook test uses sh")
+	}
+
+	var report buildReport
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Errorf("decoding webhook body: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "notify:\n" +
+		"  command: echo \"$SSG_BUILD_STATUS $SSG_BUILD_ERROR\" > hook.txt\n" +
+		"  webhook: " + srv.URL + "\n" +
+		"permalink: posts/:slug\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	buildErr := Build(context.Background(), BuildOptions{Notify: true})
+	if buildErr == nil {
+		t.Fatal("Build() succeeded, want an invalid permalink error")
+	}
+
+	hook, err := os.ReadFile(filepath.Join(tmpDir, "hook.txt"))
+	if err != nil {
+		t.Fatalf("command hook didn't run: %v", err)
+	}
+	if !strings.HasPrefix(string(hook), "failure ") {
+		t.Errorf("hook output = %q, want failure status", hook)
+	}
+
+	if report.Status != "failure" || report.Error != buildErr.Error() || report.Site != "Test Blog" {
+		t.Errorf("webhook report = %+v", report)
+	}
+}
+
+// TestBuild_NotifySuccess tests that successful builds notify only with onSuccess
+func TestBuild_NotifySuccess(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "notify:\n  webhook: " + srv.URL + "\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{Notify: true}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("webhook called %d times after a successful build, want 0", calls)
+	}
+
+	writeFiles(t, tmpDir, map[string]string{"config.yaml": site["config.yaml"] + "  onSuccess: true\n"})
+	if err := Build(context.Background(), BuildOptions{Notify: true}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("webhook called %d times, want 1 (onSuccess set, Notify on for one build)", calls)
+	}
+}
+
+// TestNotifyWebhook_Error tests that non-2xx webhook responses are reported
+func TestNotifyWebhook_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	err := notifyWebhook(context.Background(), srv.URL, buildReport{Status: "success"})
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("notifyWebhook() error = %v, want a 500 error", err)
+	}
+}
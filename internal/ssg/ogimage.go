@@ -0,0 +1,352 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	_ "image/jpeg" // Register JPEG for decoding logos
+	"image/png"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Size of generated Open Graph images, the size social sites show best.
+const (
+	ogImageWidth  = 1200
+	ogImageHeight = 630
+)
+
+// defaultOGImageCommand converts an SVG layout to PNG unless
+// ogImage.command is set.
+const defaultOGImageCommand = "rsvg-convert --format png"
+
+// OGImageConfig generates a social preview image (a 1200×630 PNG) for each
+// post and section entry, shown when a link to it is shared. Images are
+// written to /og/, e.g. /og/hello.png for /posts/hello.html, linked from
+// .Head as og:image and twitter:image, and from templates as .Post.OGImage.
+//
+// The default layout draws the post's title, its date, and the site's title
+// and logo in the configured colors. For a layout of your own, template is
+// an SVG file executed as a Go template with .Title, .Post, and .Site (and
+// a wrap function, e.g. {{ range wrap 30 .Title }}, for splitting the title
+// into lines), and command converts the SVG it's given on stdin to the PNG
+// it prints.
+//
+// Example config.yaml:
+//
+//	ogImage:
+//	  enabled: true
+//	  background: "#0f172a"
+//	  color: "#f8fafc"
+//	  accent: "#38bdf8"
+//	  logo: static/images/logo.png
+//
+// or, with an SVG layout:
+//
+//	ogImage:
+//	  enabled: true
+//	  template: templates/og.svg
+//	  command: rsvg-convert --format png
+type OGImageConfig struct {
+	Enabled    bool   `yaml:"enabled"`    // Generate an image for every post
+	Template   string `yaml:"template"`   // SVG layout executed with .Title, .Post, and .Site (default: the built-in layout)
+	Command    string `yaml:"command"`    // Command converting an SVG layout on stdin to PNG (default: rsvg-convert --format png)
+	Background string `yaml:"background"` // Background color of the built-in layout (default: "#1e293b")
+	Color      string `yaml:"color"`      // Text color of the built-in layout (default: "#ffffff")
+	Accent     string `yaml:"accent"`     // Color of the built-in layout's bar and site title (default: "#3b82f6")
+	Logo       string `yaml:"logo"`       // PNG or JPEG drawn in the built-in layout's corner
+	Font       string `yaml:"font"`       // TrueType or OpenType font of the built-in layout (default: Go Bold)
+}
+
+// ogImageData is the input to an SVG layout.
+type ogImageData struct {
+	Title string
+	Post  *parser.Post
+	Site  SiteConfig
+}
+
+// ogImager draws the Open Graph images of posts (see OGImageConfig).
+type ogImager struct {
+	cfg                      OGImageConfig
+	tmpl                     *template.Template // SVG layout, or nil for the built-in one
+	font                     *opentype.Font
+	logo                     image.Image // nil if none
+	background, text, accent color.Color
+}
+
+// newOGImager returns an imager for cfg, with its layout, font, and logo
+// read from fsys, or nil if OG images aren't enabled.
+func newOGImager(fsys fs.FS, cfg OGImageConfig) (*ogImager, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	g := &ogImager{cfg: cfg}
+	if cfg.Template != "" {
+		data, err := fs.ReadFile(fsys, filepath.ToSlash(cfg.Template))
+		if err != nil {
+			return nil, fmt.Errorf("ogImage.template: %w", err)
+		}
+		g.tmpl, err = template.New(filepath.Base(cfg.Template)).Funcs(template.FuncMap{"wrap": wrapWords}).Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("ogImage.template: %w", err)
+		}
+		return g, nil
+	}
+
+	var err error
+	for _, c := range []struct {
+		key, value, def string
+		dst             *color.Color
+	}{
+		{"background", cfg.Background, "#1e293b", &g.background},
+		{"color", cfg.Color, "#ffffff", &g.text},
+		{"accent", cfg.Accent, "#3b82f6", &g.accent},
+	} {
+		if c.value == "" {
+			c.value = c.def
+		}
+		if *c.dst, err = parseHexColor(c.value); err != nil {
+			return nil, fmt.Errorf("ogImage.%s: %w", c.key, err)
+		}
+	}
+
+	ttf := gobold.TTF
+	if cfg.Font != "" {
+		if ttf, err = fs.ReadFile(fsys, filepath.ToSlash(cfg.Font)); err != nil {
+			return nil, fmt.Errorf("ogImage.font: %w", err)
+		}
+	}
+	if g.font, err = opentype.Parse(ttf); err != nil {
+		return nil, fmt.Errorf("ogImage.font: %w", err)
+	}
+
+	if cfg.Logo != "" {
+		f, err := fsys.Open(filepath.ToSlash(cfg.Logo))
+		if err != nil {
+			return nil, fmt.Errorf("ogImage.logo: %w", err)
+		}
+		defer f.Close()
+		if g.logo, _, err = image.Decode(f); err != nil {
+			return nil, fmt.Errorf("ogImage.logo: %w", err)
+		}
+	}
+	return g, nil
+}
+
+// assignOGImages sets OGImage on each post to the URL of its image (see
+// ogImageURL), which ogImager.write writes when the page is rendered.
+//
+// Returns an error if the site has no baseUrl, which the og:image tag needs
+// to make the URL absolute, or two posts would get the same image.
+func assignOGImages(posts []*parser.Post, cfg OGImageConfig, baseURL string) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if baseURL == "" {
+		return fmt.Errorf("ogImage needs baseUrl in the config")
+	}
+	seen := make(map[string]string) // Image URL → page URL
+	for _, post := range posts {
+		url := ogImageURL(post.URL)
+		if other, ok := seen[url]; ok {
+			return fmt.Errorf("%s and %s would have the same OG image %s", other, post.URL, url)
+		}
+		seen[url] = post.URL
+		post.OGImage = url
+	}
+	return nil
+}
+
+// ogImageURL returns the URL of the Open Graph image of the page at
+// pageURL, under /og/ and without the /posts/ prefix of post URLs:
+// "/posts/hello.html" and "/posts/hello/" get "/og/hello.png", and
+// "/notes/vim.html" gets "/og/notes/vim.png".
+func ogImageURL(pageURL string) string {
+	name := strings.TrimSuffix(bundleURL(pageURL), "/")
+	if strings.HasPrefix(name, "/posts/") {
+		name = strings.TrimPrefix(name, "/posts")
+	}
+	return "/og" + name + ".png"
+}
+
+// write draws the Open Graph image of a post that has one (see
+// assignOGImages) and writes it to outputDir in out. g may be nil, if OG
+// images aren't enabled.
+func (g *ogImager) write(ctx context.Context, out FS, post *parser.Post, site SiteConfig, outputDir string) error {
+	if g == nil || post.OGImage == "" {
+		return nil
+	}
+	var data []byte
+	var err error
+	if g.tmpl != nil {
+		data, err = g.convertSVG(ctx, ogImageData{Title: post.Title, Post: post, Site: site})
+	} else {
+		data, err = g.draw(post, site)
+	}
+	if err != nil {
+		return fmt.Errorf("OG image of %s: %w", post.URL, err)
+	}
+	return out.WriteFile(urlPath(outputDir, post.OGImage), data, 0600)
+}
+
+// convertSVG executes the SVG layout with data and converts it to PNG with
+// the configured command.
+func (g *ogImager) convertSVG(ctx context.Context, data ogImageData) ([]byte, error) {
+	var svg bytes.Buffer
+	if err := g.tmpl.Execute(&svg, data); err != nil {
+		return nil, err
+	}
+	command := g.cfg.Command
+	if command == "" {
+		command = defaultOGImageCommand
+	}
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = &svg
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+	if !bytes.HasPrefix(out, []byte("\x89PNG")) {
+		return nil, fmt.Errorf("%q didn't print a PNG", command)
+	}
+	return out, nil
+}
+
+// draw draws a post's image in the built-in layout: an accent bar down the
+// left edge, the title as large as fits in up to four lines, the date below
+// it, and the site's title and logo along the bottom.
+func (g *ogImager) draw(post *parser.Post, site SiteConfig) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, ogImageWidth, ogImageHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(g.background), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, 24, ogImageHeight), image.NewUniform(g.accent), image.Point{}, draw.Src)
+
+	const margin = 96
+	textWidth := ogImageWidth - 2*margin
+	var title font.Face
+	var lines []string
+	for _, size := range []float64{80, 68, 56, 48} {
+		face, err := g.face(size)
+		if err != nil {
+			return nil, err
+		}
+		title, lines = face, wrapText(face, post.Title, textWidth)
+		if len(lines) <= 4 {
+			break
+		}
+	}
+	if len(lines) > 4 {
+		lines = append(lines[:3], lines[3]+"…")
+	}
+	lineHeight := title.Metrics().Height.Ceil() * 6 / 5
+	y := margin + title.Metrics().Ascent.Ceil()
+	for _, line := range lines {
+		drawText(img, title, g.text, margin, y, line)
+		y += lineHeight
+	}
+
+	small, err := g.face(32)
+	if err != nil {
+		return nil, err
+	}
+	if !post.Date.IsZero() {
+		drawText(img, small, fadeColor(g.text, g.background), margin, y-lineHeight+72, post.Date.Format("January 2, 2006"))
+	}
+	drawText(img, small, g.accent, margin, ogImageHeight-margin+small.Metrics().Ascent.Ceil()/2, site.Title)
+
+	if g.logo != nil {
+		b := g.logo.Bounds()
+		h := 96
+		w := b.Dx() * h / max(b.Dy(), 1)
+		dst := image.Rect(ogImageWidth-margin-w, ogImageHeight-margin-h/2, ogImageWidth-margin, ogImageHeight-margin+h/2)
+		draw.CatmullRom.Scale(img, dst, g.logo, b, draw.Over, nil)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// face returns the imager's font at size (in pixels).
+func (g *ogImager) face(size float64) (font.Face, error) {
+	return opentype.NewFace(g.font, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// drawText draws s on img in face and c, starting at x on the baseline y.
+func drawText(img draw.Image, face font.Face, c color.Color, x, y int, s string) {
+	d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+// wrapText splits s into lines no wider than width in face, breaking
+// between words. A word wider than width gets a line of its own.
+func wrapText(face font.Face, s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		next := strings.TrimSpace(line + " " + word)
+		if line != "" && font.MeasureString(face, next).Ceil() > width {
+			lines = append(lines, line)
+			next = word
+		}
+		line = next
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// wrapWords splits s into lines of at most n characters, breaking between
+// words, for SVG layouts, which don't wrap text themselves.
+func wrapWords(n int, s string) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > n {
+			lines = append(lines, line)
+			line = ""
+		}
+		line = strings.TrimSpace(line + " " + word)
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fadeColor returns c a third of the way to bg, for secondary text.
+func fadeColor(c, bg color.Color) color.Color {
+	r1, g1, b1, _ := c.RGBA()
+	r2, g2, b2, _ := bg.RGBA()
+	mix := func(a, b uint32) uint8 { return uint8((2*a + b) / 3 >> 8) }
+	return color.RGBA{mix(r1, r2), mix(g1, g2), mix(b1, b2), 0xff}
+}
+
+// parseHexColor parses a CSS hex color: "#rgb" or "#rrggbb".
+func parseHexColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 || !strings.HasPrefix(strings.TrimSpace(s), "#") {
+		return nil, fmt.Errorf("invalid color %q, want e.g. #1e293b", s)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}, nil
+}
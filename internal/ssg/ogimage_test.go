@@ -0,0 +1,149 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestOGImageURL tests naming OG images after each style of page URL
+func TestOGImageURL(t *testing.T) {
+	tests := map[string]string{
+		"/posts/hello.html":     "/og/hello.png",
+		"/posts/hello":          "/og/hello.png",
+		"/posts/hello/":         "/og/hello.png",
+		"/notes/vim.html":       "/og/notes/vim.png",
+		"/2024/01/hello/":       "/og/2024/01/hello.png",
+		"/es/posts/hola.html":   "/og/es/posts/hola.png",
+		"/posts-archive/a.html": "/og/posts-archive/a.png",
+	}
+	for url, want := range tests {
+		if got := ogImageURL(url); got != want {
+			t.Errorf("ogImageURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+// TestAssignOGImages tests assigning images and the errors doing so
+func TestAssignOGImages(t *testing.T) {
+	posts := []*parser.Post{{URL: "/posts/a.html"}, {URL: "/notes/a.html"}}
+	if err := assignOGImages(posts, OGImageConfig{}, "https://example.com"); err != nil || posts[0].OGImage != "" {
+		t.Errorf("assignOGImages() when disabled = %q, %v; want no image", posts[0].OGImage, err)
+	}
+	if err := assignOGImages(posts, OGImageConfig{Enabled: true}, "https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if posts[0].OGImage != "/og/a.png" || posts[1].OGImage != "/og/notes/a.png" {
+		t.Errorf("OG images = %q, %q", posts[0].OGImage, posts[1].OGImage)
+	}
+	if err := assignOGImages(posts, OGImageConfig{Enabled: true}, ""); err == nil {
+		t.Error("assignOGImages() without baseUrl succeeded, want error")
+	}
+	clash := []*parser.Post{{URL: "/posts/a.html"}, {URL: "/posts/a/"}}
+	if err := assignOGImages(clash, OGImageConfig{Enabled: true}, "https://example.com"); err == nil {
+		t.Error("assignOGImages() with two posts getting one image succeeded, want error")
+	}
+}
+
+// TestWrapWords tests splitting titles into lines for SVG layouts
+func TestWrapWords(t *testing.T) {
+	got := wrapWords(12, "A long title for a small   box")
+	want := []string{"A long title", "for a small", "box"}
+	if !slices.Equal(got, want) {
+		t.Errorf("wrapWords() = %q, want %q", got, want)
+	}
+}
+
+// TestBuild_OGImage tests drawing OG images in the built-in layout and
+// linking them from the head
+func TestBuild_OGImage(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "ogImage:\n  enabled: true\n  background: \"#fff\"\n  logo: static/logo.png\n"
+	site["templates/base.html"] = "<head>{{ .Head }}</head>{{template \"posts\" .}}"
+	site["content/notes/vim.md"] = "---\ntitle: A note with a title long enough that it has to wrap over several lines of the image\ndate: 2024-02-01T10:00:00Z\n---\n\nText.\n"
+	writeFiles(t, tmpDir, site)
+	var logo bytes.Buffer
+	if err := png.Encode(&logo, image.NewRGBA(image.Rect(0, 0, 20, 10))); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, tmpDir, map[string]string{"static/logo.png": logo.String()})
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	for _, file := range []string{"public/og/first.png", "public/og/notes/vim.png"} {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("decoding %s: %v", file, err)
+		}
+		if b := img.Bounds(); b.Dx() != ogImageWidth || b.Dy() != ogImageHeight {
+			t.Errorf("%s is %dx%d, want %dx%d", file, b.Dx(), b.Dy(), ogImageWidth, ogImageHeight)
+		}
+		if r, g, b, _ := img.At(ogImageWidth-1, 0).RGBA(); r>>8 != 0xff || g>>8 != 0xff || b>>8 != 0xff {
+			t.Errorf("%s background = %d,%d,%d, want white", file, r>>8, g>>8, b>>8)
+		}
+	}
+
+	page, err := os.ReadFile(filepath.Join("public", "posts", "first.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<meta property="og:image" content="https://test.com/og/first.png" />`,
+		`<meta property="og:image:width" content="1200" />`,
+		`<meta name="twitter:card" content="summary_large_image" />`,
+		`"image":"https://test.com/og/first.png"`,
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("post page missing %q:\n%s", want, page)
+		}
+	}
+}
+
+// TestBuild_OGImageTemplate tests converting an SVG layout with a command
+func TestBuild_OGImageTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "ogImage:\n  enabled: true\n  template: templates/og.svg\n  command: cat > og.svg && cat fake.png\n"
+	site["templates/og.svg"] = "<svg>{{ range wrap 12 .Title }}<text>{{ . }}</text>{{ end }}<text>{{ .Site.Title }}</text></svg>"
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: Fish & Chips for everyone\ndate: 2024-01-15T10:00:00Z\n---\n\nText.\n"
+	site["fake.png"] = "\x89PNG fake"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	svg, err := os.ReadFile("og.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<svg><text>Fish &amp; Chips</text><text>for everyone</text><text>Test Blog</text></svg>"; string(svg) != want {
+		t.Errorf("SVG = %q, want %q", svg, want)
+	}
+	if data, err := os.ReadFile("public/og/first.png"); err != nil || string(data) != "\x89PNG fake" {
+		t.Errorf("public/og/first.png = %q, %v; want the command's output", data, err)
+	}
+
+	// A command that doesn't print a PNG fails the build
+	writeFiles(t, tmpDir, map[string]string{"fake.png": "<svg/>"})
+	if err := Build(context.Background(), BuildOptions{}); err == nil || !strings.Contains(err.Error(), "didn't print a PNG") {
+		t.Errorf("Build() error = %v, want a PNG error", err)
+	}
+}
@@ -0,0 +1,258 @@
+package ssg
+
+import "os"
+
+// Build environments, set with BuildOptions.Environment and exposed to
+// templates as .Env.
+const (
+	EnvProduction  = "production"
+	EnvDevelopment = "development"
+)
+
+// envAliases are short names accepted for the build environments.
+var envAliases = map[string]string{
+	"prod": EnvProduction,
+	"dev":  EnvDevelopment,
+}
+
+// BuildOptions configures a build.
+type BuildOptions struct {
+	ConfigPath  string // Path to config.yaml (default: "config.yaml")
+	OutputDir   string // Directory the site is written to (default: "public")
+	Drafts      bool   // Include posts marked draft: true
+	Future      bool   // Include posts dated in the future
+	Expired     bool   // Include posts whose expiryDate has passed
+	BaseURL     string // Overrides baseUrl from the config (e.g., for preview deploys)
+	Environment string // EnvProduction or EnvDevelopment (default: $SSG_ENV, then EnvProduction)
+	Strict      bool   // Fail the build if generated pages have broken internal links or frontmatter doesn't match the schema
+	Notify      bool   // Run the notify hooks from the config when the build finishes
+	Webmentions bool   // Send webmentions once a production build finishes (see sendWebmentions); only for output served as it's written, since Deploy sends them after publishing
+	Shard       string // Build only shard i of n, written "i/n" (e.g., "2/4"; default: the whole site)
+	DryRun      bool   // Report the files the build would create, change, or delete in OutputDir instead of writing them
+	Profile     bool   // Print how long each stage of the build and the slowest posts took
+	CPUProfile  string // Write a pprof CPU profile of the build to this file
+	Source      FS     // Filesystem the site is read from; ConfigPath and the site's directories are names in it (default: DirFS("."))
+	Output      FS     // Filesystem OutputDir is written to (default: Source)
+
+	pages   map[string]bool // Write only the pages at these URLs, skipping site-wide files and slow asset steps (see liveSite)
+	preview bool            // Build without side effects outside the output: no hooks, and no "Built site" log (see dryRun and diffBuild)
+}
+
+// ServeOptions configures the development server.
+type ServeOptions struct {
+	ConfigPath string // Path to config.yaml (default: "config.yaml")
+	OutputDir  string // Directory to build into and serve (default: "public")
+	Port       string // Port to serve on (default: "8080")
+	NoBuild    bool   // Serve the existing output as-is, without building or watching
+	NoWatch    bool   // Build once instead of rebuilding when sources change
+	NoListings bool   // Respond 404 to directories without an index.html instead of listing them
+	NoRewrite  bool   // Serve pages as built, without pointing links to baseUrl at the local server
+	Live       bool   // Render pages from source on each request instead of serving them from the last build
+	InMemory   bool   // Build into memory and serve from there, leaving OutputDir on disk untouched
+
+	output FS // Filesystem builds write OutputDir to (default: the disk; a MemFS with InMemory)
+}
+
+// withDefaults returns opts with empty fields set to their defaults.
+func (opts ServeOptions) withDefaults() ServeOptions {
+	if opts.ConfigPath == "" {
+		opts.ConfigPath = "config.yaml"
+	}
+	if opts.OutputDir == "" {
+		opts.OutputDir = "public"
+	}
+	if opts.Port == "" {
+		opts.Port = "8080"
+	}
+	return opts
+}
+
+// buildOptions returns the options Serve builds the site with: the
+// development environment, with notify hooks.
+func (opts ServeOptions) buildOptions() BuildOptions {
+	return BuildOptions{ConfigPath: opts.ConfigPath, OutputDir: opts.OutputDir, Environment: EnvDevelopment, Notify: true, Output: opts.output}
+}
+
+// NewPostOptions configures NewPost.
+type NewPostOptions struct {
+	ConfigPath  string   // Path to config.yaml, for the site's language (default: "config.yaml")
+	Title       string   // Title of the post (required)
+	Slug        string   // Slug for the file name and URL (default: generated from Title)
+	Description string   // Description for the frontmatter
+	Tags        []string // Tags for the frontmatter
+	Section     string   // Section under content/ to create the post in (default: "posts")
+	NoDraft     bool     // Create the post with draft: false, so it's published on the next build
+	Edit        bool     // Open the new file in $VISUAL or $EDITOR
+}
+
+// withDefaults returns opts with empty fields set to their defaults.
+func (opts NewPostOptions) withDefaults() NewPostOptions {
+	if opts.ConfigPath == "" {
+		opts.ConfigPath = "config.yaml"
+	}
+	if opts.Section == "" {
+		opts.Section = "posts"
+	}
+	return opts
+}
+
+// PublishOptions configures Publish.
+type PublishOptions struct {
+	ConfigPath string // Path to config.yaml (default: "config.yaml")
+	OutputDir  string // Directory to build into (default: "public")
+	Slug       string // Slug of the draft to publish (required)
+	Rename     bool   // Rename a file named by date to the publish date
+	NoBuild    bool   // Update the post without rebuilding the site
+}
+
+// withDefaults returns opts with empty fields set to their defaults.
+func (opts PublishOptions) withDefaults() PublishOptions {
+	if opts.ConfigPath == "" {
+		opts.ConfigPath = "config.yaml"
+	}
+	if opts.OutputDir == "" {
+		opts.OutputDir = "public"
+	}
+	return opts
+}
+
+// TUIOptions configures TUI.
+type TUIOptions struct {
+	ConfigPath string // Path to config.yaml (default: "config.yaml")
+	OutputDir  string // Directory to build into (default: "public")
+}
+
+// withDefaults returns opts with empty fields set to their defaults.
+func (opts TUIOptions) withDefaults() TUIOptions {
+	if opts.ConfigPath == "" {
+		opts.ConfigPath = "config.yaml"
+	}
+	if opts.OutputDir == "" {
+		opts.OutputDir = "public"
+	}
+	return opts
+}
+
+// DiffOptions configures Diff.
+type DiffOptions struct {
+	ConfigPath string // Path to config.yaml (default: "config.yaml")
+	OutputDir  string // Output directory of the previous build (default: "public")
+	Ref        string // Git ref of OutputDir to compare against instead of the saved manifest
+	Stat       bool   // Only list the changed files
+	Words      bool   // Show changed words instead of a unified diff of lines
+}
+
+// withDefaults returns opts with empty fields set to their defaults.
+func (opts DiffOptions) withDefaults() DiffOptions {
+	if opts.ConfigPath == "" {
+		opts.ConfigPath = "config.yaml"
+	}
+	if opts.OutputDir == "" {
+		opts.OutputDir = "public"
+	}
+	return opts
+}
+
+// DeployOptions configures Deploy.
+type DeployOptions struct {
+	ConfigPath string // Path to config.yaml (default: "config.yaml")
+	OutputDir  string // Directory to build into and deploy (default: "public")
+	Target     string // Name of the deploy target in the config (default: the only one)
+	NoBuild    bool   // Deploy the existing output as-is, without building
+	DryRun     bool   // Report what would be deployed without changing the target
+}
+
+// withDefaults returns opts with empty fields set to their defaults.
+func (opts DeployOptions) withDefaults() DeployOptions {
+	if opts.ConfigPath == "" {
+		opts.ConfigPath = "config.yaml"
+	}
+	if opts.OutputDir == "" {
+		opts.OutputDir = "public"
+	}
+	return opts
+}
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	ConfigPath string // Path to config.yaml (default: "config.yaml")
+	OutputDir  string // Directory to write the export to (default: "export")
+	Format     string // Export format; only "json" (the default) is supported
+	Drafts     bool   // Include draft posts
+}
+
+// withDefaults returns opts with empty fields set to their defaults.
+func (opts ExportOptions) withDefaults() ExportOptions {
+	if opts.ConfigPath == "" {
+		opts.ConfigPath = "config.yaml"
+	}
+	if opts.OutputDir == "" {
+		opts.OutputDir = "export"
+	}
+	if opts.Format == "" {
+		opts.Format = "json"
+	}
+	return opts
+}
+
+// CheckOptions configures Check.
+type CheckOptions struct {
+	ConfigPath string // Path to config.yaml (default: "config.yaml")
+	External   bool   // Also check links to other sites, reporting dead ones
+}
+
+// withDefaults returns opts with empty fields set to their defaults.
+func (opts CheckOptions) withDefaults() CheckOptions {
+	if opts.ConfigPath == "" {
+		opts.ConfigPath = "config.yaml"
+	}
+	return opts
+}
+
+// NewsletterOptions configures Newsletter.
+type NewsletterOptions struct {
+	ConfigPath string // Path to config.yaml (default: "config.yaml")
+	Output     string // HTML file to write; the plain text goes next to it as .txt (default: "newsletter.html")
+	Count      int    // Number of recent posts to include (default: 5)
+}
+
+// withDefaults returns opts with empty fields set to their defaults.
+func (opts NewsletterOptions) withDefaults() NewsletterOptions {
+	if opts.ConfigPath == "" {
+		opts.ConfigPath = "config.yaml"
+	}
+	if opts.Output == "" {
+		opts.Output = "newsletter.html"
+	}
+	if opts.Count <= 0 {
+		opts.Count = 5
+	}
+	return opts
+}
+
+// withDefaults returns opts with empty fields set to their defaults, and
+// short environment names ("prod", "dev") expanded.
+func (opts BuildOptions) withDefaults() BuildOptions {
+	if opts.ConfigPath == "" {
+		opts.ConfigPath = "config.yaml"
+	}
+	if opts.OutputDir == "" {
+		opts.OutputDir = "public"
+	}
+	if opts.Environment == "" {
+		opts.Environment = os.Getenv("SSG_ENV")
+	}
+	if opts.Environment == "" {
+		opts.Environment = EnvProduction
+	}
+	if name, ok := envAliases[opts.Environment]; ok {
+		opts.Environment = name
+	}
+	if opts.Source == nil {
+		opts.Source = DirFS(".")
+	}
+	if opts.Output == nil {
+		opts.Output = opts.Source
+	}
+	return opts
+}
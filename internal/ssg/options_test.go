@@ -0,0 +1,75 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuildOptions_WithDefaults tests that empty options get their defaults
+func TestBuildOptions_WithDefaults(t *testing.T) {
+	t.Setenv("SSG_ENV", "")
+	got := BuildOptions{}.withDefaults()
+	if got.ConfigPath != "config.yaml" || got.OutputDir != "public" || got.Environment != EnvProduction {
+		t.Errorf("withDefaults() = %+v", got)
+	}
+
+	got = BuildOptions{OutputDir: "dist", Environment: EnvDevelopment}.withDefaults()
+	if got.OutputDir != "dist" || got.Environment != EnvDevelopment {
+		t.Errorf("withDefaults() overrode set fields: %+v", got)
+	}
+}
+
+// TestBuildOptions_Environment tests SSG_ENV and short environment names
+func TestBuildOptions_Environment(t *testing.T) {
+	t.Setenv("SSG_ENV", "dev")
+	if got := (BuildOptions{}).withDefaults().Environment; got != EnvDevelopment {
+		t.Errorf("Environment with SSG_ENV=dev = %q, want %q", got, EnvDevelopment)
+	}
+	if got := (BuildOptions{Environment: "prod"}).withDefaults().Environment; got != EnvProduction {
+		t.Errorf("Environment %q = %q, want the option to win over SSG_ENV", "prod", got)
+	}
+}
+
+// TestBuild_Options tests drafts, future posts, baseUrl override, and environment
+func TestBuild_Options(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["content/posts/draft.md"] = "---\ntitle: Draft\ndate: 2024-02-01T10:00:00Z\ndraft: true\n---\n\nWIP.\n"
+	site["content/posts/scheduled.md"] = "---\ntitle: Scheduled\ndate: 2999-01-01T10:00:00Z\n---\n\nLater.\n"
+	site["templates/base.html"] = "<html><head>{{.Head}}</head><body data-env=\"{{.Env}}\">{{template \"posts\" .}}</body></html>"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join("public", "posts", name))
+		return err == nil
+	}
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if !exists("first.html") || exists("draft.html") || exists("scheduled.html") {
+		t.Error("default build should include only published, past posts")
+	}
+
+	opts := BuildOptions{Drafts: true, Future: true, BaseURL: "https://preview.example.com", Environment: EnvDevelopment}
+	if err := Build(context.Background(), opts); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if !exists("draft.html") || !exists("scheduled.html") {
+		t.Error("build with Drafts and Future should include drafts and scheduled posts")
+	}
+
+	index, err := os.ReadFile(filepath.Join("public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`href="https://preview.example.com/"`, `data-env="development"`} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("index missing %s:\n%s", want, index)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package ssg
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats a post can be written in, listed under outputs in the
+// config or a post's frontmatter. Every post gets its HTML page; the others
+// are written next to it, so the same content can be consumed headlessly:
+//
+//	outputs: [html, json, markdown]
+//
+// writes /posts/hello.html, /posts/hello.json (the post's metadata and
+// rendered HTML, as in the JSON API), and /posts/hello.md (its markdown,
+// with the public fields of its frontmatter).
+const (
+	OutputHTML     = "html"
+	OutputJSON     = "json"
+	OutputMarkdown = "markdown"
+)
+
+// outputFormats maps the names accepted under outputs to their formats.
+var outputFormats = map[string]string{
+	"html":     OutputHTML,
+	"json":     OutputJSON,
+	"markdown": OutputMarkdown,
+	"md":       OutputMarkdown,
+}
+
+// outputTypes are the file extensions and media types of the formats
+// written besides HTML.
+var outputTypes = map[string]struct{ ext, mediaType string }{
+	OutputJSON:     {".json", "application/json"},
+	OutputMarkdown: {".md", "text/markdown"},
+}
+
+// PageOutput is the current post in another format, exposed to templates
+// as .Outputs (e.g., for a "view as markdown" link).
+type PageOutput struct {
+	Format    string // OutputJSON or OutputMarkdown
+	MediaType string // MIME type (e.g., "application/json")
+	URL       string // Site-relative URL (e.g., "/posts/hello.json")
+}
+
+// assignOutputs sets each post's Outputs to the formats it's written in
+// besides HTML: those in its frontmatter, or else the config's.
+//
+// Returns an error naming the post (or the config) if a format is unknown.
+func assignOutputs(posts []*parser.Post, config *SiteConfig) error {
+	defaults, err := alternateFormats(config.Outputs)
+	if err != nil {
+		return fmt.Errorf("outputs: %w", err)
+	}
+	for _, post := range posts {
+		if post.Outputs == nil {
+			post.Outputs = defaults
+			continue
+		}
+		if post.Outputs, err = alternateFormats(post.Outputs); err != nil {
+			return fmt.Errorf("post %s: outputs: %w", post.Slug, err)
+		}
+	}
+	return nil
+}
+
+// alternateFormats returns the formats in names other than HTML, once each
+// and in order.
+func alternateFormats(names []string) ([]string, error) {
+	var formats []string
+	for _, name := range names {
+		format, ok := outputFormats[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q (want html, json, or markdown)", name)
+		}
+		if format != OutputHTML && !slices.Contains(formats, format) {
+			formats = append(formats, format)
+		}
+	}
+	return formats, nil
+}
+
+// formatURL returns the URL of the page at url in the format with file
+// extension ext: /posts/hello.json for /posts/hello.html or /posts/hello,
+// and /posts/hello/index.json for /posts/hello/.
+func formatURL(url, ext string) string {
+	switch {
+	case strings.HasSuffix(url, "/"):
+		return url + "index" + ext
+	case strings.HasSuffix(url, ".html"):
+		return strings.TrimSuffix(url, ".html") + ext
+	default:
+		return url + ext
+	}
+}
+
+// postOutputs returns the outputs of post for PageData.
+func postOutputs(post *parser.Post) []PageOutput {
+	var outputs []PageOutput
+	for _, format := range post.Outputs {
+		t := outputTypes[format]
+		outputs = append(outputs, PageOutput{Format: format, MediaType: t.mediaType, URL: formatURL(post.URL, t.ext)})
+	}
+	return outputs
+}
+
+// markdownFrontmatter is the frontmatter of a post's markdown output: its
+// public fields, without build settings like draft or aliases.
+type markdownFrontmatter struct {
+	Title       string    `yaml:"title"`
+	Date        time.Time `yaml:"date"`
+	Description string    `yaml:"description,omitempty"`
+	Tags        []string  `yaml:"tags,omitempty"`
+	URL         string    `yaml:"url"`
+}
+
+// writePostOutputs writes post in each of its formats besides HTML to
+// outputDir in fsys. The JSON output has the fields of the post's JSON API
+// document, with links in its content made absolute using baseURL.
+func writePostOutputs(fsys FS, post *parser.Post, baseURL, outputDir string) error {
+	for _, out := range postOutputs(post) {
+		path := urlPath(outputDir, out.URL)
+		switch out.Format {
+		case OutputJSON:
+			doc := apiPost{
+				apiPostSummary: apiPostSummary{
+					Title:       post.Title,
+					Slug:        post.Slug,
+					URL:         post.URL,
+					API:         out.URL,
+					Date:        post.Date,
+					Description: post.Description,
+					Tags:        post.Tags,
+				},
+				Content: string(absoluteURLs(post.Content, baseURL, post.URL)),
+			}
+			if doc.Tags == nil {
+				doc.Tags = []string{}
+			}
+			if err := writeJSON(fsys, path, doc); err != nil {
+				return err
+			}
+		case OutputMarkdown:
+			fm, err := yaml.Marshal(markdownFrontmatter{
+				Title:       post.Title,
+				Date:        post.Date,
+				Description: post.Description,
+				Tags:        post.Tags,
+				URL:         post.URL,
+			})
+			if err != nil {
+				return fmt.Errorf("encoding frontmatter of %s: %w", out.URL, err)
+			}
+			var buf bytes.Buffer
+			buf.WriteString("---\n")
+			buf.Write(fm)
+			buf.WriteString("---\n\n")
+			buf.WriteString(strings.TrimSpace(post.RawContent) + "\n")
+			if err := fsys.WriteFile(path, buf.Bytes(), 0600); err != nil {
+				return fmt.Errorf("writing %s: %w", out.URL, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package ssg
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestAssignOutputs tests choosing each post's formats from its frontmatter or the config
+func TestAssignOutputs(t *testing.T) {
+	config := &SiteConfig{Outputs: []string{"html", "json"}}
+	plain := &parser.Post{Slug: "plain"}
+	custom := &parser.Post{Slug: "custom", Outputs: []string{"MD", "html", "markdown"}}
+	htmlOnly := &parser.Post{Slug: "html-only", Outputs: []string{"html"}}
+
+	if err := assignOutputs([]*parser.Post{plain, custom, htmlOnly}, config); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(plain.Outputs, []string{OutputJSON}) {
+		t.Errorf("plain.Outputs = %v, want the config's", plain.Outputs)
+	}
+	if !slices.Equal(custom.Outputs, []string{OutputMarkdown}) {
+		t.Errorf("custom.Outputs = %v, want markdown once", custom.Outputs)
+	}
+	if len(htmlOnly.Outputs) != 0 {
+		t.Errorf("htmlOnly.Outputs = %v, want none", htmlOnly.Outputs)
+	}
+
+	bad := &parser.Post{Slug: "bad", Outputs: []string{"pdf"}}
+	if err := assignOutputs([]*parser.Post{bad}, &SiteConfig{}); err == nil || !strings.Contains(err.Error(), "post bad") {
+		t.Errorf("assignOutputs() error = %v, want error naming the post", err)
+	}
+}
+
+// TestFormatURL tests the URLs of a page in other formats for each URL style
+func TestFormatURL(t *testing.T) {
+	for url, want := range map[string]string{
+		"/posts/hello.html": "/posts/hello.json",
+		"/posts/hello":      "/posts/hello.json",
+		"/posts/hello/":     "/posts/hello/index.json",
+	} {
+		if got := formatURL(url, ".json"); got != want {
+			t.Errorf("formatURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+// TestBuild_Outputs tests writing posts as JSON and markdown next to their pages
+func TestBuild_Outputs(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "outputs: [html, json, markdown]\n"
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\ndate: 2024-01-15\ntags: [go]\ndraft: false\n---\n\nHello ![pic](/images/a.png).\n"
+	site["content/posts/2024-01-10-older.md"] = "---\ntitle: Older\noutputs: [html]\n---\n\nOld.\n"
+	site["templates/base.html"] = `<html><head>{{ .Head }}</head><body>{{ template "posts" . }}</body></html>`
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join("public", "posts", "first.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc apiPost
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "First Post" || doc.URL != "/posts/first.html" || !strings.Contains(doc.Content, `src="https://test.com/images/a.png"`) {
+		t.Errorf("first.json = %s, want its metadata and content with absolute URLs", data)
+	}
+
+	md, err := os.ReadFile(filepath.Join("public", "posts", "first.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "---\ntitle: First Post\ndate: 2024-01-15T00:00:00Z\ntags:\n    - go\nurl: /posts/first.html\n---\n\nHello ![pic](/images/a.png).\n"; string(md) != want {
+		t.Errorf("first.md = %q, want %q", md, want)
+	}
+
+	page, err := os.ReadFile(filepath.Join("public", "posts", "first.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), `<link rel="alternate" type="text/markdown" href="/posts/first.md" />`) {
+		t.Errorf("first.html doesn't link its markdown:\n%s", page)
+	}
+
+	if _, err := os.Stat(filepath.Join("public", "posts", "older.json")); err == nil {
+		t.Error("post with outputs: [html] was written as JSON")
+	}
+}
@@ -0,0 +1,160 @@
+package ssg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// bundleIndex returns the path of the index file of the page bundle dir in
+// fsys: the content file (as isContent reports) named index, e.g.
+// "content/posts/trip/index.md". Reports false if dir isn't a page bundle.
+//
+// A page bundle is a post or section entry as a directory, with its images
+// and other files next to its index file. They're copied next to the
+// rendered page, so the content can link to them with relative links (see
+// assignResources).
+func bundleIndex(fsys fs.FS, dir string, isContent func(name string) bool) (string, bool) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.TrimSuffix(name, filepath.Ext(name)) == "index" && isContent(name) {
+			return filepath.Join(dir, name), true
+		}
+	}
+	return "", false
+}
+
+// contentPaths returns the content files in dir in fsys (those isContent
+// reports true for), in name order: its files other than the section index,
+// and the index files of the page bundles in it. Returns nil if dir doesn't
+// exist.
+func contentPaths(fsys fs.FS, dir string, isContent func(name string) bool) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.IsDir():
+			if index, ok := bundleIndex(fsys, path, isContent); ok {
+				paths = append(paths, index)
+			}
+		case isContent(entry.Name()) && entry.Name() != sectionIndexFile:
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// bundleURL returns the site-relative URL of the directory the resources
+// of the page at pageURL are copied to: the page's own directory for
+// "/posts/trip/", and "/posts/trip/" for "/posts/trip.html" or "/posts/trip".
+func bundleURL(pageURL string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(pageURL, ".html"), "/") + "/"
+}
+
+// bundleResources returns the files of the page bundle of post, relative to
+// its directory and slash-separated: every file but its index file and
+// hidden files.
+func bundleResources(fsys fs.FS, post *parser.Post) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(fsys, post.Bundle, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != post.Bundle {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Dir(p) == post.Bundle && strings.TrimSuffix(d.Name(), filepath.Ext(d.Name())) == "index" {
+			return nil
+		}
+		rel, err := filepath.Rel(post.Bundle, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+// assignResources sets the Resources of the posts that are page bundles,
+// once their URLs are assigned, to the URLs their files are copied to (see
+// bundleURL). Relative links in their content to those files are rewritten
+// to the files' URLs, so they work whatever the URL style, and in feeds.
+func assignResources(fsys fs.FS, posts []*parser.Post) error {
+	for _, post := range posts {
+		if post.Bundle == "" {
+			continue
+		}
+		files, err := bundleResources(fsys, post)
+		if err != nil {
+			return fmt.Errorf("reading page bundle %s: %w", post.Bundle, err)
+		}
+		dir := bundleURL(post.URL)
+		post.Resources = make([]string, len(files))
+		urls := make(map[string]string, len(files))
+		for i, file := range files {
+			post.Resources[i] = dir + file
+			urls[file] = dir + file
+		}
+		post.Content = rewriteURLs(post.Content, func(ref string) string {
+			return resourceURL(ref, urls)
+		})
+	}
+	return nil
+}
+
+// resourceURL returns the URL of the bundle resource a relative link refers
+// to, given the URLs of the resources by path in the bundle, or ref if it
+// refers to none.
+func resourceURL(ref string, urls map[string]string) string {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return ref
+	}
+	resource, ok := urls[path.Clean(u.Path)]
+	if !ok {
+		return ref
+	}
+	u.Path = resource
+	return u.String()
+}
+
+// writeBundleResources copies the files of post's page bundle in src, if it
+// is one, to the URLs in its Resources under outputDir in out.
+func writeBundleResources(src fs.FS, out FS, post *parser.Post, outputDir string) error {
+	if post.Bundle == "" {
+		return nil
+	}
+	dir := bundleURL(post.URL)
+	for _, resource := range post.Resources {
+		file := filepath.Join(post.Bundle, filepath.FromSlash(strings.TrimPrefix(resource, dir)))
+		data, err := fs.ReadFile(src, file)
+		if err != nil {
+			return err
+		}
+		if err := out.WriteFile(urlPath(outputDir, resource), data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
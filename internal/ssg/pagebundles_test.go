@@ -0,0 +1,94 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBuild_PageBundles tests posts and section entries as page bundles
+func TestBuild_PageBundles(t *testing.T) {
+	for _, style := range []string{"", URLStyleSlash} {
+		t.Run("urls="+style, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			site := testSite()
+			site["config.yaml"] += "urls: " + style + "\n"
+			site["templates/post.html"] = "{{define \"posts\"}}{{.Post.Content}}{{range .Post.Resources}}[{{.}}]{{end}}{{end}}"
+			site["content/posts/2024-02-01-trip/index.md"] = "---\ntitle: Trip\n---\n\n![Lake](lake.jpg) [Map](files/map.pdf#page=2) [Home](/) [Other](first.html)\n"
+			site["content/posts/2024-02-01-trip/lake.jpg"] = "jpeg"
+			site["content/posts/2024-02-01-trip/files/map.pdf"] = "pdf"
+			site["content/posts/2024-02-01-trip/.DS_Store"] = "junk"
+			site["content/notes/vim/index.md"] = "---\ntitle: Vim\n---\n\n![Keys](keys.png)\n"
+			site["content/notes/vim/keys.png"] = "png"
+			writeFiles(t, tmpDir, site)
+			t.Chdir(tmpDir)
+
+			if err := Build(context.Background(), BuildOptions{}); err != nil {
+				t.Fatalf("Build() failed: %v", err)
+			}
+
+			trip := "public/posts/trip.html"
+			if style == URLStyleSlash {
+				trip = "public/posts/trip/index.html"
+			}
+			page, err := os.ReadFile(trip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range []string{
+				`<img src="/posts/trip/lake.jpg" alt="Lake"/>`,
+				`<a href="/posts/trip/files/map.pdf#page=2">Map</a>`,
+				`<a href="/">Home</a>`,
+				`<a href="first.html">Other</a>`,
+				"[/posts/trip/files/map.pdf][/posts/trip/lake.jpg]",
+			} {
+				if !strings.Contains(string(page), want) {
+					t.Errorf("%s missing %q:\n%s", trip, want, page)
+				}
+			}
+			for file, want := range map[string]string{
+				"public/posts/trip/lake.jpg":      "jpeg",
+				"public/posts/trip/files/map.pdf": "pdf",
+				"public/notes/vim/keys.png":       "png",
+			} {
+				if data, err := os.ReadFile(file); err != nil || string(data) != want {
+					t.Errorf("%s = %q, %v; want %q", file, data, err, want)
+				}
+			}
+			for _, file := range []string{"public/posts/trip/index.md", "public/posts/trip/.DS_Store", "public/notes/vim/vim.html"} {
+				if _, err := os.Stat(file); err == nil {
+					t.Errorf("%s written", file)
+				}
+			}
+		})
+	}
+}
+
+// TestCheckSite_PageBundles tests that page bundles are checked like posts
+func TestCheckSite_PageBundles(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["content/posts/2024-02-01-trip/index.md"] = "---\ntitle: Trip\n---\n\n![Lake](lake.jpg)\n"
+	site["content/posts/2024-02-01-trip/lake.jpg"] = "jpeg"
+	site["content/posts/2024-02-02-untitled/index.md"] = "---\ndate: 2024-02-02T10:00:00Z\n---\n\nNo title.\n"
+	site["content/notes/vim/index.md"] = "---\ntitle: Vim\ndate: 2024-03-01T10:00:00Z\n---\n\nText.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	problems, err := checkSite(CheckOptions{ConfigPath: "config.yaml"}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("checkSite() failed: %v", err)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.File+": "+p.Message)
+	}
+	want := []string{filepath.Join("content", "posts", "2024-02-02-untitled", "index.md") + ": missing required field: title"}
+	if !slices.Equal(got, want) {
+		t.Errorf("checkSite() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package ssg
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// URL styles for generated pages, set with urls in config.yaml.
+const (
+	URLStyleHTML          = "html"          // /about.html (default)
+	URLStyleSlash         = "slash"         // /about/, written to about/index.html
+	URLStyleExtensionless = "extensionless" // /about, written to about.html
+)
+
+// checkURLStyle returns an error if style isn't one of the URL styles.
+func checkURLStyle(style string) error {
+	switch style {
+	case "", URLStyleHTML, URLStyleSlash, URLStyleExtensionless:
+		return nil
+	}
+	return fmt.Errorf("urls %q must be %q, %q, or %q", style, URLStyleHTML, URLStyleSlash, URLStyleExtensionless)
+}
+
+// pageURL returns the URL of the page at path (a site-relative path without
+// an extension, e.g. "/pkg/parser") in the given URL style. Every generated
+// page's URL goes through pageURL, so links are formatted consistently.
+func pageURL(style, path string) string {
+	switch style {
+	case URLStyleSlash:
+		return path + "/"
+	case URLStyleExtensionless:
+		return path
+	default:
+		return path + ".html"
+	}
+}
+
+// assignPostURLs sets the URL of each post from a permalink pattern.
+//
+// The pattern may contain these placeholders:
+//   - :slug: the post's slug
+//   - :year, :month, :day: the post's date (zero-padded)
+//
+// For example, "/:year/:month/:slug/" puts a post dated 2024-01-15 at
+// /2024/01/hello/. The pattern must contain :slug so every post gets a
+// distinct URL. An empty pattern means /posts/:slug in the given URL style
+// (e.g., "/posts/:slug.html"); an explicit pattern is used as written.
+//
+// Returns an error if the pattern is invalid.
+func assignPostURLs(posts []*parser.Post, pattern, style string) error {
+	if pattern == "" {
+		pattern = pageURL(style, "/posts/:slug")
+	}
+	if !strings.HasPrefix(pattern, "/") || !strings.Contains(pattern, ":slug") {
+		return fmt.Errorf("permalink %q must start with / and contain :slug", pattern)
+	}
+
+	for _, post := range posts {
+		post.URL = strings.NewReplacer(
+			":slug", post.Slug,
+			":year", post.Date.Format("2006"),
+			":month", post.Date.Format("01"),
+			":day", post.Date.Format("02"),
+		).Replace(pattern)
+	}
+	return nil
+}
+
+// urlPath returns the file a site-relative URL is written to in outputDir.
+// URLs ending in a slash are written as index.html inside the directory.
+func urlPath(outputDir, url string) string {
+	if strings.HasSuffix(url, "/") {
+		url += "index.html"
+	}
+	return filepath.Join(outputDir, filepath.FromSlash(strings.TrimPrefix(url, "/")))
+}
+
+// pageFile returns the file the HTML page at a site-relative URL is written
+// to in outputDir: like urlPath, but extensionless URLs get ".html" added.
+func pageFile(outputDir, url string) string {
+	if !strings.HasSuffix(url, "/") && !strings.HasSuffix(url, ".html") {
+		url += ".html"
+	}
+	return urlPath(outputDir, url)
+}
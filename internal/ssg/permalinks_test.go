@@ -0,0 +1,159 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestAssignPostURLs tests expanding permalink patterns
+func TestAssignPostURLs(t *testing.T) {
+	date := time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"", "/posts/hello.html"},
+		{"/:year/:month/:day/:slug.html", "/2024/01/05/hello.html"},
+		{"/blog/:year/:slug/", "/blog/2024/hello/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			post := &parser.Post{Slug: "hello", Date: date}
+			if err := assignPostURLs([]*parser.Post{post}, tt.pattern, ""); err != nil {
+				t.Fatalf("assignPostURLs() failed: %v", err)
+			}
+			if post.URL != tt.want {
+				t.Errorf("URL = %q, want %q", post.URL, tt.want)
+			}
+		})
+	}
+}
+
+// TestPageURL tests formatting page URLs in each URL style
+func TestPageURL(t *testing.T) {
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{"", "/pkg/parser.html"},
+		{URLStyleHTML, "/pkg/parser.html"},
+		{URLStyleSlash, "/pkg/parser/"},
+		{URLStyleExtensionless, "/pkg/parser"},
+	}
+	for _, tt := range tests {
+		if got := pageURL(tt.style, "/pkg/parser"); got != tt.want {
+			t.Errorf("pageURL(%q) = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+	if err := checkURLStyle("pretty"); err == nil {
+		t.Error("checkURLStyle(\"pretty\") succeeded, want error")
+	}
+}
+
+// TestAssignPostURLs_Invalid tests that patterns without :slug are rejected
+func TestAssignPostURLs_Invalid(t *testing.T) {
+	for _, pattern := range []string{"/:year/index.html", "posts/:slug.html"} {
+		if err := assignPostURLs(nil, pattern, ""); err == nil {
+			t.Errorf("assignPostURLs(%q) succeeded, want error", pattern)
+		}
+	}
+}
+
+// TestURLPath tests mapping URLs to output files
+func TestURLPath(t *testing.T) {
+	if got, want := urlPath("public", "/posts/a.html"), filepath.Join("public", "posts", "a.html"); got != want {
+		t.Errorf("urlPath() = %q, want %q", got, want)
+	}
+	if got, want := urlPath("public", "/2024/a/"), filepath.Join("public", "2024", "a", "index.html"); got != want {
+		t.Errorf("urlPath() = %q, want %q", got, want)
+	}
+}
+
+// TestPageFile tests mapping page URLs in each style to output files
+func TestPageFile(t *testing.T) {
+	for url, want := range map[string]string{
+		"/about.html": filepath.Join("public", "about.html"),
+		"/about/":     filepath.Join("public", "about", "index.html"),
+		"/about":      filepath.Join("public", "about.html"),
+	} {
+		if got := pageFile("public", url); got != want {
+			t.Errorf("pageFile(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+// TestBuild_URLStyle tests that every kind of page follows the configured URL style
+func TestBuild_URLStyle(t *testing.T) {
+	for _, tt := range []struct {
+		style string
+		files []string
+		links []string
+	}{
+		{URLStyleSlash, []string{"posts/first/index.html", "readme/index.html"}, []string{`href="/posts/first/"`}},
+		{URLStyleExtensionless, []string{"posts/first.html", "readme.html"}, []string{`href="/posts/first"`}},
+	} {
+		t.Run(tt.style, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			site := testSite()
+			site["config.yaml"] += "urls: " + tt.style + "\nmounts:\n  - source: README.md\n"
+			site["README.md"] = "# About\n\nAbout this site.\n"
+			site["templates/page.html"] = "{{define \"posts\"}}{{.Post.Content}}{{end}}"
+			site["templates/posts.html"] = "{{define \"posts\"}}{{range .Posts}}<a href=\"{{.URL}}\">{{.Title}}</a>\n{{end}}{{end}}"
+			writeFiles(t, tmpDir, site)
+			t.Chdir(tmpDir)
+
+			if err := Build(context.Background(), BuildOptions{}); err != nil {
+				t.Fatalf("Build() failed: %v", err)
+			}
+			for _, name := range tt.files {
+				if _, err := os.Stat(filepath.Join("public", filepath.FromSlash(name))); err != nil {
+					t.Errorf("%s not generated: %v", name, err)
+				}
+			}
+			index, err := os.ReadFile(filepath.Join("public", "index.html"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, link := range tt.links {
+				if !strings.Contains(string(index), link) {
+					t.Errorf("index missing %s, got:\n%s", link, index)
+				}
+			}
+		})
+	}
+}
+
+// TestBuild_Permalink tests that posts are written and linked at their permalinks
+func TestBuild_Permalink(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "permalink: /:year/:month/:slug/\n"
+	site["templates/posts.html"] = "{{define \"posts\"}}{{range .Posts}}<a href=\"{{.URL}}\">{{.Title}}</a>\n{{end}}{{end}}"
+	site["content/posts/undated-name.md"] = "---\ntitle: Undated Name\ndate: 2024-03-02T10:00:00Z\n---\n\nNo date in the filename.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{Strict: true}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	for _, path := range []string{"public/2024/01/first/index.html", "public/2024/03/undated-name/index.html"} {
+		if _, err := os.Stat(filepath.FromSlash(path)); err != nil {
+			t.Errorf("%s not generated: %v", path, err)
+		}
+	}
+	index, err := os.ReadFile(filepath.Join("public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `href="/2024/01/first/"`) {
+		t.Errorf("index doesn't link to the permalink, got:\n%s", index)
+	}
+}
@@ -0,0 +1,81 @@
+package ssg
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+)
+
+// Default modes of generated output: readable by everyone, so a web server
+// running as another user can serve public/.
+const (
+	defaultFileMode fs.FileMode = 0644
+	defaultDirMode  fs.FileMode = 0755
+)
+
+// PermissionsConfig sets the modes of files and directories in the output.
+//
+// Example config.yaml:
+//
+//	permissions:
+//	  file: "0640"
+//	  dir: "0750"
+type PermissionsConfig struct {
+	File string `yaml:"file"` // Octal mode of generated files (default: 0644)
+	Dir  string `yaml:"dir"`  // Octal mode of generated directories (default: 0755)
+}
+
+// modes returns the configured file and directory modes, or an error if one
+// isn't a valid octal permission.
+func (c PermissionsConfig) modes() (file, dir fs.FileMode, err error) {
+	file, err = parseMode("permissions.file", c.File, defaultFileMode)
+	if err != nil {
+		return 0, 0, err
+	}
+	dir, err = parseMode("permissions.dir", c.Dir, defaultDirMode)
+	if err != nil {
+		return 0, 0, err
+	}
+	return file, dir, nil
+}
+
+// parseMode parses an octal permission like "0644", returning def if s is
+// empty.
+func parseMode(name, s string, def fs.FileMode) (fs.FileMode, error) {
+	if s == "" {
+		return def, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("%s %q must be an octal permission like 0644", name, s)
+	}
+	return fs.FileMode(mode), nil
+}
+
+// setOutputModes sets every file in outputDir in out to fileMode and every directory
+// (including outputDir) to dirMode, except the preserved paths (see
+// preserved), which keep their modes.
+//
+// The build steps write files with whatever mode suits them (rendered pages
+// are created subject to the umask, static files keep their source modes), so
+// this runs last to leave the output consistent. Chmod isn't affected by the
+// umask, so the modes are exactly as configured.
+func setOutputModes(out FS, outputDir string, fileMode, dirMode fs.FileMode, preserve []string) error {
+	return fs.WalkDir(out, outputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(outputDir, path); err == nil && rel != "." && preserved(filepath.ToSlash(rel), preserve) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		mode := fileMode
+		if d.IsDir() {
+			mode = dirMode
+		}
+		return out.Chmod(path, mode) // #nosec G302 -- mode set by the site's config; output is meant to be served
+	})
+}
@@ -0,0 +1,63 @@
+//go:build unix
+
+package ssg
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// TestBuild_Permissions tests that output modes follow the config regardless of umask
+func TestBuild_Permissions(t *testing.T) {
+	old := syscall.Umask(0077)
+	defer syscall.Umask(old)
+
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "permissions:\n  file: \"0640\"\n  dir: 0750\n"
+	site["static/js/app.js"] = "console.log(1)"
+	writeFiles(t, tmpDir, site)
+	if err := os.Chmod(filepath.Join(tmpDir, "static", "js", "app.js"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	for name, want := range map[string]fs.FileMode{
+		"public":                  0750,
+		"public/posts":            0750,
+		"public/index.html":       0640,
+		"public/posts/first.html": 0640,
+		"public/js/app.js":        0640,
+	} {
+		info, err := os.Stat(filepath.FromSlash(name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s mode = %o, want %o", name, got, want)
+		}
+	}
+}
+
+// TestPermissionsConfig_Modes tests defaults and invalid modes
+func TestPermissionsConfig_Modes(t *testing.T) {
+	file, dir, err := PermissionsConfig{}.modes()
+	if err != nil || file != 0644 || dir != 0755 {
+		t.Errorf("modes() = %o, %o, %v, want 644, 755", file, dir, err)
+	}
+
+	for _, c := range []PermissionsConfig{{File: "0999"}, {Dir: "rwx"}, {File: "01777"}} {
+		if _, _, err := c.modes(); err == nil {
+			t.Errorf("modes(%+v) succeeded, want error", c)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package ssg
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// Plugin extends a build with hooks called at fixed points, so programs
+// embedding the generator can add pages, files, or rewrites without forking
+// it. A build calls, in order:
+//
+//   - BeforeBuild once the config and data files are loaded, before any
+//     content is read. Plugins may change b.Config.
+//   - AfterParse for every published post and section entry, before series
+//     and taxonomies are collected, so changes to tags and other fields show
+//     up everywhere the post does.
+//   - AfterRender for every rendered page, after the HTML transformers,
+//     minification, and post-processors, with the HTML about to be written.
+//   - AfterBuild once every page and static file is written, with the
+//     site's content in b, to write pages (see BuildContext.RenderPage) or
+//     files of its own.
+//
+// Plugins that need only some hooks embed BasePlugin. Returning an error
+// from any hook fails the build.
+type Plugin interface {
+	Name() string
+	BeforeBuild(b *BuildContext) error
+	AfterParse(post *parser.Post) error
+	AfterRender(page *Page) error
+	AfterBuild(b *BuildContext) error
+}
+
+// BasePlugin implements every Plugin hook but Name as a no-op, for plugins
+// to embed.
+type BasePlugin struct{}
+
+// BeforeBuild does nothing.
+func (BasePlugin) BeforeBuild(*BuildContext) error { return nil }
+
+// AfterParse does nothing.
+func (BasePlugin) AfterParse(*parser.Post) error { return nil }
+
+// AfterRender does nothing.
+func (BasePlugin) AfterRender(*Page) error { return nil }
+
+// AfterBuild does nothing.
+func (BasePlugin) AfterBuild(*BuildContext) error { return nil }
+
+// BuildContext is the state of a build as seen by plugins. The content
+// fields are set once the content is loaded, so they're empty in
+// BeforeBuild.
+type BuildContext struct {
+	Config     *SiteConfig    // Site config, with .Data loaded
+	OutputDir  string         // Directory the site is written to
+	Output     FS             // Filesystem OutputDir is in; plugins write their files through it
+	Posts      []*parser.Post // Published posts, newest first
+	Sections   []*Section     // Sections under content/, with their entries
+	Series     []*Series      // Series, in name order
+	Taxonomies []*Taxonomy    // Taxonomies, in config order
+	Pages      []*parser.Post // Pages mounted from files outside content/
+	Packages   []*PackageDoc  // Go packages documented under /pkg/
+
+	renderer *Renderer
+	shard    shard
+}
+
+// Owns reports whether this build writes the page at url. On a sharded
+// build (see BuildOptions.Shard) each page is written by one shard only.
+func (b *BuildContext) Owns(url string) bool {
+	return b.shard.owns(url)
+}
+
+// First reports whether this build writes the site-wide files, like
+// sitemap.xml: always, unless the build is a shard other than the first.
+func (b *BuildContext) First() bool {
+	return b.shard.first()
+}
+
+// RenderPage renders a page at the site-relative url with the site's
+// templates, using the first of the content templates that exists (or
+// "posts.html"). data.Site is usually *b.Config. Does nothing if the build
+// doesn't own url.
+func (b *BuildContext) RenderPage(url string, data PageData, templates ...string) error {
+	if !b.Owns(url) {
+		return nil
+	}
+	data.URL = url
+	return b.renderer.renderToFile(b.renderer.contentTemplate(templates...), data, pageFile(b.OutputDir, url))
+}
+
+var (
+	registeredPluginsMu sync.Mutex
+	registeredPlugins   []Plugin
+)
+
+// RegisterPlugin adds a plugin to every build started afterwards. Plugins'
+// hooks run in registration order, after the built-in plugins'; registering
+// a plugin with the name of an earlier one replaces it in its original
+// position.
+func RegisterPlugin(p Plugin) {
+	registeredPluginsMu.Lock()
+	defer registeredPluginsMu.Unlock()
+	for i, q := range registeredPlugins {
+		if q.Name() == p.Name() {
+			registeredPlugins[i] = p
+			return
+		}
+	}
+	registeredPlugins = append(registeredPlugins, p)
+}
+
+// sitePlugins returns the plugins of a build: the built-in ones, which
+// write taxonomy pages, the JSON API, sitemap.xml, RSS feeds, and
+// robots.txt and humans.txt, then the registered ones.
+func sitePlugins() []Plugin {
+	registeredPluginsMu.Lock()
+	defer registeredPluginsMu.Unlock()
+	plugins := []Plugin{taxonomyPlugin{}, apiPlugin{}, sitemapPlugin{}, feedsPlugin{}, robotsPlugin{}}
+	return append(plugins, registeredPlugins...)
+}
+
+// runPlugins calls hook for each plugin, stopping at the first error, which
+// names the plugin.
+func runPlugins(plugins []Plugin, hook func(Plugin) error) error {
+	for _, p := range plugins {
+		if err := hook(p); err != nil {
+			return fmt.Errorf("plugin %s: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
+
+// pluginPostProcessors returns the AfterRender hooks of plugins as
+// post-processors, run after the registered ones.
+func pluginPostProcessors(plugins []Plugin) []namedPostProcessor {
+	var processors []namedPostProcessor
+	for _, p := range plugins {
+		processors = append(processors, namedPostProcessor{name: "plugin " + p.Name(), p: PostProcessorFunc(p.AfterRender)})
+	}
+	return processors
+}
+
+// taxonomyPlugin renders the page of each taxonomy and its terms (see
+// Taxonomy). The taxonomies themselves are collected by the build, since
+// every page can list them.
+type taxonomyPlugin struct{ BasePlugin }
+
+func (taxonomyPlugin) Name() string { return "taxonomies" }
+
+func (taxonomyPlugin) AfterBuild(b *BuildContext) error {
+	for _, tax := range b.Taxonomies {
+		if b.Owns(tax.URL) {
+			if err := b.renderer.renderTaxonomy(tax, *b.Config, pageFile(b.OutputDir, tax.URL)); err != nil {
+				return fmt.Errorf("rendering taxonomy %s: %w", tax.Name, err)
+			}
+		}
+		if tax.Name == "series" {
+			continue // The series pages are its term pages
+		}
+		for _, term := range tax.Terms {
+			if !b.Owns(term.URL) {
+				continue
+			}
+			if err := b.renderer.renderTerm(tax, term, *b.Config, pageFile(b.OutputDir, term.URL)); err != nil {
+				return fmt.Errorf("rendering %s %s: %w", tax.Name, term.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// apiPlugin writes the JSON API of the posts, the site's feed, once per
+// language (see APIConfig).
+type apiPlugin struct{ BasePlugin }
+
+func (apiPlugin) Name() string { return "api" }
+
+func (apiPlugin) AfterBuild(b *BuildContext) error {
+	if !b.First() {
+		return nil
+	}
+	for _, lang := range indexLanguages(*b.Config) {
+		posts := postsInLanguage(b.Posts, lang)
+		if err := writeAPI(posts, b.Config.API, b.Config.BaseURL, b.Config.languageURL(lang, "/api/"), b.Output, b.OutputDir); err != nil {
+			return fmt.Errorf("writing JSON API: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package ssg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// recordingPlugin records the hooks it's called with, tags every post, and
+// writes a page listing the posts
+type recordingPlugin struct {
+	BasePlugin
+	calls []string
+}
+
+func (p *recordingPlugin) Name() string { return "recording" }
+
+func (p *recordingPlugin) BeforeBuild(b *BuildContext) error {
+	p.calls = append(p.calls, "before")
+	b.Config.Title = "Plugged Blog"
+	return nil
+}
+
+func (p *recordingPlugin) AfterParse(post *parser.Post) error {
+	p.calls = append(p.calls, "parse "+post.Slug)
+	post.Tags = append(post.Tags, "plugged")
+	return nil
+}
+
+func (p *recordingPlugin) AfterRender(page *Page) error {
+	p.calls = append(p.calls, "render "+filepath.Base(page.Path))
+	page.Content = append(page.Content, []byte("<!-- plugged -->")...)
+	return nil
+}
+
+func (p *recordingPlugin) AfterBuild(b *BuildContext) error {
+	p.calls = append(p.calls, "after")
+	return b.RenderPage("/archive.html", PageData{Site: *b.Config, Posts: b.Posts, Title: "Archive"})
+}
+
+// TestRegisterPlugin tests that a registered plugin's hooks run during a build
+func TestRegisterPlugin(t *testing.T) {
+	plugin := &recordingPlugin{}
+	RegisterPlugin(plugin)
+	defer func() {
+		registeredPluginsMu.Lock()
+		registeredPlugins = nil
+		registeredPluginsMu.Unlock()
+	}()
+
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "taxonomies: [tags]\n"
+	site["templates/posts.html"] = `{{define "posts"}}{{ range .Posts }}<a href="{{ .URL }}">{{ .Title }}</a>{{ end }}{{end}}`
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	calls := strings.Join(plugin.calls, ",")
+	if !strings.HasPrefix(calls, "before,parse first,") || !strings.Contains(calls, "render first.html") || !strings.Contains(calls, ",after,render archive.html") {
+		t.Errorf("hooks ran as %s, want before, parse, renders, then after", calls)
+	}
+
+	index, err := os.ReadFile(filepath.Join("public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "Plugged Blog") {
+		t.Errorf("index.html = %s, want the title set in BeforeBuild", index)
+	}
+
+	archive, err := os.ReadFile(filepath.Join("public", "archive.html"))
+	if err != nil {
+		t.Fatalf("page rendered in AfterBuild wasn't written: %v", err)
+	}
+	for _, want := range []string{"<title>Archive</title>", "First Post", "<!-- plugged -->"} {
+		if !strings.Contains(string(archive), want) {
+			t.Errorf("archive.html doesn't contain %q:\n%s", want, archive)
+		}
+	}
+	if _, err := os.Stat(filepath.Join("public", "tags", "plugged.html")); err != nil {
+		t.Errorf("tag added in AfterParse has no term page: %v", err)
+	}
+}
+
+// failingPlugin fails its AfterBuild hook
+type failingPlugin struct{ BasePlugin }
+
+func (failingPlugin) Name() string { return "failing" }
+
+func (failingPlugin) AfterBuild(*BuildContext) error { return errors.New("boom") }
+
+// TestRegisterPlugin_Error tests that a failing hook fails the build, naming the plugin
+func TestRegisterPlugin_Error(t *testing.T) {
+	RegisterPlugin(failingPlugin{})
+	defer func() {
+		registeredPluginsMu.Lock()
+		registeredPlugins = nil
+		registeredPluginsMu.Unlock()
+	}()
+
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+	t.Chdir(tmpDir)
+
+	err := Build(context.Background(), BuildOptions{})
+	if err == nil || !strings.Contains(err.Error(), "plugin failing") {
+		t.Errorf("Build() error = %v, want error naming the plugin", err)
+	}
+}
@@ -0,0 +1,104 @@
+package ssg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// preserved reports whether the slash-separated path rel, relative to the
+// output directory, matches one of the preserve patterns from the config.
+// Patterns are matched with path.Match against the path and each of its
+// parent directories, so ".git" keeps everything under .git/ and
+// "uploads/*.pdf" keeps the PDFs in uploads/.
+func preserved(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		for p := rel; p != "." && p != ""; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkPreserve returns an error if a preserve pattern is malformed.
+func checkPreserve(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("preserve pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// cleanOutput empties outputDir in out for a build, creating it if it
+// doesn't exist. Files and directories matching the preserve patterns are kept (see
+// preserved), along with the directories containing them; everything else
+// is removed.
+func cleanOutput(out FS, outputDir string, preserve []string) error {
+	if len(preserve) == 0 {
+		if err := out.RemoveAll(outputDir); err != nil {
+			return err
+		}
+		return out.MkdirAll(outputDir, 0750)
+	}
+
+	var dirs []string
+	err := fs.WalkDir(out, outputDir, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p == outputDir {
+			return filepath.SkipAll
+		}
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(outputDir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		if preserved(filepath.ToSlash(rel), preserve) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			dirs = append(dirs, p)
+			return nil
+		}
+		return out.RemoveAll(p)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Remove directories left empty, deepest first
+	for _, dir := range slices.Backward(dirs) {
+		entries, err := out.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			if err := out.RemoveAll(dir); err != nil {
+				return err
+			}
+		}
+	}
+	return out.MkdirAll(outputDir, 0750)
+}
+
+// preservePatterns returns the preserve patterns of the config at
+// configPath in fsys in the environment env, or nil if it can't be loaded
+// (in which case the build reports why).
+func preservePatterns(fsys fs.FS, configPath, env string) []string {
+	config, err := loadConfigEnv(fsys, configPath, env)
+	if err != nil {
+		return nil
+	}
+	return config.Preserve
+}
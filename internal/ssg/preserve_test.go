@@ -0,0 +1,97 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestPreserved tests matching output paths against preserve patterns
+func TestPreserved(t *testing.T) {
+	patterns := []string{".git", "CNAME", "/uploads/*.pdf"}
+	for rel, want := range map[string]bool{
+		".git":                true,
+		".git/objects/ab/cd":  true,
+		"CNAME":               true,
+		"uploads/a.pdf":       true,
+		"uploads/a.png":       false,
+		"posts/first.html":    false,
+		"posts/.git/whatever": false,
+	} {
+		if got := preserved(rel, patterns); got != want {
+			t.Errorf("preserved(%q) = %v, want %v", rel, got, want)
+		}
+	}
+	if err := checkPreserve([]string{"[a-"}); err == nil {
+		t.Error("checkPreserve() accepted a malformed pattern")
+	}
+}
+
+// TestBuild_Preserve tests keeping preserved paths in the output directory across builds
+func TestBuild_Preserve(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "preserve: [.git, CNAME, uploads]\npermissions:\n  file: \"0640\"\n"
+	writeFiles(t, tmpDir, site)
+	writeFiles(t, tmpDir, map[string]string{
+		"public/.git/HEAD":        "ref: refs/heads/gh-pages\n",
+		"public/CNAME":            "example.com\n",
+		"public/uploads/cv.pdf":   "pdf",
+		"public/old/stale.html":   "stale",
+		"public/posts/first.html": "stale",
+	})
+	head := filepath.Join(tmpDir, "public", ".git", "HEAD")
+	if err := os.Chmod(head, 0400); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	for _, p := range []string{".git/HEAD", "CNAME", "uploads/cv.pdf", "posts/first.html"} {
+		if _, err := os.Stat(filepath.Join("public", p)); err != nil {
+			t.Errorf("public/%s missing after build: %v", p, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join("public", "old")); !os.IsNotExist(err) {
+		t.Errorf("public/old kept, want it removed (stat error = %v)", err)
+	}
+	if info, err := os.Stat(head); err != nil || info.Mode().Perm() != 0400 {
+		t.Errorf("mode of preserved file changed: %v, %v", info.Mode(), err)
+	}
+
+	m, err := readManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Files["CNAME"]; ok {
+		t.Error("manifest lists preserved CNAME")
+	}
+
+	changes, err := dryRun(context.Background(), BuildOptions{DryRun: true}.withDefaults(), os.Stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("dry run changes = %v, want none for preserved files", changes)
+	}
+}
+
+// TestSyncOutput_Preserve tests that watch rebuilds leave preserved paths alone
+func TestSyncOutput_Preserve(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	writeFiles(t, src, map[string]string{"index.html": "new"})
+	writeFiles(t, dst, map[string]string{"index.html": "old", "CNAME": "example.com", "gone.html": "x"})
+
+	if _, _, err := syncOutput(src, dst, []string{"CNAME"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "CNAME")); err != nil {
+		t.Errorf("CNAME removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "gone.html")); !os.IsNotExist(err) {
+		t.Error("gone.html kept, want it removed")
+	}
+}
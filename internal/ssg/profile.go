@@ -0,0 +1,93 @@
+package ssg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/pprof"
+	"sort"
+	"time"
+)
+
+// profileTopPosts is how many of the slowest posts a profile report lists.
+const profileTopPosts = 10
+
+// profileEntry is a stage of a build, or a post, and how long it took.
+type profileEntry struct {
+	name     string
+	duration time.Duration
+}
+
+// buildProfile records where the time of a build went, for
+// BuildOptions.Profile. Its methods do nothing on a nil profile, so builds
+// without one don't need to check.
+type buildProfile struct {
+	stages []profileEntry // In build order (see stageTimer)
+	posts  []profileEntry // Rendering time of each post and section entry, by URL
+}
+
+// addStage records that stage took d.
+func (p *buildProfile) addStage(stage string, d time.Duration) {
+	if p != nil {
+		p.stages = append(p.stages, profileEntry{stage, d})
+	}
+}
+
+// addPost records that rendering the post at url (its page, QR code, and
+// other output formats) took d.
+func (p *buildProfile) addPost(url string, d time.Duration) {
+	if p != nil {
+		p.posts = append(p.posts, profileEntry{url, d})
+	}
+}
+
+// write prints the profile of a build that took total to w: the time and
+// share of each stage, then the slowest posts.
+func (p *buildProfile) write(w io.Writer, total time.Duration) {
+	fmt.Fprintf(w, "Build profile: %s\n", total.Round(time.Millisecond))
+	fmt.Fprintln(w, "  Stages:")
+	for _, s := range p.stages {
+		fmt.Fprintf(w, "    %-16s %8s %5.1f%%\n", s.name, s.duration.Round(time.Microsecond*100), percent(s.duration, total))
+	}
+	if len(p.posts) == 0 {
+		return
+	}
+
+	posts := make([]profileEntry, len(p.posts))
+	copy(posts, p.posts)
+	sort.SliceStable(posts, func(i, j int) bool { return posts[i].duration > posts[j].duration })
+	var sum time.Duration
+	for _, post := range posts {
+		sum += post.duration
+	}
+	fmt.Fprintf(w, "  Slowest posts (%d rendered in %s, %s on average):\n",
+		len(posts), sum.Round(time.Microsecond*10), (sum / time.Duration(len(posts))).Round(time.Microsecond*10))
+	for _, post := range posts[:min(len(posts), profileTopPosts)] {
+		fmt.Fprintf(w, "    %8s  %s\n", post.duration.Round(time.Microsecond*10), post.name)
+	}
+}
+
+// percent returns d as a percentage of total.
+func percent(d, total time.Duration) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return 100 * float64(d) / float64(total)
+}
+
+// startCPUProfile starts writing a pprof CPU profile to path, returning a
+// function that stops it and closes the file.
+func startCPUProfile(path string) (stop func() error, err error) {
+	f, err := os.Create(path) // #nosec G304 -- path given by the user with --cpu-profile
+	if err != nil {
+		return nil, fmt.Errorf("creating CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("starting CPU profile: %w", err)
+	}
+	return func() error {
+		pprof.StopCPUProfile()
+		return f.Close()
+	}, nil
+}
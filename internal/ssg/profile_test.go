@@ -0,0 +1,65 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBuildProfile_Write tests the report of stage times and the slowest posts
+func TestBuildProfile_Write(t *testing.T) {
+	p := &buildProfile{}
+	p.addStage("content", 30*time.Millisecond)
+	p.addStage("pages", 70*time.Millisecond)
+	for i := range 12 {
+		p.addPost(fmt.Sprintf("/posts/%d.html", i), time.Duration(i+1)*time.Millisecond)
+	}
+
+	var buf bytes.Buffer
+	p.write(&buf, 100*time.Millisecond)
+	out := buf.String()
+	for _, want := range []string{
+		"Build profile: 100ms",
+		"content",
+		" 30.0%",
+		" 70.0%",
+		"12 rendered in 78ms, 6.5ms on average",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(out, "/posts/11.html") || strings.Contains(out, "/posts/1.html\n") {
+		t.Errorf("report should list the %d slowest posts:\n%s", profileTopPosts, out)
+	}
+
+	var none *buildProfile
+	none.addStage("content", time.Second) // No-op on a nil profile
+	none.addPost("/", time.Second)
+}
+
+// TestBuild_Profile tests recording every stage, and writing a CPU profile
+func TestBuild_Profile(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+	t.Chdir(tmpDir)
+
+	p := &buildProfile{}
+	timer := newStageTimer(p)
+	timer.done("config")
+	if len(p.stages) != 1 || p.stages[0].name != "config" {
+		t.Errorf("stages = %v, want config", p.stages)
+	}
+
+	if err := Build(context.Background(), BuildOptions{Profile: true, CPUProfile: "cpu.pprof"}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat("cpu.pprof")
+	if err != nil || info.Size() == 0 {
+		t.Errorf("CPU profile not written: %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+package ssg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ProseConfig configures the prose linting `ssg check` does on the markdown
+// of posts and section entries, drafts included. Problems are reported with
+// the file, line, and column they're on, like other problems check finds.
+//
+// The rules are:
+//   - spelling: words a spell checker doesn't know, unless they're in the
+//     dictionary file. The spell checker is a command that reads text on
+//     stdin and prints the misspelled words, one per line, like
+//     "aspell list" or "hunspell -l"; without one, spelling isn't checked.
+//   - passive: passive voice, like "was written", found by a heuristic that
+//     has false positives
+//   - wordy: phrases with shorter alternatives, like "in order to"
+//
+// Code blocks, inline code, URLs, and HTML tags aren't linted.
+//
+// Example config.yaml:
+//
+//	prose:
+//	  enabled: true
+//	  spell: aspell list --lang=en
+//	  dictionary: data/dictionary.txt
+//	  ignore: [passive]
+type ProseConfig struct {
+	Enabled    bool     `yaml:"enabled"`    // Lint prose during `ssg check`
+	Spell      string   `yaml:"spell"`      // Command printing the misspelled words of the text on its stdin (default: none)
+	Dictionary string   `yaml:"dictionary"` // File of words the spell checker should accept, one per line (default: data/dictionary.txt)
+	Ignore     []string `yaml:"ignore"`     // Rules not to check: spelling, passive, or wordy
+}
+
+// defaultDictionaryPath is the user dictionary read unless prose.dictionary
+// is set.
+var defaultDictionaryPath = filepath.Join(dataDir, "dictionary.txt")
+
+// proseRules are the rules of the prose linter.
+var proseRules = []string{"spelling", "passive", "wordy"}
+
+// passiveVoice matches a form of "to be" followed by a past participle:
+// a word ending in -ed, or a common irregular one. An adverb may come
+// between them ("was quickly fixed").
+var passiveVoice = regexp.MustCompile(`(?i)\b(?:am|is|are|was|were|be|been|being)\s+(?:\w+ly\s+)?(?:\w{2,}ed|` +
+	`known|written|given|taken|seen|done|made|built|sent|shown|found|held|kept|left|paid|said|told|thought|` +
+	`brought|bought|caught|taught|chosen|driven|eaten|forgotten|hidden|spoken|stolen|broken|begun|drawn|` +
+	`grown|thrown|worn|torn|sold|understood|meant|lost|led|set|put|run)\b`)
+
+// wordyPhrases maps wordy phrases to what to write instead ("" to cut them).
+var wordyPhrases = map[string]string{
+	"in order to":                  "to",
+	"due to the fact that":         "because",
+	"in spite of the fact that":    "although",
+	"at this point in time":        "now",
+	"at the present time":          "now",
+	"in the event that":            "if",
+	"for the purpose of":           "for",
+	"has the ability to":           "can",
+	"is able to":                   "can",
+	"a large number of":            "many",
+	"the majority of":              "most",
+	"with regard to":               "about",
+	"in close proximity to":        "near",
+	"prior to":                     "before",
+	"each and every":               "each",
+	"it is important to note that": "",
+	"it should be noted that":      "",
+	"needless to say":              "",
+}
+
+// wordyPhrase matches any of wordyPhrases, longest first.
+var wordyPhrase = func() *regexp.Regexp {
+	phrases := make([]string, 0, len(wordyPhrases))
+	for p := range wordyPhrases {
+		phrases = append(phrases, p)
+	}
+	slices.SortFunc(phrases, func(a, b string) int { return len(b) - len(a) })
+	for i, p := range phrases {
+		phrases[i] = strings.ReplaceAll(regexp.QuoteMeta(p), " ", `\s+`)
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(phrases, "|") + `)\b`)
+}()
+
+// proseWord matches a word for spell checking.
+var proseWord = regexp.MustCompile(`\p{L}+(?:['’]\p{L}+)*`)
+
+// proseMasks match the parts of a markdown line that aren't prose: inline
+// code, link destinations, URLs, HTML tags, and template tags.
+var proseMasks = []*regexp.Regexp{
+	regexp.MustCompile("`[^`]*`"),
+	regexp.MustCompile(`\]\([^)]*\)`),
+	regexp.MustCompile(`https?://\S+`),
+	regexp.MustCompile(`<[^>]*>`),
+	regexp.MustCompile(`\{\{.*?\}\}`),
+}
+
+// referenceDefinition matches a markdown link reference definition, which
+// is skipped.
+var referenceDefinition = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s*\S+`)
+
+// proseLine is a line of prose in a markdown file, with what isn't prose
+// blanked out so columns still match.
+type proseLine struct {
+	Num  int // 1-based line number in the file
+	Text string
+}
+
+// proseLinter lints the prose of markdown files (see ProseConfig).
+type proseLinter struct {
+	cfg        ProseConfig
+	dictionary map[string]bool // Lowercase words to accept
+	spellErr   error           // Why the spell checker failed, after which spelling isn't checked
+}
+
+// newProseLinter returns a linter for cfg, with its dictionary loaded from
+// fsys, or nil if prose linting isn't enabled.
+func newProseLinter(fsys fs.FS, cfg ProseConfig) (*proseLinter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	for _, rule := range cfg.Ignore {
+		if !slices.Contains(proseRules, rule) {
+			return nil, fmt.Errorf("prose.ignore: unknown rule %q", rule)
+		}
+	}
+	path := cfg.Dictionary
+	if path == "" {
+		path = defaultDictionaryPath
+	}
+	l := &proseLinter{cfg: cfg, dictionary: make(map[string]bool)}
+	data, err := fs.ReadFile(fsys, filepath.ToSlash(path))
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && cfg.Dictionary == "":
+	case err != nil:
+		return nil, fmt.Errorf("prose.dictionary: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if word := strings.TrimSpace(line); word != "" && !strings.HasPrefix(word, "#") {
+			l.dictionary[strings.ToLower(word)] = true
+		}
+	}
+	return l, nil
+}
+
+// checks reports whether the linter checks rule.
+func (l *proseLinter) checks(rule string) bool {
+	if rule == "spelling" && (l.cfg.Spell == "" || l.spellErr != nil) {
+		return false
+	}
+	return !slices.Contains(l.cfg.Ignore, rule)
+}
+
+// lint reports the prose problems in the markdown file at path, each at
+// "file:line:col". Jupyter notebooks, being JSON, aren't linted.
+func (l *proseLinter) lint(path string, report func(file, format string, args ...any)) error {
+	if strings.EqualFold(filepath.Ext(path), ".ipynb") {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := proseLines(string(content))
+
+	var misspelled map[string]bool
+	if l.checks("spelling") {
+		misspelled, err = l.misspelled(lines)
+		if err != nil {
+			l.spellErr = err
+		}
+	}
+
+	at := func(line proseLine, idx int) string {
+		return fmt.Sprintf("%s:%d:%d", path, line.Num, utf8.RuneCountInString(line.Text[:idx])+1)
+	}
+	for _, line := range lines {
+		if len(misspelled) > 0 {
+			for _, m := range proseWord.FindAllStringIndex(line.Text, -1) {
+				if word := line.Text[m[0]:m[1]]; misspelled[word] {
+					report(at(line, m[0]), "spelling: %q", word)
+				}
+			}
+		}
+		if l.checks("passive") {
+			for _, m := range passiveVoice.FindAllStringIndex(line.Text, -1) {
+				report(at(line, m[0]), "passive voice: %q", line.Text[m[0]:m[1]])
+			}
+		}
+		if l.checks("wordy") {
+			for _, m := range wordyPhrase.FindAllStringIndex(line.Text, -1) {
+				phrase := line.Text[m[0]:m[1]]
+				if instead := wordyPhrases[strings.ToLower(strings.Join(strings.Fields(phrase), " "))]; instead != "" {
+					report(at(line, m[0]), "wordy: %q (try %q)", phrase, instead)
+				} else {
+					report(at(line, m[0]), "wordy: %q (try cutting it)", phrase)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// misspelled runs the spell checker on lines, returning the words it
+// doesn't know that aren't in the dictionary.
+func (l *proseLinter) misspelled(lines []proseLine) (map[string]bool, error) {
+	var text strings.Builder
+	for _, line := range lines {
+		text.WriteString(line.Text)
+		text.WriteByte('\n')
+	}
+	cmd := shellCommand(context.Background(), l.cfg.Spell)
+	cmd.Stdin = strings.NewReader(text.String())
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w: %s", l.cfg.Spell, err, strings.TrimSpace(stderr.String()))
+	}
+	words := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" && !l.dictionary[strings.ToLower(word)] {
+			words[word] = true
+		}
+	}
+	return words, scanner.Err()
+}
+
+// proseLines returns the lines of prose in a markdown file: those after its
+// frontmatter, outside fenced code blocks and link reference definitions,
+// with inline code, URLs, and tags blanked out (see proseMasks).
+func proseLines(content string) []proseLine {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start < len(lines) && strings.TrimSpace(lines[start]) == "---" {
+		for i := start + 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	var prose []proseLine
+	fence := "" // Marker of the fenced code block the line is in, if any
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimLeft(line, " ")
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if strings.TrimSpace(line) == "" || referenceDefinition.MatchString(line) {
+			continue
+		}
+		for _, mask := range proseMasks {
+			line = mask.ReplaceAllStringFunc(line, func(s string) string {
+				return strings.Repeat(" ", utf8.RuneCountInString(s))
+			})
+		}
+		prose = append(prose, proseLine{Num: i + 1, Text: line})
+	}
+	return prose
+}
@@ -0,0 +1,53 @@
+package ssg
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestProseLines tests that only prose is linted
+func TestProseLines(t *testing.T) {
+	content := "---\ntitle: Was written\n---\n\nSee `in order to` at [docs](https://example.com/x).\n\n" +
+		"```go\nx was fixed\n```\n\n[ref]: https://example.com/y\n<b>Bold</b> text\n"
+	var got []string
+	for _, line := range proseLines(content) {
+		got = append(got, strings.TrimRight(line.Text, " "))
+	}
+	want := []string{"See               at [docs                        .", "   Bold     text"}
+	if !slices.Equal(got, want) {
+		t.Errorf("proseLines() = %q, want %q", got, want)
+	}
+}
+
+// TestCheckSite_Prose tests the prose linting rules
+func TestCheckSite_Prose(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "prose:\n  enabled: true\n  spell: grep -o -w -e teh -e ssg || true\n"
+	site["data/dictionary.txt"] = "# Our words\nSSG\n"
+	site["content/posts/2024-02-01-prose.md"] = "---\ntitle: Prose\ndate: 2024-02-01T10:00:00Z\n---\n\n" +
+		"I wrote teh post with ssg.\n\nIt was quickly written in order to test. `teh` is code.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	problems, err := checkSite(CheckOptions{ConfigPath: "config.yaml"}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("checkSite() failed: %v", err)
+	}
+	var got []string
+	for _, p := range problems {
+		if strings.HasPrefix(p.File, "content/posts/2024-02-01-prose.md") {
+			got = append(got, p.File+": "+p.Message)
+		}
+	}
+	want := []string{
+		`content/posts/2024-02-01-prose.md:6:9: spelling: "teh"`,
+		`content/posts/2024-02-01-prose.md:8:24: wordy: "in order to" (try "to")`,
+		`content/posts/2024-02-01-prose.md:8:4: passive voice: "was quickly written"`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("prose problems = %q\nwant %q", got, want)
+	}
+}
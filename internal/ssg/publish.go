@@ -0,0 +1,189 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// Publish publishes a draft: it finds the post with the slug opts.Slug in
+// the site's content, sets draft: false and its date to now in the
+// frontmatter, and rebuilds the site. The rest of the file, including
+// comments and the order of fields, is left as written.
+//
+// With opts.Rename, a file named by date (e.g., 2024-01-15-hello.md) is
+// renamed to the new date, so content/posts stays in order. With
+// opts.NoBuild, the site isn't rebuilt.
+//
+// Parameters:
+//   - opts: Publish options; Slug is required
+//
+// Returns an error if no post or more than one has the slug, the post isn't
+// a draft, the file can't be updated or renamed, or the build fails.
+func Publish(ctx context.Context, opts PublishOptions) error {
+	opts = opts.withDefaults()
+	if opts.Slug == "" {
+		return fmt.Errorf("slug is required")
+	}
+	config, err := LoadConfig(opts.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	path, content, err := findPost(DirFS("."), config, opts.Slug)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	content, err = publishFrontmatter(content, path, now)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	if opts.Rename {
+		renamed, err := renameByDate(path, now)
+		if err != nil {
+			return err
+		}
+		path = renamed
+	}
+	fmt.Printf("Published %s\n", path)
+
+	if opts.NoBuild {
+		return nil
+	}
+	if err := Build(ctx, BuildOptions{ConfigPath: opts.ConfigPath, OutputDir: opts.OutputDir}); err != nil {
+		return fmt.Errorf("building site: %w", err)
+	}
+	return nil
+}
+
+// findPost returns the path and content of the one markdown file in the
+// site's content directories (see contentDirs) whose slug is slug, as the
+// parser derives it: from its frontmatter, or its file name without the date.
+// Files that can't be parsed are skipped, since the build reports them.
+func findPost(fsys fs.FS, config *SiteConfig, slug string) (string, []byte, error) {
+	files, err := contentFiles(fsys, config)
+	if err != nil {
+		return "", nil, err
+	}
+
+	p := newParser(config)
+	var matches []string
+	var content []byte
+	for _, path := range files {
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return "", nil, err
+		}
+		post, err := p.Parse(data, path)
+		if err != nil || post.Slug != slug {
+			continue
+		}
+		matches = append(matches, path)
+		content = data
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", nil, fmt.Errorf("no post with the slug %q in content/", slug)
+	case 1:
+		return matches[0], content, nil
+	default:
+		return "", nil, fmt.Errorf("%d posts have the slug %q: %s", len(matches), slug, strings.Join(matches, ", "))
+	}
+}
+
+// publishFrontmatter returns content with its frontmatter's draft field set
+// to false and its date set to now, changing only those lines. If the
+// frontmatter has no date, one is added after draft. path is used in
+// errors.
+//
+// Returns an error if the frontmatter can't be parsed, or the post isn't a
+// draft.
+func publishFrontmatter(content []byte, path string, now time.Time) ([]byte, error) {
+	fields, err := parser.Fields(content, path)
+	if err != nil {
+		return nil, err
+	}
+	var draft, date *parser.Field
+	for i := range fields {
+		switch fields[i].Name {
+		case "draft":
+			draft = &fields[i]
+		case "date":
+			date = &fields[i]
+		}
+	}
+	if draft == nil || !strings.EqualFold(draft.Value, "true") {
+		return nil, fmt.Errorf("%s isn't a draft", path)
+	}
+
+	lines := bytes.Split(content, []byte("\n"))
+	setFieldValue(lines, draft, "false")
+	stamp := now.Format(time.RFC3339)
+	if date != nil && date.Set {
+		setFieldValue(lines, date, stamp)
+	} else {
+		if date != nil {
+			lines = append(lines[:date.Line-1], lines[date.Line:]...)
+			if date.Line < draft.Line {
+				draft.Line--
+			}
+		}
+		lines = append(lines[:draft.Line], append([][]byte{[]byte("date: " + stamp)}, lines[draft.Line:]...)...)
+	}
+	return bytes.Join(lines, []byte("\n")), nil
+}
+
+// setFieldValue replaces the value of the scalar field f in lines (the
+// file's lines, as numbered in f) with value, keeping any comment after it.
+func setFieldValue(lines [][]byte, f *parser.Field, value string) {
+	line := lines[f.Line-1]
+	start := f.Column - 1
+	rest := line[start:]
+	end := len(rest)
+	if i := bytes.Index(rest, []byte(" #")); i >= 0 {
+		end = i
+	} else if bytes.HasSuffix(rest, []byte("\r")) {
+		end--
+	}
+	updated := append([]byte{}, line[:start]...)
+	updated = append(updated, value...)
+	updated = append(updated, rest[end:]...)
+	lines[f.Line-1] = updated
+}
+
+// renameByDate renames the file at path, if its name starts with a date
+// (e.g., 2024-01-15-hello.md), to start with the date of now instead, and
+// returns its new path.
+func renameByDate(path string, now time.Time) (string, error) {
+	dir, name := filepath.Split(path)
+	if len(name) < 11 || name[10] != '-' {
+		return path, nil
+	}
+	if _, err := time.Parse("2006-01-02", name[:10]); err != nil {
+		return path, nil
+	}
+	renamed := filepath.Join(dir, now.Format("2006-01-02")+name[10:])
+	if renamed == path {
+		return path, nil
+	}
+	if _, err := os.Stat(renamed); err == nil {
+		return "", fmt.Errorf("can't rename %s: %s already exists", path, renamed)
+	}
+	if err := os.Rename(path, renamed); err != nil {
+		return "", fmt.Errorf("renaming %s: %w", path, err)
+	}
+	return renamed, nil
+}
@@ -0,0 +1,97 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPublish tests publishing a draft, renaming it, and rebuilding
+func TestPublish(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := testSite()
+	files["content/posts/2024-03-01-draft.md"] = "---\ntitle: Draft Post\ndate: 2024-03-01T10:00:00Z # written on the train\ndraft: true\ntags: [go]\n---\n\nAlmost done.\n"
+	writeFiles(t, tmpDir, files)
+	t.Chdir(tmpDir)
+
+	if err := Publish(context.Background(), PublishOptions{Slug: "draft", Rename: true}); err != nil {
+		t.Fatalf("Publish() failed: %v", err)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	renamed := filepath.Join("content", "posts", today+"-draft.md")
+	content, err := os.ReadFile(renamed)
+	if err != nil {
+		t.Fatalf("draft wasn't renamed to %s: %v", renamed, err)
+	}
+	if _, err := os.Stat(filepath.Join("content", "posts", "2024-03-01-draft.md")); today != "2024-03-01" && err == nil {
+		t.Error("the draft's old file still exists")
+	}
+	for _, want := range []string{"\ndraft: false\n", "\ndate: " + today, " # written on the train\n", "\ntags: [go]\n", "Almost done."} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("published post:\n%s\nwant it to contain %q", content, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join("public", "posts", "draft.html")); err != nil {
+		t.Errorf("site wasn't rebuilt with the post: %v", err)
+	}
+
+	if err := Publish(context.Background(), PublishOptions{Slug: "draft", NoBuild: true}); err == nil {
+		t.Error("Publish() of a published post succeeded, want error")
+	}
+	if err := Publish(context.Background(), PublishOptions{Slug: "missing", NoBuild: true}); err == nil {
+		t.Error("Publish() of a missing slug succeeded, want error")
+	}
+}
+
+// TestPublishFrontmatter tests the frontmatter changes of publishing
+func TestPublishFrontmatter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "date and draft",
+			content: "---\ntitle: Post\ndate: 2024-01-15\ndraft: true\n---\nBody\n",
+			want:    "---\ntitle: Post\ndate: 2025-06-01T09:30:00Z\ndraft: false\n---\nBody\n",
+		},
+		{
+			name:    "no date",
+			content: "---\ntitle: Post\ndraft: true # not yet\n---\nBody\n",
+			want:    "---\ntitle: Post\ndraft: false # not yet\ndate: 2025-06-01T09:30:00Z\n---\nBody\n",
+		},
+		{
+			name:    "CRLF",
+			content: "---\r\ntitle: Post\r\ndate: \"2024-01-15\"\r\ndraft: true\r\n---\r\nBody\r\n",
+			want:    "---\r\ntitle: Post\r\ndate: 2025-06-01T09:30:00Z\r\ndraft: false\r\n---\r\nBody\r\n",
+		},
+		{
+			name:    "not a draft",
+			content: "---\ntitle: Post\ndraft: false\n---\nBody\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := publishFrontmatter([]byte(tt.content), "post.md", now)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("publishFrontmatter() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("publishFrontmatter() failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("publishFrontmatter() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
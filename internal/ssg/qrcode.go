@@ -0,0 +1,85 @@
+package ssg
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+	"github.com/skip2/go-qrcode"
+)
+
+// defaultQRCodeScale is the size of a QR code module in pixels unless
+// qrcode.scale is set.
+const defaultQRCodeScale = 8
+
+// QRCodeConfig generates a PNG QR code of each post's URL, for printouts
+// and slides, linked from templates as .Post.QRCode. A post's frontmatter
+// can turn its code on or off with "qrcode: true" or "qrcode: false".
+//
+// Example config.yaml:
+//
+//	qrcode:
+//	  enabled: true
+//	  scale: 10
+type QRCodeConfig struct {
+	Enabled bool `yaml:"enabled"` // Generate codes for every post, not only those with qrcode: true
+	Scale   int  `yaml:"scale"`   // Pixels per module (default: 8)
+}
+
+// assignQRCodes sets QRCode on each post that gets a QR code: those with
+// qrcode: true in their frontmatter, or every post without qrcode: false if
+// the config enables them. The image goes next to the post's page (see
+// qrCodeURL) and is written with writeQRCode when the page is rendered.
+//
+// Returns an error if a post needs a code but the site has no baseUrl to
+// make its URL absolute.
+func assignQRCodes(posts []*parser.Post, cfg QRCodeConfig, baseURL string) error {
+	for _, post := range posts {
+		want := cfg.Enabled
+		if post.WantsQRCode != nil {
+			want = *post.WantsQRCode
+		}
+		if !want {
+			continue
+		}
+		if baseURL == "" {
+			return fmt.Errorf("QR code of %s needs baseUrl in the config", post.URL)
+		}
+		post.QRCode = qrCodeURL(post.URL)
+	}
+	return nil
+}
+
+// qrCodeURL returns the URL of the QR code image of the page at pageURL,
+// in the page's output directory: "/posts/hello.html" and "/posts/hello"
+// get "/posts/hello-qr.png", and "/posts/hello/" gets "/posts/hello/qr.png".
+func qrCodeURL(pageURL string) string {
+	if strings.HasSuffix(pageURL, "/") {
+		return pageURL + "qr.png"
+	}
+	return strings.TrimSuffix(pageURL, path.Ext(pageURL)) + "-qr.png"
+}
+
+// writeQRCode writes the QR code image of a post that has one (see
+// assignQRCodes) to outputDir in out, encoding the post's absolute URL at
+// error correction level M, so it reads with 15% of it damaged.
+func writeQRCode(out FS, post *parser.Post, cfg QRCodeConfig, baseURL, outputDir string) error {
+	if post.QRCode == "" {
+		return nil
+	}
+	code, err := qrcode.New(absURL(baseURL, post.URL), qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("QR code of %s: %w", post.URL, err)
+	}
+	scale := cfg.Scale
+	if scale <= 0 {
+		scale = defaultQRCodeScale
+	}
+	// A negative size sets the pixels per module rather than the width
+	data, err := code.PNG(-scale)
+	if err != nil {
+		return err
+	}
+	return out.WriteFile(urlPath(outputDir, post.QRCode), data, 0600)
+}
@@ -0,0 +1,111 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestQRCodeURL tests placing QR codes next to each style of page URL
+func TestQRCodeURL(t *testing.T) {
+	tests := map[string]string{
+		"/posts/hello.html": "/posts/hello-qr.png",
+		"/posts/hello":      "/posts/hello-qr.png",
+		"/posts/hello/":     "/posts/hello/qr.png",
+	}
+	for url, want := range tests {
+		if got := qrCodeURL(url); got != want {
+			t.Errorf("qrCodeURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+// TestAssignQRCodes tests the site default and frontmatter overrides
+func TestAssignQRCodes(t *testing.T) {
+	yes, no := true, false
+	posts := []*parser.Post{
+		{URL: "/a.html"},
+		{URL: "/b.html", WantsQRCode: &yes},
+		{URL: "/c.html", WantsQRCode: &no},
+	}
+	if err := assignQRCodes(posts, QRCodeConfig{}, "https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if posts[0].QRCode != "" || posts[1].QRCode != "/b-qr.png" || posts[2].QRCode != "" {
+		t.Errorf("QR codes without the site default = %q, %q, %q", posts[0].QRCode, posts[1].QRCode, posts[2].QRCode)
+	}
+
+	if err := assignQRCodes(posts, QRCodeConfig{Enabled: true}, "https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if posts[0].QRCode != "/a-qr.png" || posts[2].QRCode != "" {
+		t.Errorf("QR codes with the site default = %q, %q", posts[0].QRCode, posts[2].QRCode)
+	}
+
+	if err := assignQRCodes(posts, QRCodeConfig{Enabled: true}, ""); err == nil {
+		t.Error("assignQRCodes() without baseUrl succeeded, want error")
+	}
+}
+
+// TestBuild_QRCode tests writing a post's QR code and linking it from templates
+func TestBuild_QRCode(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "qrcode:\n  enabled: true\n  scale: 2\n"
+	site["templates/post.html"] = "{{define \"posts\"}}<img src=\"{{ .Post.QRCode }}\">{{end}}"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	page, err := os.ReadFile(filepath.Join("public", "posts", "first.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), `<img src="/posts/first-qr.png">`) {
+		t.Errorf("post = %s, want it to link its QR code", page)
+	}
+
+	f, err := os.Open(filepath.Join("public", "posts", "first-qr.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// https://test.com/posts/first.html fits version 3 (29 modules)
+	if w := img.Bounds().Dx(); w != (29+8)*2 {
+		t.Errorf("QR code width = %d, want %d", w, (29+8)*2)
+	}
+}
+
+// TestWriteQRCode_LongURL tests encoding a URL too long for the smaller
+// versions, at one pixel per module
+func TestWriteQRCode_LongURL(t *testing.T) {
+	out := &MemFS{}
+	post := &parser.Post{URL: "/posts/" + strings.Repeat("a", 300) + ".html", QRCode: "/posts/long-qr.png"}
+	if err := writeQRCode(out, post, QRCodeConfig{Scale: 1}, "https://test.com", "public"); err != nil {
+		t.Fatalf("writeQRCode() failed: %v", err)
+	}
+	data, err := out.ReadFile("public/posts/long-qr.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 328 bytes needs version 13 at level M (69 modules)
+	if w := img.Bounds().Dx(); w != 69+8 {
+		t.Errorf("QR code width = %d, want %d", w, 69+8)
+	}
+}
@@ -0,0 +1,380 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// redirectTemplate is the page written at a redirect's old path. Static hosts
+// can't send real redirects from files, so it refreshes to the new URL and
+// points search engines at it with a canonical link.
+var redirectTemplate = template.Must(template.New("redirect").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Redirecting to {{ .To }}</title>
+<link rel="canonical" href="{{ .Canonical }}" />
+<meta name="robots" content="noindex" />
+<meta http-equiv="refresh" content="0; url={{ .To }}" />
+</head>
+<body>
+<p>This page has moved to <a href="{{ .To }}">{{ .To }}</a>.</p>
+</body>
+</html>
+`))
+
+// writeRedirects writes a redirect page (see redirectTemplate) to outputDir
+// in out for each entry of redirects, which maps an old site path (e.g.,
+// "/posts/hello.html") to the URL that replaced it. Old paths without an
+// extension get ".html" added, and ones ending in a slash get index.html.
+//
+// Called by Build after every page is rendered. Returns an error if an old
+// path is invalid or a redirect would overwrite a generated page.
+func writeRedirects(out FS, outputDir string, redirects map[string]string, baseURL string) error {
+	froms := make([]string, 0, len(redirects))
+	for from := range redirects {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+
+	for _, from := range froms {
+		to := redirects[from]
+		if !strings.HasPrefix(from, "/") || path.Clean(from) != strings.TrimSuffix(from, "/") && from != "/" {
+			return fmt.Errorf("redirect from %q must be a clean site path starting with /", from)
+		}
+		if to == "" {
+			return fmt.Errorf("redirect from %s has no target", from)
+		}
+
+		file := pageFile(outputDir, from)
+		if _, err := out.Stat(file); err == nil {
+			return fmt.Errorf("redirect from %s would overwrite a generated page", from)
+		}
+
+		data := struct{ To, Canonical string }{To: to, Canonical: to}
+		if baseURL != "" && strings.HasPrefix(to, "/") {
+			data.Canonical = absURL(baseURL, to)
+		}
+		var buf bytes.Buffer
+		if err := redirectTemplate.Execute(&buf, data); err != nil {
+			return err
+		}
+
+		if err := out.WriteFile(file, buf.Bytes(), 0600); err != nil {
+			return fmt.Errorf("writing redirect from %s: %w", from, err)
+		}
+	}
+	return nil
+}
+
+// siteRedirects returns the redirects a build writes: those listed under
+// redirects in the config, plus one from each alias in a page's frontmatter
+// (e.g. "aliases: [/old/path/]") to the page's URL.
+//
+// Returns an error if an alias isn't a site path or is already a redirect to
+// somewhere else.
+func siteRedirects(config SiteConfig, pages ...[]*parser.Post) (map[string]string, error) {
+	redirects := maps.Clone(config.Redirects)
+	if redirects == nil {
+		redirects = make(map[string]string)
+	}
+	for _, list := range pages {
+		for _, page := range list {
+			for _, alias := range page.Aliases {
+				if !strings.HasPrefix(alias, "/") {
+					return nil, fmt.Errorf("alias %q of %s must be a site path starting with /", alias, page.URL)
+				}
+				if to, ok := redirects[alias]; ok && to != page.URL {
+					return nil, fmt.Errorf("alias %s of %s already redirects to %s", alias, page.URL, to)
+				}
+				redirects[alias] = page.URL
+			}
+		}
+	}
+	return redirects, nil
+}
+
+// redirectFiles are the server rule files writeServerRedirects can write,
+// by the name used in redirectFiles in the config. Each line is formatted
+// with the old path and the new URL.
+var redirectFiles = map[string]struct{ file, line string }{
+	"netlify":    {"_redirects", "%s %s 301!\n"},               // Forced, since the redirect page exists at the old path
+	"cloudflare": {"_redirects", "%s %s 301\n"},                // Cloudflare Pages always redirects, and doesn't accept "!"
+	"apache":     {".htaccess", "RedirectMatch 301 ^%s$ %s\n"}, // Apache mod_alias
+}
+
+// writeServerRedirects writes redirects as rules for the hosts listed in
+// formats (see redirectFiles) to outputDir in out, so the server answers old paths with a real
+// 301 rather than the redirect page. Rules are appended to a file of the
+// same name copied from static/, so hand-written rules are kept.
+//
+// Called by Build after static files are copied. Returns an error for an
+// unknown format or if a file can't be written.
+func writeServerRedirects(out FS, outputDir string, redirects map[string]string, formats []string) error {
+	froms := make([]string, 0, len(redirects))
+	for from := range redirects {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+
+	for _, format := range formats {
+		rf, ok := redirectFiles[format]
+		if !ok {
+			return fmt.Errorf("unknown redirect file %q (want netlify, cloudflare, or apache)", format)
+		}
+		file := filepath.Join(outputDir, rf.file)
+		existing, err := out.ReadFile(file)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		var buf bytes.Buffer
+		buf.Write(existing)
+		if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		for _, from := range froms {
+			pattern := from
+			if format == "apache" {
+				pattern = regexp.QuoteMeta(from)
+			}
+			fmt.Fprintf(&buf, rf.line, pattern, redirects[from])
+		}
+		if err := out.WriteFile(file, buf.Bytes(), 0600); err != nil {
+			return fmt.Errorf("writing %s: %w", rf.file, err)
+		}
+	}
+	return nil
+}
+
+// movedURL is a page of a previous build that a new build no longer
+// generates.
+type movedURL struct {
+	Old string // Site-relative URL of the old page
+	New string // URL of the new page most likely to have replaced it, or "" if none matched
+}
+
+// Moved builds the site into a temporary directory and reports the pages of
+// the previous build that no longer exist, so changing permalink or urls
+// doesn't silently break links from elsewhere on the web.
+//
+// Each missing page is matched to the new page with the same name (its slug),
+// preferring pages that are new in this build. If write is set, redirects for
+// the matched pages are added to the redirects in the config file, so the
+// next build writes a redirect page at each old URL (see writeRedirects).
+//
+// The previous build is found the same way as for Diff: the saved manifest,
+// or the git ref of outputDir if ref is set.
+//
+// Parameters:
+//   - configPath: Path to config.yaml
+//   - outputDir: Output directory of the previous build (e.g., "public")
+//   - ref: Optional git ref of outputDir to compare against instead of the manifest
+//   - write: Add redirects for the matched pages to configPath
+//
+// Returns an error if the build fails, there is no previous build to compare
+// with, or the config file can't be updated.
+func Moved(configPath, outputDir, ref string, write bool) error {
+	moved, err := findMoved(configPath, outputDir, ref, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if len(moved) == 0 {
+		fmt.Println("No pages have moved.")
+		return nil
+	}
+
+	var matched []movedURL
+	for _, m := range moved {
+		if m.New != "" {
+			matched = append(matched, m)
+		}
+	}
+	switch {
+	case len(matched) == 0:
+		return nil
+	case !write:
+		fmt.Printf("\nRun 'ssg moved --write' to add %d redirects to %s.\n", len(matched), configPath)
+		return nil
+	}
+
+	if err := addRedirects(configPath, matched); err != nil {
+		return fmt.Errorf("adding redirects: %w", err)
+	}
+	fmt.Printf("\nAdded %d redirects to %s.\n", len(matched), configPath)
+	return nil
+}
+
+// findMoved does the work of Moved, writing the report to w and returning
+// the pages that disappeared, sorted by old URL.
+func findMoved(configPath, outputDir, ref string, w io.Writer) ([]movedURL, error) {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	var prev *snapshot
+	if ref != "" {
+		prev, err = gitSnapshot(outputDir, ref)
+	} else {
+		prev, err = manifestSnapshot(outputDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	prev.dropPreserved(config.Preserve)
+
+	tmpDir, err := os.MkdirTemp("", "ssg-moved-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	newDir := filepath.Join(tmpDir, "public")
+	if err := generate(context.Background(), BuildOptions{ConfigPath: configPath, OutputDir: newDir, preview: true}); err != nil {
+		return nil, fmt.Errorf("building site: %w", err)
+	}
+	current, err := buildManifest(DirFS("."), newDir, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading new build: %w", err)
+	}
+
+	moved := movedPages(prev.files, current.Files, config.URLs)
+	if len(moved) > 0 {
+		fmt.Fprintf(w, "%d pages from the %s no longer exist:\n", len(moved), prev.description)
+	}
+	for _, m := range moved {
+		if m.New == "" {
+			fmt.Fprintf(w, "  %s (no matching page)\n", m.Old)
+		} else {
+			fmt.Fprintf(w, "  %s → %s\n", m.Old, m.New)
+		}
+	}
+	return moved, nil
+}
+
+// movedPages lists the HTML pages in prev missing from current (both
+// output-relative paths, as in Manifest.Files), each matched to a page of
+// current with the same pageName if there is exactly one, looking first at
+// pages added since prev. URLs are formatted in the given URL style.
+func movedPages(prev, current map[string]string, style string) []movedURL {
+	added := make(map[string][]string)    // name → pages new in current
+	existing := make(map[string][]string) // name → all pages in current
+	for p := range current {
+		if path.Ext(p) != ".html" {
+			continue
+		}
+		name := pageName(p)
+		existing[name] = append(existing[name], p)
+		if _, ok := prev[p]; !ok {
+			added[name] = append(added[name], p)
+		}
+	}
+
+	var moved []movedURL
+	for p := range prev {
+		if _, ok := current[p]; ok || path.Ext(p) != ".html" || p == "404.html" {
+			continue
+		}
+		m := movedURL{Old: outputURL(style, p)}
+		name := pageName(p)
+		if candidates := added[name]; len(candidates) == 1 {
+			m.New = outputURL(style, candidates[0])
+		} else if candidates := existing[name]; len(candidates) == 1 {
+			m.New = outputURL(style, candidates[0])
+		}
+		moved = append(moved, m)
+	}
+
+	sort.Slice(moved, func(i, j int) bool { return moved[i].Old < moved[j].Old })
+	return moved
+}
+
+// pageName returns the name identifying an output page across URL schemes:
+// the file name without .html, or the directory name for an index.html, so
+// posts/hello.html and 2024/01/hello/index.html are both "hello".
+func pageName(p string) string {
+	if path.Base(p) == "index.html" {
+		return path.Base(path.Dir(p))
+	}
+	return strings.TrimSuffix(path.Base(p), ".html")
+}
+
+// outputURL returns the URL of an output page (a path relative to the output
+// directory) in the given URL style: index.html files are addressed by their
+// directory, and extensionless sites drop .html.
+func outputURL(style, p string) string {
+	switch {
+	case p == "index.html":
+		return "/"
+	case path.Base(p) == "index.html":
+		return "/" + strings.TrimSuffix(p, "index.html")
+	case style == URLStyleExtensionless:
+		return "/" + strings.TrimSuffix(p, ".html")
+	}
+	return "/" + p
+}
+
+// redirectsKey matches the top-level redirects key of a config file written
+// as a block mapping (with its entries on the following lines).
+var redirectsKey = regexp.MustCompile(`(?m)^redirects:[ \t]*(#.*)?$`)
+
+// addRedirects adds a redirect for each moved page to the redirects mapping
+// in the config file at configPath, creating the mapping if there isn't one.
+// The file is edited as text, so its comments and formatting are kept.
+func addRedirects(configPath string, moved []movedURL) error {
+	data, err := os.ReadFile(configPath) // #nosec G304 -- config path comes from the user's command line
+	if err != nil {
+		return err
+	}
+	content := string(data)
+
+	var entries strings.Builder
+	indent := "  "
+	loc := redirectsKey.FindStringIndex(content)
+	if loc == nil {
+		if strings.Contains("\n"+content, "\nredirects:") {
+			return fmt.Errorf("redirects in %s isn't a block mapping; add the redirects by hand", configPath)
+		}
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += "redirects:\n"
+		loc = []int{len(content) - 1, len(content) - 1}
+	} else if next := content[loc[1]:]; strings.HasPrefix(next, "\n") {
+		// Match the indentation of the existing entries
+		line := strings.SplitN(next[1:], "\n", 2)[0]
+		if trimmed := strings.TrimLeft(line, " "); trimmed != "" && len(trimmed) < len(line) {
+			indent = line[:len(line)-len(trimmed)]
+		}
+	}
+
+	for _, m := range moved {
+		fmt.Fprintf(&entries, "\n%s%q: %q", indent, m.Old, m.New)
+	}
+	content = content[:loc[1]] + entries.String() + content[loc[1]:]
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	var config SiteConfig
+	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+		return fmt.Errorf("updated %s would be invalid: %w", configPath, err)
+	}
+	return os.WriteFile(configPath, []byte(content), 0600)
+}
@@ -0,0 +1,269 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestWriteRedirects tests writing redirect pages at old paths
+func TestWriteRedirects(t *testing.T) {
+	outputDir := t.TempDir()
+	writeFiles(t, outputDir, map[string]string{"posts/kept.html": "page"})
+
+	redirects := map[string]string{
+		"/posts/hello.html": "/2024/01/hello/",
+		"/old/":             "https://elsewhere.example.com/",
+		"/about":            "/about/",
+	}
+	if err := writeRedirects(DirFS("."), outputDir, redirects, "https://test.com"); err != nil {
+		t.Fatalf("writeRedirects() failed: %v", err)
+	}
+
+	page, err := os.ReadFile(filepath.Join(outputDir, "posts", "hello.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<meta http-equiv="refresh" content="0; url=/2024/01/hello/" />`,
+		`<link rel="canonical" href="https://test.com/2024/01/hello/" />`,
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("redirect page missing %s:\n%s", want, page)
+		}
+	}
+	for _, name := range []string{"old/index.html", "about.html"} {
+		if _, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+
+	for _, bad := range []map[string]string{
+		{"/posts/kept.html": "/new/"},
+		{"posts/relative.html": "/new/"},
+		{"/../escape.html": "/new/"},
+		{"/empty.html": ""},
+	} {
+		if err := writeRedirects(DirFS("."), outputDir, bad, ""); err == nil {
+			t.Errorf("writeRedirects(%v) succeeded, want error", bad)
+		}
+	}
+}
+
+// TestSiteRedirects tests combining configured redirects with frontmatter aliases
+func TestSiteRedirects(t *testing.T) {
+	config := SiteConfig{Redirects: map[string]string{"/feed.xml": "/rss.xml"}}
+	posts := []*parser.Post{{URL: "/posts/hello.html", Aliases: []string{"/2019/01/hello/", "/feed.xml"}}}
+	pages := []*parser.Post{{URL: "/about.html", Aliases: []string{"/about-me/"}}}
+
+	if _, err := siteRedirects(config, posts, pages); err == nil || !strings.Contains(err.Error(), "already redirects") {
+		t.Errorf("siteRedirects() error = %v, want a conflict", err)
+	}
+
+	posts[0].Aliases = posts[0].Aliases[:1]
+	got, err := siteRedirects(config, posts, pages)
+	if err != nil {
+		t.Fatalf("siteRedirects() failed: %v", err)
+	}
+	want := map[string]string{
+		"/feed.xml":       "/rss.xml",
+		"/2019/01/hello/": "/posts/hello.html",
+		"/about-me/":      "/about.html",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("siteRedirects() = %v, want %v", got, want)
+	}
+	if len(config.Redirects) != 1 {
+		t.Errorf("config.Redirects modified: %v", config.Redirects)
+	}
+
+	pages[0].Aliases = []string{"about-me"}
+	if _, err := siteRedirects(config, posts, pages); err == nil {
+		t.Error("siteRedirects() with a relative alias succeeded, want error")
+	}
+}
+
+// TestWriteServerRedirects tests writing redirects as server rules
+func TestWriteServerRedirects(t *testing.T) {
+	outputDir := t.TempDir()
+	writeFiles(t, outputDir, map[string]string{"_redirects": "/docs/* https://docs.example.com/:splat 302"})
+	redirects := map[string]string{"/old.html": "/new/", "/a/": "https://example.com/"}
+
+	if err := writeServerRedirects(DirFS("."), outputDir, redirects, []string{"netlify", "apache"}); err != nil {
+		t.Fatalf("writeServerRedirects() failed: %v", err)
+	}
+	for name, want := range map[string]string{
+		"_redirects": "/docs/* https://docs.example.com/:splat 302\n/a/ https://example.com/ 301!\n/old.html /new/ 301!\n",
+		".htaccess":  "RedirectMatch 301 ^/a/$ https://example.com/\nRedirectMatch 301 ^/old\\.html$ /new/\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s =\n%s\nwant\n%s", name, got, want)
+		}
+	}
+
+	if err := writeServerRedirects(DirFS("."), outputDir, redirects, []string{"nginx"}); err == nil {
+		t.Error("writeServerRedirects() with an unknown format succeeded, want error")
+	}
+}
+
+// TestBuild_Aliases tests that frontmatter aliases redirect to their posts
+func TestBuild_Aliases(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "redirectFiles: [netlify]\n"
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\naliases: [/2024/01/first-post/]\n---\n\nHello.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{Strict: true}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	page, err := os.ReadFile(filepath.Join("public", "2024", "01", "first-post", "index.html"))
+	if err != nil {
+		t.Fatalf("alias redirect page not written: %v", err)
+	}
+	if !strings.Contains(string(page), `<link rel="canonical" href="https://test.com/posts/first.html" />`) {
+		t.Errorf("alias page doesn't point at the post:\n%s", page)
+	}
+	rules, err := os.ReadFile(filepath.Join("public", "_redirects"))
+	if err != nil || string(rules) != "/2024/01/first-post/ /posts/first.html 301!\n" {
+		t.Errorf("_redirects = %q, %v", rules, err)
+	}
+}
+
+// TestMovedPages tests matching pages that disappeared to their new URLs
+func TestMovedPages(t *testing.T) {
+	prev := map[string]string{
+		"index.html":             "",
+		"posts/hello.html":       "",
+		"posts/gone.html":        "",
+		"posts/about.html":       "",
+		"404.html":               "",
+		"css/style.css":          "",
+		"posts/kept-same.html":   "",
+		"tags/go.html":           "",
+		"posts/ambiguous.html":   "",
+		"posts/extension.html":   "",
+		"notes/renamed/old.html": "",
+	}
+	current := map[string]string{
+		"index.html":                     "",
+		"2024/01/hello/index.html":       "",
+		"about.html":                     "",
+		"posts/kept-same.html":           "",
+		"go/index.html":                  "",
+		"a/ambiguous.html":               "",
+		"b/ambiguous.html":               "",
+		"extension.html":                 "",
+		"2024/01/extension-other/x.html": "",
+		"notes/renamed/index.html":       "",
+	}
+
+	got := movedPages(prev, current, URLStyleSlash)
+	want := []movedURL{
+		{Old: "/notes/renamed/old.html"},
+		{Old: "/posts/about.html", New: "/about.html"},
+		{Old: "/posts/ambiguous.html"},
+		{Old: "/posts/extension.html", New: "/extension.html"},
+		{Old: "/posts/gone.html"},
+		{Old: "/posts/hello.html", New: "/2024/01/hello/"},
+		{Old: "/tags/go.html", New: "/go/"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("movedPages() =\n%v\nwant\n%v", got, want)
+	}
+
+	if got := movedPages(prev, current, URLStyleExtensionless); got[1].New != "/about" {
+		t.Errorf("extensionless movedPages() matched %q, want /about", got[1].New)
+	}
+}
+
+// TestFindMoved tests reporting pages that moved after a permalink change
+func TestFindMoved(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	writeFiles(t, tmpDir, map[string]string{
+		"config.yaml": testSite()["config.yaml"] + "permalink: /:year/:slug/\n",
+	})
+
+	var out bytes.Buffer
+	moved, err := findMoved("config.yaml", "public", "", &out)
+	if err != nil {
+		t.Fatalf("findMoved() failed: %v", err)
+	}
+	want := []movedURL{{Old: "/posts/first.html", New: "/2024/first/"}}
+	if !slices.Equal(moved, want) {
+		t.Errorf("findMoved() = %v, want %v", moved, want)
+	}
+	if !strings.Contains(out.String(), "/posts/first.html → /2024/first/") {
+		t.Errorf("report = %q", out.String())
+	}
+
+	// Adding the redirects makes the old URL exist again
+	if err := addRedirects("config.yaml", moved); err != nil {
+		t.Fatalf("addRedirects() failed: %v", err)
+	}
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() with redirects failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("public", "posts", "first.html")); err != nil {
+		t.Errorf("redirect page not written: %v", err)
+	}
+}
+
+// TestAddRedirects tests editing the redirects in a config file as text
+func TestAddRedirects(t *testing.T) {
+	tests := []struct {
+		name, config, want string
+	}{
+		{
+			name:   "no redirects",
+			config: "title: Blog # my blog",
+			want:   "title: Blog # my blog\nredirects:\n  \"/a.html\": \"/b/\"\n",
+		},
+		{
+			name:   "existing block",
+			config: "redirects: # moved pages\n    \"/x.html\": /y/\ntitle: Blog\n",
+			want:   "redirects: # moved pages\n    \"/a.html\": \"/b/\"\n    \"/x.html\": /y/\ntitle: Blog\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			writeFiles(t, filepath.Dir(path), map[string]string{"config.yaml": tt.config})
+
+			if err := addRedirects(path, []movedURL{{Old: "/a.html", New: "/b/"}}); err != nil {
+				t.Fatalf("addRedirects() failed: %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("config =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFiles(t, filepath.Dir(path), map[string]string{"config.yaml": "redirects: {\"/x.html\": /y/}\n"})
+	if err := addRedirects(path, []movedURL{{Old: "/a.html", New: "/b/"}}); err == nil {
+		t.Error("addRedirects() to a flow mapping succeeded, want error")
+	}
+}
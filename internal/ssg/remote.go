@@ -0,0 +1,177 @@
+package ssg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// remoteCacheDir is where responses fetched by getJSON and getCSV are cached
+// between builds, relative to the site root.
+var remoteCacheDir = filepath.Join(".ssg", "remote")
+
+// defaultRemoteMaxAge is how long a cached response is used before it's
+// fetched again, unless remoteData.maxAge is set.
+const defaultRemoteMaxAge = time.Hour
+
+// maxRemoteResponse limits the size of a fetched dataset.
+const maxRemoteResponse = 5 << 20
+
+// remoteClient fetches remote datasets.
+var remoteClient = &http.Client{Timeout: 30 * time.Second}
+
+// RemoteDataConfig configures the getJSON and getCSV template functions,
+// which fetch small datasets (a list of GitHub repositories, say) while the
+// site is built:
+//
+//	{{ range getJSON "https://api.github.com/users/you/repos" }}{{ .name }}{{ end }}
+//
+// Responses are cached in .ssg/remote/ and reused until they're older than
+// maxAge, so rebuilds don't fetch them every time.
+//
+// Example config.yaml:
+//
+//	remoteData:
+//	  maxAge: 24h
+type RemoteDataConfig struct {
+	MaxAge string `yaml:"maxAge"` // How long cached responses are reused, e.g. "30m" (default: 1h)
+}
+
+// remoteData fetches and caches the datasets of one build. Each URL is
+// fetched (or read from the cache) once per build, however many pages use
+// it.
+type remoteData struct {
+	maxAge   time.Duration
+	cacheDir string
+	now      func() time.Time
+
+	mu     sync.Mutex
+	bodies map[string][]byte // Response bodies by URL, for this build
+}
+
+// newRemoteData returns the fetcher for cfg, or an error if its maxAge
+// isn't a valid duration.
+func newRemoteData(cfg RemoteDataConfig) (*remoteData, error) {
+	maxAge := defaultRemoteMaxAge
+	if cfg.MaxAge != "" {
+		d, err := time.ParseDuration(cfg.MaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("remoteData.maxAge: %w", err)
+		}
+		maxAge = d
+	}
+	return &remoteData{maxAge: maxAge, cacheDir: remoteCacheDir, now: time.Now, bodies: make(map[string][]byte)}, nil
+}
+
+// getJSON returns the JSON document at rawURL, decoded into maps, slices,
+// strings, float64s, and bools.
+func (rd *remoteData) getJSON(rawURL string) (any, error) {
+	body, err := rd.get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, fmt.Errorf("getJSON %s: %w", rawURL, err)
+	}
+	return v, nil
+}
+
+// getCSV returns the rows of the CSV document at rawURL, each a slice of
+// fields. A header row, if the document has one, is the first row.
+func (rd *remoteData) getCSV(rawURL string) ([][]string, error) {
+	body, err := rd.get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(bytes.NewReader(body))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("getCSV %s: %w", rawURL, err)
+	}
+	return rows, nil
+}
+
+// get returns the body at rawURL: from this build's earlier calls, from the
+// cache if it's younger than maxAge, or fetched and cached. If fetching
+// fails, a cached body of any age is used with a warning, so a flaky API
+// doesn't fail the build once its data has been fetched.
+func (rd *remoteData) get(rawURL string) ([]byte, error) {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	if body, ok := rd.bodies[rawURL]; ok {
+		return body, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("%q isn't an http(s) URL", rawURL)
+	}
+	sum := sha256.Sum256([]byte(rawURL))
+	cachePath := filepath.Join(rd.cacheDir, hex.EncodeToString(sum[:]))
+
+	cached, cacheErr := os.ReadFile(cachePath) // #nosec G304 -- path is a hash inside the cache directory
+	info, statErr := os.Stat(cachePath)
+	fresh := cacheErr == nil && statErr == nil && rd.now().Sub(info.ModTime()) < rd.maxAge
+	body := cached
+	if !fresh {
+		fetched, err := fetchRemote(rawURL)
+		switch {
+		case err == nil:
+			body = fetched
+			if err := writeRemoteCache(cachePath, body, rd.now()); err != nil {
+				slog.Warn("caching remote data", "url", rawURL, "error", err)
+			}
+		case cacheErr == nil:
+			slog.Warn("fetching remote data, using the cached copy", "url", rawURL, "error", err)
+		default:
+			return nil, fmt.Errorf("fetching %s: %w", rawURL, err)
+		}
+	}
+	rd.bodies[rawURL] = body
+	return body, nil
+}
+
+// fetchRemote fetches rawURL and returns its body.
+func fetchRemote(rawURL string) ([]byte, error) {
+	resp, err := remoteClient.Get(rawURL) // #nosec G107 -- URL comes from the site's own templates
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteResponse+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxRemoteResponse {
+		return nil, fmt.Errorf("response is larger than %d bytes", maxRemoteResponse)
+	}
+	return body, nil
+}
+
+// writeRemoteCache saves a body fetched at the given time to the cache. The
+// file's modification time records when it was fetched.
+func writeRemoteCache(path string, body []byte, fetched time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, body, 0600); err != nil {
+		return err
+	}
+	return os.Chtimes(path, fetched, fetched)
+}
@@ -0,0 +1,81 @@
+package ssg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRemoteData tests fetching, caching, and falling back to the cache
+func TestRemoteData(t *testing.T) {
+	var hits atomic.Int32
+	var down atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if down.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/talks.csv" {
+			_, _ = w.Write([]byte("title,year\nStatic sites,2024\n"))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name": "ssg", "stars": 3}]`))
+	}))
+	defer srv.Close()
+
+	cacheDir := t.TempDir()
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	newFetcher := func() *remoteData {
+		rd, err := newRemoteData(RemoteDataConfig{MaxAge: "1h"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rd.cacheDir = cacheDir
+		rd.now = func() time.Time { return now }
+		return rd
+	}
+
+	rd := newFetcher()
+	repos, err := rd.getJSON(srv.URL + "/repos")
+	if err != nil {
+		t.Fatalf("getJSON() error = %v", err)
+	}
+	if list, ok := repos.([]any); !ok || list[0].(map[string]any)["name"] != "ssg" {
+		t.Errorf("getJSON() = %#v, want the decoded list", repos)
+	}
+	if _, err := rd.getJSON(srv.URL + "/repos"); err != nil || hits.Load() != 1 {
+		t.Errorf("second call in a build fetched again (%d requests, err %v)", hits.Load(), err)
+	}
+
+	rows, err := rd.getCSV(srv.URL + "/talks.csv")
+	if err != nil || len(rows) != 2 || rows[1][0] != "Static sites" {
+		t.Errorf("getCSV() = %v, %v, want the header and one row", rows, err)
+	}
+
+	// A later build within maxAge reads the cache
+	hits.Store(0)
+	if _, err := newFetcher().getJSON(srv.URL + "/repos"); err != nil || hits.Load() != 0 {
+		t.Errorf("fresh cache wasn't used (%d requests, err %v)", hits.Load(), err)
+	}
+
+	// Once it's stale, it's fetched again, or used anyway if that fails
+	now = now.Add(48 * time.Hour)
+	down.Store(true)
+	if _, err := newFetcher().getJSON(srv.URL + "/repos"); err != nil || hits.Load() != 1 {
+		t.Errorf("stale cache wasn't refetched and used as a fallback (%d requests, err %v)", hits.Load(), err)
+	}
+	if _, err := newFetcher().getJSON(srv.URL + "/uncached"); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("getJSON() of an uncached failing URL error = %v, want the status", err)
+	}
+
+	if _, err := newFetcher().getJSON("file:///etc/passwd"); err == nil {
+		t.Error("getJSON() of a file URL succeeded")
+	}
+	if _, err := newRemoteData(RemoteDataConfig{MaxAge: "soon"}); err == nil {
+		t.Error("newRemoteData() with an invalid maxAge succeeded")
+	}
+}
@@ -0,0 +1,178 @@
+package ssg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// RobotsConfig configures robots.txt, which tells crawlers which paths of
+// the site they may visit. It's written only if the site doesn't have its
+// own in static/ (or its theme's static/).
+//
+// Example config.yaml:
+//
+//	robots:
+//	  enabled: true
+//	  rules:
+//	    - userAgent: "*"
+//	      disallow: [/drafts/, /search]
+//	    - userAgent: GPTBot
+//	      disallow: [/]
+type RobotsConfig struct {
+	Enabled bool         `yaml:"enabled"` // Write /robots.txt
+	Rules   []RobotsRule `yaml:"rules"`   // Groups of rules (default: allow every crawler everywhere)
+}
+
+// RobotsRule is a group of robots.txt rules for one crawler.
+type RobotsRule struct {
+	UserAgent string   `yaml:"userAgent"` // Crawler the rules apply to (default: "*", every crawler)
+	Allow     []string `yaml:"allow"`     // Paths the crawler may visit, as exceptions to Disallow
+	Disallow  []string `yaml:"disallow"`  // Paths the crawler may not visit
+}
+
+// HumansConfig configures humans.txt (see humanstxt.org), which credits the
+// people behind the site. Like robots.txt, it's written only if the site
+// doesn't have its own in static/.
+//
+// Example config.yaml:
+//
+//	humans:
+//	  enabled: true
+//	  team:
+//	    - name: Ada Lovelace
+//	      role: Writer
+//	      contact: ada@example.com
+//	      location: London, UK
+//	  thanks: [The Go team]
+type HumansConfig struct {
+	Enabled bool          `yaml:"enabled"` // Write /humans.txt
+	Team    []HumanConfig `yaml:"team"`    // People behind the site (default: the site's author)
+	Thanks  []string      `yaml:"thanks"`  // Others to thank
+}
+
+// HumanConfig is a member of the team in humans.txt.
+type HumanConfig struct {
+	Name     string `yaml:"name"`
+	Role     string `yaml:"role"`     // e.g. "Writer" (default: "Author")
+	Contact  string `yaml:"contact"`  // Email address or URL
+	Location string `yaml:"location"` // e.g. "London, UK"
+}
+
+// robotsPlugin writes robots.txt and humans.txt (see RobotsConfig and
+// HumansConfig).
+type robotsPlugin struct{ BasePlugin }
+
+func (robotsPlugin) Name() string { return "robots" }
+
+func (robotsPlugin) AfterBuild(b *BuildContext) error {
+	if !b.First() {
+		return nil
+	}
+	if b.Config.Robots.Enabled {
+		if err := writeTextFile(b, "robots.txt", robotsTxt(b.Config)); err != nil {
+			return err
+		}
+	}
+	if b.Config.Humans.Enabled {
+		if err := writeTextFile(b, "humans.txt", humansTxt(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTextFile writes content to name in the output directory, unless a
+// file by that name was copied there from static/, which is left as is.
+func writeTextFile(b *BuildContext, name, content string) error {
+	path := filepath.Join(b.OutputDir, name)
+	if _, err := fs.Stat(b.Output, path); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return b.Output.WriteFile(path, []byte(content), 0600)
+}
+
+// robotsTxt returns the robots.txt of the site: the groups of rules from the
+// config, and the sitemap's URL if the site has one.
+func robotsTxt(config *SiteConfig) string {
+	rules := config.Robots.Rules
+	if len(rules) == 0 {
+		rules = []RobotsRule{{}}
+	}
+
+	var b strings.Builder
+	for i, rule := range rules {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		agent := rule.UserAgent
+		if agent == "" {
+			agent = "*"
+		}
+		fmt.Fprintf(&b, "User-agent: %s\n", agent)
+		for _, p := range rule.Allow {
+			fmt.Fprintf(&b, "Allow: %s\n", p)
+		}
+		for _, p := range rule.Disallow {
+			fmt.Fprintf(&b, "Disallow: %s\n", p)
+		}
+		if len(rule.Allow) == 0 && len(rule.Disallow) == 0 {
+			b.WriteString("Disallow:\n") // An empty Disallow allows everything
+		}
+	}
+	if config.Sitemap.Enabled && config.BaseURL != "" {
+		fmt.Fprintf(&b, "\nSitemap: %s/sitemap.xml\n", strings.TrimSuffix(config.BaseURL, "/"))
+	}
+	return b.String()
+}
+
+// humansTxt returns the humans.txt of the site, in the sections humanstxt.org
+// suggests: the team, thanks, and the site itself, last updated on the date
+// of its newest post.
+func humansTxt(b *BuildContext) string {
+	cfg := b.Config.Humans
+	team := cfg.Team
+	if len(team) == 0 && b.Config.Author != "" {
+		team = []HumanConfig{{Name: b.Config.Author}}
+	}
+
+	var s strings.Builder
+	s.WriteString("/* TEAM */\n")
+	for i, h := range team {
+		if i > 0 {
+			s.WriteString("\n")
+		}
+		role := h.Role
+		if role == "" {
+			role = "Author"
+		}
+		fmt.Fprintf(&s, "\t%s: %s\n", role, h.Name)
+		if h.Contact != "" {
+			fmt.Fprintf(&s, "\tContact: %s\n", h.Contact)
+		}
+		if h.Location != "" {
+			fmt.Fprintf(&s, "\tFrom: %s\n", h.Location)
+		}
+	}
+
+	if len(cfg.Thanks) > 0 {
+		s.WriteString("\n/* THANKS */\n")
+		for _, name := range cfg.Thanks {
+			fmt.Fprintf(&s, "\t%s\n", name)
+		}
+	}
+
+	s.WriteString("\n/* SITE */\n")
+	if len(b.Posts) > 0 {
+		fmt.Fprintf(&s, "\tLast update: %s\n", b.Posts[0].Date.Format("2006/01/02"))
+	}
+	if b.Config.Language != "" {
+		fmt.Fprintf(&s, "\tLanguage: %s\n", b.Config.Language)
+	}
+	s.WriteString("\tSoftware: ssg\n")
+	return s.String()
+}
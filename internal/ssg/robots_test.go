@@ -0,0 +1,82 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestBuild_RobotsAndHumans tests writing robots.txt and humans.txt from the config
+func TestBuild_RobotsAndHumans(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "language: en\nsitemap:\n  enabled: true\n" +
+		"robots:\n  enabled: true\n  rules:\n    - disallow: [/drafts/]\n      allow: [/drafts/public/]\n    - userAgent: GPTBot\n      disallow: [/]\n" +
+		"humans:\n  enabled: true\n  thanks: [The Go team]\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{
+			file: "robots.txt",
+			want: "User-agent: *\nAllow: /drafts/public/\nDisallow: /drafts/\n\n" +
+				"User-agent: GPTBot\nDisallow: /\n\n" +
+				"Sitemap: https://test.com/sitemap.xml\n",
+		},
+		{
+			file: "humans.txt",
+			want: "/* TEAM */\n\tAuthor: Test Author\n\n" +
+				"/* THANKS */\n\tThe Go team\n\n" +
+				"/* SITE */\n\tLast update: 2024/01/15\n\tLanguage: en\n\tSoftware: ssg\n",
+		},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join("public", tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s =\n%s\nwant\n%s", tt.file, data, tt.want)
+		}
+	}
+}
+
+// TestBuild_RobotsFromStatic tests that robots.txt in static/ isn't replaced
+func TestBuild_RobotsFromStatic(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "robots:\n  enabled: true\n"
+	site["static/robots.txt"] = "User-agent: *\nDisallow: /private/\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join("public", "robots.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != site["static/robots.txt"] {
+		t.Errorf("robots.txt =\n%s\nwant the one from static/", data)
+	}
+	if _, err := os.Stat(filepath.Join("public", "humans.txt")); !os.IsNotExist(err) {
+		t.Errorf("humans.txt written without humans.enabled: %v", err)
+	}
+}
+
+// TestRobotsTxt_Default tests that robots.txt allows everything by default
+func TestRobotsTxt_Default(t *testing.T) {
+	config := &SiteConfig{BaseURL: "https://test.com"}
+	if got, want := robotsTxt(config), "User-agent: *\nDisallow:\n"; got != want {
+		t.Errorf("robotsTxt() = %q, want %q", got, want)
+	}
+}
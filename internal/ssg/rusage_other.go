@@ -0,0 +1,9 @@
+//go:build !unix
+
+package ssg
+
+// peakRSS returns 0 because peak memory usage isn't available on this
+// platform.
+func peakRSS() uint64 {
+	return 0
+}
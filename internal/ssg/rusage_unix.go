@@ -0,0 +1,21 @@
+//go:build unix
+
+package ssg
+
+import (
+	"runtime"
+	"syscall"
+)
+
+// peakRSS returns the peak resident set size of the current process in bytes.
+func peakRSS() uint64 {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+	// Maxrss is reported in bytes on macOS and kilobytes elsewhere.
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		return uint64(usage.Maxrss) // #nosec G115 -- Maxrss is never negative
+	}
+	return uint64(usage.Maxrss) * 1024 // #nosec G115 -- Maxrss is never negative
+}
@@ -0,0 +1,386 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5" // #nosec G501 -- S3 ETags are MD5 digests; used to detect changes, not for security
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// s3HTTPClient sends requests to S3.
+var s3HTTPClient = &http.Client{Timeout: 5 * time.Minute}
+
+// s3Deployer syncs the site to an S3 bucket, or a bucket of an
+// S3-compatible service like Cloudflare R2, MinIO, or DigitalOcean Spaces.
+// Only files whose contents changed are uploaded, each with a Content-Type
+// from its extension and a Cache-Control from the target's rules.
+// Credentials are read from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+// and (optionally) AWS_SESSION_TOKEN environment variables.
+type s3Deployer struct{}
+
+func (s3Deployer) check(t DeployTarget) error {
+	if t.Bucket == "" {
+		return fmt.Errorf("bucket is required for s3")
+	}
+	for _, r := range t.CacheControl {
+		if _, err := path.Match(r.Match, ""); err != nil {
+			return fmt.Errorf("cacheControl pattern %q: %w", r.Match, err)
+		}
+	}
+	_, err := newS3Client(t)
+	return err
+}
+
+func (s3Deployer) deploy(ctx context.Context, t DeployTarget, outputDir string, skip []string, dryRun bool) error {
+	c, err := newS3Client(t)
+	if err != nil {
+		return err
+	}
+	prefix := strings.Trim(t.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	remote, err := c.list(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	verb := map[bool]string{false: "Uploading", true: "Would upload"}[dryRun]
+	local := make(map[string]bool)
+	uploaded, unchanged := 0, 0
+	err = filepath.Walk(outputDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(outputDir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if preserved(rel, skip) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		key := prefix + rel
+		local[key] = true
+
+		data, err := os.ReadFile(p) // #nosec G304 -- path found by walking the output directory
+		if err != nil {
+			return err
+		}
+		sum := md5.Sum(data) // #nosec G401 -- compared with S3 ETags, not used for security
+		if remote[key] == hex.EncodeToString(sum[:]) {
+			unchanged++
+			return nil
+		}
+		fmt.Printf("%s %s\n", verb, key)
+		uploaded++
+		if dryRun {
+			return nil
+		}
+		return c.put(ctx, key, data, contentType(rel, data), cacheControl(t.CacheControl, rel))
+	})
+	if err != nil {
+		return err
+	}
+
+	deleted := 0
+	if t.Delete {
+		verb := map[bool]string{false: "Deleting", true: "Would delete"}[dryRun]
+		keys := make([]string, 0, len(remote))
+		for key := range remote {
+			if !local[key] && !preserved(strings.TrimPrefix(key, prefix), skip) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("%s %s\n", verb, key)
+			deleted++
+			if dryRun {
+				continue
+			}
+			if err := c.delete(ctx, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("%d uploaded, %d deleted, %d unchanged\n", uploaded, deleted, unchanged)
+	return nil
+}
+
+// contentType returns the Content-Type of the file at name, from its
+// extension, or sniffed from data if the extension isn't known.
+func contentType(name string, data []byte) string {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return t
+	}
+	return http.DetectContentType(data)
+}
+
+// s3Client makes signed requests to one bucket.
+type s3Client struct {
+	base      *url.URL // URL of the bucket root
+	region    string
+	accessKey string
+	secretKey string
+	token     string
+	now       func() time.Time
+}
+
+// newS3Client returns a client for the target's bucket. Buckets on AWS are
+// addressed by host (https://<bucket>.s3.<region>.amazonaws.com); buckets
+// at a custom endpoint by path (<endpoint>/<bucket>), which is what
+// S3-compatible services support.
+func newS3Client(t DeployTarget) (*s3Client, error) {
+	c := &s3Client{
+		region:    t.Region,
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:     os.Getenv("AWS_SESSION_TOKEN"),
+		now:       time.Now,
+	}
+	if c.region == "" {
+		c.region = "us-east-1"
+	}
+	if c.accessKey == "" || c.secretKey == "" {
+		return nil, fmt.Errorf("set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY to deploy to s3")
+	}
+
+	if t.Endpoint == "" {
+		c.base = &url.URL{Scheme: "https", Host: t.Bucket + ".s3." + c.region + ".amazonaws.com", Path: "/"}
+		return c, nil
+	}
+	u, err := url.Parse(t.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("endpoint %q isn't an http(s) URL", t.Endpoint)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + t.Bucket + "/"
+	c.base = u
+	return c, nil
+}
+
+// listBucketResult is a page of a ListObjectsV2 response.
+type listBucketResult struct {
+	Contents []struct {
+		Key  string `xml:"Key"`
+		ETag string `xml:"ETag"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list returns the ETag of every object whose key starts with prefix, by
+// key. ETags of objects uploaded in one part are the MD5 digest of their
+// contents.
+func (c *s3Client) list(ctx context.Context, prefix string) (map[string]string, error) {
+	objects := make(map[string]string)
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		body, err := c.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing bucket: %w", err)
+		}
+		var page listBucketResult
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("listing bucket: %w", err)
+		}
+		for _, obj := range page.Contents {
+			objects[obj.Key] = strings.Trim(obj.ETag, `"`)
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// put uploads data as the object at key.
+func (c *s3Client) put(ctx context.Context, key string, data []byte, contentType, cacheControl string) error {
+	header := http.Header{"Content-Type": {contentType}}
+	if cacheControl != "" {
+		header.Set("Cache-Control", cacheControl)
+	}
+	if _, err := c.do(ctx, http.MethodPut, key, nil, header, data); err != nil {
+		return fmt.Errorf("uploading %s: %w", key, err)
+	}
+	return nil
+}
+
+// delete deletes the object at key.
+func (c *s3Client) delete(ctx context.Context, key string) error {
+	if _, err := c.do(ctx, http.MethodDelete, key, nil, nil, nil); err != nil {
+		return fmt.Errorf("deleting %s: %w", key, err)
+	}
+	return nil
+}
+
+// do sends a signed request for key (or the bucket, if key is empty) and
+// returns the response body, or an error with S3's message if the response
+// isn't a success.
+func (c *s3Client) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) ([]byte, error) {
+	u := *c.base
+	u.Path += key
+	u.RawPath = awsEscapePath(u.Path)
+	u.RawQuery = awsQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	c.sign(req, body)
+
+	resp, err := s3HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var s3Err struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+			return nil, fmt.Errorf("%s: %s: %s", resp.Status, s3Err.Code, s3Err.Message)
+		}
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return data, nil
+}
+
+// sign adds AWS Signature Version 4 headers to req, signing its method,
+// path, query, host, the x-amz-* headers, and the SHA-256 of body.
+func (c *s3Client) sign(req *http.Request, body []byte) {
+	now := c.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.token != "" {
+		req.Header.Set("X-Amz-Security-Token", c.token)
+	}
+
+	signed := []string{"host"}
+	canonicalHeaders := "host:" + req.URL.Host + "\n"
+	var amzHeaders []string
+	for k := range req.Header {
+		if lk := strings.ToLower(k); strings.HasPrefix(lk, "x-amz-") {
+			amzHeaders = append(amzHeaders, lk)
+		}
+	}
+	sort.Strings(amzHeaders)
+	for _, k := range amzHeaders {
+		signed = append(signed, k)
+		canonicalHeaders += k + ":" + strings.TrimSpace(req.Header.Get(k)) + "\n"
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + c.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), date)
+	for _, part := range []string{c.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+// awsEscapePath escapes a URL path the way Signature Version 4 expects:
+// every byte except unreserved characters and slashes is percent-encoded.
+func awsEscapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = awsEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// awsQuery encodes query with its keys sorted and escaped the way Signature
+// Version 4 expects, so it can be sent and signed as is.
+func awsQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape percent-encodes every byte of s except the unreserved
+// characters A-Z, a-z, 0-9, '-', '.', '_', and '~'.
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if 'A' <= ch && ch <= 'Z' || 'a' <= ch && ch <= 'z' || '0' <= ch && ch <= '9' || strings.IndexByte("-._~", ch) >= 0 {
+			b.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+// sha256Hex returns the hex-encoded SHA-256 digest of data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key.
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
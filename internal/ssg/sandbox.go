@@ -0,0 +1,197 @@
+package ssg
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// Capabilities describe what a template function can do beyond formatting
+// the data it's given. They're the renderer's capability model for themes:
+// the standard functions and the data passed to templates can't read files,
+// run commands, or reach the network, so a theme's templates can only do
+// those things through functions that declare them. A sandboxed theme (see
+// ThemeConfig.Sandbox) may only call functions whose capabilities are all
+// granted in theme.allow.
+const (
+	CapUnsafeHTML = "unsafe-html" // Emits HTML that isn't escaped, e.g. safeHTML and markdownify
+	CapReadFiles  = "read-files"  // Reads files from disk
+	CapExec       = "exec"        // Runs commands
+	CapNetwork    = "network"     // Makes network requests
+)
+
+// allCapabilities lists every capability. Functions registered without
+// declaring their capabilities are assumed to need all of them.
+var allCapabilities = []string{CapUnsafeHTML, CapReadFiles, CapExec, CapNetwork}
+
+// standardFuncCapabilities lists the capabilities of the standard functions
+// (see templateFuncs) that need any. The rest only format their arguments.
+var standardFuncCapabilities = map[string][]string{
+	"markdownify": {CapUnsafeHTML},
+	"safeHTML":    {CapUnsafeHTML},
+	"getJSON":     {CapNetwork},
+	"getCSV":      {CapNetwork},
+}
+
+// funcCapabilities returns the capabilities template function name needs.
+// Registered functions replace standard ones, and user-defined funcs from
+// config.yaml are part of the site rather than the theme, so they're trusted.
+func funcCapabilities(config SiteConfig, name string) []string {
+	if _, ok := config.Funcs[name]; ok {
+		return nil
+	}
+	registeredFuncsMu.Lock()
+	_, registered := registeredFuncs[name]
+	caps, declared := registeredCaps[name]
+	registeredFuncsMu.Unlock()
+	if registered {
+		if !declared {
+			return allCapabilities
+		}
+		return caps
+	}
+	return standardFuncCapabilities[name]
+}
+
+// checkSandbox checks that the templates a sandboxed theme contributes only
+// call functions whose capabilities the config grants. Templates the site
+// overrides in templateDir aren't checked: the site's own templates are
+// trusted. Returns nil if the theme isn't sandboxed.
+//
+// Templates are checked statically before they're parsed for rendering, so
+// a theme that needs more than it's been granted fails the build before any
+// of its code runs.
+//
+// Parameters:
+//   - src: Filesystem the site is read from
+//   - config: Site configuration (theme.sandbox, theme.allow, and funcs)
+//   - templateDir: The site's template directory (e.g., "templates")
+//   - themeDir: Directory of the theme (e.g., "themes/paper")
+//
+// Returns an error listing every disallowed call, or if a template can't be
+// read or parsed.
+func checkSandbox(src fs.FS, config SiteConfig, templateDir, themeDir string) error {
+	if !config.Theme.Sandbox || themeDir == "" {
+		return nil
+	}
+	for _, c := range config.Theme.Allow {
+		if !slices.Contains(allCapabilities, c) {
+			return fmt.Errorf("theme.allow: unknown capability %q (want one of %s)", c, strings.Join(allCapabilities, ", "))
+		}
+	}
+
+	themeTemplates := path.Join(themeDir, "templates")
+	if _, err := fs.Stat(src, themeTemplates); err != nil {
+		return nil
+	}
+	fsys, err := subFS(src, themeTemplates)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	err = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) != ".html" {
+			return err
+		}
+		if _, err := fs.Stat(src, path.Join(templateDir, p)); err == nil {
+			return nil
+		}
+		text, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		names, err := templateFuncNames(p, string(text))
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			for _, c := range funcCapabilities(config, name) {
+				if !slices.Contains(config.Theme.Allow, c) {
+					problems = append(problems, fmt.Sprintf("templates/%s calls %s, which needs the %s capability", p, name, c))
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("checking theme %s: %w", config.Theme.Name, err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("sandboxed theme %s isn't allowed to run:\n  %s\ngrant capabilities with theme.allow if you trust the theme", config.Theme.Name, strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// templateFuncNames parses a template and returns the names of the functions
+// it calls, sorted and without duplicates. Functions don't need to be
+// defined, so a template can be checked before its FuncMap exists.
+func templateFuncNames(name, text string) ([]string, error) {
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+	if _, err := tree.Parse(text, "", "", trees); err != nil {
+		return nil, err
+	}
+	trees[name] = tree
+
+	seen := make(map[string]bool)
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.IdentifierNode:
+			seen[n.Ident] = true
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		}
+	}
+	for _, tree := range trees {
+		walk(tree.Root)
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
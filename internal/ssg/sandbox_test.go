@@ -0,0 +1,74 @@
+package ssg
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestTemplateFuncNames tests finding the functions a template calls
+func TestTemplateFuncNames(t *testing.T) {
+	text := `{{define "posts"}}{{range sortByTitle .Posts}}{{.Title | truncate 10}}{{end}}` +
+		`{{if eq .Kind "home"}}{{template "nav" (readFile "/etc/passwd")}}{{else}}{{.Site.Title}}{{end}}{{end}}` +
+		`{{with .Post}}{{safeHTML .Content}}{{end}}`
+	got, err := templateFuncNames("posts.html", text)
+	if err != nil {
+		t.Fatalf("templateFuncNames() failed: %v", err)
+	}
+	want := []string{"eq", "readFile", "safeHTML", "sortByTitle", "truncate"}
+	if !slices.Equal(got, want) {
+		t.Errorf("templateFuncNames() = %v, want %v", got, want)
+	}
+}
+
+// TestBuild_SandboxedTheme tests that sandboxed themes can only call allowed functions
+func TestBuild_SandboxedTheme(t *testing.T) {
+	RegisterFunc("sandboxTestEnv", func() string { return "secret" })
+	RegisterFuncWithCapabilities("sandboxTestUpper", strings.ToUpper)
+	t.Cleanup(func() {
+		registeredFuncsMu.Lock()
+		defer registeredFuncsMu.Unlock()
+		for _, name := range []string{"sandboxTestEnv", "sandboxTestUpper"} {
+			delete(registeredFuncs, name)
+			delete(registeredCaps, name)
+		}
+	})
+
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"config.yaml":                       "title: Test Blog\ntheme:\n  name: shady\n  sandbox: true\n",
+		"themes/shady/templates/base.html":  "<html><body>{{sandboxTestUpper .Site.Title}}{{template \"posts\" .}}</body></html>",
+		"themes/shady/templates/posts.html": "{{define \"posts\"}}{{sandboxTestEnv}}{{end}}",
+		"themes/shady/templates/post.html":  "{{define \"posts\"}}{{safeHTML \"<b>x</b>\"}}{{end}}",
+		"content/posts/2024-01-15-first.md": "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\nHello.\n",
+	})
+	t.Chdir(tmpDir)
+
+	err := Build(context.Background(), BuildOptions{})
+	if err == nil {
+		t.Fatal("Build() with a sandboxed theme calling unsafe funcs succeeded, want error")
+	}
+	for _, want := range []string{"posts.html calls sandboxTestEnv", "post.html calls safeHTML, which needs the unsafe-html capability"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Build() error = %v, want it to mention %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "sandboxTestUpper") {
+		t.Errorf("Build() error = %v, want functions declaring no capabilities allowed", err)
+	}
+
+	// Granting unsafe-html and overriding the template that needs more fixes the build
+	writeFiles(t, tmpDir, map[string]string{
+		"config.yaml":          "title: Test Blog\ntheme:\n  name: shady\n  sandbox: true\n  allow: [unsafe-html]\n",
+		"templates/posts.html": "{{define \"posts\"}}{{sandboxTestEnv}}{{end}}",
+	})
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() with capabilities granted failed: %v", err)
+	}
+
+	writeFiles(t, tmpDir, map[string]string{"config.yaml": "title: Test Blog\ntheme:\n  name: shady\n  sandbox: true\n  allow: [everything]\n"})
+	if err := Build(context.Background(), BuildOptions{}); err == nil || !strings.Contains(err.Error(), "unknown capability") {
+		t.Errorf("Build() error = %v, want an unknown capability error", err)
+	}
+}
@@ -0,0 +1,155 @@
+package ssg
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// dateFields are the frontmatter fields FrontmatterConfig.DateFormat applies to.
+var dateFields = []string{"date", "publishDate", "expiryDate"}
+
+// FrontmatterConfig is a schema the frontmatter of posts and section entries
+// is validated against, so a site's conventions are enforced as it grows.
+// Problems are printed as warnings by build, fail it with --strict, and are
+// reported by check. Empty fields don't validate anything.
+//
+// Example config.yaml:
+//
+//	frontmatter:
+//	  required: [title, date, description]
+//	  dateFormat: "2006-01-02"
+//	  tags: [go, web, tools]
+//	  maxDescription: 160
+type FrontmatterConfig struct {
+	Required       []string `yaml:"required"`       // Fields every post must set, e.g. [title, date, description]
+	DateFormat     string   `yaml:"dateFormat"`     // Go layout date, publishDate, and expiryDate must be written in, e.g. "2006-01-02" (default: any YAML timestamp)
+	Tags           []string `yaml:"tags"`           // Tags posts may use (default: any)
+	Categories     []string `yaml:"categories"`     // Categories posts may use (default: any)
+	MaxDescription int      `yaml:"maxDescription"` // Longest description allowed, in characters (default: no limit)
+}
+
+// enabled reports whether the schema validates anything.
+func (c FrontmatterConfig) enabled() bool {
+	return len(c.Required) > 0 || c.DateFormat != "" || len(c.Tags) > 0 || len(c.Categories) > 0 || c.MaxDescription > 0
+}
+
+// validateFile checks the frontmatter of the post at file in fsys against the
+// schema, returning a problem for each violation, located as "file:line"
+// where the field is written. Files whose frontmatter can't be parsed have
+// no problems here; parsing them reports that.
+func (c FrontmatterConfig) validateFile(fsys fs.FS, file string) ([]problem, error) {
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parser.Fields(content, file)
+	if err != nil {
+		return nil, nil
+	}
+	return c.validate(file, fields), nil
+}
+
+// validate checks the fields of the post at file against the schema.
+func (c FrontmatterConfig) validate(file string, fields []parser.Field) []problem {
+	var problems []problem
+	report := func(f parser.Field, format string, args ...any) {
+		problems = append(problems, problem{File: fmt.Sprintf("%s:%d", file, f.Line), Message: fmt.Sprintf(format, args...)})
+	}
+
+	byName := make(map[string]parser.Field, len(fields))
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+	for _, name := range c.Required {
+		if !byName[name].Set {
+			problems = append(problems, problem{File: file, Message: "missing required field: " + name})
+		}
+	}
+
+	for _, f := range fields {
+		switch {
+		case c.DateFormat != "" && slices.Contains(dateFields, f.Name) && f.Value != "":
+			if _, err := time.Parse(c.DateFormat, f.Value); err != nil {
+				report(f, "%s %q isn't in the format %q", f.Name, f.Value, c.DateFormat)
+			}
+		case f.Name == "tags" && len(c.Tags) > 0:
+			for _, tag := range fieldItems(f) {
+				if !slices.Contains(c.Tags, tag) {
+					report(f, "tag %q isn't allowed (allowed: %s)", tag, strings.Join(c.Tags, ", "))
+				}
+			}
+		case f.Name == "categories" && len(c.Categories) > 0:
+			for _, category := range fieldItems(f) {
+				if !slices.Contains(c.Categories, category) {
+					report(f, "category %q isn't allowed (allowed: %s)", category, strings.Join(c.Categories, ", "))
+				}
+			}
+		case f.Name == "description" && c.MaxDescription > 0:
+			if n := utf8.RuneCountInString(f.Value); n > c.MaxDescription {
+				report(f, "description is %d characters, over the maximum of %d", n, c.MaxDescription)
+			}
+		}
+	}
+	return problems
+}
+
+// fieldItems returns the items of a list field, or a scalar field's value
+// as the only item.
+func fieldItems(f parser.Field) []string {
+	if f.Value != "" {
+		return []string{f.Value}
+	}
+	return f.Values
+}
+
+// validateContent checks the posts in content/posts in fsys, the language
+// directories of a multilingual site, and the entries of each section (see
+// loadSections) against the config's frontmatter schema.
+func validateContent(fsys fs.FS, config *SiteConfig) ([]problem, error) {
+	if !config.Frontmatter.enabled() {
+		return nil, nil
+	}
+	dirs, err := contentDirs(fsys, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []problem
+	for _, dir := range dirs {
+		paths, err := contentPaths(fsys, dir, config.isContentFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			found, err := config.Frontmatter.validateFile(fsys, path)
+			if err != nil {
+				return nil, err
+			}
+			problems = append(problems, found...)
+		}
+	}
+	return problems, nil
+}
+
+// reportFrontmatterProblems warns about posts in fsys that don't match the
+// config's frontmatter schema. With strict, any problem is an error.
+func reportFrontmatterProblems(fsys fs.FS, config *SiteConfig, strict bool) error {
+	problems, err := validateContent(fsys, config)
+	if err != nil {
+		return fmt.Errorf("validating frontmatter: %w", err)
+	}
+	for _, p := range problems {
+		slog.Warn("invalid frontmatter", "file", p.File, "problem", p.Message)
+	}
+	if strict && len(problems) > 0 {
+		return fmt.Errorf("found %d frontmatter problems", len(problems))
+	}
+	return nil
+}
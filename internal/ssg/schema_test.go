@@ -0,0 +1,93 @@
+package ssg
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestValidateContent tests that each schema rule is reported at the field's line
+func TestValidateContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "frontmatter:\n  required: [title, description]\n  dateFormat: \"2006-01-02\"\n" +
+		"  tags: [go, web]\n  categories: [notes]\n  maxDescription: 10\n"
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First\ndate: 2024-01-15\ndescription: Short\ntags: [go]\n---\n\nFine.\n"
+	site["content/posts/bad.md"] = "---\ntitle: Bad\ndate: 2024-01-15T10:00:00Z\ndescription: Far too long a description\n" +
+		"tags: [go, rust]\ncategories: misc\n---\n\nBad.\n"
+	site["content/posts/empty.md"] = "---\ntitle: Empty\ndescription:\n---\n\nEmpty.\n"
+	site["content/notes/note.md"] = "---\ndescription: Note\n---\n\nNote.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	config, err := LoadConfig("config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := validateContent(DirFS("."), config)
+	if err != nil {
+		t.Fatalf("validateContent() failed: %v", err)
+	}
+
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.File+": "+p.Message)
+	}
+	want := []string{
+		`content/posts/bad.md:3: date "2024-01-15T10:00:00Z" isn't in the format "2006-01-02"`,
+		"content/posts/bad.md:4: description is 26 characters, over the maximum of 10",
+		`content/posts/bad.md:5: tag "rust" isn't allowed (allowed: go, web)`,
+		`content/posts/bad.md:6: category "misc" isn't allowed (allowed: notes)`,
+		"content/posts/empty.md: missing required field: description",
+		"content/notes/note.md: missing required field: title",
+	}
+	for _, w := range want {
+		if !slices.Contains(got, w) {
+			t.Errorf("problems missing %q, got:\n%s", w, strings.Join(got, "\n"))
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d problems, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+}
+
+// TestBuild_StrictFrontmatter tests that frontmatter problems only fail strict builds
+func TestBuild_StrictFrontmatter(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "frontmatter:\n  required: [description]\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Errorf("Build() failed without strict: %v", err)
+	}
+	err := Build(context.Background(), BuildOptions{Strict: true})
+	if err == nil || !strings.Contains(err.Error(), "1 frontmatter problems") {
+		t.Errorf("Build() with strict = %v, want frontmatter problems", err)
+	}
+}
+
+// TestCheckSite_Schema tests that check reports schema problems once
+func TestCheckSite_Schema(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "frontmatter:\n  required: [title]\n  tags: [go]\n"
+	site["content/posts/2024-02-01-second.md"] = "---\ndate: 2024-02-01T10:00:00Z\ntags: [web]\n---\n\nNo title.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	problems, err := checkSite(CheckOptions{ConfigPath: "config.yaml"}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("checkSite() failed: %v", err)
+	}
+	want := []problem{
+		{File: "content/posts/2024-02-01-second.md", Message: "missing required field: title"},
+		{File: "content/posts/2024-02-01-second.md:3", Message: `tag "web" isn't allowed (allowed: go)`},
+	}
+	if !slices.Equal(problems, want) {
+		t.Errorf("problems = %v, want %v", problems, want)
+	}
+}
@@ -0,0 +1,152 @@
+package ssg
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// ScriptConfig is a JavaScript or TypeScript entry point bundled with
+// esbuild: its imports (including packages in node_modules/) are bundled
+// into one file, which is written under a name with a content hash, so it
+// can be cached forever. Templates get its URL from .Bundles by name:
+//
+//	<script src="{{ index .Bundles "js/app.js" }}" defer></script>
+//
+// Example config.yaml:
+//
+//	scripts:
+//	  - entry: assets/js/app.ts
+//	    name: js/app.js
+//	    target: es2020
+type ScriptConfig struct {
+	Entry     string `yaml:"entry"`     // Entry point relative to the site root (.js, .ts, .jsx, or .tsx)
+	Name      string `yaml:"name"`      // Output path before hashing (default: js/<entry name>.js)
+	Format    string `yaml:"format"`    // "iife" (default) or "esm" for <script type="module">
+	Target    string `yaml:"target"`    // Oldest JavaScript version to support, e.g. "es2018" (default: esnext)
+	Minify    bool   `yaml:"minify"`    // Minify the bundle (always on when the site-wide minify is set)
+	Sourcemap bool   `yaml:"sourcemap"` // Also write a source map, linked from the bundle
+}
+
+// scriptTargets maps the target names a ScriptConfig accepts to esbuild's.
+var scriptTargets = map[string]api.Target{
+	"":       api.ESNext,
+	"esnext": api.ESNext,
+	"es2015": api.ES2015,
+	"es2016": api.ES2016,
+	"es2017": api.ES2017,
+	"es2018": api.ES2018,
+	"es2019": api.ES2019,
+	"es2020": api.ES2020,
+	"es2021": api.ES2021,
+	"es2022": api.ES2022,
+	"es2023": api.ES2023,
+	"es2024": api.ES2024,
+}
+
+// scriptFormats maps the formats a ScriptConfig accepts to esbuild's.
+var scriptFormats = map[string]api.Format{
+	"":     api.FormatIIFE,
+	"iife": api.FormatIIFE,
+	"esm":  api.FormatESModule,
+}
+
+// name returns the output path of the script before hashing.
+func (s ScriptConfig) name() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	base := filepath.Base(s.Entry)
+	return "js/" + strings.TrimSuffix(base, filepath.Ext(base)) + ".js"
+}
+
+// buildScripts bundles each configured script in src with esbuild and writes
+// it to outputDir in out, as <name>-<hash>.js (e.g., "js/app-5KQ2BZ7N.js").
+// esbuild reads the entry and its imports itself, so src must be on disk
+// (see DirFS).
+//
+// Returns a map of script name → URL for templates, or an error if a script
+// is misconfigured, fails to compile (with esbuild's messages), or can't be
+// written.
+func buildScripts(src fs.FS, out FS, scripts []ScriptConfig, outputDir string, minify bool) (map[string]string, error) {
+	urls := make(map[string]string)
+	absOutput, err := filepath.Abs(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range scripts {
+		if s.Entry == "" {
+			return nil, fmt.Errorf("script is missing an entry")
+		}
+		name := s.name()
+		entry, ok := diskPath(src, s.Entry)
+		if !ok {
+			return nil, fmt.Errorf("script %s: scripts can only be bundled from a site on disk", name)
+		}
+		target, ok := scriptTargets[strings.ToLower(s.Target)]
+		if !ok {
+			return nil, fmt.Errorf("script %s: unknown target %q (want es2015 to es2024, or esnext)", name, s.Target)
+		}
+		format, ok := scriptFormats[s.Format]
+		if !ok {
+			return nil, fmt.Errorf("script %s: unknown format %q (want iife or esm)", name, s.Format)
+		}
+		sourcemap := api.SourceMapNone
+		if s.Sourcemap {
+			sourcemap = api.SourceMapLinked
+		}
+
+		dir, file := path.Split(name)
+		result := api.Build(api.BuildOptions{
+			EntryPoints:       []string{entry},
+			Bundle:            true,
+			Outdir:            filepath.Join(absOutput, filepath.FromSlash(dir)),
+			EntryNames:        strings.TrimSuffix(file, path.Ext(file)) + "-[hash]",
+			Format:            format,
+			Target:            target,
+			Platform:          api.PlatformBrowser,
+			MinifyWhitespace:  s.Minify || minify,
+			MinifyIdentifiers: s.Minify || minify,
+			MinifySyntax:      s.Minify || minify,
+			Sourcemap:         sourcemap,
+			LogLevel:          api.LogLevelSilent,
+		})
+		if len(result.Errors) > 0 {
+			return nil, fmt.Errorf("script %s: %s", name, esbuildError(result.Errors))
+		}
+
+		// esbuild only names the files under Outdir; they're written to out
+		for _, file := range result.OutputFiles {
+			rel, err := filepath.Rel(absOutput, file.Path)
+			if err != nil {
+				return nil, err
+			}
+			if err := out.WriteFile(filepath.Join(outputDir, rel), file.Contents, 0600); err != nil {
+				return nil, fmt.Errorf("writing script %s: %w", name, err)
+			}
+			if strings.HasSuffix(file.Path, ".js") {
+				urls[name] = "/" + filepath.ToSlash(rel)
+			}
+		}
+	}
+
+	return urls, nil
+}
+
+// esbuildError describes esbuild's error messages, each with its location.
+func esbuildError(msgs []api.Message) string {
+	var parts []string
+	for _, msg := range msgs {
+		if loc := msg.Location; loc != nil {
+			parts = append(parts, fmt.Sprintf("%s:%d:%d: %s", loc.File, loc.Line, loc.Column+1, msg.Text))
+		} else {
+			parts = append(parts, msg.Text)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
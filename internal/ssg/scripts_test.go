@@ -0,0 +1,77 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestBuildScripts tests bundling a TypeScript entry point and its imports
+func TestBuildScripts(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"assets/app.ts":    "import { greet } from './greet';\nconst el: HTMLElement | null = document.body;\nel?.append(greet('World'));\n",
+		"assets/greet.ts":  "export function greet(name: string): string {\n  return `Hello, ${name}!`;\n}\n",
+		"assets/broken.ts": "const x = ;\n",
+	})
+	t.Chdir(tmpDir)
+
+	urls, err := buildScripts(DirFS("."), DirFS("."), []ScriptConfig{{Entry: "assets/app.ts", Sourcemap: true}}, "public", true)
+	if err != nil {
+		t.Fatalf("buildScripts() failed: %v", err)
+	}
+	url := urls["js/app.js"]
+	if !regexp.MustCompile(`^/js/app-[A-Z0-9]+\.js$`).MatchString(url) {
+		t.Fatalf("URL of js/app.js = %q, want /js/app-<hash>.js", url)
+	}
+	js, err := os.ReadFile(filepath.Join("public", filepath.FromSlash(url)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(js), "Hello, ") || strings.Contains(string(js), ": string") {
+		t.Errorf("bundle = %s, want greet bundled in with its types removed", js)
+	}
+	if !strings.Contains(string(js), "sourceMappingURL="+filepath.Base(url)+".map") {
+		t.Errorf("bundle = %s, want a link to its source map", js)
+	}
+	if _, err := os.Stat(filepath.Join("public", filepath.FromSlash(url)+".map")); err != nil {
+		t.Errorf("source map wasn't written: %v", err)
+	}
+
+	_, err = buildScripts(DirFS("."), DirFS("."), []ScriptConfig{{Entry: "assets/broken.ts"}}, "public", false)
+	if err == nil || !strings.Contains(err.Error(), "assets/broken.ts:1:") {
+		t.Errorf("buildScripts() error = %v, want the location of the syntax error", err)
+	}
+	if _, err := buildScripts(DirFS("."), DirFS("."), []ScriptConfig{{Entry: "assets/app.ts", Target: "es3"}}, "public", false); err == nil {
+		t.Error("buildScripts() with an unknown target succeeded")
+	}
+}
+
+// TestBuild_Scripts tests that pages link bundled scripts through .Bundles
+func TestBuild_Scripts(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "scripts:\n  - entry: assets/main.js\n    name: js/site.js\n"
+	site["assets/main.js"] = "console.log('hi');\n"
+	site["templates/base.html"] = `<html><head><script src="{{ index .Bundles "js/site.js" }}"></script></head><body>{{ template "posts" . }}</body></html>`
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	index, err := os.ReadFile(filepath.Join("public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := regexp.MustCompile(`src="(/js/site-[A-Z0-9]+\.js)"`).FindSubmatch(index)
+	if m == nil {
+		t.Fatalf("index.html = %s, want a link to the hashed script", index)
+	}
+	if _, err := os.Stat(filepath.Join("public", filepath.FromSlash(string(m[1])))); err != nil {
+		t.Errorf("linked script wasn't written: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package ssg
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// defaultSearchPath is where the search index is written unless
+// search.path is set.
+const defaultSearchPath = "/search.json"
+
+// SearchConfig configures the search index for client-side search.
+//
+// Example config.yaml:
+//
+//	search:
+//	  enabled: true
+//	  contentLength: 5000
+type SearchConfig struct {
+	Enabled       bool   `yaml:"enabled"`       // Write the search index
+	Path          string `yaml:"path"`          // Site path of the index (default: /search.json)
+	ContentLength int    `yaml:"contentLength"` // Characters of each page's text to include (default: all)
+}
+
+// searchEntry is a page in the search index.
+type searchEntry struct {
+	Title   string    `json:"title"`
+	URL     string    `json:"url"`
+	Date    time.Time `json:"date"`
+	Section string    `json:"section,omitempty"` // Section name, for section entries
+	Tags    []string  `json:"tags"`
+	Summary string    `json:"summary"` // Description, or the start of the text
+	Content string    `json:"content"` // Plain text, without markup
+}
+
+// writeSearchIndex writes the search index of the published posts and
+// section entries: a JSON array with each page's title, URL, date, tags,
+// summary, and plain-text content. The array can be searched as is, or
+// loaded into a library like Fuse.js (new Fuse(entries, {keys: [...]})) or
+// Lunr (by adding each entry to an index in the browser), so themes can
+// offer search without parsing markdown.
+//
+// Parameters:
+//   - posts: Published posts, in listing order
+//   - sections: Sections whose entries are indexed after the posts
+//   - cfg: Search configuration from config.yaml
+//   - out: Filesystem the site is written to
+//   - outputDir: Output directory (e.g., "public")
+//
+// Returns an error if the path is invalid or the index can't be written.
+// Does nothing if search is disabled.
+func writeSearchIndex(posts []*parser.Post, sections []*Section, cfg SearchConfig, out FS, outputDir string) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	indexPath := cfg.Path
+	if indexPath == "" {
+		indexPath = defaultSearchPath
+	}
+	if !strings.HasPrefix(indexPath, "/") || strings.HasSuffix(indexPath, "/") || path.Clean(indexPath) != indexPath {
+		return fmt.Errorf("search.path %q must be a clean site path to a file, like /search.json", cfg.Path)
+	}
+
+	return writeJSON(out, urlPath(outputDir, indexPath), searchEntries(posts, sections, cfg.ContentLength))
+}
+
+// searchEntries returns the search index entries of posts, followed by the
+// entries of each section, with at most contentLength characters of text
+// each (0 for all of it).
+func searchEntries(posts []*parser.Post, sections []*Section, contentLength int) []searchEntry {
+	entries := make([]searchEntry, 0, len(posts))
+	add := func(post *parser.Post, section string) {
+		text := strings.Join(strings.Fields(htmlText(string(post.Content))), " ")
+		summary := post.Description
+		if summary == "" {
+			summary = truncate(200, text)
+		}
+		if contentLength > 0 && len([]rune(text)) > contentLength {
+			text = string([]rune(text)[:contentLength])
+		}
+		tags := post.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		entries = append(entries, searchEntry{
+			Title:   post.Title,
+			URL:     post.URL,
+			Date:    post.Date,
+			Section: section,
+			Tags:    tags,
+			Summary: summary,
+			Content: text,
+		})
+	}
+	for _, post := range posts {
+		add(post, "")
+	}
+	for _, section := range sections {
+		for _, post := range section.Posts {
+			add(post, section.Name)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,72 @@
+package ssg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestWriteSearchIndex tests indexing posts and section entries as plain text
+func TestWriteSearchIndex(t *testing.T) {
+	tmpDir := t.TempDir()
+	posts := []*parser.Post{
+		{
+			Title:   "Hello",
+			URL:     "/posts/hello.html",
+			Date:    time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			Tags:    []string{"go"},
+			Content: "<h2>Intro</h2>\n<p>Some <em>emphasized</em> text.</p>",
+		},
+		{
+			Title:       "Described",
+			URL:         "/posts/described.html",
+			Description: "A summary",
+			Content:     "<p>Body</p>",
+		},
+	}
+	sections := []*Section{{Name: "notes", Posts: []*parser.Post{{Title: "Vim", URL: "/notes/vim.html", Content: "<p>Use it.</p>"}}}}
+
+	if err := writeSearchIndex(posts, sections, SearchConfig{Enabled: true}, DirFS("."), tmpDir); err != nil {
+		t.Fatalf("writeSearchIndex() failed: %v", err)
+	}
+	var entries []searchEntry
+	readJSON(t, filepath.Join(tmpDir, "search.json"), &entries)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if e := entries[0]; e.Content != "Intro Some emphasized text." || e.Summary != e.Content || len(e.Tags) != 1 || e.Section != "" {
+		t.Errorf("entry = %+v, want plain-text content as the summary", e)
+	}
+	if e := entries[1]; e.Summary != "A summary" || e.Tags == nil {
+		t.Errorf("entry = %+v, want the description as summary and empty tags", e)
+	}
+	if e := entries[2]; e.Section != "notes" || e.URL != "/notes/vim.html" {
+		t.Errorf("section entry = %+v", e)
+	}
+
+	cfg := SearchConfig{Enabled: true, Path: "/assets/index.json", ContentLength: 5}
+	if err := writeSearchIndex(posts, nil, cfg, DirFS("."), tmpDir); err != nil {
+		t.Fatalf("writeSearchIndex() with a path failed: %v", err)
+	}
+	readJSON(t, filepath.Join(tmpDir, "assets", "index.json"), &entries)
+	if entries[0].Content != "Intro" {
+		t.Errorf("content = %q, want it cut to 5 characters", entries[0].Content)
+	}
+
+	for _, bad := range []string{"search.json", "/../search.json", "/search/"} {
+		if err := writeSearchIndex(posts, nil, SearchConfig{Enabled: true, Path: bad}, DirFS("."), tmpDir); err == nil {
+			t.Errorf("writeSearchIndex() with path %q succeeded, want error", bad)
+		}
+	}
+
+	empty := t.TempDir()
+	if err := writeSearchIndex(posts, nil, SearchConfig{}, DirFS("."), empty); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(empty, "search.json")); !os.IsNotExist(err) {
+		t.Error("search index written while disabled")
+	}
+}
@@ -0,0 +1,244 @@
+package ssg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// sectionIndexFile is the optional file in a content directory describing
+// the directory itself rather than an entry in it: its frontmatter title
+// names the section, and its content is shown on the section's list page.
+const sectionIndexFile = "_index.md"
+
+// Section is a directory under content/ other than content/posts (e.g.,
+// content/notes/ or content/projects/). Each section's entries are published
+// under the section's URL prefix, and the section gets its own list page.
+type Section struct {
+	Name  string         // Directory relative to content/, e.g. "notes" or "notes/go"
+	Title string         // Title from _index.md, or the directory name (e.g., "Notes")
+	URL   string         // Site-relative URL of the list page, e.g. "/notes/"
+	Index *parser.Post   // Parsed _index.md, or nil if there is none
+	Posts []*parser.Post // Published entries, newest first
+}
+
+// findSections returns the names of the sections in contentDir in fsys: every
+// directory containing content files (those isContent reports true for) or
+// page bundles, relative to contentDir and slash-separated. Page bundles
+// aren't sections themselves. content/posts (the blog itself), the directories in skip
+// (the posts of a multilingual site's languages), and directories starting
+// with "." or "_" are skipped. Returns nil if contentDir doesn't exist.
+func findSections(fsys fs.FS, contentDir string, isContent func(name string) bool, skip ...string) ([]string, error) {
+	var names []string
+	err := fs.WalkDir(fsys, contentDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == contentDir && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !d.IsDir() || path == contentDir {
+			return nil
+		}
+
+		rel, err := filepath.Rel(contentDir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if name == "posts" || slices.Contains(skip, name) || strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_") {
+			return fs.SkipDir
+		}
+
+		if _, ok := bundleIndex(fsys, path, isContent); ok {
+			return fs.SkipDir // A page bundle, an entry of the section it's in
+		}
+		entries, err := fs.ReadDir(fsys, path)
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(entries, func(e fs.DirEntry) bool {
+			_, bundle := bundleIndex(fsys, filepath.Join(path, e.Name()), isContent)
+			return e.IsDir() && bundle || !e.IsDir() && isContent(e.Name())
+		}) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names, err
+}
+
+// contentDirs returns the directories in fsys whose markdown files are
+// published: content/posts, the posts of each of the site's languages, and
+// its sections.
+func contentDirs(fsys fs.FS, config *SiteConfig) ([]string, error) {
+	dirs := []string{filepath.Join("content", "posts")}
+	for _, lang := range config.siteLanguages() {
+		dirs = append(dirs, filepath.Join("content", lang))
+	}
+	names, err := findSections(fsys, "content", config.isContentFile, config.siteLanguages()...)
+	if err != nil {
+		return nil, fmt.Errorf("finding sections: %w", err)
+	}
+	for _, name := range names {
+		dirs = append(dirs, filepath.Join("content", filepath.FromSlash(name)))
+	}
+	return dirs, nil
+}
+
+// contentFiles returns the content files of the site's content directories
+// (see contentDirs) in fsys, other than section index files, including the
+// index files of page bundles.
+func contentFiles(fsys fs.FS, config *SiteConfig) ([]string, error) {
+	dirs, err := contentDirs(fsys, config)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, dir := range dirs {
+		paths, err := contentPaths(fsys, dir, config.isContentFile)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, paths...)
+	}
+	return files, nil
+}
+
+// loadSections parses the sections under content/ in fsys, filtering and
+// sorting each section's entries like posts (see loadPosts).
+//
+// An entry's URL is its slug under the section's prefix in the site's URL
+// style (e.g., "/notes/vim.html"), and the list page is at the section's
+// directory (e.g., "/notes/").
+//
+// Returns the sections in path order, or an error if a file can't be parsed.
+func loadSections(fsys fs.FS, p *parser.Parser, config *SiteConfig, drafts, future, expired bool) ([]*Section, error) {
+	names, err := findSections(fsys, "content", config.isContentFile, config.siteLanguages()...)
+	if err != nil {
+		return nil, fmt.Errorf("finding sections: %w", err)
+	}
+
+	var sections []*Section
+	for _, name := range names {
+		dir := filepath.Join("content", filepath.FromSlash(name))
+		posts, err := parseAllPosts(fsys, p, dir)
+		if err != nil {
+			return nil, fmt.Errorf("parsing section %s: %w", name, err)
+		}
+
+		section := &Section{
+			Name:  name,
+			Title: sectionTitle(name),
+			URL:   "/" + name + "/",
+			Posts: publishPosts(posts, drafts, future, expired),
+		}
+		for _, post := range section.Posts {
+			post.URL = pageURL(config.URLs, "/"+name+"/"+post.Slug)
+		}
+		if err := assignResources(fsys, section.Posts); err != nil {
+			return nil, err
+		}
+		if err := assignAttachments(fsys, section.Posts); err != nil {
+			return nil, err
+		}
+
+		indexPath := filepath.Join(dir, sectionIndexFile)
+		if _, err := fs.Stat(fsys, indexPath); err == nil {
+			section.Index, err = parseFile(fsys, p, indexPath)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", indexPath, err)
+			}
+			section.Index.URL = section.URL
+			if section.Index.Title != "" {
+				section.Title = section.Index.Title
+			}
+		}
+
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+// sectionTitle derives a title from the last element of a section name:
+// dashes and underscores become spaces and each word is capitalized, so
+// "notes/side-projects" becomes "Side Projects".
+func sectionTitle(name string) string {
+	base := name[strings.LastIndex(name, "/")+1:]
+	words := strings.FieldsFunc(base, func(r rune) bool {
+		return r == '-' || r == '_' || unicode.IsSpace(r)
+	})
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToUpper(r)) + w[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// sectionTemplate returns the content template for a section's list page,
+// looked up in order: "<section>.html" (e.g., "notes.html", or "notes/go.html"
+// for a nested section), then "list.html", then "posts.html".
+func (r *Renderer) sectionTemplate(name string) string {
+	return r.contentTemplate(name+".html", "list.html")
+}
+
+// contentTemplate returns the first of candidates the renderer has, or
+// "posts.html" if it has none of them.
+func (r *Renderer) contentTemplate(candidates ...string) string {
+	for _, candidate := range candidates {
+		if _, err := fs.Stat(r.fs, candidate); err == nil {
+			return candidate
+		}
+	}
+	return "posts.html"
+}
+
+// renderSection renders a section's list page to an HTML file.
+//
+// Called by Build for each section. The content template (see
+// sectionTemplate) receives the section's entries as .Posts, the section as
+// .Section, and its _index.md, if any, as .Post.
+//
+// Parameters:
+//   - section: Section from loadSections
+//   - config: Site configuration (title, author, etc.) for template rendering
+//   - outputPath: Where to write the HTML file (e.g., "public/notes/index.html")
+//
+// Returns an error if rendering or file writing fails.
+func (r *Renderer) renderSection(section *Section, config SiteConfig, outputPath string) error {
+	data := PageData{
+		Site:    config,
+		Post:    section.Index,
+		Posts:   section.Posts,
+		Section: section,
+		Title:   section.Title,
+		Kind:    KindSection,
+		URL:     section.URL,
+	}
+
+	return r.renderToFile(r.sectionTemplate(section.Name), data, outputPath)
+}
+
+// renderSectionPost renders an entry of a section with "post.html", like a
+// blog post, with the section available as .Section.
+func (r *Renderer) renderSectionPost(section *Section, post *parser.Post, config SiteConfig, outputPath string) error {
+	data := PageData{
+		Site:    config,
+		Post:    post,
+		Section: section,
+		Series:  r.series[post.Series],
+		Outputs: postOutputs(post),
+		Title:   post.Title,
+		Kind:    KindPost,
+		URL:     post.URL,
+	}
+
+	return r.renderToFile("post.html", data, outputPath)
+}
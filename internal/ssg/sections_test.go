@@ -0,0 +1,151 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFindSections tests that directories with markdown become sections
+func TestFindSections(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"content/posts/a.md":         "",
+		"content/notes/a.md":         "",
+		"content/notes/go/b.md":      "",
+		"content/projects/_index.md": "",
+		"content/images/logo.png":    "",
+		"content/_drafts/c.md":       "",
+		"content/.git/d.md":          "",
+	})
+
+	names, err := findSections(DirFS("."), filepath.Join(tmpDir, "content"), SiteConfig{}.isContentFile)
+	if err != nil {
+		t.Fatalf("findSections() failed: %v", err)
+	}
+	want := []string{"notes", "notes/go", "projects"}
+	if !slices.Equal(names, want) {
+		t.Errorf("findSections() = %v, want %v", names, want)
+	}
+
+	if names, err := findSections(DirFS("."), filepath.Join(tmpDir, "missing"), SiteConfig{}.isContentFile); err != nil || names != nil {
+		t.Errorf("findSections() on a missing dir = %v, %v; want nil, nil", names, err)
+	}
+}
+
+// TestSectionTitle tests deriving titles from section names
+func TestSectionTitle(t *testing.T) {
+	tests := map[string]string{
+		"notes":               "Notes",
+		"notes/side-projects": "Side Projects",
+		"reading_list":        "Reading List",
+		"écrits":              "Écrits",
+	}
+	for name, want := range tests {
+		if got := sectionTitle(name); got != want {
+			t.Errorf("sectionTitle(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// TestBuild_Sections tests section list pages, entry URLs, and template lookup
+func TestBuild_Sections(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["templates/notes.html"] = "{{define \"posts\"}}notes:{{.Title}}|{{.Post.Content}}|{{range .Posts}}{{.URL}},{{end}}{{end}}"
+	site["templates/list.html"] = "{{define \"posts\"}}list:{{.Section.Name}}|{{range .Posts}}{{.URL}},{{end}}{{end}}"
+	site["content/notes/_index.md"] = "---\ntitle: Field Notes\n---\n\nThings I wrote down.\n"
+	site["content/notes/vim.md"] = "---\ntitle: Vim\ndate: 2024-02-01T00:00:00Z\n---\n\nUse it.\n"
+	site["content/notes/emacs.md"] = "---\ntitle: Emacs\ndate: 2024-03-01T00:00:00Z\n---\n\nOr this.\n"
+	site["content/notes/draft.md"] = "---\ntitle: Draft\ndate: 2024-03-01T00:00:00Z\ndraft: true\n---\n"
+	site["content/projects/ssg.md"] = "---\ntitle: SSG\ndate: 2024-01-01T00:00:00Z\n---\n\nThis site.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	notes, err := os.ReadFile(filepath.Join("public", "notes", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(notes), "notes:Field Notes|<p>Things I wrote down.</p>") {
+		t.Errorf("notes list page didn't use notes.html with _index.md:\n%s", notes)
+	}
+	if !strings.Contains(string(notes), "|/notes/emacs.html,/notes/vim.html,") {
+		t.Errorf("notes list page = %s, want entries newest first without drafts", notes)
+	}
+
+	projects, err := os.ReadFile(filepath.Join("public", "projects", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(projects), "list:projects|/projects/ssg.html,") {
+		t.Errorf("projects list page didn't fall back to list.html:\n%s", projects)
+	}
+
+	entry, err := os.ReadFile(filepath.Join("public", "notes", "vim.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(entry), "<h1>Vim</h1>") {
+		t.Errorf("entry page = %s, want it rendered with post.html", entry)
+	}
+
+	index, err := os.ReadFile(filepath.Join("public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(index), "Vim") {
+		t.Error("section entries listed on the blog index")
+	}
+}
+
+// TestBuild_SectionsURLStyle tests that section entries follow the URL style
+func TestBuild_SectionsURLStyle(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "urls: slash\n"
+	site["content/notes/vim.md"] = "---\ntitle: Vim\ndate: 2024-02-01T00:00:00Z\n---\n\nUse it.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	notes, err := os.ReadFile(filepath.Join("public", "notes", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(notes), "Vim") {
+		t.Errorf("notes list page didn't fall back to posts.html:\n%s", notes)
+	}
+	if _, err := os.Stat(filepath.Join("public", "notes", "vim", "index.html")); err != nil {
+		t.Errorf("entry not written for the slash URL style: %v", err)
+	}
+}
+
+// TestCheckSite_Sections tests that links to and from sections are checked
+func TestCheckSite_Sections(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\nSee [notes](/notes/) and [vim](/notes/vim.html).\n"
+	site["content/notes/vim.md"] = "---\ntitle: Vim\ndate: 2024-02-01T00:00:00Z\n---\n\nSee [missing](/notes/missing.html).\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	problems, err := checkSite(CheckOptions{ConfigPath: "config.yaml"}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []problem{{File: filepath.Join("content", "notes", "vim.md"), Message: "broken link to /notes/missing.html"}}
+	if !slices.Equal(problems, want) {
+		t.Errorf("checkSite() = %v, want %v", problems, want)
+	}
+}
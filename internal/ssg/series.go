@@ -0,0 +1,104 @@
+package ssg
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// seriesPrefix is the site path series pages are written under.
+const seriesPrefix = "/series/"
+
+// Series is a group of posts that name it in their frontmatter, read in
+// order:
+//
+//	series: Learning Go
+//	series_weight: 2
+//
+// Each series gets a page listing its posts at /series/<slug>, and is
+// exposed as .Series to that page and to the posts in it, whose
+// SeriesPart, SeriesPrev, and SeriesNext link them together.
+type Series struct {
+	Name  string         // Name from the frontmatter (e.g., "Learning Go")
+	Slug  string         // Slug of the name (e.g., "learning-go")
+	URL   string         // Site-relative URL of the series page (e.g., "/series/learning-go.html")
+	Posts []*parser.Post // Posts in reading order (see collectSeries)
+}
+
+// collectSeries groups posts by their series and links each series'
+// posts, setting their SeriesPart, SeriesPrev, and SeriesNext. Posts with a
+// series_weight come first, lowest first, followed by the rest, oldest
+// first.
+//
+// Returns the series sorted by name, or an error if two names have the same
+// slug or a name has none.
+func collectSeries(posts []*parser.Post, config *SiteConfig) ([]*Series, error) {
+	byName := make(map[string]*Series)
+	bySlug := make(map[string]string)
+	var all []*Series
+	for _, post := range posts {
+		if post.Series == "" {
+			continue
+		}
+		series, ok := byName[post.Series]
+		if !ok {
+			slug := slugifyLang(config.Language, post.Series)
+			if slug == "" {
+				return nil, fmt.Errorf("series %q needs a letter or digit for its URL", post.Series)
+			}
+			if other, ok := bySlug[slug]; ok {
+				return nil, fmt.Errorf("series %q and %q would both be at %s%s", other, post.Series, seriesPrefix, slug)
+			}
+			bySlug[slug] = post.Series
+			series = &Series{Name: post.Series, Slug: slug, URL: pageURL(config.URLs, seriesPrefix+slug)}
+			byName[post.Series] = series
+			all = append(all, series)
+		}
+		series.Posts = append(series.Posts, post)
+	}
+
+	for _, series := range all {
+		sort.SliceStable(series.Posts, func(i, j int) bool {
+			a, b := series.Posts[i], series.Posts[j]
+			switch {
+			case a.SeriesWeight != 0 && b.SeriesWeight == 0:
+				return true
+			case a.SeriesWeight == 0 && b.SeriesWeight != 0:
+				return false
+			case a.SeriesWeight != b.SeriesWeight:
+				return a.SeriesWeight < b.SeriesWeight
+			}
+			return a.Date.Before(b.Date)
+		})
+		for i, post := range series.Posts {
+			post.SeriesPart = i + 1
+			post.SeriesPrev, post.SeriesNext = nil, nil
+			if i > 0 {
+				post.SeriesPrev = series.Posts[i-1]
+			}
+			if i < len(series.Posts)-1 {
+				post.SeriesNext = series.Posts[i+1]
+			}
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all, nil
+}
+
+// renderSeries renders a series' page, listing its posts in reading order.
+// The content template is "series.html", falling back to "list.html" and
+// "posts.html" like a section's (see sectionTemplate), and receives the
+// series as .Series and its posts as .Posts.
+func (r *Renderer) renderSeries(series *Series, config SiteConfig, outputPath string) error {
+	data := PageData{
+		Site:   config,
+		Posts:  series.Posts,
+		Series: series,
+		Title:  series.Name,
+		Kind:   KindSeries,
+		URL:    series.URL,
+	}
+
+	return r.renderToFile(r.sectionTemplate("series"), data, outputPath)
+}
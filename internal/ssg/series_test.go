@@ -0,0 +1,94 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestCollectSeries tests grouping posts into series and their reading order
+func TestCollectSeries(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	intro := &parser.Post{Title: "Intro", Series: "Learning Go", Date: day(20), SeriesWeight: 1}
+	types := &parser.Post{Title: "Types", Series: "Learning Go", Date: day(5)}
+	funcs := &parser.Post{Title: "Funcs", Series: "Learning Go", Date: day(10)}
+	other := &parser.Post{Title: "Other", Series: "Cooking", Date: day(1)}
+	alone := &parser.Post{Title: "Alone", Date: day(2)}
+
+	series, err := collectSeries([]*parser.Post{funcs, other, intro, alone, types}, &SiteConfig{})
+	if err != nil {
+		t.Fatalf("collectSeries() error = %v", err)
+	}
+	if len(series) != 2 || series[0].Name != "Cooking" || series[1].Name != "Learning Go" {
+		t.Fatalf("collectSeries() = %v, want Cooking and Learning Go", series)
+	}
+	goSeries := series[1]
+	if goSeries.Slug != "learning-go" || goSeries.URL != "/series/learning-go.html" {
+		t.Errorf("Slug, URL = %q, %q", goSeries.Slug, goSeries.URL)
+	}
+	var titles []string
+	for _, post := range goSeries.Posts {
+		titles = append(titles, post.Title)
+	}
+	if got := strings.Join(titles, ", "); got != "Intro, Types, Funcs" {
+		t.Errorf("order = %s, want weighted first, then by date", got)
+	}
+	if types.SeriesPart != 2 || types.SeriesPrev != intro || types.SeriesNext != funcs {
+		t.Errorf("Types: part %d, prev %v, next %v", types.SeriesPart, types.SeriesPrev, types.SeriesNext)
+	}
+	if intro.SeriesPrev != nil || funcs.SeriesNext != nil {
+		t.Error("first post has a previous post or last post has a next one")
+	}
+	if alone.SeriesPart != 0 {
+		t.Errorf("post without a series has SeriesPart %d", alone.SeriesPart)
+	}
+
+	_, err = collectSeries([]*parser.Post{{Series: "Go!"}, {Series: "Go?"}}, &SiteConfig{})
+	if err == nil {
+		t.Error("collectSeries() with clashing slugs succeeded")
+	}
+}
+
+// TestBuild_Series tests the series page and navigation on post pages
+func TestBuild_Series(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["templates/post.html"] = `{{define "posts"}}{{ with .Series }}{{ .Name }} {{ $.Post.SeriesPart }}/{{ len .Posts }}` +
+		`{{ with $.Post.SeriesPrev }} prev={{ .URL }}{{ end }}{{ with $.Post.SeriesNext }} next={{ .URL }}{{ end }}{{ end }}{{end}}`
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\nseries: Learning Go\n---\n\nOne.\n"
+	site["content/posts/2024-01-16-second.md"] = "---\ntitle: Second Post\nseries: Learning Go\n---\n\nTwo.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{OutputDir: "public"}); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join("public", "series", "learning-go.html"))
+	if err != nil {
+		t.Fatalf("series page not written: %v", err)
+	}
+	if first, second := strings.Index(string(index), "First Post"), strings.Index(string(index), "Second Post"); first < 0 || second < first {
+		t.Errorf("series page doesn't list the posts in order:\n%s", index)
+	}
+
+	first, err := os.ReadFile(filepath.Join("public", "posts", "first.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(first), "Learning Go 1/2 next=/posts/second.html") {
+		t.Errorf("first.html = %s, want series navigation", first)
+	}
+	second, err := os.ReadFile(filepath.Join("public", "posts", "second.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(second), "Learning Go 2/2 prev=/posts/first.html") {
+		t.Errorf("second.html = %s, want series navigation", second)
+	}
+}
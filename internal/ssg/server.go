@@ -0,0 +1,336 @@
+package ssg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// siteHandler serves a built site the way static hosts like Netlify and
+// GitHub Pages do, so the dev server matches the deployed site:
+//   - /foo/ serves foo/index.html, and /foo redirects to /foo/ if foo is a directory
+//   - /foo serves foo.html if there is no file or directory named foo
+//   - missing paths get 404.html from the site root, with a 404 status
+//   - directories without an index.html are listed only if listings is set
+//
+// If base is set, links to it in HTML pages are rewritten to the local
+// server as they're served (see localURLs).
+type siteHandler struct {
+	fsys     fs.FS // The built site
+	listings bool
+	base     *url.URL     // Site's baseUrl, or nil to serve pages as built
+	files    http.Handler // Serves directory listings
+}
+
+// newSiteHandler returns a handler serving the site in root. If baseURL is
+// not empty, links to it in served pages point at the local server instead,
+// so a site built with its production baseUrl can be clicked through.
+func newSiteHandler(root string, listings bool, baseURL string) *siteHandler {
+	return newFSHandler(DirFS(root), listings, baseURL)
+}
+
+// newFSHandler returns a handler serving the site in fsys, like
+// newSiteHandler.
+func newFSHandler(fsys fs.FS, listings bool, baseURL string) *siteHandler {
+	h := &siteHandler{fsys: fsys, listings: listings, files: http.FileServerFS(fsys)}
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		h.base = u
+	}
+	return h
+}
+
+func (h *siteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.serve(w, r) {
+		h.notFound(w, r)
+	}
+}
+
+// serve responds to r with the file its URL resolves to, reporting false
+// without responding if there's none.
+func (h *siteHandler) serve(w http.ResponseWriter, r *http.Request) bool {
+	urlPath := path.Clean("/" + r.URL.Path)
+	name := strings.TrimPrefix(urlPath, "/")
+	if name == "" {
+		name = "."
+	}
+
+	info, err := fs.Stat(h.fsys, name)
+	switch {
+	case err == nil && info.IsDir():
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			target := urlPath + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return true
+		}
+		if h.serveFile(w, r, path.Join(name, "index.html")) {
+			return true
+		}
+		if h.listings {
+			h.files.ServeHTTP(w, r)
+			return true
+		}
+	case err == nil:
+		return h.serveFile(w, r, name)
+	case urlPath != "/":
+		return h.serveFile(w, r, name+".html")
+	}
+	return false
+}
+
+// serveFile serves the regular file at name, reporting false if it doesn't
+// exist or can't be read.
+func (h *siteHandler) serveFile(w http.ResponseWriter, r *http.Request, name string) bool {
+	info, err := fs.Stat(h.fsys, name)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	content, err := fs.ReadFile(h.fsys, name)
+	if err != nil {
+		return false
+	}
+	if h.base != nil && path.Ext(name) == ".html" {
+		content = localURLs(content, h.base)
+	}
+	http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(content))
+	return true
+}
+
+// notFound responds with the site's 404.html, or a plain message if the site
+// doesn't have one.
+func (h *siteHandler) notFound(w http.ResponseWriter, r *http.Request) {
+	content, err := fs.ReadFile(h.fsys, "404.html")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if h.base != nil {
+		content = localURLs(content, h.base)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(content)
+	}
+}
+
+// localURLs rewrites href, src, srcset, and action attributes in an HTML
+// page that point into the site at base (e.g., "https://example.com/blog/")
+// to root-relative URLs, so "https://example.com/blog/posts/hello.html"
+// becomes "/posts/hello.html". Links are matched by host and path, so http
+// and https links and protocol-relative ones are all rewritten; links to
+// other hosts are left unchanged.
+func localURLs(content []byte, base *url.URL) []byte {
+	basePath := strings.TrimSuffix(base.Path, "/")
+	local := func(ref string) (string, bool) {
+		u, err := url.Parse(strings.TrimSpace(ref))
+		if err != nil || u.Host != base.Host || (u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https") {
+			return ref, false
+		}
+		if u.Path != basePath && !strings.HasPrefix(u.Path, basePath+"/") {
+			return ref, false
+		}
+		rest := strings.TrimPrefix(u.Path, basePath)
+		if rest == "" {
+			rest = "/"
+		}
+		return (&url.URL{Path: rest, RawQuery: u.RawQuery, Fragment: u.Fragment}).String(), true
+	}
+
+	var out bytes.Buffer
+	z := html.NewTokenizer(bytes.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			out.Write(z.Raw())
+			continue
+		}
+
+		raw := bytes.Clone(z.Raw())
+		tok := z.Token()
+		rewritten := false
+		for i, a := range tok.Attr {
+			switch a.Key {
+			case "href", "src", "action":
+				if v, ok := local(a.Val); ok {
+					tok.Attr[i].Val = v
+					rewritten = true
+				}
+			case "srcset":
+				candidates := strings.Split(a.Val, ",")
+				for j, c := range candidates {
+					fields := strings.Fields(c)
+					if len(fields) == 0 {
+						continue
+					}
+					if v, ok := local(fields[0]); ok {
+						fields[0] = v
+						rewritten = true
+					}
+					candidates[j] = strings.Join(fields, " ")
+				}
+				tok.Attr[i].Val = strings.Join(candidates, ", ")
+			}
+		}
+		if rewritten {
+			out.WriteString(tok.String())
+		} else {
+			out.Write(raw)
+		}
+	}
+	return out.Bytes()
+}
+
+// newDevHandler returns the dev server's handler: /search?q= requests go
+// to search, and everything else, including /search without a query (which
+// may be the site's own search page), to site.
+func newDevHandler(site http.Handler, search *searchHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" && r.URL.Query().Has("q") {
+			search.ServeHTTP(w, r)
+			return
+		}
+		site.ServeHTTP(w, r)
+	})
+}
+
+// maxSearchResults is how many results the dev server's /search endpoint
+// returns unless the request sets limit.
+const maxSearchResults = 20
+
+// searchHandler answers /search?q= queries in the dev server from an
+// in-memory index of the site's posts and section entries, so search UIs
+// can be prototyped before the site has a search index (see SearchConfig).
+// The index is replaced with load after each build.
+type searchHandler struct {
+	mu      sync.RWMutex
+	entries []searchEntry
+}
+
+// searchResult is a page matching a /search query.
+type searchResult struct {
+	Title   string    `json:"title"`
+	URL     string    `json:"url"`
+	Date    time.Time `json:"date"`
+	Section string    `json:"section,omitempty"`
+	Tags    []string  `json:"tags"`
+	Summary string    `json:"summary"`
+	Score   int       `json:"score"`
+}
+
+// load replaces the index with the published posts and section entries of
+// the site in fsys configured at configPath, as Serve builds them.
+func (h *searchHandler) load(fsys FS, configPath string) error {
+	config, err := loadConfigEnv(fsys, configPath, EnvDevelopment)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	p := newParser(config)
+	posts, err := loadPosts(fsys, p, config, false, false, false)
+	if err != nil {
+		return err
+	}
+	sections, err := loadSections(fsys, p, config, false, false, false)
+	if err != nil {
+		return err
+	}
+	entries := searchEntries(posts, sections, 0)
+
+	h.mu.Lock()
+	h.entries = entries
+	h.mu.Unlock()
+	return nil
+}
+
+// ServeHTTP responds with the pages matching the q parameter as JSON:
+// {"query": ..., "total": ..., "results": [...]}, best matches first. Every
+// word of the query has to appear in a page's title, tags, or text, and
+// pages score higher for matches in their title and tags. limit sets how
+// many results are returned (default: 20).
+func (h *searchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	limit := maxSearchResults
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	h.mu.RLock()
+	results := searchPages(h.entries, query)
+	h.mu.RUnlock()
+
+	response := struct {
+		Query   string         `json:"query"`
+		Total   int            `json:"total"`
+		Results []searchResult `json:"results"`
+	}{Query: query, Total: len(results), Results: results[:min(limit, len(results))]}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// searchPages returns the entries matching every word of query, scored by
+// where the words appear: 10 for each title match, 5 for each tag match,
+// and 1 for each occurrence in the text (at most 5 per word). Results are
+// sorted by score, then newest first.
+func searchPages(entries []searchEntry, query string) []searchResult {
+	words := strings.Fields(strings.ToLower(query))
+	results := []searchResult{}
+	if len(words) == 0 {
+		return results
+	}
+	for _, e := range entries {
+		title := strings.ToLower(e.Title)
+		tags := strings.ToLower(strings.Join(e.Tags, " "))
+		text := strings.ToLower(e.Summary + " " + e.Content)
+		score := 0
+		for _, word := range words {
+			s := 0
+			if strings.Contains(title, word) {
+				s += 10
+			}
+			if strings.Contains(tags, word) {
+				s += 5
+			}
+			s += min(strings.Count(text, word), 5)
+			if s == 0 {
+				score = 0
+				break
+			}
+			score += s
+		}
+		if score == 0 {
+			continue
+		}
+		results = append(results, searchResult{
+			Title: e.Title, URL: e.URL, Date: e.Date, Section: e.Section,
+			Tags: e.Tags, Summary: e.Summary, Score: score,
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Date.After(results[j].Date)
+	})
+	return results
+}
@@ -0,0 +1,169 @@
+package ssg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestSiteHandler tests pretty URLs, 404 pages, and directory listings
+func TestSiteHandler(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"index.html":       "home",
+		"about.html":       "about",
+		"blog/index.html":  "blog",
+		"assets/style.css": "body {}",
+		"404.html":         "not here",
+	})
+
+	tests := []struct {
+		name       string
+		listings   bool
+		path       string
+		wantStatus int
+		wantBody   string
+		wantLoc    string
+	}{
+		{name: "root", path: "/", wantStatus: 200, wantBody: "home"},
+		{name: "directory index", path: "/blog/", wantStatus: 200, wantBody: "blog"},
+		{name: "directory redirect", path: "/blog", wantStatus: 301, wantLoc: "/blog/"},
+		{name: "extensionless page", path: "/about", wantStatus: 200, wantBody: "about"},
+		{name: "exact file", path: "/about.html", wantStatus: 200, wantBody: "about"},
+		{name: "missing page", path: "/nope", wantStatus: 404, wantBody: "not here"},
+		{name: "traversal", path: "/../../etc/passwd", wantStatus: 404, wantBody: "not here"},
+		{name: "listing disabled", path: "/assets/", wantStatus: 404, wantBody: "not here"},
+		{name: "listing enabled", listings: true, path: "/assets/", wantStatus: 200, wantBody: "style.css"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newSiteHandler(root, tt.listings, "").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.wantBody)
+			}
+			if loc := rec.Header().Get("Location"); loc != tt.wantLoc {
+				t.Errorf("Location = %q, want %q", loc, tt.wantLoc)
+			}
+		})
+	}
+}
+
+// TestSiteHandler_No404Page tests the fallback when the site has no 404.html
+func TestSiteHandler_No404Page(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newSiteHandler(t.TempDir(), false, "").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
+
+// TestSiteHandler_LocalURLs tests rewriting links to baseUrl in served pages
+func TestSiteHandler_LocalURLs(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"index.html": `<a href="https://example.com/blog/posts/hello.html#top">Hello</a>` +
+			`<a href="http://example.com/blog/">Home</a>` +
+			`<img src="//example.com/blog/img/a.png" srcset="https://example.com/blog/img/a-2x.png 2x, /img/a.png 1x">` +
+			`<a href="https://example.com/other/">Outside</a><a href="https://elsewhere.com/blog/x">Elsewhere</a>` +
+			`<p>https://example.com/blog/ in text</p>`,
+		"style.css": "body { background: url(https://example.com/blog/bg.png) }",
+		"404.html":  `<a href="https://example.com/blog/">Home</a>`,
+	})
+
+	get := func(h http.Handler, path string) string {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Body.String()
+	}
+
+	h := newSiteHandler(root, false, "https://example.com/blog")
+	want := `<a href="/posts/hello.html#top">Hello</a>` +
+		`<a href="/">Home</a>` +
+		`<img src="/img/a.png" srcset="/img/a-2x.png 2x, /img/a.png 1x">` +
+		`<a href="https://example.com/other/">Outside</a><a href="https://elsewhere.com/blog/x">Elsewhere</a>` +
+		`<p>https://example.com/blog/ in text</p>`
+	if got := get(h, "/"); got != want {
+		t.Errorf("index =\n%s\nwant\n%s", got, want)
+	}
+	if got := get(h, "/style.css"); !strings.Contains(got, "https://example.com/blog/bg.png") {
+		t.Errorf("style.css = %q, want non-HTML files unchanged", got)
+	}
+	if got := get(h, "/missing"); got != `<a href="/">Home</a>` {
+		t.Errorf("404 page = %q, want its links rewritten", got)
+	}
+	if got := get(newSiteHandler(root, false, ""), "/"); !strings.Contains(got, "https://example.com/blog/posts/hello.html") {
+		t.Errorf("index without a base = %q, want it unchanged", got)
+	}
+}
+
+// TestSearchHandler tests the dev server's /search endpoint
+func TestSearchHandler(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["content/posts/2024-02-01-go.md"] = "---\ntitle: Learning Go\ndate: 2024-02-01T00:00:00Z\ntags: [go]\n---\n\nGo has goroutines. The first thing to learn.\n"
+	site["content/posts/2024-03-01-draft.md"] = "---\ntitle: Go Draft\ndate: 2024-03-01T00:00:00Z\ndraft: true\n---\n\nUnfinished.\n"
+	site["content/notes/vim.md"] = "---\ntitle: Vim\ndate: 2024-01-01T00:00:00Z\n---\n\nVim has a first-class Go plugin.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	search := &searchHandler{}
+	if err := search.load(DirFS("."), "config.yaml"); err != nil {
+		t.Fatalf("load() failed: %v", err)
+	}
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"search.html": "search page"})
+	h := newDevHandler(newSiteHandler(root, false, ""), search)
+
+	query := func(path string) (titles []string, total int) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, rec.Code)
+		}
+		var resp struct {
+			Total   int
+			Results []searchResult
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		for _, r := range resp.Results {
+			titles = append(titles, r.Title)
+		}
+		return titles, resp.Total
+	}
+
+	// Title and tag matches rank above text matches, and drafts aren't indexed
+	if got, total := query("/search?q=go"); strings.Join(got, ",") != "Learning Go,Vim" || total != 2 {
+		t.Errorf("q=go = %v (%d), want [Learning Go Vim]", got, total)
+	}
+	if got, _ := query("/search?q=FIRST+go"); strings.Join(got, ",") != "Learning Go,Vim" {
+		t.Errorf("q=FIRST go = %v, want pages with both words", got)
+	}
+	if got, total := query("/search?q=first&limit=1"); len(got) != 1 || total != 3 {
+		t.Errorf("q=first&limit=1 = %v (%d), want 1 of 3 results", got, total)
+	}
+	if got, _ := query("/search?q="); len(got) != 0 {
+		t.Errorf("empty query = %v, want no results", got)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search", nil))
+	if rec.Body.String() != "search page" {
+		t.Errorf("/search without a query = %q, want the site's page", rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=go&limit=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("limit=0 status = %d, want 400", rec.Code)
+	}
+}
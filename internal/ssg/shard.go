@@ -0,0 +1,167 @@
+package ssg
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shard is the part of the site a sharded build writes: shard index of count,
+// counting from 1. The zero value (and 1/1) is the whole site. A shard with
+// pages set writes only those pages, and none of the site-wide files, for
+// rendering single pages on request (see liveSite).
+type shard struct {
+	index, count int
+	pages        map[string]bool // URLs of the pages to write, if set
+}
+
+// parseShard parses a shard written "i/n", like "2/4". An empty string is the
+// whole site.
+func parseShard(s string) (shard, error) {
+	if s == "" {
+		return shard{}, nil
+	}
+	is, ns, ok := strings.Cut(s, "/")
+	i, err1 := strconv.Atoi(is)
+	n, err2 := strconv.Atoi(ns)
+	if !ok || err1 != nil || err2 != nil || n < 1 || i < 1 || i > n {
+		return shard{}, fmt.Errorf("shard %q must be i/n with 1 <= i <= n (e.g., 2/4)", s)
+	}
+	return shard{index: i, count: n}, nil
+}
+
+// String returns the shard as "i/n".
+func (sh shard) String() string {
+	return fmt.Sprintf("%d/%d", sh.index, sh.count)
+}
+
+// owns reports whether the page at url belongs to the shard. Pages are
+// assigned by a hash of their URL, so every job agrees on the split without
+// coordinating, and a page stays in the same shard as others are added.
+func (sh shard) owns(url string) bool {
+	if sh.pages != nil {
+		return sh.pages[url]
+	}
+	if sh.count <= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(url))
+	return int(h.Sum32()%uint32(sh.count)) == sh.index-1 // #nosec G115 -- count is a small positive int
+}
+
+// first reports whether the shard writes the site-wide files: static files,
+// the JSON API, and redirects.
+func (sh shard) first() bool {
+	return sh.pages == nil && (sh.count <= 1 || sh.index == 1)
+}
+
+// Merge combines the output directories of sharded builds (see
+// BuildOptions.Shard) into outputDir, then checks the merged site's links
+// and saves its manifest, like the end of an unsharded Build.
+//
+// Shards may write the same file (bundles and image variants are generated
+// by every shard) as long as its contents match; a path with different
+// contents in two shards is an error, since it means the shards were built
+// from different sources.
+//
+// Parameters:
+//   - configPath: Path to the config file, for the preserve patterns of the
+//     $SSG_ENV environment (see SiteConfig.Preserve)
+//   - outputDir: Directory to write the merged site to; it's emptied first,
+//     except for preserved paths
+//   - shardDirs: Output directories of the shard builds
+//   - strict: Fail if the merged site has broken internal links
+//
+// Returns an error if a shard can't be read, shards conflict, or strict is
+// set and broken links were found.
+func Merge(configPath, outputDir string, shardDirs []string, strict bool) error {
+	if len(shardDirs) == 0 {
+		return fmt.Errorf("no shard directories given")
+	}
+	preserve := preservePatterns(DirFS("."), configPath, BuildOptions{}.withDefaults().Environment)
+	if err := checkPreserve(preserve); err != nil {
+		return err
+	}
+
+	files := make(map[string]string)  // path → shard dir it's copied from
+	merged := make(map[string]string) // path → digest
+	for _, dir := range shardDirs {
+		if rel, err := filepath.Rel(outputDir, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("shard %s is inside the output directory %s", dir, outputDir)
+		}
+		m, err := buildManifest(DirFS("."), dir, preserve)
+		if err != nil {
+			return fmt.Errorf("reading shard %s: %w", dir, err)
+		}
+		for p, sum := range m.Files {
+			if prev, ok := merged[p]; ok && prev != sum {
+				return fmt.Errorf("%s differs between shards %s and %s", p, files[p], dir)
+			}
+			merged[p] = sum
+			files[p] = dir
+		}
+	}
+
+	if err := cleanOutput(DirFS("."), outputDir, preserve); err != nil {
+		return fmt.Errorf("cleaning output directory: %w", err)
+	}
+	for p, dir := range files {
+		src := filepath.Join(dir, filepath.FromSlash(p))
+		dst := filepath.Join(outputDir, filepath.FromSlash(p))
+		info, err := os.Stat(src)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(src) // #nosec G304 -- path found by walking a shard directory
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, data, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("writing %s: %w", dst, err)
+		}
+	}
+
+	// Directories keep the modes the shards gave them (see PermissionsConfig)
+	for _, dir := range shardDirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || !info.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			if preserved(filepath.ToSlash(rel), preserve) {
+				return filepath.SkipDir
+			}
+			dst := filepath.Join(outputDir, rel)
+			if err := os.MkdirAll(dst, 0750); err != nil {
+				return err
+			}
+			return os.Chmod(dst, info.Mode().Perm())
+		})
+		if err != nil {
+			return fmt.Errorf("setting directory modes: %w", err)
+		}
+	}
+
+	if err := reportBrokenLinks(DirFS("."), outputDir, strict); err != nil {
+		return err
+	}
+
+	m := &Manifest{Generated: time.Now().UTC(), OutputDir: outputDir, Files: merged}
+	if err := m.write(DirFS("."), manifestPath); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	fmt.Printf("Merged %d shards (%d files) into %s\n", len(shardDirs), len(merged), outputDir)
+	return nil
+}
@@ -0,0 +1,118 @@
+package ssg
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParseShard tests parsing i/n shard specs
+func TestParseShard(t *testing.T) {
+	if sh, err := parseShard("2/4"); err != nil || sh.index != 2 || sh.count != 4 || sh.pages != nil {
+		t.Errorf("parseShard(2/4) = %v, %v", sh, err)
+	}
+	if sh, err := parseShard(""); err != nil || !sh.owns("/anything") || !sh.first() {
+		t.Errorf("parseShard(\"\") = %v, %v; want the whole site", sh, err)
+	}
+	for _, bad := range []string{"2", "0/4", "5/4", "a/b", "1/0", "-1/2"} {
+		if _, err := parseShard(bad); err == nil {
+			t.Errorf("parseShard(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+// TestShardOwns tests that every page belongs to exactly one shard
+func TestShardOwns(t *testing.T) {
+	counts := make([]int, 4)
+	for i := range 200 {
+		url := fmt.Sprintf("/posts/post-%d.html", i)
+		owners := 0
+		for j := range counts {
+			if (shard{index: j + 1, count: len(counts)}).owns(url) {
+				owners++
+				counts[j]++
+			}
+		}
+		if owners != 1 {
+			t.Fatalf("%s owned by %d shards, want 1", url, owners)
+		}
+	}
+	for j, n := range counts {
+		if n == 0 {
+			t.Errorf("shard %d/%d got no pages", j+1, len(counts))
+		}
+	}
+}
+
+// TestMerge tests that merging sharded builds reproduces an unsharded build
+func TestMerge(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["templates/posts.html"] = "{{define \"posts\"}}{{range .Posts}}<a href=\"{{.URL}}\">{{.Title}}</a>\n{{end}}{{end}}"
+	site["static/css/style.css"] = "body {}"
+	site["config.yaml"] += "preserve: [.git, CNAME]\n"
+	site["content/notes/vim.md"] = "---\ntitle: Vim\ndate: 2024-02-01T00:00:00Z\n---\n\nUse it.\n"
+	for i := range 12 {
+		site[fmt.Sprintf("content/posts/post-%d.md", i)] = fmt.Sprintf("---\ntitle: Post %d\ndate: 2024-03-%02dT00:00:00Z\n---\n\nSee [the first](/posts/first.html).\n", i, i+1)
+	}
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{OutputDir: "full"}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	full, err := buildManifest(DirFS("."), "full", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var shardDirs []string
+	for i := 1; i <= 3; i++ {
+		dir := fmt.Sprintf("shard-%d", i)
+		if err := Build(context.Background(), BuildOptions{OutputDir: dir, Shard: fmt.Sprintf("%d/3", i)}); err != nil {
+			t.Fatalf("Build() shard %d failed: %v", i, err)
+		}
+		shardDirs = append(shardDirs, dir)
+	}
+	if _, err := os.Stat(filepath.Join("shard-2", "css", "style.css")); !os.IsNotExist(err) {
+		t.Error("static files copied to shard 2, want them only in the first shard")
+	}
+
+	writeFiles(t, tmpDir, map[string]string{
+		"public/.git/HEAD": "ref: refs/heads/gh-pages\n",
+		"public/CNAME":     "blog.example.com\n",
+		"public/old.html":  "stale",
+	})
+	if err := Merge("config.yaml", "public", shardDirs, true); err != nil {
+		t.Fatalf("Merge() failed: %v", err)
+	}
+	for _, name := range []string{".git/HEAD", "CNAME"} {
+		if _, err := os.Stat(filepath.Join("public", name)); err != nil {
+			t.Errorf("preserved %s removed by Merge(): %v", name, err)
+		}
+	}
+	merged, err := buildManifest(DirFS("."), "public", []string{".git", "CNAME"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(merged.Files, full.Files) {
+		t.Errorf("merged files = %v\nwant %v", merged.Paths(), full.Paths())
+	}
+	saved, err := readManifest(manifestPath)
+	if err != nil || saved.OutputDir != "public" || !maps.Equal(saved.Files, full.Files) {
+		t.Errorf("saved manifest = %+v, %v; want the merged site", saved, err)
+	}
+
+	// Shards built from different sources conflict
+	writeFiles(t, tmpDir, map[string]string{"shard-3/css/style.css": "body { color: red }"})
+	if err := Merge("config.yaml", "public", shardDirs, false); err == nil || !strings.Contains(err.Error(), "differs") {
+		t.Errorf("Merge() error = %v, want a conflict", err)
+	}
+	if err := Merge("config.yaml", "public", []string{"public/x"}, false); err == nil {
+		t.Error("Merge() of a shard inside the output directory succeeded, want error")
+	}
+}
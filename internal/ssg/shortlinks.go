@@ -0,0 +1,154 @@
+package ssg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// shortlinksPath is the data file listing short links, relative to the site
+// root.
+var shortlinksPath = filepath.Join("data", "shortlinks.yaml")
+
+// defaultShortlinkPrefix is the site path short link pages are written
+// under unless shortlinks.prefix is set.
+const defaultShortlinkPrefix = "/s/"
+
+// shortlinkCodePattern matches valid short link codes.
+var shortlinkCodePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// shortlinkTemplate is the page written for a short link when the site has
+// no shortlink.html template. Like a redirect page, it refreshes to the
+// destination, after shortlinks.delay seconds.
+var shortlinkTemplate = template.Must(template.New("shortlink").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Redirecting to {{ .URL }}</title>
+<meta name="robots" content="noindex" />
+<meta http-equiv="refresh" content="{{ .Delay }}; url={{ .URL }}" />
+</head>
+<body>
+<p>Redirecting to <a href="{{ .URL }}">{{ .URL }}</a>.</p>
+</body>
+</html>
+`))
+
+// ShortlinksConfig configures the short link pages generated from
+// data/shortlinks.yaml, which maps codes to destinations:
+//
+//	gh: https://github.com/you
+//	talk: /posts/my-conference-talk.html
+//
+// Each code gets a page at /s/<code>/ that redirects to its destination.
+//
+// Example config.yaml:
+//
+//	shortlinks:
+//	  prefix: /go/
+//	  delay: 1
+type ShortlinksConfig struct {
+	Prefix string `yaml:"prefix"` // Site path short links are under (default: /s/)
+	Delay  int    `yaml:"delay"`  // Seconds before redirecting, so analytics on the page can record the visit (default: 0)
+}
+
+// Shortlink is a short link page, exposed to the shortlink.html template as
+// .Shortlink.
+type Shortlink struct {
+	Code  string // Code from data/shortlinks.yaml (e.g., "gh")
+	URL   string // Destination
+	Path  string // Site path of the page (e.g., "/s/gh/")
+	Delay int    // Seconds before redirecting
+}
+
+// loadShortlinks reads the short links in data/shortlinks.yaml in fsys,
+// sorted by code. Returns nil if the file doesn't exist, or an error if it can't be
+// parsed, the prefix is invalid, or a code or destination is.
+func loadShortlinks(fsys fs.FS, cfg ShortlinksConfig) ([]*Shortlink, error) {
+	data, err := fs.ReadFile(fsys, shortlinksPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var codes map[string]string
+	if err := yaml.Unmarshal(data, &codes); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", shortlinksPath, err)
+	}
+
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = defaultShortlinkPrefix
+	}
+	if !strings.HasPrefix(prefix, "/") || !strings.HasSuffix(prefix, "/") || prefix == "/" || path.Clean(prefix)+"/" != prefix {
+		return nil, fmt.Errorf("shortlinks.prefix %q must be a clean site path ending in /, like /s/", cfg.Prefix)
+	}
+	if cfg.Delay < 0 {
+		return nil, fmt.Errorf("shortlinks.delay can't be negative")
+	}
+
+	links := make([]*Shortlink, 0, len(codes))
+	for code, dest := range codes {
+		if !shortlinkCodePattern.MatchString(code) {
+			return nil, fmt.Errorf("%s: code %q may only contain letters, digits, - and _", shortlinksPath, code)
+		}
+		u, err := url.Parse(dest)
+		if err != nil || !(u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "" && strings.HasPrefix(dest, "/")) {
+			return nil, fmt.Errorf("%s: %s must link to an http(s) URL or a site path, not %q", shortlinksPath, code, dest)
+		}
+		links = append(links, &Shortlink{Code: code, URL: dest, Path: prefix + code + "/", Delay: cfg.Delay})
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Code < links[j].Code })
+	return links, nil
+}
+
+// renderShortlink writes the page of a short link. If the site has a
+// shortlink.html template, it's rendered inside base.html like any page,
+// with the link as .Shortlink, so the page can carry the site's analytics;
+// .Head includes the refresh to the destination. Otherwise a bare redirect
+// page (see shortlinkTemplate) is written. Either way, the page goes through
+// the transformer pipeline, so scripts added by the consent banner run on it.
+//
+// Returns an error if the page would overwrite a generated page, or if
+// rendering or writing fails.
+func (r *Renderer) renderShortlink(link *Shortlink, config SiteConfig, outputPath string) error {
+	if _, err := r.out.Stat(outputPath); err == nil {
+		return fmt.Errorf("short link %s would overwrite a generated page", link.Path)
+	}
+	data := PageData{
+		Site:      config,
+		Shortlink: link,
+		Title:     link.Code,
+		Kind:      KindShortlink,
+		URL:       link.Path,
+	}
+	if _, err := fs.Stat(r.fs, "shortlink.html"); err == nil {
+		return r.renderToFile("shortlink.html", data, outputPath)
+	}
+
+	var buf bytes.Buffer
+	if err := shortlinkTemplate.Execute(&buf, link); err != nil {
+		return err
+	}
+	out, err := r.finishPage(buf.Bytes(), &data, outputPath)
+	if err != nil {
+		return err
+	}
+	if err := r.out.WriteFile(outputPath, out, 0600); err != nil {
+		return fmt.Errorf("writing short link %s: %w", link.Code, err)
+	}
+	slog.Debug("Wrote file", "file", outputPath)
+	return nil
+}
@@ -0,0 +1,85 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadShortlinks tests reading and validating data/shortlinks.yaml
+func TestLoadShortlinks(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if links, err := loadShortlinks(DirFS("."), ShortlinksConfig{}); links != nil || err != nil {
+		t.Errorf("loadShortlinks() without a file = %v, %v; want none", links, err)
+	}
+
+	writeFiles(t, ".", map[string]string{"data/shortlinks.yaml": "talk: /posts/talk.html\ngh: https://github.com/you\n"})
+	links, err := loadShortlinks(DirFS("."), ShortlinksConfig{Prefix: "/go/", Delay: 2})
+	if err != nil {
+		t.Fatalf("loadShortlinks() failed: %v", err)
+	}
+	if len(links) != 2 || *links[0] != (Shortlink{Code: "gh", URL: "https://github.com/you", Path: "/go/gh/", Delay: 2}) || links[1].Path != "/go/talk/" {
+		t.Errorf("loadShortlinks() = %+v, want gh and talk under /go/", links)
+	}
+
+	for _, bad := range []ShortlinksConfig{{Prefix: "/s"}, {Prefix: "/"}, {Prefix: "/a/../s/"}, {Delay: -1}} {
+		if _, err := loadShortlinks(DirFS("."), bad); err == nil {
+			t.Errorf("loadShortlinks(%+v) succeeded, want error", bad)
+		}
+	}
+	for _, bad := range []string{"a/b: https://example.com\n", "gh: javascript:alert(1)\n", "gh: relative/path\n", "- not a map\n"} {
+		writeFiles(t, ".", map[string]string{"data/shortlinks.yaml": bad})
+		if _, err := loadShortlinks(DirFS("."), ShortlinksConfig{}); err == nil {
+			t.Errorf("loadShortlinks() of %q succeeded, want error", bad)
+		}
+	}
+}
+
+// TestBuild_Shortlinks tests writing short link pages, bare and from a template
+func TestBuild_Shortlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["data/shortlinks.yaml"] = "gh: https://github.com/you\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	read := func() string {
+		t.Helper()
+		got, err := os.ReadFile(filepath.Join("public", "s", "gh", "index.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(got)
+	}
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if got := read(); !strings.Contains(got, `content="0; url=https://github.com/you"`) || !strings.Contains(got, "noindex") {
+		t.Errorf("short link page = %s, want a refresh to the destination", got)
+	}
+
+	site["config.yaml"] += "shortlinks:\n  delay: 1\n"
+	site["templates/base.html"] = "<html><head>{{ .Head }}<script src=\"/analytics.js\"></script></head><body>{{ template \"posts\" . }}</body></html>"
+	site["templates/shortlink.html"] = "{{ define \"posts\" }}Off to {{ .Shortlink.URL }}{{ end }}"
+	writeFiles(t, tmpDir, site)
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() with shortlink.html failed: %v", err)
+	}
+	got := read()
+	for _, want := range []string{`content="1; url=https://github.com/you"`, `<meta name="robots" content="noindex" />`, "/analytics.js", "Off to https://github.com/you"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("short link page = %s, want it to contain %s", got, want)
+		}
+	}
+
+	site["data/shortlinks.yaml"] = "posts: /\n"
+	site["config.yaml"] = "title: Test Blog\nshortlinks:\n  prefix: /\n"
+	writeFiles(t, tmpDir, site)
+	if err := Build(context.Background(), BuildOptions{}); err == nil {
+		t.Error("Build() with an invalid prefix succeeded, want error")
+	}
+}
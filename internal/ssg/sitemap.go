@@ -0,0 +1,110 @@
+package ssg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SitemapConfig configures sitemap.xml, which lists the site's pages for
+// search engines.
+//
+// Example config.yaml:
+//
+//	sitemap:
+//	  enabled: true
+type SitemapConfig struct {
+	Enabled bool `yaml:"enabled"` // Write /sitemap.xml (needs baseUrl)
+}
+
+// sitemapURLSet is the root element of sitemap.xml.
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL is one page in sitemap.xml.
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"` // Lastmod of the post, for posts and entries
+}
+
+// sitemapPlugin writes sitemap.xml (see SitemapConfig).
+type sitemapPlugin struct{ BasePlugin }
+
+func (sitemapPlugin) Name() string { return "sitemap" }
+
+func (sitemapPlugin) AfterBuild(b *BuildContext) error {
+	if !b.Config.Sitemap.Enabled || !b.First() {
+		return nil
+	}
+	if b.Config.BaseURL == "" {
+		return fmt.Errorf("sitemap needs baseUrl set, since its URLs must be absolute")
+	}
+	return writeSitemap(b.Output, sitemapURLs(b), filepath.Join(b.OutputDir, "sitemap.xml"))
+}
+
+// sitemapURLs returns the pages of the site, with absolute URLs: each
+// language's home page, the posts, sections and their entries, series,
+// taxonomies and terms, mounted pages, and Go package pages. Pages are
+// listed whichever shard writes them, so one sitemap covers a sharded site.
+func sitemapURLs(b *BuildContext) []sitemapURL {
+	base := strings.TrimSuffix(b.Config.BaseURL, "/")
+	var urls []sitemapURL
+	add := func(url string, date time.Time) {
+		u := sitemapURL{Loc: base + url}
+		if !date.IsZero() {
+			u.LastMod = date.Format("2006-01-02")
+		}
+		urls = append(urls, u)
+	}
+
+	for _, lang := range indexLanguages(*b.Config) {
+		add(b.Config.languageURL(lang, "/"), time.Time{})
+	}
+	for _, post := range b.Posts {
+		add(post.URL, lastmod(post))
+	}
+	for _, section := range b.Sections {
+		add(section.URL, time.Time{})
+		for _, post := range section.Posts {
+			add(post.URL, lastmod(post))
+		}
+	}
+	for _, s := range b.Series {
+		add(s.URL, time.Time{})
+	}
+	for _, tax := range b.Taxonomies {
+		add(tax.URL, time.Time{})
+		if tax.Name == "series" {
+			continue
+		}
+		for _, term := range tax.Terms {
+			add(term.URL, time.Time{})
+		}
+	}
+	for _, page := range b.Pages {
+		add(page.URL, time.Time{})
+	}
+	for _, pkg := range b.Packages {
+		add(pageURL(b.Config.URLs, "/pkg/"+pkg.Slug), time.Time{})
+	}
+	return urls
+}
+
+// writeSitemap writes urls to path in fsys as a sitemap (sitemaps.org
+// protocol).
+func writeSitemap(fsys FS, urls []sitemapURL, path string) error {
+	data, err := xml.MarshalIndent(sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9", URLs: urls}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding sitemap: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	if err := fsys.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("writing sitemap: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_Sitemap tests that sitemap.xml lists the site's pages
+func TestBuild_Sitemap(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "sitemap:\n  enabled: true\ntaxonomies: [tags]\n"
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\ndate: 2024-01-15\ntags: [go]\n---\n\nHello.\n"
+	site["content/notes/2024-02-01-a-note.md"] = "---\ntitle: A Note\n---\n\nNote.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join("public", "sitemap.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sitemap := string(data)
+	for _, want := range []string{
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`,
+		"<loc>https://test.com/</loc>",
+		"<loc>https://test.com/posts/first.html</loc>\n    <lastmod>2024-01-15</lastmod>",
+		"<loc>https://test.com/notes/</loc>",
+		"<loc>https://test.com/notes/a-note.html</loc>",
+		"<loc>https://test.com/tags/go.html</loc>",
+	} {
+		if !strings.Contains(sitemap, want) {
+			t.Errorf("sitemap.xml doesn't contain %q:\n%s", want, sitemap)
+		}
+	}
+}
+
+// TestBuild_SitemapNeedsBaseURL tests that a sitemap without baseUrl fails the build
+func TestBuild_SitemapNeedsBaseURL(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] = "title: Test Blog\nsitemap:\n  enabled: true\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err == nil || !strings.Contains(err.Error(), "baseUrl") {
+		t.Errorf("Build() error = %v, want error about baseUrl", err)
+	}
+}
@@ -0,0 +1,181 @@
+package ssg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// SnapshotConfig declares a third-party asset to download at build time and
+// serve from the site instead.
+//
+// Example config.yaml:
+//
+//	snapshot:
+//	  - url: https://fonts.googleapis.com/css2?family=Inter
+//	    path: vendor/inter.css
+//	  - url: https://cdn.example.com/widget.js
+type SnapshotConfig struct {
+	URL  string `yaml:"url"`  // Absolute URL referenced by the site's pages
+	Path string `yaml:"path"` // Output path relative to the output directory (default: vendor/<hash><ext>)
+}
+
+// maxSnapshotSize limits the size of a single downloaded asset.
+const maxSnapshotSize = 50 << 20
+
+// cssURLPattern matches absolute url(...) references in a stylesheet.
+var cssURLPattern = regexp.MustCompile(`url\(\s*['"]?(https?://[^'")\s]+)['"]?\s*\)`)
+
+// snapshotClient downloads snapshot assets.
+var snapshotClient = &http.Client{Timeout: 30 * time.Second}
+
+// snapshotResources downloads each configured asset into the output
+// directory, so published pages don't depend on third-party hosts.
+//
+// Stylesheets are scanned for absolute url(...) references (such as the font
+// files a web font stylesheet loads), which are downloaded too and rewritten
+// to their local copies.
+//
+// Parameters:
+//   - resources: Assets from config.yaml
+//   - out: Filesystem the site is written to
+//   - outputDir: Output directory (e.g., "public")
+//
+// Returns a map of original URL → local URL (e.g., "/vendor/inter.css") for
+// rewriting references, or an error if a download fails.
+func snapshotResources(resources []SnapshotConfig, out FS, outputDir string) (map[string]string, error) {
+	local := make(map[string]string)
+	for _, res := range resources {
+		data, contentType, err := fetchSnapshot(res.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		name := res.Path
+		if name == "" {
+			name = snapshotName(res.URL, contentType)
+		}
+
+		if strings.HasPrefix(contentType, "text/css") || path.Ext(name) == ".css" {
+			data, err = snapshotCSSRefs(data, out, outputDir, local)
+			if err != nil {
+				return nil, fmt.Errorf("snapshotting assets of %s: %w", res.URL, err)
+			}
+		}
+
+		if err := writeSnapshot(out, outputDir, name, data); err != nil {
+			return nil, err
+		}
+		local[res.URL] = "/" + strings.TrimPrefix(filepath.ToSlash(name), "/")
+	}
+	return local, nil
+}
+
+// snapshotCSSRefs downloads the absolute url(...) references in a stylesheet
+// and returns the stylesheet rewritten to use the local copies. Downloaded
+// URLs are recorded in local.
+func snapshotCSSRefs(css []byte, fsys FS, outputDir string, local map[string]string) ([]byte, error) {
+	var firstErr error
+	out := cssURLPattern.ReplaceAllFunc(css, func(match []byte) []byte {
+		ref := string(cssURLPattern.FindSubmatch(match)[1])
+		if localURL, ok := local[ref]; ok {
+			return []byte("url(" + localURL + ")")
+		}
+
+		data, contentType, err := fetchSnapshot(ref)
+		if err == nil {
+			name := snapshotName(ref, contentType)
+			if err = writeSnapshot(fsys, outputDir, name, data); err == nil {
+				local[ref] = "/" + name
+				return []byte("url(/" + name + ")")
+			}
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		return match
+	})
+	return out, firstErr
+}
+
+// fetchSnapshot downloads a URL, returning its body and content type.
+func fetchSnapshot(rawURL string) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, "", fmt.Errorf("snapshot URL %q must be an absolute http(s) URL", rawURL)
+	}
+
+	resp, err := snapshotClient.Get(rawURL) // #nosec G107 -- URL comes from the site's own config
+	if err != nil {
+		return nil, "", fmt.Errorf("downloading %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("downloading %s: %s", rawURL, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSnapshotSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("downloading %s: %w", rawURL, err)
+	}
+	if len(data) > maxSnapshotSize {
+		return nil, "", fmt.Errorf("downloading %s: larger than %d MB", rawURL, maxSnapshotSize>>20)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// snapshotName returns the default output path for a downloaded URL: a short
+// hash of the URL under vendor/, with the URL's extension or one matching the
+// content type.
+func snapshotName(rawURL, contentType string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	ext := ""
+	if u, err := url.Parse(rawURL); err == nil {
+		ext = path.Ext(u.Path)
+	}
+	if ext == "" {
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+				ext = exts[0]
+			}
+		}
+	}
+	return "vendor/" + hex.EncodeToString(sum[:8]) + ext
+}
+
+// writeSnapshot writes a downloaded asset to name inside outputDir in out.
+func writeSnapshot(out FS, outputDir, name string, data []byte) error {
+	return out.WriteFile(filepath.Join(outputDir, filepath.FromSlash(name)), data, 0600)
+}
+
+// snapshotTransformer returns an HTMLTransformer that points href and src
+// attributes referencing snapshotted URLs at their local copies.
+func snapshotTransformer(local map[string]string) HTMLTransformer {
+	return func(doc *html.Node, _ *RenderedPage) error {
+		walkHTML(doc, func(n *html.Node) {
+			if n.Type != html.ElementNode {
+				return
+			}
+			for i, a := range n.Attr {
+				if a.Key != "href" && a.Key != "src" {
+					continue
+				}
+				if localURL, ok := local[a.Val]; ok {
+					n.Attr[i].Val = localURL
+				}
+			}
+		})
+		return nil
+	}
+}
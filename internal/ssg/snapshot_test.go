@@ -0,0 +1,99 @@
+package ssg
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newAssetServer serves a stylesheet referencing a font, and a script.
+func newAssetServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/css":
+			w.Header().Set("Content-Type", "text/css; charset=utf-8")
+			_, _ = w.Write([]byte(`@font-face { src: url(` + srv.URL + `/font.woff2) format("woff2"); }`))
+		case "/font.woff2":
+			_, _ = w.Write([]byte("font data"))
+		case "/widget.js":
+			_, _ = w.Write([]byte("console.log('hi')"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestSnapshotResources tests downloading assets and the fonts their stylesheets load
+func TestSnapshotResources(t *testing.T) {
+	srv := newAssetServer(t)
+	outputDir := t.TempDir()
+
+	local, err := snapshotResources([]SnapshotConfig{
+		{URL: srv.URL + "/css?family=Inter"},
+		{URL: srv.URL + "/widget.js", Path: "js/widget.js"},
+	}, DirFS("."), outputDir)
+	if err != nil {
+		t.Fatalf("snapshotResources() failed: %v", err)
+	}
+
+	cssURL := local[srv.URL+"/css?family=Inter"]
+	if !strings.HasPrefix(cssURL, "/vendor/") || !strings.HasSuffix(cssURL, ".css") {
+		t.Errorf("stylesheet URL = %q, want /vendor/<hash>.css", cssURL)
+	}
+	if local[srv.URL+"/widget.js"] != "/js/widget.js" {
+		t.Errorf("script URL = %q, want /js/widget.js", local[srv.URL+"/widget.js"])
+	}
+
+	css, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(cssURL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fontURL := local[srv.URL+"/font.woff2"]
+	if fontURL == "" || !strings.Contains(string(css), "url("+fontURL+")") {
+		t.Errorf("stylesheet not rewritten to the local font %q:\n%s", fontURL, css)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(fontURL))); err != nil {
+		t.Errorf("font not downloaded: %v", err)
+	}
+}
+
+// TestSnapshotResources_Errors tests that failed downloads fail the build
+func TestSnapshotResources_Errors(t *testing.T) {
+	srv := newAssetServer(t)
+	for _, rawURL := range []string{srv.URL + "/missing.js", "/local/path.js"} {
+		if _, err := snapshotResources([]SnapshotConfig{{URL: rawURL}}, DirFS("."), t.TempDir()); err == nil {
+			t.Errorf("snapshotResources(%q) succeeded, want error", rawURL)
+		}
+	}
+}
+
+// TestBuild_Snapshot tests that page references are rewritten to the local copies
+func TestBuild_Snapshot(t *testing.T) {
+	srv := newAssetServer(t)
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "snapshot:\n  - url: " + srv.URL + "/widget.js\n    path: js/widget.js\n"
+	site["templates/base.html"] = `<html><head><script src="` + srv.URL + `/widget.js"></script></head><body>{{template "posts" .}}</body></html>`
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{Strict: true}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join("public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `<script src="/js/widget.js">`) || strings.Contains(string(index), srv.URL) {
+		t.Errorf("script reference not rewritten:\n%s", index)
+	}
+}
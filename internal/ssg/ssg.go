@@ -3,14 +3,22 @@
 package ssg
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/kvnloughead/ssg/internal/parser"
@@ -19,126 +27,862 @@ import (
 
 // SiteConfig represents the site configuration from config.yaml
 type SiteConfig struct {
-	Title       string `yaml:"title"`
-	Description string `yaml:"description"`
-	BaseURL     string `yaml:"baseUrl"`
-	Author      string `yaml:"author"`
-	Keywords    string `yaml:"keywords"`
+	Title         string                    `yaml:"title"`
+	Description   string                    `yaml:"description"`
+	BaseURL       string                    `yaml:"baseUrl"`
+	Author        string                    `yaml:"author"`
+	Keywords      string                    `yaml:"keywords"`
+	Language      string                    `yaml:"language"`      // Content language (BCP 47, e.g. "de") for slugs and sort order
+	Languages     map[string]LanguageConfig `yaml:"languages"`     // Languages of a multilingual site, with their own titles (see LanguageConfig)
+	Minify        bool                      `yaml:"minify"`        // Minify generated HTML and copied CSS/JS
+	Godoc         GodocConfig               `yaml:"godoc"`         // Go packages to publish reference pages for
+	Images        ImagesConfig              `yaml:"images"`        // Responsive image generation
+	Mounts        []MountConfig             `yaml:"mounts"`        // Files outside content/ to publish as pages
+	Funcs         map[string]string         `yaml:"funcs"`         // User-defined template funcs (name → template snippet)
+	Bundles       []BundleConfig            `yaml:"bundles"`       // Static CSS/JS files concatenated into bundles
+	Scripts       []ScriptConfig            `yaml:"scripts"`       // JS/TS entry points bundled with esbuild into hashed files
+	Permalink     string                    `yaml:"permalink"`     // Post URL pattern (default "/posts/:slug.html")
+	API           APIConfig                 `yaml:"api"`           // Static JSON API of posts
+	Search        SearchConfig              `yaml:"search"`        // Search index for client-side search
+	Snapshot      []SnapshotConfig          `yaml:"snapshot"`      // Third-party assets to download and serve locally
+	URLs          string                    `yaml:"urls"`          // Page URL style: "html" (default), "slash", or "extensionless"
+	Params        map[string]any            `yaml:"params"`        // Arbitrary user values, e.g. {{ .Site.Params.social.github }}
+	Permissions   PermissionsConfig         `yaml:"permissions"`   // Modes of generated files and directories
+	Static        StaticConfig              `yaml:"static"`        // Size limits for files copied from static/
+	Notify        NotifyConfig              `yaml:"notify"`        // Hooks run when a build finishes
+	Hooks         HooksConfig               `yaml:"hooks"`         // Shell commands run before and after builds and deploys
+	Redirects     map[string]string         `yaml:"redirects"`     // Old site path → new URL, written as redirect pages
+	RedirectFiles []string                  `yaml:"redirectFiles"` // Also write redirects as server rules: "netlify" or "cloudflare" (_redirects) and/or "apache" (.htaccess)
+	DeployTarget  string                    `yaml:"deployTarget"`  // Host to tailor the output for: "netlify", "vercel", or "cloudflare" (see writeHostFiles)
+	Theme         ThemeConfig               `yaml:"theme"`         // Theme from themes/ to use under templates/ and static/
+	Featured      FeaturedConfig            `yaml:"featured"`      // Posts to highlight on the home page
+	Consent       ConsentConfig             `yaml:"consent"`       // Cookie consent banner, with analytics loaded only after consent
+	Webmentions   WebmentionsConfig         `yaml:"webmentions"`   // Webmentions sent for the links in posts, and received ones fetched for them
+	Comments      CommentsConfig            `yaml:"comments"`      // Comment counts fetched from each post's comment thread
+	Deploy        map[string]DeployTarget   `yaml:"deploy"`        // Named targets for `ssg deploy`
+	Shortlinks    ShortlinksConfig          `yaml:"shortlinks"`    // Short link pages generated from data/shortlinks.yaml
+	QRCode        QRCodeConfig              `yaml:"qrcode"`        // QR code images of post URLs
+	OGImage       OGImageConfig             `yaml:"ogImage"`       // Social preview images of posts, linked as og:image
+	Markdown      MarkdownConfig            `yaml:"markdown"`      // Markdown conversion, e.g. whether raw HTML passes through
+	Formats       FormatsConfig             `yaml:"formats"`       // Commands converting content in other formats, like AsciiDoc, to HTML
+	Notebooks     *bool                     `yaml:"notebooks"`     // Publish Jupyter notebooks (.ipynb) in content directories as posts (default: true)
+	GitInfo       *bool                     `yaml:"gitInfo"`       // Set posts' Lastmod and Contributors from git history when the site is a git repo (default: true)
+	Taxonomies    []string                  `yaml:"taxonomies"`    // Frontmatter fields to group posts by, with term pages (see Taxonomy)
+	Outputs       []string                  `yaml:"outputs"`       // Formats posts are written in, e.g. [html, json, markdown] (default: [html])
+	Preserve      []string                  `yaml:"preserve"`      // Paths in the output directory kept across builds, e.g. [.git, CNAME]
+	RemoteData    RemoteDataConfig          `yaml:"remoteData"`    // Caching of the datasets getJSON and getCSV fetch
+	Sitemap       SitemapConfig             `yaml:"sitemap"`       // sitemap.xml listing the site's pages
+	Feeds         FeedsConfig               `yaml:"feeds"`         // RSS feeds of the posts, and of each term and section
+	Robots        RobotsConfig              `yaml:"robots"`        // robots.txt rules for crawlers, unless static/ has one
+	Humans        HumansConfig              `yaml:"humans"`        // humans.txt crediting the site's authors, unless static/ has one
+	Frontmatter   FrontmatterConfig         `yaml:"frontmatter"`   // Schema the frontmatter of posts is validated against
+	LinkCheck     LinkCheckConfig           `yaml:"linkCheck"`     // How `ssg check --external` checks links to other sites
+	Prose         ProseConfig               `yaml:"prose"`         // Spelling and style linting of posts by `ssg check`
+	Accessibility AccessibilityConfig       `yaml:"accessibility"` // Audit of built pages for missing alt text, empty links, and the like
+	Favicons      FaviconsConfig            `yaml:"favicons"`      // Favicons, apple-touch-icon, and web manifest generated from one logo
+
+	Stats SiteStats      `yaml:"-"` // Computed from the published posts when building, not read from the config
+	Data  map[string]any `yaml:"-"` // Loaded from the files in data/ when building (see loadData)
+
+	defaultLang string // Default language of a multilingual site, kept by forLanguage
 }
 
 // Renderer handles template rendering
 type Renderer struct {
-	templates *template.Template
+	templates      *template.Template
+	fs             fs.FS                         // Filesystem content templates are parsed from
+	out            FS                            // Filesystem pages are written to (default: the working directory)
+	pagesMu        sync.Mutex                    // Guards pages
+	pages          map[string]*template.Template // base.html with each content template parsed in, by content template (see pageTemplate)
+	defaultTheme   bool                          // Templates come from the embedded default theme
+	minify         bool                          // Minify rendered HTML before writing
+	bundles        map[string]string             // Bundle name → URL, exposed to every page
+	transformers   []namedTransformer            // HTML transformers run on every rendered page
+	postProcessors []namedPostProcessor          // Post-processors run on the final HTML of every page
+	icons          []headIcon                    // Icons found in static/ or generated (see FaviconsConfig), linked from .Head
+	series         map[string]*Series            // Series by name, exposed to the posts in them
+	taxonomies     map[string]*Taxonomy          // Taxonomies by name, exposed to every page
+	env            string                        // Build environment, exposed to templates as .Env
 }
 
 // PageData holds data passed to templates
 type PageData struct {
-	Site  SiteConfig
-	Post  *parser.Post
-	Posts []*parser.Post
-	Title string
+	Site         SiteConfig
+	Post         *parser.Post
+	Posts        []*parser.Post
+	Featured     []*parser.Post       // Featured posts on the home page (see FeaturedConfig)
+	Package      *PackageDoc          // Set on Go package reference pages
+	Section      *Section             // Set on section list pages and section entries
+	Shortlink    *Shortlink           // Set on short link pages (see ShortlinksConfig)
+	Series       *Series              // Set on series pages, and on posts in a series
+	Translations []Translation        // The page in the site's other languages, on multilingual sites
+	Outputs      []PageOutput         // The post in its other formats, on post pages (see OutputJSON)
+	Taxonomy     *Taxonomy            // Set on taxonomy and term pages
+	Term         *Term                // Set on term pages
+	Taxonomies   map[string]*Taxonomy // Taxonomies by name, with their terms and counts (see Taxonomy)
+	Bundles      map[string]string    // Bundle name → URL, e.g. {{ index .Bundles "css/site.css" }}
+	Title        string
+	Kind         string        // KindIndex, KindSection, KindPost, KindPage, KindPackage, KindShortlink, KindSeries, KindTaxonomy, or KindTerm
+	URL          string        // Site-relative URL of the page (e.g., "/posts/hello.html")
+	Home         string        // URL of the home page in the page's language ("/", or "/es/" on a multilingual site)
+	Head         template.HTML // Generated <head> metadata (see Renderer.head)
+	Icons        template.HTML // Icon and web manifest links, also part of Head (see FaviconsConfig)
+	Env          string        // Build environment: EnvProduction or EnvDevelopment
 }
 
 // Build generates the static site by orchestrating parser and renderer.
 //
 // Flow:
-//  1. Loads site configuration from config.yaml (title, author, etc.)
+//  1. Loads site configuration from config.yaml (title, author, etc.), runs
+//     the preBuild hooks (see HooksConfig), loads the data files in data/
+//     (see loadData), then runs the plugins' BeforeBuild hooks (see Plugin)
 //  2. Creates a parser instance to handle markdown conversion
-//  3. Parses all markdown files in content/posts/ using parser.ParseFile
-//  4. Filters out draft posts and sorts by date (newest first)
-//  5. Creates a renderer instance with templates from templates/
-//  6. Renders posts.html with the list of posts using renderer.renderIndex
-//  7. Renders individual post pages using renderer.renderPost
-//  8. Copies static assets (CSS, images, etc.) to output directory
+//  3. Parses all markdown files in content/posts/ using parser.ParseFile,
+//     and in content/<lang>/ for the languages of a multilingual site (see
+//     LanguageConfig), and warns about frontmatter that doesn't match the
+//     config's schema (see FrontmatterConfig)
+//  4. Filters out draft, future-dated, and expired posts (unless opts include them),
+//     sorts by date (newest first), and assigns each post its URL from the
+//     permalink pattern
+//  5. Loads the other directories under content/ as sections (see
+//     loadSections), filtered and sorted the same way, runs the plugins'
+//     AfterParse hooks on every post and entry, computes the site's
+//     statistics (see SiteStats), reads when each post last changed and
+//     who changed it from git history (see assignGitInfo), fetches comment
+//     counts for posts and entries that link a comment thread, assigns
+//     QR code images to those that get one (see QRCodeConfig) and social
+//     preview images (see OGImageConfig), and groups
+//     them into series (see Series) and taxonomies (see Taxonomy)
+//  6. Creates a renderer instance with templates from templates/, layered
+//     over the templates of the configured theme, or the embedded default
+//     theme if the site has neither, whose getJSON and getCSV functions
+//     fetch remote data through a cache (see RemoteDataConfig)
+//  7. Concatenates configured CSS/JS bundles, bundles scripts with esbuild
+//     (see ScriptConfig), and downloads snapshotted
+//     third-party assets, whose references are rewritten in every page
+//  8. Generates responsive image variants and rewrites post <img> tags to use them
+//  9. Renders posts.html with the list of posts and the featured posts
+//     (see FeaturedConfig) using renderer.renderIndex, once per language on
+//     a multilingual site
+//  10. Renders individual post pages using renderer.renderPost, with their
+//     QR codes and their other output formats (see OutputJSON)
+//  11. Renders each section's list page and entries using renderer.renderSection,
+//     then each series' page using renderer.renderSeries
+//  12. Writes the search index (see SearchConfig) if enabled
+//  13. Renders pages mounted from files outside content/ (e.g., README.md)
+//  14. Renders Go package reference pages configured under godoc.packages,
+//     then the short link pages listed in data/shortlinks.yaml (see
+//     ShortlinksConfig) and redirect pages for the old URLs listed under
+//     redirects and in frontmatter aliases
+//  15. Copies static assets (CSS, images, etc.) to output directory, after
+//     the consent script (see ConsentConfig) and the theme's static files
+//     (or the default theme's stylesheet), then writes the server redirect
+//     files listed under redirectFiles and the files the host named by
+//     deployTarget needs (see writeHostFiles)
+//  16. Runs the plugins' AfterBuild hooks: the built-in plugins render the
+//     list and term pages of each taxonomy (see Taxonomy), write the JSON
+//     API of posts under /api/ (and /<lang>/api/ for each other language),
+//     and write sitemap.xml (see SitemapConfig), then registered plugins
+//     add their own pages and files, then runs the postBuild hooks
+//  17. Sets every output file and directory to the configured permissions
+//
+// Every rendered page is run through the transformers added with
+// RegisterTransformer, then the built-in ones (snapshot links, the consent
+// script). If minify is enabled in the config, rendered HTML and
+// copied CSS/JS files are minified as they are written. Finally, pages are
+// run through the post-processors added with RegisterPostProcessor and the
+// plugins' AfterRender hooks.
+//
+// Cancelling ctx stops the build between pages.
+//
+// The site is read from opts.Source and written to opts.Output, the working
+// directory unless set (see FS), so a build can run entirely in memory with
+// a MemFS. Hooks, scripts, godoc packages, and extra image formats run
+// outside the FS and still need the site on disk.
 //
 // Parameters:
-//   - configPath: Path to config.yaml containing site metadata
-//   - outputDir: Directory where generated HTML files will be written (usually "public")
+//   - ctx: Context for cancelling the build
+//   - opts: Build options (config path, output directory, drafts, etc.); empty
+//     fields take their defaults
+//
+// After the site is generated, every page is scanned for internal links to
+// files missing from the output directory, and each broken link is printed as
+// a warning. With opts.Strict, broken links fail the build, as does
+// frontmatter that doesn't match the config's schema. If the config enables
+// it, pages are also audited for accessibility (see AccessibilityConfig).
+//
+// After a successful build, a manifest of the output files is saved to
+// .ssg/manifest.json so later commands (like diff) can compare against it,
+// unless opts.Output is a different FS than opts.Source.
 //
-// Returns an error if any step fails (config loading, parsing, rendering, or file I/O).
-func Build(configPath, outputDir string) error {
+// With opts.Shard (e.g., "2/4"), only a deterministic subset of the pages is
+// written, so a large site can be built by parallel CI jobs and combined with
+// Merge, which also does the link check and writes the manifest. Every shard
+// parses all content, so pages list and link to each other as usual.
+//
+// With opts.DryRun, the site is built into a scratch directory instead, and
+// the files the build would create, change, or delete in the output
+// directory are printed (see dryRun).
+//
+// With opts.Profile, a report of how long each stage of the build took and
+// which posts were slowest to render is printed once the site is written
+// (see buildProfile). With opts.CPUProfile, a pprof CPU profile of the whole
+// build is written to that file, for `go tool pprof`.
+//
+// With opts.Notify, the hooks configured under notify in config.yaml are run
+// once the build finishes (see NotifyConfig).
+//
+// Returns an error if any step fails (config loading, parsing, rendering, or
+// file I/O), or if opts.Strict is set and broken links or frontmatter problems
+// were found.
+func Build(ctx context.Context, opts BuildOptions) error {
+	opts = opts.withDefaults()
+	start := time.Now()
+	if opts.CPUProfile != "" {
+		stop, err := startCPUProfile(opts.CPUProfile)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := stop(); err != nil {
+				slog.Warn("writing CPU profile", "error", err)
+			}
+		}()
+	}
+	var err error
+	if opts.DryRun {
+		_, err = dryRun(ctx, opts, os.Stdout)
+	} else {
+		err = buildSite(ctx, opts)
+	}
+	if opts.Notify {
+		notifyBuild(opts.ConfigPath, opts.Environment, err, time.Since(start))
+	}
+	return err
+}
+
+// buildSite generates the site, checks its links, and saves its manifest, as
+// documented on Build.
+func buildSite(ctx context.Context, opts BuildOptions) error {
+	opts = opts.withDefaults()
+	outputDir := opts.OutputDir
+	if err := generate(ctx, opts); err != nil {
+		return err
+	}
+	if opts.Shard != "" {
+		// A shard's links and manifest are checked once shards are merged
+		return nil
+	}
+
+	if err := reportBrokenLinks(opts.Output, outputDir, opts.Strict); err != nil {
+		return err
+	}
+	if err := reportAccessibility(opts.Source, opts.Output, outputDir, opts.ConfigPath, opts.Environment); err != nil {
+		return err
+	}
+	if opts.Output != opts.Source {
+		// The manifest describes the output next to the site, for diff
+		return nil
+	}
+	if err := saveManifest(opts.Source, opts.Output, outputDir, preservePatterns(opts.Source, opts.ConfigPath, opts.Environment)); err != nil {
+		return err
+	}
+
+	// Receivers fetch the posts to verify them, so builds only send
+	// webmentions when asked to, and never for a preview's baseUrl
+	if opts.Webmentions && opts.Environment == EnvProduction && opts.BaseURL == "" {
+		return sendWebmentions(ctx, opts)
+	}
+	return nil
+}
+
+// reportBrokenLinks warns about broken internal links in the site built to
+// outputDir in fsys. With strict, broken links are an error.
+func reportBrokenLinks(fsys fs.FS, outputDir string, strict bool) error {
+	broken, err := checkOutputLinks(fsys, outputDir)
+	if err != nil {
+		return fmt.Errorf("checking links: %w", err)
+	}
+	for _, p := range broken {
+		slog.Warn("broken link", "file", p.File, "problem", p.Message)
+	}
+	if strict && len(broken) > 0 {
+		return fmt.Errorf("found %d broken links", len(broken))
+	}
+	return nil
+}
+
+// saveManifest records the files in outputDir in out as the manifest of the
+// last build, saved to the site in src, leaving out the preserved paths,
+// which the build doesn't write.
+func saveManifest(src FS, out fs.FS, outputDir string, preserve []string) error {
+	m, err := buildManifest(out, outputDir, preserve)
+	if err != nil {
+		return fmt.Errorf("creating manifest: %w", err)
+	}
+	if err := m.write(src, manifestPath); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	return nil
+}
+
+// generate runs the build steps documented on Build, writing the site to
+// opts.OutputDir without recording a manifest. Commands that build into
+// scratch directories (like diff) use it so the saved manifest keeps
+// describing the real output directory.
+func generate(ctx context.Context, opts BuildOptions) error {
+	opts = opts.withDefaults()
+	start := time.Now()
+	var profile *buildProfile
+	if opts.Profile {
+		profile = &buildProfile{}
+	}
+	timer := newStageTimer(profile)
+	src, out, outputDir := opts.Source, opts.Output, opts.OutputDir
+	sh, err := parseShard(opts.Shard)
+	if err != nil {
+		return err
+	}
+	sh.pages = opts.pages
+
 	// Load configuration
-	config, err := loadConfig(configPath)
+	config, err := loadConfigEnv(src, opts.ConfigPath, opts.Environment)
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
+	if opts.BaseURL != "" {
+		config.BaseURL = opts.BaseURL
+	}
 
-	// Create parser
-	p := parser.New()
+	// Kept here since sandboxed themes don't see the config's hooks
+	hooks := config.Hooks
+	if opts.preview || opts.pages != nil {
+		hooks = HooksConfig{}
+	}
+	if err := runHooks(ctx, "preBuild", hooks.PreBuild, hookEnv(opts, config)); err != nil {
+		return err
+	}
 
-	// Parse all posts
-	posts, err := parseAllPosts(p, "content/posts")
+	config.Data, err = loadData(src, dataDir)
 	if err != nil {
-		return fmt.Errorf("parsing posts: %w", err)
+		return fmt.Errorf("loading data files: %w", err)
 	}
 
-	// Filter out drafts
-	publishedPosts := filterDrafts(posts)
+	plugins := sitePlugins()
+	b := &BuildContext{Config: config, OutputDir: outputDir, Output: out, shard: sh}
+	if err := runPlugins(plugins, func(p Plugin) error { return p.BeforeBuild(b) }); err != nil {
+		return err
+	}
+	timer.done("config")
 
-	// Sort posts by date (newest first)
-	sort.Slice(publishedPosts, func(i, j int) bool {
-		return publishedPosts[i].Date.After(publishedPosts[j].Date)
-	})
+	// Create parser
+	p := newParser(config)
+
+	// Parse, filter, and sort posts
+	publishedPosts, err := loadPosts(src, p, config, opts.Drafts, opts.Future, opts.Expired)
+	if err != nil {
+		return err
+	}
+	sections, err := loadSections(src, p, config, opts.Drafts, opts.Future, opts.Expired)
+	if err != nil {
+		return err
+	}
+	if opts.pages == nil {
+		if err := reportFrontmatterProblems(src, config, opts.Strict); err != nil {
+			return err
+		}
+	}
+	allPosts := slices.Clone(publishedPosts)
+	for _, section := range sections {
+		allPosts = append(allPosts, section.Posts...)
+	}
+	for _, post := range allPosts {
+		if err := runPlugins(plugins, func(p Plugin) error { return p.AfterParse(post) }); err != nil {
+			return err
+		}
+	}
+	if err := assignOutputs(allPosts, config); err != nil {
+		return err
+	}
+	config.Stats = computeStats(publishedPosts)
+	assignGitInfo(src, allPosts, config.GitInfo)
+	if err := fetchCommentCounts(allPosts, config.Comments, time.Now()); err != nil {
+		return err
+	}
+	if err := fetchMentions(src, allPosts, config.Webmentions, config.BaseURL, time.Now()); err != nil {
+		return err
+	}
+	if err := assignQRCodes(allPosts, config.QRCode, config.BaseURL); err != nil {
+		return err
+	}
+	if err := assignOGImages(allPosts, config.OGImage, config.BaseURL); err != nil {
+		return err
+	}
+	series, err := collectSeries(allPosts, config)
+	if err != nil {
+		return err
+	}
+	taxonomies, err := collectTaxonomies(allPosts, config)
+	if err != nil {
+		return err
+	}
+	b.Posts, b.Sections, b.Series, b.Taxonomies = publishedPosts, sections, series, taxonomies
+	timer.done("content")
 
 	// Create renderer
-	r, err := newRenderer("templates")
+	funcs, err := templateFuncs(*config, p)
+	if err != nil {
+		return fmt.Errorf("creating template funcs: %w", err)
+	}
+	themeDir, err := config.Theme.dir(src)
+	if err != nil {
+		return err
+	}
+	if dir, ok := diskPath(src, themeDir); ok && themeDir != "" {
+		checkThemeVersion(dir, config.Theme)
+	}
+	if err := checkSandbox(src, *config, "templates", themeDir); err != nil {
+		return err
+	}
+	if config.Theme.Sandbox {
+		// Hook commands and webhook URLs aren't the theme's business
+		config.Notify = NotifyConfig{}
+		config.Hooks = HooksConfig{}
+	}
+	r, err := newRenderer(src, "templates", themeDir, funcs)
 	if err != nil {
 		return fmt.Errorf("creating renderer: %w", err)
 	}
+	r.out = out
+	r.minify = config.Minify
+	r.transformers = htmlTransformers()
+	r.postProcessors = append(pagePostProcessors(), pluginPostProcessors(plugins)...)
+	r.icons = findIcons(src, "static")
+	if config.Favicons.Source != "" {
+		r.icons = config.Favicons.icons()
+	}
+	r.env = opts.Environment
+	r.series = make(map[string]*Series, len(series))
+	for _, s := range series {
+		r.series[s.Name] = s
+	}
+	r.taxonomies = make(map[string]*Taxonomy, len(taxonomies))
+	for _, tax := range taxonomies {
+		r.taxonomies[tax.Name] = tax
+	}
+	b.renderer = r
+	og, err := newOGImager(src, config.OGImage)
+	if err != nil {
+		return err
+	}
+
+	fileMode, dirMode, err := config.Permissions.modes()
+	if err != nil {
+		return err
+	}
+	limits, err := config.Static.limits()
+	if err != nil {
+		return err
+	}
 
-	// Clean and create output directory
-	if err := os.RemoveAll(outputDir); err != nil {
+	// Clean and create output directory, keeping preserved paths
+	if err := checkPreserve(config.Preserve); err != nil {
+		return err
+	}
+	if err := cleanOutput(out, outputDir, config.Preserve); err != nil {
 		return fmt.Errorf("cleaning output directory: %w", err)
 	}
-	if err := os.MkdirAll(outputDir, 0750); err != nil {
-		return fmt.Errorf("creating output directory: %w", err)
+
+	timer.done("templates")
+
+	// Concatenate static asset bundles
+	r.bundles, err = buildBundles(src, out, config.Bundles, "static", outputDir, config.Minify)
+	if err != nil {
+		return fmt.Errorf("building bundles: %w", err)
+	}
+	scripts, err := buildScripts(src, out, config.Scripts, outputDir, config.Minify)
+	if err != nil {
+		return fmt.Errorf("bundling scripts: %w", err)
+	}
+	for name, url := range scripts {
+		if _, ok := r.bundles[name]; ok {
+			return fmt.Errorf("script %s has the same name as a bundle", name)
+		}
+		r.bundles[name] = url
 	}
 
-	// Render index page
-	indexPath := filepath.Join(outputDir, "index.html")
-	if err := r.renderIndex(publishedPosts, *config, indexPath); err != nil {
-		return fmt.Errorf("rendering index: %w", err)
+	// Pages rendered on request skip downloading and resizing, and link to
+	// the original assets and images instead
+	snapshotAssets, imagesConfig := config.Snapshot, config.Images
+	if opts.pages != nil {
+		snapshotAssets, imagesConfig.Widths = nil, nil
+	}
+
+	// Download third-party assets and point pages at the local copies
+	snapshots, err := snapshotResources(snapshotAssets, out, outputDir)
+	if err != nil {
+		return fmt.Errorf("snapshotting external resources: %w", err)
+	}
+	if len(snapshots) > 0 {
+		r.transformers = append(r.transformers, namedTransformer{name: "snapshot", fn: snapshotTransformer(snapshots)})
+	}
+	if config.Consent.Enabled {
+		r.transformers = append(r.transformers, namedTransformer{name: "consent", fn: consentTransformer()})
+	}
+	if config.Images.Lazy || config.Images.Dimensions {
+		staticDirs := []string{"static"}
+		if themeDir != "" {
+			staticDirs = append(staticDirs, filepath.Join(themeDir, "static"))
+		}
+		r.transformers = append(r.transformers, namedTransformer{name: "images", fn: imageAttrsTransformer(src, staticDirs, config.BaseURL, config.Images)})
+	}
+
+	// Generate responsive image variants and use them in post content
+	images, err := processImages(src, out, filepath.Join("static", "images"), filepath.Join(outputDir, "images"), "/images", imagesConfig)
+	if err != nil {
+		return fmt.Errorf("processing images: %w", err)
+	}
+	for _, post := range publishedPosts {
+		post.Content = rewriteImages(post.Content, images, config.Images.Sizes)
+	}
+	for _, section := range sections {
+		for _, post := range section.Posts {
+			post.Content = rewriteImages(post.Content, images, config.Images.Sizes)
+		}
+	}
+	timer.done("assets")
+
+	// Render index pages, one per language on a multilingual site
+	for _, lang := range indexLanguages(*config) {
+		indexURL := config.languageURL(lang, "/")
+		if !sh.owns(indexURL) {
+			continue
+		}
+		posts := postsInLanguage(publishedPosts, lang)
+		featured := featuredPosts(config.Featured, posts, time.Now())
+		if err := r.renderIndex(lang, posts, featured, *config, pageFile(outputDir, indexURL)); err != nil {
+			return fmt.Errorf("rendering index: %w", err)
+		}
 	}
 
 	// Render individual post pages
 	for _, post := range publishedPosts {
-		postPath := filepath.Join(outputDir, "posts", post.Slug+".html")
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !sh.owns(post.URL) {
+			continue
+		}
+		postStart := time.Now()
+		postPath := pageFile(outputDir, post.URL)
 		if err := r.renderPost(post, *config, postPath); err != nil {
 			return fmt.Errorf("rendering post %s: %w", post.Slug, err)
 		}
+		if err := writeQRCode(out, post, config.QRCode, config.BaseURL, outputDir); err != nil {
+			return fmt.Errorf("writing QR code: %w", err)
+		}
+		if err := og.write(ctx, out, post, config.forLanguage(post.Lang), outputDir); err != nil {
+			return err
+		}
+		if err := writeBundleResources(src, out, post, outputDir); err != nil {
+			return fmt.Errorf("copying resources of post %s: %w", post.Slug, err)
+		}
+		if err := writeAttachments(src, out, post, outputDir); err != nil {
+			return fmt.Errorf("copying attachments of post %s: %w", post.Slug, err)
+		}
+		if err := writePostOutputs(out, post, config.BaseURL, outputDir); err != nil {
+			return fmt.Errorf("writing outputs of post %s: %w", post.Slug, err)
+		}
+		profile.addPost(post.URL, time.Since(postStart))
+	}
+
+	// Render content sections and their entries
+	for _, section := range sections {
+		if sh.owns(section.URL) {
+			if err := r.renderSection(section, *config, pageFile(outputDir, section.URL)); err != nil {
+				return fmt.Errorf("rendering section %s: %w", section.Name, err)
+			}
+		}
+		for _, post := range section.Posts {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !sh.owns(post.URL) {
+				continue
+			}
+			postStart := time.Now()
+			if err := r.renderSectionPost(section, post, *config, pageFile(outputDir, post.URL)); err != nil {
+				return fmt.Errorf("rendering %s/%s: %w", section.Name, post.Slug, err)
+			}
+			if err := writeQRCode(out, post, config.QRCode, config.BaseURL, outputDir); err != nil {
+				return fmt.Errorf("writing QR code: %w", err)
+			}
+			if err := og.write(ctx, out, post, *config, outputDir); err != nil {
+				return err
+			}
+			if err := writeBundleResources(src, out, post, outputDir); err != nil {
+				return fmt.Errorf("copying resources of %s/%s: %w", section.Name, post.Slug, err)
+			}
+			if err := writeAttachments(src, out, post, outputDir); err != nil {
+				return fmt.Errorf("copying attachments of %s/%s: %w", section.Name, post.Slug, err)
+			}
+			if err := writePostOutputs(out, post, config.BaseURL, outputDir); err != nil {
+				return fmt.Errorf("writing outputs of %s/%s: %w", section.Name, post.Slug, err)
+			}
+			profile.addPost(post.URL, time.Since(postStart))
+		}
+	}
+
+	// Render series pages
+	for _, s := range series {
+		if !sh.owns(s.URL) {
+			continue
+		}
+		if err := r.renderSeries(s, *config, pageFile(outputDir, s.URL)); err != nil {
+			return fmt.Errorf("rendering series %s: %w", s.Name, err)
+		}
+	}
+
+	// Write search index
+	if sh.first() {
+		if err := writeSearchIndex(publishedPosts, sections, config.Search, out, outputDir); err != nil {
+			return fmt.Errorf("writing search index: %w", err)
+		}
+	}
+
+	// Render mounted pages
+	pages, err := loadMounts(src, p, config.Mounts, config.URLs)
+	if err != nil {
+		return fmt.Errorf("loading mounts: %w", err)
+	}
+	b.Pages = pages
+	for _, page := range pages {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !sh.owns(page.URL) {
+			continue
+		}
+		pagePath := pageFile(outputDir, page.URL)
+		if err := r.renderPage(page, *config, pagePath); err != nil {
+			return fmt.Errorf("rendering page %s: %w", page.Slug, err)
+		}
+	}
+
+	// Render Go package reference pages
+	pkgs, err := loadPackageDocs(src, config.Godoc.Packages)
+	if err != nil {
+		return fmt.Errorf("loading package docs: %w", err)
+	}
+	b.Packages = pkgs
+	for _, pkg := range pkgs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pkgURL := pageURL(config.URLs, "/pkg/"+pkg.Slug)
+		if !sh.owns(pkgURL) {
+			continue
+		}
+		pkgPath := pageFile(outputDir, pkgURL)
+		if err := r.renderPackage(pkg, *config, pkgPath); err != nil {
+			return fmt.Errorf("rendering package %s: %w", pkg.ImportPath, err)
+		}
+	}
+
+	// Render the 404 page, if the site has a template for it
+	if sh.owns(notFoundURL) {
+		if err := r.renderNotFound(*config, pageFile(outputDir, notFoundURL)); err != nil {
+			return fmt.Errorf("rendering 404 page: %w", err)
+		}
+	}
+
+	timer.done("pages")
+
+	// Site-wide files go to the first shard
+	if sh.first() {
+		// Write short link pages from data/shortlinks.yaml
+		shortlinks, err := loadShortlinks(src, config.Shortlinks)
+		if err != nil {
+			return fmt.Errorf("loading short links: %w", err)
+		}
+		for _, link := range shortlinks {
+			if err := r.renderShortlink(link, *config, pageFile(outputDir, link.Path)); err != nil {
+				return fmt.Errorf("rendering short link %s: %w", link.Code, err)
+			}
+		}
+
+		// Write redirect pages for moved URLs and frontmatter aliases
+		redirects, err := siteRedirects(*config, allPosts, pages)
+		if err != nil {
+			return err
+		}
+		if err := writeRedirects(out, outputDir, redirects, config.BaseURL); err != nil {
+			return fmt.Errorf("writing redirects: %w", err)
+		}
+
+		if err := writeConsentScript(out, outputDir, config.Consent, config.Minify); err != nil {
+			return fmt.Errorf("writing consent script: %w", err)
+		}
+
+		// Copy static files
+		if r.defaultTheme {
+			if err := copyThemeStatic(out, outputDir, config.Minify); err != nil {
+				return fmt.Errorf("copying theme files: %w", err)
+			}
+		}
+		if themeDir != "" {
+			if err := copyStatic(src, out, filepath.Join(themeDir, "static"), outputDir, config.Minify, limits); err != nil {
+				return fmt.Errorf("copying theme files: %w", err)
+			}
+		}
+		if err := copyStatic(src, out, "static", outputDir, config.Minify, limits); err != nil {
+			return fmt.Errorf("copying static files: %w", err)
+		}
+		if err := writeServerRedirects(out, outputDir, redirects, config.RedirectFiles); err != nil {
+			return fmt.Errorf("writing redirects: %w", err)
+		}
+		if err := writeHostFiles(out, outputDir, *config, redirects); err != nil {
+			return fmt.Errorf("writing %s files: %w", config.DeployTarget, err)
+		}
+		if err := writeFavicons(src, out, config.Favicons, *config, outputDir); err != nil {
+			return fmt.Errorf("writing favicons: %w", err)
+		}
+		if err := writeThemeColors(out, config.Theme.Colors, outputDir); err != nil {
+			return fmt.Errorf("writing theme colors: %w", err)
+		}
+	}
+
+	timer.done("static")
+
+	// Let plugins add their pages and files: taxonomy pages, the JSON API,
+	// sitemap.xml, feeds, robots.txt and humans.txt, and those of registered
+	// plugins
+	if err := runPlugins(plugins, func(p Plugin) error { return p.AfterBuild(b) }); err != nil {
+		return err
 	}
+	timer.done("plugins")
 
-	// Copy static files
-	if err := copyStatic("static", outputDir); err != nil {
-		return fmt.Errorf("copying static files: %w", err)
+	if err := runHooks(ctx, "postBuild", hooks.PostBuild, postBuildEnv(opts, config, len(publishedPosts), time.Since(start))); err != nil {
+		return err
 	}
+	timer.done("postBuild hooks")
 
-	fmt.Printf("Built %d posts to %s\n", len(publishedPosts), outputDir)
+	// Normalize output permissions
+	if err := setOutputModes(out, outputDir, fileMode, dirMode, config.Preserve); err != nil {
+		return fmt.Errorf("setting output permissions: %w", err)
+	}
+
+	timer.done("permissions")
+	if profile != nil {
+		profile.write(os.Stdout, time.Since(start))
+	}
+
+	if opts.pages != nil {
+		return nil
+	}
+	if sh.count > 1 {
+		slog.Info("Built shard", "shard", sh.String(), "output", outputDir, "duration", time.Since(start))
+		return nil
+	}
+	if !opts.preview {
+		slog.Info("Built site", "posts", len(publishedPosts), "output", outputDir, "duration", time.Since(start))
+	}
 	return nil
 }
 
 // Serve starts a local development server to preview the generated site.
 //
-// Serves static files from the "public" directory on the specified port.
-// This is a simple HTTP file server for local development only.
+// Builds the site, then serves the output directory on the specified port, so
+// the preview always reflects the current content. Unless opts.NoWatch is
+// set, the site's sources are watched and the site is rebuilt whenever one
+// changes (see watchSite), so editing needs only this one command. URLs
+// resolve the way static hosts resolve them (see siteHandler): /foo/ serves
+// foo/index.html, /foo falls back to foo.html, and missing pages get the
+// site's 404.html. Unless opts.NoRewrite is set, links to the site's baseUrl
+// in served pages are rewritten to local ones (see localURLs), so a site
+// configured for production can be clicked through. Builds run the notify
+// hooks from the config, so a broken build is reported even if the terminal
+// isn't in view. This is for local development only.
+//
+// If opts.Live is set, pages are rendered from source on each request (see
+// liveSite) rather than served from the last build, so edits to templates
+// and markdown show up without waiting for a rebuild; other files are still
+// served from the output directory, which the watcher keeps up to date.
+//
+// If opts.InMemory is set, the site is built into a MemFS and served from
+// there, so the output directory on disk is neither read nor written.
 //
 // Parameters:
-//   - port: Port number to serve on (e.g., "3000" for localhost:3000)
+//   - opts: Serve options (config path, output directory, port, etc.); empty
+//     fields take their defaults
 //
-// Returns an error if the public directory doesn't exist or server fails to start.
-func Serve(port string) error {
-	publicDir := "public"
+// Returns an error if the site is missing its config or content, the initial
+// build fails, the output directory doesn't exist, or the server fails to
+// start. Failed rebuilds are printed and the previous output keeps being served.
+func Serve(opts ServeOptions) error {
+	opts = opts.withDefaults()
+	if opts.Live && opts.NoBuild {
+		return fmt.Errorf("--live renders pages from source, so it can't be used with --no-build")
+	}
+	if opts.InMemory {
+		if opts.NoBuild {
+			return fmt.Errorf("--in-memory serves what it builds, so it can't be used with --no-build")
+		}
+		opts.output = &MemFS{}
+	}
+	if err := prepareServe(opts); err != nil {
+		return err
+	}
 
-	// Check if public directory exists
-	if _, err := os.Stat(publicDir); os.IsNotExist(err) {
-		return fmt.Errorf("public directory does not exist, run 'ssg build' first")
+	// /search?q= queries an in-memory index of the site, reloaded with
+	// each build.
+	buildOpts := opts.buildOptions().withDefaults()
+	search := &searchHandler{}
+	if err := search.load(buildOpts.Source, opts.ConfigPath); err != nil {
+		slog.Warn("indexing site for /search", "error", err)
 	}
 
-	// Serve static files
-	fs := http.FileServer(http.Dir(publicDir))
-	http.Handle("/", fs)
+	if !opts.NoBuild && !opts.NoWatch {
+		go watchSite(context.Background(), opts.ConfigPath, watchInterval, func(changed []string) {
+			slog.Info("Rebuilding", "changed", describeChanges(changed))
+			start := time.Now()
+			if err := Build(context.Background(), buildOpts); err != nil {
+				slog.Error("building site", "error", err)
+				return
+			}
+			if err := search.load(buildOpts.Source, opts.ConfigPath); err != nil {
+				slog.Warn("indexing site for /search", "error", err)
+			}
+			slog.Info("Rebuilt", "duration", time.Since(start))
+		})
+		slog.Info("Watching for changes")
+	}
+
+	// Links to the production site are rewritten to point here. baseUrl is
+	// read once, so changing it needs a restart.
+	var baseURL string
+	if !opts.NoRewrite {
+		if config, err := LoadConfigEnv(opts.ConfigPath, EnvDevelopment); err == nil {
+			baseURL = config.BaseURL
+		}
+	}
+
+	root := fs.FS(DirFS(opts.OutputDir))
+	if opts.output != nil {
+		sub, err := subFS(opts.output, path.Clean(filepath.ToSlash(opts.OutputDir)))
+		if err != nil {
+			return err
+		}
+		root = sub
+	}
+	built := newFSHandler(root, !opts.NoListings, baseURL)
+	var site http.Handler = built
+	if opts.Live {
+		site = &liveSite{opts: opts.buildOptions().withDefaults(), built: built, baseURL: baseURL}
+		slog.Info("Rendering pages on each request")
+	}
 
-	addr := ":" + port
+	addr := ":" + opts.Port
 	fmt.Printf("Serving site at http://localhost%s\n", addr)
 	fmt.Println("Press Ctrl+C to stop")
 
@@ -150,6 +894,7 @@ func Serve(port string) error {
 	// Start HTTP server
 	srv := &http.Server{
 		Addr:              addr,
+		Handler:           newDevHandler(site, search),
 		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
 		ReadHeaderTimeout: 60 * time.Second,
 	}
@@ -157,52 +902,158 @@ func Serve(port string) error {
 	return srv.ListenAndServe()
 }
 
-// NewPost creates a new markdown post file with YAML frontmatter template.
+// prepareServe builds the site for Serve, or checks that a previous build
+// exists if opts.NoBuild is set.
+func prepareServe(opts ServeOptions) error {
+	configPath, outputDir := opts.ConfigPath, opts.OutputDir
+	if opts.NoBuild {
+		if _, err := os.Stat(outputDir); os.IsNotExist(err) {
+			return fmt.Errorf("%s does not exist, run 'ssg build' first or serve without --no-build", outputDir)
+		}
+		return nil
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return fmt.Errorf("%s not found, run ssg from your site's root directory", configPath)
+	}
+	if _, err := os.Stat(filepath.Join("content", "posts")); os.IsNotExist(err) {
+		return fmt.Errorf("no content/posts directory found, create a post with 'ssg new --title \"My Post\"'")
+	}
+
+	if err := Build(context.Background(), opts.buildOptions()); err != nil {
+		return fmt.Errorf("building site: %w", err)
+	}
+	return nil
+}
+
+// NewPost creates a new markdown post file from an archetype.
+//
+// Creates a new file in content/posts/ with the format: YYYY-MM-DD-slug.md,
+// or in content/<section>/ as slug.md if opts.Section is set. The slug is
+// generated from the title (lowercase, spaces to hyphens, alphanumeric only),
+// with letters like ü or é transliterated following the language in the
+// config, unless opts.Slug is set. The file's frontmatter and starting text
+// come from the section's archetype (see findArchetype), filled in with the
+// title, date, description, tags, and draft status from opts.
 //
-// Creates a new file in content/posts/ with the format: YYYY-MM-DD-slug.md
-// The slug is generated from the title (lowercase, spaces to hyphens, alphanumeric only).
-// The file is pre-populated with YAML frontmatter including title, date, and draft status.
+// If opts.Edit is set, the new file is then opened in $VISUAL or $EDITOR.
+// Since the file is for the user to edit, NewPost always works on the site
+// in the working directory, on disk.
 //
 // Parameters:
-//   - title: Human-readable title for the post (e.g., "My First Post")
+//   - opts: Post options; Title is required
 //
-// Returns an error if file creation fails.
-func NewPost(title string) error {
-	// Create slug from title
-	slug := strings.ToLower(title)
-	slug = strings.ReplaceAll(slug, " ", "-")
-	// Remove non-alphanumeric characters except hyphens
-	var cleanSlug strings.Builder
-	for _, r := range slug {
-		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
-			cleanSlug.WriteRune(r)
+// Returns the path of the new file, or an error if the section or archetype
+// is invalid, a file by that name already exists, or the file can't be
+// written or edited.
+func NewPost(opts NewPostOptions) (string, error) {
+	opts = opts.withDefaults()
+	if opts.Title == "" {
+		return "", fmt.Errorf("post title is required")
+	}
+	section, err := newPostSection(opts.Section)
+	if err != nil {
+		return "", err
+	}
+
+	// Create slug from title, transliterated for the site's language
+	slug := opts.Slug
+	if slug == "" {
+		var lang string
+		if config, err := LoadConfig(opts.ConfigPath); err == nil {
+			lang = config.Language
 		}
+		slug = slugifyLang(lang, opts.Title)
+	}
+	if slug == "" || strings.ContainsAny(slug, `/\`) {
+		return "", fmt.Errorf("invalid slug %q", slug)
 	}
-	slug = cleanSlug.String()
 
-	// Create filename with date
-	date := time.Now().Format("2006-01-02")
-	filename := fmt.Sprintf("%s-%s.md", date, slug)
-	filepath := filepath.Join("content/posts", filename)
+	// Posts are named by date, so they sort in order; entries of other
+	// sections are named by slug
+	now := time.Now()
+	filename := slug + ".md"
+	if section == "posts" {
+		filename = now.Format("2006-01-02") + "-" + filename
+	}
+	postPath := filepath.Join("content", filepath.FromSlash(section), filename)
 
-	// Create post template
-	content := fmt.Sprintf(`---
-title: %s
-date: %s
-description: ""
-tags: []
-draft: true
----
+	archetype, err := findArchetype(DirFS("."), section)
+	if err != nil {
+		return "", err
+	}
+	content, err := archetype.render(archetypeData{
+		Title:       opts.Title,
+		Slug:        slug,
+		Section:     section,
+		Date:        now.Format(time.RFC3339),
+		Description: opts.Description,
+		Tags:        opts.Tags,
+		Draft:       !opts.NoDraft,
+	})
+	if err != nil {
+		return "", err
+	}
 
-Write your post here...
-`, title, time.Now().Format(time.RFC3339))
+	if err := os.MkdirAll(filepath.Dir(postPath), 0750); err != nil {
+		return "", fmt.Errorf("creating %s: %w", filepath.Dir(postPath), err)
+	}
+	f, err := os.OpenFile(postPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) // #nosec G304 -- path is built from a validated section and slug
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("%s already exists", postPath)
+		}
+		return "", fmt.Errorf("writing post file: %w", err)
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return "", fmt.Errorf("writing post file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("writing post file: %w", err)
+	}
+	fmt.Printf("Created new post: %s\n", postPath)
+
+	if opts.Edit {
+		if err := openEditor(postPath); err != nil {
+			return postPath, err
+		}
+	}
+	return postPath, nil
+}
 
-	// Write file
-	if err := os.WriteFile(filepath, []byte(content), 0600); err != nil {
-		return fmt.Errorf("writing post file: %w", err)
+// newPostSection returns the cleaned, slash-separated section a new post
+// goes in, or an error if it isn't a directory under content/ that could
+// hold a section (see findSections).
+func newPostSection(section string) (string, error) {
+	name := path.Clean(filepath.ToSlash(section))
+	for _, elem := range strings.Split(name, "/") {
+		if elem == ".." || elem == "." || strings.HasPrefix(elem, "_") || strings.HasPrefix(elem, ".") {
+			return "", fmt.Errorf("invalid section %q", section)
+		}
 	}
+	if path.IsAbs(name) {
+		return "", fmt.Errorf("invalid section %q", section)
+	}
+	return name, nil
+}
 
-	fmt.Printf("Created new post: %s\n", filepath)
+// openEditor opens path in the user's editor, $VISUAL or else $EDITOR, and
+// waits for it to exit. The variable may include arguments, like "code -w".
+func openEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		return fmt.Errorf("set $EDITOR to open %s", path)
+	}
+	cmd := exec.Command(args[0], append(args[1:], path)...) // #nosec G204 -- the editor is the user's own setting
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", args[0], err)
+	}
 	return nil
 }
 
@@ -210,25 +1061,72 @@ Write your post here...
 //
 // Uses template.ParseGlob to load all *.html files in the directory into a single
 // template set. Each file is named by its filename (e.g., "base.html", "posts.html").
-// Templates can reference each other using {{define}} blocks.
+// Templates can reference each other using {{define}} blocks. The funcs are
+// registered before parsing so every template can call them.
+//
+// Files under templateDir/partials/ (including subdirectories) are added as
+// partials, named by their path relative to partials/ without the extension,
+// so templates/partials/nav.html is available as {{template "nav" .}}.
+//
+// Templates in templateDir are layered over those of the theme in themeDir
+// (see templateFS), so a site only needs the templates it overrides. If
+// neither exists, the embedded default theme is used instead. A sandboxed
+// theme's templates must pass checkSandbox before they're loaded here (see
+// CapUnsafeHTML for the capability model).
 //
 // Expected template structure:
 //   - base.html: Main layout with {{template "posts" .}} placeholder
 //   - posts.html: Defines {{define "posts"}} for the posts list page
 //   - post.html: Defines {{define "posts"}} for individual post pages
+//   - partials/*.html: Optional shared components (nav, footer, etc.)
 //
 // Parameters:
+//   - src: Filesystem the site is read from
 //   - templateDir: Directory containing HTML templates (e.g., "templates")
+//   - themeDir: Directory of the site's theme (e.g., "themes/paper"), or ""
+//   - funcs: Template functions from templateFuncs (may be nil)
 //
 // Returns a Renderer instance or an error if template loading fails.
-func newRenderer(templateDir string) (*Renderer, error) {
+func newRenderer(src fs.FS, templateDir, themeDir string, funcs template.FuncMap) (*Renderer, error) {
+	fsys, isDefault := templateFS(src, templateDir, themeDir)
+
 	// Load all templates
-	tmpl, err := template.ParseGlob(filepath.Join(templateDir, "*.html"))
+	tmpl, err := template.New("").Funcs(funcs).ParseFS(fsys, "*.html")
 	if err != nil {
 		return nil, fmt.Errorf("loading templates: %w", err)
 	}
 
-	return &Renderer{templates: tmpl}, nil
+	if err := parsePartials(tmpl, fsys, "partials"); err != nil {
+		return nil, fmt.Errorf("loading partials: %w", err)
+	}
+
+	return &Renderer{templates: tmpl, fs: fsys, out: DirFS("."), defaultTheme: isDefault}, nil
+}
+
+// parsePartials adds every *.html file under dir in fsys to tmpl as a named
+// template. Returns nil if dir doesn't exist.
+func parsePartials(tmpl *template.Template, fsys fs.FS, dir string) error {
+	if _, err := fs.Stat(fsys, dir); err != nil {
+		return nil
+	}
+
+	return fs.WalkDir(fsys, dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".html" {
+			return err
+		}
+
+		relPath := strings.TrimPrefix(path, dir+"/")
+		name := strings.TrimSuffix(relPath, ".html")
+
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		if _, err := tmpl.New(name).Parse(string(content)); err != nil {
+			return fmt.Errorf("parsing partial %s: %w", relPath, err)
+		}
+		return nil
+	})
 }
 
 // renderPost renders a single blog post page to an HTML file.
@@ -245,9 +1143,14 @@ func newRenderer(templateDir string) (*Renderer, error) {
 // Returns an error if rendering or file writing fails.
 func (r *Renderer) renderPost(post *parser.Post, config SiteConfig, outputPath string) error {
 	data := PageData{
-		Site:  config,
-		Post:  post,
-		Title: post.Title,
+		Site:         config.forLanguage(post.Lang),
+		Post:         post,
+		Series:       r.series[post.Series],
+		Translations: postTranslations(post),
+		Outputs:      postOutputs(post),
+		Title:        post.Title,
+		Kind:         KindPost,
+		URL:          post.URL,
 	}
 
 	return r.renderToFile("post.html", data, outputPath)
@@ -258,18 +1161,27 @@ func (r *Renderer) renderPost(post *parser.Post, config SiteConfig, outputPath s
 // Called by Build to create the main posts.html page. Creates a
 // PageData struct with all posts and site config, then calls renderToFile with
 // "posts.html" to render base.html + posts.html's {{define "posts"}} block.
+// On a multilingual site, each language gets its own home page, at "/" for
+// the default language and "/<lang>/" for the others.
 //
 // Parameters:
-//   - posts: Slice of all published posts (already filtered and sorted by builder)
+//   - lang: Language of the page ("" if the site isn't multilingual)
+//   - posts: Slice of all published posts in lang (already filtered and sorted by builder)
+//   - featured: Posts selected by featuredPosts, available as .Featured
 //   - config: Site configuration (title, author, etc.) for template rendering
 //   - outputPath: Where to write the HTML file (e.g., "public/posts.html")
 //
 // Returns an error if rendering or file writing fails.
-func (r *Renderer) renderIndex(posts []*parser.Post, config SiteConfig, outputPath string) error {
+func (r *Renderer) renderIndex(lang string, posts, featured []*parser.Post, config SiteConfig, outputPath string) error {
+	site := config.forLanguage(lang)
 	data := PageData{
-		Site:  config,
-		Posts: posts,
-		Title: config.Title,
+		Site:         site,
+		Posts:        posts,
+		Featured:     featured,
+		Translations: indexTranslations(config, lang),
+		Title:        site.Title,
+		Kind:         KindIndex,
+		URL:          config.languageURL(lang, "/"),
 	}
 
 	return r.renderToFile("posts.html", data, outputPath)
@@ -278,12 +1190,11 @@ func (r *Renderer) renderIndex(posts []*parser.Post, config SiteConfig, outputPa
 // renderToFile renders a page by combining base.html with a content template.
 //
 // This is where the template inheritance pattern is implemented:
-//  1. Clones the pre-loaded base.html template (for a fresh copy)
-//  2. Parses the content template file (posts.html or post.html) which contains
-//     a {{define "posts"}} block
-//  3. Executes base.html, which calls {{template "posts" .}} to inject the
+//  1. Gets base.html with the content template (posts.html or post.html),
+//     which contains a {{define "posts"}} block, parsed in (see pageTemplate)
+//  2. Executes base.html, which calls {{template "posts" .}} to inject the
 //     appropriate content block
-//  4. Writes the final HTML to the output file
+//  3. Writes the final HTML to the output file
 //
 // This allows index and post pages to share the same header/footer/nav from base.html
 // while having different main content.
@@ -291,44 +1202,83 @@ func (r *Renderer) renderIndex(posts []*parser.Post, config SiteConfig, outputPa
 // Parameters:
 //   - contentTemplate: Which content template to use ("posts.html" or "post.html")
 //   - data: PageData struct containing site config and post(s) for template variables
-//   - outputPath: Where to write the rendered HTML file
+//   - outputPath: Where to write the rendered HTML file, in the renderer's output FS
+//
+// The HTML is then passed through the renderer's transformer pipeline (see
+// HTMLTransformer), minified if minification is enabled, and run through the
+// post-processors (see PagePostProcessor) before it is written.
 //
 // Returns an error if template cloning, parsing, execution, or file writing fails.
 func (r *Renderer) renderToFile(contentTemplate string, data PageData, outputPath string) error {
-	// Create output directory if it doesn't exist
-	dir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(dir, 0750); err != nil {
-		return fmt.Errorf("creating output directory: %w", err)
+	tmpl, err := r.pageTemplate(contentTemplate)
+	if err != nil {
+		return err
 	}
 
-	// Create output file
-	f, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("creating output file: %w", err)
+	data.Bundles = r.bundles
+	data.Taxonomies = r.taxonomies
+	data.Home = data.Site.languageURL(data.Site.Language, "/")
+	data.Head = r.head(data)
+	data.Icons = r.iconLinks(data.Site)
+	data.Env = r.env
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("executing template: %w", err)
 	}
-	defer f.Close()
 
-	// Parse base.html with the specific content template
-	tmpl, err := r.templates.Lookup("base.html").Clone()
+	out, err := r.finishPage(buf.Bytes(), &data, outputPath)
 	if err != nil {
-		return fmt.Errorf("cloning base template: %w", err)
+		return err
 	}
 
-	// Add the specific content template
-	if _, err := tmpl.ParseFiles(filepath.Join("templates", contentTemplate)); err != nil {
-		return fmt.Errorf("parsing content template: %w", err)
+	if err := r.out.WriteFile(outputPath, out, 0600); err != nil {
+		return fmt.Errorf("writing output file: %w", err)
 	}
+	slog.Debug("Wrote file", "file", outputPath)
 
-	if err := tmpl.Execute(f, data); err != nil {
-		return fmt.Errorf("executing template: %w", err)
+	return nil
+}
+
+// pageTemplate returns base.html with contentTemplate parsed in from the
+// renderer's template filesystem, which was resolved when the renderer was
+// created, so rendering doesn't depend on the working directory. Each
+// content template is parsed once; later pages reuse it, which is safe since
+// executing a template doesn't modify it.
+func (r *Renderer) pageTemplate(contentTemplate string) (*template.Template, error) {
+	r.pagesMu.Lock()
+	defer r.pagesMu.Unlock()
+	if tmpl, ok := r.pages[contentTemplate]; ok {
+		return tmpl, nil
 	}
 
-	return nil
+	tmpl, err := r.templates.Lookup("base.html").Clone()
+	if err != nil {
+		return nil, fmt.Errorf("cloning base template: %w", err)
+	}
+	if _, err := tmpl.ParseFS(r.fs, contentTemplate); err != nil {
+		return nil, fmt.Errorf("parsing content template: %w", err)
+	}
+	if r.pages == nil {
+		r.pages = make(map[string]*template.Template)
+	}
+	r.pages[contentTemplate] = tmpl
+	return tmpl, nil
 }
 
-// loadConfig loads the site configuration from YAML
-func loadConfig(path string) (*SiteConfig, error) {
-	data, err := os.ReadFile(path)
+// LoadConfig loads the site configuration from YAML
+func LoadConfig(path string) (*SiteConfig, error) {
+	return loadConfig(DirFS("."), path)
+}
+
+// LoadConfigFS is LoadConfig reading path from fsys.
+func LoadConfigFS(fsys fs.FS, path string) (*SiteConfig, error) {
+	return loadConfig(fsys, path)
+}
+
+// loadConfig does the work of LoadConfig, reading path from fsys.
+func loadConfig(fsys fs.FS, path string) (*SiteConfig, error) {
+	data, err := fs.ReadFile(fsys, path)
 	if err != nil {
 		return nil, err
 	}
@@ -341,45 +1291,243 @@ func loadConfig(path string) (*SiteConfig, error) {
 	return &config, nil
 }
 
+// LoadConfigEnv loads the site configuration from YAML, then merges the
+// overlay for env over it, so settings like baseUrl can differ per
+// environment.
+//
+// The overlay sits next to the base config and is named after the
+// environment: config.production.yaml or config.prod.yaml for production,
+// config.development.yaml or config.dev.yaml for development. Fields set in
+// the overlay replace the base config's; lists are replaced, not appended to,
+// and maps (like funcs) gain the overlay's keys. A missing overlay is not an
+// error.
+//
+// Parameters:
+//   - path: Path to the base config (e.g., "config.yaml")
+//   - env: Build environment (e.g., EnvProduction); empty loads only the base config
+//
+// Returns the merged config, or an error if either file can't be read or parsed.
+func LoadConfigEnv(path, env string) (*SiteConfig, error) {
+	return loadConfigEnv(DirFS("."), path, env)
+}
+
+// loadConfigEnv does the work of LoadConfigEnv, reading the config and its
+// overlay from fsys.
+func loadConfigEnv(fsys fs.FS, path, env string) (*SiteConfig, error) {
+	config, err := loadConfig(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	if env == "" {
+		return config, nil
+	}
+
+	for _, overlay := range configOverlayPaths(path, env) {
+		data, err := fs.ReadFile(fsys, overlay)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", overlay, err)
+		}
+		break
+	}
+	return config, nil
+}
+
+// configOverlayPaths returns the paths the overlay for env may have, in order
+// of preference: the full environment name, then its short alias.
+func configOverlayPaths(path, env string) []string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	paths := []string{base + "." + env + ext}
+	for alias, name := range envAliases {
+		if name == env {
+			paths = append(paths, base+"."+alias+ext)
+		}
+	}
+	return paths
+}
+
+// LoadPosts returns the site's published posts, newest first, with their URLs
+// assigned from the permalink config. Drafts, posts dated in the future, and
+// expired posts are excluded.
+//
+// Posts are read from content/posts relative to the current directory, and
+// on a multilingual site from content/<lang> for each of its languages (see
+// LanguageConfig). Posts in languages other than the default one get their
+// URLs under /<lang>, are linked with Prev and Next to the posts in their
+// own language, and with Translations to their translations.
+//
+// Returns an error if a post fails to parse or the permalink is invalid.
+func LoadPosts(config *SiteConfig) ([]*parser.Post, error) {
+	return loadPosts(DirFS("."), newParser(config), config, false, false, false)
+}
+
+// LoadPostsFS is LoadPosts reading the posts from fsys.
+func LoadPostsFS(fsys fs.FS, config *SiteConfig) ([]*parser.Post, error) {
+	return loadPosts(fsys, newParser(config), config, false, false, false)
+}
+
+// loadPosts does the work of LoadPosts with the given parser, reading the
+// posts from fsys and optionally keeping drafts, future-dated, and expired
+// posts.
+func loadPosts(fsys fs.FS, p *parser.Parser, config *SiteConfig, drafts, future, expired bool) ([]*parser.Post, error) {
+	if err := config.checkLanguages(); err != nil {
+		return nil, err
+	}
+	// Parse every content directory before failing, to report all bad posts
+	var errs []error
+	posts, err := parseAllPosts(fsys, p, "content/posts")
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, lang := range config.siteLanguages() {
+		dir := filepath.Join("content", lang)
+		langPosts, err := parseAllPosts(fsys, p, dir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, post := range langPosts {
+			if post.Lang == "" {
+				post.Lang = lang
+			}
+		}
+		posts = append(posts, langPosts...)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("parsing posts:\n%w", errors.Join(errs...))
+	}
+	if err := assignLanguages(posts, config); err != nil {
+		return nil, err
+	}
+
+	published := publishPosts(posts, drafts, future, expired)
+
+	// Assign post URLs from the permalink pattern
+	if err := checkURLStyle(config.URLs); err != nil {
+		return nil, err
+	}
+	if err := assignPostURLs(published, config.Permalink, config.URLs); err != nil {
+		return nil, err
+	}
+
+	if langs := config.siteLanguages(); langs != nil {
+		for _, post := range published {
+			post.URL = config.languageURL(post.Lang, post.URL)
+		}
+		for _, lang := range langs {
+			linkNeighbors(postsInLanguage(published, lang))
+		}
+		linkTranslations(published, config)
+	}
+	if err := assignResources(fsys, published); err != nil {
+		return nil, err
+	}
+	if err := assignAttachments(fsys, published); err != nil {
+		return nil, err
+	}
+
+	return published, nil
+}
+
+// publishPosts filters drafts, future posts, and expired posts out of posts,
+// unless drafts, future, or expired is set, sorts the rest by date (newest
+// first), and links each to its neighbors with Prev and Next.
+func publishPosts(posts []*parser.Post, drafts, future, expired bool) []*parser.Post {
+	published := posts
+	if !drafts {
+		published = filterDrafts(published)
+	}
+	if !future {
+		published = filterFuture(published, time.Now())
+	}
+	if !expired {
+		published = filterExpired(published, time.Now())
+	}
+
+	sort.Slice(published, func(i, j int) bool {
+		return published[i].Date.After(published[j].Date)
+	})
+	linkNeighbors(published)
+	return published
+}
+
+// linkNeighbors links each of posts (sorted newest first) to its neighbors
+// with Prev and Next.
+func linkNeighbors(posts []*parser.Post) {
+	for i, post := range posts {
+		post.Prev, post.Next = nil, nil
+		if i > 0 {
+			post.Next = posts[i-1]
+		}
+		if i < len(posts)-1 {
+			post.Prev = posts[i+1]
+		}
+	}
+}
+
 // parseAllPosts parses all markdown files in a directory using the provided parser.
 //
-// Scans the directory for .md files and calls parser.ParseFile on each one.
-// Returns an empty slice if the directory doesn't exist (not an error).
+// Scans the directory for content files and page bundles (see contentPaths)
+// and parses each one (see parseFile), setting the Bundle of those from page
+// bundles. Returns an empty slice if the directory doesn't exist (not an
+// error).
 //
 // Parameters:
+//   - fsys: Filesystem the site is read from
 //   - p: Parser instance to use for markdown conversion
 //   - dir: Directory path containing markdown files (e.g., "content/posts")
 //
-// Returns a slice of parsed Post structs or an error if parsing fails.
-func parseAllPosts(p *parser.Parser, dir string) ([]*parser.Post, error) {
+// Returns a slice of parsed Post structs, or an error listing every post that
+// failed to parse, one per line, so they can all be fixed at once.
+func parseAllPosts(fsys fs.FS, p *parser.Parser, dir string) ([]*parser.Post, error) {
 	var posts []*parser.Post
 
-	entries, err := os.ReadDir(dir)
+	// A missing directory has no posts
+	paths, err := contentPaths(fsys, dir, p.Handles)
 	if err != nil {
-		// If directory doesn't exist, return empty slice
-		if os.IsNotExist(err) {
-			return posts, nil
-		}
 		return nil, err
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+	var errs []error
+	for _, path := range paths {
+		post, err := parseFile(fsys, p, path)
+		var fmErr *parser.FrontmatterError
+		switch {
+		case errors.As(err, &fmErr):
+			errs = append(errs, err) // Already names the file
+			continue
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
 			continue
 		}
-
-		path := filepath.Join(dir, entry.Name())
-		post, err := p.ParseFile(path)
-		if err != nil {
-			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		if filepath.Dir(path) != dir {
+			post.Bundle = filepath.Dir(path)
 		}
 
 		posts = append(posts, post)
 	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
 
 	return posts, nil
 }
 
+// parseFile parses the markdown file at path in fsys, like parser.ParseFile.
+func parseFile(fsys fs.FS, p *parser.Parser, path string) (*parser.Post, error) {
+	content, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return p.Parse(content, path)
+}
+
 // filterDrafts removes draft posts from the list based on the "draft" frontmatter field.
 //
 // Posts with draft: true in their frontmatter are excluded from the published site.
@@ -398,24 +1546,72 @@ func filterDrafts(posts []*parser.Post) []*parser.Post {
 	return published
 }
 
+// filterFuture removes posts dated after now, so posts can be scheduled by
+// giving them a future date and rebuilding once it has passed. A post's
+// publishDate, if set, is used instead of its date.
+func filterFuture(posts []*parser.Post, now time.Time) []*parser.Post {
+	var published []*parser.Post
+	for _, post := range posts {
+		if !publishTime(post).After(now) {
+			published = append(published, post)
+		}
+	}
+	return published
+}
+
+// filterExpired removes posts whose expiryDate has passed, so time-limited
+// posts like announcements drop out of the first build after it.
+func filterExpired(posts []*parser.Post, now time.Time) []*parser.Post {
+	var published []*parser.Post
+	for _, post := range posts {
+		if !expired(post, now) {
+			published = append(published, post)
+		}
+	}
+	return published
+}
+
+// publishTime returns when post goes live: its publishDate, or its date if
+// that isn't set.
+func publishTime(post *parser.Post) time.Time {
+	if !post.PublishDate.IsZero() {
+		return post.PublishDate
+	}
+	return post.Date
+}
+
+// expired reports whether post's expiryDate is set and not after now.
+func expired(post *parser.Post, now time.Time) bool {
+	return !post.ExpiryDate.IsZero() && !post.ExpiryDate.After(now)
+}
+
 // copyStatic recursively copies static assets (CSS, images, etc.) to the output directory.
 //
 // Walks the source directory tree and copies all files and directories to the destination,
 // preserving directory structure and file permissions. Returns nil if source doesn't exist.
+// Files over the size limits are warned about, or skipped if over limits.max.
 //
 // Parameters:
+//   - src: Filesystem the site is read from
+//   - out: Filesystem the site is written to
 //   - srcDir: Source directory containing static files (e.g., "static")
 //   - dstDir: Destination directory in the output (e.g., "public")
+//   - minify: Whether to minify CSS, JS, and HTML files while copying
+//   - limits: Size thresholds for warning about and skipping files
 //
 // Returns an error if copying fails.
-func copyStatic(srcDir, dstDir string) error {
+func copyStatic(src fs.FS, out FS, srcDir, dstDir string, minify bool, limits sizeLimits) error {
 	// Check if static directory exists
-	if _, err := os.Stat(srcDir); os.IsNotExist(err) {
+	if _, err := fs.Stat(src, srcDir); errors.Is(err, fs.ErrNotExist) {
 		// No static files, that's OK
 		return nil
 	}
 
-	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+	return fs.WalkDir(src, srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
 		if err != nil {
 			return err
 		}
@@ -431,15 +1627,25 @@ func copyStatic(srcDir, dstDir string) error {
 
 		if info.IsDir() {
 			// Create directory
-			return os.MkdirAll(dstPath, info.Mode())
+			return out.MkdirAll(dstPath, info.Mode())
+		}
+
+		if !limits.check(path, info.Size()) {
+			return nil
 		}
 
 		// Copy file
-		data, err := os.ReadFile(path)
+		data, err := fs.ReadFile(src, path)
 		if err != nil {
 			return err
 		}
 
-		return os.WriteFile(dstPath, data, info.Mode())
+		if minify {
+			if fn := minifyFor(path); fn != nil {
+				data = fn(data)
+			}
+		}
+
+		return out.WriteFile(dstPath, data, info.Mode())
 	})
 }
@@ -1,8 +1,12 @@
 package ssg
 
 import (
+	"context"
+	"errors"
+	"html/template"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +14,32 @@ import (
 	"github.com/kvnloughead/ssg/internal/parser"
 )
 
+// writeFiles writes each file (slash-separated path relative to dir → content)
+// under dir, creating parent directories as needed.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// testSite returns the files of a minimal site with one published post.
+func testSite() map[string]string {
+	return map[string]string{
+		"config.yaml":                       "title: Test Blog\nbaseUrl: https://test.com\nauthor: Test Author\n",
+		"templates/base.html":               "<html>\n<head><title>{{.Title}}</title></head>\n<body>\n{{template \"posts\" .}}\n</body>\n</html>\n",
+		"templates/posts.html":              "{{define \"posts\"}}{{range .Posts}}<a href=\"/posts/{{.Slug}}.html\">{{.Title}}</a>\n{{end}}{{end}}",
+		"templates/post.html":               "{{define \"posts\"}}<article>\n<h1>{{.Post.Title}}</h1>\n{{.Post.Content}}\n</article>{{end}}",
+		"content/posts/2024-01-15-first.md": "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\nHello from the first post.\n",
+	}
+}
+
 // TestBuild tests the full Build function
 func TestBuild(t *testing.T) {
 	// Create temporary directories
@@ -118,7 +148,7 @@ Draft content.
 	}
 
 	// Run build
-	err = Build(configPath, outputDir)
+	err = Build(context.Background(), BuildOptions{ConfigPath: configPath, OutputDir: outputDir})
 	if err != nil {
 		t.Fatalf("Build() failed: %v", err)
 	}
@@ -181,7 +211,7 @@ func TestNewPost(t *testing.T) {
 
 	// Create new post
 	title := "My Test Post"
-	err = NewPost(title)
+	_, err = NewPost(NewPostOptions{Title: title})
 	if err != nil {
 		t.Fatalf("NewPost() failed: %v", err)
 	}
@@ -244,7 +274,7 @@ func TestNewPost_SlugGeneration(t *testing.T) {
 			defer os.Chdir(origDir)
 			os.Chdir(tmpDir)
 
-			err := NewPost(tt.title)
+			_, err := NewPost(NewPostOptions{Title: tt.title})
 			if err != nil {
 				t.Fatalf("NewPost() failed: %v", err)
 			}
@@ -285,6 +315,56 @@ func TestFilterDrafts(t *testing.T) {
 	}
 }
 
+// TestPublishPosts tests publishDate and expiryDate filtering
+func TestPublishPosts(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	posts := []*parser.Post{
+		{Title: "Plain", Date: now.Add(-3 * day)},
+		{Title: "Scheduled", Date: now.Add(-2 * day), PublishDate: now.Add(day)},
+		{Title: "Published early", Date: now.Add(day), PublishDate: now.Add(-day)},
+		{Title: "Expired", Date: now.Add(-2 * day), ExpiryDate: now.Add(-day)},
+		{Title: "Expiring", Date: now.Add(-2 * day), ExpiryDate: now.Add(day)},
+	}
+	titles := func(posts []*parser.Post) []string {
+		var s []string
+		for _, p := range posts {
+			s = append(s, p.Title)
+		}
+		return s
+	}
+
+	got := titles(publishPosts(slices.Clone(posts), false, false, false))
+	want := []string{"Published early", "Expiring", "Plain"}
+	if !slices.Equal(got, want) {
+		t.Errorf("publishPosts() = %v, want %v", got, want)
+	}
+	if got := titles(publishPosts(slices.Clone(posts), false, true, true)); len(got) != len(posts) {
+		t.Errorf("publishPosts(future, expired) = %v, want every post", got)
+	}
+}
+
+// TestPublishPosts_PrevNext tests that posts link to their chronological
+// neighbors
+func TestPublishPosts_PrevNext(t *testing.T) {
+	now := time.Now()
+	oldest := &parser.Post{Title: "Oldest", Date: now.Add(-3 * time.Hour)}
+	middle := &parser.Post{Title: "Middle", Date: now.Add(-2 * time.Hour)}
+	newest := &parser.Post{Title: "Newest", Date: now.Add(-time.Hour)}
+	draft := &parser.Post{Title: "Draft", Date: now.Add(-90 * time.Minute), Draft: true}
+
+	publishPosts([]*parser.Post{middle, newest, draft, oldest}, false, false, false)
+	if oldest.Prev != nil || oldest.Next != middle {
+		t.Errorf("Oldest: Prev = %v, Next = %v", oldest.Prev, oldest.Next)
+	}
+	if middle.Prev != oldest || middle.Next != newest {
+		t.Errorf("Middle: Prev = %v, Next = %v, want Oldest and Newest (skipping the draft)", middle.Prev, middle.Next)
+	}
+	if newest.Prev != middle || newest.Next != nil {
+		t.Errorf("Newest: Prev = %v, Next = %v", newest.Prev, newest.Next)
+	}
+}
+
 // TestParseAllPosts tests parsing multiple posts
 func TestParseAllPosts(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -340,7 +420,7 @@ Content 3`,
 	}
 
 	p := parser.New()
-	parsed, err := parseAllPosts(p, postsDir)
+	parsed, err := parseAllPosts(DirFS("."), p, postsDir)
 	if err != nil {
 		t.Fatalf("parseAllPosts() failed: %v", err)
 	}
@@ -350,6 +430,38 @@ Content 3`,
 	}
 }
 
+// TestParseAllPosts_ReportsAllErrors tests that every bad post is reported,
+// with its position, instead of only the first
+func TestParseAllPosts_ReportsAllErrors(t *testing.T) {
+	postsDir := t.TempDir()
+	files := map[string]string{
+		"a-missing.md":   "No frontmatter",
+		"b-good.md":      "---\ntitle: Good\n---\nFine",
+		"c-malformed.md": "---\ntitle: Bad\ndate: soon\n---\nBroken",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(postsDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := parseAllPosts(DirFS("."), parser.New(), postsDir)
+	if err == nil {
+		t.Fatal("parseAllPosts() succeeded, want error")
+	}
+	if !errors.Is(err, parser.ErrNoFrontmatter) {
+		t.Errorf("error doesn't wrap ErrNoFrontmatter: %v", err)
+	}
+	for _, want := range []string{
+		filepath.Join(postsDir, "a-missing.md") + ":1: missing frontmatter",
+		filepath.Join(postsDir, "c-malformed.md") + ":3:7: malformed frontmatter",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want it to contain %q", err, want)
+		}
+	}
+}
+
 // TestParseAllPosts_EmptyDirectory tests parsing an empty directory
 func TestParseAllPosts_EmptyDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -359,7 +471,7 @@ func TestParseAllPosts_EmptyDirectory(t *testing.T) {
 	}
 
 	p := parser.New()
-	parsed, err := parseAllPosts(p, postsDir)
+	parsed, err := parseAllPosts(DirFS("."), p, postsDir)
 	if err != nil {
 		t.Fatalf("parseAllPosts() failed: %v", err)
 	}
@@ -372,7 +484,7 @@ func TestParseAllPosts_EmptyDirectory(t *testing.T) {
 // TestParseAllPosts_NonExistentDirectory tests parsing a non-existent directory
 func TestParseAllPosts_NonExistentDirectory(t *testing.T) {
 	p := parser.New()
-	parsed, err := parseAllPosts(p, "/nonexistent/path")
+	parsed, err := parseAllPosts(DirFS("."), p, "/nonexistent/path")
 	if err != nil {
 		t.Fatalf("parseAllPosts() should not error on non-existent dir: %v", err)
 	}
@@ -397,9 +509,9 @@ keywords: golang, blog
 		t.Fatal(err)
 	}
 
-	config, err := loadConfig(configPath)
+	config, err := LoadConfig(configPath)
 	if err != nil {
-		t.Fatalf("loadConfig() failed: %v", err)
+		t.Fatalf("LoadConfig() failed: %v", err)
 	}
 
 	if config.Title != "My Blog" {
@@ -419,11 +531,76 @@ keywords: golang, blog
 	}
 }
 
+// TestBuild_Params tests that nested params from the config reach templates
+func TestBuild_Params(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "params:\n  analyticsId: UA-123\n  social:\n    github: octocat\n  tags: [a, b]\n"
+	site["templates/posts.html"] = "{{define \"posts\"}}{{.Site.Params.analyticsId}} {{.Site.Params.social.github}} {{index .Site.Params.tags 1}}{{end}}"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join("public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "UA-123 octocat b") {
+		t.Errorf("index missing params, got:\n%s", index)
+	}
+}
+
+// TestLoadConfigEnv tests merging environment overlays over the base config
+func TestLoadConfigEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"config.yaml":            "title: My Blog\nbaseUrl: https://example.com\nfuncs:\n  a: A\n",
+		"config.dev.yaml":        "baseUrl: http://localhost:8080\nfuncs:\n  b: B\n",
+		"config.production.yaml": "minify: true\n",
+	})
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	config, err := LoadConfigEnv(configPath, EnvDevelopment)
+	if err != nil {
+		t.Fatalf("LoadConfigEnv() failed: %v", err)
+	}
+	if config.Title != "My Blog" || config.BaseURL != "http://localhost:8080" || config.Minify {
+		t.Errorf("development config = %+v", config)
+	}
+	if config.Funcs["a"] != "A" || config.Funcs["b"] != "B" {
+		t.Errorf("Funcs = %v, want keys from both files", config.Funcs)
+	}
+
+	config, err = LoadConfigEnv(configPath, EnvProduction)
+	if err != nil {
+		t.Fatalf("LoadConfigEnv() failed: %v", err)
+	}
+	if config.BaseURL != "https://example.com" || !config.Minify {
+		t.Errorf("production config = %+v", config)
+	}
+
+	config, err = LoadConfigEnv(configPath, "staging")
+	if err != nil {
+		t.Fatalf("LoadConfigEnv() without an overlay failed: %v", err)
+	}
+	if config.BaseURL != "https://example.com" {
+		t.Errorf("BaseURL = %q, want the base config's", config.BaseURL)
+	}
+
+	writeFiles(t, tmpDir, map[string]string{"config.staging.yaml": "title: [unclosed\n"})
+	if _, err := LoadConfigEnv(configPath, "staging"); err == nil || !strings.Contains(err.Error(), "config.staging.yaml") {
+		t.Errorf("LoadConfigEnv() error = %v, want an error naming the overlay", err)
+	}
+}
+
 // TestLoadConfig_NonExistent tests loading a non-existent config file
 func TestLoadConfig_NonExistent(t *testing.T) {
-	_, err := loadConfig("/nonexistent/config.yaml")
+	_, err := LoadConfig("/nonexistent/config.yaml")
 	if err == nil {
-		t.Error("loadConfig() succeeded, want error")
+		t.Error("LoadConfig() succeeded, want error")
 	}
 }
 
@@ -439,9 +616,9 @@ description: [unclosed bracket
 		t.Fatal(err)
 	}
 
-	_, err := loadConfig(configPath)
+	_, err := LoadConfig(configPath)
 	if err == nil {
-		t.Error("loadConfig() succeeded with invalid YAML, want error")
+		t.Error("LoadConfig() succeeded with invalid YAML, want error")
 	}
 }
 
@@ -474,7 +651,7 @@ func TestCopyStatic(t *testing.T) {
 	}
 
 	// Copy static files
-	err := copyStatic(srcDir, dstDir)
+	err := copyStatic(DirFS("."), DirFS("."), srcDir, dstDir, false, sizeLimits{})
 	if err != nil {
 		t.Fatalf("copyStatic() failed: %v", err)
 	}
@@ -501,7 +678,7 @@ func TestCopyStatic(t *testing.T) {
 // TestCopyStatic_NonExistentSource tests copying from non-existent directory
 func TestCopyStatic_NonExistentSource(t *testing.T) {
 	tmpDir := t.TempDir()
-	err := copyStatic("/nonexistent", tmpDir)
+	err := copyStatic(DirFS("."), DirFS("."), "/nonexistent", tmpDir, false, sizeLimits{})
 	if err != nil {
 		t.Errorf("copyStatic() with non-existent source should not error, got: %v", err)
 	}
@@ -535,7 +712,7 @@ func TestRenderer_Integration(t *testing.T) {
 	}
 
 	// Create renderer
-	r, err := newRenderer(templatesDir)
+	r, err := newRenderer(DirFS("."), templatesDir, "", nil)
 	if err != nil {
 		t.Fatalf("newRenderer() failed: %v", err)
 	}
@@ -555,11 +732,6 @@ func TestRenderer_Integration(t *testing.T) {
 
 	outputPath := filepath.Join(outputDir, "test.html")
 
-	// Change to temp directory so renderToFile can find templates
-	origDir, _ := os.Getwd()
-	defer os.Chdir(origDir)
-	os.Chdir(tmpDir)
-
 	// Render post
 	err = r.renderPost(testPost, config, outputPath)
 	if err != nil {
@@ -580,3 +752,188 @@ func TestRenderer_Integration(t *testing.T) {
 		t.Error("Rendered HTML doesn't contain post content")
 	}
 }
+
+// TestNewRenderer_Partials tests that templates/partials/ files are available by name
+func TestNewRenderer_Partials(t *testing.T) {
+	tmpDir := t.TempDir()
+	templatesDir := filepath.Join(tmpDir, "templates")
+	partialsDir := filepath.Join(templatesDir, "partials", "social")
+	if err := os.MkdirAll(partialsDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"base.html":                  `<html><body>{{template "nav" .}}{{template "social/links" .}}{{template "posts" .}}</body></html>`,
+		"post.html":                  `{{define "posts"}}<article>{{.Post.Title}}</article>{{end}}`,
+		"partials/nav.html":          `<nav>{{.Site.Title}}</nav>`,
+		"partials/social/links.html": `<a href="https://example.com/{{.Site.Author}}">me</a>`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(templatesDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := newRenderer(DirFS("."), templatesDir, "", nil)
+	if err != nil {
+		t.Fatalf("newRenderer() failed: %v", err)
+	}
+
+	outputPath := filepath.Join(tmpDir, "out", "post.html")
+	post := &parser.Post{Title: "Partial Post", Slug: "partial-post"}
+	if err := r.renderPost(post, SiteConfig{Title: "My Site", Author: "me"}, outputPath); err != nil {
+		t.Fatalf("renderPost() failed: %v", err)
+	}
+
+	html, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<html><body><nav>My Site</nav><a href="https://example.com/me">me</a><article>Partial Post</article></body></html>`
+	if string(html) != want {
+		t.Errorf("rendered HTML = %q, want %q", html, want)
+	}
+}
+
+// TestRenderer_OtherDirectory tests that content templates are found without
+// the site being the working directory, and parsed only once
+func TestRenderer_OtherDirectory(t *testing.T) {
+	siteDir := t.TempDir()
+	writeFiles(t, siteDir, map[string]string{
+		"templates/base.html": `<html><body>{{template "posts" .}}</body></html>`,
+		"templates/post.html": `{{define "posts"}}<article>{{.Post.Title}}</article>{{end}}`,
+	})
+	t.Chdir(t.TempDir())
+
+	r, err := newRenderer(DirFS(siteDir), "templates", "", nil)
+	if err != nil {
+		t.Fatalf("newRenderer() failed: %v", err)
+	}
+	out := &MemFS{}
+	r.out = out
+	post := &parser.Post{Title: "Elsewhere", Slug: "elsewhere"}
+	if err := r.renderPost(post, SiteConfig{}, "public/first.html"); err != nil {
+		t.Fatalf("renderPost() failed: %v", err)
+	}
+
+	// A later page reuses the parsed template rather than reading it again
+	if err := os.Remove(filepath.Join(siteDir, "templates", "post.html")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.renderPost(post, SiteConfig{}, "public/second.html"); err != nil {
+		t.Fatalf("renderPost() after the template was removed failed: %v", err)
+	}
+	html, err := out.ReadFile("public/second.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<html><body><article>Elsewhere</article></body></html>"; string(html) != want {
+		t.Errorf("page = %s, want %s", html, want)
+	}
+}
+
+// TestPrepareServe tests that serve builds first, to disk or memory, and
+// explains missing content
+func TestPrepareServe(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	if err := prepareServe(ServeOptions{}.withDefaults()); err == nil || !strings.Contains(err.Error(), "config.yaml not found") {
+		t.Errorf("prepareServe() without config = %v, want config not found error", err)
+	}
+	if err := prepareServe(ServeOptions{NoBuild: true}.withDefaults()); err == nil || !strings.Contains(err.Error(), "ssg build") {
+		t.Errorf("prepareServe() without build = %v, want missing output error", err)
+	}
+
+	writeFiles(t, tmpDir, map[string]string{"config.yaml": "title: Test Blog\n"})
+	if err := prepareServe(ServeOptions{}.withDefaults()); err == nil || !strings.Contains(err.Error(), "ssg new") {
+		t.Errorf("prepareServe() without content = %v, want missing content error", err)
+	}
+
+	writeFiles(t, tmpDir, testSite())
+	mem := &MemFS{}
+	if err := prepareServe(ServeOptions{InMemory: true, output: mem}.withDefaults()); err != nil {
+		t.Fatalf("prepareServe() in memory failed: %v", err)
+	}
+	if _, err := mem.Stat("public/index.html"); err != nil {
+		t.Errorf("prepareServe() in memory didn't build the site: %v", err)
+	}
+	if _, err := os.Stat("public"); err == nil {
+		t.Error("prepareServe() in memory wrote the output to disk")
+	}
+
+	if err := prepareServe(ServeOptions{}.withDefaults()); err != nil {
+		t.Fatalf("prepareServe() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("public", "index.html")); err != nil {
+		t.Errorf("prepareServe() didn't build the site: %v", err)
+	}
+}
+
+// TestBuild_Formats tests building posts in other markdown extensions and
+// formats converted by commands
+func TestBuild_Formats(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "formats:\n  .txt: \"sed 's/.*/<p class=txt>&<\\\\/p>/'\"\n"
+	site["content/posts/2024-01-16-second.markdown"] = "---\ntitle: Second\ndate: 2024-01-16T10:00:00Z\n---\n\n*Markdown* too.\n"
+	site["content/posts/2024-01-17-third.txt"] = "---\ntitle: Third\ndate: 2024-01-17T10:00:00Z\n---\n\nPlain text.\n"
+	site["content/notes/vim.txt"] = "---\ntitle: Vim\n---\n\nA note.\n"
+	site["content/posts/notes.org"] = "Not content."
+	site["content/posts/2024-01-18-fourth.rst"] = "---\ntitle: Fourth\ndate: 2024-01-18T10:00:00Z\n---\n\nRegistered.\n"
+	RegisterContentParser("rst", parser.ContentParserFunc(func(body []byte, path string) (template.HTML, error) {
+		return template.HTML("<pre>" + string(body) + "</pre>"), nil
+	}))
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	for path, want := range map[string]string{
+		"public/posts/second.html": "<em>Markdown</em> too.",
+		"public/posts/third.html":  "<p class=txt>Plain text.</p>",
+		"public/notes/vim.html":    "<p class=txt>A note.</p>",
+		"public/posts/fourth.html": "<pre>Registered.</pre>",
+	} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Errorf("%s missing %q:\n%s", path, want, content)
+		}
+	}
+}
+
+// TestBuild_Notebooks tests publishing Jupyter notebooks as posts
+func TestBuild_Notebooks(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		tmpDir := t.TempDir()
+		site := testSite()
+		if !enabled {
+			site["config.yaml"] += "notebooks: false\n"
+		}
+		site["content/posts/2024-02-01-plot.ipynb"] = `{"nbformat": 4, "metadata": {"title": "Plot"}, "cells": [
+  {"cell_type": "code", "source": "print(2)", "outputs": [{"output_type": "stream", "name": "stdout", "text": "2\n"}]}]}`
+		writeFiles(t, tmpDir, site)
+		t.Chdir(tmpDir)
+
+		if err := Build(context.Background(), BuildOptions{}); err != nil {
+			t.Fatalf("Build() failed: %v", err)
+		}
+		page, err := os.ReadFile(filepath.Join("public", "posts", "plot.html"))
+		if !enabled {
+			if err == nil {
+				t.Error("notebook published with notebooks: false")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(page), "<h1>Plot</h1>") || !strings.Contains(string(page), "<pre class=\"notebook-stream\">2\n</pre>") {
+			t.Errorf("notebook page missing its title or output:\n%s", page)
+		}
+	}
+}
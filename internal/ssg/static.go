@@ -0,0 +1,113 @@
+package ssg
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultWarnSize is the size above which copied static files are warned
+// about when static.warnSize isn't set.
+const defaultWarnSize = 25 << 20
+
+// StaticConfig sets size limits for files copied from static/, so a stray
+// video or archive doesn't silently bloat the output.
+//
+// Example config.yaml:
+//
+//	static:
+//	  warnSize: 10MB
+//	  maxSize: 100MB
+type StaticConfig struct {
+	WarnSize string `yaml:"warnSize"` // Warn about larger files (default: 25MB; "0" disables)
+	MaxSize  string `yaml:"maxSize"`  // Skip larger files instead of copying them (default: no limit)
+}
+
+// sizeLimits are the parsed StaticConfig thresholds in bytes. Zero means no
+// limit.
+type sizeLimits struct {
+	warn, max int64
+}
+
+// limits returns the parsed size thresholds, or an error if one is invalid.
+func (c StaticConfig) limits() (sizeLimits, error) {
+	var l sizeLimits
+	var err error
+	l.warn = defaultWarnSize
+	if c.WarnSize != "" {
+		if l.warn, err = parseSize(c.WarnSize); err != nil {
+			return l, fmt.Errorf("static.warnSize: %w", err)
+		}
+	}
+	if c.MaxSize != "" {
+		if l.max, err = parseSize(c.MaxSize); err != nil {
+			return l, fmt.Errorf("static.maxSize: %w", err)
+		}
+	}
+	return l, nil
+}
+
+// check reports whether a static file of the given size should be copied,
+// printing a warning with a suggestion if it's over a threshold.
+func (l sizeLimits) check(path string, size int64) bool {
+	switch {
+	case l.max > 0 && size > l.max:
+		slog.Warn("skipping file over static.maxSize", "file", path, "size", formatSize(size), "hint", largeFileHint(path))
+		return false
+	case l.warn > 0 && size > l.warn:
+		slog.Warn("large static file", "file", path, "size", formatSize(size), "hint", largeFileHint(path))
+	}
+	return true
+}
+
+// parseSize parses a size like "512KB", "10MB", "1GB", or a plain number of
+// bytes. Units are binary (1KB = 1024 bytes).
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, mult = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q, want e.g. 10MB", s)
+	}
+	return n * mult, nil
+}
+
+// formatSize formats a byte count for messages, e.g. "12.3 MB".
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	default:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+}
+
+// largeFileHint suggests where a large static file belongs instead, based on
+// its type.
+func largeFileHint(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp4", ".mov", ".webm", ".mkv", ".avi", ".m4v":
+		return "host videos on object storage (e.g., S3 or R2) or a video service and link to them"
+	case ".jpg", ".jpeg", ".png", ".gif", ".tif", ".tiff", ".bmp", ".psd":
+		return "resize or compress the image, or let images: generate smaller variants"
+	case ".zip", ".gz", ".tgz", ".tar", ".7z", ".rar", ".dmg", ".iso", ".exe", ".pkg":
+		return "publish downloads as release assets or on object storage and link to them"
+	case ".wav", ".flac", ".aiff", ".mp3":
+		return "compress the audio or host it on object storage"
+	default:
+		return "move it to object storage (e.g., S3 or R2) and link to it"
+	}
+}
@@ -0,0 +1,76 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParseSize tests parsing sizes with and without units
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"1024":  1024,
+		"512KB": 512 << 10,
+		"10 MB": 10 << 20,
+		"1gb":   1 << 30,
+		"100B":  100,
+		"0":     0,
+	}
+	for in, want := range tests {
+		got, err := parseSize(in)
+		if err != nil || got != want {
+			t.Errorf("parseSize(%q) = %d, %v, want %d", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "ten MB", "-1", "1.5MB"} {
+		if _, err := parseSize(in); err == nil {
+			t.Errorf("parseSize(%q) succeeded, want error", in)
+		}
+	}
+}
+
+// TestBuild_StaticMaxSize tests that static files over maxSize are skipped
+func TestBuild_StaticMaxSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "static:\n  maxSize: 1KB\n"
+	site["static/video/intro.mp4"] = strings.Repeat("x", 2048)
+	site["static/robots.txt"] = "User-agent: *\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join("public", "video", "intro.mp4")); !os.IsNotExist(err) {
+		t.Errorf("file over maxSize was copied (err = %v)", err)
+	}
+	if _, err := os.Stat(filepath.Join("public", "robots.txt")); err != nil {
+		t.Errorf("small file not copied: %v", err)
+	}
+}
+
+// TestStaticConfig_Limits tests defaults and invalid thresholds
+func TestStaticConfig_Limits(t *testing.T) {
+	l, err := StaticConfig{}.limits()
+	if err != nil || l.warn != defaultWarnSize || l.max != 0 {
+		t.Errorf("limits() = %+v, %v, want default warn and no max", l, err)
+	}
+	if _, err := (StaticConfig{MaxSize: "lots"}).limits(); err == nil || !strings.Contains(err.Error(), "static.maxSize") {
+		t.Errorf("limits() error = %v, want a static.maxSize error", err)
+	}
+}
+
+// TestLargeFileHint tests suggestions for large files by type
+func TestLargeFileHint(t *testing.T) {
+	if got := largeFileHint("static/intro.MP4"); !strings.Contains(got, "video") {
+		t.Errorf("largeFileHint(mp4) = %q, want a video suggestion", got)
+	}
+	if got := largeFileHint("static/data.bin"); !strings.Contains(got, "object storage") {
+		t.Errorf("largeFileHint(bin) = %q, want an object storage suggestion", got)
+	}
+}
@@ -0,0 +1,42 @@
+package ssg
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// SiteStats summarizes a site's published posts for templates, available as
+// .Site.Stats, e.g.
+//
+//	{{ .Site.Stats.Posts }} posts since {{ .Site.Stats.FirstPost.Year }}
+//
+// Drafts and future posts count only in builds that publish them.
+type SiteStats struct {
+	Posts     int       // Number of published posts
+	Words     int       // Words in all published posts, not counting markup
+	Tags      int       // Number of distinct tags
+	FirstPost time.Time // Date of the oldest post (zero if there are none)
+	LastPost  time.Time // Date of the newest post (zero if there are none)
+}
+
+// computeStats returns the SiteStats of posts.
+func computeStats(posts []*parser.Post) SiteStats {
+	stats := SiteStats{Posts: len(posts)}
+	tags := make(map[string]bool)
+	for _, post := range posts {
+		stats.Words += len(strings.Fields(htmlText(string(post.Content))))
+		for _, tag := range post.Tags {
+			tags[tag] = true
+		}
+		if stats.FirstPost.IsZero() || post.Date.Before(stats.FirstPost) {
+			stats.FirstPost = post.Date
+		}
+		if post.Date.After(stats.LastPost) {
+			stats.LastPost = post.Date
+		}
+	}
+	stats.Tags = len(tags)
+	return stats
+}
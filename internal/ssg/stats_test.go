@@ -0,0 +1,53 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestComputeStats tests summarizing posts
+func TestComputeStats(t *testing.T) {
+	first := time.Date(2017, 3, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	posts := []*parser.Post{
+		{Date: last, Content: "<p>Three <em>short</em> words</p>", Tags: []string{"go", "web"}},
+		{Date: first, Content: "<pre><code>x := 1</code></pre>", Tags: []string{"go"}},
+		{Date: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	got := computeStats(posts)
+	want := SiteStats{Posts: 3, Words: 6, Tags: 2, FirstPost: first, LastPost: last}
+	if got != want {
+		t.Errorf("computeStats() = %+v, want %+v", got, want)
+	}
+	if got := computeStats(nil); got != (SiteStats{}) {
+		t.Errorf("computeStats(nil) = %+v, want zero", got)
+	}
+}
+
+// TestBuild_Stats tests that templates can show site statistics
+func TestBuild_Stats(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["templates/base.html"] = "<html><body>{{template \"posts\" .}}" +
+		"<footer>{{.Site.Stats.Posts}} posts since {{.Site.Stats.FirstPost.Year}}</footer></body></html>"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	index, err := os.ReadFile(filepath.Join("public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "<footer>1 posts since 2024</footer>") {
+		t.Errorf("index.html = %s, want stats in the footer", index)
+	}
+}
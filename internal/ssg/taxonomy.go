@@ -0,0 +1,160 @@
+package ssg
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// taxonomyNamePattern matches valid taxonomy names, which are used as
+// frontmatter keys and URL prefixes.
+var taxonomyNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Taxonomy groups posts by the values of a frontmatter field, its terms.
+// Taxonomies are listed in config.yaml:
+//
+//	taxonomies: [tags, categories]
+//
+// and posts name their terms in the field, as a list or a single value:
+//
+//	tags: [go, web]
+//	categories: Tutorials
+//
+// Each taxonomy gets a page listing its terms at /<taxonomy>/, and each term
+// a page listing its posts at /<taxonomy>/<slug>. The tags taxonomy reads
+// the posts' tags, and a series taxonomy their series, whose term pages are
+// the series pages (see Series). Every page can reach the taxonomies as
+// .Taxonomies, e.g. for a tag cloud.
+type Taxonomy struct {
+	Name  string  // Frontmatter field (e.g., "categories")
+	Title string  // Title derived from the name (e.g., "Categories")
+	URL   string  // Site-relative URL of the list of terms (e.g., "/categories/")
+	Terms []*Term // Terms in the site language's order
+}
+
+// Term is one value of a taxonomy, with the posts that have it.
+type Term struct {
+	Name  string         // Value from the frontmatter (e.g., "Tutorials")
+	Slug  string         // Slug of the name (e.g., "tutorials")
+	URL   string         // Site-relative URL of the term's page (e.g., "/categories/tutorials.html")
+	Count int            // Number of posts with the term
+	Posts []*parser.Post // Posts with the term, newest first
+}
+
+// postTerms returns the terms of post in the taxonomy name: its tags for
+// "tags", its series for "series", and otherwise the frontmatter field,
+// as a list or a single value.
+func postTerms(post *parser.Post, name string) []string {
+	switch name {
+	case "tags":
+		return post.Tags
+	case "series":
+		if post.Series == "" {
+			return nil
+		}
+		return []string{post.Series}
+	}
+	switch v := post.Params[name].(type) {
+	case nil:
+		return nil
+	case []any:
+		terms := make([]string, 0, len(v))
+		for _, item := range v {
+			terms = append(terms, fmt.Sprint(item))
+		}
+		return terms
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// collectTaxonomies groups posts into the taxonomies listed in the config.
+// Terms whose names have the same slug (like "Go" and "go") are one term,
+// named as in the newest post.
+//
+// Returns the taxonomies in config order, or an error if a taxonomy name
+// isn't valid or is listed twice.
+func collectTaxonomies(posts []*parser.Post, config *SiteConfig) ([]*Taxonomy, error) {
+	byDate := slices.Clone(posts)
+	slices.SortStableFunc(byDate, func(a, b *parser.Post) int { return b.Date.Compare(a.Date) })
+
+	var taxonomies []*Taxonomy
+	seen := make(map[string]bool)
+	for _, name := range config.Taxonomies {
+		if !taxonomyNamePattern.MatchString(name) || name == "posts" {
+			return nil, fmt.Errorf("taxonomy %q must be lowercase letters, digits, - and _, and not posts", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("taxonomy %q is listed twice", name)
+		}
+		seen[name] = true
+
+		tax := &Taxonomy{Name: name, Title: sectionTitle(name), URL: "/" + name + "/"}
+		bySlug := make(map[string]*Term)
+		var names []string
+		for _, post := range byDate {
+			for _, value := range postTerms(post, name) {
+				slug := slugifyLang(config.Language, value)
+				if slug == "" {
+					continue
+				}
+				term, ok := bySlug[slug]
+				if !ok {
+					term = &Term{Name: value, Slug: slug, URL: termURL(*config, name, value)}
+					bySlug[slug] = term
+					names = append(names, value)
+				}
+				if !slices.Contains(term.Posts, post) {
+					term.Posts = append(term.Posts, post)
+					term.Count++
+				}
+			}
+		}
+		for _, n := range sortStrings(config.Language, names) {
+			tax.Terms = append(tax.Terms, bySlug[slugifyLang(config.Language, n)])
+		}
+		taxonomies = append(taxonomies, tax)
+	}
+	return taxonomies, nil
+}
+
+// termURL returns the site-relative URL of the page of a term in the
+// taxonomy name, in the site's URL style (e.g., "/tags/go.html").
+func termURL(config SiteConfig, name, term string) string {
+	return pageURL(config.URLs, "/"+name+"/"+slugifyLang(config.Language, term))
+}
+
+// renderTaxonomy renders the page listing a taxonomy's terms. The content
+// template is "terms.html", falling back to "list.html" and "posts.html",
+// and receives the taxonomy as .Taxonomy.
+func (r *Renderer) renderTaxonomy(tax *Taxonomy, config SiteConfig, outputPath string) error {
+	data := PageData{
+		Site:     config,
+		Taxonomy: tax,
+		Title:    tax.Title,
+		Kind:     KindTaxonomy,
+		URL:      tax.URL,
+	}
+
+	return r.renderToFile(r.contentTemplate("terms.html", "list.html"), data, outputPath)
+}
+
+// renderTerm renders the page of a term, listing its posts. The content
+// template is "term.html", falling back to "list.html" and "posts.html",
+// and receives the posts as .Posts, the term as .Term, and its taxonomy as
+// .Taxonomy.
+func (r *Renderer) renderTerm(tax *Taxonomy, term *Term, config SiteConfig, outputPath string) error {
+	data := PageData{
+		Site:     config,
+		Posts:    term.Posts,
+		Taxonomy: tax,
+		Term:     term,
+		Title:    term.Name,
+		Kind:     KindTerm,
+		URL:      term.URL,
+	}
+
+	return r.renderToFile(r.contentTemplate("term.html", "list.html"), data, outputPath)
+}
@@ -0,0 +1,95 @@
+package ssg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestCollectTaxonomies tests grouping posts by their terms
+func TestCollectTaxonomies(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	older := &parser.Post{Title: "Older", Date: day(1), Tags: []string{"go", "web"}, Params: map[string]any{"categories": "Tutorials"}}
+	newer := &parser.Post{Title: "Newer", Date: day(2), Tags: []string{"Go"}, Params: map[string]any{"categories": []any{"Notes", "Tutorials"}}}
+	config := &SiteConfig{Taxonomies: []string{"tags", "categories"}}
+
+	taxonomies, err := collectTaxonomies([]*parser.Post{older, newer}, config)
+	if err != nil {
+		t.Fatalf("collectTaxonomies() error = %v", err)
+	}
+	if len(taxonomies) != 2 || taxonomies[0].Name != "tags" || taxonomies[1].Name != "categories" {
+		t.Fatalf("collectTaxonomies() = %v, want tags and categories", taxonomies)
+	}
+
+	tags := taxonomies[0]
+	if tags.URL != "/tags/" || tags.Title != "Tags" || len(tags.Terms) != 2 {
+		t.Fatalf("tags = %+v, want two terms at /tags/", tags)
+	}
+	goTerm := tags.Terms[0]
+	if goTerm.Name != "Go" || goTerm.URL != "/tags/go.html" || goTerm.Count != 2 || goTerm.Posts[0] != newer {
+		t.Errorf("go term = %+v, want Go and go merged, newest first", goTerm)
+	}
+
+	categories := taxonomies[1]
+	var got []string
+	for _, term := range categories.Terms {
+		got = append(got, fmt.Sprintf("%s:%d", term.Name, term.Count))
+	}
+	if strings.Join(got, ",") != "Notes:1,Tutorials:2" {
+		t.Errorf("categories = %v, want Notes:1,Tutorials:2", got)
+	}
+
+	for _, bad := range [][]string{{"Tags"}, {"posts"}, {"tags", "tags"}} {
+		if _, err := collectTaxonomies(nil, &SiteConfig{Taxonomies: bad}); err == nil {
+			t.Errorf("collectTaxonomies() with %v succeeded", bad)
+		}
+	}
+}
+
+// TestBuild_Taxonomies tests rendering taxonomy and term pages
+func TestBuild_Taxonomies(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "taxonomies: [tags, series]\n"
+	site["templates/terms.html"] = `{{define "posts"}}{{ range .Taxonomy.Terms }}{{ .Name }}={{ .Count }} {{ end }}{{end}}`
+	site["templates/term.html"] = `{{define "posts"}}{{ .Taxonomy.Name }}/{{ .Term.Name }}:{{ range .Posts }} {{ .Title }}{{ end }}{{end}}`
+	site["templates/post.html"] = `{{define "posts"}}{{ range (index .Taxonomies "tags").Terms }}{{ .Name }} {{ end }}{{end}}`
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\ntags: [go]\nseries: Learning Go\n---\n\nOne.\n"
+	site["content/posts/2024-01-16-second.md"] = "---\ntitle: Second Post\ntags: [go, web]\n---\n\nTwo.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{OutputDir: "public"}); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join("public", filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	if got := read("tags/index.html"); !strings.Contains(got, "go=2 web=1") {
+		t.Errorf("tags/index.html = %s, want terms with counts", got)
+	}
+	if got := read("tags/go.html"); !strings.Contains(got, "tags/go: Second Post First Post") {
+		t.Errorf("tags/go.html = %s, want the term's posts, newest first", got)
+	}
+	if got := read("series/index.html"); !strings.Contains(got, "Learning Go=1") {
+		t.Errorf("series/index.html = %s, want the series listed", got)
+	}
+	if got := read("series/learning-go.html"); strings.Contains(got, "series/Learning Go:") {
+		t.Errorf("series/learning-go.html = %s, want the series page, not a term page", got)
+	}
+	if got := read("posts/first.html"); !strings.Contains(got, "go web") {
+		t.Errorf("posts/first.html = %s, want .Taxonomies on every page", got)
+	}
+}
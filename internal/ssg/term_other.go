@@ -0,0 +1,20 @@
+//go:build !unix && !windows
+
+package ssg
+
+import (
+	"errors"
+	"os"
+)
+
+// rawTerminal returns an error, since raw terminal input isn't supported on
+// this platform.
+func rawTerminal(_, _ *os.File) (func(), error) {
+	return nil, errors.New("not supported on this platform")
+}
+
+// terminalRows returns 0 because the terminal's size isn't known on this
+// platform.
+func terminalRows(_, _ *os.File) int {
+	return 0
+}
@@ -0,0 +1,47 @@
+//go:build unix
+
+package ssg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// rawTerminal puts the terminal on in into raw mode, so keys are read as
+// they're pressed and not echoed, with stty. Returns a function restoring
+// its previous mode, or an error if in isn't a terminal.
+func rawTerminal(in, _ *os.File) (func(), error) {
+	state, err := stty(in, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(in, "raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { _, _ = stty(in, state) }, nil
+}
+
+// terminalRows returns the height of the terminal on in, or 0 if it isn't
+// known.
+func terminalRows(in, _ *os.File) int {
+	size, err := stty(in, "size")
+	if err != nil {
+		return 0
+	}
+	rows, _ := strconv.Atoi(strings.Fields(size + " 0")[0])
+	return rows
+}
+
+// stty runs stty with args on the terminal tty, returning its output.
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...) // #nosec G204 -- fixed arguments, or a state stty printed
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("stty %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
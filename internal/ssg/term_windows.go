@@ -0,0 +1,77 @@
+package ssg
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procSetConsoleMode             = kernel32.NewProc("SetConsoleMode")
+	procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
+)
+
+// Console modes (see the SetConsoleMode documentation).
+const (
+	enableProcessedInput            = 0x0001
+	enableLineInput                 = 0x0002
+	enableEchoInput                 = 0x0004
+	enableVirtualTerminalInput      = 0x0200
+	enableVirtualTerminalProcessing = 0x0004
+)
+
+// consoleScreenBufferInfo is the CONSOLE_SCREEN_BUFFER_INFO structure.
+type consoleScreenBufferInfo struct {
+	size              [2]int16
+	cursorPosition    [2]int16
+	attributes        uint16
+	window            [4]int16 // Left, top, right, bottom
+	maximumWindowSize [2]int16
+}
+
+// rawTerminal switches the console of in to reading keys as they're
+// pressed, without echoing them, as escape sequences, and the console of
+// out to interpreting escape sequences. Returns a function restoring their
+// previous modes, or an error if they aren't consoles.
+func rawTerminal(in, out *os.File) (func(), error) {
+	inHandle, outHandle := syscall.Handle(in.Fd()), syscall.Handle(out.Fd())
+	var inMode, outMode uint32
+	if err := syscall.GetConsoleMode(inHandle, &inMode); err != nil {
+		return nil, err
+	}
+	if err := syscall.GetConsoleMode(outHandle, &outMode); err != nil {
+		return nil, err
+	}
+	raw := inMode&^(enableProcessedInput|enableLineInput|enableEchoInput) | enableVirtualTerminalInput
+	if err := setConsoleMode(inHandle, raw); err != nil {
+		return nil, err
+	}
+	if err := setConsoleMode(outHandle, outMode|enableVirtualTerminalProcessing); err != nil {
+		_ = setConsoleMode(inHandle, inMode)
+		return nil, err
+	}
+	return func() {
+		_ = setConsoleMode(inHandle, inMode)
+		_ = setConsoleMode(outHandle, outMode)
+	}, nil
+}
+
+// terminalRows returns the height of the console window of out, or 0 if it
+// isn't known.
+func terminalRows(_, out *os.File) int {
+	var info consoleScreenBufferInfo
+	r, _, _ := procGetConsoleScreenBufferInfo.Call(out.Fd(), uintptr(unsafe.Pointer(&info))) // #nosec G103 -- the documented way to call the API
+	if r == 0 {
+		return 0
+	}
+	return int(info.window[3]-info.window[1]) + 1
+}
+
+// setConsoleMode sets the mode of the console handle h.
+func setConsoleMode(h syscall.Handle, mode uint32) error {
+	if r, _, err := procSetConsoleMode.Call(uintptr(h), uintptr(mode)); r == 0 {
+		return err
+	}
+	return nil
+}
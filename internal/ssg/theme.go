@@ -0,0 +1,129 @@
+package ssg
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+)
+
+// defaultTheme holds the templates and CSS used when a site has no
+// templates/ directory, so a freshly created site builds without setup.
+//
+//go:embed theme
+var defaultTheme embed.FS
+
+// templateFS returns the filesystem templates are loaded from. A site's
+// templateDir in src is layered over the templates of its theme (themeDir,
+// or "" for none), so a site can override individual theme templates; with
+// neither, the embedded default theme is used. The second result reports whether the
+// default theme is used.
+func templateFS(src fs.FS, templateDir, themeDir string) (fs.FS, bool) {
+	dirs := []string{templateDir}
+	if themeDir != "" {
+		dirs = append(dirs, filepath.Join(themeDir, "templates"))
+	}
+	var layers layeredFS
+	for _, dir := range dirs {
+		if _, err := fs.Stat(src, dir); err != nil {
+			continue
+		}
+		if sub, err := subFS(src, dir); err == nil {
+			layers = append(layers, sub)
+		}
+	}
+	switch len(layers) {
+	case 1:
+		return layers[0], false
+	case 2:
+		return layers, false
+	}
+
+	sub, err := fs.Sub(defaultTheme, "theme/templates")
+	if err != nil {
+		// The embedded path is fixed at compile time.
+		panic(err)
+	}
+	return sub, true
+}
+
+// layeredFS reads from each filesystem in turn, so a file in an earlier layer
+// hides the file at the same path in later ones. Directory listings are
+// merged.
+type layeredFS []fs.FS
+
+// Open opens name from the first layer that has it.
+func (l layeredFS) Open(name string) (fs.File, error) {
+	var firstErr error
+	for _, fsys := range l {
+		f, err := fsys.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// ReadDir lists the entries of directory name in every layer, sorted by
+// name, with earlier layers' entries taking precedence.
+func (l layeredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	seen := make(map[string]bool)
+	found := false
+	for _, fsys := range l {
+		layer, err := fs.ReadDir(fsys, name)
+		if err != nil {
+			continue
+		}
+		found = true
+		for _, e := range layer {
+			if !seen[e.Name()] {
+				seen[e.Name()] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// copyThemeStatic writes the default theme's static files (its stylesheet)
+// to dstDir in out. Called before copyStatic so files in static/ take
+// precedence.
+func copyThemeStatic(out FS, dstDir string, minify bool) error {
+	const root = "theme/static"
+	return fs.WalkDir(defaultTheme, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dstDir, relPath)
+
+		if d.IsDir() {
+			return out.MkdirAll(dstPath, 0750)
+		}
+
+		data, err := defaultTheme.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		if minify {
+			if fn := minifyFor(path); fn != nil {
+				data = fn(data)
+			}
+		}
+
+		return out.WriteFile(dstPath, data, 0600)
+	})
+}
@@ -0,0 +1,65 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_DefaultTheme tests that a site without templates/ builds with the embedded theme
+func TestBuild_DefaultTheme(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	for name := range site {
+		if strings.HasPrefix(name, "templates/") {
+			delete(site, name)
+		}
+	}
+	site["config.yaml"] += "mounts:\n  - source: README.md\n"
+	site["README.md"] = "# About\n\nAbout this site.\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	for _, name := range []string{"index.html", "posts/first.html", "readme.html"} {
+		content, err := os.ReadFile(filepath.Join("public", filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("%s not generated: %v", name, err)
+			continue
+		}
+		if !strings.Contains(string(content), "Test Blog") {
+			t.Errorf("%s missing site title, got:\n%s", name, content)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join("public", "css", "style.css")); err != nil {
+		t.Errorf("default theme stylesheet not copied: %v", err)
+	}
+}
+
+// TestBuild_DefaultThemeStaticOverride tests that static/ files replace the theme's
+func TestBuild_DefaultThemeStaticOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"config.yaml":          "title: Test Blog\n",
+		"static/css/style.css": "body { color: red; }",
+	})
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join("public", "css", "style.css"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "body { color: red; }" {
+		t.Errorf("style.css = %q, want the site's own stylesheet", content)
+	}
+}
@@ -0,0 +1,391 @@
+package ssg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// themesDir is where installed themes live, relative to the site root.
+const themesDir = "themes"
+
+// ThemeConfig selects a theme from themes/ and records where it came from,
+// so the same version can be installed again on another machine or in CI.
+//
+// Example config.yaml (written by `ssg theme install`):
+//
+//	theme:
+//	  name: paper
+//	  url: https://github.com/example/ssg-theme-paper.git
+//	  version: 3f1c2a9e...
+//
+// A theme that isn't installed from git can be selected by name alone:
+// "theme: paper".
+type ThemeConfig struct {
+	Name    string `yaml:"name,omitempty"`    // Directory under themes/
+	URL     string `yaml:"url,omitempty"`     // Git URL the theme is installed from
+	Version string `yaml:"version,omitempty"` // Commit the theme is pinned to
+
+	// Sandbox restricts the theme's templates to functions without side
+	// effects, unless their capabilities are granted in Allow (see
+	// CapUnsafeHTML and friends). Themes installed with `ssg theme install`
+	// are sandboxed by default.
+	Sandbox bool     `yaml:"sandbox,omitempty"`
+	Allow   []string `yaml:"allow,omitempty"` // Capabilities granted to a sandboxed theme
+
+	// Colors overrides the theme's colors in light and dark mode (see
+	// ThemeColors).
+	Colors ThemeColors `yaml:"colors,omitempty"`
+}
+
+// UnmarshalYAML accepts a theme name as a plain string as well as a mapping.
+func (c *ThemeConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		c.Name = value.Value
+		return nil
+	}
+	type plain ThemeConfig
+	return value.Decode((*plain)(c))
+}
+
+// dir returns the directory of the selected theme in fsys, or "" if no theme
+// is selected. Returns an error if the theme isn't installed.
+func (c ThemeConfig) dir(fsys fs.FS) (string, error) {
+	if c.Name == "" {
+		return "", nil
+	}
+	dir := filepath.Join(themesDir, c.Name)
+	if _, err := fs.Stat(fsys, dir); err != nil {
+		if c.URL != "" {
+			return "", fmt.Errorf("theme %s isn't installed, run 'ssg theme install'", c.Name)
+		}
+		return "", fmt.Errorf("theme %s not found in %s", c.Name, themesDir)
+	}
+	return dir, nil
+}
+
+// checkThemeVersion prints a warning if the theme's checkout in dir isn't at
+// the commit pinned in the config, e.g. after pulling a config change without
+// reinstalling. Themes that aren't git checkouts aren't checked.
+func checkThemeVersion(dir string, c ThemeConfig) {
+	if c.Version == "" {
+		return
+	}
+	rev, err := runGit(dir, "rev-parse", "HEAD")
+	if err == nil && rev != c.Version {
+		slog.Warn("theme isn't at the pinned version; run 'ssg theme install'", "theme", c.Name, "version", shortRev(rev), "pinned", shortRev(c.Version))
+	}
+}
+
+// ThemeMeta describes a theme in its theme.yaml.
+type ThemeMeta struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Author      string `yaml:"author"`
+	License     string `yaml:"license"`
+	Homepage    string `yaml:"homepage"`
+}
+
+// InstallTheme clones a theme into themes/ and selects it in the config,
+// pinned to the commit that was installed.
+//
+// With no url, the theme recorded in the config is installed at its pinned
+// version, so a fresh clone of a site (or a CI job) gets the same theme. If
+// the theme is already installed, it's checked out at that version instead.
+//
+// Parameters:
+//   - configPath: Path to config.yaml, updated with the theme's name, URL, and commit
+//   - url: Git URL of the theme, or "" to install the theme from the config
+//   - name: Directory name under themes/ (default: the repository name
+//     without an "ssg-theme-" prefix or ".git" suffix)
+//   - version: Tag, branch, or commit to install (default: the pinned
+//     version, or the repository's default branch)
+//
+// Returns an error if the URL or version is invalid, git fails, the theme
+// directory is taken by something else, or the config can't be updated.
+func InstallTheme(configPath, url, name, version string) error {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	pinned := config.Theme
+	if url == "" {
+		if pinned.URL == "" {
+			return errors.New("no theme URL given and none recorded in the config")
+		}
+		url, name = pinned.URL, pinned.Name
+	}
+	if name == "" {
+		name = themeName(url)
+	}
+	if version == "" && url == pinned.URL {
+		version = pinned.Version
+	}
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid theme name %q", name)
+	}
+	// Leading dashes would be read by git as options
+	if strings.HasPrefix(url, "-") {
+		return fmt.Errorf("invalid theme URL %q", url)
+	}
+
+	dir := filepath.Join(themesDir, name)
+	if _, err := os.Stat(dir); err == nil {
+		if origin, err := runGit(dir, "remote", "get-url", "origin"); err != nil || origin != url {
+			return fmt.Errorf("%s already exists and isn't a checkout of %s", dir, url)
+		}
+		if _, err := runGit(dir, "fetch", "--quiet", "--tags", "origin"); err != nil {
+			return err
+		}
+	} else {
+		if err := os.MkdirAll(themesDir, 0750); err != nil {
+			return err
+		}
+		if _, err := runGit("", "clone", "--quiet", "--", url, dir); err != nil {
+			return err
+		}
+	}
+	if version != "" {
+		if err := checkoutTheme(dir, version); err != nil {
+			return err
+		}
+	}
+
+	rev, err := runGit(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	// Themes from elsewhere are sandboxed until the site says otherwise
+	theme := ThemeConfig{Name: name, URL: url, Version: rev, Sandbox: true, Colors: pinned.Colors}
+	if url == pinned.URL {
+		theme.Sandbox, theme.Allow = pinned.Sandbox, pinned.Allow
+	}
+	if err := setConfigKey(configPath, "theme", theme); err != nil {
+		return fmt.Errorf("updating %s: %w", configPath, err)
+	}
+
+	fmt.Printf("Installed theme %s at %s\n", name, shortRev(rev))
+	if meta, err := readThemeMeta(dir); err == nil && meta.Description != "" {
+		fmt.Printf("  %s\n", meta.Description)
+	}
+	return nil
+}
+
+// UpdateTheme fetches the selected theme's repository, checks out version
+// (default: the latest commit of its default branch), and pins the config
+// to it.
+//
+// Returns an error if no installed theme is selected, the version is invalid,
+// git fails, or the config can't be updated.
+func UpdateTheme(configPath, version string) error {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	theme := config.Theme
+	if theme.URL == "" {
+		return errors.New("no theme installed from git, run 'ssg theme install <url>' first")
+	}
+	dir, err := theme.dir(DirFS("."))
+	if err != nil {
+		return err
+	}
+
+	if _, err := runGit(dir, "fetch", "--quiet", "--tags", "origin"); err != nil {
+		return err
+	}
+	if version == "" {
+		version = "origin/HEAD"
+	}
+	if err := checkoutTheme(dir, version); err != nil {
+		return err
+	}
+	rev, err := runGit(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	if rev == theme.Version {
+		fmt.Printf("Theme %s is up to date (%s)\n", theme.Name, shortRev(rev))
+		return nil
+	}
+	old := theme.Version
+	theme.Version = rev
+	if err := setConfigKey(configPath, "theme", theme); err != nil {
+		return fmt.Errorf("updating %s: %w", configPath, err)
+	}
+	fmt.Printf("Updated theme %s from %s to %s\n", theme.Name, shortRev(old), shortRev(rev))
+	return nil
+}
+
+// NewTheme creates a theme skeleton in themes/<name>: a copy of the default
+// theme's templates and stylesheet, and a theme.yaml to fill in before
+// publishing it as a git repository.
+//
+// Returns an error if the theme directory already exists or can't be written.
+func NewTheme(name string) error {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid theme name %q", name)
+	}
+	dir := filepath.Join(themesDir, name)
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("%s already exists", dir)
+	}
+
+	err := fs.WalkDir(defaultTheme, "theme", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(p, "theme")))
+		if d.IsDir() {
+			return os.MkdirAll(dst, 0750)
+		}
+		data, err := defaultTheme.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dst, data, 0600)
+	})
+	if err != nil {
+		return fmt.Errorf("copying default theme: %w", err)
+	}
+
+	meta, err := yaml.Marshal(ThemeMeta{Name: name, Description: "A theme for ssg"})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "theme.yaml"), meta, 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created theme %s\n", dir)
+	fmt.Printf("Set \"theme: %s\" in your config to use it\n", name)
+	return nil
+}
+
+// readThemeMeta reads the theme.yaml of the theme in dir.
+func readThemeMeta(dir string) (*ThemeMeta, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "theme.yaml")) // #nosec G304 -- path inside the site's themes/ directory
+	if err != nil {
+		return nil, err
+	}
+	var meta ThemeMeta
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("parsing %s/theme.yaml: %w", dir, err)
+	}
+	return &meta, nil
+}
+
+// themeName derives a theme's directory name from its git URL:
+// "https://github.com/example/ssg-theme-paper.git" becomes "paper".
+func themeName(url string) string {
+	name := path.Base(strings.TrimRight(strings.ReplaceAll(url, "\\", "/"), "/"))
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, ".git")
+	return strings.TrimPrefix(name, "ssg-theme-")
+}
+
+// shortRev abbreviates a commit ID for messages.
+func shortRev(rev string) string {
+	if len(rev) > 12 {
+		return rev[:12]
+	}
+	return rev
+}
+
+// checkoutTheme detaches the theme checkout in dir at version. The version is
+// resolved to a commit first, so it can't be mistaken for a git option.
+func checkoutTheme(dir, version string) error {
+	if strings.HasPrefix(version, "-") {
+		return fmt.Errorf("invalid theme version %q", version)
+	}
+	rev, err := runGit(dir, "rev-parse", "--verify", "--quiet", version+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("theme version %q not found", version)
+	}
+	_, err = runGit(dir, "checkout", "--quiet", "--detach", rev)
+	return err
+}
+
+// runGit runs git with args in dir (the current directory if dir is ""),
+// returning its trimmed output. Errors include git's stderr.
+func runGit(dir string, args ...string) (string, error) {
+	cmdArgs := args
+	if dir != "" {
+		cmdArgs = append([]string{"-C", dir}, args...)
+	}
+	cmd := exec.Command("git", cmdArgs...) // #nosec G204 -- arguments come from the user's command line and config
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// setConfigKey sets a top-level key of the YAML config at configPath to
+// value, replacing the key's existing block or appending one. The rest of the
+// file is edited as text, so its comments and formatting are kept.
+func setConfigKey(configPath, key string, value any) error {
+	data, err := os.ReadFile(configPath) // #nosec G304 -- config path comes from the user's command line
+	if err != nil {
+		return err
+	}
+
+	body, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var block strings.Builder
+	block.WriteString(key + ":\n")
+	for _, line := range strings.SplitAfter(strings.TrimRight(string(body), "\n"), "\n") {
+		block.WriteString("  " + strings.TrimSuffix(line, "\n") + "\n")
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, key+":") {
+			start = i
+			break
+		}
+	}
+
+	var content string
+	if start < 0 {
+		content = string(data)
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += block.String()
+	} else {
+		// The key's block runs until the next line that isn't indented,
+		// leaving blank lines before it in place.
+		end := start + 1
+		for end < len(lines) && (strings.TrimSpace(lines[end]) == "" || lines[end][0] == ' ' || lines[end][0] == '\t') {
+			end++
+		}
+		for end > start+1 && strings.TrimSpace(lines[end-1]) == "" {
+			end--
+		}
+		content = strings.Join(lines[:start], "") + block.String() + strings.Join(lines[end:], "")
+	}
+
+	var config SiteConfig
+	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+		return fmt.Errorf("updated config would be invalid: %w", err)
+	}
+	return os.WriteFile(configPath, []byte(content), 0600)
+}
@@ -0,0 +1,230 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestThemeName tests deriving theme directory names from git URLs
+func TestThemeName(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/example/ssg-theme-paper.git": "paper",
+		"git@github.com:example/minimal.git":             "minimal",
+		"git@host:bare.git":                              "bare",
+		"/srv/themes/dark/":                              "dark",
+	}
+	for url, want := range tests {
+		if got := themeName(url); got != want {
+			t.Errorf("themeName(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+// TestSetConfigKey tests replacing or appending a top-level config block
+func TestSetConfigKey(t *testing.T) {
+	tests := []struct {
+		name, config, want string
+	}{
+		{
+			name:   "append",
+			config: "title: Blog",
+			want:   "title: Blog\ntheme:\n  name: paper\n  version: abc\n",
+		},
+		{
+			name:   "replace block",
+			config: "title: Blog\ntheme:\n  name: old\n  url: x\n\n# Comments stay\nauthor: Me\n",
+			want:   "title: Blog\ntheme:\n  name: paper\n  version: abc\n\n# Comments stay\nauthor: Me\n",
+		},
+		{
+			name:   "replace scalar",
+			config: "theme: old\nauthor: Me\n",
+			want:   "theme:\n  name: paper\n  version: abc\nauthor: Me\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			writeFiles(t, filepath.Dir(path), map[string]string{"config.yaml": tt.config})
+
+			if err := setConfigKey(path, "theme", ThemeConfig{Name: "paper", Version: "abc"}); err != nil {
+				t.Fatalf("setConfigKey() failed: %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("config =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBuild_Theme tests building with a theme, overriding parts of it in the site
+func TestBuild_Theme(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"config.yaml":                          "title: Test Blog\ntheme: paper\n",
+		"themes/paper/templates/base.html":     "<html><body>{{template \"posts\" .}}</body></html>",
+		"themes/paper/templates/posts.html":    "{{define \"posts\"}}theme list{{end}}",
+		"themes/paper/templates/post.html":     "{{define \"posts\"}}theme post{{end}}",
+		"themes/paper/static/css/paper.css":    "theme css",
+		"themes/paper/static/css/override.css": "theme version",
+		"templates/post.html":                  "{{define \"posts\"}}site post{{end}}",
+		"static/css/override.css":              "site version",
+		"content/posts/2024-01-15-first.md":    "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\nHello.\n",
+	})
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"index.html":       "theme list",
+		"posts/first.html": "site post",
+		"css/paper.css":    "theme css",
+		"css/override.css": "site version",
+	} {
+		got, err := os.ReadFile(filepath.Join("public", filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(got), want) {
+			t.Errorf("%s = %q, want it to contain %q", name, got, want)
+		}
+	}
+
+	writeFiles(t, tmpDir, map[string]string{"config.yaml": "title: Test Blog\ntheme:\n  name: missing\n  url: https://example.com/missing.git\n"})
+	if err := Build(context.Background(), BuildOptions{}); err == nil || !strings.Contains(err.Error(), "ssg theme install") {
+		t.Errorf("Build() error = %v, want a hint to install the theme", err)
+	}
+}
+
+// TestNewTheme tests that a theme skeleton builds a site
+func TestNewTheme(t *testing.T) {
+	tmpDir := t.TempDir()
+	site := testSite()
+	delete(site, "templates/base.html")
+	delete(site, "templates/posts.html")
+	delete(site, "templates/post.html")
+	site["config.yaml"] += "theme: mine\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := NewTheme("mine"); err != nil {
+		t.Fatalf("NewTheme() failed: %v", err)
+	}
+	meta, err := readThemeMeta(filepath.Join("themes", "mine"))
+	if err != nil || meta.Name != "mine" {
+		t.Errorf("theme.yaml = %+v, %v", meta, err)
+	}
+	if err := NewTheme("mine"); err == nil {
+		t.Error("NewTheme() over an existing theme succeeded, want error")
+	}
+	if err := NewTheme("../escape"); err == nil {
+		t.Error("NewTheme() with a path succeeded, want error")
+	}
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() with the new theme failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("public", "css", "style.css")); err != nil {
+		t.Errorf("theme stylesheet not copied: %v", err)
+	}
+}
+
+// TestInstallTheme tests installing, pinning, updating, and reinstalling a theme from git
+func TestInstallTheme(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for _, v := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(v, "Test")
+	}
+	for _, v := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(v, "test@example.com")
+	}
+
+	// A theme repository with two versions
+	repo := filepath.Join(t.TempDir(), "ssg-theme-paper")
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := runGit(repo, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	writeFiles(t, repo, map[string]string{"theme.yaml": "name: paper\ndescription: Plain and simple\n", "templates/posts.html": "v1"})
+	if _, err := runGit("", "init", "--quiet", repo); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "v1")
+	git("tag", "v1")
+	v1 := git("rev-parse", "HEAD")
+	writeFiles(t, repo, map[string]string{"templates/posts.html": "v2"})
+	git("commit", "--quiet", "-am", "v2")
+	v2 := git("rev-parse", "HEAD")
+
+	site := t.TempDir()
+	writeFiles(t, site, map[string]string{"config.yaml": "title: Blog\n"})
+	t.Chdir(site)
+
+	if err := InstallTheme("config.yaml", repo, "", "v1"); err != nil {
+		t.Fatalf("InstallTheme() failed: %v", err)
+	}
+	config, err := LoadConfig("config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if th := config.Theme; th.Name != "paper" || th.URL != repo || th.Version != v1 || !th.Sandbox {
+		t.Errorf("Theme = %+v, want paper pinned to %s and sandboxed", th, v1)
+	}
+	if got, _ := os.ReadFile(filepath.Join("themes", "paper", "templates", "posts.html")); string(got) != "v1" {
+		t.Errorf("installed posts.html = %q, want v1", got)
+	}
+
+	if err := UpdateTheme("config.yaml", ""); err != nil {
+		t.Fatalf("UpdateTheme() failed: %v", err)
+	}
+	if config, _ := LoadConfig("config.yaml"); config.Theme.Version != v2 {
+		t.Errorf("Version after update = %s, want %s", config.Theme.Version, v2)
+	}
+
+	// A fresh checkout of the site installs the pinned version
+	if err := os.RemoveAll("themes"); err != nil {
+		t.Fatal(err)
+	}
+	if err := InstallTheme("config.yaml", "", "", ""); err != nil {
+		t.Fatalf("InstallTheme() from the config failed: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join("themes", "paper", "templates", "posts.html")); string(got) != "v2" {
+		t.Errorf("reinstalled posts.html = %q, want v2", got)
+	}
+
+	// URLs and versions that git would read as options are rejected
+	marker := filepath.Join(t.TempDir(), "pwned")
+	if err := InstallTheme("config.yaml", "--upload-pack=touch "+marker, "evil", ""); err == nil {
+		t.Error("InstallTheme() with an option as the URL succeeded, want error")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("InstallTheme() ran the command in the URL")
+	}
+	for _, version := range []string{"--orphan=x", "no-such-tag"} {
+		if err := InstallTheme("config.yaml", repo, "", version); err == nil {
+			t.Errorf("InstallTheme() with version %q succeeded, want error", version)
+		}
+		if err := UpdateTheme("config.yaml", version); err == nil {
+			t.Errorf("UpdateTheme() with version %q succeeded, want error", version)
+		}
+	}
+	if config, _ := LoadConfig("config.yaml"); config.Theme.Version != v2 {
+		t.Errorf("Version after rejected versions = %s, want %s", config.Theme.Version, v2)
+	}
+}
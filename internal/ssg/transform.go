@@ -0,0 +1,178 @@
+package ssg
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// HTMLTransformer modifies the parsed HTML of a rendered page in place.
+//
+// Transformers run after a page's template is executed and before it is
+// minified and written. The page is parsed once and handed to every
+// transformer in turn, so features that post-process output (lazy-loading
+// images, external link attributes, subresource integrity, analytics
+// snippets) compose without each re-parsing the HTML.
+type HTMLTransformer func(doc *html.Node, page *RenderedPage) error
+
+// RenderedPage describes the page an HTMLTransformer is applied to.
+type RenderedPage struct {
+	Path string    // File the page is written to (e.g., "public/posts/hello.html")
+	Data *PageData // Data the page was rendered with
+}
+
+// namedTransformer is an HTMLTransformer with the name it was registered
+// under, used in error messages.
+type namedTransformer struct {
+	name string
+	fn   HTMLTransformer
+}
+
+var (
+	registeredTransformersMu sync.Mutex
+	registeredTransformers   []namedTransformer
+)
+
+// RegisterTransformer appends a transformer to the pipeline of every build
+// started afterwards. Transformers run in registration order; registering a
+// name again replaces the earlier transformer in its original position.
+func RegisterTransformer(name string, fn HTMLTransformer) {
+	registeredTransformersMu.Lock()
+	defer registeredTransformersMu.Unlock()
+	for i, t := range registeredTransformers {
+		if t.name == name {
+			registeredTransformers[i].fn = fn
+			return
+		}
+	}
+	registeredTransformers = append(registeredTransformers, namedTransformer{name: name, fn: fn})
+}
+
+// htmlTransformers returns the transformer pipeline for a build.
+func htmlTransformers() []namedTransformer {
+	registeredTransformersMu.Lock()
+	defer registeredTransformersMu.Unlock()
+	return append([]namedTransformer(nil), registeredTransformers...)
+}
+
+// transformHTML parses a rendered page, runs each transformer on it, and
+// returns the re-rendered HTML. Returns src unchanged if there are no
+// transformers.
+func transformHTML(src []byte, page *RenderedPage, transformers []namedTransformer) ([]byte, error) {
+	if len(transformers) == 0 {
+		return src, nil
+	}
+
+	doc, err := html.Parse(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("parsing rendered HTML: %w", err)
+	}
+	for _, t := range transformers {
+		if err := t.fn(doc, page); err != nil {
+			return nil, fmt.Errorf("transformer %s: %w", t.name, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return nil, fmt.Errorf("rendering transformed HTML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Page is a rendered page on its way to being written, as seen by a
+// PagePostProcessor.
+type Page struct {
+	Path    string    // File the page is written to (e.g., "public/posts/hello.html")
+	Data    *PageData // Data the page was rendered with
+	Content []byte    // HTML of the page, which processors may replace
+}
+
+// PagePostProcessor is a step run on the final HTML of every rendered page,
+// after the HTMLTransformers and minification, just before it is written.
+// Processors work on the page's bytes rather than a parsed tree, so they
+// suit changes that must survive minification or that don't need the
+// document's structure: license banners, nonce attributes matched to a
+// Content-Security-Policy, or a rewrite with strings.ReplaceAll.
+type PagePostProcessor interface {
+	Process(page *Page) error
+}
+
+// PostProcessorFunc adapts an ordinary function to a PagePostProcessor.
+type PostProcessorFunc func(page *Page) error
+
+// Process calls f(page).
+func (f PostProcessorFunc) Process(page *Page) error {
+	return f(page)
+}
+
+// namedPostProcessor is a PagePostProcessor with the name it was registered
+// under, used in error messages.
+type namedPostProcessor struct {
+	name string
+	p    PagePostProcessor
+}
+
+var (
+	registeredPostProcessorsMu sync.Mutex
+	registeredPostProcessors   []namedPostProcessor
+)
+
+// RegisterPostProcessor appends a post-processor to the pipeline of every
+// build started afterwards. Post-processors run in registration order;
+// registering a name again replaces the earlier one in its original
+// position.
+func RegisterPostProcessor(name string, p PagePostProcessor) {
+	registeredPostProcessorsMu.Lock()
+	defer registeredPostProcessorsMu.Unlock()
+	for i, np := range registeredPostProcessors {
+		if np.name == name {
+			registeredPostProcessors[i].p = p
+			return
+		}
+	}
+	registeredPostProcessors = append(registeredPostProcessors, namedPostProcessor{name: name, p: p})
+}
+
+// pagePostProcessors returns the post-processor pipeline for a build.
+func pagePostProcessors() []namedPostProcessor {
+	registeredPostProcessorsMu.Lock()
+	defer registeredPostProcessorsMu.Unlock()
+	return append([]namedPostProcessor(nil), registeredPostProcessors...)
+}
+
+// postProcess runs each post-processor on a page and returns its final
+// content.
+func postProcess(page *Page, processors []namedPostProcessor) ([]byte, error) {
+	for _, np := range processors {
+		if err := np.p.Process(page); err != nil {
+			return nil, fmt.Errorf("post-processor %s: %w", np.name, err)
+		}
+	}
+	return page.Content, nil
+}
+
+// finishPage runs a rendered page through the renderer's output pipeline:
+// the HTML transformers, minification if enabled, then the post-processors.
+// Returns the HTML to write.
+func (r *Renderer) finishPage(src []byte, data *PageData, outputPath string) ([]byte, error) {
+	out, err := transformHTML(src, &RenderedPage{Path: outputPath, Data: data}, r.transformers)
+	if err != nil {
+		return nil, err
+	}
+	if r.minify {
+		out = minifyHTML(out)
+	}
+	return postProcess(&Page{Path: outputPath, Data: data, Content: out}, r.postProcessors)
+}
+
+// walkHTML calls fn for n and each of its descendants in document order.
+// Transformers use it to find the elements they rewrite.
+func walkHTML(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walkHTML(c, fn)
+	}
+}
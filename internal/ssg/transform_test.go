@@ -0,0 +1,159 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// TestTransformHTML tests that transformers run in order on one parsed document
+func TestTransformHTML(t *testing.T) {
+	var order []string
+	addClass := func(name string) namedTransformer {
+		return namedTransformer{name: name, fn: func(doc *html.Node, page *RenderedPage) error {
+			order = append(order, name)
+			walkHTML(doc, func(n *html.Node) {
+				if n.Type == html.ElementNode && n.Data == "p" {
+					n.Attr = append(n.Attr, html.Attribute{Key: "data-" + name, Val: page.Data.Title})
+				}
+			})
+			return nil
+		}}
+	}
+
+	page := &RenderedPage{Path: "public/index.html", Data: &PageData{Title: "Home"}}
+	out, err := transformHTML([]byte("<p>Hello</p>"), page, []namedTransformer{addClass("first"), addClass("second")})
+	if err != nil {
+		t.Fatalf("transformHTML() failed: %v", err)
+	}
+
+	if got := strings.Join(order, ","); got != "first,second" {
+		t.Errorf("transformers ran in order %s, want first,second", got)
+	}
+	if !strings.Contains(string(out), `<p data-first="Home" data-second="Home">Hello</p>`) {
+		t.Errorf("transformHTML() = %s, want both attributes added", out)
+	}
+}
+
+// TestTransformHTML_NoTransformers tests that pages are left untouched without transformers
+func TestTransformHTML_NoTransformers(t *testing.T) {
+	src := []byte("<p>Hello</p>")
+	out, err := transformHTML(src, &RenderedPage{}, nil)
+	if err != nil {
+		t.Fatalf("transformHTML() failed: %v", err)
+	}
+	if string(out) != string(src) {
+		t.Errorf("transformHTML() = %s, want %s", out, src)
+	}
+}
+
+// TestTransformHTML_Error tests that transformer errors name the transformer
+func TestTransformHTML_Error(t *testing.T) {
+	failing := namedTransformer{name: "broken", fn: func(*html.Node, *RenderedPage) error {
+		return errors.New("boom")
+	}}
+	_, err := transformHTML([]byte("<p>Hello</p>"), &RenderedPage{}, []namedTransformer{failing})
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("transformHTML() error = %v, want error naming the transformer", err)
+	}
+}
+
+// TestRegisterTransformer tests that registered transformers apply to every built page
+func TestRegisterTransformer(t *testing.T) {
+	RegisterTransformer("footer-note", func(doc *html.Node, page *RenderedPage) error {
+		walkHTML(doc, func(n *html.Node) {
+			if n.Type == html.ElementNode && n.Data == "body" {
+				n.AppendChild(&html.Node{Type: html.CommentNode, Data: " rendered " + filepath.Base(page.Path) + " "})
+			}
+		})
+		return nil
+	})
+	defer func() {
+		registeredTransformersMu.Lock()
+		registeredTransformers = nil
+		registeredTransformersMu.Unlock()
+	}()
+
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	for _, path := range []string{filepath.Join("public", "index.html"), filepath.Join("public", "posts", "first.html")} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), "<!-- rendered "+filepath.Base(path)+" -->") {
+			t.Errorf("%s not transformed, got:\n%s", path, content)
+		}
+	}
+}
+
+// TestPostProcess tests that post-processors run in order on the page's content
+func TestPostProcess(t *testing.T) {
+	banner := PostProcessorFunc(func(page *Page) error {
+		page.Content = append([]byte("<!-- "+page.Data.Title+" -->"), page.Content...)
+		return nil
+	})
+	nonce := PostProcessorFunc(func(page *Page) error {
+		page.Content = bytes.ReplaceAll(page.Content, []byte("<script>"), []byte(`<script nonce="abc">`))
+		return nil
+	})
+
+	page := &Page{Path: "public/index.html", Data: &PageData{Title: "Home"}, Content: []byte("<script>x()</script>")}
+	out, err := postProcess(page, []namedPostProcessor{{name: "banner", p: banner}, {name: "nonce", p: nonce}})
+	if err != nil {
+		t.Fatalf("postProcess() failed: %v", err)
+	}
+	if want := `<!-- Home --><script nonce="abc">x()</script>`; string(out) != want {
+		t.Errorf("postProcess() = %s, want %s", out, want)
+	}
+
+	failing := PostProcessorFunc(func(*Page) error { return errors.New("boom") })
+	if _, err := postProcess(page, []namedPostProcessor{{name: "broken", p: failing}}); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("postProcess() error = %v, want error naming the post-processor", err)
+	}
+}
+
+// TestRegisterPostProcessor tests that registered post-processors see every built page after minification
+func TestRegisterPostProcessor(t *testing.T) {
+	RegisterPostProcessor("banner", PostProcessorFunc(func(page *Page) error {
+		page.Content = append([]byte("<!-- built by ssg -->\n"), page.Content...)
+		return nil
+	}))
+	defer func() {
+		registeredPostProcessorsMu.Lock()
+		registeredPostProcessors = nil
+		registeredPostProcessorsMu.Unlock()
+	}()
+
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "minify: true\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	if err := Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	for _, path := range []string{filepath.Join("public", "index.html"), filepath.Join("public", "posts", "first.html")} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(content), "<!-- built by ssg -->\n") {
+			t.Errorf("%s doesn't start with the banner (minified away?), got:\n%s", path, content)
+		}
+	}
+}
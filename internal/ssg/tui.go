@@ -0,0 +1,641 @@
+package ssg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// tuiKeys is the key help shown below the list of posts.
+const tuiKeys = "j/k select  space draft  e edit  o open  n new  b build  x errors  r reload  q quit"
+
+// tuiFields are the frontmatter fields the content manager can set, by the
+// key that picks them after e.
+var tuiFields = []struct {
+	key, name string
+}{
+	{"t", "title"},
+	{"d", "description"},
+	{"a", "date"},
+	{"g", "tags"},
+}
+
+// Terminal control sequences the content manager draws with.
+const (
+	ansiClear       = "\x1b[H\x1b[2J" // Move to the top left and clear the screen
+	ansiReverse     = "\x1b[7m"
+	ansiBold        = "\x1b[1m"
+	ansiReset       = "\x1b[0m"
+	ansiEnterScreen = "\x1b[?1049h\x1b[?25l" // Switch to the alternate screen and hide the cursor
+	ansiLeaveScreen = "\x1b[?25h\x1b[?1049l"
+)
+
+// TUI runs an interactive content manager in the terminal. It lists the
+// site's posts, including drafts, newest first; the arrow keys (or j and k)
+// select one, and single keys act on it: space toggles whether it's a draft,
+// e sets its title, description, date, or tags, o (or enter) opens it in
+// $VISUAL or $EDITOR, n creates a post, b builds the site, and x shows why
+// the last build failed, along with its warnings. It's for those who'd
+// rather not remember the flags of new, publish, and build.
+//
+// Frontmatter is changed in place, like Publish does, so the rest of each
+// file stays as written.
+//
+// The terminal is switched to raw mode and the alternate screen while the
+// manager runs, and back while an editor is open (see rawTerminal).
+//
+// Parameters:
+//   - ctx: Context for builds started from the manager
+//   - opts: Options; empty fields take their defaults
+//
+// Returns an error if the config can't be loaded, or standard input isn't
+// an interactive terminal. Failed actions and builds are shown, and the
+// manager keeps running.
+func TUI(ctx context.Context, opts TUIOptions) error {
+	restore, err := rawTerminal(os.Stdin, os.Stdout)
+	if err != nil {
+		return fmt.Errorf("ssg tui needs an interactive terminal: %w", err)
+	}
+	fmt.Fprint(os.Stdout, ansiEnterScreen)
+	defer func() {
+		fmt.Fprint(os.Stdout, ansiLeaveScreen)
+		restore()
+	}()
+
+	ui, err := newContentUI(opts, os.Stdout)
+	if err != nil {
+		return err
+	}
+	ui.rows = func() int { return terminalRows(os.Stdin, os.Stdout) }
+	ui.suspend = func(fn func() error) error {
+		fmt.Fprint(os.Stdout, ansiLeaveScreen)
+		restore()
+		err := fn()
+		raw, rawErr := rawTerminal(os.Stdin, os.Stdout)
+		if rawErr != nil {
+			return rawErr
+		}
+		restore = raw
+		fmt.Fprint(os.Stdout, ansiEnterScreen)
+		return err
+	}
+	return ui.loop(ctx, bufio.NewReader(os.Stdin))
+}
+
+// contentUI is the state of a TUI session.
+type contentUI struct {
+	opts      TUIOptions
+	out       io.Writer
+	config    *SiteConfig
+	posts     []tuiPost
+	selected  int    // Index of the selected post
+	top       int    // Index of the first post on screen
+	status    string // Result of the last action, shown below the list
+	prompt    string // Line being typed, with its label, or ""
+	errorView bool   // The last build's errors are shown instead of the list
+	buildErr  error  // Error of the last build, or nil
+	buildLog  string // Warnings logged by the last build
+	hasBuilds bool   // A build has run
+
+	rows    func() int                  // Height of the terminal, or 0 if unknown
+	suspend func(fn func() error) error // Runs fn with the terminal as it was before the manager started
+}
+
+// tuiPost is a content file as listed by the content manager.
+type tuiPost struct {
+	Path        string
+	Title       string
+	Description string
+	Date        time.Time
+	Tags        []string
+	Draft       bool
+	Err         error // Why the file couldn't be parsed, or nil
+}
+
+// newContentUI loads the site's config and posts for a session drawn to
+// out.
+func newContentUI(opts TUIOptions, out io.Writer) (*contentUI, error) {
+	opts = opts.withDefaults()
+	config, err := LoadConfig(opts.ConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	ui := &contentUI{
+		opts:    opts,
+		out:     out,
+		config:  config,
+		rows:    func() int { return 0 },
+		suspend: func(fn func() error) error { return fn() },
+	}
+	if err := ui.reload(""); err != nil {
+		return nil, err
+	}
+	return ui, nil
+}
+
+// runTUI runs the content manager with keys read from in, drawing to out,
+// until in is exhausted or the user quits.
+func runTUI(ctx context.Context, in io.Reader, out io.Writer, opts TUIOptions) error {
+	ui, err := newContentUI(opts, out)
+	if err != nil {
+		return err
+	}
+	return ui.loop(ctx, bufio.NewReader(in))
+}
+
+// loop draws the screen and handles keys until the user quits or in is
+// exhausted.
+func (ui *contentUI) loop(ctx context.Context, in *bufio.Reader) error {
+	for {
+		ui.draw()
+		key, err := readKey(in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		quit, err := ui.handle(ctx, in, key)
+		if err != nil {
+			ui.status = "Error: " + err.Error()
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+// handle acts on one key pressed while the list is shown, reading more keys
+// from in for prompts. Reports true if the user quit.
+func (ui *contentUI) handle(ctx context.Context, in *bufio.Reader, key string) (bool, error) {
+	if ui.errorView {
+		ui.errorView = false
+		return false, nil
+	}
+	ui.status = ""
+	switch key {
+	case "q", "ctrl-c", "ctrl-d":
+		return true, nil
+	case "up", "k":
+		ui.selected = max(ui.selected-1, 0)
+	case "down", "j":
+		ui.selected = min(ui.selected+1, max(len(ui.posts)-1, 0))
+	case "home", "g":
+		ui.selected = 0
+	case "end", "G":
+		ui.selected = max(len(ui.posts)-1, 0)
+	case "r":
+		return false, ui.reload("")
+	case " ", "d":
+		post, err := ui.post()
+		if err != nil {
+			return false, err
+		}
+		if err := setFrontmatterFile(post.Path, "draft", strconv.FormatBool(!post.Draft)); err != nil {
+			return false, err
+		}
+		ui.status = map[bool]string{false: "Marked as a draft.", true: "Published."}[post.Draft]
+		return false, ui.reload(post.Path)
+	case "e":
+		return false, ui.edit(in)
+	case "o", "enter":
+		post, err := ui.post()
+		if err != nil {
+			return false, err
+		}
+		if err := ui.suspend(func() error { return openEditor(post.Path) }); err != nil {
+			return false, err
+		}
+		return false, ui.reload(post.Path)
+	case "n":
+		title, ok, err := ui.readLine(in, "New post title: ", "")
+		if err != nil || !ok || title == "" {
+			return false, err
+		}
+		path, err := NewPost(NewPostOptions{ConfigPath: ui.opts.ConfigPath, Title: title})
+		if err != nil {
+			return false, err
+		}
+		ui.status = "Created " + path + "."
+		return false, ui.reload(path)
+	case "b":
+		ui.build(ctx)
+	case "x":
+		ui.errorView = true
+	default:
+		ui.status = fmt.Sprintf("Unknown key %q.", key)
+	}
+	return false, nil
+}
+
+// edit asks which field of the selected post to set, then for its value,
+// starting from the current one.
+func (ui *contentUI) edit(in *bufio.Reader) error {
+	post, err := ui.post()
+	if err != nil {
+		return err
+	}
+	var choices []string
+	for _, f := range tuiFields {
+		choices = append(choices, f.key+" "+f.name)
+	}
+	ui.prompt = "Edit which field? " + strings.Join(choices, ", ") + " (esc to cancel)"
+	ui.draw()
+	ui.prompt = ""
+	key, err := readKey(in)
+	if err != nil {
+		return err
+	}
+	var field string
+	for _, f := range tuiFields {
+		if f.key == key {
+			field = f.name
+		}
+	}
+	if field == "" {
+		return nil
+	}
+
+	current := map[string]string{
+		"title":       post.Title,
+		"description": post.Description,
+		"tags":        strings.Join(post.Tags, ", "),
+	}[field]
+	if field == "date" && !post.Date.IsZero() {
+		current = post.Date.Format(time.RFC3339)
+	}
+	value, ok, err := ui.readLine(in, strings.ToUpper(field[:1])+field[1:]+": ", current)
+	if err != nil || !ok {
+		return err
+	}
+	yamlText, err := tuiFieldValue(field, strings.TrimSpace(value))
+	if err != nil {
+		return err
+	}
+	if err := setFrontmatterFile(post.Path, field, yamlText); err != nil {
+		return err
+	}
+	ui.status = "Set " + field + "."
+	return ui.reload(post.Path)
+}
+
+// readLine reads a line of text from in below the list, starting from
+// value: enter accepts it, reporting true, and esc cancels. Backspace
+// deletes a character and ctrl-u the whole line.
+func (ui *contentUI) readLine(in *bufio.Reader, label, value string) (string, bool, error) {
+	defer func() { ui.prompt = "" }()
+	for {
+		ui.prompt = label + value + "_"
+		ui.draw()
+		key, err := readKey(in)
+		if err != nil {
+			return "", false, err
+		}
+		switch key {
+		case "enter":
+			return value, true, nil
+		case "esc", "ctrl-c":
+			return "", false, nil
+		case "backspace":
+			_, size := utf8.DecodeLastRuneInString(value)
+			value = value[:len(value)-size]
+		case "ctrl-u":
+			value = ""
+		default:
+			if r, size := utf8.DecodeRuneInString(key); size == len(key) && unicode.IsPrint(r) {
+				value += key
+			}
+		}
+	}
+}
+
+// build builds the site, keeping its error and the warnings it logs for
+// the error view rather than letting them scroll over the screen.
+func (ui *contentUI) build(ctx context.Context) {
+	ui.status = "Building..."
+	ui.draw()
+
+	var log bytes.Buffer
+	logger, err := NewLogger(&log, slog.LevelWarn, LogFormatText)
+	if err != nil {
+		ui.status = "Error: " + err.Error()
+		return
+	}
+	prev := slog.Default()
+	slog.SetDefault(logger)
+	start := time.Now()
+	ui.buildErr = Build(ctx, BuildOptions{ConfigPath: ui.opts.ConfigPath, OutputDir: ui.opts.OutputDir})
+	slog.SetDefault(prev)
+	ui.buildLog = log.String()
+	ui.hasBuilds = true
+
+	switch {
+	case ui.buildErr != nil:
+		ui.status = "Build failed; press x to see why."
+	case ui.buildLog != "":
+		ui.status = fmt.Sprintf("Built %s in %s with warnings; press x to see them.", ui.opts.OutputDir, time.Since(start).Round(time.Millisecond))
+	default:
+		ui.status = fmt.Sprintf("Built %s in %s.", ui.opts.OutputDir, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// reload reads the posts again, keeping the post at path selected if it's
+// given and still listed.
+func (ui *contentUI) reload(path string) error {
+	posts, err := loadTUIPosts(DirFS("."), ui.config)
+	if err != nil {
+		return err
+	}
+	ui.posts = posts
+	for i, post := range posts {
+		if path != "" && post.Path == path {
+			ui.selected = i
+		}
+	}
+	ui.selected = min(ui.selected, max(len(posts)-1, 0))
+	return nil
+}
+
+// post returns the selected post.
+func (ui *contentUI) post() (tuiPost, error) {
+	if len(ui.posts) == 0 {
+		return tuiPost{}, fmt.Errorf("no posts yet; press n to create one")
+	}
+	return ui.posts[ui.selected], nil
+}
+
+// draw redraws the screen: the list of posts, scrolled to keep the selected
+// one in view, or the error view, then the status line and the prompt or
+// the key help. Lines end in "\r\n", since the terminal is in raw mode.
+func (ui *contentUI) draw() {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, format, args...)
+		b.WriteString("\r\n")
+	}
+	b.WriteString(ansiClear)
+	line("%s%s%s: %d posts", ansiBold, ui.config.Title, ansiReset, len(ui.posts))
+	line("")
+
+	if ui.errorView {
+		switch {
+		case !ui.hasBuilds:
+			line("No builds yet; press b to build.")
+		case ui.buildErr == nil:
+			line("The last build succeeded.")
+		default:
+			for l := range strings.SplitSeq(ui.buildErr.Error(), "\n") {
+				line("%s", l)
+			}
+		}
+		if ui.buildLog != "" {
+			line("")
+			for l := range strings.SplitSeq(strings.TrimRight(ui.buildLog, "\n"), "\n") {
+				line("%s", l)
+			}
+		}
+		line("")
+		line("Press any key to go back.")
+		fmt.Fprint(ui.out, b.String())
+		return
+	}
+
+	// Two lines above the list, and three below it
+	height := len(ui.posts)
+	if rows := ui.rows(); rows > 5 {
+		height = min(height, rows-5)
+	}
+	if ui.selected < ui.top {
+		ui.top = ui.selected
+	}
+	if height > 0 && ui.selected >= ui.top+height {
+		ui.top = ui.selected - height + 1
+	}
+	ui.top = min(ui.top, max(len(ui.posts)-height, 0))
+	if len(ui.posts) == 0 {
+		line("No posts yet; press n to create one.")
+	}
+	for i := ui.top; i < ui.top+height && i < len(ui.posts); i++ {
+		post := ui.posts[i]
+		status, date := "published", "----------"
+		switch {
+		case post.Err != nil:
+			status = "invalid"
+		case post.Draft:
+			status = "draft"
+		}
+		if !post.Date.IsZero() {
+			date = post.Date.Format("2006-01-02")
+		}
+		row := fmt.Sprintf("%-9s  %s  %s (%s)", status, date, post.Title, post.Path)
+		if i == ui.selected {
+			line("%s> %s%s", ansiReverse, row, ansiReset)
+		} else {
+			line("  %s", row)
+		}
+	}
+
+	line("")
+	status := ui.status
+	if post, err := ui.post(); err == nil && post.Err != nil && status == "" {
+		status = post.Err.Error()
+	}
+	line("%s", status)
+	if ui.prompt != "" {
+		b.WriteString(ui.prompt)
+	} else {
+		b.WriteString(tuiKeys)
+	}
+	fmt.Fprint(ui.out, b.String())
+}
+
+// readKey reads one key press from in: a character, or the name of a
+// special key ("up", "down", "home", "end", "enter", "esc", "backspace",
+// "ctrl-c", "ctrl-d", "ctrl-u"). Escape sequences for other keys are read
+// and returned as "".
+func readKey(in *bufio.Reader) (string, error) {
+	b, err := in.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch b {
+	case '\r', '\n':
+		return "enter", nil
+	case 0x7f, 0x08:
+		return "backspace", nil
+	case 0x03:
+		return "ctrl-c", nil
+	case 0x04:
+		return "ctrl-d", nil
+	case 0x15:
+		return "ctrl-u", nil
+	case 0x1b:
+		// A lone esc arrives by itself; the keys sending sequences send
+		// them at once
+		if in.Buffered() == 0 {
+			return "esc", nil
+		}
+		next, err := in.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if next != '[' && next != 'O' {
+			return "esc", in.UnreadByte()
+		}
+		var seq []byte
+		for {
+			c, err := in.ReadByte()
+			if err != nil {
+				return "", err
+			}
+			seq = append(seq, c)
+			if c >= 0x40 && c <= 0x7e {
+				break
+			}
+		}
+		switch string(seq) {
+		case "A":
+			return "up", nil
+		case "B":
+			return "down", nil
+		case "H", "1~":
+			return "home", nil
+		case "F", "4~":
+			return "end", nil
+		}
+		return "", nil
+	}
+	if err := in.UnreadByte(); err != nil {
+		return "", err
+	}
+	r, _, err := in.ReadRune()
+	return string(r), err
+}
+
+// loadTUIPosts parses the site's content files (see contentFiles) for the
+// content manager, newest first. Files that can't be parsed are included
+// with their error, named by their path.
+func loadTUIPosts(fsys fs.FS, config *SiteConfig) ([]tuiPost, error) {
+	files, err := contentFiles(fsys, config)
+	if err != nil {
+		return nil, err
+	}
+	p := newParser(config)
+	posts := make([]tuiPost, 0, len(files))
+	for _, path := range files {
+		post, err := parseFile(fsys, p, path)
+		if err != nil {
+			posts = append(posts, tuiPost{Path: path, Title: path, Err: err})
+			continue
+		}
+		posts = append(posts, tuiPost{Path: path, Title: post.Title, Description: post.Description, Date: post.Date, Tags: post.Tags, Draft: post.Draft})
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].Date.After(posts[j].Date)
+	})
+	return posts, nil
+}
+
+// tuiFieldValue returns value, as typed for field, as YAML for the
+// frontmatter.
+func tuiFieldValue(field, value string) (string, error) {
+	switch field {
+	case "title", "description":
+		return yamlValue(value)
+	case "date":
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if _, err := time.Parse(layout, value); err == nil {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("invalid date %q (want 2006-01-02 or RFC 3339)", value)
+	case "tags":
+		var tags []string
+		for tag := range strings.SplitSeq(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		return yamlValue(tags)
+	}
+	return "", fmt.Errorf("can't set %q", field)
+}
+
+// setFrontmatterFile sets the field name in the frontmatter of the file at
+// path to the YAML value (see setFrontmatterField).
+func setFrontmatterFile(path, name, value string) error {
+	content, err := os.ReadFile(path) // #nosec G304 -- path is one of the site's content files
+	if err != nil {
+		return err
+	}
+	content, err = setFrontmatterField(content, path, name, value)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0600)
+}
+
+// setFrontmatterField returns content with the top-level field name of its
+// frontmatter set to the YAML value, changing only that field's line: a
+// value on the field's line is replaced, keeping any comment after it, an
+// empty field's line is rewritten, and a missing field is added at the end
+// of the frontmatter. path is used in errors.
+//
+// Returns an error if the frontmatter can't be parsed, or the field's value
+// spans several lines (a block list or text), which is left to an editor.
+func setFrontmatterField(content []byte, path, name, value string) ([]byte, error) {
+	fields, err := parser.Fields(content, path)
+	if err != nil {
+		return nil, err
+	}
+	lines := bytes.Split(content, []byte("\n"))
+	for i := range fields {
+		f := &fields[i]
+		if f.Name != name || !f.Set {
+			continue
+		}
+		rest := bytes.TrimSpace(lines[f.Line-1][f.Column-1:])
+		if bytes.HasPrefix(rest, []byte("- ")) || bytes.HasPrefix(rest, []byte("|")) || bytes.HasPrefix(rest, []byte(">")) {
+			return nil, fmt.Errorf("%s in %s spans several lines; edit it with o", name, path)
+		}
+		setFieldValue(lines, f, value)
+		return bytes.Join(lines, []byte("\n")), nil
+	}
+
+	// The field is missing or empty: rewrite its line, or add it before the
+	// closing delimiter
+	var opened bool
+	for i, line := range lines {
+		trimmed := bytes.TrimRight(line, " \t\r")
+		cr := bytes.HasSuffix(line, []byte("\r"))
+		entry := []byte(name + ": " + value)
+		if cr {
+			entry = append(entry, '\r')
+		}
+		switch {
+		case !opened && string(trimmed) == "---":
+			opened = true
+		case opened && bytes.HasPrefix(line, []byte(name+":")):
+			lines[i] = entry
+			return bytes.Join(lines, []byte("\n")), nil
+		case opened && string(trimmed) == "---":
+			lines = append(lines[:i], append([][]byte{entry}, lines[i:]...)...)
+			return bytes.Join(lines, []byte("\n")), nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no frontmatter", path)
+}
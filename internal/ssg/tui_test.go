@@ -0,0 +1,142 @@
+package ssg
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestRunTUI tests selecting posts, toggling drafts, setting fields, and
+// building with keys
+func TestRunTUI(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := testSite()
+	files["content/posts/2024-03-01-draft.md"] = "---\ntitle: Draft Post\ndate: 2024-03-01T10:00:00Z\ndraft: true # soon\n---\n\nAlmost done.\n"
+	writeFiles(t, tmpDir, files)
+	t.Chdir(tmpDir)
+
+	input := strings.Join([]string{
+		"j", "k", // Select the first post, then the draft above it again
+		" ",
+		"eg", "go, web\r",
+		"et", "\x15Published: At Last\r",
+		"ea", "\x15tomorrow\r",
+		"et", "Nope\x1b", // Cancelled
+		"b",
+		"x", "z", // Show the build's errors, then go back
+		"?",
+		"q",
+	}, "")
+	var out strings.Builder
+	if err := runTUI(context.Background(), strings.NewReader(input), &out, TUIOptions{}); err != nil {
+		t.Fatalf("runTUI() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"> draft      2024-03-01  Draft Post (content/posts/2024-03-01-draft.md)",
+		"> published  2024-01-15  First Post",
+		"> published  2024-03-01  Draft Post",
+		"Tags: go, web_",
+		"> published  2024-03-01  Published: At Last",
+		`Error: invalid date "tomorrow"`,
+		"Title: Published: At LastNope_",
+		"Built public in",
+		"The last build succeeded.",
+		`Unknown key "?".`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join("content", "posts", "2024-03-01-draft.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\ntitle: 'Published: At Last'\ndate: 2024-03-01T10:00:00Z\ndraft: false # soon\ntags: [go, web]\n---\n\nAlmost done.\n"
+	if string(content) != want {
+		t.Errorf("post =\n%s\nwant\n%s", content, want)
+	}
+	if _, err := os.Stat(filepath.Join("public", "posts", "draft.html")); err != nil {
+		t.Errorf("build didn't include the published post: %v", err)
+	}
+}
+
+// TestReadKey tests decoding key presses, including escape sequences
+func TestReadKey(t *testing.T) {
+	in := bufio.NewReader(strings.NewReader("a\x1b[A\x1bOB\x1b[4~\x1b[15~é\r\x7f\x1bx"))
+	var got []string
+	for {
+		key, err := readKey(in)
+		if err != nil {
+			break
+		}
+		got = append(got, key)
+	}
+	want := []string{"a", "up", "down", "end", "", "é", "enter", "backspace", "esc", "x"}
+	if !slices.Equal(got, want) {
+		t.Errorf("readKey() = %q, want %q", got, want)
+	}
+}
+
+// TestSetFrontmatterField tests changing one field and keeping the rest of the file
+func TestSetFrontmatterField(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		field   string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "replace",
+			content: "---\ntitle: Old # keep\ndraft: true\n---\nBody\n",
+			field:   "title",
+			value:   "New",
+			want:    "---\ntitle: New # keep\ndraft: true\n---\nBody\n",
+		},
+		{
+			name:    "empty",
+			content: "---\ntitle: Post\ndescription: \"\"\ntags: []\n---\nBody\n",
+			field:   "tags",
+			value:   "[go]",
+			want:    "---\ntitle: Post\ndescription: \"\"\ntags: [go]\n---\nBody\n",
+		},
+		{
+			name:    "missing",
+			content: "---\r\ntitle: Post\r\n---\r\nBody\r\n",
+			field:   "description",
+			value:   "About",
+			want:    "---\r\ntitle: Post\r\ndescription: About\r\n---\r\nBody\r\n",
+		},
+		{
+			name:    "block list",
+			content: "---\ntitle: Post\ntags:\n  - go\n---\nBody\n",
+			field:   "tags",
+			value:   "[web]",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setFrontmatterField([]byte(tt.content), "post.md", tt.field, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("setFrontmatterField() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setFrontmatterField() failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("setFrontmatterField() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
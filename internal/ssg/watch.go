@@ -0,0 +1,422 @@
+package ssg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// watchInterval is how often Serve polls the site's sources for changes.
+const watchInterval = 500 * time.Millisecond
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchSite polls the site's sources every interval and calls onChange with
+// the paths of the changed, added, and removed files whenever something
+// changes. It returns when ctx is cancelled.
+//
+// Polling rather than OS file notifications keeps the watcher portable and
+// copes with editors that save by replacing files. If the site has preBuild
+// hooks, files are scanned again after onChange returns, so what the hooks
+// write doesn't call it again (nor, on such sites, do other changes made
+// while it runs).
+func watchSite(ctx context.Context, configPath string, interval time.Duration, onChange func(changed []string)) {
+	prev := scanFiles(watchedRoots(configPath))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cur := scanFiles(watchedRoots(configPath))
+			if changed := changedFiles(prev, cur); len(changed) > 0 {
+				prev = cur
+				onChange(changed)
+				if hasPreBuildHooks(configPath) {
+					// Files the hooks wrote while rebuilding, like CSS
+					// generated into static/, would trigger another rebuild
+					prev = scanFiles(watchedRoots(configPath))
+				}
+			}
+		}
+	}
+}
+
+// hasPreBuildHooks reports whether the config at configPath has preBuild
+// hooks (see HooksConfig).
+func hasPreBuildHooks(configPath string) bool {
+	config, err := LoadConfig(configPath)
+	return err == nil && len(config.Hooks.PreBuild) > 0
+}
+
+// watchedRoots returns the files and directories a build reads: the config
+// and its environment overlays, content/, templates/, static/, archetypes/,
+// data files (including short links), the theme, mounted files, Go packages
+// documented with godoc, the directories of script entry points, the
+// favicons' source logo, and the Open Graph image layout, logo, and font.
+func watchedRoots(configPath string) []string {
+	roots := []string{configPath, "content", "templates", "static", archetypeDir, dataDir}
+	ext := filepath.Ext(configPath)
+	if overlays, err := filepath.Glob(strings.TrimSuffix(configPath, ext) + ".*" + ext); err == nil {
+		roots = append(roots, overlays...)
+	}
+	if config, err := LoadConfig(configPath); err == nil {
+		for _, m := range config.Mounts {
+			roots = append(roots, m.Source)
+		}
+		roots = append(roots, config.Godoc.Packages...)
+		for _, s := range config.Scripts {
+			roots = append(roots, filepath.Dir(s.Entry))
+		}
+		if config.Theme.Name != "" {
+			roots = append(roots, filepath.Join(themesDir, config.Theme.Name))
+		}
+		for _, file := range []string{config.Favicons.Source, config.OGImage.Template, config.OGImage.Logo, config.OGImage.Font} {
+			if file != "" {
+				roots = append(roots, file)
+			}
+		}
+	}
+	return roots
+}
+
+// scanFiles records the stamp of every file under roots. Missing roots are
+// skipped.
+func scanFiles(roots []string) map[string]fileStamp {
+	files := make(map[string]fileStamp)
+	for _, root := range roots {
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				files[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// changedFiles returns the paths that differ between two scans, sorted, or
+// nil if they match.
+func changedFiles(prev, cur map[string]fileStamp) []string {
+	var changed []string
+	for path, stamp := range cur {
+		if old, ok := prev[path]; !ok || old != stamp {
+			changed = append(changed, path)
+		}
+	}
+	for path := range prev {
+		if _, ok := cur[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// describeChanges names the changed files for a rebuild message, e.g.
+// "static/site.css" or "static/site.css and 2 other files".
+func describeChanges(changed []string) string {
+	switch len(changed) {
+	case 1:
+		return changed[0]
+	case 2:
+		return changed[0] + " and 1 other file"
+	default:
+		return fmt.Sprintf("%s and %d other files", changed[0], len(changed)-1)
+	}
+}
+
+// WatchBuild builds the site, then keeps watching its sources (see
+// watchSite) and updates the output directory whenever one changes, until
+// ctx is cancelled. It's for sites served by another web server or a
+// framework's dev proxy; Serve does the same for its own server.
+//
+// Only what's needed is redone: a changed static file that's copied as is
+// (see copiedAsIs) is copied on its own, and any other change rebuilds the
+// site into a scratch directory and writes just the files that differ to
+// the output directory (see syncOutput). Unchanged files keep their
+// modification times, and the output is never left half-built, so a server
+// reading it mid-rebuild gets the old or the new version of each file.
+//
+// Watching is limited to a site in the working directory, built to disk:
+// changes are found by polling the files there, and rebuilds are staged in
+// a temporary directory.
+//
+// Parameters:
+//   - opts: Build options; opts.Shard must be empty, and opts.Source and
+//     opts.Output must be left at their defaults
+//
+// Returns an error if opts.Shard, opts.Source, or opts.Output is set or the
+// initial build fails. Failed rebuilds are printed and the previous output is
+// left in place.
+func WatchBuild(ctx context.Context, opts BuildOptions) error {
+	opts = opts.withDefaults()
+	if opts.Shard != "" {
+		return fmt.Errorf("can't watch a shard build")
+	}
+	if opts.Source != DirFS(".") || opts.Output != opts.Source {
+		return fmt.Errorf("can only watch a site in the working directory, built to disk")
+	}
+	if err := Build(ctx, opts); err != nil {
+		return err
+	}
+
+	slog.Info("Watching for changes, press Ctrl+C to stop")
+	watchSite(ctx, opts.ConfigPath, watchInterval, func(changed []string) {
+		slog.Info("Rebuilding", "changed", describeChanges(changed))
+		start := time.Now()
+		summary, err := rebuild(ctx, opts, changed)
+		if opts.Notify {
+			notifyBuild(opts.ConfigPath, opts.Environment, err, time.Since(start))
+		}
+		if err != nil {
+			slog.Error("building site", "error", err)
+			return
+		}
+		slog.Info(summary, "duration", time.Since(start))
+	})
+	return nil
+}
+
+// rebuild updates the output directory after changes to the files in
+// changed, as described on WatchBuild, and returns a summary of what it did.
+func rebuild(ctx context.Context, opts BuildOptions, changed []string) (string, error) {
+	config, err := loadConfigEnv(opts.Source, opts.ConfigPath, opts.Environment)
+	if err == nil && copiedAsIs(changed, *config) {
+		if err := copyStaticFiles(changed, *config, opts.OutputDir); err != nil {
+			return "", err
+		}
+		if err := saveManifest(opts.Source, opts.Output, opts.OutputDir, config.Preserve); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Copied %d static files", len(changed)), nil
+	}
+
+	stageDir, err := os.MkdirTemp("", "ssg-watch-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(stageDir)
+
+	staged := opts
+	staged.OutputDir = stageDir
+	if err := generate(ctx, staged); err != nil {
+		return "", err
+	}
+	if err := reportBrokenLinks(opts.Output, stageDir, opts.Strict); err != nil {
+		return "", err
+	}
+	if err := reportAccessibility(opts.Source, opts.Output, stageDir, opts.ConfigPath, opts.Environment); err != nil {
+		return "", err
+	}
+	preserve := preservePatterns(opts.Source, opts.ConfigPath, opts.Environment)
+	written, removed, err := syncOutput(stageDir, opts.OutputDir, preserve)
+	if err != nil {
+		return "", fmt.Errorf("updating %s: %w", opts.OutputDir, err)
+	}
+	if err := saveManifest(opts.Source, opts.Output, opts.OutputDir, preserve); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Rebuilt (%d files written, %d removed)", written, removed), nil
+}
+
+// copiedAsIs reports whether every file in changed is a static file the
+// build copies straight to the output, so that copying them is all a
+// rebuild needs to do. Files that were removed aren't, nor are images
+// (which get resized variants, and whose sizes go in the width and height
+// of <img> tags), head icons (linked from every page), the favicons' source
+// logo, or files in a bundle (which is written from all of its files).
+func copiedAsIs(changed []string, config SiteConfig) bool {
+	for _, file := range changed {
+		rel, err := filepath.Rel("static", file)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return false
+		}
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			return false
+		}
+		if strings.HasPrefix(filepath.ToSlash(rel), "images/") {
+			return false
+		}
+		switch strings.ToLower(filepath.Ext(rel)) {
+		case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+			return false
+		}
+		for _, icon := range iconFiles {
+			if rel == icon.name {
+				return false
+			}
+		}
+		if config.Favicons.Source != "" && filepath.Clean(config.Favicons.Source) == file {
+			return false
+		}
+		for _, b := range config.Bundles {
+			for _, pattern := range b.Files {
+				if ok, _ := filepath.Match(filepath.Join("static", pattern), file); ok {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// copyStaticFiles copies static files to the output directory the way the
+// build does (see copyStatic), minified if the config says so and with the
+// configured file mode.
+func copyStaticFiles(files []string, config SiteConfig, outputDir string) error {
+	limits, err := config.Static.limits()
+	if err != nil {
+		return err
+	}
+	fileMode, dirMode, err := config.Permissions.modes()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		rel, err := filepath.Rel("static", file)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(outputDir, rel)
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if !limits.check(file, info.Size()) {
+			if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		data, err := os.ReadFile(file) // #nosec G304 -- path found by watching static/
+		if err != nil {
+			return err
+		}
+		if config.Minify {
+			if fn := minifyFor(file); fn != nil {
+				data = fn(data)
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), dirMode); err != nil {
+			return err
+		}
+		if err := replaceFile(dst, data, fileMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncOutput makes dstDir match srcDir, writing only the files whose
+// contents or modes differ and removing files and directories srcDir doesn't
+// have, other than the preserved paths (see preserved). Returns the number
+// of files written and paths removed.
+func syncOutput(srcDir, dstDir string, preserve []string) (written, removed int, err error) {
+	err = filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dstDir, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		mode := info.Mode().Perm()
+
+		if d.IsDir() {
+			if err := os.MkdirAll(dst, 0750); err != nil {
+				return err
+			}
+			return os.Chmod(dst, mode) // #nosec G302 -- mode of the freshly built directory
+		}
+
+		data, err := os.ReadFile(path) // #nosec G304 -- path found by walking the build directory
+		if err != nil {
+			return err
+		}
+		if old, err := os.Lstat(dst); err == nil && old.Mode().IsRegular() {
+			current, err := os.ReadFile(dst) // #nosec G304 -- path in the output directory
+			if err == nil && bytes.Equal(current, data) {
+				if old.Mode().Perm() != mode {
+					return os.Chmod(dst, mode) // #nosec G302 -- mode of the freshly built file
+				}
+				return nil
+			}
+		}
+		written++
+		return replaceFile(dst, data, mode)
+	})
+	if err != nil {
+		return written, removed, err
+	}
+
+	err = filepath.WalkDir(dstDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dstDir, path)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Lstat(filepath.Join(srcDir, rel)); !os.IsNotExist(err) {
+			return err
+		}
+		if preserved(filepath.ToSlash(rel), preserve) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		removed++
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return written, removed, err
+}
+
+// replaceFile writes data to a temporary file next to path and renames it
+// into place, so readers of path never see a partly written file.
+func replaceFile(path string, data []byte, mode fs.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".ssg-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), mode); err != nil { // #nosec G302 -- mode of the file being replaced
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,215 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWatchSite tests that editing, adding, or removing a source triggers a rebuild
+func TestWatchSite(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+	t.Chdir(tmpDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan []string, 10)
+	go watchSite(ctx, "config.yaml", 10*time.Millisecond, func(changed []string) { changes <- changed })
+
+	expect := func(want string) {
+		t.Helper()
+		select {
+		case got := <-changes:
+			if !slices.Equal(got, []string{want}) {
+				t.Errorf("changed = %q, want [%q]", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no change reported for %s", want)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond) // Let the first scan finish
+	post := filepath.Join("content", "posts", "first.md")
+	writeFiles(t, tmpDir, map[string]string{"content/posts/first.md": "---\ntitle: Edited\n---\n\nChanged content.\n"})
+	expect(post)
+
+	writeFiles(t, tmpDir, map[string]string{"static/new.css": "body {}"})
+	expect(filepath.Join("static", "new.css"))
+
+	if err := os.Remove(filepath.Join("static", "new.css")); err != nil {
+		t.Fatal(err)
+	}
+	expect(filepath.Join("static", "new.css"))
+}
+
+// TestWatchedRoots tests that overlays and mounted files are watched
+func TestWatchedRoots(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"config.yaml":     "title: Test\nmounts:\n  - source: README.md\nfavicons:\n  source: logo.png\nogImage:\n  template: og.svg\n",
+		"config.dev.yaml": "baseUrl: http://localhost:8080\n",
+	})
+	t.Chdir(tmpDir)
+
+	roots := watchedRoots("config.yaml")
+	for _, want := range []string{"config.yaml", "config.dev.yaml", "content", "archetypes", "README.md", "logo.png", "og.svg"} {
+		if !slices.Contains(roots, want) {
+			t.Errorf("watchedRoots() = %v, missing %s", roots, want)
+		}
+	}
+}
+
+// TestDescribeChanges tests naming the files that triggered a rebuild
+func TestDescribeChanges(t *testing.T) {
+	tests := []struct {
+		changed []string
+		want    string
+	}{
+		{[]string{"static/a.css"}, "static/a.css"},
+		{[]string{"static/a.css", "static/b.css"}, "static/a.css and 1 other file"},
+		{[]string{"static/a.css", "static/b.css", "static/c.css"}, "static/a.css and 2 other files"},
+	}
+	for _, tt := range tests {
+		if got := describeChanges(tt.changed); got != tt.want {
+			t.Errorf("describeChanges(%q) = %q, want %q", tt.changed, got, tt.want)
+		}
+	}
+}
+
+// TestCopiedAsIs tests which static changes need only the files copied
+func TestCopiedAsIs(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"static/css/site.css":     "body {}",
+		"static/css/extra.css":    "p {}",
+		"static/js/a.js":          "a()",
+		"static/images/photo.jpg": "jpeg",
+		"static/favicon.ico":      "ico",
+		"static/css/bg.png":       "png",
+	})
+	t.Chdir(tmpDir)
+	config := SiteConfig{Bundles: []BundleConfig{{Name: "js/all.js", Files: []string{"js/*.js"}}}}
+
+	tests := []struct {
+		changed []string
+		want    bool
+	}{
+		{[]string{filepath.Join("static", "css", "site.css")}, true},
+		{[]string{filepath.Join("static", "css", "site.css"), filepath.Join("static", "css", "extra.css")}, true},
+		{[]string{filepath.Join("static", "css", "gone.css")}, false},
+		{[]string{filepath.Join("static", "images", "photo.jpg")}, false},
+		{[]string{filepath.Join("static", "css", "bg.png")}, false},
+		{[]string{filepath.Join("static", "favicon.ico")}, false},
+		{[]string{filepath.Join("static", "js", "a.js")}, false},
+		{[]string{filepath.Join("static", "css", "site.css"), filepath.Join("content", "posts", "first.md")}, false},
+	}
+	for _, tt := range tests {
+		if got := copiedAsIs(tt.changed, config); got != tt.want {
+			t.Errorf("copiedAsIs(%q) = %v, want %v", tt.changed, got, tt.want)
+		}
+	}
+}
+
+// TestSyncOutput tests that only differing files are written and extra
+// ones removed
+func TestSyncOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	src, dst := filepath.Join(tmpDir, "src"), filepath.Join(tmpDir, "dst")
+	writeFiles(t, src, map[string]string{
+		"index.html":       "new index",
+		"same.html":        "same",
+		"posts/added.html": "added",
+	})
+	writeFiles(t, dst, map[string]string{
+		"index.html":     "old index",
+		"same.html":      "same",
+		"stale.html":     "stale",
+		"old/page.html":  "old",
+		"old/other.html": "old",
+	})
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dst, "same.html"), past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	written, removed, err := syncOutput(src, dst, nil)
+	if err != nil {
+		t.Fatalf("syncOutput() error = %v", err)
+	}
+	if written != 2 || removed != 2 {
+		t.Errorf("syncOutput() = %d written, %d removed, want 2 and 2", written, removed)
+	}
+
+	var files []string
+	err = filepath.WalkDir(dst, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(dst, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"index.html", "posts/added.html", "same.html"}; !slices.Equal(files, want) {
+		t.Errorf("output files = %v, want %v", files, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "index.html")); string(data) != "new index" {
+		t.Errorf("index.html = %q, want the new version", data)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "same.html")); err != nil || !info.ModTime().Equal(past) {
+		t.Errorf("same.html was rewritten, want it left alone")
+	}
+}
+
+// TestWatchBuild tests that changes update the output while watching
+func TestWatchBuild(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, testSite())
+	writeFiles(t, tmpDir, map[string]string{"static/site.css": "body { color: red; }"})
+	t.Chdir(tmpDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- WatchBuild(ctx, BuildOptions{OutputDir: "public"}) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("WatchBuild() error = %v", err)
+		}
+	}()
+
+	waitFor := func(file, want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if data, err := os.ReadFile(filepath.Join("public", file)); err == nil && strings.Contains(string(data), want) {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("%s doesn't contain %q", file, want)
+	}
+	waitFor("site.css", "red")
+	waitFor("index.html", "First Post")
+	time.Sleep(100 * time.Millisecond) // Let the first scan finish
+
+	index := filepath.Join("public", "index.html")
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(index, past, past); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, tmpDir, map[string]string{"static/site.css": "body { color: blue; }"})
+	waitFor("site.css", "blue")
+	if info, err := os.Stat(index); err != nil || !info.ModTime().Equal(past) {
+		t.Errorf("index.html was rewritten by a static change")
+	}
+
+	writeFiles(t, tmpDir, map[string]string{"content/posts/2024-01-15-first.md": "---\ntitle: Renamed Post\ndate: 2024-01-15\n---\n\nContent.\n"})
+	waitFor("index.html", "Renamed Post")
+}
@@ -0,0 +1,519 @@
+package ssg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+	"golang.org/x/net/html"
+)
+
+// webmentionLogPath is where the webmentions sent for each post are logged,
+// relative to the site root, so each is sent once.
+var webmentionLogPath = filepath.Join(".ssg", "webmentions.json")
+
+// maxWebmentionPage limits how much of a linked page is read to discover
+// its webmention endpoint.
+const maxWebmentionPage = 1 << 20
+
+// webmentionClient discovers endpoints, sends webmentions, and fetches
+// received ones.
+var webmentionClient = &http.Client{Timeout: 10 * time.Second}
+
+// mentionsCachePath is where received mentions are cached between builds,
+// relative to the site root.
+var mentionsCachePath = filepath.Join(".ssg", "mentions.json")
+
+// defaultMentionsAPI is the mentions API queried unless webmentions.api is
+// set.
+const defaultMentionsAPI = "https://webmention.io/api/mentions.jf2"
+
+// defaultMentionsMaxAge is how long cached mentions are used before they're
+// fetched again, unless webmentions.maxAge is set.
+const defaultMentionsMaxAge = time.Hour
+
+// mentionTypes maps the wm-property of a received mention to its type.
+var mentionTypes = map[string]string{
+	"in-reply-to": "reply",
+	"like-of":     "like",
+	"repost-of":   "repost",
+	"bookmark-of": "bookmark",
+	"mention-of":  "mention",
+}
+
+// WebmentionsConfig configures webmentions (see w3.org/TR/webmention), which
+// notify the sites a post links to that it mentions them.
+//
+// Example config.yaml:
+//
+//	webmentions:
+//	  send: true
+//	  receive: true
+//
+// Mentions are sent after production builds, for the outbound links of
+// posts and section entries that are new or changed since mentions were
+// last sent for them, as logged in .ssg/webmentions.json.
+//
+// Received mentions are fetched when building from a webmention.io
+// compatible API, which answers GET <api>?target=<post URL> with a JF2 feed
+// of the post's mentions, and are set as .Post.Mentions for templates to
+// show replies and reactions. A token for the API is read from
+// $WEBMENTION_IO_TOKEN, since it doesn't belong in the config.
+type WebmentionsConfig struct {
+	Send    bool   `yaml:"send"`    // Send webmentions for the links of new and changed posts (needs baseUrl)
+	Receive bool   `yaml:"receive"` // Fetch mentions of each post when building (needs baseUrl)
+	API     string `yaml:"api"`     // Mentions API (default: https://webmention.io/api/mentions.jf2)
+	MaxAge  string `yaml:"maxAge"`  // How long fetched mentions are reused, e.g. "30m" (default: 1h)
+}
+
+// cachedMentions are a post's mentions in the cache.
+type cachedMentions struct {
+	Mentions []parser.Mention `json:"mentions"`
+	Fetched  time.Time        `json:"fetched"`
+}
+
+// jf2Feed is a JF2 feed of mentions, as returned by webmention.io.
+type jf2Feed struct {
+	Children []jf2Entry `json:"children"`
+}
+
+// jf2Entry is one mention in a JF2 feed.
+type jf2Entry struct {
+	Author struct {
+		Name  string `json:"name"`
+		URL   string `json:"url"`
+		Photo string `json:"photo"`
+	} `json:"author"`
+	URL       string `json:"url"`
+	Published string `json:"published"`
+	Received  string `json:"wm-received"`
+	Source    string `json:"wm-source"`
+	Property  string `json:"wm-property"`
+	Private   bool   `json:"wm-private"`
+	Content   struct {
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// webmentionLogEntry is what was sent for a post, in the webmention log.
+type webmentionLogEntry struct {
+	Hash    string    `json:"hash"`    // Hash of the post's content when mentions were last sent
+	Targets []string  `json:"targets"` // Links mentions were sent for
+	Sent    time.Time `json:"sent"`
+}
+
+// sendWebmentions sends webmentions for the site built with opts, if the
+// config enables it. Each post's outbound links are the targets, and its
+// URL the source; a post whose content hasn't changed since its mentions
+// were logged is skipped, and a changed one also notifies the links it no
+// longer has, so their pages can drop the mention.
+//
+// The receiving sites fetch the source to verify it links to them, so
+// mentions should be sent once the site is published: Deploy sends them
+// after publishing, and Build only with BuildOptions.Webmentions.
+//
+// Targets without an endpoint are logged as done. A mention that can't be
+// sent is printed as a warning and its post isn't logged, so it's retried
+// after the next build.
+//
+// Returns an error if the config or content can't be loaded, or the site
+// has no baseUrl.
+func sendWebmentions(ctx context.Context, opts BuildOptions) error {
+	opts = opts.withDefaults()
+	config, err := loadConfigEnv(opts.Source, opts.ConfigPath, opts.Environment)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if !config.Webmentions.Send {
+		return nil
+	}
+	if config.BaseURL == "" {
+		return fmt.Errorf("sending webmentions needs baseUrl set, since sources must be absolute URLs")
+	}
+	p := newParser(config)
+	posts, err := loadPosts(opts.Source, p, config, false, false, false)
+	if err != nil {
+		return err
+	}
+	sections, err := loadSections(opts.Source, p, config, false, false, false)
+	if err != nil {
+		return err
+	}
+	for _, section := range sections {
+		posts = append(posts, section.Posts...)
+	}
+
+	log := readWebmentionLog(opts.Source)
+	changed := false
+	sent := 0
+	for _, post := range posts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		source := absURL(config.BaseURL, post.URL)
+		sum := sha256.Sum256([]byte(post.Content))
+		hash := hex.EncodeToString(sum[:])
+		entry, ok := log[source]
+		if ok && entry.Hash == hash {
+			continue
+		}
+
+		links := webmentionTargets(post, config.BaseURL)
+		targets := slices.Clone(links)
+		for _, old := range entry.Targets {
+			if !slices.Contains(targets, old) {
+				targets = append(targets, old)
+			}
+		}
+		failed := false
+		for _, target := range targets {
+			endpoint, err := discoverWebmentionEndpoint(ctx, target)
+			if err == nil && endpoint != "" {
+				err = sendWebmention(ctx, endpoint, source, target)
+			}
+			switch {
+			case err != nil:
+				slog.Warn("sending webmention", "source", source, "target", target, "error", err)
+				failed = true
+			case endpoint != "":
+				slog.Info("Sent webmention", "source", source, "target", target)
+				sent++
+			}
+		}
+		if !failed {
+			log[source] = webmentionLogEntry{Hash: hash, Targets: links, Sent: time.Now()}
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := writeWebmentionLog(opts.Source, log); err != nil {
+			slog.Warn("logging webmentions", "error", err)
+		}
+	}
+	if sent > 0 {
+		slog.Info("Sent webmentions", "count", sent)
+	}
+	return nil
+}
+
+// webmentionTargets returns the outbound links of post, the http(s) links
+// to other hosts than the site's at baseURL, without fragments and in the
+// order they first appear.
+func webmentionTargets(post *parser.Post, baseURL string) []string {
+	site, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	var targets []string
+	z := html.NewTokenizer(strings.NewReader(string(absoluteURLs(post.Content, baseURL, post.URL))))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return targets
+		}
+		if tt != html.StartTagToken {
+			continue
+		}
+		tok := z.Token()
+		if tok.Data != "a" {
+			continue
+		}
+		for _, a := range tok.Attr {
+			if a.Key != "href" {
+				continue
+			}
+			u, err := url.Parse(a.Val)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == site.Host {
+				continue
+			}
+			u.Fragment = ""
+			if target := u.String(); !slices.Contains(targets, target) {
+				targets = append(targets, target)
+			}
+		}
+	}
+}
+
+// discoverWebmentionEndpoint returns the webmention endpoint of the page at
+// target: the first Link header with rel="webmention", or else the first
+// <link> or <a> element with rel="webmention" in an HTML page, resolved
+// against the page's URL after redirects. Returns "" if the page has none,
+// or doesn't exist.
+func discoverWebmentionEndpoint(ctx context.Context, target string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/html")
+	resp, err := webmentionClient.Do(req) // #nosec G107 -- target is a link from the site's own posts
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 400 && resp.StatusCode <= 499:
+		return "", nil // A dead link has nothing to notify
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return "", fmt.Errorf("%s: %s", target, resp.Status)
+	}
+	base := resp.Request.URL
+
+	if href, ok := webmentionLinkHeader(resp.Header.Values("Link")); ok {
+		return resolveEndpoint(base, href)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" {
+		return "", nil
+	}
+	z := html.NewTokenizer(io.LimitReader(resp.Body, maxWebmentionPage))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return "", nil
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tok := z.Token()
+		if tok.Data != "link" && tok.Data != "a" {
+			continue
+		}
+		var href, rel string
+		hasHref := false
+		for _, a := range tok.Attr {
+			switch a.Key {
+			case "href":
+				href, hasHref = a.Val, true
+			case "rel":
+				rel = a.Val
+			}
+		}
+		if hasHref && slices.Contains(strings.Fields(strings.ToLower(rel)), "webmention") {
+			return resolveEndpoint(base, href)
+		}
+	}
+}
+
+// webmentionLinkHeader returns the URL of the first link with the rel
+// webmention in Link header values, like `<https://example.com/wm>;
+// rel="webmention"`.
+func webmentionLinkHeader(values []string) (string, bool) {
+	for _, value := range values {
+		for link := range strings.SplitSeq(value, ",") {
+			parts := strings.Split(link, ";")
+			href := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(href, "<") || !strings.HasSuffix(href, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				name, rel, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				rel = strings.Trim(strings.TrimSpace(rel), `"`)
+				if slices.Contains(strings.Fields(strings.ToLower(rel)), "webmention") {
+					return href[1 : len(href)-1], true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// resolveEndpoint resolves the endpoint href against the URL of the page it
+// was found on. An empty href is the page itself.
+func resolveEndpoint(base *url.URL, href string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return "", fmt.Errorf("invalid webmention endpoint %q: %w", href, err)
+	}
+	return base.ResolveReference(u).String(), nil
+}
+
+// sendWebmention notifies endpoint that source links to target.
+func sendWebmention(ctx context.Context, endpoint, source, target string) error {
+	form := url.Values{"source": {source}, "target": {target}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := webmentionClient.Do(req) // #nosec G107 -- endpoint advertised by a page the site links to
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebmentionPage))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: %s", endpoint, resp.Status)
+	}
+	return nil
+}
+
+// readWebmentionLog loads the webmention log from the site in fsys. A missing
+// or corrupt log is treated as empty.
+func readWebmentionLog(fsys FS) map[string]webmentionLogEntry {
+	log := make(map[string]webmentionLogEntry)
+	data, err := fsys.ReadFile(webmentionLogPath)
+	if err == nil {
+		_ = json.Unmarshal(data, &log)
+	}
+	return log
+}
+
+// writeWebmentionLog saves the webmention log to the site in fsys.
+func writeWebmentionLog(fsys FS, log map[string]webmentionLogEntry) error {
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsys.WriteFile(webmentionLogPath, append(data, '\n'), 0600)
+}
+
+// fetchMentions sets Mentions on each post to the webmentions it received,
+// fetched from the mentions API in cfg for the post's absolute URL. Like
+// comment counts (see fetchCommentCounts), mentions are cached in
+// .ssg/mentions.json and reused until they're older than the configured max
+// age, and mentions that can't be fetched are printed as a warning and the
+// cached ones used instead, so the API being down never fails a build.
+//
+// Parameters:
+//   - fsys: Filesystem the site is read from, which holds the cache
+//   - posts: Posts to set mentions on
+//   - cfg: Webmentions configuration from config.yaml
+//   - baseURL: Site's baseUrl, which post URLs are resolved against
+//   - now: Time of the build, to check cached mentions' age against
+//
+// Returns an error if the configuration is invalid. Does nothing unless
+// receiving is enabled.
+func fetchMentions(fsys FS, posts []*parser.Post, cfg WebmentionsConfig, baseURL string, now time.Time) error {
+	if !cfg.Receive {
+		return nil
+	}
+	if baseURL == "" {
+		return fmt.Errorf("receiving webmentions needs baseUrl set, since mentions are of absolute URLs")
+	}
+	maxAge := defaultMentionsMaxAge
+	if cfg.MaxAge != "" {
+		d, err := time.ParseDuration(cfg.MaxAge)
+		if err != nil {
+			return fmt.Errorf("webmentions.maxAge: %w", err)
+		}
+		maxAge = d
+	}
+	api := cfg.API
+	if api == "" {
+		api = defaultMentionsAPI
+	}
+	if u, err := url.Parse(api); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("webmentions.api %q isn't an http(s) URL", api)
+	}
+
+	cache := readMentionsCache(fsys)
+	changed := false
+	for _, post := range posts {
+		target := absURL(baseURL, post.URL)
+		cached, ok := cache[target]
+		if !ok || now.Sub(cached.Fetched) >= maxAge {
+			mentions, err := fetchPostMentions(api, target, os.Getenv("WEBMENTION_IO_TOKEN"))
+			if err != nil {
+				slog.Warn("fetching webmentions", "post", post.Slug, "error", err)
+			} else {
+				cached = cachedMentions{Mentions: mentions, Fetched: now}
+				cache[target] = cached
+				changed = true
+			}
+		}
+		post.Mentions = cached.Mentions
+	}
+
+	if changed {
+		if err := writeMentionsCache(fsys, cache); err != nil {
+			slog.Warn("caching webmentions", "error", err)
+		}
+	}
+	return nil
+}
+
+// fetchPostMentions returns the public mentions of target from the
+// mentions API at api, oldest first.
+func fetchPostMentions(api, target, token string) ([]parser.Mention, error) {
+	u, err := url.Parse(api)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("target", target)
+	q.Set("per-page", "1000")
+	if token != "" {
+		q.Set("token", token)
+	}
+	u.RawQuery = q.Encode()
+
+	var feed jf2Feed
+	if err := getJSON(u.String(), &feed); err != nil {
+		return nil, err
+	}
+	mentions := []parser.Mention{}
+	for _, entry := range feed.Children {
+		if entry.Private {
+			continue
+		}
+		m := parser.Mention{
+			Type:      mentionTypes[entry.Property],
+			URL:       entry.URL,
+			Author:    entry.Author.Name,
+			AuthorURL: entry.Author.URL,
+			Photo:     entry.Author.Photo,
+			Content:   strings.TrimSpace(entry.Content.Text),
+		}
+		if m.Type == "" {
+			m.Type = "mention"
+		}
+		if m.URL == "" {
+			m.URL = entry.Source
+		}
+		for _, date := range []string{entry.Published, entry.Received} {
+			if t, err := time.Parse(time.RFC3339, date); err == nil {
+				m.Published = t
+				break
+			}
+		}
+		mentions = append(mentions, m)
+	}
+	slices.SortStableFunc(mentions, func(a, b parser.Mention) int { return a.Published.Compare(b.Published) })
+	return mentions, nil
+}
+
+// readMentionsCache loads the cached mentions from the site in fsys. A
+// missing or corrupt cache is treated as empty.
+func readMentionsCache(fsys FS) map[string]cachedMentions {
+	cache := make(map[string]cachedMentions)
+	data, err := fsys.ReadFile(mentionsCachePath)
+	if err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// writeMentionsCache saves the cached mentions to the site in fsys.
+func writeMentionsCache(fsys FS, cache map[string]cachedMentions) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsys.WriteFile(mentionsCachePath, append(data, '\n'), 0600)
+}
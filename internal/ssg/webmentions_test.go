@@ -0,0 +1,196 @@
+package ssg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+)
+
+// TestSendWebmentions tests sending mentions for new and changed posts only
+func TestSendWebmentions(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><link rel="webmention" href="/webmention"></head><body>Article</body></html>`)
+	})
+	mux.HandleFunc("/header", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", `<https://other.example/>; rel="me", </webmention?via=header>; rel="webmention"`)
+		fmt.Fprint(w, "ok")
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "no endpoint")
+	})
+	mux.HandleFunc("/webmention", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, r.PostFormValue("source")+" -> "+r.PostFormValue("target"))
+		w.WriteHeader(http.StatusAccepted)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tmpDir := t.TempDir()
+	site := testSite()
+	site["config.yaml"] += "webmentions:\n  send: true\n"
+	site["content/posts/2024-01-15-first.md"] = "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\n" +
+		"Read [this](" + srv.URL + "/article#intro), [that](" + srv.URL + "/header), [nothing](" + srv.URL + "/plain), " +
+		"[gone](" + srv.URL + "/missing), and [my own](/posts/second.html).\n"
+	writeFiles(t, tmpDir, site)
+	t.Chdir(tmpDir)
+
+	send := func() []string {
+		t.Helper()
+		mu.Lock()
+		received = nil
+		mu.Unlock()
+		if err := sendWebmentions(context.Background(), BuildOptions{Environment: EnvProduction}); err != nil {
+			t.Fatalf("sendWebmentions() failed: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(received)
+	}
+
+	// Builds leave sending to Deploy unless asked to send
+	if err := Build(context.Background(), BuildOptions{Environment: EnvProduction}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	mu.Lock()
+	if len(received) != 0 {
+		t.Errorf("Build() sent %v, want nothing without Webmentions", received)
+	}
+	mu.Unlock()
+
+	source := "https://test.com/posts/first.html"
+	want := []string{source + " -> " + srv.URL + "/article", source + " -> " + srv.URL + "/header"}
+	if got := send(); !slices.Equal(got, want) {
+		t.Errorf("first run sent %v, want %v", got, want)
+	}
+	if got := send(); len(got) != 0 {
+		t.Errorf("unchanged post sent %v again, want nothing", got)
+	}
+
+	// Dropping a link notifies its target too
+	writeFiles(t, tmpDir, map[string]string{
+		"content/posts/2024-01-15-first.md": "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\nRead [that](" + srv.URL + "/header).\n",
+	})
+	want = []string{source + " -> " + srv.URL + "/header", source + " -> " + srv.URL + "/article"}
+	if got := send(); !slices.Equal(got, want) {
+		t.Errorf("changed post sent %v, want %v", got, want)
+	}
+}
+
+// TestWebmentionLinkHeader tests finding the endpoint in Link headers
+func TestWebmentionLinkHeader(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   string
+		wantOK bool
+	}{
+		{values: []string{`<https://example.com/wm>; rel="webmention"`}, want: "https://example.com/wm", wantOK: true},
+		{values: []string{`<https://example.com/a>; rel=me`, `</wm>; rel="webmention somethingelse"`}, want: "/wm", wantOK: true},
+		{values: []string{`<https://example.com/wm>; REL=Webmention`}, want: "https://example.com/wm", wantOK: true},
+		{values: []string{`<https://example.com/wm>; rel="pingback"`}},
+		{},
+	}
+	for _, tt := range tests {
+		got, ok := webmentionLinkHeader(tt.values)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("webmentionLinkHeader(%q) = %q, %v, want %q, %v", tt.values, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// TestFetchMentions tests fetching received mentions, caching them, and
+// falling back to the cache
+func TestFetchMentions(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("WEBMENTION_IO_TOKEN", "secret")
+	requests := 0
+	down := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if down {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Query().Get("token") != "secret" {
+			t.Errorf("request without token: %s", r.URL)
+		}
+		if r.URL.Query().Get("target") != "https://test.com/posts/first/" {
+			_, _ = w.Write([]byte(`{"type": "feed", "children": []}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"type": "feed", "children": [
+			{"author": {"name": "Bob", "url": "https://bob.example", "photo": "https://bob.example/me.jpg"},
+			 "url": "https://bob.example/reply", "published": "2024-05-02T10:00:00Z",
+			 "wm-property": "in-reply-to", "content": {"text": " Nice post! "}},
+			{"author": {"name": "Carol"}, "url": "https://social.example/like/1",
+			 "wm-received": "2024-05-01T09:00:00Z", "wm-property": "like-of"},
+			{"author": {"name": "Dave"}, "wm-source": "https://dave.example/post",
+			 "wm-received": "2024-05-03T09:00:00Z", "wm-property": "mention-of", "wm-private": true}
+		]}`))
+	}))
+	defer srv.Close()
+
+	posts := []*parser.Post{{Slug: "first", URL: "/posts/first/"}, {Slug: "second", URL: "/posts/second/"}}
+	cfg := WebmentionsConfig{Receive: true, API: srv.URL + "/api/mentions.jf2"}
+	now := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)
+	if err := fetchMentions(DirFS("."), posts, cfg, "https://test.com", now); err != nil {
+		t.Fatalf("fetchMentions() failed: %v", err)
+	}
+	mentions := posts[0].Mentions
+	if len(mentions) != 2 {
+		t.Fatalf("got %d mentions, want 2 (private one skipped): %+v", len(mentions), mentions)
+	}
+	like, reply := mentions[0], mentions[1]
+	if like.Type != "like" || like.Author != "Carol" || !like.Published.Equal(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("first mention = %+v, want Carol's like, dated when received", like)
+	}
+	if reply.Type != "reply" || reply.Content != "Nice post!" || reply.AuthorURL != "https://bob.example" || reply.Photo != "https://bob.example/me.jpg" {
+		t.Errorf("second mention = %+v, want Bob's reply", reply)
+	}
+	if posts[1].Mentions == nil || len(posts[1].Mentions) != 0 {
+		t.Errorf("second post mentions = %v, want empty", posts[1].Mentions)
+	}
+	if requests != 2 {
+		t.Errorf("made %d requests, want 2", requests)
+	}
+
+	// Fresh mentions come from the cache; stale ones are used when the API is down
+	requests = 0
+	posts[0].Mentions = nil
+	if err := fetchMentions(DirFS("."), posts[:1], cfg, "https://test.com", now.Add(30*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 0 || len(posts[0].Mentions) != 2 {
+		t.Errorf("cached fetch made %d requests, got %d mentions; want 0 requests and 2", requests, len(posts[0].Mentions))
+	}
+	down = true
+	posts[0].Mentions = nil
+	cfg.MaxAge = "10m"
+	if err := fetchMentions(DirFS("."), posts[:1], cfg, "https://test.com", now.Add(30*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 || len(posts[0].Mentions) != 2 {
+		t.Errorf("stale fetch made %d requests, got %d mentions; want 1 request and the cached 2", requests, len(posts[0].Mentions))
+	}
+
+	for _, bad := range []WebmentionsConfig{{Receive: true, MaxAge: "soon"}, {Receive: true, API: "ftp://host/api"}} {
+		if err := fetchMentions(DirFS("."), posts, bad, "https://test.com", now); err == nil {
+			t.Errorf("fetchMentions(%+v) succeeded, want error", bad)
+		}
+	}
+	if err := fetchMentions(DirFS("."), posts, WebmentionsConfig{Receive: true}, "", now); err == nil {
+		t.Error("fetchMentions() without baseUrl succeeded, want error")
+	}
+}
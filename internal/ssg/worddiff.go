@@ -0,0 +1,107 @@
+package ssg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+)
+
+// wordToken matches the units a word diff compares: runs of letters and
+// digits, runs of whitespace, and single other characters, so a changed
+// word inside "<p>Hello</p>" shows up as the word alone.
+var wordToken = regexp.MustCompile(`[\p{L}\p{N}_]+|\s+|.`)
+
+// wordDiff returns a word diff between two versions of a file: the lines
+// with changes, each with the words removed marked [-like this-] and those
+// added marked {+like this+}, under a header with the line number in the
+// new version. Runs of changed lines are shown together, and unchanged
+// lines aren't shown.
+func wordDiff(name, before, after string) string {
+	a := wordToken.FindAllString(before, -1)
+	b := wordToken.FindAllString(after, -1)
+
+	// The token diff runs on myers' line diff, with each token on a line of
+	// its own and its newlines replaced (tokens are the same after undoing it)
+	edits := myers.ComputeEdits(span.URIFromPath(name), tokenLines(a), tokenLines(b))
+
+	var merged strings.Builder
+	changed := map[int]bool{} // Lines of merged with changes
+	newLines := []int{1}      // Line in after of each line of merged
+	line := 0
+	write := func(tokens []string, marker string, inAfter bool) {
+		for i, part := range strings.Split(strings.Join(tokens, ""), "\n") {
+			if i > 0 {
+				merged.WriteByte('\n')
+				line++
+				next := newLines[len(newLines)-1]
+				if inAfter {
+					next++
+				}
+				newLines = append(newLines, next)
+			}
+			if part == "" {
+				continue
+			}
+			if marker != "" {
+				part = marker[:2] + part + marker[2:]
+				changed[line] = true
+			}
+			merged.WriteString(part)
+		}
+	}
+
+	// myers reports a run of inserted tokens as one edit per token, so
+	// adjacent edits are merged into one change
+	pos := 0
+	for i := 0; i < len(edits); {
+		start, end := edits[i].Span.Start().Line()-1, edits[i].Span.End().Line()-1
+		var added []string
+		for ; i < len(edits) && edits[i].Span.Start().Line()-1 <= end; i++ {
+			end = max(end, edits[i].Span.End().Line()-1)
+			added = append(added, untokenLines(edits[i].NewText)...)
+		}
+		write(a[pos:start], "", true)
+		write(a[start:end], "[--]", false)
+		write(added, "{++}", true)
+		pos = end
+	}
+	write(a[pos:], "", true)
+
+	lines := strings.Split(merged.String(), "\n")
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", name, name)
+	for i := 0; i < len(lines); i++ {
+		if !changed[i] {
+			continue
+		}
+		fmt.Fprintf(&out, "@@ line %d @@\n", newLines[i])
+		for ; i < len(lines) && changed[i]; i++ {
+			fmt.Fprintln(&out, lines[i])
+		}
+	}
+	return out.String()
+}
+
+// tokenLines joins tokens as lines, for a line diff to compare them.
+func tokenLines(tokens []string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString(strings.ReplaceAll(t, "\n", "\x00"))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// untokenLines splits text from tokenLines back into tokens.
+func untokenLines(text string) []string {
+	var tokens []string
+	for t := range strings.SplitSeq(strings.TrimSuffix(text, "\n"), "\n") {
+		if text != "" {
+			tokens = append(tokens, strings.ReplaceAll(t, "\x00", "\n"))
+		}
+	}
+	return tokens
+}
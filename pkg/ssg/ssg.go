@@ -0,0 +1,243 @@
+// Package ssg is the public Go API of the static site generator. It lets other
+// Go programs load and build a site instead of shelling out to the CLI:
+//
+//	site, err := ssg.Load("config.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	for _, post := range site.Posts() {
+//		fmt.Println(post.Title, post.URL)
+//	}
+//	err = site.Build(ctx, ssg.BuildOptions{OutputDir: "public"})
+//
+// Like the CLI, Load reads a site's content/, templates/, and static/
+// directories relative to the current working directory. LoadFS reads them
+// from an FS instead, such as a *MemFS or a DirFS of another directory, and
+// the site is built from the same FS. The output can be written to memory
+// instead of disk by building with a *MemFS as BuildOptions.Output.
+package ssg
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/kvnloughead/ssg/internal/parser"
+	"github.com/kvnloughead/ssg/internal/ssg"
+)
+
+// Config is the site configuration loaded from config.yaml.
+type Config = ssg.SiteConfig
+
+// Post is a parsed markdown post.
+type Post = parser.Post
+
+// Mention is a webmention a post received, as in Post.Mentions.
+type Mention = parser.Mention
+
+// Attachment is a file a post offers for download, as in Post.Attachments.
+type Attachment = parser.Attachment
+
+// ContentParser converts content files in a format other than markdown to
+// HTML. See RegisterContentParser.
+type ContentParser = parser.ContentParser
+
+// ContentParserFunc adapts an ordinary function to a ContentParser.
+type ContentParserFunc = parser.ContentParserFunc
+
+// HTMLTransformer modifies the parsed HTML of each rendered page. See
+// RegisterTransformer.
+type HTMLTransformer = ssg.HTMLTransformer
+
+// RenderedPage describes the page an HTMLTransformer is applied to.
+type RenderedPage = ssg.RenderedPage
+
+// Page is a rendered page as seen by a PagePostProcessor, with its final
+// HTML in Content.
+type Page = ssg.Page
+
+// PagePostProcessor is a step run on the final HTML of each rendered page.
+// See RegisterPostProcessor.
+type PagePostProcessor = ssg.PagePostProcessor
+
+// PostProcessorFunc adapts an ordinary function to a PagePostProcessor.
+type PostProcessorFunc = ssg.PostProcessorFunc
+
+// Plugin extends builds with hooks run before and after each stage. See
+// RegisterPlugin.
+type Plugin = ssg.Plugin
+
+// BasePlugin implements the Plugin hooks as no-ops, for plugins to embed.
+type BasePlugin = ssg.BasePlugin
+
+// BuildContext is the state of a build passed to a Plugin's BeforeBuild and
+// AfterBuild hooks.
+type BuildContext = ssg.BuildContext
+
+// PageData is the data pages are rendered with, as passed to
+// BuildContext.RenderPage.
+type PageData = ssg.PageData
+
+// FS is a filesystem a build writes to: a directory on disk (see DirFS) or
+// memory (see MemFS).
+type FS = ssg.FS
+
+// MemFS is an FS held in memory. The zero value is an empty filesystem.
+type MemFS = ssg.MemFS
+
+// DirFS returns an FS for the directory dir on disk.
+func DirFS(dir string) FS {
+	return ssg.DirFS(dir)
+}
+
+// BuildOptions configures Site.Build. Builds log through slog's default
+// logger; to see each file written, set one from NewLogger at debug level.
+type BuildOptions struct {
+	OutputDir   string // Directory to write the site to (default: "public")
+	Drafts      bool   // Include posts marked draft: true
+	Future      bool   // Include posts dated in the future
+	Expired     bool   // Include posts whose expiryDate has passed
+	BaseURL     string // Overrides baseUrl from the config
+	Environment string // "production" or "development" (default: $SSG_ENV, then production); selects the config overlay and is exposed to templates as .Env
+	Strict      bool   // Fail the build if generated pages have broken internal links
+	Notify      bool   // Run the notify hooks from the config when the build finishes
+	Webmentions bool   // Send webmentions once a production build finishes; only for output served as it's written, since Deploy sends them after publishing
+	Shard       string // Build only shard i of n, written "i/n" (e.g., "2/4"); combine shards with Merge
+	Profile     bool   // Print how long each stage of the build and the slowest posts took
+	CPUProfile  string // Write a pprof CPU profile of the build to this file
+	Source      FS     // Filesystem to read the site from (default: the one it was loaded from)
+	Output      FS     // Filesystem to write OutputDir to, like a *MemFS (default: Source)
+}
+
+// Site is a loaded site: its configuration and published posts.
+type Site struct {
+	Config *Config
+
+	source     FS
+	configPath string
+	posts      []*Post
+}
+
+// Load reads the site configuration at configPath and parses the site's
+// published posts, from the current directory.
+//
+// Returns an error if the config can't be read or a post fails to parse.
+func Load(configPath string) (*Site, error) {
+	return LoadFS(DirFS("."), configPath)
+}
+
+// LoadFS is Load reading the site from fsys, with configPath a name in it.
+func LoadFS(fsys FS, configPath string) (*Site, error) {
+	config, err := ssg.LoadConfigFS(fsys, configPath)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := ssg.LoadPostsFS(fsys, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Site{Config: config, source: fsys, configPath: configPath, posts: posts}, nil
+}
+
+// Posts returns the site's published posts, newest first. Drafts, posts
+// dated in the future, and expired posts are excluded.
+func (s *Site) Posts() []*Post {
+	return s.posts
+}
+
+// Build generates the site, exactly as `ssg build` does.
+//
+// The config and content are read again from the site's FS, so the build
+// reflects any changes made since Load. Cancelling ctx stops the build
+// between pages.
+func (s *Site) Build(ctx context.Context, opts BuildOptions) error {
+	if opts.Source == nil {
+		opts.Source = s.source
+	}
+	return ssg.Build(ctx, ssg.BuildOptions{
+		ConfigPath:  s.configPath,
+		OutputDir:   opts.OutputDir,
+		Drafts:      opts.Drafts,
+		Future:      opts.Future,
+		Expired:     opts.Expired,
+		BaseURL:     opts.BaseURL,
+		Environment: opts.Environment,
+		Strict:      opts.Strict,
+		Notify:      opts.Notify,
+		Webmentions: opts.Webmentions,
+		Shard:       opts.Shard,
+		Profile:     opts.Profile,
+		CPUProfile:  opts.CPUProfile,
+		Source:      opts.Source,
+		Output:      opts.Output,
+	})
+}
+
+// Merge combines the output directories of sharded builds into outputDir,
+// exactly as `ssg merge` does.
+func Merge(configPath, outputDir string, shardDirs []string, strict bool) error {
+	return ssg.Merge(configPath, outputDir, shardDirs, strict)
+}
+
+// Log formats accepted by NewLogger.
+const (
+	LogFormatText = ssg.LogFormatText
+	LogFormatJSON = ssg.LogFormatJSON
+)
+
+// NewLogger returns a logger writing messages of level and above to w in
+// format, the way the CLI logs. Builds log through slog's default logger, so
+// pass it to slog.SetDefault; files written and the duration of each build
+// stage are logged at debug level.
+func NewLogger(w io.Writer, level slog.Level, format string) (*slog.Logger, error) {
+	return ssg.NewLogger(w, level, format)
+}
+
+// RegisterFunc adds a function available to every template in builds started
+// afterwards. It replaces a standard function of the same name.
+func RegisterFunc(name string, fn any) {
+	ssg.RegisterFunc(name, fn)
+}
+
+// Capabilities a registered function can declare, so sandboxed themes that
+// are granted them can call it.
+const (
+	CapUnsafeHTML = ssg.CapUnsafeHTML
+	CapReadFiles  = ssg.CapReadFiles
+	CapExec       = ssg.CapExec
+	CapNetwork    = ssg.CapNetwork
+)
+
+// RegisterFuncWithCapabilities is RegisterFunc for a function that declares
+// the capabilities it needs. Functions registered without them can't be
+// called by sandboxed themes.
+func RegisterFuncWithCapabilities(name string, fn any, caps ...string) {
+	ssg.RegisterFuncWithCapabilities(name, fn, caps...)
+}
+
+// RegisterTransformer adds a transformer run on every page rendered by builds
+// started afterwards. Transformers run in registration order.
+func RegisterTransformer(name string, fn HTMLTransformer) {
+	ssg.RegisterTransformer(name, fn)
+}
+
+// RegisterPostProcessor adds a post-processor run on the final HTML of every
+// page rendered by builds started afterwards, after the transformers and
+// minification. Post-processors run in registration order.
+func RegisterPostProcessor(name string, p PagePostProcessor) {
+	ssg.RegisterPostProcessor(name, p)
+}
+
+// RegisterContentParser makes files with the extension ext (e.g., ".adoc")
+// posts and section entries in builds started afterwards, their content
+// after the frontmatter converted to HTML by cp.
+func RegisterContentParser(ext string, cp ContentParser) {
+	ssg.RegisterContentParser(ext, cp)
+}
+
+// RegisterPlugin adds a plugin to builds started afterwards. Plugins' hooks
+// run in registration order, after those of the built-in plugins.
+func RegisterPlugin(p Plugin) {
+	ssg.RegisterPlugin(p)
+}
@@ -0,0 +1,151 @@
+package ssg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// siteFiles is a minimal site with published posts and a draft.
+var siteFiles = map[string]string{
+	"config.yaml":                       "title: Test Blog\nbaseUrl: https://test.com\n",
+	"content/posts/2024-01-15-first.md": "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\n---\n\nHello.\n",
+	"content/posts/2024-02-01-later.md": "---\ntitle: Later Post\ndate: 2024-02-01T10:00:00Z\n---\n\nLater.\n",
+	"content/posts/draft.md":            "---\ntitle: Draft\ndate: 2024-03-01T10:00:00Z\ndraft: true\n---\n\nWIP.\n",
+}
+
+// writeSite writes siteFiles into dir.
+func writeSite(t *testing.T, dir string) {
+	t.Helper()
+	for name, content := range siteFiles {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestLoad tests loading a site's config and published posts
+func TestLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeSite(t, tmpDir)
+	t.Chdir(tmpDir)
+
+	site, err := Load("config.yaml")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if site.Config.Title != "Test Blog" {
+		t.Errorf("Config.Title = %q, want %q", site.Config.Title, "Test Blog")
+	}
+
+	posts := site.Posts()
+	if len(posts) != 2 {
+		t.Fatalf("got %d posts, want 2 (drafts excluded)", len(posts))
+	}
+	if posts[0].Title != "Later Post" || posts[0].URL != "/posts/later.html" {
+		t.Errorf("first post = %q at %q, want newest post with its URL", posts[0].Title, posts[0].URL)
+	}
+}
+
+// TestLoadFS tests loading a site from a MemFS and building it there,
+// without touching the disk
+func TestLoadFS(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+	src := &MemFS{}
+	for name, content := range siteFiles {
+		if err := src.WriteFile(name, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	site, err := LoadFS(src, "config.yaml")
+	if err != nil {
+		t.Fatalf("LoadFS() failed: %v", err)
+	}
+	if site.Config.Title != "Test Blog" || len(site.Posts()) != 2 {
+		t.Errorf("LoadFS() = %q with %d posts, want %q with 2", site.Config.Title, len(site.Posts()), "Test Blog")
+	}
+	if err := site.Build(context.Background(), BuildOptions{}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if _, err := src.Stat("public/posts/first.html"); err != nil {
+		t.Errorf("post page wasn't written to the MemFS: %v", err)
+	}
+	if entries, err := os.ReadDir(tmpDir); err != nil || len(entries) > 0 {
+		t.Errorf("build wrote %v to the working directory (%v)", entries, err)
+	}
+}
+
+// TestSite_Build tests building a loaded site
+func TestSite_Build(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeSite(t, tmpDir)
+	t.Chdir(tmpDir)
+
+	site, err := Load("config.yaml")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if err := site.Build(context.Background(), BuildOptions{OutputDir: "out"}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join("out", "posts", "first.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "First Post") {
+		t.Errorf("post page missing title:\n%s", content)
+	}
+}
+
+// TestSite_BuildCancelled tests that a cancelled context stops the build
+func TestSite_BuildCancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeSite(t, tmpDir)
+	t.Chdir(tmpDir)
+
+	site, err := Load("config.yaml")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := site.Build(ctx, BuildOptions{}); err == nil {
+		t.Error("Build() with a cancelled context succeeded, want error")
+	}
+}
+
+// TestSite_BuildInMemory tests building a loaded site into a MemFS
+func TestSite_BuildInMemory(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeSite(t, tmpDir)
+	t.Chdir(tmpDir)
+
+	site, err := Load("config.yaml")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	out := &MemFS{}
+	if err := site.Build(context.Background(), BuildOptions{Output: out}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	content, err := out.ReadFile("public/posts/first.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "First Post") {
+		t.Errorf("post page missing title:\n%s", content)
+	}
+	if _, err := os.Stat("public"); err == nil {
+		t.Error("Build() wrote the output to disk")
+	}
+}